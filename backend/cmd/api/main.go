@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"log"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-contrib/gzip"
@@ -12,6 +14,7 @@ import (
 	"github.com/npinot/vibe/backend/internal/config"
 	"github.com/npinot/vibe/backend/internal/db"
 	"github.com/npinot/vibe/backend/internal/middleware"
+	"github.com/npinot/vibe/backend/internal/model"
 	"github.com/npinot/vibe/backend/internal/repository"
 	"github.com/npinot/vibe/backend/internal/service"
 	"github.com/npinot/vibe/backend/internal/static"
@@ -43,7 +46,14 @@ func main() {
 	sessionRepo := repository.NewSessionRepository(database)
 	configRepo := repository.NewConfigRepository(database)
 	interactionRepo := repository.NewInteractionRepository(database)
+	reviewRepo := repository.NewReviewRepository(database)
+	budgetRepo := repository.NewBudgetRepository(database)
+	resourceCapRepo := repository.NewResourceCapRepository(database)
+	templateRepo := repository.NewProjectTemplateRepository(database)
+	memberRepo := repository.NewProjectMemberRepository(database)
 
+	// Pods of projects without a resource profile get the default profile
+	defaultProfile, _ := service.LookupResourceProfile(service.DefaultResourceProfile)
 	k8sService, err := service.NewKubernetesService(
 		cfg.Kubeconfig,
 		cfg.K8SNamespace,
@@ -52,11 +62,13 @@ func main() {
 			OpenCodeImage:     cfg.OpenCodeServerImage,
 			FileBrowserImage:  cfg.FileBrowserImage,
 			SessionProxyImage: cfg.SessionProxyImage,
-			WorkspaceSize:     "1Gi",
-			CPULimit:          "1000m",
-			MemoryLimit:       "1Gi",
-			CPURequest:        "100m",
-			MemoryRequest:     "256Mi",
+			GitCloneImage:     cfg.GitCloneImage,
+			WorkspaceSize:     defaultProfile.StorageSize,
+			CPULimit:          defaultProfile.CPULimit,
+			MemoryLimit:       defaultProfile.MemoryLimit,
+			CPURequest:        defaultProfile.CPURequest,
+			MemoryRequest:     defaultProfile.MemoryRequest,
+			SnapshotClass:     cfg.WorkspaceSnapshotClass,
 		},
 	)
 	if err != nil {
@@ -64,15 +76,79 @@ func main() {
 		log.Println("Project management features will be limited")
 	}
 
-	configService, err := service.NewConfigService(configRepo, cfg.EncryptionKey)
+	// Every pod lookup goes through the hibernator, so idle projects can be hibernated and woken on demand
+	var hibernator *service.ProjectHibernator
+	if k8sService != nil {
+		hibernator = service.NewProjectHibernator(k8sService, projectRepo, sessionRepo, cfg.ProjectIdleTimeout)
+		k8sService = hibernator
+	}
+
+	configService, err := service.NewConfigService(configRepo, projectRepo, cfg.EncryptionKey)
 	if err != nil {
 		log.Fatalf("Failed to initialize config service: %v", err)
 	}
 
-	sessionService := service.NewSessionService(sessionRepo, taskRepo, projectRepo, k8sService, configService, cfg.OpenCodeSharedSecret)
-	projectService := service.NewProjectService(projectRepo, k8sService)
-	taskService := service.NewTaskService(taskRepo, projectRepo, sessionService)
-	interactionService := service.NewInteractionService(interactionRepo, taskRepo, projectRepo, sessionRepo)
+	secretCipher, err := service.NewSecretCipher(cfg.EncryptionKey)
+	if err != nil {
+		log.Fatalf("Failed to initialize secret cipher: %v", err)
+	}
+
+	authz := service.NewAuthorizationService(projectRepo, memberRepo)
+	workspaceGitService := service.NewWorkspaceGitService(k8sService)
+	sessionService := service.NewSessionService(sessionRepo, taskRepo, projectRepo, interactionRepo, budgetRepo, k8sService, configService, workspaceGitService, cfg.OpenCodeSharedSecret)
+	resourceProfileService := service.NewResourceProfileService(resourceCapRepo, model.ResourceCap{
+		MaxCPU:      cfg.ResourceCapCPU,
+		MaxMemory:   cfg.ResourceCapMemory,
+		MaxStorage:  cfg.ResourceCapStorage,
+		AllowCustom: cfg.ResourceCapAllowCustom,
+	})
+	var archiveStore service.ArchiveStore
+	if cfg.ArchiveDir != "" {
+		if archiveStore, err = service.NewFilesystemArchiveStore(cfg.ArchiveDir); err != nil {
+			log.Printf("Warning: Failed to initialize archive store: %v", err)
+		}
+	}
+	projectService := service.NewProjectService(projectRepo, authz, k8sService, secretCipher, resourceProfileService, archiveStore)
+	// Without the VolumeSnapshot CRDs every task run would fail to take its automatic snapshot
+	taskSnapshotsKept := cfg.TaskSnapshotsKept
+	if taskSnapshotsKept > 0 && k8sService != nil {
+		if err := k8sService.CheckWorkspaceSnapshots(context.Background()); err != nil {
+			log.Printf("Warning: Automatic workspace snapshots before task runs are disabled: %v", err)
+			taskSnapshotsKept = 0
+		}
+	}
+	snapshotService := service.NewWorkspaceSnapshotService(projectRepo, authz, sessionRepo, k8sService, taskSnapshotsKept)
+	archiveService := service.NewProjectArchiveService(projectRepo, authz, sessionRepo, k8sService, archiveStore, secretCipher)
+	taskService := service.NewTaskService(taskRepo, projectRepo, authz, sessionService, workspaceGitService, reviewRepo, snapshotService)
+	interactionService := service.NewInteractionService(interactionRepo, taskRepo, authz, sessionRepo)
+	usageService := service.NewUsageService(sessionRepo, authz)
+	budgetService := service.NewBudgetService(budgetRepo, sessionRepo, projectRepo, authz)
+	templateService := service.NewProjectTemplateService(templateRepo, projectRepo, projectService, configService, taskService, k8sService)
+	forkService := service.NewProjectForkService(projectRepo, authz, taskRepo, configService, k8sService, secretCipher, resourceProfileService)
+	memberService := service.NewProjectMemberService(memberRepo, projectRepo, userRepo, authz)
+
+	// Resume or settle sessions left running by a previous backend instance or a restarted project pod
+	if k8sService != nil {
+		go service.NewSessionReconciler(sessionService, time.Minute).Run(context.Background())
+		// Stop sessions that run past the timeout or iteration limit of their project config
+		go service.NewSessionWatchdog(sessionService, 15*time.Second).Run(context.Background())
+		// Drop the workspace content recorded for the diffs of deleted or expired sessions
+		if cfg.SessionDiffRetention > 0 {
+			go service.NewSessionSnapshotPruner(sessionService, cfg.SessionDiffRetention, time.Hour).Run(context.Background())
+		}
+	}
+	// Keep the pod status of projects up to date, recreate vanished pods and clean up after deleted projects
+	if k8sService != nil {
+		clientset, err := service.NewKubernetesClient(cfg.Kubeconfig)
+		if err != nil {
+			log.Printf("Warning: Failed to initialize pod reconciler: %v", err)
+		} else {
+			go service.NewPodReconciler(clientset, cfg.K8SNamespace, projectRepo, k8sService, 5*time.Minute).Run(context.Background())
+		}
+	}
+	if hibernator != nil && cfg.ProjectIdleTimeout > 0 {
+		go hibernator.Run(context.Background(), time.Minute)
+	}
 
 	authService, err := service.NewAuthService(cfg, userRepo)
 	if err != nil {
@@ -82,14 +158,23 @@ func main() {
 
 	authMiddleware := middleware.NewAuthMiddleware(cfg, userRepo)
 	authHandler := api.NewAuthHandler(authService)
-	projectHandler := api.NewProjectHandler(projectService)
-	taskHandler := api.NewTaskHandler(taskService, projectRepo, k8sService)
-	fileHandler := api.NewFileHandler(projectRepo, k8sService)
-	configHandler := api.NewConfigHandler(configService)
-	interactionHandler := api.NewInteractionHandler(interactionService)
+	projectHandler := api.NewProjectHandler(projectService, templateService)
+	taskHandler := api.NewTaskHandler(taskService, authz, k8sService, cfg.OpenCodeSharedSecret)
+	fileHandler := api.NewFileHandler(authz, k8sService)
+	configHandler := api.NewConfigHandler(configService, authz)
+	interactionHandler := api.NewInteractionHandler(interactionService, taskService)
 	sessionHandler := api.NewSessionHandler(sessionService)
+	terminalHandler := api.NewTerminalHandler(authz, k8sService, hibernator, cfg.OpenCodeSharedSecret, cfg.TerminalIdleTimeout, cfg.TerminalMaxPerUser)
+	usageHandler := api.NewUsageHandler(usageService)
+	budgetHandler := api.NewBudgetHandler(budgetService)
+	resourceHandler := api.NewResourceHandler(resourceProfileService)
+	snapshotHandler := api.NewSnapshotHandler(snapshotService)
+	archiveHandler := api.NewArchiveHandler(archiveService)
+	templateHandler := api.NewTemplateHandler(templateService)
+	forkHandler := api.NewForkHandler(forkService)
+	memberHandler := api.NewMemberHandler(memberService)
 
-	router := setupRouter(cfg, authHandler, projectHandler, taskHandler, fileHandler, configHandler, interactionHandler, sessionHandler, authMiddleware)
+	router := setupRouter(cfg, authHandler, projectHandler, taskHandler, fileHandler, configHandler, interactionHandler, sessionHandler, terminalHandler, usageHandler, budgetHandler, resourceHandler, snapshotHandler, archiveHandler, templateHandler, forkHandler, memberHandler, authMiddleware)
 
 	// Setup static file serving for production (embedded frontend)
 	if cfg.Environment == "production" {
@@ -110,7 +195,7 @@ func main() {
 	}
 }
 
-func setupRouter(cfg *config.Config, authHandler *api.AuthHandler, projectHandler *api.ProjectHandler, taskHandler *api.TaskHandler, fileHandler *api.FileHandler, configHandler *api.ConfigHandler, interactionHandler *api.InteractionHandler, sessionHandler *api.SessionHandler, authMiddleware *middleware.AuthMiddleware) *gin.Engine {
+func setupRouter(cfg *config.Config, authHandler *api.AuthHandler, projectHandler *api.ProjectHandler, taskHandler *api.TaskHandler, fileHandler *api.FileHandler, configHandler *api.ConfigHandler, interactionHandler *api.InteractionHandler, sessionHandler *api.SessionHandler, terminalHandler *api.TerminalHandler, usageHandler *api.UsageHandler, budgetHandler *api.BudgetHandler, resourceHandler *api.ResourceHandler, snapshotHandler *api.SnapshotHandler, archiveHandler *api.ArchiveHandler, templateHandler *api.TemplateHandler, forkHandler *api.ForkHandler, memberHandler *api.MemberHandler, authMiddleware *middleware.AuthMiddleware) *gin.Engine {
 	if cfg.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
@@ -146,13 +231,45 @@ func setupRouter(cfg *config.Config, authHandler *api.AuthHandler, projectHandle
 			auth.POST("/logout", authHandler.Logout)
 		}
 
-		sessions := v1.Group("/sessions")
+		// Callbacks from the project pod sidecars, which authenticate with the shared secret
+		sessions := v1.Group("/sessions", authMiddleware.SharedSecretAuth())
 		{
 			sessions.GET("/active", sessionHandler.GetActiveSessions)
 			sessions.PATCH("/:id/status", sessionHandler.UpdateSessionStatus)
 			sessions.PATCH("/:id/event-id", sessionHandler.UpdateLastEventID)
 		}
 
+		budget := v1.Group("/budget", authMiddleware.JWTAuth())
+		{
+			budget.GET("", budgetHandler.GetUserBudget)
+			budget.PUT("", budgetHandler.SetUserBudget)
+			budget.DELETE("", budgetHandler.DeleteUserBudget)
+		}
+
+		v1.GET("/resource-profiles", authMiddleware.JWTAuth(), resourceHandler.ListResourceProfiles)
+
+		templates := v1.Group("/templates", authMiddleware.JWTAuth())
+		{
+			templates.GET("", templateHandler.ListTemplates)
+			templates.POST("", templateHandler.CreateTemplate)
+			templates.GET("/:id", templateHandler.GetTemplate)
+			templates.DELETE("/:id", templateHandler.DeleteTemplate)
+		}
+
+		invitations := v1.Group("/invitations", authMiddleware.JWTAuth())
+		{
+			invitations.GET("", memberHandler.ListMyInvitations)
+			invitations.POST("/:id/accept", memberHandler.AcceptInvitation)
+			invitations.POST("/:id/decline", memberHandler.DeclineInvitation)
+		}
+
+		admin := v1.Group("/admin", authMiddleware.JWTAuth(), authMiddleware.RequireAdmin())
+		{
+			admin.GET("/users/:userId/resource-cap", resourceHandler.GetUserResourceCap)
+			admin.PUT("/users/:userId/resource-cap", resourceHandler.SetUserResourceCap)
+			admin.DELETE("/users/:userId/resource-cap", resourceHandler.DeleteUserResourceCap)
+		}
+
 		projects := v1.Group("/projects", authMiddleware.JWTAuth())
 		{
 			projects.GET("", projectHandler.ListProjects)
@@ -161,6 +278,26 @@ func setupRouter(cfg *config.Config, authHandler *api.AuthHandler, projectHandle
 			projects.PATCH("/:id", projectHandler.UpdateProject)
 			projects.DELETE("/:id", projectHandler.DeleteProject)
 			projects.GET("/:id/status", projectHandler.ProjectStatus)
+			projects.POST("/:id/wake", projectHandler.WakeProject)
+			projects.PUT("/:id/resources", projectHandler.UpdateProjectResources)
+			projects.GET("/:id/logs", projectHandler.ProjectLogs)
+			projects.GET("/:id/usage", usageHandler.GetProjectUsage)
+			projects.GET("/:id/budget", budgetHandler.GetProjectBudget)
+			projects.PUT("/:id/budget", budgetHandler.SetProjectBudget)
+			projects.DELETE("/:id/budget", budgetHandler.DeleteProjectBudget)
+			projects.GET("/:id/snapshots", snapshotHandler.ListSnapshots)
+			projects.POST("/:id/snapshots", snapshotHandler.CreateSnapshot)
+			projects.POST("/:id/snapshots/:snapshot/restore", snapshotHandler.RestoreSnapshot)
+			projects.DELETE("/:id/snapshots/:snapshot", snapshotHandler.DeleteSnapshot)
+			projects.POST("/:id/archive", archiveHandler.ArchiveProject)
+			projects.POST("/:id/restore", archiveHandler.RestoreProject)
+			projects.POST("/:id/fork", forkHandler.ForkProject)
+			projects.GET("/:id/members", memberHandler.ListMembers)
+			projects.PATCH("/:id/members/:userId", memberHandler.UpdateMember)
+			projects.DELETE("/:id/members/:userId", memberHandler.RemoveMember)
+			projects.GET("/:id/invitations", memberHandler.ListInvitations)
+			projects.POST("/:id/invitations", memberHandler.InviteMember)
+			projects.DELETE("/:id/invitations/:invitationId", memberHandler.RevokeInvitation)
 
 			projects.GET("/:id/tasks", taskHandler.ListTasks)
 			projects.POST("/:id/tasks", taskHandler.CreateTask)
@@ -173,6 +310,10 @@ func setupRouter(cfg *config.Config, authHandler *api.AuthHandler, projectHandle
 			projects.POST("/:id/tasks/:taskId/stop", taskHandler.StopTask)
 			projects.GET("/:id/tasks/:taskId/output", taskHandler.TaskOutputStream)
 			projects.GET("/:id/tasks/:taskId/sessions", taskHandler.GetTaskSessions)
+			projects.GET("/:id/tasks/:taskId/sessions/:sessionId/diff", taskHandler.GetSessionDiff)
+			projects.POST("/:id/tasks/:taskId/sessions/:sessionId/prompt", taskHandler.ContinueSession)
+			projects.GET("/:id/tasks/:taskId/reviews", taskHandler.ListReviews)
+			projects.POST("/:id/tasks/:taskId/reviews", taskHandler.SubmitReview)
 			projects.GET("/:id/tasks/:taskId/interactions", interactionHandler.GetTaskHistory)
 			projects.GET("/:id/tasks/:taskId/interact", interactionHandler.TaskInteractionWebSocket)
 
@@ -184,6 +325,15 @@ func setupRouter(cfg *config.Config, authHandler *api.AuthHandler, projectHandle
 			projects.POST("/:id/files/mkdir", fileHandler.CreateDirectory)
 			projects.GET("/:id/files/watch", fileHandler.FileChangesStream)
 
+			projects.GET("/:id/terminal", terminalHandler.ProjectTerminal)
+
+			projects.GET("/:id/git/status", fileHandler.GitStatus)
+			projects.GET("/:id/git/diff", fileHandler.GitDiff)
+			projects.POST("/:id/git/commit", fileHandler.GitCommit)
+			projects.POST("/:id/git/branch", fileHandler.GitCreateBranch)
+			projects.POST("/:id/git/checkout", fileHandler.GitCheckout)
+			projects.POST("/:id/git/push", fileHandler.GitPush)
+
 			projects.GET("/:id/config", configHandler.GetActiveConfig)
 			projects.POST("/:id/config", configHandler.CreateOrUpdateConfig)
 			projects.GET("/:id/config/versions", configHandler.GetConfigHistory)
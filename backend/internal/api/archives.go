@@ -0,0 +1,76 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/npinot/vibe/backend/internal/service"
+)
+
+// ArchiveHandler moves projects to the archive store and back
+type ArchiveHandler struct {
+	archiveService service.ProjectArchiveService
+}
+
+func NewArchiveHandler(archiveService service.ProjectArchiveService) *ArchiveHandler {
+	return &ArchiveHandler{
+		archiveService: archiveService,
+	}
+}
+
+// ArchiveProject exports the project workspace to the archive store and deletes the project pod and PVC.
+// Archived projects are read-only until restored.
+// POST /api/projects/:id/archive
+func (h *ArchiveHandler) ArchiveProject(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	project, err := h.archiveService.ArchiveProject(c.Request.Context(), projectID, userID)
+	if err != nil {
+		respondArchiveError(c, err, "Failed to archive project")
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+// RestoreProject recreates the workspace of an archived project from its archive and starts the project pod
+// POST /api/projects/:id/restore
+func (h *ArchiveHandler) RestoreProject(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	project, err := h.archiveService.RestoreProject(c.Request.Context(), projectID, userID)
+	if err != nil {
+		respondArchiveError(c, err, "Failed to restore project")
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+func respondArchiveError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrProjectNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+	case errors.Is(err, service.ErrUnauthorized):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+	case errors.Is(err, service.ErrProjectArchived),
+		errors.Is(err, service.ErrProjectNotArchived),
+		errors.Is(err, service.ErrProjectPodMissing),
+		errors.Is(err, service.ErrWorkspaceBusy):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrArchivesUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		log.Printf("[Archive] %s: %v", message, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/npinot/vibe/backend/internal/model"
+	"github.com/npinot/vibe/backend/internal/service"
+)
+
+type MockArchiveService struct {
+	mock.Mock
+}
+
+func (m *MockArchiveService) ArchiveProject(ctx context.Context, projectID, userID uuid.UUID) (*model.Project, error) {
+	args := m.Called(ctx, projectID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Project), args.Error(1)
+}
+
+func (m *MockArchiveService) RestoreProject(ctx context.Context, projectID, userID uuid.UUID) (*model.Project, error) {
+	args := m.Called(ctx, projectID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Project), args.Error(1)
+}
+
+func setupArchiveTestRouter() (*MockArchiveService, http.Handler) {
+	mockService := new(MockArchiveService)
+	handler := NewArchiveHandler(mockService)
+	router := setupProjectTestRouter(nil)
+	router.POST("/projects/:id/archive", handler.ArchiveProject)
+	router.POST("/projects/:id/restore", handler.RestoreProject)
+	return mockService, router
+}
+
+func TestArchiveHandler(t *testing.T) {
+	userID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	projectID := uuid.New()
+
+	t.Run("archives project", func(t *testing.T) {
+		mockService, router := setupArchiveTestRouter()
+		mockService.On("ArchiveProject", mock.Anything, projectID, userID).
+			Return(&model.Project{ID: projectID, UserID: userID, Status: model.ProjectStatusArchived, ArchiveKey: "projects/key.tar.gz", ArchiveSize: 2048}, nil)
+
+		req, _ := http.NewRequest("POST", "/projects/"+projectID.String()+"/archive", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "archived", response["status"])
+		assert.Equal(t, float64(2048), response["archive_size"])
+		assert.NotContains(t, w.Body.String(), "projects/key.tar.gz")
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("restores project", func(t *testing.T) {
+		mockService, router := setupArchiveTestRouter()
+		mockService.On("RestoreProject", mock.Anything, projectID, userID).
+			Return(&model.Project{ID: projectID, UserID: userID, Status: model.ProjectStatusReady}, nil)
+
+		req, _ := http.NewRequest("POST", "/projects/"+projectID.String()+"/restore", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"status":"ready"`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid project ID", func(t *testing.T) {
+		_, router := setupArchiveTestRouter()
+
+		req, _ := http.NewRequest("POST", "/projects/not-a-uuid/archive", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	errorCases := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"project not found", service.ErrProjectNotFound, http.StatusNotFound},
+		{"unauthorized", service.ErrUnauthorized, http.StatusForbidden},
+		{"already archived", service.ErrProjectArchived, http.StatusConflict},
+		{"not archived", fmt.Errorf("%w: status is ready", service.ErrProjectNotArchived), http.StatusConflict},
+		{"pod missing", fmt.Errorf("%w: the workspace was never created", service.ErrProjectPodMissing), http.StatusConflict},
+		{"workspace busy", fmt.Errorf("%w: task is still running", service.ErrWorkspaceBusy), http.StatusConflict},
+		{"archives unavailable", service.ErrArchivesUnavailable, http.StatusServiceUnavailable},
+		{"internal error", errors.New("boom"), http.StatusInternalServerError},
+	}
+	for _, tc := range errorCases {
+		t.Run("archive "+tc.name, func(t *testing.T) {
+			mockService, router := setupArchiveTestRouter()
+			mockService.On("ArchiveProject", mock.Anything, projectID, userID).Return(nil, tc.err)
+
+			req, _ := http.NewRequest("POST", "/projects/"+projectID.String()+"/archive", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.wantStatus, w.Code)
+		})
+	}
+}
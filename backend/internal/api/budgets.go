@@ -0,0 +1,185 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/npinot/vibe/backend/internal/middleware"
+	"github.com/npinot/vibe/backend/internal/service"
+)
+
+// BudgetHandler manages the monthly spending budgets of projects and users
+type BudgetHandler struct {
+	budgetService service.BudgetService
+}
+
+func NewBudgetHandler(budgetService service.BudgetService) *BudgetHandler {
+	return &BudgetHandler{
+		budgetService: budgetService,
+	}
+}
+
+// SetBudgetRequest sets the monthly limits of a budget; omitted limits are not enforced
+type SetBudgetRequest struct {
+	MonthlyCostUSD *float64 `json:"monthly_cost_usd"`
+	MonthlyTokens  *int64   `json:"monthly_tokens"`
+	WarnPercent    int      `json:"warn_percent"` // defaults to 80
+}
+
+func (r SetBudgetRequest) limits() service.BudgetLimits {
+	return service.BudgetLimits{
+		MonthlyCostUSD: r.MonthlyCostUSD,
+		MonthlyTokens:  r.MonthlyTokens,
+		WarnPercent:    r.WarnPercent,
+	}
+}
+
+// GetProjectBudget returns the project's budget and what the project spent this month
+// GET /api/projects/:id/budget
+func (h *BudgetHandler) GetProjectBudget(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.budgetService.GetProjectBudget(c.Request.Context(), projectID, userID)
+	if err != nil {
+		respondBudgetError(c, err, "Failed to fetch budget")
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// SetProjectBudget creates or replaces the project's budget
+// PUT /api/projects/:id/budget
+func (h *BudgetHandler) SetProjectBudget(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	var req SetBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	status, err := h.budgetService.SetProjectBudget(c.Request.Context(), projectID, userID, req.limits())
+	if err != nil {
+		respondBudgetError(c, err, "Failed to save budget")
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// DeleteProjectBudget removes the project's budget
+// DELETE /api/projects/:id/budget
+func (h *BudgetHandler) DeleteProjectBudget(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.budgetService.DeleteProjectBudget(c.Request.Context(), projectID, userID); err != nil {
+		respondBudgetError(c, err, "Failed to delete budget")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetUserBudget returns the current user's budget and what their projects spent this month
+// GET /api/budget
+func (h *BudgetHandler) GetUserBudget(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	if userID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	status, err := h.budgetService.GetUserBudget(c.Request.Context(), userID)
+	if err != nil {
+		respondBudgetError(c, err, "Failed to fetch budget")
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// SetUserBudget creates or replaces the budget covering all projects of the current user
+// PUT /api/budget
+func (h *BudgetHandler) SetUserBudget(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	if userID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req SetBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	status, err := h.budgetService.SetUserBudget(c.Request.Context(), userID, req.limits())
+	if err != nil {
+		respondBudgetError(c, err, "Failed to save budget")
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// DeleteUserBudget removes the current user's budget
+// DELETE /api/budget
+func (h *BudgetHandler) DeleteUserBudget(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	if userID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.budgetService.DeleteUserBudget(c.Request.Context(), userID); err != nil {
+		respondBudgetError(c, err, "Failed to delete budget")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// projectParams reads the current user and the project ID of the route, writing the error response on failure
+func projectParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID := middleware.GetCurrentUserID(c)
+	if userID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, projectID, true
+}
+
+func respondBudgetError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrProjectNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+	case errors.Is(err, service.ErrUnauthorized):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+	case errors.Is(err, service.ErrInvalidBudget):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		log.Printf("[Budget] %s: %v", message, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,256 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/npinot/vibe/backend/internal/model"
+	"github.com/npinot/vibe/backend/internal/service"
+)
+
+type MockBudgetService struct {
+	mock.Mock
+}
+
+func (m *MockBudgetService) GetProjectBudget(ctx context.Context, projectID, userID uuid.UUID) (*service.BudgetStatus, error) {
+	args := m.Called(ctx, projectID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.BudgetStatus), args.Error(1)
+}
+
+func (m *MockBudgetService) SetProjectBudget(ctx context.Context, projectID, userID uuid.UUID, limits service.BudgetLimits) (*service.BudgetStatus, error) {
+	args := m.Called(ctx, projectID, userID, limits)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.BudgetStatus), args.Error(1)
+}
+
+func (m *MockBudgetService) DeleteProjectBudget(ctx context.Context, projectID, userID uuid.UUID) error {
+	args := m.Called(ctx, projectID, userID)
+	return args.Error(0)
+}
+
+func (m *MockBudgetService) GetUserBudget(ctx context.Context, userID uuid.UUID) (*service.BudgetStatus, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.BudgetStatus), args.Error(1)
+}
+
+func (m *MockBudgetService) SetUserBudget(ctx context.Context, userID uuid.UUID, limits service.BudgetLimits) (*service.BudgetStatus, error) {
+	args := m.Called(ctx, userID, limits)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.BudgetStatus), args.Error(1)
+}
+
+func (m *MockBudgetService) DeleteUserBudget(ctx context.Context, userID uuid.UUID) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockBudgetService) CheckBudget(ctx context.Context, projectID uuid.UUID) error {
+	args := m.Called(ctx, projectID)
+	return args.Error(0)
+}
+
+func (m *MockBudgetService) EvaluateBudgets(ctx context.Context, projectID uuid.UUID) ([]service.BudgetStatus, error) {
+	args := m.Called(ctx, projectID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]service.BudgetStatus), args.Error(1)
+}
+
+func (m *MockBudgetService) MarkWarned(ctx context.Context, status *service.BudgetStatus) (bool, error) {
+	args := m.Called(ctx, status)
+	return args.Bool(0), args.Error(1)
+}
+
+func setupBudgetTestRouter() (*MockBudgetService, http.Handler) {
+	mockService := new(MockBudgetService)
+	handler := NewBudgetHandler(mockService)
+	router := setupProjectTestRouter(nil)
+	router.GET("/projects/:id/budget", handler.GetProjectBudget)
+	router.PUT("/projects/:id/budget", handler.SetProjectBudget)
+	router.DELETE("/projects/:id/budget", handler.DeleteProjectBudget)
+	router.GET("/budget", handler.GetUserBudget)
+	router.PUT("/budget", handler.SetUserBudget)
+	router.DELETE("/budget", handler.DeleteUserBudget)
+	return mockService, router
+}
+
+func TestBudgetHandler_ProjectBudget(t *testing.T) {
+	userID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	projectID := uuid.New()
+	limit := 50.0
+
+	t.Run("returns budget with this month's spending", func(t *testing.T) {
+		mockService, router := setupBudgetTestRouter()
+		status := &service.BudgetStatus{
+			Budget: &model.Budget{Scope: model.BudgetScopeProject, ScopeID: projectID, MonthlyCostUSD: &limit, WarnPercent: 80},
+			Period: "2026-03",
+			Spent:  &model.UsageTotals{Sessions: 3, CostUSD: 12.5},
+		}
+		mockService.On("GetProjectBudget", mock.Anything, projectID, userID).Return(status, nil)
+
+		req, _ := http.NewRequest("GET", "/projects/"+projectID.String()+"/budget", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "2026-03", response["period"])
+		assert.Equal(t, 50.0, response["budget"].(map[string]interface{})["monthly_cost_usd"])
+		assert.Equal(t, 12.5, response["spent"].(map[string]interface{})["cost_usd"])
+	})
+
+	t.Run("sets budget", func(t *testing.T) {
+		mockService, router := setupBudgetTestRouter()
+		mockService.On("SetProjectBudget", mock.Anything, projectID, userID, mock.MatchedBy(func(limits service.BudgetLimits) bool {
+			return *limits.MonthlyCostUSD == 50 && limits.MonthlyTokens == nil && limits.WarnPercent == 90
+		})).Return(&service.BudgetStatus{Period: "2026-03", Spent: &model.UsageTotals{}}, nil)
+
+		body := bytes.NewBufferString(`{"monthly_cost_usd": 50, "warn_percent": 90}`)
+		req, _ := http.NewRequest("PUT", "/projects/"+projectID.String()+"/budget", body)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("rejects invalid limits", func(t *testing.T) {
+		mockService, router := setupBudgetTestRouter()
+		mockService.On("SetProjectBudget", mock.Anything, projectID, userID, mock.Anything).
+			Return(nil, fmt.Errorf("%w: set a monthly cost or token limit", service.ErrInvalidBudget))
+
+		req, _ := http.NewRequest("PUT", "/projects/"+projectID.String()+"/budget", bytes.NewBufferString(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "set a monthly cost or token limit")
+	})
+
+	t.Run("deletes budget", func(t *testing.T) {
+		mockService, router := setupBudgetTestRouter()
+		mockService.On("DeleteProjectBudget", mock.Anything, projectID, userID).Return(nil)
+
+		req, _ := http.NewRequest("DELETE", "/projects/"+projectID.String()+"/budget", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("invalid project ID", func(t *testing.T) {
+		_, router := setupBudgetTestRouter()
+
+		req, _ := http.NewRequest("GET", "/projects/not-a-uuid/budget", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	errorCases := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"project not found", service.ErrProjectNotFound, http.StatusNotFound},
+		{"other user's project", service.ErrUnauthorized, http.StatusForbidden},
+		{"repository failure", errors.New("db down"), http.StatusInternalServerError},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService, router := setupBudgetTestRouter()
+			mockService.On("GetProjectBudget", mock.Anything, projectID, userID).Return(nil, tc.err)
+
+			req, _ := http.NewRequest("GET", "/projects/"+projectID.String()+"/budget", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestBudgetHandler_UserBudget(t *testing.T) {
+	userID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+
+	t.Run("returns spending without a budget", func(t *testing.T) {
+		mockService, router := setupBudgetTestRouter()
+		mockService.On("GetUserBudget", mock.Anything, userID).
+			Return(&service.BudgetStatus{Period: "2026-03", Spent: &model.UsageTotals{CostUSD: 4}}, nil)
+
+		req, _ := http.NewRequest("GET", "/budget", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Nil(t, response["budget"])
+		assert.Equal(t, 4.0, response["spent"].(map[string]interface{})["cost_usd"])
+	})
+
+	t.Run("sets token budget", func(t *testing.T) {
+		mockService, router := setupBudgetTestRouter()
+		mockService.On("SetUserBudget", mock.Anything, userID, mock.MatchedBy(func(limits service.BudgetLimits) bool {
+			return limits.MonthlyCostUSD == nil && *limits.MonthlyTokens == 2_000_000
+		})).Return(&service.BudgetStatus{Period: "2026-03", Spent: &model.UsageTotals{}}, nil)
+
+		req, _ := http.NewRequest("PUT", "/budget", bytes.NewBufferString(`{"monthly_tokens": 2000000}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		_, router := setupBudgetTestRouter()
+
+		req, _ := http.NewRequest("PUT", "/budget", bytes.NewBufferString(`{"monthly_tokens": "lots"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("deletes budget", func(t *testing.T) {
+		mockService, router := setupBudgetTestRouter()
+		mockService.On("DeleteUserBudget", mock.Anything, userID).Return(nil)
+
+		req, _ := http.NewRequest("DELETE", "/budget", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/npinot/vibe/backend/internal/middleware"
 	"github.com/npinot/vibe/backend/internal/model"
+	"github.com/npinot/vibe/backend/internal/service"
 )
 
 type ConfigService interface {
@@ -24,14 +25,46 @@ type ConfigService interface {
 
 type ConfigHandler struct {
 	configService ConfigService
+	authz         service.AuthorizationService
 }
 
-func NewConfigHandler(configService ConfigService) *ConfigHandler {
+func NewConfigHandler(configService ConfigService, authz service.AuthorizationService) *ConfigHandler {
 	return &ConfigHandler{
 		configService: configService,
+		authz:         authz,
 	}
 }
 
+// authorizeProject checks the current user holds at least role on the project of the request.
+// It writes the error response and returns false when not.
+func (h *ConfigHandler) authorizeProject(c *gin.Context, role model.ProjectRole) (uuid.UUID, *model.User, bool) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return uuid.Nil, nil, false
+	}
+
+	user, err := middleware.GetCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return uuid.Nil, nil, false
+	}
+
+	if _, err := h.authz.AuthorizeProject(c.Request.Context(), projectID, user.ID, role); err != nil {
+		switch {
+		case errors.Is(err, service.ErrProjectNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+		case errors.Is(err, service.ErrUnauthorized):
+			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return uuid.Nil, nil, false
+	}
+
+	return projectID, user, true
+}
+
 // GetActiveConfig godoc
 // @Summary Get active configuration
 // @Description Retrieves the currently active OpenCode configuration for a project
@@ -39,12 +72,12 @@ func NewConfigHandler(configService ConfigService) *ConfigHandler {
 // @Produce json
 // @Param id path string true "Project ID"
 // @Success 200 {object} model.OpenCodeConfig
+// @Failure 403 {object} gin.H{"error": "access denied"}
 // @Failure 404 {object} gin.H{"error": "config not found"}
 // @Router /api/projects/{id}/config [get]
 func (h *ConfigHandler) GetActiveConfig(c *gin.Context) {
-	projectID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+	projectID, _, ok := h.authorizeProject(c, model.ProjectRoleViewer)
+	if !ok {
 		return
 	}
 
@@ -71,11 +104,11 @@ func (h *ConfigHandler) GetActiveConfig(c *gin.Context) {
 // @Param config body CreateConfigRequest true "Configuration"
 // @Success 201 {object} model.OpenCodeConfig
 // @Failure 400 {object} gin.H{"error": "validation error"}
+// @Failure 409 {object} gin.H{"error": "project is archived"}
 // @Router /api/projects/{id}/config [post]
 func (h *ConfigHandler) CreateOrUpdateConfig(c *gin.Context) {
-	projectID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+	projectID, user, ok := h.authorizeProject(c, model.ProjectRoleMaintainer)
+	if !ok {
 		return
 	}
 
@@ -85,13 +118,6 @@ func (h *ConfigHandler) CreateOrUpdateConfig(c *gin.Context) {
 		return
 	}
 
-	// Get user from context (set by auth middleware)
-	user, err := middleware.GetCurrentUser(c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
-		return
-	}
-
 	config := &model.OpenCodeConfig{
 		ProjectID:      projectID,
 		ModelProvider:  req.ModelProvider,
@@ -106,10 +132,24 @@ func (h *ConfigHandler) CreateOrUpdateConfig(c *gin.Context) {
 		MaxIterations:  req.MaxIterations,
 		TimeoutSeconds: req.TimeoutSeconds,
 		CreatedBy:      user.ID,
+
+		AIReviewEnabled:    model.DefaultAIReviewEnabled,
+		ReviewModelName:    req.ReviewModelName,
+		ReviewSystemPrompt: req.ReviewSystemPrompt,
+	}
+	if req.AIReviewEnabled != nil {
+		config.AIReviewEnabled = *req.AIReviewEnabled
 	}
 
 	if err := h.configService.CreateOrUpdateConfig(c.Request.Context(), config, req.APIKey); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		switch {
+		case errors.Is(err, service.ErrProjectNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+		case errors.Is(err, service.ErrProjectArchived):
+			c.JSON(http.StatusConflict, gin.H{"error": "project is archived"})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
@@ -125,9 +165,8 @@ func (h *ConfigHandler) CreateOrUpdateConfig(c *gin.Context) {
 // @Success 200 {array} model.OpenCodeConfig
 // @Router /api/projects/{id}/config/versions [get]
 func (h *ConfigHandler) GetConfigHistory(c *gin.Context) {
-	projectID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+	projectID, _, ok := h.authorizeProject(c, model.ProjectRoleViewer)
+	if !ok {
 		return
 	}
 
@@ -149,11 +188,11 @@ func (h *ConfigHandler) GetConfigHistory(c *gin.Context) {
 // @Param version path int true "Version to rollback to"
 // @Success 200 {object} gin.H{"message": "config rolled back"}
 // @Failure 404 {object} gin.H{"error": "version not found"}
+// @Failure 409 {object} gin.H{"error": "project is archived"}
 // @Router /api/projects/{id}/config/rollback/{version} [post]
 func (h *ConfigHandler) RollbackConfig(c *gin.Context) {
-	projectID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+	projectID, _, ok := h.authorizeProject(c, model.ProjectRoleMaintainer)
+	if !ok {
 		return
 	}
 
@@ -165,11 +204,14 @@ func (h *ConfigHandler) RollbackConfig(c *gin.Context) {
 	}
 
 	if err := h.configService.RollbackToVersion(c.Request.Context(), projectID, version); err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, service.ErrProjectNotFound):
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-			return
+		case errors.Is(err, service.ErrProjectArchived):
+			c.JSON(http.StatusConflict, gin.H{"error": "project is archived"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -190,4 +232,9 @@ type CreateConfigRequest struct {
 	SystemPrompt   *string     `json:"system_prompt,omitempty"`
 	MaxIterations  int         `json:"max_iterations" binding:"min=1,max=50"`
 	TimeoutSeconds int         `json:"timeout_seconds" binding:"min=60,max=3600"`
+
+	// AI review settings; ai_review_enabled defaults to model.DefaultAIReviewEnabled when omitted
+	AIReviewEnabled    *bool   `json:"ai_review_enabled,omitempty"`
+	ReviewModelName    *string `json:"review_model_name,omitempty"`
+	ReviewSystemPrompt *string `json:"review_system_prompt,omitempty"`
 }
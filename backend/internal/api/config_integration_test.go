@@ -52,7 +52,7 @@ func setupConfigIntegrationTest(t *testing.T) (*gorm.DB, *service.ConfigService,
 
 	// Initialize repository and service
 	configRepo := repository.NewConfigRepository(db)
-	configService, err := service.NewConfigService(configRepo, encryptionKey)
+	configService, err := service.NewConfigService(configRepo, repository.NewProjectRepository(db), encryptionKey)
 	require.NoError(t, err, "Failed to create ConfigService")
 
 	// Cleanup function
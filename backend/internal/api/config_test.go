@@ -16,6 +16,7 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/npinot/vibe/backend/internal/model"
+	"github.com/npinot/vibe/backend/internal/service"
 )
 
 type MockConfigService struct {
@@ -53,6 +54,15 @@ func (m *MockConfigService) GetDecryptedAPIKey(ctx context.Context, projectID uu
 	return args.Get(0).(string), args.Error(1)
 }
 
+// ownerAuthorizer authorizes the test user as the owner of every project
+func ownerAuthorizer() service.AuthorizationService {
+	projectRepo := new(MockProjectRepo)
+	projectRepo.On("FindByID", mock.Anything, mock.Anything).Return(&model.Project{
+		UserID: uuid.MustParse("11111111-1111-1111-1111-111111111111"),
+	}, nil)
+	return service.NewAuthorizationService(projectRepo, nil)
+}
+
 func setupConfigTestRouter(handler *ConfigHandler) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
@@ -71,7 +81,7 @@ func setupConfigTestRouter(handler *ConfigHandler) *gin.Engine {
 
 func TestConfigHandler_GetActiveConfig(t *testing.T) {
 	mockService := new(MockConfigService)
-	handler := NewConfigHandler(mockService)
+	handler := NewConfigHandler(mockService, ownerAuthorizer())
 	router := setupConfigTestRouter(handler)
 
 	router.GET("/projects/:id/config", handler.GetActiveConfig)
@@ -156,7 +166,7 @@ func TestConfigHandler_GetActiveConfig(t *testing.T) {
 
 func TestConfigHandler_CreateOrUpdateConfig(t *testing.T) {
 	mockService := new(MockConfigService)
-	handler := NewConfigHandler(mockService)
+	handler := NewConfigHandler(mockService, ownerAuthorizer())
 	router := setupConfigTestRouter(handler)
 
 	router.POST("/projects/:id/config", handler.CreateOrUpdateConfig)
@@ -188,6 +198,37 @@ func TestConfigHandler_CreateOrUpdateConfig(t *testing.T) {
 		err := json.Unmarshal(w.Body.Bytes(), &resp)
 		assert.NoError(t, err)
 		assert.Equal(t, "gpt-4o-mini", resp.ModelName)
+		assert.Equal(t, model.DefaultAIReviewEnabled, resp.AIReviewEnabled)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("AI review disabled explicitly", func(t *testing.T) {
+		projectID := uuid.New()
+		disabled := false
+
+		reqBody := CreateConfigRequest{
+			ModelProvider:   "openai",
+			ModelName:       "gpt-4o-mini",
+			Temperature:     0.7,
+			MaxTokens:       4096,
+			EnabledTools:    []string{"file_ops"},
+			MaxIterations:   10,
+			TimeoutSeconds:  300,
+			AIReviewEnabled: &disabled,
+		}
+
+		mockService.On("CreateOrUpdateConfig", mock.Anything, mock.MatchedBy(func(config *model.OpenCodeConfig) bool {
+			return !config.AIReviewEnabled
+		}), "").Return(nil).Once()
+
+		body, _ := json.Marshal(reqBody)
+		req, _ := http.NewRequest("POST", "/projects/"+projectID.String()+"/config", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
 
 		mockService.AssertExpectations(t)
 	})
@@ -455,7 +496,7 @@ func TestConfigHandler_CreateOrUpdateConfig(t *testing.T) {
 
 func TestConfigHandler_GetConfigHistory(t *testing.T) {
 	mockService := new(MockConfigService)
-	handler := NewConfigHandler(mockService)
+	handler := NewConfigHandler(mockService, ownerAuthorizer())
 	router := setupConfigTestRouter(handler)
 
 	router.GET("/projects/:id/config/versions", handler.GetConfigHistory)
@@ -573,7 +614,7 @@ func TestConfigHandler_GetConfigHistory(t *testing.T) {
 
 func TestConfigHandler_RollbackConfig(t *testing.T) {
 	mockService := new(MockConfigService)
-	handler := NewConfigHandler(mockService)
+	handler := NewConfigHandler(mockService, ownerAuthorizer())
 	router := setupConfigTestRouter(handler)
 
 	router.POST("/projects/:id/config/rollback/:version", handler.RollbackConfig)
@@ -669,7 +710,7 @@ func TestConfigHandler_RollbackConfig(t *testing.T) {
 
 func TestConfigHandler_AuthenticationRequired(t *testing.T) {
 	mockService := new(MockConfigService)
-	handler := NewConfigHandler(mockService)
+	handler := NewConfigHandler(mockService, ownerAuthorizer())
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
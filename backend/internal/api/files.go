@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -14,47 +15,66 @@ import (
 	"github.com/gorilla/websocket"
 
 	"github.com/npinot/vibe/backend/internal/middleware"
-	"github.com/npinot/vibe/backend/internal/repository"
+	"github.com/npinot/vibe/backend/internal/model"
 	"github.com/npinot/vibe/backend/internal/service"
 )
 
 // FileHandler handles file-related HTTP requests by proxying to the file-browser sidecar
 type FileHandler struct {
-	projectRepo repository.ProjectRepository
-	k8sService  service.KubernetesService
-	httpClient  *http.Client
-	sidecarPort int
+	authz      service.AuthorizationService
+	k8sService service.KubernetesService
+	httpClient *http.Client
+	// gitHTTPClient allows for slow git operations such as pushing to a remote
+	gitHTTPClient *http.Client
+	sidecarPort   int
 }
 
 // NewFileHandler creates a new file handler
-func NewFileHandler(projectRepo repository.ProjectRepository, k8sService service.KubernetesService) *FileHandler {
+func NewFileHandler(authz service.AuthorizationService, k8sService service.KubernetesService) *FileHandler {
 	return &FileHandler{
-		projectRepo: projectRepo,
-		k8sService:  k8sService,
+		authz:      authz,
+		k8sService: k8sService,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		gitHTTPClient: &http.Client{
+			Timeout: 3 * time.Minute,
+		},
 		sidecarPort: 3001,
 	}
 }
 
-// getSidecarURL resolves the file-browser sidecar URL for a given project
-func (h *FileHandler) getSidecarURL(ctx context.Context, projectID uuid.UUID, userID uuid.UUID) (string, error) {
-	project, err := h.projectRepo.FindByID(ctx, projectID)
-	if err != nil {
-		return "", fmt.Errorf("failed to find project: %w", err)
-	}
+// getSidecarURL resolves the file-browser sidecar URL for a project the user holds at least role on
+func (h *FileHandler) getSidecarURL(ctx context.Context, projectID uuid.UUID, userID uuid.UUID, role model.ProjectRole) (string, error) {
+	_, sidecarURL, err := h.getProjectSidecar(ctx, projectID, userID, role)
+	return sidecarURL, err
+}
 
-	if project.UserID != userID {
-		return "", fmt.Errorf("unauthorized: user does not own project")
+// getProjectSidecar authorizes the project like getSidecarURL and returns it with its sidecar URL
+func (h *FileHandler) getProjectSidecar(ctx context.Context, projectID uuid.UUID, userID uuid.UUID, role model.ProjectRole) (*model.Project, string, error) {
+	project, err := h.authz.AuthorizeProject(ctx, projectID, userID, role)
+	if err != nil {
+		return nil, "", err
 	}
 
 	podIP, err := h.k8sService.GetPodIP(ctx, project.PodName, project.PodNamespace)
 	if err != nil {
-		return "", fmt.Errorf("failed to get pod IP: %w", err)
+		return nil, "", fmt.Errorf("failed to get pod IP: %w", err)
 	}
 
-	return fmt.Sprintf("http://%s:%d", podIP, h.sidecarPort), nil
+	return project, fmt.Sprintf("http://%s:%d", podIP, h.sidecarPort), nil
+}
+
+// respondSidecarError writes the response for a sidecar URL that could not be resolved
+func respondSidecarError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrProjectNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+	case errors.Is(err, service.ErrUnauthorized):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
 }
 
 func (h *FileHandler) GetTree(c *gin.Context) {
@@ -70,9 +90,9 @@ func (h *FileHandler) GetTree(c *gin.Context) {
 		return
 	}
 
-	sidecarURL, err := h.getSidecarURL(c.Request.Context(), projectID, userID)
+	sidecarURL, err := h.getSidecarURL(c.Request.Context(), projectID, userID, model.ProjectRoleViewer)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondSidecarError(c, err)
 		return
 	}
 
@@ -118,9 +138,9 @@ func (h *FileHandler) GetContent(c *gin.Context) {
 		return
 	}
 
-	sidecarURL, err := h.getSidecarURL(c.Request.Context(), projectID, userID)
+	sidecarURL, err := h.getSidecarURL(c.Request.Context(), projectID, userID, model.ProjectRoleViewer)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondSidecarError(c, err)
 		return
 	}
 
@@ -163,9 +183,9 @@ func (h *FileHandler) GetFileInfo(c *gin.Context) {
 		return
 	}
 
-	sidecarURL, err := h.getSidecarURL(c.Request.Context(), projectID, userID)
+	sidecarURL, err := h.getSidecarURL(c.Request.Context(), projectID, userID, model.ProjectRoleViewer)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondSidecarError(c, err)
 		return
 	}
 
@@ -208,9 +228,9 @@ func (h *FileHandler) WriteFile(c *gin.Context) {
 		return
 	}
 
-	sidecarURL, err := h.getSidecarURL(c.Request.Context(), projectID, userID)
+	sidecarURL, err := h.getSidecarURL(c.Request.Context(), projectID, userID, model.ProjectRoleMaintainer)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondSidecarError(c, err)
 		return
 	}
 
@@ -260,9 +280,9 @@ func (h *FileHandler) DeleteFile(c *gin.Context) {
 		return
 	}
 
-	sidecarURL, err := h.getSidecarURL(c.Request.Context(), projectID, userID)
+	sidecarURL, err := h.getSidecarURL(c.Request.Context(), projectID, userID, model.ProjectRoleMaintainer)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondSidecarError(c, err)
 		return
 	}
 
@@ -305,9 +325,9 @@ func (h *FileHandler) CreateDirectory(c *gin.Context) {
 		return
 	}
 
-	sidecarURL, err := h.getSidecarURL(c.Request.Context(), projectID, userID)
+	sidecarURL, err := h.getSidecarURL(c.Request.Context(), projectID, userID, model.ProjectRoleMaintainer)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondSidecarError(c, err)
 		return
 	}
 
@@ -351,12 +371,15 @@ func (h *FileHandler) FileChangesStream(c *gin.Context) {
 		return
 	}
 
-	sidecarURL, err := h.getSidecarURL(c.Request.Context(), projectID, userID)
+	project, sidecarURL, err := h.getProjectSidecar(c.Request.Context(), projectID, userID, model.ProjectRoleViewer)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondSidecarError(c, err)
 		return
 	}
 
+	// The pod stays awake while changes are being watched
+	defer service.HoldPod(h.k8sService, project.PodName, project.PodNamespace)()
+
 	// Upgrade client connection to WebSocket
 	upgrader := websocket.Upgrader{
 		CheckOrigin: func(r *http.Request) bool {
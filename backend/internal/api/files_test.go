@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -46,6 +47,14 @@ func (m *MockFileProjectRepository) FindByUserID(ctx context.Context, userID uui
 	return args.Get(0).([]model.Project), args.Error(1)
 }
 
+func (m *MockFileProjectRepository) FindSharedWithUser(ctx context.Context, userID uuid.UUID) ([]model.Project, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Project), args.Error(1)
+}
+
 func (m *MockFileProjectRepository) Update(ctx context.Context, project *model.Project) error {
 	args := m.Called(ctx, project)
 	return args.Error(0)
@@ -61,6 +70,35 @@ func (m *MockFileProjectRepository) UpdatePodStatus(ctx context.Context, id uuid
 	return args.Error(0)
 }
 
+func (m *MockFileProjectRepository) FindByPodName(ctx context.Context, podName, namespace string) (*model.Project, error) {
+	args := m.Called(ctx, podName, namespace)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Project), args.Error(1)
+}
+
+func (m *MockFileProjectRepository) FindIdleProjects(ctx context.Context, idleSince time.Time) ([]model.Project, error) {
+	args := m.Called(ctx, idleSince)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Project), args.Error(1)
+}
+
+func (m *MockFileProjectRepository) UpdateLastActivity(ctx context.Context, podName, namespace string, at time.Time) error {
+	args := m.Called(ctx, podName, namespace, at)
+	return args.Error(0)
+}
+
+func (m *MockFileProjectRepository) FindRunningProjects(ctx context.Context) ([]model.Project, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Project), args.Error(1)
+}
+
 var _ repository.ProjectRepository = (*MockFileProjectRepository)(nil)
 
 type MockFileK8sService struct {
@@ -72,6 +110,16 @@ func (m *MockFileK8sService) CreateProjectPod(ctx context.Context, project *mode
 	return args.Error(0)
 }
 
+func (m *MockFileK8sService) CreateEmptyProjectPod(ctx context.Context, project *model.Project) error {
+	args := m.Called(ctx, project)
+	return args.Error(0)
+}
+
+func (m *MockFileK8sService) CreateForkedProjectPod(ctx context.Context, project, source *model.Project) error {
+	args := m.Called(ctx, project, source)
+	return args.Error(0)
+}
+
 func (m *MockFileK8sService) DeleteProjectPod(ctx context.Context, podName, namespace string) error {
 	args := m.Called(ctx, podName, namespace)
 	return args.Error(0)
@@ -82,12 +130,12 @@ func (m *MockFileK8sService) GetPodStatus(ctx context.Context, podName, namespac
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockFileK8sService) WatchPodStatus(ctx context.Context, podName, namespace string) (<-chan string, error) {
+func (m *MockFileK8sService) WatchPodStatus(ctx context.Context, podName, namespace string) (<-chan service.PodStatusUpdate, error) {
 	args := m.Called(ctx, podName, namespace)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(<-chan string), args.Error(1)
+	return args.Get(0).(<-chan service.PodStatusUpdate), args.Error(1)
 }
 
 func (m *MockFileK8sService) GetPodIP(ctx context.Context, podName, namespace string) (string, error) {
@@ -95,6 +143,73 @@ func (m *MockFileK8sService) GetPodIP(ctx context.Context, podName, namespace st
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockFileK8sService) GetRepoCloneStatus(ctx context.Context, podName, namespace string) (*service.RepoCloneStatus, error) {
+	args := m.Called(ctx, podName, namespace)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.RepoCloneStatus), args.Error(1)
+}
+
+func (m *MockFileK8sService) StopProjectPod(ctx context.Context, podName, namespace string) error {
+	args := m.Called(ctx, podName, namespace)
+	return args.Error(0)
+}
+
+func (m *MockFileK8sService) StartProjectPod(ctx context.Context, project *model.Project) error {
+	args := m.Called(ctx, project)
+	return args.Error(0)
+}
+
+func (m *MockFileK8sService) WaitForPodReady(ctx context.Context, podName, namespace string) (string, error) {
+	args := m.Called(ctx, podName, namespace)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockFileK8sService) ResizeProjectResources(ctx context.Context, project *model.Project) error {
+	args := m.Called(ctx, project)
+	return args.Error(0)
+}
+
+func (m *MockFileK8sService) StreamContainerLogs(ctx context.Context, podName, namespace string, opts service.ContainerLogOptions) (io.ReadCloser, error) {
+	args := m.Called(ctx, podName, namespace, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func (m *MockFileK8sService) CheckWorkspaceSnapshots(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockFileK8sService) CreateWorkspaceSnapshot(ctx context.Context, project *model.Project, taskID *uuid.UUID) (*service.WorkspaceSnapshot, error) {
+	args := m.Called(ctx, project, taskID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.WorkspaceSnapshot), args.Error(1)
+}
+
+func (m *MockFileK8sService) ListWorkspaceSnapshots(ctx context.Context, project *model.Project) ([]service.WorkspaceSnapshot, error) {
+	args := m.Called(ctx, project)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]service.WorkspaceSnapshot), args.Error(1)
+}
+
+func (m *MockFileK8sService) DeleteWorkspaceSnapshot(ctx context.Context, project *model.Project, name string) error {
+	args := m.Called(ctx, project, name)
+	return args.Error(0)
+}
+
+func (m *MockFileK8sService) RestoreWorkspaceSnapshot(ctx context.Context, project *model.Project, name string, save func(*model.Project) error) error {
+	args := m.Called(ctx, project, name, save)
+	return args.Error(0)
+}
+
 var _ service.KubernetesService = (*MockFileK8sService)(nil)
 
 func setupFileTestRouter(handler *FileHandler) *gin.Engine {
@@ -128,10 +243,10 @@ func TestNewFileHandler(t *testing.T) {
 	mockRepo := new(MockFileProjectRepository)
 	mockK8s := new(MockFileK8sService)
 
-	handler := NewFileHandler(mockRepo, mockK8s)
+	handler := NewFileHandler(service.NewAuthorizationService(mockRepo, nil), mockK8s)
 
 	assert.NotNil(t, handler)
-	assert.NotNil(t, handler.projectRepo)
+	assert.NotNil(t, handler.authz)
 	assert.NotNil(t, handler.k8sService)
 	assert.NotNil(t, handler.httpClient)
 }
@@ -193,8 +308,8 @@ func TestFileHandler_GetTree(t *testing.T) {
 				}
 				repo.On("FindByID", mock.Anything, uuid.MustParse("00000000-0000-0000-0000-000000000002")).Return(project, nil)
 			},
-			expectedStatus: http.StatusInternalServerError,
-			expectedBody:   "unauthorized",
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "Access denied",
 		},
 		{
 			name:      "pod IP not found",
@@ -228,7 +343,7 @@ func TestFileHandler_GetTree(t *testing.T) {
 
 			tt.mockSetup(mockRepo, mockK8s, sidecarServer)
 
-			handler := NewFileHandler(mockRepo, mockK8s)
+			handler := NewFileHandler(service.NewAuthorizationService(mockRepo, nil), mockK8s)
 			addr := sidecarServer.Listener.Addr().(*net.TCPAddr)
 			handler.sidecarPort = addr.Port
 			router := setupFileTestRouter(handler)
@@ -297,8 +412,8 @@ func TestFileHandler_GetContent(t *testing.T) {
 				}
 				repo.On("FindByID", mock.Anything, uuid.MustParse("00000000-0000-0000-0000-000000000002")).Return(project, nil)
 			},
-			expectedStatus: http.StatusInternalServerError,
-			expectedBody:   "unauthorized",
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "Access denied",
 		},
 	}
 
@@ -315,7 +430,7 @@ func TestFileHandler_GetContent(t *testing.T) {
 
 			tt.mockSetup(mockRepo, mockK8s, sidecarServer)
 
-			handler := NewFileHandler(mockRepo, mockK8s)
+			handler := NewFileHandler(service.NewAuthorizationService(mockRepo, nil), mockK8s)
 			addr := sidecarServer.Listener.Addr().(*net.TCPAddr)
 			handler.sidecarPort = addr.Port
 			router := setupFileTestRouter(handler)
@@ -386,7 +501,7 @@ func TestFileHandler_GetFileInfo(t *testing.T) {
 
 			tt.mockSetup(mockRepo, mockK8s, sidecarServer)
 
-			handler := NewFileHandler(mockRepo, mockK8s)
+			handler := NewFileHandler(service.NewAuthorizationService(mockRepo, nil), mockK8s)
 			addr := sidecarServer.Listener.Addr().(*net.TCPAddr)
 			handler.sidecarPort = addr.Port
 			router := setupFileTestRouter(handler)
@@ -460,8 +575,8 @@ func TestFileHandler_WriteFile(t *testing.T) {
 				}
 				repo.On("FindByID", mock.Anything, uuid.MustParse("00000000-0000-0000-0000-000000000002")).Return(project, nil)
 			},
-			expectedStatus: http.StatusInternalServerError,
-			expectedBody:   "unauthorized",
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "Access denied",
 		},
 	}
 
@@ -478,7 +593,7 @@ func TestFileHandler_WriteFile(t *testing.T) {
 
 			tt.mockSetup(mockRepo, mockK8s, sidecarServer)
 
-			handler := NewFileHandler(mockRepo, mockK8s)
+			handler := NewFileHandler(service.NewAuthorizationService(mockRepo, nil), mockK8s)
 			addr := sidecarServer.Listener.Addr().(*net.TCPAddr)
 			handler.sidecarPort = addr.Port
 			router := setupFileTestRouter(handler)
@@ -558,7 +673,7 @@ func TestFileHandler_DeleteFile(t *testing.T) {
 
 			tt.mockSetup(mockRepo, mockK8s, sidecarServer)
 
-			handler := NewFileHandler(mockRepo, mockK8s)
+			handler := NewFileHandler(service.NewAuthorizationService(mockRepo, nil), mockK8s)
 			addr := sidecarServer.Listener.Addr().(*net.TCPAddr)
 			handler.sidecarPort = addr.Port
 			router := setupFileTestRouter(handler)
@@ -630,7 +745,7 @@ func TestFileHandler_CreateDirectory(t *testing.T) {
 
 			tt.mockSetup(mockRepo, mockK8s, sidecarServer)
 
-			handler := NewFileHandler(mockRepo, mockK8s)
+			handler := NewFileHandler(service.NewAuthorizationService(mockRepo, nil), mockK8s)
 			addr := sidecarServer.Listener.Addr().(*net.TCPAddr)
 			handler.sidecarPort = addr.Port
 			router := setupFileTestRouter(handler)
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/npinot/vibe/backend/internal/service"
+)
+
+// ForkHandler creates projects from the workspace of an existing one
+type ForkHandler struct {
+	forkService service.ProjectForkService
+}
+
+func NewForkHandler(forkService service.ProjectForkService) *ForkHandler {
+	return &ForkHandler{
+		forkService: forkService,
+	}
+}
+
+// ForkProjectRequest is the optional body of a fork request
+type ForkProjectRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	CopyTasks   bool   `json:"copy_tasks"`
+}
+
+// ForkProject creates a project whose workspace is cloned from the project, with a copy of its
+// active configuration and optionally of its tasks, reset to todo
+// POST /api/projects/:id/fork
+func (h *ForkHandler) ForkProject(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	// All fields are optional, so is the body
+	var req ForkProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	fork, err := h.forkService.ForkProject(c.Request.Context(), projectID, userID, service.ForkOptions{
+		Name:        req.Name,
+		Description: req.Description,
+		CopyTasks:   req.CopyTasks,
+	})
+	if err != nil {
+		respondForkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, fork)
+}
+
+func respondForkError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrProjectNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+	case errors.Is(err, service.ErrUnauthorized):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+	case errors.Is(err, service.ErrInvalidProjectName):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrProjectArchived),
+		errors.Is(err, service.ErrProjectPodMissing):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrResourceCapExceeded),
+		errors.Is(err, service.ErrInvalidResourceCap):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		log.Printf("[Fork] Failed to fork project: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fork project"})
+	}
+}
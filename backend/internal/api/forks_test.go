@@ -0,0 +1,127 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/npinot/vibe/backend/internal/model"
+	"github.com/npinot/vibe/backend/internal/service"
+)
+
+type MockForkService struct {
+	mock.Mock
+}
+
+func (m *MockForkService) ForkProject(ctx context.Context, projectID, userID uuid.UUID, opts service.ForkOptions) (*model.Project, error) {
+	args := m.Called(ctx, projectID, userID, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Project), args.Error(1)
+}
+
+func setupForkTestRouter() (*MockForkService, http.Handler) {
+	mockService := new(MockForkService)
+	handler := NewForkHandler(mockService)
+	router := setupProjectTestRouter(nil)
+	router.POST("/projects/:id/fork", handler.ForkProject)
+	return mockService, router
+}
+
+func TestForkHandler(t *testing.T) {
+	userID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	projectID := uuid.New()
+	forkID := uuid.New()
+
+	t.Run("forks project", func(t *testing.T) {
+		mockService, router := setupForkTestRouter()
+		opts := service.ForkOptions{Name: "Experiment", Description: "Trying things", CopyTasks: true}
+		mockService.On("ForkProject", mock.Anything, projectID, userID, opts).
+			Return(&model.Project{ID: forkID, UserID: userID, Name: "Experiment", ParentProjectID: &projectID, Status: model.ProjectStatusReady}, nil)
+
+		body := `{"name":"Experiment","description":"Trying things","copy_tasks":true}`
+		req, _ := http.NewRequest("POST", "/projects/"+projectID.String()+"/fork", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, forkID.String(), response["id"])
+		assert.Equal(t, projectID.String(), response["parent_project_id"])
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("empty body uses defaults", func(t *testing.T) {
+		mockService, router := setupForkTestRouter()
+		mockService.On("ForkProject", mock.Anything, projectID, userID, service.ForkOptions{}).
+			Return(&model.Project{ID: forkID, UserID: userID, ParentProjectID: &projectID}, nil)
+
+		req, _ := http.NewRequest("POST", "/projects/"+projectID.String()+"/fork", strings.NewReader(""))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		mockService, router := setupForkTestRouter()
+
+		req, _ := http.NewRequest("POST", "/projects/"+projectID.String()+"/fork", strings.NewReader(`{"copy_tasks":"yes"}`))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "ForkProject", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid project ID", func(t *testing.T) {
+		_, router := setupForkTestRouter()
+
+		req, _ := http.NewRequest("POST", "/projects/not-a-uuid/fork", strings.NewReader(""))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	errorCases := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"project not found", service.ErrProjectNotFound, http.StatusNotFound},
+		{"unauthorized", service.ErrUnauthorized, http.StatusForbidden},
+		{"invalid name", fmt.Errorf("%w: name cannot exceed 100 characters", service.ErrInvalidProjectName), http.StatusBadRequest},
+		{"archived", service.ErrProjectArchived, http.StatusConflict},
+		{"pod missing", fmt.Errorf("%w: the workspace was never created", service.ErrProjectPodMissing), http.StatusConflict},
+		{"pod creation failed", fmt.Errorf("%w: clone not supported", service.ErrPodCreationFailed), http.StatusInternalServerError},
+		{"internal error", errors.New("boom"), http.StatusInternalServerError},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService, router := setupForkTestRouter()
+			mockService.On("ForkProject", mock.Anything, projectID, userID, service.ForkOptions{}).Return(nil, tc.err)
+
+			req, _ := http.NewRequest("POST", "/projects/"+projectID.String()+"/fork", strings.NewReader(""))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.wantStatus, w.Code)
+		})
+	}
+}
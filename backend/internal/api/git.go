@@ -0,0 +1,173 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/npinot/vibe/backend/internal/middleware"
+	"github.com/npinot/vibe/backend/internal/model"
+)
+
+// GitStatus proxies GET /git/status to the sidecar
+func (h *FileHandler) GitStatus(c *gin.Context) {
+	h.proxyGitRequest(c, "GET", "/git/status", nil)
+}
+
+// GitDiff proxies GET /git/diff to the sidecar
+func (h *FileHandler) GitDiff(c *gin.Context) {
+	query := url.Values{}
+	if path := c.Query("path"); path != "" {
+		query.Set("path", path)
+	}
+	if staged := c.Query("staged"); staged != "" {
+		query.Set("staged", staged)
+	}
+
+	path := "/git/diff"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	h.proxyGitRequest(c, "GET", path, nil)
+}
+
+// GitCommit proxies POST /git/commit to the sidecar, using the authenticated
+// user as commit author
+func (h *FileHandler) GitCommit(c *gin.Context) {
+	user, err := middleware.GetCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req struct {
+		Message string   `json:"message" binding:"required"`
+		Paths   []string `json:"paths"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// The author is never taken from the request body
+	authorName := user.Name
+	if authorName == "" {
+		authorName = user.Email
+	}
+
+	h.proxyGitRequest(c, "POST", "/git/commit", gin.H{
+		"message":      req.Message,
+		"paths":        req.Paths,
+		"author_name":  authorName,
+		"author_email": user.Email,
+	})
+}
+
+// GitCreateBranch proxies POST /git/branch to the sidecar
+func (h *FileHandler) GitCreateBranch(c *gin.Context) {
+	var req struct {
+		Name       string `json:"name" binding:"required"`
+		StartPoint string `json:"start_point"`
+		Checkout   bool   `json:"checkout"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.proxyGitRequest(c, "POST", "/git/branch", req)
+}
+
+// GitCheckout proxies POST /git/checkout to the sidecar
+func (h *FileHandler) GitCheckout(c *gin.Context) {
+	var req struct {
+		Branch string `json:"branch" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.proxyGitRequest(c, "POST", "/git/checkout", req)
+}
+
+// GitPush proxies POST /git/push to the sidecar
+func (h *FileHandler) GitPush(c *gin.Context) {
+	var req struct {
+		Remote string `json:"remote"`
+		Branch string `json:"branch"`
+	}
+	// An empty body pushes the current branch to origin
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	h.proxyGitRequest(c, "POST", "/git/push", req)
+}
+
+// proxyGitRequest resolves the project sidecar and forwards a git request,
+// copying the sidecar response back to the client
+func (h *FileHandler) proxyGitRequest(c *gin.Context, method, path string, body interface{}) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	userID := middleware.GetCurrentUserID(c)
+	if userID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// Reading the repository is enough for viewers; everything else changes it
+	role := model.ProjectRoleMaintainer
+	if method == http.MethodGet {
+		role = model.ProjectRoleViewer
+	}
+
+	sidecarURL, err := h.getSidecarURL(c.Request.Context(), projectID, userID, role)
+	if err != nil {
+		respondSidecarError(c, err)
+		return
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		jsonData, err := json.Marshal(body)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to marshal request"})
+			return
+		}
+		reqBody = bytes.NewBuffer(jsonData)
+	}
+
+	req, err := http.NewRequestWithContext(c.Request.Context(), method, fmt.Sprintf("%s%s", sidecarURL, path), reqBody)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create request"})
+		return
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := h.gitHTTPClient.Do(req)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to reach file-browser sidecar"})
+		return
+	}
+	defer resp.Body.Close()
+
+	c.Status(resp.StatusCode)
+	io.Copy(c.Writer, resp.Body)
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/npinot/vibe/backend/internal/model"
+	"github.com/npinot/vibe/backend/internal/service"
+)
+
+func setupGitTestHandler(t *testing.T, sidecar http.HandlerFunc) (*FileHandler, *MockFileProjectRepository, *MockFileK8sService) {
+	t.Helper()
+
+	server := httptest.NewServer(sidecar)
+	t.Cleanup(server.Close)
+
+	mockRepo := new(MockFileProjectRepository)
+	mockK8s := new(MockFileK8sService)
+
+	project := &model.Project{
+		ID:           uuid.MustParse("00000000-0000-0000-0000-000000000002"),
+		UserID:       uuid.MustParse("00000000-0000-0000-0000-000000000001"),
+		PodName:      "test-pod",
+		PodNamespace: "test-ns",
+	}
+	addr := server.Listener.Addr().(*net.TCPAddr)
+	mockRepo.On("FindByID", mock.Anything, project.ID).Return(project, nil)
+	mockK8s.On("GetPodIP", mock.Anything, "test-pod", "test-ns").Return(addr.IP.String(), nil)
+
+	handler := NewFileHandler(service.NewAuthorizationService(mockRepo, nil), mockK8s)
+	handler.sidecarPort = addr.Port
+
+	return handler, mockRepo, mockK8s
+}
+
+func setupGitTestRouter(handler *FileHandler) http.Handler {
+	router := setupFileTestRouter(handler)
+	projects := router.Group("/api/projects")
+	{
+		projects.GET("/:id/git/status", handler.GitStatus)
+		projects.GET("/:id/git/diff", handler.GitDiff)
+		projects.POST("/:id/git/commit", handler.GitCommit)
+		projects.POST("/:id/git/branch", handler.GitCreateBranch)
+		projects.POST("/:id/git/checkout", handler.GitCheckout)
+		projects.POST("/:id/git/push", handler.GitPush)
+	}
+	return router
+}
+
+func TestFileHandler_GitStatus(t *testing.T) {
+	handler, mockRepo, mockK8s := setupGitTestHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "/git/status", r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"branch":"main","clean":true}`))
+	})
+	router := setupGitTestRouter(handler)
+
+	req := httptest.NewRequest("GET", "/api/projects/00000000-0000-0000-0000-000000000002/git/status", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"branch":"main"`)
+	mockRepo.AssertExpectations(t)
+	mockK8s.AssertExpectations(t)
+}
+
+func TestFileHandler_GitDiff(t *testing.T) {
+	handler, _, _ := setupGitTestHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/git/diff", r.URL.Path)
+		assert.Equal(t, "src/main.go", r.URL.Query().Get("path"))
+		assert.Equal(t, "true", r.URL.Query().Get("staged"))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"diff":""}`))
+	})
+	router := setupGitTestRouter(handler)
+
+	req := httptest.NewRequest("GET", "/api/projects/00000000-0000-0000-0000-000000000002/git/diff?path=src/main.go&staged=true", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestFileHandler_GitCommit(t *testing.T) {
+	t.Run("author taken from authenticated user", func(t *testing.T) {
+		var received map[string]interface{}
+		handler, _, _ := setupGitTestHandler(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "POST", r.Method)
+			assert.Equal(t, "/git/commit", r.URL.Path)
+			json.NewDecoder(r.Body).Decode(&received)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"sha":"abc123","branch":"main"}`))
+		})
+		router := setupGitTestRouter(handler)
+
+		body, _ := json.Marshal(map[string]interface{}{
+			"message":      "Fix bug",
+			"paths":        []string{"main.go"},
+			"author_name":  "Mallory",
+			"author_email": "mallory@example.com",
+		})
+		req := httptest.NewRequest("POST", "/api/projects/00000000-0000-0000-0000-000000000002/git/commit", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "Fix bug", received["message"])
+		assert.Equal(t, []interface{}{"main.go"}, received["paths"])
+		// The test user has no name, so the email is used for both fields
+		assert.Equal(t, "test@example.com", received["author_name"])
+		assert.Equal(t, "test@example.com", received["author_email"])
+	})
+
+	t.Run("missing message", func(t *testing.T) {
+		handler := NewFileHandler(service.NewAuthorizationService(new(MockFileProjectRepository), nil), new(MockFileK8sService))
+		router := setupGitTestRouter(handler)
+
+		req := httptest.NewRequest("POST", "/api/projects/00000000-0000-0000-0000-000000000002/git/commit", bytes.NewBufferString(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestFileHandler_GitCreateBranch(t *testing.T) {
+	var received map[string]interface{}
+	handler, _, _ := setupGitTestHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/git/branch", r.URL.Path)
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusCreated)
+	})
+	router := setupGitTestRouter(handler)
+
+	req := httptest.NewRequest("POST", "/api/projects/00000000-0000-0000-0000-000000000002/git/branch", bytes.NewBufferString(`{"name":"feature","checkout":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "feature", received["name"])
+	assert.Equal(t, true, received["checkout"])
+}
+
+func TestFileHandler_GitCheckout(t *testing.T) {
+	handler, _, _ := setupGitTestHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"error":"Uncommitted changes would be overwritten by checkout"}`))
+	})
+	router := setupGitTestRouter(handler)
+
+	req := httptest.NewRequest("POST", "/api/projects/00000000-0000-0000-0000-000000000002/git/checkout", bytes.NewBufferString(`{"branch":"main"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Sidecar errors are passed through unchanged
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "Uncommitted changes")
+}
+
+func TestFileHandler_GitPush(t *testing.T) {
+	handler, _, _ := setupGitTestHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/git/push", r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"remote":"origin","branch":"main"}`))
+	})
+	router := setupGitTestRouter(handler)
+
+	req := httptest.NewRequest("POST", "/api/projects/00000000-0000-0000-0000-000000000002/git/push", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "origin")
+}
@@ -20,13 +20,15 @@ import (
 
 type InteractionHandler struct {
 	interactionService service.InteractionService
+	taskService        service.TaskService // delivers chat messages to running agents; nil only stores them
 	upgrader           websocket.Upgrader
 	sessions           *sessionManager
 }
 
-func NewInteractionHandler(interactionService service.InteractionService) *InteractionHandler {
+func NewInteractionHandler(interactionService service.InteractionService, taskService service.TaskService) *InteractionHandler {
 	return &InteractionHandler{
 		interactionService: interactionService,
+		taskService:        taskService,
 		upgrader: websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool {
 				return true
@@ -99,22 +101,21 @@ func (h *InteractionHandler) TaskInteractionWebSocket(c *gin.Context) {
 		return
 	}
 
-	taskIDParam := c.Param("id")
-	taskID, err := uuid.Parse(taskIDParam)
+	taskID, err := uuid.Parse(interactionTaskIDParam(c))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
 		return
 	}
 
 	ctx := c.Request.Context()
-	if err := h.interactionService.ValidateTaskOwnership(ctx, taskID, user.ID); err != nil {
+	if err := h.interactionService.ValidateTaskAccess(ctx, taskID, user.ID); err != nil {
 		switch {
 		case errors.Is(err, service.ErrTaskNotFound):
 			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
 		case errors.Is(err, service.ErrTaskNotOwnedByUser):
 			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
 		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate task ownership"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate task access"})
 		}
 		return
 	}
@@ -191,7 +192,24 @@ func (h *InteractionHandler) TaskInteractionWebSocket(c *gin.Context) {
 	}
 }
 
+// interactionTaskIDParam returns the task ID of project-scoped routes (/projects/:id/tasks/:taskId/...)
+// and of routes keyed by the task alone
+func interactionTaskIDParam(c *gin.Context) string {
+	if taskID := c.Param("taskId"); taskID != "" {
+		return taskID
+	}
+	return c.Param("id")
+}
+
 func (h *InteractionHandler) handleUserMessage(ctx context.Context, taskID, userID uuid.UUID, msg WebSocketMessage) error {
+	session, err := h.activeAgentSession(ctx, taskID, userID)
+	if err != nil {
+		return err
+	}
+	if session != nil {
+		return h.deliverUserMessage(ctx, taskID, userID, session, msg)
+	}
+
 	metadataJSON := model.JSONB(msg.Metadata)
 
 	interaction, err := h.interactionService.CreateUserMessage(ctx, taskID, userID, msg.Content, metadataJSON)
@@ -210,6 +228,46 @@ func (h *InteractionHandler) handleUserMessage(ctx context.Context, taskID, user
 	return nil
 }
 
+// activeAgentSession returns the task's execution session whose agent can take chat messages, if any
+func (h *InteractionHandler) activeAgentSession(ctx context.Context, taskID, userID uuid.UUID) (*model.Session, error) {
+	if h.taskService == nil {
+		return nil, nil
+	}
+
+	sessions, err := h.taskService.GetTaskSessions(ctx, taskID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task sessions: %w", err)
+	}
+
+	for i := range sessions {
+		session := &sessions[i]
+		if session.Kind == model.SessionKindReview || session.RemoteSessionID == "" {
+			continue
+		}
+		if session.Status == model.SessionStatusRunning || session.Status == model.SessionStatusWaitingForInput {
+			return session, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// deliverUserMessage sends the message to the agent as a follow-up prompt, which also stores it,
+// and shows it in the other tabs open on the task
+func (h *InteractionHandler) deliverUserMessage(ctx context.Context, taskID, userID uuid.UUID, session *model.Session, msg WebSocketMessage) error {
+	if _, err := h.taskService.ContinueSession(ctx, taskID, session.ID, userID, msg.Content); err != nil {
+		return fmt.Errorf("failed to deliver message to agent: %w", err)
+	}
+
+	h.sessions.broadcast(taskID, WebSocketMessage{
+		Type:      service.MessageTypeUser,
+		Content:   msg.Content,
+		Timestamp: time.Now(),
+	})
+
+	return nil
+}
+
 func (h *InteractionHandler) sendError(conn *websocket.Conn, message string) {
 	errMsg := WebSocketMessage{
 		Type:      "error",
@@ -228,8 +286,7 @@ func (h *InteractionHandler) GetTaskHistory(c *gin.Context) {
 		return
 	}
 
-	taskIDParam := c.Param("id")
-	taskID, err := uuid.Parse(taskIDParam)
+	taskID, err := uuid.Parse(interactionTaskIDParam(c))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
 		return
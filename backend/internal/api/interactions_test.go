@@ -68,7 +68,7 @@ func (m *MockInteractionService) DeleteTaskHistory(ctx context.Context, taskID,
 	return args.Error(0)
 }
 
-func (m *MockInteractionService) ValidateTaskOwnership(ctx context.Context, taskID, userID uuid.UUID) error {
+func (m *MockInteractionService) ValidateTaskAccess(ctx context.Context, taskID, userID uuid.UUID) error {
 	args := m.Called(ctx, taskID, userID)
 	return args.Error(0)
 }
@@ -76,7 +76,7 @@ func (m *MockInteractionService) ValidateTaskOwnership(ctx context.Context, task
 func setupTestInteractionHandler() (*InteractionHandler, *MockInteractionService) {
 	gin.SetMode(gin.TestMode)
 	mockService := new(MockInteractionService)
-	handler := NewInteractionHandler(mockService)
+	handler := NewInteractionHandler(mockService, nil)
 	return handler, mockService
 }
 
@@ -323,7 +323,7 @@ func TestTaskInteractionWebSocket_TaskNotFound(t *testing.T) {
 	taskID := uuid.New()
 	userID := uuid.New()
 
-	mockService.On("ValidateTaskOwnership", mock.Anything, taskID, userID).Return(service.ErrTaskNotFound)
+	mockService.On("ValidateTaskAccess", mock.Anything, taskID, userID).Return(service.ErrTaskNotFound)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -343,7 +343,7 @@ func TestTaskInteractionWebSocket_Unauthorized(t *testing.T) {
 	taskID := uuid.New()
 	userID := uuid.New()
 
-	mockService.On("ValidateTaskOwnership", mock.Anything, taskID, userID).Return(service.ErrTaskNotOwnedByUser)
+	mockService.On("ValidateTaskAccess", mock.Anything, taskID, userID).Return(service.ErrTaskNotOwnedByUser)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -363,7 +363,7 @@ func TestTaskInteractionWebSocket_OwnershipValidationError(t *testing.T) {
 	taskID := uuid.New()
 	userID := uuid.New()
 
-	mockService.On("ValidateTaskOwnership", mock.Anything, taskID, userID).Return(fmt.Errorf("database error"))
+	mockService.On("ValidateTaskAccess", mock.Anything, taskID, userID).Return(fmt.Errorf("database error"))
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -374,7 +374,7 @@ func TestTaskInteractionWebSocket_OwnershipValidationError(t *testing.T) {
 	handler.TaskInteractionWebSocket(c)
 
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
-	assert.Contains(t, w.Body.String(), "Failed to validate task ownership")
+	assert.Contains(t, w.Body.String(), "Failed to validate task access")
 }
 
 func TestHandleUserMessage_Success(t *testing.T) {
@@ -433,6 +433,74 @@ func TestHandleUserMessage_ServiceError(t *testing.T) {
 	assert.Contains(t, err.Error(), "failed to store user message")
 }
 
+func TestHandleUserMessage_DeliversToRunningAgent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockInteractionService)
+	mockTaskService := new(MockTaskService)
+	handler := NewInteractionHandler(mockService, mockTaskService)
+
+	ctx := context.Background()
+	taskID := uuid.New()
+	userID := uuid.New()
+	review := model.Session{ID: uuid.New(), Kind: model.SessionKindReview, Status: model.SessionStatusRunning, RemoteSessionID: "ses_review"}
+	waiting := model.Session{ID: uuid.New(), Kind: model.SessionKindExecution, Status: model.SessionStatusWaitingForInput, RemoteSessionID: "ses_exec"}
+
+	mockTaskService.On("GetTaskSessions", ctx, taskID, userID).Return([]model.Session{review, waiting}, nil)
+	mockTaskService.On("ContinueSession", ctx, taskID, waiting.ID, userID, "Use PostgreSQL").Return(&waiting, nil)
+
+	err := handler.handleUserMessage(ctx, taskID, userID, WebSocketMessage{Type: "user_message", Content: "Use PostgreSQL"})
+
+	assert.NoError(t, err)
+	mockTaskService.AssertExpectations(t)
+	mockService.AssertNotCalled(t, "CreateUserMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestHandleUserMessage_StoresWithoutActiveSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockInteractionService)
+	mockTaskService := new(MockTaskService)
+	handler := NewInteractionHandler(mockService, mockTaskService)
+
+	ctx := context.Background()
+	taskID := uuid.New()
+	userID := uuid.New()
+	finished := model.Session{ID: uuid.New(), Kind: model.SessionKindExecution, Status: model.SessionStatusCompleted, RemoteSessionID: "ses_exec"}
+
+	mockTaskService.On("GetTaskSessions", ctx, taskID, userID).Return([]model.Session{finished}, nil)
+	mockService.On("CreateUserMessage", ctx, taskID, userID, "A note", model.JSONB(nil)).Return(&model.Interaction{
+		ID:          uuid.New(),
+		TaskID:      taskID,
+		UserID:      userID,
+		MessageType: "user_message",
+		Content:     "A note",
+	}, nil)
+
+	err := handler.handleUserMessage(ctx, taskID, userID, WebSocketMessage{Type: "user_message", Content: "A note"})
+
+	assert.NoError(t, err)
+	mockService.AssertExpectations(t)
+	mockTaskService.AssertNotCalled(t, "ContinueSession", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestHandleUserMessage_DeliveryError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockTaskService := new(MockTaskService)
+	handler := NewInteractionHandler(new(MockInteractionService), mockTaskService)
+
+	ctx := context.Background()
+	taskID := uuid.New()
+	userID := uuid.New()
+	running := model.Session{ID: uuid.New(), Kind: model.SessionKindExecution, Status: model.SessionStatusRunning, RemoteSessionID: "ses_exec"}
+
+	mockTaskService.On("GetTaskSessions", ctx, taskID, userID).Return([]model.Session{running}, nil)
+	mockTaskService.On("ContinueSession", ctx, taskID, running.ID, userID, "Hello").Return(nil, service.ErrOpenCodeAPICall)
+
+	err := handler.handleUserMessage(ctx, taskID, userID, WebSocketMessage{Type: "user_message", Content: "Hello"})
+
+	assert.ErrorIs(t, err, service.ErrOpenCodeAPICall)
+	assert.Contains(t, err.Error(), "failed to deliver message to agent")
+}
+
 func TestWebSocketMessage_JSONSerialization(t *testing.T) {
 	msg := WebSocketMessage{
 		Type:      "user_message",
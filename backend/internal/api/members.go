@@ -0,0 +1,254 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/npinot/vibe/backend/internal/middleware"
+	"github.com/npinot/vibe/backend/internal/model"
+	"github.com/npinot/vibe/backend/internal/service"
+)
+
+// MemberHandler shares projects with other users through invitations
+type MemberHandler struct {
+	memberService service.ProjectMemberService
+}
+
+func NewMemberHandler(memberService service.ProjectMemberService) *MemberHandler {
+	return &MemberHandler{
+		memberService: memberService,
+	}
+}
+
+// InviteMemberRequest invites an email address to join a project
+type InviteMemberRequest struct {
+	Email string            `json:"email" binding:"required"`
+	Role  model.ProjectRole `json:"role" binding:"required"` // maintainer or viewer
+}
+
+// UpdateMemberRequest changes the role of a project member
+type UpdateMemberRequest struct {
+	Role model.ProjectRole `json:"role" binding:"required"`
+}
+
+// ListMembers lists the members of a project
+// GET /api/projects/:id/members
+func (h *MemberHandler) ListMembers(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.memberService.ListMembers(c.Request.Context(), projectID, userID)
+	if err != nil {
+		respondMemberError(c, err, "Failed to list members")
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// UpdateMember changes the role of a project member
+// PATCH /api/projects/:id/members/:userId
+func (h *MemberHandler) UpdateMember(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	memberID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req UpdateMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	member, err := h.memberService.UpdateMemberRole(c.Request.Context(), projectID, memberID, userID, req.Role)
+	if err != nil {
+		respondMemberError(c, err, "Failed to update member")
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+// RemoveMember removes a member from a project; members may remove themselves to leave it
+// DELETE /api/projects/:id/members/:userId
+func (h *MemberHandler) RemoveMember(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	memberID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := h.memberService.RemoveMember(c.Request.Context(), projectID, memberID, userID); err != nil {
+		respondMemberError(c, err, "Failed to remove member")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListInvitations lists the pending invitations to a project
+// GET /api/projects/:id/invitations
+func (h *MemberHandler) ListInvitations(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	invitations, err := h.memberService.ListInvitations(c.Request.Context(), projectID, userID)
+	if err != nil {
+		respondMemberError(c, err, "Failed to list invitations")
+		return
+	}
+
+	c.JSON(http.StatusOK, invitations)
+}
+
+// InviteMember invites an email address to join a project
+// POST /api/projects/:id/invitations
+func (h *MemberHandler) InviteMember(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	var req InviteMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	invitation, err := h.memberService.InviteMember(c.Request.Context(), projectID, userID, req.Email, req.Role)
+	if err != nil {
+		respondMemberError(c, err, "Failed to invite member")
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitation)
+}
+
+// RevokeInvitation deletes a pending invitation to a project
+// DELETE /api/projects/:id/invitations/:invitationId
+func (h *MemberHandler) RevokeInvitation(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	invitationID, err := uuid.Parse(c.Param("invitationId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invitation ID"})
+		return
+	}
+
+	if err := h.memberService.RevokeInvitation(c.Request.Context(), projectID, invitationID, userID); err != nil {
+		respondMemberError(c, err, "Failed to revoke invitation")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListMyInvitations lists the pending invitations sent to the current user
+// GET /api/invitations
+func (h *MemberHandler) ListMyInvitations(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	if userID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	invitations, err := h.memberService.ListMyInvitations(c.Request.Context(), userID)
+	if err != nil {
+		respondMemberError(c, err, "Failed to list invitations")
+		return
+	}
+
+	c.JSON(http.StatusOK, invitations)
+}
+
+// AcceptInvitation makes the current user a member of the project they were invited to
+// POST /api/invitations/:id/accept
+func (h *MemberHandler) AcceptInvitation(c *gin.Context) {
+	userID, invitationID, ok := invitationParams(c)
+	if !ok {
+		return
+	}
+
+	member, err := h.memberService.AcceptInvitation(c.Request.Context(), invitationID, userID)
+	if err != nil {
+		respondMemberError(c, err, "Failed to accept invitation")
+		return
+	}
+
+	c.JSON(http.StatusCreated, member)
+}
+
+// DeclineInvitation deletes an invitation sent to the current user
+// POST /api/invitations/:id/decline
+func (h *MemberHandler) DeclineInvitation(c *gin.Context) {
+	userID, invitationID, ok := invitationParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.memberService.DeclineInvitation(c.Request.Context(), invitationID, userID); err != nil {
+		respondMemberError(c, err, "Failed to decline invitation")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func invitationParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID := middleware.GetCurrentUserID(c)
+	if userID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	invitationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invitation ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, invitationID, true
+}
+
+func respondMemberError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrProjectNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+	case errors.Is(err, service.ErrMemberNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+	case errors.Is(err, service.ErrInvitationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+	case errors.Is(err, service.ErrUnauthorized):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+	case errors.Is(err, service.ErrInvalidMemberRole),
+		errors.Is(err, service.ErrInvalidEmail):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrAlreadyMember):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		log.Printf("[Member] %s: %v", message, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
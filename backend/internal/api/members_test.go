@@ -0,0 +1,286 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/npinot/vibe/backend/internal/model"
+	"github.com/npinot/vibe/backend/internal/service"
+)
+
+type MockMemberService struct {
+	mock.Mock
+}
+
+func (m *MockMemberService) ListMembers(ctx context.Context, projectID, userID uuid.UUID) ([]model.ProjectMember, error) {
+	args := m.Called(ctx, projectID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.ProjectMember), args.Error(1)
+}
+
+func (m *MockMemberService) UpdateMemberRole(ctx context.Context, projectID, memberID, userID uuid.UUID, role model.ProjectRole) (*model.ProjectMember, error) {
+	args := m.Called(ctx, projectID, memberID, userID, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ProjectMember), args.Error(1)
+}
+
+func (m *MockMemberService) RemoveMember(ctx context.Context, projectID, memberID, userID uuid.UUID) error {
+	args := m.Called(ctx, projectID, memberID, userID)
+	return args.Error(0)
+}
+
+func (m *MockMemberService) ListInvitations(ctx context.Context, projectID, userID uuid.UUID) ([]model.ProjectInvitation, error) {
+	args := m.Called(ctx, projectID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.ProjectInvitation), args.Error(1)
+}
+
+func (m *MockMemberService) InviteMember(ctx context.Context, projectID, userID uuid.UUID, email string, role model.ProjectRole) (*model.ProjectInvitation, error) {
+	args := m.Called(ctx, projectID, userID, email, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ProjectInvitation), args.Error(1)
+}
+
+func (m *MockMemberService) RevokeInvitation(ctx context.Context, projectID, invitationID, userID uuid.UUID) error {
+	args := m.Called(ctx, projectID, invitationID, userID)
+	return args.Error(0)
+}
+
+func (m *MockMemberService) ListMyInvitations(ctx context.Context, userID uuid.UUID) ([]model.ProjectInvitation, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.ProjectInvitation), args.Error(1)
+}
+
+func (m *MockMemberService) AcceptInvitation(ctx context.Context, invitationID, userID uuid.UUID) (*model.ProjectMember, error) {
+	args := m.Called(ctx, invitationID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ProjectMember), args.Error(1)
+}
+
+func (m *MockMemberService) DeclineInvitation(ctx context.Context, invitationID, userID uuid.UUID) error {
+	args := m.Called(ctx, invitationID, userID)
+	return args.Error(0)
+}
+
+func setupMemberTestRouter() (*MockMemberService, http.Handler) {
+	mockService := new(MockMemberService)
+	handler := NewMemberHandler(mockService)
+	router := setupProjectTestRouter(nil)
+	router.GET("/projects/:id/members", handler.ListMembers)
+	router.PATCH("/projects/:id/members/:userId", handler.UpdateMember)
+	router.DELETE("/projects/:id/members/:userId", handler.RemoveMember)
+	router.GET("/projects/:id/invitations", handler.ListInvitations)
+	router.POST("/projects/:id/invitations", handler.InviteMember)
+	router.DELETE("/projects/:id/invitations/:invitationId", handler.RevokeInvitation)
+	router.GET("/invitations", handler.ListMyInvitations)
+	router.POST("/invitations/:id/accept", handler.AcceptInvitation)
+	router.POST("/invitations/:id/decline", handler.DeclineInvitation)
+	return mockService, router
+}
+
+func TestMemberHandler_Members(t *testing.T) {
+	userID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	projectID := uuid.New()
+	memberID := uuid.New()
+
+	t.Run("lists members", func(t *testing.T) {
+		mockService, router := setupMemberTestRouter()
+		mockService.On("ListMembers", mock.Anything, projectID, userID).
+			Return([]model.ProjectMember{{ProjectID: projectID, UserID: memberID, Role: model.ProjectRoleViewer}}, nil)
+
+		req, _ := http.NewRequest("GET", "/projects/"+projectID.String()+"/members", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response []map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response, 1)
+		assert.Equal(t, "viewer", response[0]["role"])
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("updates member role", func(t *testing.T) {
+		mockService, router := setupMemberTestRouter()
+		mockService.On("UpdateMemberRole", mock.Anything, projectID, memberID, userID, model.ProjectRoleMaintainer).
+			Return(&model.ProjectMember{ProjectID: projectID, UserID: memberID, Role: model.ProjectRoleMaintainer}, nil)
+
+		req, _ := http.NewRequest("PATCH", "/projects/"+projectID.String()+"/members/"+memberID.String(), strings.NewReader(`{"role":"maintainer"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("removes member", func(t *testing.T) {
+		mockService, router := setupMemberTestRouter()
+		mockService.On("RemoveMember", mock.Anything, projectID, memberID, userID).Return(nil)
+
+		req, _ := http.NewRequest("DELETE", "/projects/"+projectID.String()+"/members/"+memberID.String(), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid member ID", func(t *testing.T) {
+		_, router := setupMemberTestRouter()
+
+		req, _ := http.NewRequest("DELETE", "/projects/"+projectID.String()+"/members/not-a-uuid", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestMemberHandler_Invitations(t *testing.T) {
+	userID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	projectID := uuid.New()
+	invitationID := uuid.New()
+
+	t.Run("invites member", func(t *testing.T) {
+		mockService, router := setupMemberTestRouter()
+		mockService.On("InviteMember", mock.Anything, projectID, userID, "dev@example.com", model.ProjectRoleViewer).
+			Return(&model.ProjectInvitation{ID: invitationID, ProjectID: projectID, Email: "dev@example.com", Role: model.ProjectRoleViewer}, nil)
+
+		req, _ := http.NewRequest("POST", "/projects/"+projectID.String()+"/invitations", strings.NewReader(`{"email":"dev@example.com","role":"viewer"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, invitationID.String(), response["id"])
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("missing email", func(t *testing.T) {
+		mockService, router := setupMemberTestRouter()
+
+		req, _ := http.NewRequest("POST", "/projects/"+projectID.String()+"/invitations", strings.NewReader(`{"role":"viewer"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "InviteMember", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("revokes invitation", func(t *testing.T) {
+		mockService, router := setupMemberTestRouter()
+		mockService.On("RevokeInvitation", mock.Anything, projectID, invitationID, userID).Return(nil)
+
+		req, _ := http.NewRequest("DELETE", "/projects/"+projectID.String()+"/invitations/"+invitationID.String(), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("lists invitations of the current user", func(t *testing.T) {
+		mockService, router := setupMemberTestRouter()
+		mockService.On("ListMyInvitations", mock.Anything, userID).
+			Return([]model.ProjectInvitation{{ID: invitationID, ProjectID: projectID, Email: "test@example.com"}}, nil)
+
+		req, _ := http.NewRequest("GET", "/invitations", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("accepts invitation", func(t *testing.T) {
+		mockService, router := setupMemberTestRouter()
+		mockService.On("AcceptInvitation", mock.Anything, invitationID, userID).
+			Return(&model.ProjectMember{ProjectID: projectID, UserID: userID, Role: model.ProjectRoleViewer}, nil)
+
+		req, _ := http.NewRequest("POST", "/invitations/"+invitationID.String()+"/accept", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("declines invitation", func(t *testing.T) {
+		mockService, router := setupMemberTestRouter()
+		mockService.On("DeclineInvitation", mock.Anything, invitationID, userID).Return(nil)
+
+		req, _ := http.NewRequest("POST", "/invitations/"+invitationID.String()+"/decline", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	errorCases := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"project not found", service.ErrProjectNotFound, http.StatusNotFound},
+		{"unauthorized", fmt.Errorf("%w: requires the owner role", service.ErrUnauthorized), http.StatusForbidden},
+		{"invalid email", fmt.Errorf("%w: %q", service.ErrInvalidEmail, "nope"), http.StatusBadRequest},
+		{"invalid role", fmt.Errorf("%w: must be maintainer or viewer", service.ErrInvalidMemberRole), http.StatusBadRequest},
+		{"already member", service.ErrAlreadyMember, http.StatusConflict},
+		{"internal error", errors.New("boom"), http.StatusInternalServerError},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService, router := setupMemberTestRouter()
+			mockService.On("InviteMember", mock.Anything, projectID, userID, "nope", model.ProjectRoleOwner).Return(nil, tc.err)
+
+			req, _ := http.NewRequest("POST", "/projects/"+projectID.String()+"/invitations", strings.NewReader(`{"email":"nope","role":"owner"}`))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.wantStatus, w.Code)
+		})
+	}
+
+	t.Run("invitation not found", func(t *testing.T) {
+		mockService, router := setupMemberTestRouter()
+		mockService.On("AcceptInvitation", mock.Anything, invitationID, userID).Return(nil, service.ErrInvitationNotFound)
+
+		req, _ := http.NewRequest("POST", "/invitations/"+invitationID.String()+"/accept", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -1,32 +1,72 @@
 package api
 
 import (
+	"bufio"
+	"context"
 	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 
 	"github.com/npinot/vibe/backend/internal/middleware"
+	"github.com/npinot/vibe/backend/internal/model"
 	"github.com/npinot/vibe/backend/internal/service"
 )
 
 // ProjectHandler handles project-related requests
 type ProjectHandler struct {
-	projectService service.ProjectService
+	projectService  service.ProjectService
+	templateService service.ProjectTemplateService
 }
 
-func NewProjectHandler(projectService service.ProjectService) *ProjectHandler {
+// NewProjectHandler creates a project handler. templateService may be nil, in which case
+// projects cannot be created from templates.
+func NewProjectHandler(projectService service.ProjectService, templateService service.ProjectTemplateService) *ProjectHandler {
 	return &ProjectHandler{
-		projectService: projectService,
+		projectService:  projectService,
+		templateService: templateService,
 	}
 }
 
 type CreateProjectRequest struct {
-	Name        string `json:"name" binding:"required"`
-	Description string `json:"description"`
-	RepoURL     string `json:"repo_url"`
+	Name           string `json:"name" binding:"required"`
+	Description    string `json:"description"`
+	RepoURL        string `json:"repo_url"`
+	RepoRef        string `json:"repo_ref"`
+	RepoCloneDepth int    `json:"repo_clone_depth"`
+	RepoCredential string `json:"repo_credential"`
+	ResourceProfileRequest
+
+	// TemplateID bootstraps the project from a template; APIKey is used for the template configuration
+	TemplateID string `json:"template_id"`
+	APIKey     string `json:"api_key"`
+}
+
+// ResourceProfileRequest selects the resource profile of a project. CPU, memory, storage size
+// and storage class are only read for the custom profile.
+type ResourceProfileRequest struct {
+	Profile      string `json:"resource_profile"`
+	CPU          string `json:"cpu"`
+	Memory       string `json:"memory"`
+	StorageSize  string `json:"storage_size"`
+	StorageClass string `json:"storage_class"`
+}
+
+func (r ResourceProfileRequest) selection() service.ResourceSelection {
+	return service.ResourceSelection{
+		Profile:      r.Profile,
+		CPU:          r.CPU,
+		Memory:       r.Memory,
+		StorageSize:  r.StorageSize,
+		StorageClass: r.StorageClass,
+	}
 }
 
 type UpdateProjectRequest struct {
@@ -66,19 +106,55 @@ func (h *ProjectHandler) CreateProject(c *gin.Context) {
 		return
 	}
 
-	project, err := h.projectService.CreateProject(
-		c.Request.Context(),
-		user.ID,
-		req.Name,
-		req.Description,
-		req.RepoURL,
-	)
+	repoOpts := service.RepoCloneOptions{
+		Ref:        req.RepoRef,
+		Depth:      req.RepoCloneDepth,
+		Credential: req.RepoCredential,
+	}
+
+	var project *model.Project
+	if req.TemplateID != "" {
+		templateID, parseErr := uuid.Parse(req.TemplateID)
+		if parseErr != nil || h.templateService == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+			return
+		}
+		project, err = h.templateService.CreateProjectFromTemplate(c.Request.Context(), templateID, user.ID, service.TemplateProjectOptions{
+			Name:        req.Name,
+			Description: req.Description,
+			RepoURL:     req.RepoURL,
+			RepoOpts:    repoOpts,
+			Resources:   req.selection(),
+			APIKey:      req.APIKey,
+		})
+	} else {
+		project, err = h.projectService.CreateProject(
+			c.Request.Context(),
+			user.ID,
+			req.Name,
+			req.Description,
+			req.RepoURL,
+			repoOpts,
+			req.selection(),
+		)
+	}
 	if err != nil {
 		switch {
+		case errors.Is(err, service.ErrTemplateNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+		case errors.Is(err, service.ErrUnauthorized):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
 		case errors.Is(err, service.ErrInvalidProjectName):
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		case errors.Is(err, service.ErrInvalidRepoURL):
+		case errors.Is(err, service.ErrInvalidRepoURL),
+			errors.Is(err, service.ErrInvalidRepoRef),
+			errors.Is(err, service.ErrInvalidCloneDepth),
+			errors.Is(err, service.ErrInvalidCredential),
+			errors.Is(err, service.ErrInvalidResourceProfile):
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrResourceCapExceeded),
+			errors.Is(err, service.ErrInvalidResourceCap):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create project"})
 		}
@@ -207,12 +283,241 @@ func (h *ProjectHandler) DeleteProject(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// WakeProject recreates the pod of a hibernated project and returns the project once it is ready
+// POST /api/projects/:id/wake
+func (h *ProjectHandler) WakeProject(c *gin.Context) {
+	user, err := middleware.GetCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	idParam := c.Param("id")
+	projectID, err := uuid.Parse(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	project, err := h.projectService.WakeProject(c.Request.Context(), projectID, user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrProjectNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		case errors.Is(err, service.ErrUnauthorized):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		case errors.Is(err, service.ErrProjectWakeFailed):
+			log.Printf("[ProjectHandler] Failed to wake project %s: %v", projectID, err)
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Project pod did not start, try again later"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to wake project"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+// UpdateProjectResources switches a project to another resource profile; its pod restarts with the new resources
+// PUT /api/projects/:id/resources
+func (h *ProjectHandler) UpdateProjectResources(c *gin.Context) {
+	user, err := middleware.GetCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	idParam := c.Param("id")
+	projectID, err := uuid.Parse(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	var req ResourceProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Profile == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	project, err := h.projectService.UpdateProjectResources(c.Request.Context(), projectID, user.ID, req.selection())
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrProjectNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		case errors.Is(err, service.ErrUnauthorized):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		case errors.Is(err, service.ErrInvalidResourceProfile):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrResourceCapExceeded),
+			errors.Is(err, service.ErrInvalidResourceCap):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update project resources"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+// logHeartbeatInterval is how often ProjectLogs writes a comment to keep idle follow streams open
+const logHeartbeatInterval = 30 * time.Second
+
+// ProjectLogs streams the logs of a project pod container as server-sent events: a "log" event per line,
+// then an "end" event once the logs are exhausted, or an "error" event when reading them fails.
+// GET /api/projects/:id/logs?container=opencode-server&follow=true&since=10m&tail=500&previous=false
+func (h *ProjectHandler) ProjectLogs(c *gin.Context) {
+	user, err := middleware.GetCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	idParam := c.Param("id")
+	projectID, err := uuid.Parse(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	opts, err := parseLogOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	logs, err := h.projectService.StreamProjectLogs(ctx, projectID, user.ID, opts)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrProjectNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		case errors.Is(err, service.ErrUnauthorized):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		case errors.Is(err, service.ErrInvalidLogOptions):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrProjectPodMissing):
+			c.JSON(http.StatusConflict, gin.H{"error": "Project has no running pod"})
+		case errors.Is(err, service.ErrContainerLogsUnavailable):
+			c.JSON(http.StatusConflict, gin.H{"error": "Container has no logs yet"})
+		default:
+			log.Printf("[ProjectHandler] Failed to stream logs of project %s: %v", projectID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to stream logs"})
+		}
+		return
+	}
+	defer logs.Close()
+
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	c.Writer.Header().Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	lines := make(chan string)
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(logs)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			select {
+			case lines <- scanner.Text():
+			case <-ctx.Done():
+				readErr <- ctx.Err()
+				return
+			}
+		}
+		readErr <- scanner.Err()
+	}()
+
+	heartbeat := time.NewTicker(logHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": ping\n\n")
+			c.Writer.Flush()
+		case line, ok := <-lines:
+			if !ok {
+				err := <-readErr
+				if ctx.Err() != nil {
+					return
+				}
+				if err != nil {
+					log.Printf("[ProjectHandler] Failed to read logs of project %s: %v", projectID, err)
+					c.SSEvent("error", gin.H{"error": "Failed to read logs"})
+				} else {
+					c.SSEvent("end", gin.H{"container": opts.Container})
+				}
+				c.Writer.Flush()
+				return
+			}
+			c.SSEvent("log", logLine(line))
+			c.Writer.Flush()
+		}
+	}
+}
+
+// parseLogOptions reads the container, follow, previous, since and tail query parameters of ProjectLogs.
+// since is either a duration back from now or an RFC3339 time.
+func parseLogOptions(c *gin.Context) (service.ContainerLogOptions, error) {
+	opts := service.ContainerLogOptions{Container: c.Query("container")}
+
+	for name, value := range map[string]*bool{"follow": &opts.Follow, "previous": &opts.Previous} {
+		if param := c.Query(name); param != "" {
+			parsed, err := strconv.ParseBool(param)
+			if err != nil {
+				return opts, fmt.Errorf("%s must be true or false", name)
+			}
+			*value = parsed
+		}
+	}
+
+	if since := c.Query("since"); since != "" {
+		if d, err := time.ParseDuration(since); err == nil && d > 0 {
+			opts.Since = time.Now().Add(-d)
+		} else if t, err := time.Parse(time.RFC3339, since); err == nil {
+			opts.Since = t
+		} else {
+			return opts, fmt.Errorf("since must be a duration such as 10m or an RFC3339 time")
+		}
+	}
+
+	if tail := c.Query("tail"); tail != "" {
+		lines, err := strconv.ParseInt(tail, 10, 64)
+		if err != nil || lines <= 0 {
+			return opts, fmt.Errorf("tail must be a positive number of lines")
+		}
+		opts.TailLines = lines
+	}
+
+	return opts, nil
+}
+
+// logLine splits the timestamp Kubernetes prefixes to each log line from the message
+func logLine(line string) gin.H {
+	if timestamp, message, found := strings.Cut(line, " "); found {
+		if _, err := time.Parse(time.RFC3339Nano, timestamp); err == nil {
+			return gin.H{"timestamp": timestamp, "message": message}
+		}
+	}
+	return gin.H{"message": line}
+}
+
 var upgrader = websocket.Upgrader{
 	CheckOrigin: func(r *http.Request) bool {
 		return true
 	},
 }
 
+// ProjectStatus streams the status of the project pod, its containers and its Kubernetes events over a
+// WebSocket until the client disconnects
 func (h *ProjectHandler) ProjectStatus(c *gin.Context) {
 	user, err := middleware.GetCurrentUser(c)
 	if err != nil {
@@ -246,16 +551,62 @@ func (h *ProjectHandler) ProjectStatus(c *gin.Context) {
 	}
 	defer conn.Close()
 
-	if project.PodName == "" || project.PodNamespace == "" {
+	// Hibernated projects keep their pod name but have no pod
+	if project.PodName == "" || project.PodNamespace == "" || project.Status == model.ProjectStatusHibernated {
 		conn.WriteJSON(gin.H{
+			"type":   service.PodUpdateStatus,
 			"status": string(project.Status),
 			"error":  project.PodError,
 		})
 		return
 	}
 
-	conn.WriteJSON(gin.H{
+	if err := conn.WriteJSON(gin.H{
+		"type":   service.PodUpdateStatus,
 		"status": project.PodStatus,
 		"error":  project.PodError,
-	})
+	}); err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	updates, err := h.projectService.WatchProjectStatus(ctx, projectID, user.ID)
+	if err != nil {
+		log.Printf("[ProjectHandler] Failed to watch status of project %s: %v", projectID, err)
+		conn.WriteJSON(gin.H{"type": "error", "error": "Failed to watch project status"})
+		return
+	}
+
+	// The client sends nothing, reading only notices when it goes away
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	pingTicker := time.NewTicker(30 * time.Second)
+	defer pingTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case update, ok := <-updates:
+			if !ok {
+				return
+			}
+			if err := conn.WriteJSON(update); err != nil {
+				return
+			}
+		case <-pingTicker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second)); err != nil {
+				return
+			}
+		}
+	}
 }
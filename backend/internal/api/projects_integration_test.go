@@ -76,10 +76,10 @@ func setupIntegrationTest(t *testing.T) (*gorm.DB, service.ProjectService, servi
 	}
 
 	// Initialize project service
-	projectService := service.NewProjectService(projectRepo, k8sService)
+	projectService := service.NewProjectService(projectRepo, service.NewAuthorizationService(projectRepo, nil), k8sService, nil, nil, nil)
 
 	// Initialize handler
-	handler := NewProjectHandler(projectService)
+	handler := NewProjectHandler(projectService, nil)
 
 	// Cleanup function
 	cleanup := func() {
@@ -5,14 +5,20 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"github.com/npinot/vibe/backend/internal/model"
 	"github.com/npinot/vibe/backend/internal/service"
@@ -22,8 +28,8 @@ type MockProjectService struct {
 	mock.Mock
 }
 
-func (m *MockProjectService) CreateProject(ctx context.Context, userID uuid.UUID, name, description, repoURL string) (*model.Project, error) {
-	args := m.Called(ctx, userID, name, description, repoURL)
+func (m *MockProjectService) CreateProject(ctx context.Context, userID uuid.UUID, name, description, repoURL string, repoOpts service.RepoCloneOptions, resources service.ResourceSelection) (*model.Project, error) {
+	args := m.Called(ctx, userID, name, description, repoURL, repoOpts, resources)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -59,6 +65,38 @@ func (m *MockProjectService) DeleteProject(ctx context.Context, id, userID uuid.
 	return args.Error(0)
 }
 
+func (m *MockProjectService) WakeProject(ctx context.Context, id, userID uuid.UUID) (*model.Project, error) {
+	args := m.Called(ctx, id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Project), args.Error(1)
+}
+
+func (m *MockProjectService) WatchProjectStatus(ctx context.Context, id, userID uuid.UUID) (<-chan service.PodStatusUpdate, error) {
+	args := m.Called(ctx, id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(<-chan service.PodStatusUpdate), args.Error(1)
+}
+
+func (m *MockProjectService) StreamProjectLogs(ctx context.Context, id, userID uuid.UUID, opts service.ContainerLogOptions) (io.ReadCloser, error) {
+	args := m.Called(ctx, id, userID, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func (m *MockProjectService) UpdateProjectResources(ctx context.Context, id, userID uuid.UUID, resources service.ResourceSelection) (*model.Project, error) {
+	args := m.Called(ctx, id, userID, resources)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Project), args.Error(1)
+}
+
 func setupProjectTestRouter(handler *ProjectHandler) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
@@ -79,7 +117,7 @@ func setupProjectTestRouter(handler *ProjectHandler) *gin.Engine {
 
 func TestProjectHandler_ListProjects(t *testing.T) {
 	mockService := new(MockProjectService)
-	handler := NewProjectHandler(mockService)
+	handler := NewProjectHandler(mockService, nil)
 	router := setupProjectTestRouter(handler)
 
 	router.GET("/projects", handler.ListProjects)
@@ -162,7 +200,7 @@ func TestProjectHandler_ListProjects(t *testing.T) {
 
 func TestProjectHandler_CreateProject(t *testing.T) {
 	mockService := new(MockProjectService)
-	handler := NewProjectHandler(mockService)
+	handler := NewProjectHandler(mockService, nil)
 	router := setupProjectTestRouter(handler)
 
 	router.POST("/projects", handler.CreateProject)
@@ -187,7 +225,7 @@ func TestProjectHandler_CreateProject(t *testing.T) {
 			Status:      model.ProjectStatusReady,
 		}
 
-		mockService.On("CreateProject", mock.Anything, userID, "Test Project", "Test Description", "https://github.com/test/repo").
+		mockService.On("CreateProject", mock.Anything, userID, "Test Project", "Test Description", "https://github.com/test/repo", service.RepoCloneOptions{}, service.ResourceSelection{}).
 			Return(expectedProject, nil).Once()
 
 		body, _ := json.Marshal(reqBody)
@@ -237,7 +275,7 @@ func TestProjectHandler_CreateProject(t *testing.T) {
 			Name: "Invalid@Name#",
 		}
 
-		mockService.On("CreateProject", mock.Anything, userID, "Invalid@Name#", "", "").
+		mockService.On("CreateProject", mock.Anything, userID, "Invalid@Name#", "", "", service.RepoCloneOptions{}, service.ResourceSelection{}).
 			Return(nil, service.ErrInvalidProjectName).Once()
 
 		body, _ := json.Marshal(reqBody)
@@ -259,7 +297,7 @@ func TestProjectHandler_CreateProject(t *testing.T) {
 			RepoURL: "invalid-url",
 		}
 
-		mockService.On("CreateProject", mock.Anything, userID, "Valid Name", "", "invalid-url").
+		mockService.On("CreateProject", mock.Anything, userID, "Valid Name", "", "invalid-url", service.RepoCloneOptions{}, service.ResourceSelection{}).
 			Return(nil, service.ErrInvalidRepoURL).Once()
 
 		body, _ := json.Marshal(reqBody)
@@ -272,11 +310,46 @@ func TestProjectHandler_CreateProject(t *testing.T) {
 
 		mockService.AssertExpectations(t)
 	})
+
+	t.Run("resource profile above the caps", func(t *testing.T) {
+		userID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+
+		mockService.On("CreateProject", mock.Anything, userID, "Big Project", "", "", service.RepoCloneOptions{}, service.ResourceSelection{Profile: "large"}).
+			Return(nil, service.ErrResourceCapExceeded).Once()
+
+		body := `{"name":"Big Project","resource_profile":"large"}`
+		req, _ := http.NewRequest("POST", "/projects", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("misconfigured resource caps", func(t *testing.T) {
+		userID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+
+		mockService.On("CreateProject", mock.Anything, userID, "Capped Project", "", "", service.RepoCloneOptions{}, service.ResourceSelection{}).
+			Return(nil, fmt.Errorf("%w: maximum CPU \"lots\" is not a valid quantity", service.ErrInvalidResourceCap)).Once()
+
+		body := `{"name":"Capped Project"}`
+		req, _ := http.NewRequest("POST", "/projects", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "invalid resource caps")
+
+		mockService.AssertExpectations(t)
+	})
 }
 
 func TestProjectHandler_GetProject(t *testing.T) {
 	mockService := new(MockProjectService)
-	handler := NewProjectHandler(mockService)
+	handler := NewProjectHandler(mockService, nil)
 	router := setupProjectTestRouter(handler)
 
 	router.GET("/projects/:id", handler.GetProject)
@@ -350,7 +423,7 @@ func TestProjectHandler_GetProject(t *testing.T) {
 
 func TestProjectHandler_UpdateProject(t *testing.T) {
 	mockService := new(MockProjectService)
-	handler := NewProjectHandler(mockService)
+	handler := NewProjectHandler(mockService, nil)
 	router := setupProjectTestRouter(handler)
 
 	router.PATCH("/projects/:id", handler.UpdateProject)
@@ -445,7 +518,7 @@ func TestProjectHandler_UpdateProject(t *testing.T) {
 
 func TestProjectHandler_DeleteProject(t *testing.T) {
 	mockService := new(MockProjectService)
-	handler := NewProjectHandler(mockService)
+	handler := NewProjectHandler(mockService, nil)
 	router := setupProjectTestRouter(handler)
 
 	router.DELETE("/projects/:id", handler.DeleteProject)
@@ -503,3 +576,312 @@ func TestProjectHandler_DeleteProject(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 }
+
+func TestProjectHandler_WakeProject(t *testing.T) {
+	mockService := new(MockProjectService)
+	handler := NewProjectHandler(mockService, nil)
+	router := setupProjectTestRouter(handler)
+
+	router.POST("/projects/:id/wake", handler.WakeProject)
+
+	userID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+
+	t.Run("returns the awake project", func(t *testing.T) {
+		projectID := uuid.New()
+		mockService.On("WakeProject", mock.Anything, projectID, userID).
+			Return(&model.Project{ID: projectID, UserID: userID, Status: model.ProjectStatusReady}, nil).Once()
+
+		req, _ := http.NewRequest("POST", "/projects/"+projectID.String()+"/wake", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response model.Project
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, model.ProjectStatusReady, response.Status)
+	})
+
+	t.Run("invalid project ID", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/projects/invalid-uuid/wake", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	errorCases := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"project not found", service.ErrProjectNotFound, http.StatusNotFound},
+		{"unauthorized access", service.ErrUnauthorized, http.StatusForbidden},
+		{"pod did not start", service.ErrProjectWakeFailed, http.StatusServiceUnavailable},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			projectID := uuid.New()
+			mockService.On("WakeProject", mock.Anything, projectID, userID).Return(nil, tc.err).Once()
+
+			req, _ := http.NewRequest("POST", "/projects/"+projectID.String()+"/wake", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestProjectHandler_UpdateProjectResources(t *testing.T) {
+	mockService := new(MockProjectService)
+	handler := NewProjectHandler(mockService, nil)
+	router := setupProjectTestRouter(handler)
+
+	router.PUT("/projects/:id/resources", handler.UpdateProjectResources)
+
+	userID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+
+	t.Run("switches the resource profile", func(t *testing.T) {
+		projectID := uuid.New()
+		selection := service.ResourceSelection{Profile: "custom", CPU: "1500m", Memory: "2Gi", StorageSize: "5Gi"}
+		mockService.On("UpdateProjectResources", mock.Anything, projectID, userID, selection).
+			Return(&model.Project{ID: projectID, UserID: userID, ResourceProfile: "custom", CPULimit: "1500m"}, nil).Once()
+
+		body := `{"resource_profile":"custom","cpu":"1500m","memory":"2Gi","storage_size":"5Gi"}`
+		req, _ := http.NewRequest("PUT", "/projects/"+projectID.String()+"/resources", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response model.Project
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "custom", response.ResourceProfile)
+	})
+
+	t.Run("missing profile", func(t *testing.T) {
+		req, _ := http.NewRequest("PUT", "/projects/"+uuid.New().String()+"/resources", strings.NewReader(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	errorCases := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"project not found", service.ErrProjectNotFound, http.StatusNotFound},
+		{"unauthorized access", service.ErrUnauthorized, http.StatusForbidden},
+		{"invalid profile", service.ErrInvalidResourceProfile, http.StatusBadRequest},
+		{"above the caps", service.ErrResourceCapExceeded, http.StatusForbidden},
+		{"misconfigured caps", service.ErrInvalidResourceCap, http.StatusForbidden},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			projectID := uuid.New()
+			mockService.On("UpdateProjectResources", mock.Anything, projectID, userID, service.ResourceSelection{Profile: "large"}).
+				Return(nil, tc.err).Once()
+
+			req, _ := http.NewRequest("PUT", "/projects/"+projectID.String()+"/resources", strings.NewReader(`{"resource_profile":"large"}`))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestProjectHandler_ProjectLogs(t *testing.T) {
+	mockService := new(MockProjectService)
+	handler := NewProjectHandler(mockService, nil)
+	router := setupProjectTestRouter(handler)
+
+	router.GET("/projects/:id/logs", handler.ProjectLogs)
+
+	userID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+
+	t.Run("streams log lines as events", func(t *testing.T) {
+		projectID := uuid.New()
+		logs := "2024-05-01T10:00:00.123456789Z server started\npanic: out of memory\n"
+		mockService.On("StreamProjectLogs", mock.Anything, projectID, userID, mock.MatchedBy(func(opts service.ContainerLogOptions) bool {
+			return opts.Container == "session-proxy" && !opts.Follow && opts.Previous && opts.TailLines == 50 &&
+				time.Since(opts.Since) > 9*time.Minute && time.Since(opts.Since) < 11*time.Minute
+		})).Return(io.NopCloser(strings.NewReader(logs)), nil).Once()
+
+		req, _ := http.NewRequest("GET", "/projects/"+projectID.String()+"/logs?container=session-proxy&previous=true&since=10m&tail=50", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "text/event-stream")
+		body := w.Body.String()
+		assert.Contains(t, body, `event:log`)
+		assert.Contains(t, body, `{"message":"server started","timestamp":"2024-05-01T10:00:00.123456789Z"}`)
+		assert.Contains(t, body, `{"message":"panic: out of memory"}`)
+		assert.Contains(t, body, `event:end`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("since as a time", func(t *testing.T) {
+		projectID := uuid.New()
+		since := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+		mockService.On("StreamProjectLogs", mock.Anything, projectID, userID, service.ContainerLogOptions{Follow: true, Since: since}).
+			Return(io.NopCloser(strings.NewReader("")), nil).Once()
+
+		req, _ := http.NewRequest("GET", "/projects/"+projectID.String()+"/logs?follow=true&since=2024-05-01T10:00:00Z", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	invalidQueries := []string{"follow=maybe", "since=yesterday", "tail=-5"}
+	for _, query := range invalidQueries {
+		t.Run("invalid "+query, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/projects/"+uuid.New().String()+"/logs?"+query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+
+	errorCases := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"project not found", service.ErrProjectNotFound, http.StatusNotFound},
+		{"unauthorized access", service.ErrUnauthorized, http.StatusForbidden},
+		{"invalid container", service.ErrInvalidLogOptions, http.StatusBadRequest},
+		{"no pod", service.ErrProjectPodMissing, http.StatusConflict},
+		{"container not started", service.ErrContainerLogsUnavailable, http.StatusConflict},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			projectID := uuid.New()
+			mockService.On("StreamProjectLogs", mock.Anything, projectID, userID, mock.Anything).Return(nil, tc.err).Once()
+
+			req, _ := http.NewRequest("GET", "/projects/"+projectID.String()+"/logs", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestProjectHandler_ProjectStatus(t *testing.T) {
+	userID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	projectID := uuid.New()
+
+	dial := func(t *testing.T, mockService *MockProjectService) *websocket.Conn {
+		handler := NewProjectHandler(mockService, nil)
+		router := setupProjectTestRouter(handler)
+		router.GET("/projects/:id/status", handler.ProjectStatus)
+
+		server := httptest.NewServer(router)
+		t.Cleanup(server.Close)
+
+		url := "ws" + strings.TrimPrefix(server.URL, "http") + "/projects/" + projectID.String() + "/status"
+		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+		return conn
+	}
+
+	t.Run("streams pod updates until the client disconnects", func(t *testing.T) {
+		mockService := new(MockProjectService)
+		project := &model.Project{ID: projectID, UserID: userID, Status: model.ProjectStatusReady, PodName: "project-1234", PodNamespace: "opencode", PodStatus: "Pending"}
+		mockService.On("GetProject", mock.Anything, projectID, userID).Return(project, nil)
+
+		updates := make(chan service.PodStatusUpdate)
+		watchDone := make(chan struct{})
+		mockService.On("WatchProjectStatus", mock.Anything, projectID, userID).
+			Run(func(args mock.Arguments) {
+				ctx := args.Get(0).(context.Context)
+				go func() {
+					<-ctx.Done()
+					close(watchDone)
+				}()
+			}).
+			Return((<-chan service.PodStatusUpdate)(updates), nil)
+
+		conn := dial(t, mockService)
+
+		var initial map[string]interface{}
+		require.NoError(t, conn.ReadJSON(&initial))
+		assert.Equal(t, "status", initial["type"])
+		assert.Equal(t, "Pending", initial["status"])
+
+		updates <- service.PodStatusUpdate{
+			Type:   service.PodUpdateStatus,
+			Status: "Running",
+			Ready:  true,
+			Containers: []service.PodContainerStatus{
+				{Name: "opencode-server", State: "running", Ready: true, RestartCount: 2},
+			},
+		}
+		var statusUpdate service.PodStatusUpdate
+		require.NoError(t, conn.ReadJSON(&statusUpdate))
+		assert.Equal(t, "Running", statusUpdate.Status)
+		assert.True(t, statusUpdate.Ready)
+		require.Len(t, statusUpdate.Containers, 1)
+		assert.Equal(t, int32(2), statusUpdate.Containers[0].RestartCount)
+
+		updates <- service.PodStatusUpdate{
+			Type:  service.PodUpdateEvent,
+			Event: &service.PodEvent{Type: "Warning", Reason: "BackOff", Message: "Back-off restarting failed container"},
+		}
+		var eventUpdate service.PodStatusUpdate
+		require.NoError(t, conn.ReadJSON(&eventUpdate))
+		assert.Equal(t, service.PodUpdateEvent, eventUpdate.Type)
+		require.NotNil(t, eventUpdate.Event)
+		assert.Equal(t, "BackOff", eventUpdate.Event.Reason)
+
+		// Closing the connection stops the watch
+		conn.Close()
+		select {
+		case <-watchDone:
+		case <-time.After(5 * time.Second):
+			t.Fatal("watch was not stopped after the client disconnected")
+		}
+	})
+
+	t.Run("reports the status of a hibernated project and closes", func(t *testing.T) {
+		mockService := new(MockProjectService)
+		project := &model.Project{ID: projectID, UserID: userID, Status: model.ProjectStatusHibernated, PodName: "project-1234", PodNamespace: "opencode"}
+		mockService.On("GetProject", mock.Anything, projectID, userID).Return(project, nil)
+
+		conn := dial(t, mockService)
+
+		var initial map[string]interface{}
+		require.NoError(t, conn.ReadJSON(&initial))
+		assert.Equal(t, "hibernated", initial["status"])
+
+		_, _, err := conn.ReadMessage()
+		assert.Error(t, err)
+		mockService.AssertNotCalled(t, "WatchProjectStatus", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("reports a failed watch", func(t *testing.T) {
+		mockService := new(MockProjectService)
+		project := &model.Project{ID: projectID, UserID: userID, Status: model.ProjectStatusReady, PodName: "project-1234", PodNamespace: "opencode", PodStatus: "Running"}
+		mockService.On("GetProject", mock.Anything, projectID, userID).Return(project, nil)
+		mockService.On("WatchProjectStatus", mock.Anything, projectID, userID).Return(nil, errors.New("forbidden"))
+
+		conn := dial(t, mockService)
+
+		var initial, failure map[string]interface{}
+		require.NoError(t, conn.ReadJSON(&initial))
+		require.NoError(t, conn.ReadJSON(&failure))
+		assert.Equal(t, "error", failure["type"])
+	})
+}
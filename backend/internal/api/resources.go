@@ -0,0 +1,134 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/npinot/vibe/backend/internal/middleware"
+	"github.com/npinot/vibe/backend/internal/model"
+	"github.com/npinot/vibe/backend/internal/service"
+)
+
+// ResourceHandler lists the resource profiles of projects and lets admins cap them per user
+type ResourceHandler struct {
+	profileService service.ResourceProfileService
+}
+
+func NewResourceHandler(profileService service.ResourceProfileService) *ResourceHandler {
+	return &ResourceHandler{
+		profileService: profileService,
+	}
+}
+
+// SetResourceCapRequest sets the largest resources a user may give a project
+type SetResourceCapRequest struct {
+	MaxCPU      string `json:"max_cpu" binding:"required"`
+	MaxMemory   string `json:"max_memory" binding:"required"`
+	MaxStorage  string `json:"max_storage" binding:"required"`
+	AllowCustom *bool  `json:"allow_custom"` // defaults to true
+}
+
+// ListResourceProfiles returns the resource profiles and which of them the current user may choose
+// GET /api/resource-profiles
+func (h *ResourceHandler) ListResourceProfiles(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	if userID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	profiles, err := h.profileService.ListProfiles(c.Request.Context(), userID)
+	if err != nil {
+		log.Printf("[ResourceHandler] Failed to list resource profiles for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch resource profiles"})
+		return
+	}
+
+	c.JSON(http.StatusOK, profiles)
+}
+
+// GetUserResourceCap returns the resource caps of a user
+// GET /api/admin/users/:userId/resource-cap
+func (h *ResourceHandler) GetUserResourceCap(c *gin.Context) {
+	userID, ok := resourceCapUserParam(c)
+	if !ok {
+		return
+	}
+
+	resourceCap, err := h.profileService.GetUserCaps(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch resource caps"})
+		return
+	}
+
+	c.JSON(http.StatusOK, resourceCap)
+}
+
+// SetUserResourceCap creates or replaces the resource caps of a user
+// PUT /api/admin/users/:userId/resource-cap
+func (h *ResourceHandler) SetUserResourceCap(c *gin.Context) {
+	userID, ok := resourceCapUserParam(c)
+	if !ok {
+		return
+	}
+
+	var req SetResourceCapRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	allowCustom := true
+	if req.AllowCustom != nil {
+		allowCustom = *req.AllowCustom
+	}
+
+	resourceCap, err := h.profileService.SetUserCaps(c.Request.Context(), &model.ResourceCap{
+		UserID:      userID,
+		MaxCPU:      req.MaxCPU,
+		MaxMemory:   req.MaxMemory,
+		MaxStorage:  req.MaxStorage,
+		AllowCustom: allowCustom,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidResourceCap) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("[ResourceHandler] Failed to save resource caps of user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save resource caps"})
+		return
+	}
+
+	c.JSON(http.StatusOK, resourceCap)
+}
+
+// DeleteUserResourceCap puts a user back on the default resource caps
+// DELETE /api/admin/users/:userId/resource-cap
+func (h *ResourceHandler) DeleteUserResourceCap(c *gin.Context) {
+	userID, ok := resourceCapUserParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.profileService.DeleteUserCaps(c.Request.Context(), userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete resource caps"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// resourceCapUserParam parses the user whose caps an admin manages, writing the error response on failure
+func resourceCapUserParam(c *gin.Context) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/npinot/vibe/backend/internal/model"
+	"github.com/npinot/vibe/backend/internal/service"
+)
+
+type MockResourceProfileService struct {
+	mock.Mock
+}
+
+func (m *MockResourceProfileService) ListProfiles(ctx context.Context, userID uuid.UUID) (*service.ResourceProfileList, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.ResourceProfileList), args.Error(1)
+}
+
+func (m *MockResourceProfileService) ResolveProfile(ctx context.Context, userID uuid.UUID, selection service.ResourceSelection) (*service.ResourceProfile, error) {
+	args := m.Called(ctx, userID, selection)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.ResourceProfile), args.Error(1)
+}
+
+func (m *MockResourceProfileService) GetUserCaps(ctx context.Context, userID uuid.UUID) (*model.ResourceCap, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ResourceCap), args.Error(1)
+}
+
+func (m *MockResourceProfileService) SetUserCaps(ctx context.Context, resourceCap *model.ResourceCap) (*model.ResourceCap, error) {
+	args := m.Called(ctx, resourceCap)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ResourceCap), args.Error(1)
+}
+
+func (m *MockResourceProfileService) DeleteUserCaps(ctx context.Context, userID uuid.UUID) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func setupResourceTestRouter() (*MockResourceProfileService, http.Handler) {
+	mockService := new(MockResourceProfileService)
+	handler := NewResourceHandler(mockService)
+	router := setupProjectTestRouter(nil)
+	router.GET("/resource-profiles", handler.ListResourceProfiles)
+	router.GET("/admin/users/:userId/resource-cap", handler.GetUserResourceCap)
+	router.PUT("/admin/users/:userId/resource-cap", handler.SetUserResourceCap)
+	router.DELETE("/admin/users/:userId/resource-cap", handler.DeleteUserResourceCap)
+	return mockService, router
+}
+
+func TestResourceHandler_ListResourceProfiles(t *testing.T) {
+	userID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	mockService, router := setupResourceTestRouter()
+
+	mockService.On("ListProfiles", mock.Anything, userID).Return(&service.ResourceProfileList{
+		Profiles: []service.ResourceProfileOption{
+			{ResourceProfile: service.ResourceProfile{Name: "small", CPULimit: "500m"}, Allowed: true},
+			{ResourceProfile: service.ResourceProfile{Name: "custom"}, Allowed: false},
+		},
+		Caps: &model.ResourceCap{UserID: userID, MaxCPU: "1000m"},
+	}, nil)
+
+	req, _ := http.NewRequest("GET", "/resource-profiles", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Profiles []struct {
+			Name     string `json:"name"`
+			CPULimit string `json:"cpu_limit"`
+			Allowed  bool   `json:"allowed"`
+		} `json:"profiles"`
+		Caps model.ResourceCap `json:"caps"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Profiles, 2)
+	assert.Equal(t, "small", response.Profiles[0].Name)
+	assert.Equal(t, "500m", response.Profiles[0].CPULimit)
+	assert.True(t, response.Profiles[0].Allowed)
+	assert.False(t, response.Profiles[1].Allowed)
+	assert.Equal(t, "1000m", response.Caps.MaxCPU)
+}
+
+func TestResourceHandler_UserResourceCap(t *testing.T) {
+	targetID := uuid.New()
+	path := "/admin/users/" + targetID.String() + "/resource-cap"
+
+	t.Run("get", func(t *testing.T) {
+		mockService, router := setupResourceTestRouter()
+		mockService.On("GetUserCaps", mock.Anything, targetID).
+			Return(&model.ResourceCap{UserID: targetID, MaxCPU: "2000m", MaxMemory: "4Gi", MaxStorage: "10Gi", AllowCustom: true}, nil)
+
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"max_memory":"4Gi"`)
+	})
+
+	t.Run("set defaults custom resources to allowed", func(t *testing.T) {
+		mockService, router := setupResourceTestRouter()
+		mockService.On("SetUserCaps", mock.Anything, mock.MatchedBy(func(c *model.ResourceCap) bool {
+			return c.UserID == targetID && c.MaxCPU == "4" && c.MaxMemory == "8Gi" && c.MaxStorage == "50Gi" && c.AllowCustom
+		})).Return(&model.ResourceCap{UserID: targetID, MaxCPU: "4", MaxMemory: "8Gi", MaxStorage: "50Gi", AllowCustom: true}, nil)
+
+		req, _ := http.NewRequest("PUT", path, strings.NewReader(`{"max_cpu":"4","max_memory":"8Gi","max_storage":"50Gi"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("set invalid caps", func(t *testing.T) {
+		mockService, router := setupResourceTestRouter()
+		mockService.On("SetUserCaps", mock.Anything, mock.Anything).
+			Return(nil, errors.Join(service.ErrInvalidResourceCap, errors.New("maximum memory must be positive")))
+
+		req, _ := http.NewRequest("PUT", path, strings.NewReader(`{"max_cpu":"4","max_memory":"-1","max_storage":"50Gi","allow_custom":false}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("set without all caps", func(t *testing.T) {
+		_, router := setupResourceTestRouter()
+
+		req, _ := http.NewRequest("PUT", path, strings.NewReader(`{"max_cpu":"4"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		mockService, router := setupResourceTestRouter()
+		mockService.On("DeleteUserCaps", mock.Anything, targetID).Return(nil)
+
+		req, _ := http.NewRequest("DELETE", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("invalid user ID", func(t *testing.T) {
+		_, router := setupResourceTestRouter()
+
+		req, _ := http.NewRequest("GET", "/admin/users/invalid-uuid/resource-cap", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/npinot/vibe/backend/internal/middleware"
+	"github.com/npinot/vibe/backend/internal/model"
+	"github.com/npinot/vibe/backend/internal/service"
+)
+
+type SubmitReviewRequest struct {
+	Decision model.ReviewDecision   `json:"decision" binding:"required"`
+	Summary  string                 `json:"summary"`
+	Comments []ReviewCommentRequest `json:"comments"`
+}
+
+// ReviewCommentRequest is a review comment; file_path and line anchor it to the session diff
+type ReviewCommentRequest struct {
+	FilePath string `json:"file_path"`
+	Line     int    `json:"line"`
+	Body     string `json:"body"`
+}
+
+// SubmitReview approves a task in human review or sends it back with requested changes
+func (h *TaskHandler) SubmitReview(c *gin.Context) {
+	user, err := middleware.GetCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	taskID, err := uuid.Parse(c.Param("taskId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	var req SubmitReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	comments := make([]model.ReviewComment, 0, len(req.Comments))
+	for _, comment := range req.Comments {
+		comments = append(comments, model.ReviewComment{
+			FilePath: comment.FilePath,
+			Line:     comment.Line,
+			Body:     comment.Body,
+		})
+	}
+
+	review, err := h.taskService.SubmitReview(c.Request.Context(), taskID, user.ID, req.Decision, req.Summary, comments)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrTaskNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		case errors.Is(err, service.ErrUnauthorized):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		case errors.Is(err, service.ErrProjectArchived):
+			c.JSON(http.StatusConflict, gin.H{"error": "Project is archived"})
+		case errors.Is(err, service.ErrInvalidStateTransition):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrInvalidReview):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			log.Printf("[SubmitReview] Failed to submit review: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit review"})
+		}
+		return
+	}
+
+	if review.Task != nil {
+		h.taskBroadcaster.Broadcast(review.Task.ProjectID, TaskEvent{
+			Type: "moved",
+			Task: review.Task,
+		})
+	}
+
+	c.JSON(http.StatusCreated, review)
+}
+
+// ListReviews returns the review history of a task
+func (h *TaskHandler) ListReviews(c *gin.Context) {
+	user, err := middleware.GetCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	taskID, err := uuid.Parse(c.Param("taskId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	reviews, err := h.taskService.ListReviews(c.Request.Context(), taskID, user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrTaskNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		case errors.Is(err, service.ErrUnauthorized):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list reviews"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, reviews)
+}
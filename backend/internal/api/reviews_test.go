@@ -0,0 +1,134 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/npinot/vibe/backend/internal/model"
+	"github.com/npinot/vibe/backend/internal/service"
+)
+
+func TestTaskHandler_SubmitReview(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, service.NewAuthorizationService(new(MockProjectRepo), nil), new(MockK8sService), "")
+	router := setupTaskTestRouter(handler)
+
+	router.POST("/projects/:id/tasks/:taskId/reviews", handler.SubmitReview)
+
+	userID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	projectID := uuid.New()
+
+	reviewURL := func(taskID string) string {
+		return "/projects/" + projectID.String() + "/tasks/" + taskID + "/reviews"
+	}
+
+	t.Run("request changes with inline comments", func(t *testing.T) {
+		taskID := uuid.New()
+
+		expectedComments := []model.ReviewComment{
+			{FilePath: "main.go", Line: 12, Body: "Handle the error"},
+		}
+		review := &model.TaskReview{
+			ID:       uuid.New(),
+			TaskID:   taskID,
+			Decision: model.ReviewDecisionChangesRequested,
+			Comments: expectedComments,
+			Task:     &model.Task{ID: taskID, ProjectID: projectID, Status: model.TaskStatusInProgress},
+		}
+		mockService.On("SubmitReview", mock.Anything, taskID, userID, model.ReviewDecisionChangesRequested, "Needs work", expectedComments).
+			Return(review, nil).Once()
+
+		body, _ := json.Marshal(map[string]interface{}{
+			"decision": "changes_requested",
+			"summary":  "Needs work",
+			"comments": []map[string]interface{}{
+				{"file_path": "main.go", "line": 12, "body": "Handle the error"},
+			},
+		})
+		req, _ := http.NewRequest("POST", reviewURL(taskID.String()), bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		var resp model.TaskReview
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.NoError(t, err)
+		assert.Equal(t, model.ReviewDecisionChangesRequested, resp.Decision)
+		assert.Equal(t, model.TaskStatusInProgress, resp.Task.Status)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("missing decision", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]interface{}{"summary": "ok"})
+		req, _ := http.NewRequest("POST", reviewURL(uuid.New().String()), bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("invalid review", func(t *testing.T) {
+		taskID := uuid.New()
+		mockService.On("SubmitReview", mock.Anything, taskID, userID, model.ReviewDecisionChangesRequested, "", []model.ReviewComment{}).
+			Return(nil, service.ErrInvalidReview).Once()
+
+		body, _ := json.Marshal(map[string]interface{}{"decision": "changes_requested"})
+		req, _ := http.NewRequest("POST", reviewURL(taskID.String()), bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("task not found", func(t *testing.T) {
+		taskID := uuid.New()
+		mockService.On("SubmitReview", mock.Anything, taskID, userID, model.ReviewDecisionApproved, "", []model.ReviewComment{}).
+			Return(nil, service.ErrTaskNotFound).Once()
+
+		body, _ := json.Marshal(map[string]interface{}{"decision": "approved"})
+		req, _ := http.NewRequest("POST", reviewURL(taskID.String()), bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestTaskHandler_ListReviews(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, service.NewAuthorizationService(new(MockProjectRepo), nil), new(MockK8sService), "")
+	router := setupTaskTestRouter(handler)
+
+	router.GET("/projects/:id/tasks/:taskId/reviews", handler.ListReviews)
+
+	userID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	taskID := uuid.New()
+
+	mockService.On("ListReviews", mock.Anything, taskID, userID).Return([]model.TaskReview{
+		{ID: uuid.New(), TaskID: taskID, Decision: model.ReviewDecisionApproved},
+	}, nil).Once()
+
+	req, _ := http.NewRequest("GET", "/projects/"+uuid.New().String()+"/tasks/"+taskID.String()+"/reviews", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp []model.TaskReview
+	err := json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.Len(t, resp, 1)
+	mockService.AssertExpectations(t)
+}
@@ -36,6 +36,7 @@ type ActiveSessionDTO struct {
 type UpdateSessionStatusRequest struct {
 	Status string `json:"status" binding:"required"`
 	Error  string `json:"error"`
+	Output string `json:"output"` // final agent response, sent when the session completes
 }
 
 func (h *SessionHandler) GetActiveSessions(c *gin.Context) {
@@ -84,6 +85,16 @@ func (h *SessionHandler) UpdateSessionStatus(c *gin.Context) {
 		return
 	}
 
+	// Store the final output first: finishing a reviewer session reads its verdict from it
+	if req.Output != "" {
+		if err := h.sessionService.UpdateSessionFinalOutput(c.Request.Context(), sessionID, req.Output); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to update session final output",
+			})
+			return
+		}
+	}
+
 	if err := h.sessionService.UpdateSessionStatus(c.Request.Context(), sessionID, req.Status, req.Error); err != nil {
 		if err == service.ErrSessionNotFound {
 			c.JSON(http.StatusNotFound, gin.H{
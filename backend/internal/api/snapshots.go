@@ -0,0 +1,107 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/npinot/vibe/backend/internal/service"
+)
+
+// SnapshotHandler takes, lists and restores snapshots of project workspaces
+type SnapshotHandler struct {
+	snapshotService service.WorkspaceSnapshotService
+}
+
+func NewSnapshotHandler(snapshotService service.WorkspaceSnapshotService) *SnapshotHandler {
+	return &SnapshotHandler{
+		snapshotService: snapshotService,
+	}
+}
+
+// ListSnapshots returns the snapshots of the project workspace, newest first
+// GET /api/projects/:id/snapshots
+func (h *SnapshotHandler) ListSnapshots(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	snapshots, err := h.snapshotService.ListSnapshots(c.Request.Context(), projectID, userID)
+	if err != nil {
+		respondSnapshotError(c, err, "Failed to fetch snapshots")
+		return
+	}
+
+	c.JSON(http.StatusOK, snapshots)
+}
+
+// CreateSnapshot takes a snapshot of the project workspace; it can be restored once ready_to_use
+// POST /api/projects/:id/snapshots
+func (h *SnapshotHandler) CreateSnapshot(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	snapshot, err := h.snapshotService.CreateSnapshot(c.Request.Context(), projectID, userID)
+	if err != nil {
+		respondSnapshotError(c, err, "Failed to create snapshot")
+		return
+	}
+
+	c.JSON(http.StatusCreated, snapshot)
+}
+
+// RestoreSnapshot puts the project workspace back to the snapshot and returns the project
+// POST /api/projects/:id/snapshots/:snapshot/restore
+func (h *SnapshotHandler) RestoreSnapshot(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	project, err := h.snapshotService.RestoreSnapshot(c.Request.Context(), projectID, userID, c.Param("snapshot"))
+	if err != nil {
+		respondSnapshotError(c, err, "Failed to restore snapshot")
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+// DeleteSnapshot deletes a snapshot of the project workspace
+// DELETE /api/projects/:id/snapshots/:snapshot
+func (h *SnapshotHandler) DeleteSnapshot(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.snapshotService.DeleteSnapshot(c.Request.Context(), projectID, userID, c.Param("snapshot")); err != nil {
+		respondSnapshotError(c, err, "Failed to delete snapshot")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func respondSnapshotError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrProjectNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+	case errors.Is(err, service.ErrUnauthorized):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+	case errors.Is(err, service.ErrSnapshotNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Snapshot not found"})
+	case errors.Is(err, service.ErrSnapshotNotReady), errors.Is(err, service.ErrWorkspaceBusy):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrSnapshotsUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		log.Printf("[Snapshot] %s: %v", message, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
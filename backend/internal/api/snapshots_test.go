@@ -0,0 +1,171 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/npinot/vibe/backend/internal/model"
+	"github.com/npinot/vibe/backend/internal/service"
+)
+
+type MockSnapshotService struct {
+	mock.Mock
+}
+
+func (m *MockSnapshotService) ListSnapshots(ctx context.Context, projectID, userID uuid.UUID) ([]service.WorkspaceSnapshot, error) {
+	args := m.Called(ctx, projectID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]service.WorkspaceSnapshot), args.Error(1)
+}
+
+func (m *MockSnapshotService) CreateSnapshot(ctx context.Context, projectID, userID uuid.UUID) (*service.WorkspaceSnapshot, error) {
+	args := m.Called(ctx, projectID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.WorkspaceSnapshot), args.Error(1)
+}
+
+func (m *MockSnapshotService) RestoreSnapshot(ctx context.Context, projectID, userID uuid.UUID, name string) (*model.Project, error) {
+	args := m.Called(ctx, projectID, userID, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Project), args.Error(1)
+}
+
+func (m *MockSnapshotService) DeleteSnapshot(ctx context.Context, projectID, userID uuid.UUID, name string) error {
+	args := m.Called(ctx, projectID, userID, name)
+	return args.Error(0)
+}
+
+func (m *MockSnapshotService) SnapshotBeforeTask(ctx context.Context, projectID, taskID uuid.UUID) (*service.WorkspaceSnapshot, error) {
+	args := m.Called(ctx, projectID, taskID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.WorkspaceSnapshot), args.Error(1)
+}
+
+func setupSnapshotTestRouter() (*MockSnapshotService, http.Handler) {
+	mockService := new(MockSnapshotService)
+	handler := NewSnapshotHandler(mockService)
+	router := setupProjectTestRouter(nil)
+	router.GET("/projects/:id/snapshots", handler.ListSnapshots)
+	router.POST("/projects/:id/snapshots", handler.CreateSnapshot)
+	router.POST("/projects/:id/snapshots/:snapshot/restore", handler.RestoreSnapshot)
+	router.DELETE("/projects/:id/snapshots/:snapshot", handler.DeleteSnapshot)
+	return mockService, router
+}
+
+func TestSnapshotHandler(t *testing.T) {
+	userID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	projectID := uuid.New()
+	taskID := uuid.New()
+
+	t.Run("lists snapshots", func(t *testing.T) {
+		mockService, router := setupSnapshotTestRouter()
+		mockService.On("ListSnapshots", mock.Anything, projectID, userID).Return([]service.WorkspaceSnapshot{
+			{Name: "snapshot-1234-abcde", Trigger: service.SnapshotTriggerTask, TaskID: &taskID, ReadyToUse: true, RestoreSize: "1Gi"},
+		}, nil)
+
+		req, _ := http.NewRequest("GET", "/projects/"+projectID.String()+"/snapshots", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response []map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response, 1)
+		assert.Equal(t, "snapshot-1234-abcde", response[0]["name"])
+		assert.Equal(t, "task", response[0]["trigger"])
+		assert.Equal(t, taskID.String(), response[0]["task_id"])
+		assert.Equal(t, true, response[0]["ready_to_use"])
+	})
+
+	t.Run("creates snapshot", func(t *testing.T) {
+		mockService, router := setupSnapshotTestRouter()
+		mockService.On("CreateSnapshot", mock.Anything, projectID, userID).
+			Return(&service.WorkspaceSnapshot{Name: "snapshot-1234-abcde", Trigger: service.SnapshotTriggerManual}, nil)
+
+		req, _ := http.NewRequest("POST", "/projects/"+projectID.String()+"/snapshots", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), "snapshot-1234-abcde")
+	})
+
+	t.Run("restores snapshot", func(t *testing.T) {
+		mockService, router := setupSnapshotTestRouter()
+		mockService.On("RestoreSnapshot", mock.Anything, projectID, userID, "snapshot-1234-abcde").
+			Return(&model.Project{ID: projectID, UserID: userID, WorkspacePVCName: "workspace-1234-fghij"}, nil)
+
+		req, _ := http.NewRequest("POST", "/projects/"+projectID.String()+"/snapshots/snapshot-1234-abcde/restore", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("deletes snapshot", func(t *testing.T) {
+		mockService, router := setupSnapshotTestRouter()
+		mockService.On("DeleteSnapshot", mock.Anything, projectID, userID, "snapshot-1234-abcde").Return(nil)
+
+		req, _ := http.NewRequest("DELETE", "/projects/"+projectID.String()+"/snapshots/snapshot-1234-abcde", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("invalid project ID", func(t *testing.T) {
+		_, router := setupSnapshotTestRouter()
+
+		req, _ := http.NewRequest("GET", "/projects/not-a-uuid/snapshots", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	errorCases := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"project not found", service.ErrProjectNotFound, http.StatusNotFound},
+		{"unauthorized", service.ErrUnauthorized, http.StatusForbidden},
+		{"snapshot not found", service.ErrSnapshotNotFound, http.StatusNotFound},
+		{"snapshot not ready", service.ErrSnapshotNotReady, http.StatusConflict},
+		{"workspace busy", fmt.Errorf("%w: task is still running", service.ErrWorkspaceBusy), http.StatusConflict},
+		{"snapshots unavailable", service.ErrSnapshotsUnavailable, http.StatusServiceUnavailable},
+		{"internal error", errors.New("boom"), http.StatusInternalServerError},
+	}
+	for _, tc := range errorCases {
+		t.Run("restore "+tc.name, func(t *testing.T) {
+			mockService, router := setupSnapshotTestRouter()
+			mockService.On("RestoreSnapshot", mock.Anything, projectID, userID, "snapshot-1234-abcde").Return(nil, tc.err)
+
+			req, _ := http.NewRequest("POST", "/projects/"+projectID.String()+"/snapshots/snapshot-1234-abcde/restore", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.wantStatus, w.Code)
+		})
+	}
+}
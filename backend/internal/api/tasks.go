@@ -15,17 +15,17 @@ import (
 
 	"github.com/npinot/vibe/backend/internal/middleware"
 	"github.com/npinot/vibe/backend/internal/model"
-	"github.com/npinot/vibe/backend/internal/repository"
 	"github.com/npinot/vibe/backend/internal/service"
 )
 
 // TaskHandler handles task-related requests
 type TaskHandler struct {
 	taskService     service.TaskService
-	projectRepo     repository.ProjectRepository
+	authz           service.AuthorizationService
 	k8sService      service.KubernetesService
 	taskBroadcaster *TaskBroadcaster
 	httpClient      *http.Client
+	sharedSecret    string
 }
 
 // TaskEvent represents a task update event for WebSocket streaming
@@ -43,15 +43,16 @@ type TaskBroadcaster struct {
 	version        int64
 }
 
-func NewTaskHandler(taskService service.TaskService, projectRepo repository.ProjectRepository, k8sService service.KubernetesService) *TaskHandler {
+func NewTaskHandler(taskService service.TaskService, authz service.AuthorizationService, k8sService service.KubernetesService, sharedSecret string) *TaskHandler {
 	return &TaskHandler{
 		taskService:     taskService,
-		projectRepo:     projectRepo,
+		authz:           authz,
 		k8sService:      k8sService,
 		taskBroadcaster: NewTaskBroadcaster(),
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		sharedSecret: sharedSecret,
 	}
 }
 
@@ -123,6 +124,111 @@ func (h *TaskHandler) GetTaskSessions(c *gin.Context) {
 	c.JSON(http.StatusOK, sessions)
 }
 
+// GetSessionDiff returns the per-file workspace changes made by a task session
+func (h *TaskHandler) GetSessionDiff(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	if userID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	taskID, err := uuid.Parse(c.Param("taskId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("sessionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	diff, err := h.taskService.GetSessionDiff(c.Request.Context(), taskID, sessionID, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrTaskNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		case errors.Is(err, service.ErrSessionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		case errors.Is(err, service.ErrUnauthorized):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		case errors.Is(err, service.ErrSessionDiffUnavailable):
+			c.JSON(http.StatusConflict, gin.H{"error": "No workspace snapshot was recorded for this session"})
+		default:
+			log.Printf("[GetSessionDiff] Failed to compute diff: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute session diff"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// ContinueSessionRequest is a follow-up prompt for an existing session
+type ContinueSessionRequest struct {
+	Prompt string `json:"prompt" binding:"required"`
+}
+
+// ContinueSession sends a follow-up prompt to a running or finished session of the task
+func (h *TaskHandler) ContinueSession(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	if userID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	taskID, err := uuid.Parse(c.Param("taskId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("sessionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	var req ContinueSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	session, err := h.taskService.ContinueSession(c.Request.Context(), taskID, sessionID, userID, req.Prompt)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrTaskNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		case errors.Is(err, service.ErrSessionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		case errors.Is(err, service.ErrUnauthorized):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		case errors.Is(err, service.ErrProjectArchived):
+			c.JSON(http.StatusConflict, gin.H{"error": "Project is archived"})
+		case errors.Is(err, service.ErrInvalidMessageContent), errors.Is(err, service.ErrInvalidStateTransition):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrSessionNotContinuable):
+			c.JSON(http.StatusConflict, gin.H{"error": "Session cannot be continued"})
+		case errors.Is(err, service.ErrWorkspaceBusy):
+			c.JSON(http.StatusConflict, gin.H{"error": "Another task is running in this project workspace"})
+		case errors.Is(err, service.ErrBudgetExceeded):
+			log.Printf("[ContinueSession] %v", err)
+			c.JSON(http.StatusPaymentRequired, gin.H{"error": "Monthly spending budget is used up"})
+		case errors.Is(err, service.ErrOpenCodeAPICall):
+			log.Printf("[ContinueSession] Sidecar rejected follow-up prompt: %v", err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to send prompt to the agent"})
+		default:
+			log.Printf("[ContinueSession] Failed to continue session: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to continue session"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, session)
+}
+
 // Broadcast sends a task event to all connected clients for a project
 func (tb *TaskBroadcaster) Broadcast(projectID uuid.UUID, event TaskEvent) {
 	tb.mu.Lock()
@@ -236,6 +342,8 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
 		case errors.Is(err, service.ErrUnauthorized):
 			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		case errors.Is(err, service.ErrProjectArchived):
+			c.JSON(http.StatusConflict, gin.H{"error": "Project is archived"})
 		case errors.Is(err, service.ErrInvalidTaskTitle):
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		case errors.Is(err, service.ErrInvalidTaskPriority):
@@ -327,6 +435,8 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
 		case errors.Is(err, service.ErrUnauthorized):
 			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		case errors.Is(err, service.ErrProjectArchived):
+			c.JSON(http.StatusConflict, gin.H{"error": "Project is archived"})
 		case errors.Is(err, service.ErrInvalidTaskTitle):
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		case errors.Is(err, service.ErrInvalidTaskPriority):
@@ -373,8 +483,12 @@ func (h *TaskHandler) MoveTask(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
 		case errors.Is(err, service.ErrUnauthorized):
 			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		case errors.Is(err, service.ErrProjectArchived):
+			c.JSON(http.StatusConflict, gin.H{"error": "Project is archived"})
 		case errors.Is(err, service.ErrInvalidStateTransition):
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrReviewRequired):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to move task"})
 		}
@@ -418,6 +532,8 @@ func (h *TaskHandler) DeleteTask(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
 		case errors.Is(err, service.ErrUnauthorized):
 			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		case errors.Is(err, service.ErrProjectArchived):
+			c.JSON(http.StatusConflict, gin.H{"error": "Project is archived"})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete task"})
 		}
@@ -454,10 +570,17 @@ func (h *TaskHandler) ExecuteTask(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
 		case errors.Is(err, service.ErrUnauthorized):
 			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		case errors.Is(err, service.ErrProjectArchived):
+			c.JSON(http.StatusConflict, gin.H{"error": "Project is archived"})
 		case errors.Is(err, service.ErrInvalidStateTransition):
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		case errors.Is(err, service.ErrSessionAlreadyActive):
 			c.JSON(http.StatusConflict, gin.H{"error": "Task already has an active session"})
+		case errors.Is(err, service.ErrWorkspaceBusy):
+			c.JSON(http.StatusConflict, gin.H{"error": "Another task is running in this project workspace"})
+		case errors.Is(err, service.ErrBudgetExceeded):
+			log.Printf("[ExecuteTask] %v", err)
+			c.JSON(http.StatusPaymentRequired, gin.H{"error": "Monthly spending budget is used up"})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to execute task"})
 		}
@@ -492,6 +615,8 @@ func (h *TaskHandler) StopTask(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
 		case errors.Is(err, service.ErrUnauthorized):
 			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		case errors.Is(err, service.ErrProjectArchived):
+			c.JSON(http.StatusConflict, gin.H{"error": "Project is archived"})
 		case errors.Is(err, service.ErrInvalidStateTransition):
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		default:
@@ -615,17 +740,16 @@ func (h *TaskHandler) TaskOutputStream(c *gin.Context) {
 		return
 	}
 
-	project, err := h.projectRepo.FindByID(c.Request.Context(), projectID)
+	project, err := h.authz.AuthorizeProject(c.Request.Context(), projectID, user.ID, model.ProjectRoleViewer)
 	if err != nil {
+		if errors.Is(err, service.ErrUnauthorized) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+			return
+		}
 		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
 		return
 	}
 
-	if project.UserID != user.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
-		return
-	}
-
 	task, err := h.taskService.GetTask(c.Request.Context(), taskID, user.ID)
 	if err != nil {
 		switch {
@@ -650,6 +774,8 @@ func (h *TaskHandler) TaskOutputStream(c *gin.Context) {
 		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to connect to project pod"})
 		return
 	}
+	// The pod stays awake while the output is being streamed
+	defer service.HoldPod(h.k8sService, project.PodName, project.PodNamespace)()
 
 	sessionIDParam := c.Query("session_id")
 	if sessionIDParam == "" {
@@ -663,7 +789,8 @@ func (h *TaskHandler) TaskOutputStream(c *gin.Context) {
 		return
 	}
 
-	sidecarURL := fmt.Sprintf("http://%s:3003/sessions/%s/stream", podIP, sessionID.String())
+	// The session proxy replays the events after Last-Event-ID from its buffer
+	sidecarURL := fmt.Sprintf("%s/session/%s/events", service.SessionProxyURL(podIP), sessionID.String())
 
 	req, err := http.NewRequestWithContext(c.Request.Context(), "GET", sidecarURL, nil)
 	if err != nil {
@@ -677,6 +804,9 @@ func (h *TaskHandler) TaskOutputStream(c *gin.Context) {
 	if lastEventID != "" {
 		req.Header.Set("Last-Event-ID", lastEventID)
 	}
+	if h.sharedSecret != "" {
+		req.Header.Set("Authorization", "Bearer "+h.sharedSecret)
+	}
 
 	resp, err := h.httpClient.Do(req)
 	if err != nil {
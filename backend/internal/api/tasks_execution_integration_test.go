@@ -83,16 +83,16 @@ func setupTaskExecutionIntegrationTest(t *testing.T) (*gorm.DB, *TaskHandler, se
 		// Use a test key (base64-encoded 32 bytes) - safe for testing only
 		encryptionKey = "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="
 	}
-	configService, err := service.NewConfigService(configRepo, encryptionKey)
+	configService, err := service.NewConfigService(configRepo, projectRepo, encryptionKey)
 	require.NoError(t, err, "Failed to initialize config service")
 
 	// Initialize services
-	projectService := service.NewProjectService(projectRepo, k8sService)
-	sessionService := service.NewSessionService(sessionRepo, taskRepo, projectRepo, k8sService, configService)
-	taskService := service.NewTaskService(taskRepo, projectRepo, sessionService)
+	projectService := service.NewProjectService(projectRepo, service.NewAuthorizationService(projectRepo, nil), k8sService, nil, nil, nil)
+	sessionService := service.NewSessionService(sessionRepo, taskRepo, projectRepo, repository.NewInteractionRepository(db), nil, k8sService, configService, nil, "")
+	taskService := service.NewTaskService(taskRepo, projectRepo, service.NewAuthorizationService(projectRepo, nil), sessionService, nil, repository.NewReviewRepository(db), nil)
 
 	// Initialize handlers
-	taskHandler := NewTaskHandler(taskService, projectRepo, k8sService)
+	taskHandler := NewTaskHandler(taskService, service.NewAuthorizationService(projectRepo, nil), k8sService, "")
 
 	// Cleanup function
 	cleanup := func() {
@@ -133,6 +133,8 @@ func createTestProject(t *testing.T, db *gorm.DB, projectService service.Project
 		fmt.Sprintf("integration-test-%s", uuid.New().String()[:8]),
 		"Integration test project for task execution",
 		"https://github.com/test/repo.git",
+		service.RepoCloneOptions{},
+		service.ResourceSelection{},
 	)
 	require.NoError(t, err, "Failed to create test project")
 
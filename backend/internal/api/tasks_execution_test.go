@@ -4,9 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -87,6 +90,38 @@ func (m *MockTaskServiceExecution) GetTaskSessions(ctx context.Context, id, user
 	return args.Get(0).([]model.Session), args.Error(1)
 }
 
+func (m *MockTaskServiceExecution) GetSessionDiff(ctx context.Context, id, sessionID, userID uuid.UUID) (*service.WorkspaceDiff, error) {
+	args := m.Called(ctx, id, sessionID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.WorkspaceDiff), args.Error(1)
+}
+
+func (m *MockTaskServiceExecution) ContinueSession(ctx context.Context, id, sessionID, userID uuid.UUID, prompt string) (*model.Session, error) {
+	args := m.Called(ctx, id, sessionID, userID, prompt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Session), args.Error(1)
+}
+
+func (m *MockTaskServiceExecution) SubmitReview(ctx context.Context, id, userID uuid.UUID, decision model.ReviewDecision, summary string, comments []model.ReviewComment) (*model.TaskReview, error) {
+	args := m.Called(ctx, id, userID, decision, summary, comments)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TaskReview), args.Error(1)
+}
+
+func (m *MockTaskServiceExecution) ListReviews(ctx context.Context, id, userID uuid.UUID) ([]model.TaskReview, error) {
+	args := m.Called(ctx, id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.TaskReview), args.Error(1)
+}
+
 type MockProjectRepositoryExecution struct {
 	mock.Mock
 }
@@ -109,6 +144,11 @@ func (m *MockProjectRepositoryExecution) FindByUserID(ctx context.Context, userI
 	return args.Get(0).([]model.Project), args.Error(1)
 }
 
+func (m *MockProjectRepositoryExecution) FindSharedWithUser(ctx context.Context, userID uuid.UUID) ([]model.Project, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]model.Project), args.Error(1)
+}
+
 func (m *MockProjectRepositoryExecution) Update(ctx context.Context, project *model.Project) error {
 	args := m.Called(ctx, project)
 	return args.Error(0)
@@ -124,6 +164,35 @@ func (m *MockProjectRepositoryExecution) UpdatePodStatus(ctx context.Context, id
 	return args.Error(0)
 }
 
+func (m *MockProjectRepositoryExecution) FindByPodName(ctx context.Context, podName, namespace string) (*model.Project, error) {
+	args := m.Called(ctx, podName, namespace)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Project), args.Error(1)
+}
+
+func (m *MockProjectRepositoryExecution) FindIdleProjects(ctx context.Context, idleSince time.Time) ([]model.Project, error) {
+	args := m.Called(ctx, idleSince)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Project), args.Error(1)
+}
+
+func (m *MockProjectRepositoryExecution) UpdateLastActivity(ctx context.Context, podName, namespace string, at time.Time) error {
+	args := m.Called(ctx, podName, namespace, at)
+	return args.Error(0)
+}
+
+func (m *MockProjectRepositoryExecution) FindRunningProjects(ctx context.Context) ([]model.Project, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Project), args.Error(1)
+}
+
 type MockKubernetesServiceExecution struct {
 	mock.Mock
 }
@@ -133,6 +202,16 @@ func (m *MockKubernetesServiceExecution) CreateProjectPod(ctx context.Context, p
 	return args.Error(0)
 }
 
+func (m *MockKubernetesServiceExecution) CreateEmptyProjectPod(ctx context.Context, project *model.Project) error {
+	args := m.Called(ctx, project)
+	return args.Error(0)
+}
+
+func (m *MockKubernetesServiceExecution) CreateForkedProjectPod(ctx context.Context, project, source *model.Project) error {
+	args := m.Called(ctx, project, source)
+	return args.Error(0)
+}
+
 func (m *MockKubernetesServiceExecution) DeleteProjectPod(ctx context.Context, podName, namespace string) error {
 	args := m.Called(ctx, podName, namespace)
 	return args.Error(0)
@@ -143,12 +222,12 @@ func (m *MockKubernetesServiceExecution) GetPodStatus(ctx context.Context, podNa
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockKubernetesServiceExecution) WatchPodStatus(ctx context.Context, podName, namespace string) (<-chan string, error) {
+func (m *MockKubernetesServiceExecution) WatchPodStatus(ctx context.Context, podName, namespace string) (<-chan service.PodStatusUpdate, error) {
 	args := m.Called(ctx, podName, namespace)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(<-chan string), args.Error(1)
+	return args.Get(0).(<-chan service.PodStatusUpdate), args.Error(1)
 }
 
 func (m *MockKubernetesServiceExecution) GetPodIP(ctx context.Context, podName, namespace string) (string, error) {
@@ -156,6 +235,73 @@ func (m *MockKubernetesServiceExecution) GetPodIP(ctx context.Context, podName,
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockKubernetesServiceExecution) GetRepoCloneStatus(ctx context.Context, podName, namespace string) (*service.RepoCloneStatus, error) {
+	args := m.Called(ctx, podName, namespace)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.RepoCloneStatus), args.Error(1)
+}
+
+func (m *MockKubernetesServiceExecution) StopProjectPod(ctx context.Context, podName, namespace string) error {
+	args := m.Called(ctx, podName, namespace)
+	return args.Error(0)
+}
+
+func (m *MockKubernetesServiceExecution) StartProjectPod(ctx context.Context, project *model.Project) error {
+	args := m.Called(ctx, project)
+	return args.Error(0)
+}
+
+func (m *MockKubernetesServiceExecution) WaitForPodReady(ctx context.Context, podName, namespace string) (string, error) {
+	args := m.Called(ctx, podName, namespace)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockKubernetesServiceExecution) ResizeProjectResources(ctx context.Context, project *model.Project) error {
+	args := m.Called(ctx, project)
+	return args.Error(0)
+}
+
+func (m *MockKubernetesServiceExecution) StreamContainerLogs(ctx context.Context, podName, namespace string, opts service.ContainerLogOptions) (io.ReadCloser, error) {
+	args := m.Called(ctx, podName, namespace, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func (m *MockKubernetesServiceExecution) CheckWorkspaceSnapshots(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockKubernetesServiceExecution) CreateWorkspaceSnapshot(ctx context.Context, project *model.Project, taskID *uuid.UUID) (*service.WorkspaceSnapshot, error) {
+	args := m.Called(ctx, project, taskID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.WorkspaceSnapshot), args.Error(1)
+}
+
+func (m *MockKubernetesServiceExecution) ListWorkspaceSnapshots(ctx context.Context, project *model.Project) ([]service.WorkspaceSnapshot, error) {
+	args := m.Called(ctx, project)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]service.WorkspaceSnapshot), args.Error(1)
+}
+
+func (m *MockKubernetesServiceExecution) DeleteWorkspaceSnapshot(ctx context.Context, project *model.Project, name string) error {
+	args := m.Called(ctx, project, name)
+	return args.Error(0)
+}
+
+func (m *MockKubernetesServiceExecution) RestoreWorkspaceSnapshot(ctx context.Context, project *model.Project, name string, save func(*model.Project) error) error {
+	args := m.Called(ctx, project, name, save)
+	return args.Error(0)
+}
+
 func TestExecuteTask_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -163,7 +309,7 @@ func TestExecuteTask_Success(t *testing.T) {
 	mockProjectRepo := new(MockProjectRepositoryExecution)
 	mockK8sService := new(MockKubernetesServiceExecution)
 
-	handler := NewTaskHandler(mockTaskService, mockProjectRepo, mockK8sService)
+	handler := NewTaskHandler(mockTaskService, service.NewAuthorizationService(mockProjectRepo, nil), mockK8sService, "")
 
 	taskID := uuid.New()
 	userID := uuid.New()
@@ -203,7 +349,7 @@ func TestExecuteTask_TaskNotFound(t *testing.T) {
 	mockProjectRepo := new(MockProjectRepositoryExecution)
 	mockK8sService := new(MockKubernetesServiceExecution)
 
-	handler := NewTaskHandler(mockTaskService, mockProjectRepo, mockK8sService)
+	handler := NewTaskHandler(mockTaskService, service.NewAuthorizationService(mockProjectRepo, nil), mockK8sService, "")
 
 	taskID := uuid.New()
 	userID := uuid.New()
@@ -233,7 +379,7 @@ func TestExecuteTask_Unauthorized(t *testing.T) {
 	mockProjectRepo := new(MockProjectRepositoryExecution)
 	mockK8sService := new(MockKubernetesServiceExecution)
 
-	handler := NewTaskHandler(mockTaskService, mockProjectRepo, mockK8sService)
+	handler := NewTaskHandler(mockTaskService, service.NewAuthorizationService(mockProjectRepo, nil), mockK8sService, "")
 
 	taskID := uuid.New()
 	userID := uuid.New()
@@ -263,7 +409,7 @@ func TestExecuteTask_InvalidStateTransition(t *testing.T) {
 	mockProjectRepo := new(MockProjectRepositoryExecution)
 	mockK8sService := new(MockKubernetesServiceExecution)
 
-	handler := NewTaskHandler(mockTaskService, mockProjectRepo, mockK8sService)
+	handler := NewTaskHandler(mockTaskService, service.NewAuthorizationService(mockProjectRepo, nil), mockK8sService, "")
 
 	taskID := uuid.New()
 	userID := uuid.New()
@@ -293,7 +439,7 @@ func TestExecuteTask_SessionAlreadyActive(t *testing.T) {
 	mockProjectRepo := new(MockProjectRepositoryExecution)
 	mockK8sService := new(MockKubernetesServiceExecution)
 
-	handler := NewTaskHandler(mockTaskService, mockProjectRepo, mockK8sService)
+	handler := NewTaskHandler(mockTaskService, service.NewAuthorizationService(mockProjectRepo, nil), mockK8sService, "")
 
 	taskID := uuid.New()
 	userID := uuid.New()
@@ -316,6 +462,67 @@ func TestExecuteTask_SessionAlreadyActive(t *testing.T) {
 	mockTaskService.AssertExpectations(t)
 }
 
+func TestExecuteTask_WorkspaceBusy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockTaskService := new(MockTaskServiceExecution)
+	mockProjectRepo := new(MockProjectRepositoryExecution)
+	mockK8sService := new(MockKubernetesServiceExecution)
+
+	handler := NewTaskHandler(mockTaskService, service.NewAuthorizationService(mockProjectRepo, nil), mockK8sService, "")
+
+	taskID := uuid.New()
+	userID := uuid.New()
+
+	mockTaskService.On("ExecuteTask", mock.Anything, taskID, userID).Return(nil, service.ErrWorkspaceBusy)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("currentUser", &model.User{ID: userID})
+		c.Next()
+	})
+	router.POST("/tasks/:taskId/execute", handler.ExecuteTask)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/tasks/"+taskID.String()+"/execute", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	mockTaskService.AssertExpectations(t)
+}
+
+func TestExecuteTask_BudgetExceeded(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockTaskService := new(MockTaskServiceExecution)
+	mockProjectRepo := new(MockProjectRepositoryExecution)
+	mockK8sService := new(MockKubernetesServiceExecution)
+
+	handler := NewTaskHandler(mockTaskService, service.NewAuthorizationService(mockProjectRepo, nil), mockK8sService, "")
+
+	taskID := uuid.New()
+	userID := uuid.New()
+
+	budgetErr := fmt.Errorf("failed to start session: %w: the project's monthly budget of $50.00 ($50.10 spent) is used up", service.ErrBudgetExceeded)
+	mockTaskService.On("ExecuteTask", mock.Anything, taskID, userID).Return(nil, budgetErr)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("currentUser", &model.User{ID: userID})
+		c.Next()
+	})
+	router.POST("/tasks/:taskId/execute", handler.ExecuteTask)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/tasks/"+taskID.String()+"/execute", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusPaymentRequired, w.Code)
+
+	mockTaskService.AssertExpectations(t)
+}
+
 func TestExecuteTask_InvalidTaskID(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -323,7 +530,7 @@ func TestExecuteTask_InvalidTaskID(t *testing.T) {
 	mockProjectRepo := new(MockProjectRepositoryExecution)
 	mockK8sService := new(MockKubernetesServiceExecution)
 
-	handler := NewTaskHandler(mockTaskService, mockProjectRepo, mockK8sService)
+	handler := NewTaskHandler(mockTaskService, service.NewAuthorizationService(mockProjectRepo, nil), mockK8sService, "")
 
 	userID := uuid.New()
 
@@ -348,7 +555,7 @@ func TestExecuteTask_InternalError(t *testing.T) {
 	mockProjectRepo := new(MockProjectRepositoryExecution)
 	mockK8sService := new(MockKubernetesServiceExecution)
 
-	handler := NewTaskHandler(mockTaskService, mockProjectRepo, mockK8sService)
+	handler := NewTaskHandler(mockTaskService, service.NewAuthorizationService(mockProjectRepo, nil), mockK8sService, "")
 
 	taskID := uuid.New()
 	userID := uuid.New()
@@ -378,7 +585,7 @@ func TestStopTask_Success(t *testing.T) {
 	mockProjectRepo := new(MockProjectRepositoryExecution)
 	mockK8sService := new(MockKubernetesServiceExecution)
 
-	handler := NewTaskHandler(mockTaskService, mockProjectRepo, mockK8sService)
+	handler := NewTaskHandler(mockTaskService, service.NewAuthorizationService(mockProjectRepo, nil), mockK8sService, "")
 
 	taskID := uuid.New()
 	userID := uuid.New()
@@ -408,7 +615,7 @@ func TestStopTask_TaskNotFound(t *testing.T) {
 	mockProjectRepo := new(MockProjectRepositoryExecution)
 	mockK8sService := new(MockKubernetesServiceExecution)
 
-	handler := NewTaskHandler(mockTaskService, mockProjectRepo, mockK8sService)
+	handler := NewTaskHandler(mockTaskService, service.NewAuthorizationService(mockProjectRepo, nil), mockK8sService, "")
 
 	taskID := uuid.New()
 	userID := uuid.New()
@@ -438,7 +645,7 @@ func TestStopTask_Unauthorized(t *testing.T) {
 	mockProjectRepo := new(MockProjectRepositoryExecution)
 	mockK8sService := new(MockKubernetesServiceExecution)
 
-	handler := NewTaskHandler(mockTaskService, mockProjectRepo, mockK8sService)
+	handler := NewTaskHandler(mockTaskService, service.NewAuthorizationService(mockProjectRepo, nil), mockK8sService, "")
 
 	taskID := uuid.New()
 	userID := uuid.New()
@@ -468,7 +675,7 @@ func TestStopTask_InvalidStateTransition(t *testing.T) {
 	mockProjectRepo := new(MockProjectRepositoryExecution)
 	mockK8sService := new(MockKubernetesServiceExecution)
 
-	handler := NewTaskHandler(mockTaskService, mockProjectRepo, mockK8sService)
+	handler := NewTaskHandler(mockTaskService, service.NewAuthorizationService(mockProjectRepo, nil), mockK8sService, "")
 
 	taskID := uuid.New()
 	userID := uuid.New()
@@ -498,7 +705,7 @@ func TestStopTask_InvalidTaskID(t *testing.T) {
 	mockProjectRepo := new(MockProjectRepositoryExecution)
 	mockK8sService := new(MockKubernetesServiceExecution)
 
-	handler := NewTaskHandler(mockTaskService, mockProjectRepo, mockK8sService)
+	handler := NewTaskHandler(mockTaskService, service.NewAuthorizationService(mockProjectRepo, nil), mockK8sService, "")
 
 	userID := uuid.New()
 
@@ -523,7 +730,7 @@ func TestStopTask_InternalError(t *testing.T) {
 	mockProjectRepo := new(MockProjectRepositoryExecution)
 	mockK8sService := new(MockKubernetesServiceExecution)
 
-	handler := NewTaskHandler(mockTaskService, mockProjectRepo, mockK8sService)
+	handler := NewTaskHandler(mockTaskService, service.NewAuthorizationService(mockProjectRepo, nil), mockK8sService, "")
 
 	taskID := uuid.New()
 	userID := uuid.New()
@@ -553,7 +760,7 @@ func TestTaskOutputStream_MissingSessionID(t *testing.T) {
 	mockProjectRepo := new(MockProjectRepositoryExecution)
 	mockK8sService := new(MockKubernetesServiceExecution)
 
-	handler := NewTaskHandler(mockTaskService, mockProjectRepo, mockK8sService)
+	handler := NewTaskHandler(mockTaskService, service.NewAuthorizationService(mockProjectRepo, nil), mockK8sService, "")
 
 	projectID := uuid.New()
 	taskID := uuid.New()
@@ -598,7 +805,7 @@ func TestTaskOutputStream_InvalidSessionID(t *testing.T) {
 	mockProjectRepo := new(MockProjectRepositoryExecution)
 	mockK8sService := new(MockKubernetesServiceExecution)
 
-	handler := NewTaskHandler(mockTaskService, mockProjectRepo, mockK8sService)
+	handler := NewTaskHandler(mockTaskService, service.NewAuthorizationService(mockProjectRepo, nil), mockK8sService, "")
 
 	projectID := uuid.New()
 	taskID := uuid.New()
@@ -643,7 +850,7 @@ func TestTaskOutputStream_ProjectNotFound(t *testing.T) {
 	mockProjectRepo := new(MockProjectRepositoryExecution)
 	mockK8sService := new(MockKubernetesServiceExecution)
 
-	handler := NewTaskHandler(mockTaskService, mockProjectRepo, mockK8sService)
+	handler := NewTaskHandler(mockTaskService, service.NewAuthorizationService(mockProjectRepo, nil), mockK8sService, "")
 
 	projectID := uuid.New()
 	taskID := uuid.New()
@@ -674,7 +881,7 @@ func TestTaskOutputStream_TaskBelongsToDifferentProject(t *testing.T) {
 	mockProjectRepo := new(MockProjectRepositoryExecution)
 	mockK8sService := new(MockKubernetesServiceExecution)
 
-	handler := NewTaskHandler(mockTaskService, mockProjectRepo, mockK8sService)
+	handler := NewTaskHandler(mockTaskService, service.NewAuthorizationService(mockProjectRepo, nil), mockK8sService, "")
 
 	projectID := uuid.New()
 	otherProjectID := uuid.New()
@@ -5,9 +5,11 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -88,6 +90,38 @@ func (m *MockTaskService) GetTaskSessions(ctx context.Context, id, userID uuid.U
 	return args.Get(0).([]model.Session), args.Error(1)
 }
 
+func (m *MockTaskService) GetSessionDiff(ctx context.Context, id, sessionID, userID uuid.UUID) (*service.WorkspaceDiff, error) {
+	args := m.Called(ctx, id, sessionID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.WorkspaceDiff), args.Error(1)
+}
+
+func (m *MockTaskService) ContinueSession(ctx context.Context, id, sessionID, userID uuid.UUID, prompt string) (*model.Session, error) {
+	args := m.Called(ctx, id, sessionID, userID, prompt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Session), args.Error(1)
+}
+
+func (m *MockTaskService) SubmitReview(ctx context.Context, id, userID uuid.UUID, decision model.ReviewDecision, summary string, comments []model.ReviewComment) (*model.TaskReview, error) {
+	args := m.Called(ctx, id, userID, decision, summary, comments)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TaskReview), args.Error(1)
+}
+
+func (m *MockTaskService) ListReviews(ctx context.Context, id, userID uuid.UUID) ([]model.TaskReview, error) {
+	args := m.Called(ctx, id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.TaskReview), args.Error(1)
+}
+
 type MockProjectRepo struct {
 	mock.Mock
 }
@@ -110,6 +144,11 @@ func (m *MockProjectRepo) FindByUserID(ctx context.Context, userID uuid.UUID) ([
 	return args.Get(0).([]model.Project), args.Error(1)
 }
 
+func (m *MockProjectRepo) FindSharedWithUser(ctx context.Context, userID uuid.UUID) ([]model.Project, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]model.Project), args.Error(1)
+}
+
 func (m *MockProjectRepo) Update(ctx context.Context, project *model.Project) error {
 	args := m.Called(ctx, project)
 	return args.Error(0)
@@ -125,6 +164,35 @@ func (m *MockProjectRepo) UpdatePodStatus(ctx context.Context, id uuid.UUID, sta
 	return args.Error(0)
 }
 
+func (m *MockProjectRepo) FindByPodName(ctx context.Context, podName, namespace string) (*model.Project, error) {
+	args := m.Called(ctx, podName, namespace)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Project), args.Error(1)
+}
+
+func (m *MockProjectRepo) FindIdleProjects(ctx context.Context, idleSince time.Time) ([]model.Project, error) {
+	args := m.Called(ctx, idleSince)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Project), args.Error(1)
+}
+
+func (m *MockProjectRepo) UpdateLastActivity(ctx context.Context, podName, namespace string, at time.Time) error {
+	args := m.Called(ctx, podName, namespace, at)
+	return args.Error(0)
+}
+
+func (m *MockProjectRepo) FindRunningProjects(ctx context.Context) ([]model.Project, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Project), args.Error(1)
+}
+
 type MockK8sService struct {
 	mock.Mock
 }
@@ -134,6 +202,16 @@ func (m *MockK8sService) CreateProjectPod(ctx context.Context, project *model.Pr
 	return args.Error(0)
 }
 
+func (m *MockK8sService) CreateEmptyProjectPod(ctx context.Context, project *model.Project) error {
+	args := m.Called(ctx, project)
+	return args.Error(0)
+}
+
+func (m *MockK8sService) CreateForkedProjectPod(ctx context.Context, project, source *model.Project) error {
+	args := m.Called(ctx, project, source)
+	return args.Error(0)
+}
+
 func (m *MockK8sService) GetPodStatus(ctx context.Context, podName, namespace string) (string, error) {
 	args := m.Called(ctx, podName, namespace)
 	return args.String(0), args.Error(1)
@@ -149,12 +227,79 @@ func (m *MockK8sService) GetPodIP(ctx context.Context, podName, namespace string
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockK8sService) WatchPodStatus(ctx context.Context, podName, namespace string) (<-chan string, error) {
+func (m *MockK8sService) GetRepoCloneStatus(ctx context.Context, podName, namespace string) (*service.RepoCloneStatus, error) {
+	args := m.Called(ctx, podName, namespace)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.RepoCloneStatus), args.Error(1)
+}
+
+func (m *MockK8sService) StopProjectPod(ctx context.Context, podName, namespace string) error {
+	args := m.Called(ctx, podName, namespace)
+	return args.Error(0)
+}
+
+func (m *MockK8sService) StartProjectPod(ctx context.Context, project *model.Project) error {
+	args := m.Called(ctx, project)
+	return args.Error(0)
+}
+
+func (m *MockK8sService) WaitForPodReady(ctx context.Context, podName, namespace string) (string, error) {
+	args := m.Called(ctx, podName, namespace)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockK8sService) ResizeProjectResources(ctx context.Context, project *model.Project) error {
+	args := m.Called(ctx, project)
+	return args.Error(0)
+}
+
+func (m *MockK8sService) StreamContainerLogs(ctx context.Context, podName, namespace string, opts service.ContainerLogOptions) (io.ReadCloser, error) {
+	args := m.Called(ctx, podName, namespace, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func (m *MockK8sService) CheckWorkspaceSnapshots(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockK8sService) CreateWorkspaceSnapshot(ctx context.Context, project *model.Project, taskID *uuid.UUID) (*service.WorkspaceSnapshot, error) {
+	args := m.Called(ctx, project, taskID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.WorkspaceSnapshot), args.Error(1)
+}
+
+func (m *MockK8sService) ListWorkspaceSnapshots(ctx context.Context, project *model.Project) ([]service.WorkspaceSnapshot, error) {
+	args := m.Called(ctx, project)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]service.WorkspaceSnapshot), args.Error(1)
+}
+
+func (m *MockK8sService) DeleteWorkspaceSnapshot(ctx context.Context, project *model.Project, name string) error {
+	args := m.Called(ctx, project, name)
+	return args.Error(0)
+}
+
+func (m *MockK8sService) RestoreWorkspaceSnapshot(ctx context.Context, project *model.Project, name string, save func(*model.Project) error) error {
+	args := m.Called(ctx, project, name, save)
+	return args.Error(0)
+}
+
+func (m *MockK8sService) WatchPodStatus(ctx context.Context, podName, namespace string) (<-chan service.PodStatusUpdate, error) {
 	args := m.Called(ctx, podName, namespace)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(<-chan string), args.Error(1)
+	return args.Get(0).(<-chan service.PodStatusUpdate), args.Error(1)
 }
 
 func setupTaskTestRouter(handler *TaskHandler) *gin.Engine {
@@ -179,7 +324,7 @@ func TestTaskHandler_CreateTask(t *testing.T) {
 	mockService := new(MockTaskService)
 	mockProjectRepo := new(MockProjectRepo)
 	mockK8sService := new(MockK8sService)
-	handler := NewTaskHandler(mockService, mockProjectRepo, mockK8sService)
+	handler := NewTaskHandler(mockService, service.NewAuthorizationService(mockProjectRepo, nil), mockK8sService, "")
 	router := setupTaskTestRouter(handler)
 
 	router.POST("/projects/:id/tasks", handler.CreateTask)
@@ -358,7 +503,7 @@ func TestTaskHandler_GetTask(t *testing.T) {
 	mockService := new(MockTaskService)
 	mockProjectRepo := new(MockProjectRepo)
 	mockK8sService := new(MockK8sService)
-	handler := NewTaskHandler(mockService, mockProjectRepo, mockK8sService)
+	handler := NewTaskHandler(mockService, service.NewAuthorizationService(mockProjectRepo, nil), mockK8sService, "")
 	router := setupTaskTestRouter(handler)
 
 	router.GET("/projects/:id/tasks/:taskId", handler.GetTask)
@@ -436,7 +581,7 @@ func TestTaskHandler_ListProjectTasks(t *testing.T) {
 	mockService := new(MockTaskService)
 	mockProjectRepo := new(MockProjectRepo)
 	mockK8sService := new(MockK8sService)
-	handler := NewTaskHandler(mockService, mockProjectRepo, mockK8sService)
+	handler := NewTaskHandler(mockService, service.NewAuthorizationService(mockProjectRepo, nil), mockK8sService, "")
 	router := setupTaskTestRouter(handler)
 
 	router.GET("/projects/:id/tasks", handler.ListTasks)
@@ -539,7 +684,7 @@ func TestTaskHandler_UpdateTask(t *testing.T) {
 	mockService := new(MockTaskService)
 	mockProjectRepo := new(MockProjectRepo)
 	mockK8sService := new(MockK8sService)
-	handler := NewTaskHandler(mockService, mockProjectRepo, mockK8sService)
+	handler := NewTaskHandler(mockService, service.NewAuthorizationService(mockProjectRepo, nil), mockK8sService, "")
 	router := setupTaskTestRouter(handler)
 
 	router.PATCH("/projects/:id/tasks/:taskId", handler.UpdateTask)
@@ -651,7 +796,7 @@ func TestTaskHandler_MoveTask(t *testing.T) {
 	mockService := new(MockTaskService)
 	mockProjectRepo := new(MockProjectRepo)
 	mockK8sService := new(MockK8sService)
-	handler := NewTaskHandler(mockService, mockProjectRepo, mockK8sService)
+	handler := NewTaskHandler(mockService, service.NewAuthorizationService(mockProjectRepo, nil), mockK8sService, "")
 	router := setupTaskTestRouter(handler)
 
 	router.PATCH("/projects/:id/tasks/:taskId/move", handler.MoveTask)
@@ -718,6 +863,24 @@ func TestTaskHandler_MoveTask(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 
+	t.Run("review required", func(t *testing.T) {
+		projectID := uuid.New()
+		userID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+		taskID := uuid.New()
+
+		mockService.On("MoveTask", mock.Anything, taskID, userID, model.TaskStatusDone, 0).
+			Return(nil, service.ErrReviewRequired).Once()
+
+		body, _ := json.Marshal(map[string]interface{}{"status": "done", "position": 0})
+		req, _ := http.NewRequest("PATCH", "/projects/"+projectID.String()+"/tasks/"+taskID.String()+"/move", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
 	t.Run("missing status field", func(t *testing.T) {
 		projectID := uuid.New()
 		taskID := uuid.New()
@@ -740,7 +903,7 @@ func TestTaskHandler_DeleteTask(t *testing.T) {
 	mockService := new(MockTaskService)
 	mockProjectRepo := new(MockProjectRepo)
 	mockK8sService := new(MockK8sService)
-	handler := NewTaskHandler(mockService, mockProjectRepo, mockK8sService)
+	handler := NewTaskHandler(mockService, service.NewAuthorizationService(mockProjectRepo, nil), mockK8sService, "")
 	router := setupTaskTestRouter(handler)
 
 	router.DELETE("/projects/:id/tasks/:taskId", handler.DeleteTask)
@@ -799,3 +962,156 @@ func TestTaskHandler_DeleteTask(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, w.Code)
 	})
 }
+
+func TestTaskHandler_GetSessionDiff(t *testing.T) {
+	mockService := new(MockTaskService)
+	mockProjectRepo := new(MockProjectRepo)
+	mockK8sService := new(MockK8sService)
+	handler := NewTaskHandler(mockService, service.NewAuthorizationService(mockProjectRepo, nil), mockK8sService, "")
+	router := setupTaskTestRouter(handler)
+
+	router.GET("/projects/:id/tasks/:taskId/sessions/:sessionId/diff", handler.GetSessionDiff)
+
+	userID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	projectID := uuid.New()
+
+	diffURL := func(taskID, sessionID string) string {
+		return "/projects/" + projectID.String() + "/tasks/" + taskID + "/sessions/" + sessionID + "/diff"
+	}
+
+	t.Run("success", func(t *testing.T) {
+		taskID := uuid.New()
+		sessionID := uuid.New()
+
+		expected := &service.WorkspaceDiff{
+			FilesChanged: 1,
+			Additions:    2,
+			Deletions:    1,
+			Files: []service.WorkspaceFileDiff{
+				{Path: "main.go", Status: "modified", Additions: 2, Deletions: 1, Patch: "@@ -1 +1,2 @@\n-a\n+b\n+c\n"},
+			},
+		}
+		mockService.On("GetSessionDiff", mock.Anything, taskID, sessionID, userID).Return(expected, nil).Once()
+
+		req, _ := http.NewRequest("GET", diffURL(taskID.String(), sessionID.String()), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var resp service.WorkspaceDiff
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, resp.FilesChanged)
+		assert.Equal(t, "main.go", resp.Files[0].Path)
+		assert.Equal(t, 2, resp.Files[0].Additions)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid session id", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", diffURL(uuid.New().String(), "invalid-uuid"), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("session not found", func(t *testing.T) {
+		taskID := uuid.New()
+		sessionID := uuid.New()
+		mockService.On("GetSessionDiff", mock.Anything, taskID, sessionID, userID).Return(nil, service.ErrSessionNotFound).Once()
+
+		req, _ := http.NewRequest("GET", diffURL(taskID.String(), sessionID.String()), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("no snapshot recorded", func(t *testing.T) {
+		taskID := uuid.New()
+		sessionID := uuid.New()
+		mockService.On("GetSessionDiff", mock.Anything, taskID, sessionID, userID).Return(nil, service.ErrSessionDiffUnavailable).Once()
+
+		req, _ := http.NewRequest("GET", diffURL(taskID.String(), sessionID.String()), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
+
+func TestTaskHandler_ContinueSession(t *testing.T) {
+	mockService := new(MockTaskService)
+	mockProjectRepo := new(MockProjectRepo)
+	mockK8sService := new(MockK8sService)
+	handler := NewTaskHandler(mockService, service.NewAuthorizationService(mockProjectRepo, nil), mockK8sService, "")
+	router := setupTaskTestRouter(handler)
+
+	router.POST("/projects/:id/tasks/:taskId/sessions/:sessionId/prompt", handler.ContinueSession)
+
+	userID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	projectID := uuid.New()
+
+	promptURL := func(taskID, sessionID string) string {
+		return "/projects/" + projectID.String() + "/tasks/" + taskID + "/sessions/" + sessionID + "/prompt"
+	}
+
+	t.Run("success", func(t *testing.T) {
+		taskID := uuid.New()
+		sessionID := uuid.New()
+
+		session := &model.Session{ID: sessionID, TaskID: taskID, Status: model.SessionStatusRunning}
+		mockService.On("ContinueSession", mock.Anything, taskID, sessionID, userID, "Add a test").Return(session, nil).Once()
+
+		req, _ := http.NewRequest("POST", promptURL(taskID.String(), sessionID.String()), bytes.NewBufferString(`{"prompt": "Add a test"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+
+		var resp model.Session
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.NoError(t, err)
+		assert.Equal(t, sessionID, resp.ID)
+		assert.Equal(t, model.SessionStatusRunning, resp.Status)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("missing prompt", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", promptURL(uuid.New().String(), uuid.New().String()), bytes.NewBufferString(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	errorCases := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"session not found", service.ErrSessionNotFound, http.StatusNotFound},
+		{"task under review", service.ErrInvalidStateTransition, http.StatusBadRequest},
+		{"session cannot be continued", service.ErrSessionNotContinuable, http.StatusConflict},
+		{"workspace busy", service.ErrWorkspaceBusy, http.StatusConflict},
+		{"sidecar unavailable", service.ErrOpenCodeAPICall, http.StatusBadGateway},
+	}
+
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			taskID := uuid.New()
+			sessionID := uuid.New()
+			mockService.On("ContinueSession", mock.Anything, taskID, sessionID, userID, "Add a test").Return(nil, tc.err).Once()
+
+			req, _ := http.NewRequest("POST", promptURL(taskID.String(), sessionID.String()), bytes.NewBufferString(`{"prompt": "Add a test"}`))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.status, w.Code)
+		})
+	}
+}
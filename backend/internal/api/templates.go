@@ -0,0 +1,147 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/npinot/vibe/backend/internal/middleware"
+	"github.com/npinot/vibe/backend/internal/service"
+)
+
+// TemplateHandler manages the project templates new projects can be created from
+type TemplateHandler struct {
+	templateService service.ProjectTemplateService
+}
+
+func NewTemplateHandler(templateService service.ProjectTemplateService) *TemplateHandler {
+	return &TemplateHandler{
+		templateService: templateService,
+	}
+}
+
+// CreateTemplateRequest saves a project as a template. Files are the seed files written into the
+// workspace of new projects, as a map of workspace-relative path to content.
+type CreateTemplateRequest struct {
+	ProjectID   string            `json:"project_id" binding:"required"`
+	Name        string            `json:"name" binding:"required"`
+	Description string            `json:"description"`
+	Files       map[string]string `json:"files"`
+}
+
+// ListTemplates returns the builtin templates and the templates saved by the current user
+// GET /api/templates
+func (h *TemplateHandler) ListTemplates(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	if userID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	templates, err := h.templateService.ListTemplates(c.Request.Context(), userID)
+	if err != nil {
+		respondTemplateError(c, err, "Failed to fetch templates")
+		return
+	}
+
+	c.JSON(http.StatusOK, templates)
+}
+
+// GetTemplate returns a template
+// GET /api/templates/:id
+func (h *TemplateHandler) GetTemplate(c *gin.Context) {
+	userID, templateID, ok := templateParams(c)
+	if !ok {
+		return
+	}
+
+	template, err := h.templateService.GetTemplate(c.Request.Context(), templateID, userID)
+	if err != nil {
+		respondTemplateError(c, err, "Failed to fetch template")
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// CreateTemplate saves the repository, configuration, tasks and resource profile of a project as a template
+// POST /api/templates
+func (h *TemplateHandler) CreateTemplate(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	if userID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req CreateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	projectID, err := uuid.Parse(req.ProjectID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	template, err := h.templateService.CreateTemplateFromProject(c.Request.Context(), projectID, userID, req.Name, req.Description, req.Files)
+	if err != nil {
+		respondTemplateError(c, err, "Failed to save template")
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// DeleteTemplate deletes a template saved by the current user
+// DELETE /api/templates/:id
+func (h *TemplateHandler) DeleteTemplate(c *gin.Context) {
+	userID, templateID, ok := templateParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.templateService.DeleteTemplate(c.Request.Context(), templateID, userID); err != nil {
+		respondTemplateError(c, err, "Failed to delete template")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// templateParams reads the current user and the template ID of the route, writing the error response on failure
+func templateParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID := middleware.GetCurrentUserID(c)
+	if userID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	templateID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, templateID, true
+}
+
+func respondTemplateError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrTemplateNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+	case errors.Is(err, service.ErrProjectNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+	case errors.Is(err, service.ErrUnauthorized):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+	case errors.Is(err, service.ErrInvalidTemplate):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		log.Printf("[Template] %s: %v", message, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,240 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/npinot/vibe/backend/internal/model"
+	"github.com/npinot/vibe/backend/internal/service"
+)
+
+type MockTemplateService struct {
+	mock.Mock
+}
+
+func (m *MockTemplateService) ListTemplates(ctx context.Context, userID uuid.UUID) ([]model.ProjectTemplate, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.ProjectTemplate), args.Error(1)
+}
+
+func (m *MockTemplateService) GetTemplate(ctx context.Context, id, userID uuid.UUID) (*model.ProjectTemplate, error) {
+	args := m.Called(ctx, id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ProjectTemplate), args.Error(1)
+}
+
+func (m *MockTemplateService) CreateTemplateFromProject(ctx context.Context, projectID, userID uuid.UUID, name, description string, files map[string]string) (*model.ProjectTemplate, error) {
+	args := m.Called(ctx, projectID, userID, name, description, files)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ProjectTemplate), args.Error(1)
+}
+
+func (m *MockTemplateService) DeleteTemplate(ctx context.Context, id, userID uuid.UUID) error {
+	args := m.Called(ctx, id, userID)
+	return args.Error(0)
+}
+
+func (m *MockTemplateService) CreateProjectFromTemplate(ctx context.Context, templateID, userID uuid.UUID, opts service.TemplateProjectOptions) (*model.Project, error) {
+	args := m.Called(ctx, templateID, userID, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Project), args.Error(1)
+}
+
+func setupTemplateTestRouter() (*MockTemplateService, http.Handler) {
+	mockService := new(MockTemplateService)
+	handler := NewTemplateHandler(mockService)
+	router := setupProjectTestRouter(nil)
+	router.GET("/templates", handler.ListTemplates)
+	router.POST("/templates", handler.CreateTemplate)
+	router.GET("/templates/:id", handler.GetTemplate)
+	router.DELETE("/templates/:id", handler.DeleteTemplate)
+	return mockService, router
+}
+
+func TestTemplateHandler(t *testing.T) {
+	userID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	templateID := uuid.New()
+	projectID := uuid.New()
+
+	t.Run("lists templates", func(t *testing.T) {
+		mockService, router := setupTemplateTestRouter()
+		mockService.On("ListTemplates", mock.Anything, userID).Return([]model.ProjectTemplate{
+			{ID: templateID, Name: "Go service", Builtin: true, ResourceProfile: "medium"},
+		}, nil)
+
+		req, _ := http.NewRequest("GET", "/templates", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response []map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response, 1)
+		assert.Equal(t, "Go service", response[0]["name"])
+		assert.Equal(t, true, response[0]["builtin"])
+	})
+
+	t.Run("gets template", func(t *testing.T) {
+		mockService, router := setupTemplateTestRouter()
+		mockService.On("GetTemplate", mock.Anything, templateID, userID).
+			Return(&model.ProjectTemplate{ID: templateID, UserID: userID, Name: "Service"}, nil)
+
+		req, _ := http.NewRequest("GET", "/templates/"+templateID.String(), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"name":"Service"`)
+	})
+
+	t.Run("saves project as template", func(t *testing.T) {
+		mockService, router := setupTemplateTestRouter()
+		files := map[string]string{"README.md": "# Service\n"}
+		mockService.On("CreateTemplateFromProject", mock.Anything, projectID, userID, "Service", "Our services", files).
+			Return(&model.ProjectTemplate{ID: templateID, UserID: userID, Name: "Service", Files: files}, nil)
+
+		body, _ := json.Marshal(CreateTemplateRequest{ProjectID: projectID.String(), Name: "Service", Description: "Our services", Files: files})
+		req, _ := http.NewRequest("POST", "/templates", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("saving requires a valid project ID", func(t *testing.T) {
+		_, router := setupTemplateTestRouter()
+
+		req, _ := http.NewRequest("POST", "/templates", bytes.NewReader([]byte(`{"project_id":"nope","name":"Service"}`)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("deletes template", func(t *testing.T) {
+		mockService, router := setupTemplateTestRouter()
+		mockService.On("DeleteTemplate", mock.Anything, templateID, userID).Return(nil)
+
+		req, _ := http.NewRequest("DELETE", "/templates/"+templateID.String(), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("invalid template ID", func(t *testing.T) {
+		_, router := setupTemplateTestRouter()
+
+		req, _ := http.NewRequest("GET", "/templates/not-a-uuid", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	errorCases := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"template not found", service.ErrTemplateNotFound, http.StatusNotFound},
+		{"project not found", service.ErrProjectNotFound, http.StatusNotFound},
+		{"unauthorized", fmt.Errorf("%w: builtin templates cannot be deleted", service.ErrUnauthorized), http.StatusForbidden},
+		{"invalid template", fmt.Errorf("%w: name cannot be empty", service.ErrInvalidTemplate), http.StatusBadRequest},
+		{"internal error", errors.New("boom"), http.StatusInternalServerError},
+	}
+	for _, tc := range errorCases {
+		t.Run("delete "+tc.name, func(t *testing.T) {
+			mockService, router := setupTemplateTestRouter()
+			mockService.On("DeleteTemplate", mock.Anything, templateID, userID).Return(tc.err)
+
+			req, _ := http.NewRequest("DELETE", "/templates/"+templateID.String(), nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestProjectHandler_CreateProjectFromTemplate(t *testing.T) {
+	userID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	templateID := uuid.New()
+
+	setup := func() (*MockProjectService, *MockTemplateService, http.Handler) {
+		projectService := new(MockProjectService)
+		templateService := new(MockTemplateService)
+		handler := NewProjectHandler(projectService, templateService)
+		router := setupProjectTestRouter(handler)
+		router.POST("/projects", handler.CreateProject)
+		return projectService, templateService, router
+	}
+
+	t.Run("creates the project from the template", func(t *testing.T) {
+		projectService, templateService, router := setup()
+		templateService.On("CreateProjectFromTemplate", mock.Anything, templateID, userID, service.TemplateProjectOptions{
+			Name:      "Billing",
+			RepoOpts:  service.RepoCloneOptions{Credential: "ghp_token"},
+			Resources: service.ResourceSelection{Profile: "large"},
+			APIKey:    "sk-ant-key",
+		}).Return(&model.Project{ID: uuid.New(), UserID: userID, Name: "Billing", Status: model.ProjectStatusReady}, nil)
+
+		body := `{"name":"Billing","template_id":"` + templateID.String() + `","repo_credential":"ghp_token","resource_profile":"large","api_key":"sk-ant-key"}`
+		req, _ := http.NewRequest("POST", "/projects", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		templateService.AssertExpectations(t)
+		projectService.AssertNotCalled(t, "CreateProject")
+	})
+
+	t.Run("unknown template", func(t *testing.T) {
+		_, templateService, router := setup()
+		templateService.On("CreateProjectFromTemplate", mock.Anything, templateID, userID, mock.Anything).Return(nil, service.ErrTemplateNotFound)
+
+		body := `{"name":"Billing","template_id":"` + templateID.String() + `"}`
+		req, _ := http.NewRequest("POST", "/projects", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("invalid template ID", func(t *testing.T) {
+		_, _, router := setup()
+
+		req, _ := http.NewRequest("POST", "/projects", bytes.NewReader([]byte(`{"name":"Billing","template_id":"go"}`)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,253 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+
+	"github.com/npinot/vibe/backend/internal/middleware"
+	"github.com/npinot/vibe/backend/internal/model"
+	"github.com/npinot/vibe/backend/internal/service"
+)
+
+// TerminalHandler proxies interactive terminals from the session-proxy sidecar of project pods
+type TerminalHandler struct {
+	authz        service.AuthorizationService
+	k8sService   service.KubernetesService
+	hibernator   *service.ProjectHibernator // keeps pods with open terminals awake; nil without hibernation
+	sharedSecret string
+	idleTimeout  time.Duration // closes terminals without client input for this long; zero disables
+	maxPerUser   int           // concurrent terminals per user; zero means no limit
+	sidecarPort  int
+	dialer       *websocket.Dialer
+	upgrader     websocket.Upgrader
+
+	mu     sync.Mutex
+	active map[uuid.UUID]int
+}
+
+// NewTerminalHandler creates a new terminal handler
+func NewTerminalHandler(authz service.AuthorizationService, k8sService service.KubernetesService, hibernator *service.ProjectHibernator, sharedSecret string, idleTimeout time.Duration, maxPerUser int) *TerminalHandler {
+	return &TerminalHandler{
+		authz:        authz,
+		k8sService:   k8sService,
+		hibernator:   hibernator,
+		sharedSecret: sharedSecret,
+		idleTimeout:  idleTimeout,
+		maxPerUser:   maxPerUser,
+		sidecarPort:  3002,
+		dialer: &websocket.Dialer{
+			HandshakeTimeout: 10 * time.Second,
+		},
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool {
+				return true // TODO: validate origin in production
+			},
+		},
+		active: make(map[uuid.UUID]int),
+	}
+}
+
+// ProjectTerminal opens a shell in the project workspace over WebSocket.
+// Terminal output arrives as binary frames; the client sends input as binary frames or
+// {"type":"input","data":"..."} and resizes with {"type":"resize","cols":N,"rows":N}.
+// The connection ends with {"type":"exit","code":N} when the shell exits, or
+// {"type":"exit","reason":"idle timeout"} after the idle timeout.
+func (h *TerminalHandler) ProjectTerminal(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	userID := middleware.GetCurrentUserID(c)
+	if userID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// A shell can change anything in the workspace, so it takes the maintainer role
+	project, err := h.authz.AuthorizeProject(c.Request.Context(), projectID, userID, model.ProjectRoleMaintainer)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrProjectNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		case errors.Is(err, service.ErrUnauthorized):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get project"})
+		}
+		return
+	}
+
+	podIP, err := h.k8sService.GetPodIP(c.Request.Context(), project.PodName, project.PodNamespace)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Project pod is not running"})
+		return
+	}
+
+	if !h.acquire(userID) {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": fmt.Sprintf("Too many open terminals (limit %d)", h.maxPerUser)})
+		return
+	}
+	defer h.release(userID)
+
+	if h.hibernator != nil {
+		defer h.hibernator.Hold(project.PodName, project.PodNamespace)()
+	}
+
+	// Connect to the sidecar first, so failures can still be answered with a status code
+	sidecarConn, _, err := h.dialer.DialContext(c.Request.Context(), h.sidecarTerminalURL(podIP, c), h.sidecarHeader())
+	if err != nil {
+		log.Printf("[Terminal] Failed to connect to session-proxy of project %s: %v", projectID, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to connect to terminal"})
+		return
+	}
+	defer sidecarConn.Close()
+
+	clientConn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+	defer clientConn.Close()
+
+	log.Printf("[Terminal] User %s opened terminal for project %s", userID, projectID)
+	h.relay(clientConn, sidecarConn)
+	log.Printf("[Terminal] User %s closed terminal for project %s", userID, projectID)
+}
+
+// relay copies frames between the client and the sidecar until either side leaves
+// or the client stays idle for too long
+func (h *TerminalHandler) relay(clientConn, sidecarConn *websocket.Conn) {
+	// Both the sidecar copy and the idle timeout write to the client
+	var clientMu sync.Mutex
+	writeClient := func(messageType int, data []byte) error {
+		clientMu.Lock()
+		defer clientMu.Unlock()
+		return clientConn.WriteMessage(messageType, data)
+	}
+
+	// Sidecar -> Client
+	sidecarDone := make(chan struct{})
+	go func() {
+		defer close(sidecarDone)
+		for {
+			messageType, message, err := sidecarConn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err := writeClient(messageType, message); err != nil {
+				return
+			}
+		}
+	}()
+
+	// Client -> Sidecar
+	clientDone := make(chan struct{})
+	activity := make(chan struct{}, 1)
+	go func() {
+		defer close(clientDone)
+		for {
+			messageType, message, err := clientConn.ReadMessage()
+			if err != nil {
+				return
+			}
+			select {
+			case activity <- struct{}{}:
+			default:
+			}
+			if err := sidecarConn.WriteMessage(messageType, message); err != nil {
+				return
+			}
+		}
+	}()
+
+	// A nil channel never fires, leaving terminals open without an idle timeout
+	var timer *time.Timer
+	var idle <-chan time.Time
+	if h.idleTimeout > 0 {
+		timer = time.NewTimer(h.idleTimeout)
+		defer timer.Stop()
+		idle = timer.C
+	}
+
+	for {
+		select {
+		case <-activity:
+			if timer != nil {
+				if !timer.Stop() {
+					<-timer.C
+				}
+				timer.Reset(h.idleTimeout)
+			}
+		case <-idle:
+			clientMu.Lock()
+			clientConn.WriteJSON(gin.H{"type": "exit", "reason": "idle timeout"})
+			clientMu.Unlock()
+			writeClient(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "idle timeout"))
+			return
+		case <-sidecarDone:
+			writeClient(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+			return
+		case <-clientDone:
+			return
+		}
+	}
+}
+
+// sidecarTerminalURL builds the session-proxy terminal URL, passing on the initial terminal size
+func (h *TerminalHandler) sidecarTerminalURL(podIP string, c *gin.Context) string {
+	query := url.Values{}
+	for _, key := range []string{"cols", "rows"} {
+		if _, err := strconv.ParseUint(c.Query(key), 10, 16); err == nil {
+			query.Set(key, c.Query(key))
+		}
+	}
+
+	u := url.URL{
+		Scheme:   "ws",
+		Host:     fmt.Sprintf("%s:%d", podIP, h.sidecarPort),
+		Path:     fmt.Sprintf("/session/%s/pty/connect", uuid.New()),
+		RawQuery: query.Encode(),
+	}
+	return u.String()
+}
+
+func (h *TerminalHandler) sidecarHeader() http.Header {
+	header := http.Header{}
+	if h.sharedSecret != "" {
+		header.Set("Authorization", "Bearer "+h.sharedSecret)
+	}
+	return header
+}
+
+// acquire takes one of the user's terminal slots, reporting false when all are in use
+func (h *TerminalHandler) acquire(userID uuid.UUID) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.maxPerUser > 0 && h.active[userID] >= h.maxPerUser {
+		return false
+	}
+	h.active[userID]++
+	return true
+}
+
+func (h *TerminalHandler) release(userID uuid.UUID) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.active[userID]--
+	if h.active[userID] <= 0 {
+		delete(h.active, userID)
+	}
+}
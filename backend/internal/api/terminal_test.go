@@ -0,0 +1,199 @@
+package api
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/npinot/vibe/backend/internal/model"
+	"github.com/npinot/vibe/backend/internal/service"
+)
+
+var (
+	terminalTestUserID    = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	terminalTestProjectID = uuid.MustParse("00000000-0000-0000-0000-000000000002")
+)
+
+// echoTerminalSidecar fakes the session-proxy terminal by echoing every frame back
+func echoTerminalSidecar(t *testing.T) http.HandlerFunc {
+	upgrader := websocket.Upgrader{}
+	return func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		assert.True(t, strings.HasPrefix(r.URL.Path, "/session/"))
+		assert.True(t, strings.HasSuffix(r.URL.Path, "/pty/connect"))
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"size":"`+r.URL.Query().Get("cols")+"x"+r.URL.Query().Get("rows")+`"}`))
+		for {
+			messageType, message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if string(message) == "exit" {
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+				return
+			}
+			conn.WriteMessage(messageType, message)
+		}
+	}
+}
+
+func setupTerminalTest(t *testing.T, idleTimeout time.Duration, maxPerUser int) (*httptest.Server, *MockFileProjectRepository, *MockFileK8sService) {
+	t.Helper()
+
+	sidecar := httptest.NewServer(echoTerminalSidecar(t))
+	t.Cleanup(sidecar.Close)
+	addr := sidecar.Listener.Addr().(*net.TCPAddr)
+
+	mockRepo := new(MockFileProjectRepository)
+	mockK8s := new(MockFileK8sService)
+	mockK8s.On("GetPodIP", mock.Anything, "test-pod", "test-ns").Return(addr.IP.String(), nil)
+
+	handler := NewTerminalHandler(service.NewAuthorizationService(mockRepo, nil), mockK8s, nil, "secret", idleTimeout, maxPerUser)
+	handler.sidecarPort = addr.Port
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("currentUser", &model.User{ID: terminalTestUserID, Email: "test@example.com"})
+		c.Next()
+	})
+	router.GET("/api/projects/:id/terminal", handler.ProjectTerminal)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	return server, mockRepo, mockK8s
+}
+
+func terminalProject(ownerID uuid.UUID) *model.Project {
+	return &model.Project{
+		ID:           terminalTestProjectID,
+		UserID:       ownerID,
+		PodName:      "test-pod",
+		PodNamespace: "test-ns",
+	}
+}
+
+func dialProjectTerminal(server *httptest.Server, projectID string) (*websocket.Conn, *http.Response, error) {
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/projects/" + projectID + "/terminal?cols=120&rows=40"
+	return websocket.DefaultDialer.Dial(url, nil)
+}
+
+func TestTerminalHandler_ProjectTerminal(t *testing.T) {
+	t.Run("relays frames to the sidecar", func(t *testing.T) {
+		server, mockRepo, _ := setupTerminalTest(t, time.Minute, 3)
+		mockRepo.On("FindByID", mock.Anything, terminalTestProjectID).Return(terminalProject(terminalTestUserID), nil)
+
+		conn, _, err := dialProjectTerminal(server, terminalTestProjectID.String())
+		require.NoError(t, err)
+		defer conn.Close()
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+		_, message, err := conn.ReadMessage()
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"size":"120x40"}`, string(message))
+
+		require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, []byte("ls\n")))
+		messageType, message, err := conn.ReadMessage()
+		require.NoError(t, err)
+		assert.Equal(t, websocket.BinaryMessage, messageType)
+		assert.Equal(t, "ls\n", string(message))
+
+		// The client connection ends when the sidecar closes the terminal
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("exit")))
+		_, _, err = conn.ReadMessage()
+		assert.True(t, websocket.IsCloseError(err, websocket.CloseNormalClosure), "unexpected error: %v", err)
+	})
+
+	t.Run("closes idle terminals", func(t *testing.T) {
+		server, mockRepo, _ := setupTerminalTest(t, 100*time.Millisecond, 3)
+		mockRepo.On("FindByID", mock.Anything, terminalTestProjectID).Return(terminalProject(terminalTestUserID), nil)
+
+		conn, _, err := dialProjectTerminal(server, terminalTestProjectID.String())
+		require.NoError(t, err)
+		defer conn.Close()
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+		// Skip the sidecar greeting
+		_, _, err = conn.ReadMessage()
+		require.NoError(t, err)
+
+		_, message, err := conn.ReadMessage()
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"type":"exit","reason":"idle timeout"}`, string(message))
+
+		_, _, err = conn.ReadMessage()
+		assert.True(t, websocket.IsCloseError(err, websocket.CloseNormalClosure), "unexpected error: %v", err)
+	})
+
+	t.Run("limits concurrent terminals per user", func(t *testing.T) {
+		server, mockRepo, _ := setupTerminalTest(t, time.Minute, 1)
+		mockRepo.On("FindByID", mock.Anything, terminalTestProjectID).Return(terminalProject(terminalTestUserID), nil)
+
+		first, _, err := dialProjectTerminal(server, terminalTestProjectID.String())
+		require.NoError(t, err)
+
+		_, resp, err := dialProjectTerminal(server, terminalTestProjectID.String())
+		require.Error(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+
+		// Closing the first terminal frees its slot
+		first.Close()
+		assert.Eventually(t, func() bool {
+			conn, _, err := dialProjectTerminal(server, terminalTestProjectID.String())
+			if err != nil {
+				return false
+			}
+			conn.Close()
+			return true
+		}, 5*time.Second, 20*time.Millisecond)
+	})
+
+	t.Run("rejects projects of other users", func(t *testing.T) {
+		server, mockRepo, mockK8s := setupTerminalTest(t, time.Minute, 3)
+		mockRepo.On("FindByID", mock.Anything, terminalTestProjectID).Return(terminalProject(uuid.New()), nil)
+
+		_, resp, err := dialProjectTerminal(server, terminalTestProjectID.String())
+		require.Error(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		mockK8s.AssertNotCalled(t, "GetPodIP", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("project not found", func(t *testing.T) {
+		server, mockRepo, _ := setupTerminalTest(t, time.Minute, 3)
+		mockRepo.On("FindByID", mock.Anything, terminalTestProjectID).Return(nil, gorm.ErrRecordNotFound)
+
+		_, resp, err := dialProjectTerminal(server, terminalTestProjectID.String())
+		require.Error(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("invalid project ID", func(t *testing.T) {
+		server, _, _ := setupTerminalTest(t, time.Minute, 3)
+
+		_, resp, err := dialProjectTerminal(server, "not-a-uuid")
+		require.Error(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
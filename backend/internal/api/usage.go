@@ -0,0 +1,57 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/npinot/vibe/backend/internal/middleware"
+	"github.com/npinot/vibe/backend/internal/service"
+)
+
+// UsageHandler reports the token usage and cost of projects
+type UsageHandler struct {
+	usageService service.UsageService
+}
+
+func NewUsageHandler(usageService service.UsageService) *UsageHandler {
+	return &UsageHandler{
+		usageService: usageService,
+	}
+}
+
+// GetProjectUsage returns the tokens used by the project's sessions and what they cost,
+// in total and by day, model and task
+// GET /api/projects/:id/usage
+func (h *UsageHandler) GetProjectUsage(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	if userID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	usage, err := h.usageService.GetProjectUsage(c.Request.Context(), projectID, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrProjectNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		case errors.Is(err, service.ErrUnauthorized):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		default:
+			log.Printf("[GetProjectUsage] Failed to fetch usage of project %s: %v", projectID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch project usage"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, usage)
+}
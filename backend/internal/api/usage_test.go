@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/npinot/vibe/backend/internal/model"
+	"github.com/npinot/vibe/backend/internal/service"
+)
+
+type MockUsageService struct {
+	mock.Mock
+}
+
+func (m *MockUsageService) GetProjectUsage(ctx context.Context, projectID, userID uuid.UUID) (*model.ProjectUsage, error) {
+	args := m.Called(ctx, projectID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ProjectUsage), args.Error(1)
+}
+
+func TestUsageHandler_GetProjectUsage(t *testing.T) {
+	userID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	projectID := uuid.New()
+
+	setup := func() (*MockUsageService, http.Handler) {
+		mockService := new(MockUsageService)
+		handler := NewUsageHandler(mockService)
+		router := setupProjectTestRouter(nil)
+		router.GET("/projects/:id/usage", handler.GetProjectUsage)
+		return mockService, router
+	}
+
+	t.Run("returns project usage", func(t *testing.T) {
+		mockService, router := setup()
+		taskID := uuid.New()
+		usage := &model.ProjectUsage{
+			ProjectID: projectID,
+			Total:     model.UsageTotals{Sessions: 2, InputTokens: 3000, OutputTokens: 300, CostUSD: 2.5},
+			ByDay:     []model.DailyUsage{{Day: "2026-03-01", UsageTotals: model.UsageTotals{Sessions: 2, CostUSD: 2.5}}},
+			ByModel:   []model.ModelUsage{{ModelProvider: "openai", ModelName: "gpt-4o", UsageTotals: model.UsageTotals{Sessions: 2, CostUSD: 2.5}}},
+			ByTask:    []model.TaskUsage{{TaskID: taskID, TaskTitle: "Build feature", UsageTotals: model.UsageTotals{Sessions: 2, CostUSD: 2.5}}},
+		}
+		mockService.On("GetProjectUsage", mock.Anything, projectID, userID).Return(usage, nil)
+
+		req, _ := http.NewRequest("GET", "/projects/"+projectID.String()+"/usage", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 2.5, response["total"].(map[string]interface{})["cost_usd"])
+		assert.Equal(t, "2026-03-01", response["by_day"].([]interface{})[0].(map[string]interface{})["day"])
+		assert.Equal(t, "gpt-4o", response["by_model"].([]interface{})[0].(map[string]interface{})["model_name"])
+		assert.Equal(t, "Build feature", response["by_task"].([]interface{})[0].(map[string]interface{})["task_title"])
+	})
+
+	t.Run("invalid project ID", func(t *testing.T) {
+		_, router := setup()
+
+		req, _ := http.NewRequest("GET", "/projects/not-a-uuid/usage", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	errorCases := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"project not found", service.ErrProjectNotFound, http.StatusNotFound},
+		{"other user's project", service.ErrUnauthorized, http.StatusForbidden},
+		{"service error", errors.New("database error"), http.StatusInternalServerError},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService, router := setup()
+			mockService.On("GetProjectUsage", mock.Anything, projectID, userID).Return(nil, tc.err)
+
+			req, _ := http.NewRequest("GET", "/projects/"+projectID.String()+"/usage", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.status, w.Code)
+		})
+	}
+}
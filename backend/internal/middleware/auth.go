@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"crypto/subtle"
 	"fmt"
 	"net/http"
 	"strings"
@@ -91,6 +92,49 @@ func (m *AuthMiddleware) JWTAuth() gin.HandlerFunc {
 	}
 }
 
+// RequireAdmin only lets through users listed in the admin emails; it must run after JWTAuth
+func (m *AuthMiddleware) RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, err := GetCurrentUser(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+			c.Abort()
+			return
+		}
+
+		for _, email := range m.cfg.AdminEmails {
+			if strings.EqualFold(email, user.Email) {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		c.Abort()
+	}
+}
+
+// SharedSecretAuth only lets through callers presenting the OpenCode shared secret as bearer
+// token, i.e. the project pod sidecars. An empty secret disables the check, like the sidecars do.
+func (m *AuthMiddleware) SharedSecretAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		secret := m.cfg.OpenCodeSharedSecret
+		if secret == "" {
+			c.Next()
+			return
+		}
+
+		parts := strings.SplitN(c.GetHeader("Authorization"), " ", 2)
+		if len(parts) != 2 || parts[0] != "Bearer" || subtle.ConstantTimeCompare([]byte(parts[1]), []byte(secret)) != 1 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid shared secret"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
 func GetCurrentUser(c *gin.Context) (*model.User, error) {
 	user, exists := c.Get("currentUser")
 	if !exists {
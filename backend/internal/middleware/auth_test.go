@@ -346,3 +346,71 @@ func TestGetCurrentUser(t *testing.T) {
 		assert.Contains(t, err.Error(), "invalid user type in context")
 	})
 }
+
+func TestAuthMiddleware_RequireAdmin(t *testing.T) {
+	cfg := &config.Config{AdminEmails: []string{"admin@example.com"}}
+	router, middleware := setupTestMiddleware(cfg, new(MockUserRepository))
+
+	var currentUser *model.User
+	router.GET("/admin", func(c *gin.Context) {
+		if currentUser != nil {
+			c.Set("currentUser", currentUser)
+		}
+		c.Next()
+	}, middleware.RequireAdmin(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name       string
+		user       *model.User
+		wantStatus int
+	}{
+		{"admin", &model.User{ID: uuid.New(), Email: "Admin@Example.com"}, http.StatusOK},
+		{"regular user", &model.User{ID: uuid.New(), Email: "test@example.com"}, http.StatusForbidden},
+		{"no user", nil, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			currentUser = tt.user
+
+			req, _ := http.NewRequest("GET", "/admin", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestAuthMiddleware_SharedSecretAuth(t *testing.T) {
+	tests := []struct {
+		name       string
+		secret     string
+		header     string
+		wantStatus int
+	}{
+		{"valid secret", "pod-secret", "Bearer pod-secret", http.StatusOK},
+		{"wrong secret", "pod-secret", "Bearer guess", http.StatusUnauthorized},
+		{"missing header", "pod-secret", "", http.StatusUnauthorized},
+		{"user token scheme", "pod-secret", "pod-secret", http.StatusUnauthorized},
+		{"no secret configured", "", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, middleware := setupTestMiddleware(&config.Config{OpenCodeSharedSecret: tt.secret}, new(MockUserRepository))
+			router.PATCH("/sessions/:id/status", middleware.SharedSecretAuth(), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req, _ := http.NewRequest("PATCH", "/sessions/"+uuid.New().String()+"/status", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
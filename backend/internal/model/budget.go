@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type BudgetScope string
+
+const (
+	// BudgetScopeProject budgets cover the sessions of one project
+	BudgetScopeProject BudgetScope = "project"
+	// BudgetScopeUser budgets cover the sessions of all projects a user owns
+	BudgetScopeUser BudgetScope = "user"
+)
+
+// Budget limits what the sessions of a project or user may spend per calendar month (UTC).
+// A nil limit is not enforced.
+type Budget struct {
+	ID             uuid.UUID   `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	Scope          BudgetScope `gorm:"column:scope;type:varchar(20);not null" json:"scope"`
+	ScopeID        uuid.UUID   `gorm:"type:uuid;column:scope_id;not null" json:"scope_id"`
+	MonthlyCostUSD *float64    `gorm:"column:monthly_cost_usd;type:numeric(12,2)" json:"monthly_cost_usd,omitempty"`
+	MonthlyTokens  *int64      `gorm:"column:monthly_tokens" json:"monthly_tokens,omitempty"` // input and output tokens
+	WarnPercent    int         `gorm:"column:warn_percent;not null;default:80" json:"warn_percent"`
+	WarnedPeriod   string      `gorm:"column:warned_period;type:varchar(7)" json:"-"` // month (YYYY-MM) the warning was last posted for
+	CreatedAt      time.Time   `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt      time.Time   `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (Budget) TableName() string {
+	return "budgets"
+}
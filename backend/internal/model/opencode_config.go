@@ -9,6 +9,9 @@ import (
 	"github.com/google/uuid"
 )
 
+// DefaultAIReviewEnabled is whether new configurations run the AI review stage, matching the column default
+const DefaultAIReviewEnabled = true
+
 // OpenCodeConfig represents an OpenCode agent configuration with versioning
 type OpenCodeConfig struct {
 	ID        uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
@@ -36,6 +39,12 @@ type OpenCodeConfig struct {
 	MaxIterations  int     `gorm:"column:max_iterations;default:10" json:"max_iterations"`
 	TimeoutSeconds int     `gorm:"column:timeout_seconds;default:300" json:"timeout_seconds"`
 
+	// AI review configuration; the reviewer uses the main model unless ReviewModelName is set.
+	// AIReviewEnabled has no GORM default so that false is written; callers start from DefaultAIReviewEnabled.
+	AIReviewEnabled    bool    `gorm:"column:ai_review_enabled;not null" json:"ai_review_enabled"`
+	ReviewModelName    *string `gorm:"column:review_model_name;size:100" json:"review_model_name,omitempty"`
+	ReviewSystemPrompt *string `gorm:"column:review_system_prompt;type:text" json:"review_system_prompt,omitempty"`
+
 	// Metadata
 	CreatedBy uuid.UUID `gorm:"type:uuid;column:created_by;not null" json:"created_by"`
 	CreatedAt time.Time `gorm:"column:created_at;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
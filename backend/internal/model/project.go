@@ -11,9 +11,11 @@ type ProjectStatus string
 
 const (
 	ProjectStatusInitializing ProjectStatus = "initializing"
+	ProjectStatusCloning      ProjectStatus = "cloning"
 	ProjectStatusReady        ProjectStatus = "ready"
+	ProjectStatusHibernated   ProjectStatus = "hibernated" // idle pod deleted, workspace PVC kept
 	ProjectStatusError        ProjectStatus = "error"
-	ProjectStatusArchived     ProjectStatus = "archived"
+	ProjectStatusArchived     ProjectStatus = "archived" // workspace exported to the archive store, pod and PVC deleted
 )
 
 type Project struct {
@@ -24,6 +26,15 @@ type Project struct {
 	Description string    `gorm:"column:description;type:text" json:"description"`
 	RepoURL     string    `gorm:"column:repo_url;type:text" json:"repo_url"`
 
+	// Repository clone settings
+	RepoRef                 string `gorm:"column:repo_ref;size:255" json:"repo_ref,omitempty"`
+	RepoCloneDepth          int    `gorm:"column:repo_clone_depth;default:0" json:"repo_clone_depth"`
+	RepoCredentialEncrypted []byte `gorm:"column:repo_credential_encrypted;type:bytea" json:"-"` // Never expose in JSON
+
+	// RepoCredential holds the decrypted credential while the pod is being provisioned.
+	// It is never persisted nor serialized.
+	RepoCredential string `gorm:"-" json:"-"`
+
 	// Kubernetes metadata
 	PodName          string     `gorm:"column:pod_name" json:"pod_name"`
 	PodNamespace     string     `gorm:"column:pod_namespace" json:"pod_namespace"`
@@ -32,6 +43,27 @@ type Project struct {
 	PodCreatedAt     *time.Time `gorm:"column:pod_created_at" json:"pod_created_at"`
 	PodError         string     `gorm:"column:pod_error;type:text" json:"pod_error"`
 
+	// Hibernation of idle project pods
+	LastActivityAt *time.Time `gorm:"column:last_activity_at" json:"last_activity_at,omitempty"`
+	HibernatedAt   *time.Time `gorm:"column:hibernated_at" json:"hibernated_at,omitempty"`
+
+	// Resources of the project pod containers and workspace PVC, resolved from the resource profile
+	ResourceProfile string `gorm:"column:resource_profile;size:20;default:medium" json:"resource_profile"`
+	CPURequest      string `gorm:"column:cpu_request;size:20;default:100m" json:"cpu_request"`
+	CPULimit        string `gorm:"column:cpu_limit;size:20;default:1000m" json:"cpu_limit"`
+	MemoryRequest   string `gorm:"column:memory_request;size:20;default:256Mi" json:"memory_request"`
+	MemoryLimit     string `gorm:"column:memory_limit;size:20;default:1Gi" json:"memory_limit"`
+	StorageSize     string `gorm:"column:storage_size;size:20;default:1Gi" json:"storage_size"`
+	StorageClass    string `gorm:"column:storage_class;size:255" json:"storage_class,omitempty"`
+
+	// Workspace tarball of an archived project in the archive store
+	ArchiveKey  string     `gorm:"column:archive_key;size:255" json:"-"`
+	ArchiveSize int64      `gorm:"column:archive_size;default:0" json:"archive_size,omitempty"`
+	ArchivedAt  *time.Time `gorm:"column:archived_at" json:"archived_at,omitempty"`
+
+	// Project this project was forked from
+	ParentProjectID *uuid.UUID `gorm:"type:uuid;column:parent_project_id;index" json:"parent_project_id,omitempty"`
+
 	Status    ProjectStatus  `gorm:"column:status;type:varchar(20);default:'initializing';index" json:"status"`
 	CreatedAt time.Time      `gorm:"column:created_at" json:"created_at"`
 	UpdatedAt time.Time      `gorm:"column:updated_at" json:"updated_at"`
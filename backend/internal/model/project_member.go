@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProjectRole is what a user may do on a project; each role includes the ones below it
+type ProjectRole string
+
+const (
+	ProjectRoleOwner      ProjectRole = "owner"      // creator of the project; manages its settings, resources and members
+	ProjectRoleMaintainer ProjectRole = "maintainer" // executes tasks, edits files and the configuration
+	ProjectRoleViewer     ProjectRole = "viewer"     // read-only access to tasks, files and streams
+)
+
+// ProjectMember gives a user other than the owner access to a project
+type ProjectMember struct {
+	ID        uuid.UUID   `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	ProjectID uuid.UUID   `gorm:"type:uuid;column:project_id;not null;uniqueIndex:idx_project_members_project_user" json:"project_id"`
+	UserID    uuid.UUID   `gorm:"type:uuid;column:user_id;not null;uniqueIndex:idx_project_members_project_user;index" json:"user_id"`
+	Role      ProjectRole `gorm:"column:role;type:varchar(20);not null" json:"role"`
+	InvitedBy uuid.UUID   `gorm:"type:uuid;column:invited_by;not null" json:"invited_by"`
+	CreatedAt time.Time   `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt time.Time   `gorm:"column:updated_at" json:"updated_at"`
+
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+func (ProjectMember) TableName() string {
+	return "project_members"
+}
+
+// ProjectInvitation invites whoever signs in with an email address to join a project.
+// Accepting it turns it into a ProjectMember with the same role.
+type ProjectInvitation struct {
+	ID        uuid.UUID   `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	ProjectID uuid.UUID   `gorm:"type:uuid;column:project_id;not null;uniqueIndex:idx_project_invitations_project_email" json:"project_id"`
+	Email     string      `gorm:"column:email;size:255;not null;uniqueIndex:idx_project_invitations_project_email;index" json:"email"` // Stored lowercase
+	Role      ProjectRole `gorm:"column:role;type:varchar(20);not null" json:"role"`
+	InvitedBy uuid.UUID   `gorm:"type:uuid;column:invited_by;not null" json:"invited_by"`
+	CreatedAt time.Time   `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt time.Time   `gorm:"column:updated_at" json:"updated_at"`
+
+	Project *Project `gorm:"foreignKey:ProjectID" json:"project,omitempty"`
+}
+
+func (ProjectInvitation) TableName() string {
+	return "project_invitations"
+}
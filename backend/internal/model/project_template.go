@@ -0,0 +1,136 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProjectTemplate bundles what a new project starts with: a seed repository and/or file set, an
+// OpenCode configuration, starter tasks and a resource profile
+type ProjectTemplate struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID      uuid.UUID `gorm:"type:uuid;column:user_id;not null;index" json:"user_id"`
+	Name        string    `gorm:"column:name;size:100;not null" json:"name"`
+	Description string    `gorm:"column:description;type:text" json:"description"`
+
+	// Builtin templates ship with the application and are shared by all users; they are never persisted
+	Builtin bool `gorm:"-" json:"builtin"`
+
+	// Seed workspace: the repository is cloned like a project repository, then the files are written over it.
+	// Repository credentials are never part of a template.
+	RepoURL        string        `gorm:"column:repo_url;type:text" json:"repo_url,omitempty"`
+	RepoRef        string        `gorm:"column:repo_ref;size:255" json:"repo_ref,omitempty"`
+	RepoCloneDepth int           `gorm:"column:repo_clone_depth;default:0" json:"repo_clone_depth,omitempty"`
+	Files          TemplateFiles `gorm:"column:files;type:jsonb" json:"files,omitempty"`
+
+	// OpenCode configuration and starter tasks of new projects
+	Config *TemplateConfig `gorm:"column:config;type:jsonb" json:"config,omitempty"`
+	Tasks  TemplateTasks   `gorm:"column:tasks;type:jsonb" json:"tasks,omitempty"`
+
+	// Resource profile of new projects; the limits and storage are only set for the custom profile
+	ResourceProfile string `gorm:"column:resource_profile;size:20;not null" json:"resource_profile"`
+	CPULimit        string `gorm:"column:cpu_limit;size:20" json:"cpu_limit,omitempty"`
+	MemoryLimit     string `gorm:"column:memory_limit;size:20" json:"memory_limit,omitempty"`
+	StorageSize     string `gorm:"column:storage_size;size:20" json:"storage_size,omitempty"`
+	StorageClass    string `gorm:"column:storage_class;size:255" json:"storage_class,omitempty"`
+
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (ProjectTemplate) TableName() string {
+	return "project_templates"
+}
+
+// TemplateConfig is the OpenCode configuration of a template, without the API key
+type TemplateConfig struct {
+	ModelProvider  string    `json:"model_provider"`
+	ModelName      string    `json:"model_name"`
+	ModelVersion   *string   `json:"model_version,omitempty"`
+	APIEndpoint    *string   `json:"api_endpoint,omitempty"`
+	Temperature    float64   `json:"temperature"`
+	MaxTokens      int       `json:"max_tokens"`
+	EnabledTools   ToolsList `json:"enabled_tools"`
+	ToolsConfig    JSONB     `json:"tools_config,omitempty"`
+	SystemPrompt   *string   `json:"system_prompt,omitempty"`
+	MaxIterations  int       `json:"max_iterations"`
+	TimeoutSeconds int       `json:"timeout_seconds"`
+
+	AIReviewEnabled    bool    `json:"ai_review_enabled"`
+	ReviewModelName    *string `json:"review_model_name,omitempty"`
+	ReviewSystemPrompt *string `json:"review_system_prompt,omitempty"`
+}
+
+// Value implements the driver.Valuer interface for database writes
+func (c TemplateConfig) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+// Scan implements the sql.Scanner interface for database reads
+func (c *TemplateConfig) Scan(value interface{}) error {
+	return scanJSON(value, c)
+}
+
+// TemplateTask is a starter task of a template
+type TemplateTask struct {
+	Title       string       `json:"title"`
+	Description string       `json:"description,omitempty"`
+	Priority    TaskPriority `json:"priority,omitempty"`
+}
+
+// TemplateTasks is a custom type for JSONB array storage of starter tasks
+type TemplateTasks []TemplateTask
+
+// Value implements the driver.Valuer interface for database writes
+func (t TemplateTasks) Value() (driver.Value, error) {
+	if t == nil {
+		return nil, nil
+	}
+	return json.Marshal(t)
+}
+
+// Scan implements the sql.Scanner interface for database reads
+func (t *TemplateTasks) Scan(value interface{}) error {
+	return scanJSON(value, t)
+}
+
+// TemplateFiles maps workspace-relative file paths to their content
+type TemplateFiles map[string]string
+
+// Value implements the driver.Valuer interface for database writes
+func (f TemplateFiles) Value() (driver.Value, error) {
+	if f == nil {
+		return nil, nil
+	}
+	return json.Marshal(f)
+}
+
+// Scan implements the sql.Scanner interface for database reads
+func (f *TemplateFiles) Scan(value interface{}) error {
+	return scanJSON(value, f)
+}
+
+// scanJSON decodes a JSONB column; NULL leaves dest untouched
+func scanJSON(value interface{}, dest interface{}) error {
+	var b []byte
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("type assertion to []byte failed")
+	}
+
+	if len(b) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(b, dest)
+}
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ResourceCap limits the resources a user may give their projects, as Kubernetes quantities.
+// Users without one get the default caps from the configuration.
+type ResourceCap struct {
+	UserID      uuid.UUID `gorm:"type:uuid;primaryKey;column:user_id" json:"user_id"`
+	MaxCPU      string    `gorm:"column:max_cpu;size:20;not null" json:"max_cpu"`
+	MaxMemory   string    `gorm:"column:max_memory;size:20;not null" json:"max_memory"`
+	MaxStorage  string    `gorm:"column:max_storage;size:20;not null" json:"max_storage"`
+	AllowCustom bool      `gorm:"column:allow_custom;not null" json:"allow_custom"` // custom resources within the caps
+	CreatedAt   time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (ResourceCap) TableName() string {
+	return "resource_caps"
+}
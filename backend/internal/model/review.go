@@ -0,0 +1,49 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReviewDecision string
+
+const (
+	ReviewDecisionApproved         ReviewDecision = "approved"
+	ReviewDecisionChangesRequested ReviewDecision = "changes_requested"
+)
+
+// TaskReview is a human review decision on the work produced by a task session
+type TaskReview struct {
+	ID         uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	TaskID     uuid.UUID      `gorm:"type:uuid;column:task_id;not null;index" json:"task_id"`
+	SessionID  *uuid.UUID     `gorm:"type:uuid;column:session_id" json:"session_id,omitempty"`
+	ReviewerID uuid.UUID      `gorm:"type:uuid;column:reviewer_id;not null" json:"reviewer_id"`
+	Decision   ReviewDecision `gorm:"column:decision;type:varchar(20);not null" json:"decision"`
+	Summary    string         `gorm:"column:summary;type:text" json:"summary,omitempty"`
+	CreatedAt  time.Time      `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt  time.Time      `gorm:"column:updated_at" json:"updated_at"`
+
+	Comments []ReviewComment `gorm:"foreignKey:ReviewID" json:"comments"`
+	Task     *Task           `gorm:"foreignKey:TaskID" json:"task,omitempty"`
+	Reviewer *User           `gorm:"foreignKey:ReviewerID" json:"reviewer,omitempty"`
+}
+
+func (TaskReview) TableName() string {
+	return "task_reviews"
+}
+
+// ReviewComment is a review remark, optionally anchored to a file and line of the session diff.
+// Line refers to the new version of the file; 0 means the comment applies to the whole file.
+type ReviewComment struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	ReviewID  uuid.UUID `gorm:"type:uuid;column:review_id;not null;index" json:"review_id"`
+	FilePath  string    `gorm:"column:file_path;type:text" json:"file_path,omitempty"`
+	Line      int       `gorm:"column:line" json:"line,omitempty"`
+	Body      string    `gorm:"column:body;type:text;not null" json:"body"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+func (ReviewComment) TableName() string {
+	return "review_comments"
+}
@@ -15,6 +15,18 @@ const (
 	SessionStatusCompleted SessionStatus = "completed"
 	SessionStatusFailed    SessionStatus = "failed"
 	SessionStatusCancelled SessionStatus = "cancelled"
+
+	// SessionStatusWaitingForInput sessions are paused on a question the agent asked the user
+	SessionStatusWaitingForInput SessionStatus = "waiting_for_input"
+)
+
+type SessionKind string
+
+const (
+	// SessionKindExecution sessions work on the task itself
+	SessionKindExecution SessionKind = "execution"
+	// SessionKindReview sessions review the changes of the previous execution session
+	SessionKindReview SessionKind = "review"
 )
 
 type Session struct {
@@ -22,15 +34,31 @@ type Session struct {
 	TaskID          uuid.UUID      `gorm:"type:uuid;column:task_id;not null;index" json:"task_id"`
 	ProjectID       uuid.UUID      `gorm:"type:uuid;column:project_id;not null;index" json:"project_id"`
 	Status          SessionStatus  `gorm:"column:status;type:varchar(20);default:'pending'" json:"status"`
+	Kind            SessionKind    `gorm:"column:kind;type:varchar(20);default:'execution'" json:"kind"`
 	Prompt          string         `gorm:"column:prompt;type:text" json:"prompt,omitempty"`
-	Output          string         `gorm:"column:output;type:text" json:"output,omitempty"`
+	Output          string         `gorm:"column:output;type:text" json:"output,omitempty"`             // transcript persisted from the event stream
+	FinalOutput     string         `gorm:"column:final_output;type:text" json:"final_output,omitempty"` // agent's final response
 	Error           string         `gorm:"column:error;type:text" json:"error,omitempty"`
 	RemoteSessionID string         `gorm:"column:remote_session_id;type:varchar(255);index" json:"remote_session_id,omitempty"`
 	LastEventID     string         `gorm:"column:last_event_id;type:varchar(255)" json:"last_event_id,omitempty"`
 	PromptRequestID string         `gorm:"column:prompt_request_id;type:varchar(255);index" json:"prompt_request_id,omitempty"`
+	BranchName      string         `gorm:"column:branch_name;type:varchar(255)" json:"branch_name,omitempty"`
+	CommitSHA       string         `gorm:"column:commit_sha;type:varchar(64)" json:"commit_sha,omitempty"`
+	BaseSnapshot    string         `gorm:"column:base_snapshot;type:varchar(64)" json:"base_snapshot,omitempty"`
+	FinalSnapshot   string         `gorm:"column:final_snapshot;type:varchar(64)" json:"final_snapshot,omitempty"`
+	TimeoutSeconds  int            `gorm:"column:timeout_seconds" json:"timeout_seconds,omitempty"` // limits of the current run, from the project config
+	MaxIterations   int            `gorm:"column:max_iterations" json:"max_iterations,omitempty"`
+	Iterations      int            `gorm:"column:iterations" json:"iterations"` // tool calls of the current run
+	DeadlineAt      *time.Time     `gorm:"column:deadline_at" json:"deadline_at,omitempty"`
+	ModelProvider   string         `gorm:"column:model_provider;type:varchar(50)" json:"model_provider,omitempty"` // model the session last ran with
+	ModelName       string         `gorm:"column:model_name;type:varchar(100)" json:"model_name,omitempty"`
+	InputTokens     int64          `gorm:"column:input_tokens" json:"input_tokens"` // tokens used by all runs of the session
+	OutputTokens    int64          `gorm:"column:output_tokens" json:"output_tokens"`
+	CostUSD         float64        `gorm:"column:cost_usd;type:numeric(12,6)" json:"cost_usd"`
 	StartedAt       *time.Time     `gorm:"column:started_at" json:"started_at,omitempty"`
+	RunStartedAt    *time.Time     `gorm:"column:run_started_at" json:"-"` // start of the current run; nil while paused or finished
 	CompletedAt     *time.Time     `gorm:"column:completed_at" json:"completed_at,omitempty"`
-	DurationMs      int64          `gorm:"column:duration_ms" json:"duration_ms"`
+	DurationMs      int64          `gorm:"column:duration_ms" json:"duration_ms"` // time spent running, excluding pauses between runs
 	CreatedAt       time.Time      `gorm:"column:created_at" json:"created_at"`
 	UpdatedAt       time.Time      `gorm:"column:updated_at" json:"updated_at"`
 	DeletedAt       gorm.DeletedAt `gorm:"column:deleted_at;index" json:"deleted_at,omitempty"`
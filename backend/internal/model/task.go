@@ -26,22 +26,25 @@ const (
 )
 
 type Task struct {
-	ID                  uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
-	ProjectID           uuid.UUID      `gorm:"type:uuid;column:project_id;not null;index" json:"project_id"`
-	Title               string         `gorm:"column:title;not null" json:"title"`
-	Description         string         `gorm:"column:description;type:text" json:"description"`
-	Status              TaskStatus     `gorm:"column:status;type:varchar(20);default:'todo'" json:"status"`
-	Position            int            `gorm:"column:position;not null;default:0" json:"position"`
-	Priority            TaskPriority   `gorm:"column:priority;type:varchar(20);default:'medium'" json:"priority"`
-	AssignedTo          *uuid.UUID     `gorm:"type:uuid;column:assigned_to" json:"assigned_to,omitempty"`
-	CurrentSessionID    *uuid.UUID     `gorm:"type:uuid;column:current_session_id" json:"current_session_id,omitempty"`
-	OpenCodeOutput      string         `gorm:"column:opencode_output;type:text" json:"opencode_output,omitempty"`
-	ExecutionDurationMs int64          `gorm:"column:execution_duration_ms" json:"execution_duration_ms"`
-	FileReferences      string         `gorm:"column:file_references;type:jsonb" json:"file_references,omitempty"`
-	CreatedBy           uuid.UUID      `gorm:"type:uuid;column:created_by;not null" json:"created_by"`
-	CreatedAt           time.Time      `gorm:"column:created_at" json:"created_at"`
-	UpdatedAt           time.Time      `gorm:"column:updated_at" json:"updated_at"`
-	DeletedAt           gorm.DeletedAt `gorm:"column:deleted_at;index" json:"deleted_at,omitempty"`
+	ID                  uuid.UUID        `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	ProjectID           uuid.UUID        `gorm:"type:uuid;column:project_id;not null;index" json:"project_id"`
+	Title               string           `gorm:"column:title;not null" json:"title"`
+	Description         string           `gorm:"column:description;type:text" json:"description"`
+	Status              TaskStatus       `gorm:"column:status;type:varchar(20);default:'todo'" json:"status"`
+	Position            int              `gorm:"column:position;not null;default:0" json:"position"`
+	Priority            TaskPriority     `gorm:"column:priority;type:varchar(20);default:'medium'" json:"priority"`
+	AssignedTo          *uuid.UUID       `gorm:"type:uuid;column:assigned_to" json:"assigned_to,omitempty"`
+	CurrentSessionID    *uuid.UUID       `gorm:"type:uuid;column:current_session_id" json:"current_session_id,omitempty"`
+	OpenCodeOutput      string           `gorm:"column:opencode_output;type:text" json:"opencode_output,omitempty"`
+	ExecutionDurationMs int64            `gorm:"column:execution_duration_ms" json:"execution_duration_ms"`
+	FileReferences      string           `gorm:"column:file_references;type:jsonb" json:"file_references,omitempty"`
+	BranchName          string           `gorm:"column:branch_name;type:varchar(255)" json:"branch_name,omitempty"`
+	CommitSHA           string           `gorm:"column:commit_sha;type:varchar(64)" json:"commit_sha,omitempty"`
+	AIReviewVerdict     *AIReviewVerdict `gorm:"column:ai_review_verdict;type:jsonb;serializer:json" json:"ai_review_verdict,omitempty"`
+	CreatedBy           uuid.UUID        `gorm:"type:uuid;column:created_by;not null" json:"created_by"`
+	CreatedAt           time.Time        `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt           time.Time        `gorm:"column:updated_at" json:"updated_at"`
+	DeletedAt           gorm.DeletedAt   `gorm:"column:deleted_at;index" json:"deleted_at,omitempty"`
 
 	Project  *Project `gorm:"foreignKey:ProjectID" json:"project,omitempty"`
 	Creator  *User    `gorm:"foreignKey:CreatedBy" json:"creator,omitempty"`
@@ -51,3 +54,22 @@ type Task struct {
 func (Task) TableName() string {
 	return "tasks"
 }
+
+// AIReviewVerdict is the outcome of the automated review of a task's latest execution session
+type AIReviewVerdict struct {
+	Passed            bool              `json:"passed"`
+	Summary           string            `json:"summary,omitempty"`
+	Findings          []AIReviewFinding `json:"findings"`
+	Error             string            `json:"error,omitempty"`     // set when the reviewer produced no usable verdict
+	SessionID         uuid.UUID         `json:"session_id"`          // review session
+	ReviewedSessionID uuid.UUID         `json:"reviewed_session_id"` // execution session that was reviewed
+	ReviewedAt        time.Time         `json:"reviewed_at"`
+}
+
+// AIReviewFinding is a single issue reported by the reviewer agent
+type AIReviewFinding struct {
+	Severity string `json:"severity"` // "error", "warning" or "info"
+	FilePath string `json:"file_path,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Message  string `json:"message"`
+}
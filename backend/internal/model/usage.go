@@ -0,0 +1,61 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SessionUsageRecord is the usage of one model call made by a session, at the time it was recorded
+type SessionUsageRecord struct {
+	ID            uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	SessionID     uuid.UUID `gorm:"type:uuid;column:session_id;not null;index" json:"session_id"`
+	ProjectID     uuid.UUID `gorm:"type:uuid;column:project_id;not null" json:"project_id"`
+	ModelProvider string    `gorm:"column:model_provider;type:varchar(50)" json:"model_provider,omitempty"`
+	ModelName     string    `gorm:"column:model_name;type:varchar(100)" json:"model_name,omitempty"`
+	InputTokens   int64     `gorm:"column:input_tokens" json:"input_tokens"`
+	OutputTokens  int64     `gorm:"column:output_tokens" json:"output_tokens"`
+	CostUSD       float64   `gorm:"column:cost_usd;type:numeric(12,6)" json:"cost_usd"`
+	RecordedAt    time.Time `gorm:"column:recorded_at;not null" json:"recorded_at"`
+}
+
+func (SessionUsageRecord) TableName() string {
+	return "session_usage"
+}
+
+// UsageTotals sums the token usage and cost of a set of sessions
+type UsageTotals struct {
+	Sessions     int64   `json:"sessions"`
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+}
+
+// DailyUsage is the usage recorded on one day (UTC, YYYY-MM-DD) and the number of sessions that recorded it
+type DailyUsage struct {
+	Day string `json:"day"`
+	UsageTotals
+}
+
+// ModelUsage is the usage of the sessions that ran with one model
+type ModelUsage struct {
+	ModelProvider string `json:"model_provider"`
+	ModelName     string `json:"model_name"`
+	UsageTotals
+}
+
+// TaskUsage is the usage of all sessions of one task, including its reviews
+type TaskUsage struct {
+	TaskID    uuid.UUID `json:"task_id"`
+	TaskTitle string    `json:"task_title"`
+	UsageTotals
+}
+
+// ProjectUsage reports what the sessions of a project used and cost
+type ProjectUsage struct {
+	ProjectID uuid.UUID    `json:"project_id"`
+	Total     UsageTotals  `json:"total"`
+	ByDay     []DailyUsage `json:"by_day"`
+	ByModel   []ModelUsage `json:"by_model"`
+	ByTask    []TaskUsage  `json:"by_task"`
+}
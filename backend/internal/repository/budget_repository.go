@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/npinot/vibe/backend/internal/model"
+)
+
+type BudgetRepository interface {
+	FindByScope(ctx context.Context, scope model.BudgetScope, scopeID uuid.UUID) (*model.Budget, error)
+	Upsert(ctx context.Context, budget *model.Budget) error
+	Delete(ctx context.Context, scope model.BudgetScope, scopeID uuid.UUID) error
+	MarkWarned(ctx context.Context, id uuid.UUID, period string) (bool, error)
+}
+
+type budgetRepository struct {
+	db *gorm.DB
+}
+
+func NewBudgetRepository(db *gorm.DB) BudgetRepository {
+	return &budgetRepository{db: db}
+}
+
+// FindByScope returns the budget of a project or user, or gorm.ErrRecordNotFound when it has none
+func (r *budgetRepository) FindByScope(ctx context.Context, scope model.BudgetScope, scopeID uuid.UUID) (*model.Budget, error) {
+	var budget model.Budget
+
+	if err := r.db.WithContext(ctx).
+		Where("scope = ? AND scope_id = ?", scope, scopeID).
+		First(&budget).Error; err != nil {
+		return nil, err
+	}
+
+	return &budget, nil
+}
+
+// Upsert creates the budget of its scope or replaces the limits of the existing one
+func (r *budgetRepository) Upsert(ctx context.Context, budget *model.Budget) error {
+	if budget.ID == uuid.Nil {
+		budget.ID = uuid.New()
+	}
+
+	now := time.Now()
+	if budget.CreatedAt.IsZero() {
+		budget.CreatedAt = now
+	}
+	budget.UpdatedAt = now
+
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "scope"}, {Name: "scope_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"monthly_cost_usd", "monthly_tokens", "warn_percent", "updated_at"}),
+	}).Create(budget).Error; err != nil {
+		return fmt.Errorf("failed to save budget: %w", err)
+	}
+
+	// The conflicting row keeps its ID and creation time
+	stored, err := r.FindByScope(ctx, budget.Scope, budget.ScopeID)
+	if err != nil {
+		return fmt.Errorf("failed to reload budget: %w", err)
+	}
+	*budget = *stored
+
+	return nil
+}
+
+func (r *budgetRepository) Delete(ctx context.Context, scope model.BudgetScope, scopeID uuid.UUID) error {
+	if err := r.db.WithContext(ctx).
+		Where("scope = ? AND scope_id = ?", scope, scopeID).
+		Delete(&model.Budget{}).Error; err != nil {
+		return fmt.Errorf("failed to delete budget: %w", err)
+	}
+
+	return nil
+}
+
+// MarkWarned records that the warning of the budget was posted for period. It reports false
+// when it already was, so concurrent sessions post the warning once.
+func (r *budgetRepository) MarkWarned(ctx context.Context, id uuid.UUID, period string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.Budget{}).
+		Where("id = ? AND (warned_period IS NULL OR warned_period <> ?)", id, period).
+		UpdateColumn("warned_period", period)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to mark budget warned: %w", result.Error)
+	}
+
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/npinot/vibe/backend/internal/model"
+)
+
+func setupBudgetTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	createTablesSQL := `
+		CREATE TABLE budgets (
+			id TEXT PRIMARY KEY,
+			scope TEXT NOT NULL,
+			scope_id TEXT NOT NULL,
+			monthly_cost_usd REAL,
+			monthly_tokens INTEGER,
+			warn_percent INTEGER NOT NULL DEFAULT 80,
+			warned_period TEXT,
+			created_at DATETIME NOT NULL,
+			updated_at DATETIME NOT NULL,
+			UNIQUE (scope, scope_id)
+		);
+	`
+
+	err = db.Exec(createTablesSQL).Error
+	require.NoError(t, err)
+
+	return db
+}
+
+func TestBudgetRepository_Upsert(t *testing.T) {
+	db := setupBudgetTestDB(t)
+	repo := NewBudgetRepository(db)
+	ctx := context.Background()
+
+	projectID := uuid.New()
+	cost := 50.0
+	budget := &model.Budget{Scope: model.BudgetScopeProject, ScopeID: projectID, MonthlyCostUSD: &cost, WarnPercent: 80}
+	require.NoError(t, repo.Upsert(ctx, budget))
+	createdID := budget.ID
+
+	tokens := int64(1_000_000)
+	replacement := &model.Budget{Scope: model.BudgetScopeProject, ScopeID: projectID, MonthlyTokens: &tokens, WarnPercent: 90}
+	require.NoError(t, repo.Upsert(ctx, replacement))
+
+	assert.Equal(t, createdID, replacement.ID)
+	found, err := repo.FindByScope(ctx, model.BudgetScopeProject, projectID)
+	require.NoError(t, err)
+	assert.Nil(t, found.MonthlyCostUSD)
+	require.NotNil(t, found.MonthlyTokens)
+	assert.Equal(t, tokens, *found.MonthlyTokens)
+	assert.Equal(t, 90, found.WarnPercent)
+
+	// Budgets of other scopes are separate
+	_, err = repo.FindByScope(ctx, model.BudgetScopeUser, projectID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestBudgetRepository_Delete(t *testing.T) {
+	db := setupBudgetTestDB(t)
+	repo := NewBudgetRepository(db)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	require.NoError(t, repo.Upsert(ctx, &model.Budget{Scope: model.BudgetScopeUser, ScopeID: userID, WarnPercent: 80}))
+
+	require.NoError(t, repo.Delete(ctx, model.BudgetScopeUser, userID))
+
+	_, err := repo.FindByScope(ctx, model.BudgetScopeUser, userID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestBudgetRepository_MarkWarned(t *testing.T) {
+	db := setupBudgetTestDB(t)
+	repo := NewBudgetRepository(db)
+	ctx := context.Background()
+
+	budget := &model.Budget{Scope: model.BudgetScopeUser, ScopeID: uuid.New(), WarnPercent: 80}
+	require.NoError(t, repo.Upsert(ctx, budget))
+
+	marked, err := repo.MarkWarned(ctx, budget.ID, "2026-03")
+	require.NoError(t, err)
+	assert.True(t, marked)
+
+	marked, err = repo.MarkWarned(ctx, budget.ID, "2026-03")
+	require.NoError(t, err)
+	assert.False(t, marked)
+
+	marked, err = repo.MarkWarned(ctx, budget.ID, "2026-04")
+	require.NoError(t, err)
+	assert.True(t, marked)
+}
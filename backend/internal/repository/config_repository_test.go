@@ -66,6 +66,9 @@ func setupConfigTestDB(t *testing.T) *gorm.DB {
 			system_prompt TEXT,
 			max_iterations INTEGER NOT NULL DEFAULT 10,
 			timeout_seconds INTEGER NOT NULL DEFAULT 300,
+			ai_review_enabled BOOLEAN NOT NULL DEFAULT 1,
+			review_model_name TEXT,
+			review_system_prompt TEXT,
 			created_by TEXT NOT NULL,
 			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
@@ -68,10 +68,17 @@ func setupInteractionTestDB(t *testing.T) *gorm.DB {
 			task_id TEXT NOT NULL,
 			project_id TEXT NOT NULL,
 			status TEXT NOT NULL DEFAULT 'pending',
+			kind TEXT NOT NULL DEFAULT 'execution',
 			prompt TEXT,
 			output TEXT,
+			final_output TEXT,
 			error TEXT,
+			branch_name TEXT,
+			commit_sha TEXT,
+			base_snapshot TEXT,
+			final_snapshot TEXT,
 			started_at DATETIME,
+			run_started_at DATETIME,
 			completed_at DATETIME,
 			duration_ms INTEGER DEFAULT 0,
 			created_at DATETIME,
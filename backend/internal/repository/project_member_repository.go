@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/npinot/vibe/backend/internal/model"
+)
+
+type ProjectMemberRepository interface {
+	FindMember(ctx context.Context, projectID, userID uuid.UUID) (*model.ProjectMember, error)
+	FindMembers(ctx context.Context, projectID uuid.UUID) ([]model.ProjectMember, error)
+	UpdateMember(ctx context.Context, member *model.ProjectMember) error
+	DeleteMember(ctx context.Context, id uuid.UUID) error
+
+	CreateInvitation(ctx context.Context, invitation *model.ProjectInvitation) error
+	UpdateInvitation(ctx context.Context, invitation *model.ProjectInvitation) error
+	FindInvitationByID(ctx context.Context, id uuid.UUID) (*model.ProjectInvitation, error)
+	FindInvitation(ctx context.Context, projectID uuid.UUID, email string) (*model.ProjectInvitation, error)
+	FindInvitations(ctx context.Context, projectID uuid.UUID) ([]model.ProjectInvitation, error)
+	FindInvitationsByEmail(ctx context.Context, email string) ([]model.ProjectInvitation, error)
+	DeleteInvitation(ctx context.Context, id uuid.UUID) error
+
+	// AcceptInvitation creates the member and deletes the invitation in a transaction
+	AcceptInvitation(ctx context.Context, invitation *model.ProjectInvitation, member *model.ProjectMember) error
+}
+
+type projectMemberRepository struct {
+	db *gorm.DB
+}
+
+func NewProjectMemberRepository(db *gorm.DB) ProjectMemberRepository {
+	return &projectMemberRepository{db: db}
+}
+
+// FindMember returns the membership of a user in a project, or gorm.ErrRecordNotFound when there is none
+func (r *projectMemberRepository) FindMember(ctx context.Context, projectID, userID uuid.UUID) (*model.ProjectMember, error) {
+	var member model.ProjectMember
+
+	if err := r.db.WithContext(ctx).
+		Where("project_id = ? AND user_id = ?", projectID, userID).
+		First(&member).Error; err != nil {
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+// FindMembers returns the members of a project with their user, oldest first
+func (r *projectMemberRepository) FindMembers(ctx context.Context, projectID uuid.UUID) ([]model.ProjectMember, error) {
+	var members []model.ProjectMember
+
+	if err := r.db.WithContext(ctx).
+		Preload("User").
+		Where("project_id = ?", projectID).
+		Order("created_at ASC").
+		Find(&members).Error; err != nil {
+		return nil, fmt.Errorf("failed to find project members: %w", err)
+	}
+
+	return members, nil
+}
+
+func (r *projectMemberRepository) UpdateMember(ctx context.Context, member *model.ProjectMember) error {
+	member.UpdatedAt = time.Now()
+
+	if err := r.db.WithContext(ctx).
+		Model(&model.ProjectMember{}).
+		Where("id = ?", member.ID).
+		Updates(map[string]interface{}{"role": member.Role, "updated_at": member.UpdatedAt}).Error; err != nil {
+		return fmt.Errorf("failed to update project member: %w", err)
+	}
+
+	return nil
+}
+
+func (r *projectMemberRepository) DeleteMember(ctx context.Context, id uuid.UUID) error {
+	if err := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		Delete(&model.ProjectMember{}).Error; err != nil {
+		return fmt.Errorf("failed to delete project member: %w", err)
+	}
+
+	return nil
+}
+
+func (r *projectMemberRepository) CreateInvitation(ctx context.Context, invitation *model.ProjectInvitation) error {
+	if invitation.ID == uuid.Nil {
+		invitation.ID = uuid.New()
+	}
+
+	now := time.Now()
+	invitation.CreatedAt = now
+	invitation.UpdatedAt = now
+
+	if err := r.db.WithContext(ctx).Create(invitation).Error; err != nil {
+		return fmt.Errorf("failed to create project invitation: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateInvitation changes the role of an invitation and who sent it
+func (r *projectMemberRepository) UpdateInvitation(ctx context.Context, invitation *model.ProjectInvitation) error {
+	invitation.UpdatedAt = time.Now()
+
+	if err := r.db.WithContext(ctx).
+		Model(&model.ProjectInvitation{}).
+		Where("id = ?", invitation.ID).
+		Updates(map[string]interface{}{
+			"role":       invitation.Role,
+			"invited_by": invitation.InvitedBy,
+			"updated_at": invitation.UpdatedAt,
+		}).Error; err != nil {
+		return fmt.Errorf("failed to update project invitation: %w", err)
+	}
+
+	return nil
+}
+
+// FindInvitationByID returns an invitation, or gorm.ErrRecordNotFound when there is none
+func (r *projectMemberRepository) FindInvitationByID(ctx context.Context, id uuid.UUID) (*model.ProjectInvitation, error) {
+	var invitation model.ProjectInvitation
+
+	if err := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&invitation).Error; err != nil {
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+// FindInvitation returns the invitation of an email address to a project, or gorm.ErrRecordNotFound when there is none
+func (r *projectMemberRepository) FindInvitation(ctx context.Context, projectID uuid.UUID, email string) (*model.ProjectInvitation, error) {
+	var invitation model.ProjectInvitation
+
+	if err := r.db.WithContext(ctx).
+		Where("project_id = ? AND email = ?", projectID, email).
+		First(&invitation).Error; err != nil {
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+// FindInvitations returns the pending invitations to a project, oldest first
+func (r *projectMemberRepository) FindInvitations(ctx context.Context, projectID uuid.UUID) ([]model.ProjectInvitation, error) {
+	var invitations []model.ProjectInvitation
+
+	if err := r.db.WithContext(ctx).
+		Where("project_id = ?", projectID).
+		Order("created_at ASC").
+		Find(&invitations).Error; err != nil {
+		return nil, fmt.Errorf("failed to find project invitations: %w", err)
+	}
+
+	return invitations, nil
+}
+
+// FindInvitationsByEmail returns the pending invitations of an email address with their project,
+// newest first. Invitations to deleted projects are left out.
+func (r *projectMemberRepository) FindInvitationsByEmail(ctx context.Context, email string) ([]model.ProjectInvitation, error) {
+	var invitations []model.ProjectInvitation
+
+	if err := r.db.WithContext(ctx).
+		Joins("JOIN projects ON projects.id = project_invitations.project_id AND projects.deleted_at IS NULL").
+		Preload("Project").
+		Where("project_invitations.email = ?", email).
+		Order("project_invitations.created_at DESC").
+		Find(&invitations).Error; err != nil {
+		return nil, fmt.Errorf("failed to find invitations by email: %w", err)
+	}
+
+	return invitations, nil
+}
+
+func (r *projectMemberRepository) DeleteInvitation(ctx context.Context, id uuid.UUID) error {
+	if err := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		Delete(&model.ProjectInvitation{}).Error; err != nil {
+		return fmt.Errorf("failed to delete project invitation: %w", err)
+	}
+
+	return nil
+}
+
+func (r *projectMemberRepository) AcceptInvitation(ctx context.Context, invitation *model.ProjectInvitation, member *model.ProjectMember) error {
+	if member.ID == uuid.Nil {
+		member.ID = uuid.New()
+	}
+
+	now := time.Now()
+	member.CreatedAt = now
+	member.UpdatedAt = now
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(member).Error; err != nil {
+			return fmt.Errorf("failed to create project member: %w", err)
+		}
+
+		if err := tx.Where("id = ?", invitation.ID).Delete(&model.ProjectInvitation{}).Error; err != nil {
+			return fmt.Errorf("failed to delete project invitation: %w", err)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/npinot/vibe/backend/internal/model"
+)
+
+func setupProjectMemberTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	createTablesSQL := []string{`
+		CREATE TABLE users (
+			id TEXT PRIMARY KEY,
+			oidc_subject TEXT NOT NULL UNIQUE,
+			email TEXT NOT NULL,
+			name TEXT,
+			picture_url TEXT,
+			last_login_at DATETIME,
+			created_at DATETIME,
+			updated_at DATETIME
+		)`, `
+		CREATE TABLE projects (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			slug TEXT NOT NULL,
+			status TEXT NOT NULL DEFAULT 'initializing',
+			created_at DATETIME,
+			updated_at DATETIME,
+			deleted_at DATETIME
+		)`, `
+		CREATE TABLE project_members (
+			id TEXT PRIMARY KEY,
+			project_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			role TEXT NOT NULL,
+			invited_by TEXT NOT NULL,
+			created_at DATETIME,
+			updated_at DATETIME,
+			UNIQUE(project_id, user_id)
+		)`, `
+		CREATE TABLE project_invitations (
+			id TEXT PRIMARY KEY,
+			project_id TEXT NOT NULL,
+			email TEXT NOT NULL,
+			role TEXT NOT NULL,
+			invited_by TEXT NOT NULL,
+			created_at DATETIME,
+			updated_at DATETIME,
+			UNIQUE(project_id, email)
+		)`,
+	}
+	for _, sql := range createTablesSQL {
+		require.NoError(t, db.Exec(sql).Error)
+	}
+
+	return db
+}
+
+func createMemberTestProject(t *testing.T, db *gorm.DB, ownerID uuid.UUID, name string) uuid.UUID {
+	t.Helper()
+
+	projectID := uuid.New()
+	require.NoError(t, db.Exec("INSERT INTO projects (id, user_id, name, slug, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)",
+		projectID.String(), ownerID.String(), name, name, time.Now(), time.Now()).Error)
+
+	return projectID
+}
+
+func TestProjectMemberRepository_Members(t *testing.T) {
+	db := setupProjectMemberTestDB(t)
+	repo := NewProjectMemberRepository(db)
+	ctx := context.Background()
+
+	ownerID := uuid.New()
+	userID := uuid.New()
+	require.NoError(t, db.Exec("INSERT INTO users (id, oidc_subject, email, name) VALUES (?, ?, ?, ?)",
+		userID.String(), "subject-member", "member@example.com", "Member").Error)
+	projectID := createMemberTestProject(t, db, ownerID, "shared")
+
+	invitation := &model.ProjectInvitation{ProjectID: projectID, Email: "member@example.com", Role: model.ProjectRoleViewer, InvitedBy: ownerID}
+	require.NoError(t, repo.CreateInvitation(ctx, invitation))
+
+	// Accepting turns the invitation into a membership
+	member := &model.ProjectMember{ProjectID: projectID, UserID: userID, Role: invitation.Role, InvitedBy: ownerID}
+	require.NoError(t, repo.AcceptInvitation(ctx, invitation, member))
+	assert.NotEqual(t, uuid.Nil, member.ID)
+
+	_, err := repo.FindInvitationByID(ctx, invitation.ID)
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+
+	found, err := repo.FindMember(ctx, projectID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, model.ProjectRoleViewer, found.Role)
+
+	_, err = repo.FindMember(ctx, projectID, ownerID)
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+
+	// A user is a member of a project at most once
+	duplicate := &model.ProjectMember{ProjectID: projectID, UserID: userID, Role: model.ProjectRoleMaintainer, InvitedBy: ownerID}
+	assert.Error(t, repo.AcceptInvitation(ctx, &model.ProjectInvitation{ID: uuid.New()}, duplicate))
+
+	found.Role = model.ProjectRoleMaintainer
+	require.NoError(t, repo.UpdateMember(ctx, found))
+
+	members, err := repo.FindMembers(ctx, projectID)
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+	assert.Equal(t, model.ProjectRoleMaintainer, members[0].Role)
+	require.NotNil(t, members[0].User)
+	assert.Equal(t, "member@example.com", members[0].User.Email)
+
+	require.NoError(t, repo.DeleteMember(ctx, found.ID))
+	members, err = repo.FindMembers(ctx, projectID)
+	require.NoError(t, err)
+	assert.Empty(t, members)
+}
+
+func TestProjectMemberRepository_Invitations(t *testing.T) {
+	db := setupProjectMemberTestDB(t)
+	repo := NewProjectMemberRepository(db)
+	ctx := context.Background()
+
+	ownerID := uuid.New()
+	firstID := createMemberTestProject(t, db, ownerID, "first")
+	secondID := createMemberTestProject(t, db, ownerID, "second")
+	deletedID := createMemberTestProject(t, db, ownerID, "deleted")
+	require.NoError(t, db.Exec("UPDATE projects SET deleted_at = ? WHERE id = ?", time.Now(), deletedID.String()).Error)
+
+	for _, projectID := range []uuid.UUID{firstID, secondID, deletedID} {
+		require.NoError(t, repo.CreateInvitation(ctx, &model.ProjectInvitation{
+			ProjectID: projectID,
+			Email:     "invitee@example.com",
+			Role:      model.ProjectRoleViewer,
+			InvitedBy: ownerID,
+		}))
+	}
+	require.NoError(t, repo.CreateInvitation(ctx, &model.ProjectInvitation{
+		ProjectID: firstID,
+		Email:     "other@example.com",
+		Role:      model.ProjectRoleMaintainer,
+		InvitedBy: ownerID,
+	}))
+
+	// One invitation per email address and project
+	err := repo.CreateInvitation(ctx, &model.ProjectInvitation{ProjectID: firstID, Email: "invitee@example.com", Role: model.ProjectRoleMaintainer, InvitedBy: ownerID})
+	assert.Error(t, err)
+
+	invitation, err := repo.FindInvitation(ctx, firstID, "invitee@example.com")
+	require.NoError(t, err)
+	invitation.Role = model.ProjectRoleMaintainer
+	require.NoError(t, repo.UpdateInvitation(ctx, invitation))
+
+	found, err := repo.FindInvitationByID(ctx, invitation.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.ProjectRoleMaintainer, found.Role)
+
+	invitations, err := repo.FindInvitations(ctx, firstID)
+	require.NoError(t, err)
+	assert.Len(t, invitations, 2)
+
+	// Invitations to deleted projects are not offered
+	invitations, err = repo.FindInvitationsByEmail(ctx, "invitee@example.com")
+	require.NoError(t, err)
+	require.Len(t, invitations, 2)
+	for _, invitation := range invitations {
+		require.NotNil(t, invitation.Project)
+		assert.NotEqual(t, deletedID, invitation.ProjectID)
+	}
+
+	require.NoError(t, repo.DeleteInvitation(ctx, invitation.ID))
+	_, err = repo.FindInvitation(ctx, firstID, "invitee@example.com")
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+}
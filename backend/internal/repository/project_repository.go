@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -15,9 +16,14 @@ type ProjectRepository interface {
 	Create(ctx context.Context, project *model.Project) error
 	FindByID(ctx context.Context, id uuid.UUID) (*model.Project, error)
 	FindByUserID(ctx context.Context, userID uuid.UUID) ([]model.Project, error)
+	FindSharedWithUser(ctx context.Context, userID uuid.UUID) ([]model.Project, error)
 	Update(ctx context.Context, project *model.Project) error
 	SoftDelete(ctx context.Context, id uuid.UUID) error
 	UpdatePodStatus(ctx context.Context, id uuid.UUID, status string, podError string) error
+	FindByPodName(ctx context.Context, podName, namespace string) (*model.Project, error)
+	FindIdleProjects(ctx context.Context, idleSince time.Time) ([]model.Project, error)
+	UpdateLastActivity(ctx context.Context, podName, namespace string, at time.Time) error
+	FindRunningProjects(ctx context.Context) ([]model.Project, error)
 }
 
 type projectRepository struct {
@@ -64,6 +70,20 @@ func (r *projectRepository) FindByUserID(ctx context.Context, userID uuid.UUID)
 	return projects, nil
 }
 
+// FindSharedWithUser returns the projects the user is a member of
+func (r *projectRepository) FindSharedWithUser(ctx context.Context, userID uuid.UUID) ([]model.Project, error) {
+	var projects []model.Project
+	if err := r.db.WithContext(ctx).
+		Joins("JOIN project_members ON project_members.project_id = projects.id").
+		Where("project_members.user_id = ?", userID).
+		Order("projects.created_at DESC").
+		Find(&projects).Error; err != nil {
+		return nil, fmt.Errorf("failed to find projects shared with user: %w", err)
+	}
+
+	return projects, nil
+}
+
 func (r *projectRepository) Update(ctx context.Context, project *model.Project) error {
 	if err := r.db.WithContext(ctx).Save(project).Error; err != nil {
 		return fmt.Errorf("failed to update project: %w", err)
@@ -80,13 +100,11 @@ func (r *projectRepository) SoftDelete(ctx context.Context, id uuid.UUID) error
 	return nil
 }
 
+// UpdatePodStatus stores the pod phase and error of a project. An empty podError clears the previous error.
 func (r *projectRepository) UpdatePodStatus(ctx context.Context, id uuid.UUID, status string, podError string) error {
 	updates := map[string]interface{}{
 		"pod_status": status,
-	}
-
-	if podError != "" {
-		updates["pod_error"] = podError
+		"pod_error":  podError,
 	}
 
 	if err := r.db.WithContext(ctx).
@@ -98,3 +116,54 @@ func (r *projectRepository) UpdatePodStatus(ctx context.Context, id uuid.UUID, s
 
 	return nil
 }
+
+func (r *projectRepository) FindByPodName(ctx context.Context, podName, namespace string) (*model.Project, error) {
+	var project model.Project
+	if err := r.db.WithContext(ctx).
+		Where("pod_name = ? AND pod_namespace = ?", podName, namespace).
+		First(&project).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("failed to find project by pod name: %w", err)
+	}
+
+	return &project, nil
+}
+
+// FindIdleProjects returns the ready projects whose pod was last used before idleSince.
+// Projects never used count from the creation of their pod.
+func (r *projectRepository) FindIdleProjects(ctx context.Context, idleSince time.Time) ([]model.Project, error) {
+	var projects []model.Project
+	if err := r.db.WithContext(ctx).
+		Where("status = ? AND pod_name <> ''", model.ProjectStatusReady).
+		Where("COALESCE(last_activity_at, pod_created_at, created_at) < ?", idleSince).
+		Find(&projects).Error; err != nil {
+		return nil, fmt.Errorf("failed to find idle projects: %w", err)
+	}
+
+	return projects, nil
+}
+
+func (r *projectRepository) UpdateLastActivity(ctx context.Context, podName, namespace string, at time.Time) error {
+	if err := r.db.WithContext(ctx).
+		Model(&model.Project{}).
+		Where("pod_name = ? AND pod_namespace = ?", podName, namespace).
+		UpdateColumn("last_activity_at", at).Error; err != nil {
+		return fmt.Errorf("failed to update last activity: %w", err)
+	}
+
+	return nil
+}
+
+// FindRunningProjects returns the ready projects, whose pod is expected to be running
+func (r *projectRepository) FindRunningProjects(ctx context.Context) ([]model.Project, error) {
+	var projects []model.Project
+	if err := r.db.WithContext(ctx).
+		Where("status = ? AND pod_name <> ''", model.ProjectStatusReady).
+		Find(&projects).Error; err != nil {
+		return nil, fmt.Errorf("failed to find running projects: %w", err)
+	}
+
+	return projects, nil
+}
@@ -48,12 +48,28 @@ func setupProjectTestDB(t *testing.T) *gorm.DB {
 			slug TEXT NOT NULL,
 			description TEXT,
 			repo_url TEXT,
+			repo_ref TEXT,
+			repo_clone_depth INTEGER DEFAULT 0,
+			repo_credential_encrypted BLOB,
 			pod_name TEXT,
 			pod_namespace TEXT,
 			pod_status TEXT,
 			workspace_pvc_name TEXT,
 			pod_created_at DATETIME,
 			pod_error TEXT,
+			last_activity_at DATETIME,
+			hibernated_at DATETIME,
+			resource_profile TEXT NOT NULL DEFAULT 'medium',
+			cpu_request TEXT NOT NULL DEFAULT '100m',
+			cpu_limit TEXT NOT NULL DEFAULT '1000m',
+			memory_request TEXT NOT NULL DEFAULT '256Mi',
+			memory_limit TEXT NOT NULL DEFAULT '1Gi',
+			storage_size TEXT NOT NULL DEFAULT '1Gi',
+			storage_class TEXT NOT NULL DEFAULT '',
+			archive_key TEXT NOT NULL DEFAULT '',
+			archive_size INTEGER NOT NULL DEFAULT 0,
+			archived_at DATETIME,
+			parent_project_id TEXT,
 			status TEXT NOT NULL DEFAULT 'initializing',
 			created_at DATETIME,
 			updated_at DATETIME,
@@ -67,6 +83,22 @@ func setupProjectTestDB(t *testing.T) *gorm.DB {
 	err = db.Exec("CREATE INDEX idx_projects_deleted_at ON projects(deleted_at)").Error
 	require.NoError(t, err)
 
+	// Create project_members table (shared projects)
+	createProjectMembersTableSQL := `
+		CREATE TABLE project_members (
+			id TEXT PRIMARY KEY,
+			project_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			role TEXT NOT NULL,
+			invited_by TEXT NOT NULL,
+			created_at DATETIME,
+			updated_at DATETIME,
+			UNIQUE(project_id, user_id)
+		)
+	`
+	err = db.Exec(createProjectMembersTableSQL).Error
+	require.NoError(t, err)
+
 	return db
 }
 
@@ -423,6 +455,15 @@ func TestProjectRepository_UpdatePodStatus(t *testing.T) {
 		})
 	}
 
+	t.Run("clears the error of a recovered pod", func(t *testing.T) {
+		require.NoError(t, repo.UpdatePodStatus(ctx, testProject.ID, "Running", "container opencode-server was OOMKilled"))
+		require.NoError(t, repo.UpdatePodStatus(ctx, testProject.ID, "Running", ""))
+
+		project, err := repo.FindByID(ctx, testProject.ID)
+		require.NoError(t, err)
+		assert.Empty(t, project.PodError)
+	})
+
 	// Test updating non-existent project
 	t.Run("update non-existent project", func(t *testing.T) {
 		err := repo.UpdatePodStatus(ctx, uuid.New(), "Running", "")
@@ -432,6 +473,43 @@ func TestProjectRepository_UpdatePodStatus(t *testing.T) {
 	})
 }
 
+func TestProjectRepository_FindSharedWithUser(t *testing.T) {
+	db := setupProjectTestDB(t)
+	repo := NewProjectRepository(db)
+	ctx := context.Background()
+
+	ownerID := createTestUser(t, db)
+	memberID := createTestUser(t, db)
+
+	shared := &model.Project{UserID: ownerID, Name: "Shared", Slug: "shared", Status: model.ProjectStatusReady}
+	require.NoError(t, repo.Create(ctx, shared))
+	private := &model.Project{UserID: ownerID, Name: "Private", Slug: "private", Status: model.ProjectStatusReady}
+	require.NoError(t, repo.Create(ctx, private))
+	deleted := &model.Project{UserID: ownerID, Name: "Deleted", Slug: "deleted", Status: model.ProjectStatusReady}
+	require.NoError(t, repo.Create(ctx, deleted))
+
+	for _, project := range []*model.Project{shared, deleted} {
+		require.NoError(t, db.Create(&model.ProjectMember{
+			ID:        uuid.New(),
+			ProjectID: project.ID,
+			UserID:    memberID,
+			Role:      model.ProjectRoleViewer,
+			InvitedBy: ownerID,
+		}).Error)
+	}
+	require.NoError(t, repo.SoftDelete(ctx, deleted.ID))
+
+	projects, err := repo.FindSharedWithUser(ctx, memberID)
+	require.NoError(t, err)
+	require.Len(t, projects, 1)
+	assert.Equal(t, shared.ID, projects[0].ID)
+
+	// Owning a project does not make it shared with its owner
+	projects, err = repo.FindSharedWithUser(ctx, ownerID)
+	require.NoError(t, err)
+	assert.Empty(t, projects)
+}
+
 func TestProjectRepository_FindByUserID_ExcludesSoftDeleted(t *testing.T) {
 	db := setupProjectTestDB(t)
 	repo := NewProjectRepository(db)
@@ -469,3 +547,84 @@ func TestProjectRepository_FindByUserID_ExcludesSoftDeleted(t *testing.T) {
 	assert.Equal(t, project1.ID, projects[0].ID)
 	assert.Equal(t, "Active Project", projects[0].Name)
 }
+
+func TestProjectRepository_Hibernation(t *testing.T) {
+	db := setupProjectTestDB(t)
+	repo := NewProjectRepository(db)
+	ctx := context.Background()
+
+	userID := createTestUser(t, db)
+	now := time.Now().UTC()
+	podCreatedAt := now.Add(-2 * time.Hour)
+
+	newProject := func(name, podName string, status model.ProjectStatus) *model.Project {
+		project := &model.Project{
+			UserID:       userID,
+			Name:         name,
+			Slug:         name,
+			Status:       status,
+			PodName:      podName,
+			PodNamespace: "vibe",
+			PodCreatedAt: &podCreatedAt,
+		}
+		require.NoError(t, repo.Create(ctx, project))
+		return project
+	}
+
+	idle := newProject("idle", "project-idle", model.ProjectStatusReady)
+	used := newProject("used", "project-used", model.ProjectStatusReady)
+	newProject("cloning", "project-cloning", model.ProjectStatusCloning)
+	newProject("hibernated", "project-hibernated", model.ProjectStatusHibernated)
+	newProject("no-pod", "", model.ProjectStatusReady)
+
+	require.NoError(t, repo.UpdateLastActivity(ctx, used.PodName, "vibe", now))
+
+	t.Run("finds project by pod name", func(t *testing.T) {
+		project, err := repo.FindByPodName(ctx, "project-used", "vibe")
+		require.NoError(t, err)
+		assert.Equal(t, used.ID, project.ID)
+		require.NotNil(t, project.LastActivityAt)
+		assert.WithinDuration(t, now, *project.LastActivityAt, time.Second)
+
+		_, err = repo.FindByPodName(ctx, "project-used", "other")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("finds ready projects idle since the cutoff", func(t *testing.T) {
+		projects, err := repo.FindIdleProjects(ctx, now.Add(-time.Hour))
+		require.NoError(t, err)
+		require.Len(t, projects, 1)
+		assert.Equal(t, idle.ID, projects[0].ID)
+	})
+}
+
+func TestProjectRepository_FindRunningProjects(t *testing.T) {
+	db := setupProjectTestDB(t)
+	repo := NewProjectRepository(db)
+	ctx := context.Background()
+
+	userID := createTestUser(t, db)
+	newProject := func(name, podName string, status model.ProjectStatus) *model.Project {
+		project := &model.Project{
+			UserID:       userID,
+			Name:         name,
+			Slug:         name,
+			Status:       status,
+			PodName:      podName,
+			PodNamespace: "vibe",
+		}
+		require.NoError(t, repo.Create(ctx, project))
+		return project
+	}
+
+	running := newProject("running", "project-running", model.ProjectStatusReady)
+	newProject("hibernated", "project-hibernated", model.ProjectStatusHibernated)
+	newProject("failed", "", model.ProjectStatusError)
+	deleted := newProject("deleted", "project-deleted", model.ProjectStatusReady)
+	require.NoError(t, repo.SoftDelete(ctx, deleted.ID))
+
+	projects, err := repo.FindRunningProjects(ctx)
+	require.NoError(t, err)
+	require.Len(t, projects, 1)
+	assert.Equal(t, running.ID, projects[0].ID)
+}
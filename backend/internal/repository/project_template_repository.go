@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/npinot/vibe/backend/internal/model"
+)
+
+type ProjectTemplateRepository interface {
+	Create(ctx context.Context, template *model.ProjectTemplate) error
+	FindByID(ctx context.Context, id uuid.UUID) (*model.ProjectTemplate, error)
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]model.ProjectTemplate, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type projectTemplateRepository struct {
+	db *gorm.DB
+}
+
+func NewProjectTemplateRepository(db *gorm.DB) ProjectTemplateRepository {
+	return &projectTemplateRepository{db: db}
+}
+
+func (r *projectTemplateRepository) Create(ctx context.Context, template *model.ProjectTemplate) error {
+	if template.ID == uuid.Nil {
+		template.ID = uuid.New()
+	}
+
+	now := time.Now()
+	template.CreatedAt = now
+	template.UpdatedAt = now
+
+	if err := r.db.WithContext(ctx).Create(template).Error; err != nil {
+		return fmt.Errorf("failed to create project template: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID returns a saved template, or gorm.ErrRecordNotFound when there is none
+func (r *projectTemplateRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.ProjectTemplate, error) {
+	var template model.ProjectTemplate
+
+	if err := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&template).Error; err != nil {
+		return nil, err
+	}
+
+	return &template, nil
+}
+
+// FindByUserID returns the templates saved by a user, by name
+func (r *projectTemplateRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]model.ProjectTemplate, error) {
+	var templates []model.ProjectTemplate
+
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("name ASC").
+		Find(&templates).Error; err != nil {
+		return nil, fmt.Errorf("failed to find project templates: %w", err)
+	}
+
+	return templates, nil
+}
+
+func (r *projectTemplateRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		Delete(&model.ProjectTemplate{}).Error; err != nil {
+		return fmt.Errorf("failed to delete project template: %w", err)
+	}
+
+	return nil
+}
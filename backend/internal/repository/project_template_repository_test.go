@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/npinot/vibe/backend/internal/model"
+)
+
+func setupProjectTemplateTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	createTablesSQL := `
+		CREATE TABLE project_templates (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			description TEXT,
+			repo_url TEXT NOT NULL DEFAULT '',
+			repo_ref TEXT NOT NULL DEFAULT '',
+			repo_clone_depth INTEGER NOT NULL DEFAULT 0,
+			files TEXT,
+			config TEXT,
+			tasks TEXT,
+			resource_profile TEXT NOT NULL DEFAULT 'medium',
+			cpu_limit TEXT NOT NULL DEFAULT '',
+			memory_limit TEXT NOT NULL DEFAULT '',
+			storage_size TEXT NOT NULL DEFAULT '',
+			storage_class TEXT NOT NULL DEFAULT '',
+			created_at DATETIME NOT NULL,
+			updated_at DATETIME NOT NULL
+		);
+	`
+
+	err = db.Exec(createTablesSQL).Error
+	require.NoError(t, err)
+
+	return db
+}
+
+func TestProjectTemplateRepository(t *testing.T) {
+	db := setupProjectTemplateTestDB(t)
+	repo := NewProjectTemplateRepository(db)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	prompt := "You write idiomatic Go."
+
+	full := &model.ProjectTemplate{
+		UserID:          userID,
+		Name:            "Go service",
+		Files:           model.TemplateFiles{"go.mod": "module example.com/service\n"},
+		Config:          &model.TemplateConfig{ModelProvider: "anthropic", ModelName: "claude-3-haiku-20240307", EnabledTools: model.ToolsList{"file_ops"}, SystemPrompt: &prompt},
+		Tasks:           model.TemplateTasks{{Title: "Add a health endpoint", Priority: model.TaskPriorityHigh}},
+		ResourceProfile: "small",
+	}
+	require.NoError(t, repo.Create(ctx, full))
+	assert.NotEqual(t, uuid.Nil, full.ID)
+
+	bare := &model.ProjectTemplate{UserID: userID, Name: "Blank", RepoURL: "https://github.com/example/seed.git", ResourceProfile: "medium"}
+	require.NoError(t, repo.Create(ctx, bare))
+	require.NoError(t, repo.Create(ctx, &model.ProjectTemplate{UserID: uuid.New(), Name: "Other", ResourceProfile: "medium"}))
+
+	found, err := repo.FindByID(ctx, full.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "module example.com/service\n", found.Files["go.mod"])
+	require.NotNil(t, found.Config)
+	assert.Equal(t, "claude-3-haiku-20240307", found.Config.ModelName)
+	assert.Equal(t, prompt, *found.Config.SystemPrompt)
+	require.Len(t, found.Tasks, 1)
+	assert.Equal(t, model.TaskPriorityHigh, found.Tasks[0].Priority)
+
+	found, err = repo.FindByID(ctx, bare.ID)
+	require.NoError(t, err)
+	assert.Nil(t, found.Config)
+	assert.Empty(t, found.Files)
+	assert.Empty(t, found.Tasks)
+
+	templates, err := repo.FindByUserID(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, templates, 2)
+	assert.Equal(t, "Blank", templates[0].Name)
+	assert.Equal(t, "Go service", templates[1].Name)
+
+	require.NoError(t, repo.Delete(ctx, full.ID))
+	_, err = repo.FindByID(ctx, full.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/npinot/vibe/backend/internal/model"
+)
+
+type ResourceCapRepository interface {
+	FindByUserID(ctx context.Context, userID uuid.UUID) (*model.ResourceCap, error)
+	Upsert(ctx context.Context, resourceCap *model.ResourceCap) error
+	Delete(ctx context.Context, userID uuid.UUID) error
+}
+
+type resourceCapRepository struct {
+	db *gorm.DB
+}
+
+func NewResourceCapRepository(db *gorm.DB) ResourceCapRepository {
+	return &resourceCapRepository{db: db}
+}
+
+// FindByUserID returns the resource caps of a user, or gorm.ErrRecordNotFound when the user has none
+func (r *resourceCapRepository) FindByUserID(ctx context.Context, userID uuid.UUID) (*model.ResourceCap, error) {
+	var resourceCap model.ResourceCap
+
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		First(&resourceCap).Error; err != nil {
+		return nil, err
+	}
+
+	return &resourceCap, nil
+}
+
+// Upsert creates the resource caps of a user or replaces the existing ones
+func (r *resourceCapRepository) Upsert(ctx context.Context, resourceCap *model.ResourceCap) error {
+	now := time.Now()
+	if resourceCap.CreatedAt.IsZero() {
+		resourceCap.CreatedAt = now
+	}
+	resourceCap.UpdatedAt = now
+
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"max_cpu", "max_memory", "max_storage", "allow_custom", "updated_at"}),
+	}).Create(resourceCap).Error; err != nil {
+		return fmt.Errorf("failed to save resource caps: %w", err)
+	}
+
+	// The conflicting row keeps its creation time
+	stored, err := r.FindByUserID(ctx, resourceCap.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to reload resource caps: %w", err)
+	}
+	*resourceCap = *stored
+
+	return nil
+}
+
+func (r *resourceCapRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Delete(&model.ResourceCap{}).Error; err != nil {
+		return fmt.Errorf("failed to delete resource caps: %w", err)
+	}
+
+	return nil
+}
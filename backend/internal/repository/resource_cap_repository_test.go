@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/npinot/vibe/backend/internal/model"
+)
+
+func setupResourceCapTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	createTablesSQL := `
+		CREATE TABLE resource_caps (
+			user_id TEXT PRIMARY KEY,
+			max_cpu TEXT NOT NULL,
+			max_memory TEXT NOT NULL,
+			max_storage TEXT NOT NULL,
+			allow_custom BOOLEAN NOT NULL DEFAULT TRUE,
+			created_at DATETIME NOT NULL,
+			updated_at DATETIME NOT NULL
+		);
+	`
+
+	err = db.Exec(createTablesSQL).Error
+	require.NoError(t, err)
+
+	return db
+}
+
+func TestResourceCapRepository(t *testing.T) {
+	db := setupResourceCapTestDB(t)
+	repo := NewResourceCapRepository(db)
+	ctx := context.Background()
+
+	userID := uuid.New()
+
+	_, err := repo.FindByUserID(ctx, userID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	created := &model.ResourceCap{UserID: userID, MaxCPU: "1000m", MaxMemory: "1Gi", MaxStorage: "5Gi", AllowCustom: true}
+	require.NoError(t, repo.Upsert(ctx, created))
+
+	replacement := &model.ResourceCap{UserID: userID, MaxCPU: "2000m", MaxMemory: "4Gi", MaxStorage: "10Gi", AllowCustom: false}
+	require.NoError(t, repo.Upsert(ctx, replacement))
+
+	found, err := repo.FindByUserID(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, "2000m", found.MaxCPU)
+	assert.Equal(t, "4Gi", found.MaxMemory)
+	assert.Equal(t, "10Gi", found.MaxStorage)
+	assert.False(t, found.AllowCustom)
+	assert.WithinDuration(t, created.CreatedAt, found.CreatedAt, 0)
+
+	require.NoError(t, repo.Delete(ctx, userID))
+	_, err = repo.FindByUserID(ctx, userID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
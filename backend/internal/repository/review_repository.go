@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/npinot/vibe/backend/internal/model"
+)
+
+type ReviewRepository interface {
+	Create(ctx context.Context, review *model.TaskReview) error
+	FindByTaskID(ctx context.Context, taskID uuid.UUID) ([]model.TaskReview, error)
+	FindLatestByTaskID(ctx context.Context, taskID uuid.UUID) (*model.TaskReview, error)
+}
+
+type reviewRepository struct {
+	db *gorm.DB
+}
+
+func NewReviewRepository(db *gorm.DB) ReviewRepository {
+	return &reviewRepository{db: db}
+}
+
+// Create stores a review together with its comments in one transaction
+func (r *reviewRepository) Create(ctx context.Context, review *model.TaskReview) error {
+	if review.ID == uuid.Nil {
+		review.ID = uuid.New()
+	}
+
+	now := time.Now()
+	if review.CreatedAt.IsZero() {
+		review.CreatedAt = now
+	}
+	if review.UpdatedAt.IsZero() {
+		review.UpdatedAt = now
+	}
+	for i := range review.Comments {
+		if review.Comments[i].ID == uuid.Nil {
+			review.Comments[i].ID = uuid.New()
+		}
+		review.Comments[i].ReviewID = review.ID
+		if review.Comments[i].CreatedAt.IsZero() {
+			review.Comments[i].CreatedAt = now
+		}
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Comments").Create(review).Error; err != nil {
+			return err
+		}
+		if len(review.Comments) == 0 {
+			return nil
+		}
+		return tx.Create(&review.Comments).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create review: %w", err)
+	}
+
+	return nil
+}
+
+// FindByTaskID returns the reviews of a task, newest first
+func (r *reviewRepository) FindByTaskID(ctx context.Context, taskID uuid.UUID) ([]model.TaskReview, error) {
+	var reviews []model.TaskReview
+	if err := r.db.WithContext(ctx).
+		Preload("Comments", func(db *gorm.DB) *gorm.DB {
+			return db.Order("file_path ASC, line ASC, created_at ASC")
+		}).
+		Where("task_id = ?", taskID).
+		Order("created_at DESC").
+		Find(&reviews).Error; err != nil {
+		return nil, fmt.Errorf("failed to find reviews by task ID: %w", err)
+	}
+
+	return reviews, nil
+}
+
+// FindLatestByTaskID returns the most recent review of a task
+func (r *reviewRepository) FindLatestByTaskID(ctx context.Context, taskID uuid.UUID) (*model.TaskReview, error) {
+	var review model.TaskReview
+	if err := r.db.WithContext(ctx).
+		Preload("Comments", func(db *gorm.DB) *gorm.DB {
+			return db.Order("file_path ASC, line ASC, created_at ASC")
+		}).
+		Where("task_id = ?", taskID).
+		Order("created_at DESC").
+		First(&review).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("failed to find latest review: %w", err)
+	}
+
+	return &review, nil
+}
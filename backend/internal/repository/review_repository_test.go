@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/npinot/vibe/backend/internal/model"
+)
+
+func setupReviewTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		DisableForeignKeyConstraintWhenMigrating: true,
+	})
+	require.NoError(t, err)
+
+	createTablesSQL := `
+		CREATE TABLE task_reviews (
+			id TEXT PRIMARY KEY,
+			task_id TEXT NOT NULL,
+			session_id TEXT,
+			reviewer_id TEXT NOT NULL,
+			decision TEXT NOT NULL,
+			summary TEXT,
+			created_at DATETIME NOT NULL,
+			updated_at DATETIME NOT NULL
+		);
+
+		CREATE TABLE review_comments (
+			id TEXT PRIMARY KEY,
+			review_id TEXT NOT NULL,
+			file_path TEXT,
+			line INTEGER NOT NULL DEFAULT 0,
+			body TEXT NOT NULL,
+			created_at DATETIME NOT NULL,
+			FOREIGN KEY (review_id) REFERENCES task_reviews(id)
+		);
+	`
+
+	err = db.Exec(createTablesSQL).Error
+	require.NoError(t, err)
+
+	return db
+}
+
+func TestReviewRepository_Create(t *testing.T) {
+	db := setupReviewTestDB(t)
+	repo := NewReviewRepository(db)
+	ctx := context.Background()
+
+	sessionID := uuid.New()
+	review := &model.TaskReview{
+		TaskID:     uuid.New(),
+		SessionID:  &sessionID,
+		ReviewerID: uuid.New(),
+		Decision:   model.ReviewDecisionChangesRequested,
+		Summary:    "Almost there",
+		Comments: []model.ReviewComment{
+			{FilePath: "main.go", Line: 12, Body: "Handle the error"},
+			{Body: "Add tests"},
+		},
+	}
+
+	err := repo.Create(ctx, review)
+	require.NoError(t, err)
+	assert.NotEqual(t, uuid.Nil, review.ID)
+	assert.NotZero(t, review.CreatedAt)
+
+	var count int64
+	db.Model(&model.ReviewComment{}).Where("review_id = ?", review.ID).Count(&count)
+	assert.Equal(t, int64(2), count)
+	for _, comment := range review.Comments {
+		assert.Equal(t, review.ID, comment.ReviewID)
+		assert.NotEqual(t, uuid.Nil, comment.ID)
+	}
+}
+
+func TestReviewRepository_Create_RollsBackOnCommentFailure(t *testing.T) {
+	db := setupReviewTestDB(t)
+	repo := NewReviewRepository(db)
+	ctx := context.Background()
+
+	commentID := uuid.New()
+	review := &model.TaskReview{
+		TaskID:     uuid.New(),
+		ReviewerID: uuid.New(),
+		Decision:   model.ReviewDecisionChangesRequested,
+		Comments: []model.ReviewComment{
+			{ID: commentID, Body: "first"},
+			{ID: commentID, Body: "duplicate"},
+		},
+	}
+
+	err := repo.Create(ctx, review)
+	require.Error(t, err)
+
+	var reviews, comments int64
+	db.Model(&model.TaskReview{}).Count(&reviews)
+	db.Model(&model.ReviewComment{}).Count(&comments)
+	assert.Zero(t, reviews)
+	assert.Zero(t, comments)
+}
+
+func TestReviewRepository_FindByTaskID(t *testing.T) {
+	db := setupReviewTestDB(t)
+	repo := NewReviewRepository(db)
+	ctx := context.Background()
+
+	taskID := uuid.New()
+	reviewerID := uuid.New()
+	first := &model.TaskReview{
+		TaskID:     taskID,
+		ReviewerID: reviewerID,
+		Decision:   model.ReviewDecisionChangesRequested,
+		CreatedAt:  time.Now().Add(-time.Hour),
+		Comments: []model.ReviewComment{
+			{FilePath: "b.go", Line: 3, Body: "second"},
+			{FilePath: "a.go", Line: 7, Body: "first"},
+		},
+	}
+	second := &model.TaskReview{
+		TaskID:     taskID,
+		ReviewerID: reviewerID,
+		Decision:   model.ReviewDecisionApproved,
+	}
+	other := &model.TaskReview{
+		TaskID:     uuid.New(),
+		ReviewerID: reviewerID,
+		Decision:   model.ReviewDecisionApproved,
+	}
+	require.NoError(t, repo.Create(ctx, first))
+	require.NoError(t, repo.Create(ctx, second))
+	require.NoError(t, repo.Create(ctx, other))
+
+	reviews, err := repo.FindByTaskID(ctx, taskID)
+	require.NoError(t, err)
+	require.Len(t, reviews, 2)
+	assert.Equal(t, second.ID, reviews[0].ID)
+	assert.Equal(t, first.ID, reviews[1].ID)
+	require.Len(t, reviews[1].Comments, 2)
+	assert.Equal(t, "a.go", reviews[1].Comments[0].FilePath)
+
+	latest, err := repo.FindLatestByTaskID(ctx, taskID)
+	require.NoError(t, err)
+	assert.Equal(t, model.ReviewDecisionApproved, latest.Decision)
+}
+
+func TestReviewRepository_FindLatestByTaskID_NotFound(t *testing.T) {
+	db := setupReviewTestDB(t)
+	repo := NewReviewRepository(db)
+
+	_, err := repo.FindLatestByTaskID(context.Background(), uuid.New())
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -17,13 +18,30 @@ type SessionRepository interface {
 	FindByTaskID(ctx context.Context, taskID uuid.UUID) ([]model.Session, error)
 	FindActiveSessionsForProject(ctx context.Context, projectID uuid.UUID) ([]model.Session, error)
 	FindAllActiveSessions(ctx context.Context) ([]model.Session, error)
+	FindWithSnapshots(ctx context.Context, projectID uuid.UUID, since time.Time) ([]model.Session, error)
 	Update(ctx context.Context, session *model.Session) error
 	UpdateStatus(ctx context.Context, id uuid.UUID, status model.SessionStatus) error
 	UpdateOutput(ctx context.Context, id uuid.UUID, output string) error
+	AppendOutput(ctx context.Context, id uuid.UUID, taskID uuid.UUID, chunk string, lastEventID string) error
+	UpdateFinalOutput(ctx context.Context, id uuid.UUID, finalOutput string) error
 	UpdateLastEventID(ctx context.Context, id uuid.UUID, lastEventID string) error
+	IncrementIterations(ctx context.Context, id uuid.UUID) (int, error)
+	AddUsage(ctx context.Context, id uuid.UUID, usage SessionUsage) error
+	GetProjectUsage(ctx context.Context, projectID uuid.UUID) (*model.ProjectUsage, error)
+	SumProjectUsageSince(ctx context.Context, projectID uuid.UUID, since time.Time) (*model.UsageTotals, error)
+	SumUserUsageSince(ctx context.Context, userID uuid.UUID, since time.Time) (*model.UsageTotals, error)
 	SoftDelete(ctx context.Context, id uuid.UUID) error
 }
 
+// SessionUsage is the token usage of one model call made by a session
+type SessionUsage struct {
+	ModelProvider string
+	ModelName     string
+	InputTokens   int64
+	OutputTokens  int64
+	CostUSD       float64
+}
+
 type sessionRepository struct {
 	db *gorm.DB
 }
@@ -74,6 +92,7 @@ func (r *sessionRepository) FindActiveSessionsForProject(ctx context.Context, pr
 		Where("project_id = ? AND status IN ?", projectID, []model.SessionStatus{
 			model.SessionStatusPending,
 			model.SessionStatusRunning,
+			model.SessionStatusWaitingForInput,
 		}).
 		Order("created_at DESC").
 		Find(&sessions).Error; err != nil {
@@ -121,6 +140,43 @@ func (r *sessionRepository) UpdateOutput(ctx context.Context, id uuid.UUID, outp
 	return nil
 }
 
+// AppendOutput appends a chunk of streamed output to the session and, unless taskID is nil, to the output
+// of its task, and records the event it came from in one transaction, so a resumed stream never replays
+// output that was already stored nor skips output one of the transcripts is missing
+func (r *sessionRepository) AppendOutput(ctx context.Context, id uuid.UUID, taskID uuid.UUID, chunk string, lastEventID string) error {
+	updates := map[string]interface{}{
+		"last_event_id": lastEventID,
+	}
+	if chunk != "" {
+		updates["output"] = gorm.Expr("COALESCE(output, '') || ?", chunk)
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Session{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to append session output: %w", err)
+		}
+		if taskID == uuid.Nil || chunk == "" {
+			return nil
+		}
+		return appendTaskOutput(tx, taskID, id, chunk)
+	})
+}
+
+func (r *sessionRepository) UpdateFinalOutput(ctx context.Context, id uuid.UUID, finalOutput string) error {
+	updates := map[string]interface{}{
+		"final_output": finalOutput,
+	}
+
+	if err := r.db.WithContext(ctx).
+		Model(&model.Session{}).
+		Where("id = ?", id).
+		Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to update session final output: %w", err)
+	}
+
+	return nil
+}
+
 func (r *sessionRepository) UpdateLastEventID(ctx context.Context, id uuid.UUID, lastEventID string) error {
 	updates := map[string]interface{}{
 		"last_event_id": lastEventID,
@@ -136,6 +192,159 @@ func (r *sessionRepository) UpdateLastEventID(ctx context.Context, id uuid.UUID,
 	return nil
 }
 
+// IncrementIterations counts one more iteration of the session's current run and returns the new count
+func (r *sessionRepository) IncrementIterations(ctx context.Context, id uuid.UUID) (int, error) {
+	var session model.Session
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Session{}).
+			Where("id = ?", id).
+			UpdateColumn("iterations", gorm.Expr("COALESCE(iterations, 0) + 1")).Error; err != nil {
+			return err
+		}
+		return tx.Select("iterations").Where("id = ?", id).First(&session).Error
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to increment session iterations: %w", err)
+	}
+
+	return session.Iterations, nil
+}
+
+// AddUsage adds the tokens and cost of a model call to the session's totals, records the model it used and
+// keeps a usage record of the call, which budgets sum by the time it was made
+func (r *sessionRepository) AddUsage(ctx context.Context, id uuid.UUID, usage SessionUsage) error {
+	updates := map[string]interface{}{
+		"input_tokens":  gorm.Expr("COALESCE(input_tokens, 0) + ?", usage.InputTokens),
+		"output_tokens": gorm.Expr("COALESCE(output_tokens, 0) + ?", usage.OutputTokens),
+		"cost_usd":      gorm.Expr("COALESCE(cost_usd, 0) + ?", usage.CostUSD),
+	}
+	if usage.ModelName != "" {
+		updates["model_provider"] = usage.ModelProvider
+		updates["model_name"] = usage.ModelName
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var session model.Session
+		if err := tx.Select("id", "project_id").Where("id = ?", id).First(&session).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&model.Session{}).Where("id = ?", id).UpdateColumns(updates).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&model.SessionUsageRecord{
+			ID:            uuid.New(),
+			SessionID:     session.ID,
+			ProjectID:     session.ProjectID,
+			ModelProvider: usage.ModelProvider,
+			ModelName:     usage.ModelName,
+			InputTokens:   usage.InputTokens,
+			OutputTokens:  usage.OutputTokens,
+			CostUSD:       usage.CostUSD,
+			RecordedAt:    time.Now().UTC(),
+		}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add session usage: %w", err)
+	}
+
+	return nil
+}
+
+// usageColumns sums the usage of the sessions in a group
+const usageColumns = "COUNT(*) AS sessions, " +
+	"COALESCE(SUM(sessions.input_tokens), 0) AS input_tokens, " +
+	"COALESCE(SUM(sessions.output_tokens), 0) AS output_tokens, " +
+	"COALESCE(SUM(sessions.cost_usd), 0) AS cost_usd"
+
+// GetProjectUsage sums the usage of the project's sessions in total, by day it was recorded, by model and by task
+func (r *sessionRepository) GetProjectUsage(ctx context.Context, projectID uuid.UUID) (*model.ProjectUsage, error) {
+	usage := &model.ProjectUsage{
+		ProjectID: projectID,
+		ByDay:     []model.DailyUsage{},
+		ByModel:   []model.ModelUsage{},
+		ByTask:    []model.TaskUsage{},
+	}
+
+	sessions := func() *gorm.DB {
+		return r.db.WithContext(ctx).Model(&model.Session{}).Where("sessions.project_id = ?", projectID)
+	}
+
+	if err := sessions().Select(usageColumns).Scan(&usage.Total).Error; err != nil {
+		return nil, fmt.Errorf("failed to sum project usage: %w", err)
+	}
+
+	// Sessions can run across days, so daily usage comes from the usage recorded on each day
+	if err := r.db.WithContext(ctx).
+		Model(&model.SessionUsageRecord{}).
+		Select("CAST(DATE(session_usage.recorded_at) AS TEXT) AS day, "+recordedUsageColumns).
+		Where("session_usage.project_id = ?", projectID).
+		Group("DATE(session_usage.recorded_at)").
+		Order("DATE(session_usage.recorded_at)").
+		Scan(&usage.ByDay).Error; err != nil {
+		return nil, fmt.Errorf("failed to sum project usage by day: %w", err)
+	}
+
+	if err := sessions().
+		Select("COALESCE(model_provider, '') AS model_provider, COALESCE(model_name, '') AS model_name, " + usageColumns).
+		Group("COALESCE(model_provider, ''), COALESCE(model_name, '')").
+		Order("cost_usd DESC").
+		Scan(&usage.ByModel).Error; err != nil {
+		return nil, fmt.Errorf("failed to sum project usage by model: %w", err)
+	}
+
+	if err := sessions().
+		Select("sessions.task_id AS task_id, tasks.title AS task_title, " + usageColumns).
+		Joins("JOIN tasks ON tasks.id = sessions.task_id").
+		Group("sessions.task_id, tasks.title").
+		Order("cost_usd DESC").
+		Scan(&usage.ByTask).Error; err != nil {
+		return nil, fmt.Errorf("failed to sum project usage by task: %w", err)
+	}
+
+	return usage, nil
+}
+
+// recordedUsageColumns sums the usage records in a group, counting the sessions they belong to
+const recordedUsageColumns = "COUNT(DISTINCT session_usage.session_id) AS sessions, " +
+	"COALESCE(SUM(session_usage.input_tokens), 0) AS input_tokens, " +
+	"COALESCE(SUM(session_usage.output_tokens), 0) AS output_tokens, " +
+	"COALESCE(SUM(session_usage.cost_usd), 0) AS cost_usd"
+
+// SumProjectUsageSince sums the usage the project's sessions recorded since the given time
+func (r *sessionRepository) SumProjectUsageSince(ctx context.Context, projectID uuid.UUID, since time.Time) (*model.UsageTotals, error) {
+	var totals model.UsageTotals
+
+	if err := r.db.WithContext(ctx).
+		Model(&model.SessionUsageRecord{}).
+		Select(recordedUsageColumns).
+		Where("session_usage.project_id = ? AND session_usage.recorded_at >= ?", projectID, since).
+		Scan(&totals).Error; err != nil {
+		return nil, fmt.Errorf("failed to sum project usage: %w", err)
+	}
+
+	return &totals, nil
+}
+
+// SumUserUsageSince sums the usage recorded since the given time by the sessions of all projects the user owns,
+// including deleted ones
+func (r *sessionRepository) SumUserUsageSince(ctx context.Context, userID uuid.UUID, since time.Time) (*model.UsageTotals, error) {
+	var totals model.UsageTotals
+
+	if err := r.db.WithContext(ctx).
+		Model(&model.SessionUsageRecord{}).
+		Select(recordedUsageColumns).
+		Joins("JOIN projects ON projects.id = session_usage.project_id").
+		Where("projects.user_id = ? AND session_usage.recorded_at >= ?", userID, since).
+		Scan(&totals).Error; err != nil {
+		return nil, fmt.Errorf("failed to sum user usage: %w", err)
+	}
+
+	return &totals, nil
+}
+
 func (r *sessionRepository) SoftDelete(ctx context.Context, id uuid.UUID) error {
 	if err := r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.Session{}).Error; err != nil {
 		return fmt.Errorf("failed to soft delete session: %w", err)
@@ -148,10 +357,33 @@ func (r *sessionRepository) FindAllActiveSessions(ctx context.Context) ([]model.
 	var sessions []model.Session
 
 	if err := r.db.WithContext(ctx).
-		Where("status IN ?", []string{"pending", "running", "waiting_input"}).
+		Where("status IN ?", []model.SessionStatus{
+			model.SessionStatusPending,
+			model.SessionStatusRunning,
+			model.SessionStatusWaitingForInput,
+		}).
 		Find(&sessions).Error; err != nil {
 		return nil, fmt.Errorf("failed to find all active sessions: %w", err)
 	}
 
 	return sessions, nil
 }
+
+// FindWithSnapshots returns the sessions of the project that recorded a workspace snapshot since the
+// given time, along with the active ones, which may still be taking theirs
+func (r *sessionRepository) FindWithSnapshots(ctx context.Context, projectID uuid.UUID, since time.Time) ([]model.Session, error) {
+	var sessions []model.Session
+
+	if err := r.db.WithContext(ctx).
+		Where("project_id = ?", projectID).
+		Where("(base_snapshot <> '' AND created_at >= ?) OR status IN ?", since, []model.SessionStatus{
+			model.SessionStatusPending,
+			model.SessionStatusRunning,
+			model.SessionStatusWaitingForInput,
+		}).
+		Find(&sessions).Error; err != nil {
+		return nil, fmt.Errorf("failed to find sessions with snapshots: %w", err)
+	}
+
+	return sessions, nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -59,6 +60,8 @@ func setupSessionTestDB(t *testing.T) *gorm.DB {
 			priority TEXT NOT NULL DEFAULT 'medium',
 			position INTEGER NOT NULL DEFAULT 0,
 			assigned_to TEXT,
+			current_session_id TEXT,
+			opencode_output TEXT,
 			created_at DATETIME,
 			updated_at DATETIME,
 			deleted_at DATETIME
@@ -69,10 +72,29 @@ func setupSessionTestDB(t *testing.T) *gorm.DB {
 			task_id TEXT NOT NULL,
 			project_id TEXT NOT NULL,
 			status TEXT NOT NULL DEFAULT 'pending',
+			kind TEXT NOT NULL DEFAULT 'execution',
 			prompt TEXT,
 			output TEXT,
+			final_output TEXT,
 			error TEXT,
+			remote_session_id TEXT,
+			last_event_id TEXT,
+			prompt_request_id TEXT,
+			branch_name TEXT,
+			commit_sha TEXT,
+			base_snapshot TEXT,
+			final_snapshot TEXT,
+			timeout_seconds INTEGER DEFAULT 0,
+			max_iterations INTEGER DEFAULT 0,
+			iterations INTEGER DEFAULT 0,
+			deadline_at DATETIME,
+			model_provider TEXT,
+			model_name TEXT,
+			input_tokens INTEGER DEFAULT 0,
+			output_tokens INTEGER DEFAULT 0,
+			cost_usd REAL DEFAULT 0,
 			started_at DATETIME,
+			run_started_at DATETIME,
 			completed_at DATETIME,
 			duration_ms INTEGER DEFAULT 0,
 			created_at DATETIME,
@@ -82,6 +104,19 @@ func setupSessionTestDB(t *testing.T) *gorm.DB {
 			FOREIGN KEY (project_id) REFERENCES projects(id)
 		);
 
+		CREATE TABLE session_usage (
+			id TEXT PRIMARY KEY,
+			session_id TEXT NOT NULL,
+			project_id TEXT NOT NULL,
+			model_provider TEXT,
+			model_name TEXT,
+			input_tokens INTEGER DEFAULT 0,
+			output_tokens INTEGER DEFAULT 0,
+			cost_usd REAL DEFAULT 0,
+			recorded_at DATETIME NOT NULL,
+			FOREIGN KEY (session_id) REFERENCES sessions(id)
+		);
+
 		CREATE INDEX idx_sessions_task_id ON sessions(task_id);
 		CREATE INDEX idx_sessions_project_id ON sessions(project_id);
 		CREATE INDEX idx_sessions_deleted_at ON sessions(deleted_at);
@@ -208,9 +243,10 @@ func TestSessionRepository_FindActiveSessionsForProject(t *testing.T) {
 
 	projectID := uuid.New()
 
-	// Create active sessions (pending, running)
+	// Create active sessions (pending, running, waiting for input)
 	pending := createTestSession(t, db, uuid.New(), projectID, model.SessionStatusPending)
 	running := createTestSession(t, db, uuid.New(), projectID, model.SessionStatusRunning)
+	waiting := createTestSession(t, db, uuid.New(), projectID, model.SessionStatusWaitingForInput)
 
 	// Create inactive sessions
 	createTestSession(t, db, uuid.New(), projectID, model.SessionStatusCompleted)
@@ -222,11 +258,12 @@ func TestSessionRepository_FindActiveSessionsForProject(t *testing.T) {
 
 	active, err := repo.FindActiveSessionsForProject(ctx, projectID)
 	require.NoError(t, err)
-	assert.Len(t, active, 2)
+	assert.Len(t, active, 3)
 
-	ids := []uuid.UUID{active[0].ID, active[1].ID}
+	ids := []uuid.UUID{active[0].ID, active[1].ID, active[2].ID}
 	assert.Contains(t, ids, pending.ID)
 	assert.Contains(t, ids, running.ID)
+	assert.Contains(t, ids, waiting.ID)
 }
 
 func TestSessionRepository_FindActiveSessionsForProject_Empty(t *testing.T) {
@@ -245,6 +282,38 @@ func TestSessionRepository_FindActiveSessionsForProject_Empty(t *testing.T) {
 	assert.Empty(t, active)
 }
 
+func TestSessionRepository_FindWithSnapshots(t *testing.T) {
+	db := setupSessionTestDB(t)
+	repo := NewSessionRepository(db)
+	ctx := context.Background()
+
+	projectID := uuid.New()
+	since := time.Now().Add(-time.Hour)
+	withSnapshot := func(status model.SessionStatus, createdAt time.Time, snapshot string) *model.Session {
+		session := createTestSession(t, db, uuid.New(), projectID, status)
+		require.NoError(t, db.Model(session).UpdateColumns(map[string]interface{}{"base_snapshot": snapshot, "created_at": createdAt}).Error)
+		return session
+	}
+
+	recent := withSnapshot(model.SessionStatusCompleted, time.Now(), "base-1")
+	oldRunning := withSnapshot(model.SessionStatusRunning, since.Add(-time.Hour), "base-2")
+	starting := withSnapshot(model.SessionStatusPending, time.Now(), "")
+	withSnapshot(model.SessionStatusCompleted, since.Add(-time.Hour), "base-3")
+	withSnapshot(model.SessionStatusCompleted, time.Now(), "")
+	deleted := withSnapshot(model.SessionStatusCompleted, time.Now(), "base-4")
+	require.NoError(t, repo.SoftDelete(ctx, deleted.ID))
+	createTestSession(t, db, uuid.New(), uuid.New(), model.SessionStatusRunning)
+
+	sessions, err := repo.FindWithSnapshots(ctx, projectID, since)
+	require.NoError(t, err)
+
+	var ids []uuid.UUID
+	for _, session := range sessions {
+		ids = append(ids, session.ID)
+	}
+	assert.ElementsMatch(t, []uuid.UUID{recent.ID, oldRunning.ID, starting.ID}, ids)
+}
+
 func TestSessionRepository_Update(t *testing.T) {
 	db := setupSessionTestDB(t)
 	repo := NewSessionRepository(db)
@@ -298,6 +367,283 @@ func TestSessionRepository_UpdateOutput(t *testing.T) {
 	assert.Equal(t, newOutput, found.Output)
 }
 
+func TestSessionRepository_AppendOutput(t *testing.T) {
+	db := setupSessionTestDB(t)
+	repo := NewSessionRepository(db)
+	ctx := context.Background()
+
+	session := createTestSession(t, db, uuid.New(), uuid.New(), model.SessionStatusRunning)
+	require.NoError(t, repo.UpdateOutput(ctx, session.ID, ""))
+
+	require.NoError(t, repo.AppendOutput(ctx, session.ID, uuid.Nil, "Reading files\n", "evt-1"))
+	require.NoError(t, repo.AppendOutput(ctx, session.ID, uuid.Nil, "", "evt-2"))
+	require.NoError(t, repo.AppendOutput(ctx, session.ID, uuid.Nil, "Done\n", "evt-3"))
+
+	found, err := repo.FindByID(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Reading files\nDone\n", found.Output)
+	assert.Equal(t, "evt-3", found.LastEventID)
+}
+
+func TestSessionRepository_AppendOutputToTask(t *testing.T) {
+	db := setupSessionTestDB(t)
+	repo := NewSessionRepository(db)
+	ctx := context.Background()
+
+	taskID := uuid.New()
+	projectID := uuid.New()
+	require.NoError(t, db.Exec("INSERT INTO tasks (id, project_id, title) VALUES (?, ?, ?)", taskID, projectID, "Test Task").Error)
+	session := createTestSession(t, db, taskID, projectID, model.SessionStatusRunning)
+	require.NoError(t, repo.UpdateOutput(ctx, session.ID, ""))
+
+	failTask := false
+	require.NoError(t, db.Callback().Update().Before("gorm:update").Register("test:fail_task_once", func(tx *gorm.DB) {
+		if failTask && tx.Statement.Table == "tasks" {
+			failTask = false
+			tx.AddError(fmt.Errorf("connection reset"))
+		}
+	}))
+
+	require.NoError(t, repo.AppendOutput(ctx, session.ID, taskID, "Reading files\n", "evt-1"))
+
+	// The task update fails once, like a dropped connection after the session update would
+	failTask = true
+	require.Error(t, repo.AppendOutput(ctx, session.ID, taskID, "Done\n", "evt-2"))
+
+	found, err := repo.FindByID(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Reading files\n", found.Output, "a failed task append must not keep the session append")
+	assert.Equal(t, "evt-1", found.LastEventID, "the stream must resume before the failed event")
+
+	// The resumed stream replays the failed event
+	require.NoError(t, repo.AppendOutput(ctx, session.ID, taskID, "Done\n", "evt-2"))
+
+	found, err = repo.FindByID(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Reading files\nDone\n", found.Output)
+	assert.Equal(t, "evt-2", found.LastEventID)
+
+	var task model.Task
+	require.NoError(t, db.First(&task, "id = ?", taskID).Error)
+	assert.Equal(t, "Reading files\nDone\n", task.OpenCodeOutput)
+	require.NotNil(t, task.CurrentSessionID)
+	assert.Equal(t, session.ID, *task.CurrentSessionID)
+}
+
+func TestSessionRepository_IncrementIterations(t *testing.T) {
+	db := setupSessionTestDB(t)
+	repo := NewSessionRepository(db)
+	ctx := context.Background()
+
+	session := createTestSession(t, db, uuid.New(), uuid.New(), model.SessionStatusRunning)
+
+	count, err := repo.IncrementIterations(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	count, err = repo.IncrementIterations(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	found, err := repo.FindByID(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, found.Iterations)
+}
+
+func TestSessionRepository_AddUsage(t *testing.T) {
+	db := setupSessionTestDB(t)
+	repo := NewSessionRepository(db)
+	ctx := context.Background()
+
+	session := createTestSession(t, db, uuid.New(), uuid.New(), model.SessionStatusRunning)
+
+	err := repo.AddUsage(ctx, session.ID, SessionUsage{ModelProvider: "anthropic", ModelName: "claude-3.5-sonnet-20240620", InputTokens: 1000, OutputTokens: 200, CostUSD: 0.006})
+	require.NoError(t, err)
+	// Usage without a model keeps the recorded one
+	err = repo.AddUsage(ctx, session.ID, SessionUsage{InputTokens: 500, OutputTokens: 100, CostUSD: 0.003})
+	require.NoError(t, err)
+
+	found, err := repo.FindByID(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "anthropic", found.ModelProvider)
+	assert.Equal(t, "claude-3.5-sonnet-20240620", found.ModelName)
+	assert.Equal(t, int64(1500), found.InputTokens)
+	assert.Equal(t, int64(300), found.OutputTokens)
+	assert.InDelta(t, 0.009, found.CostUSD, 1e-9)
+
+	var records []model.SessionUsageRecord
+	require.NoError(t, db.Order("input_tokens DESC").Find(&records).Error)
+	require.Len(t, records, 2)
+	assert.Equal(t, session.ProjectID, records[0].ProjectID)
+	assert.Equal(t, "claude-3.5-sonnet-20240620", records[0].ModelName)
+	assert.Equal(t, int64(1000), records[0].InputTokens)
+	assert.Equal(t, int64(500), records[1].InputTokens)
+	assert.WithinDuration(t, time.Now(), records[1].RecordedAt, time.Minute)
+
+	err = repo.AddUsage(ctx, uuid.New(), SessionUsage{InputTokens: 1})
+	assert.Error(t, err)
+}
+
+func TestSessionRepository_GetProjectUsage(t *testing.T) {
+	db := setupSessionTestDB(t)
+	repo := NewSessionRepository(db)
+	ctx := context.Background()
+
+	projectID := uuid.New()
+	build := &model.Task{ID: uuid.New(), ProjectID: projectID, Title: "Build feature"}
+	fix := &model.Task{ID: uuid.New(), ProjectID: projectID, Title: "Fix bug"}
+	for _, task := range []*model.Task{build, fix} {
+		require.NoError(t, db.Exec("INSERT INTO tasks (id, project_id, title) VALUES (?, ?, ?)", task.ID, task.ProjectID, task.Title).Error)
+	}
+
+	day1 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	day2 := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	sessions := []*model.Session{
+		{TaskID: build.ID, CreatedAt: day1, ModelProvider: "openai", ModelName: "gpt-4o", InputTokens: 1000, OutputTokens: 100, CostUSD: 1.0},
+		{TaskID: build.ID, CreatedAt: day2, ModelProvider: "anthropic", ModelName: "claude-3.5-sonnet-20240620", InputTokens: 2000, OutputTokens: 200, CostUSD: 2.0},
+		{TaskID: fix.ID, CreatedAt: day2, ModelProvider: "openai", ModelName: "gpt-4o", InputTokens: 500, OutputTokens: 50, CostUSD: 0.5},
+		// Other projects are not counted
+		{TaskID: uuid.New(), ProjectID: uuid.New(), CreatedAt: day1, InputTokens: 9999, CostUSD: 99},
+	}
+	for _, session := range sessions {
+		session.ID = uuid.New()
+		session.Status = model.SessionStatusCompleted
+		if session.ProjectID == uuid.Nil {
+			session.ProjectID = projectID
+		}
+		require.NoError(t, db.Create(session).Error)
+	}
+
+	// The first session ran past midnight, so part of its usage was recorded on the second day
+	records := []model.SessionUsageRecord{
+		{SessionID: sessions[0].ID, InputTokens: 600, OutputTokens: 60, CostUSD: 0.6, RecordedAt: day1},
+		{SessionID: sessions[0].ID, InputTokens: 400, OutputTokens: 40, CostUSD: 0.4, RecordedAt: day2},
+		{SessionID: sessions[1].ID, InputTokens: 2000, OutputTokens: 200, CostUSD: 2.0, RecordedAt: day2},
+		{SessionID: sessions[2].ID, InputTokens: 500, OutputTokens: 50, CostUSD: 0.5, RecordedAt: day2},
+		{SessionID: sessions[3].ID, InputTokens: 9999, CostUSD: 99, RecordedAt: day1},
+	}
+	for i := range records {
+		records[i].ID = uuid.New()
+		for _, session := range sessions {
+			if session.ID == records[i].SessionID {
+				records[i].ProjectID = session.ProjectID
+			}
+		}
+		require.NoError(t, db.Create(&records[i]).Error)
+	}
+
+	usage, err := repo.GetProjectUsage(ctx, projectID)
+	require.NoError(t, err)
+
+	assert.Equal(t, projectID, usage.ProjectID)
+	assert.Equal(t, int64(3), usage.Total.Sessions)
+	assert.Equal(t, int64(3500), usage.Total.InputTokens)
+	assert.Equal(t, int64(350), usage.Total.OutputTokens)
+	assert.InDelta(t, 3.5, usage.Total.CostUSD, 1e-9)
+
+	require.Len(t, usage.ByDay, 2)
+	assert.Equal(t, "2026-03-01", usage.ByDay[0].Day)
+	assert.Equal(t, int64(1), usage.ByDay[0].Sessions)
+	assert.InDelta(t, 0.6, usage.ByDay[0].CostUSD, 1e-9)
+	assert.Equal(t, "2026-03-02", usage.ByDay[1].Day)
+	assert.Equal(t, int64(3), usage.ByDay[1].Sessions)
+	assert.Equal(t, int64(2900), usage.ByDay[1].InputTokens)
+	assert.InDelta(t, 2.9, usage.ByDay[1].CostUSD, 1e-9)
+
+	require.Len(t, usage.ByModel, 2)
+	assert.Equal(t, "claude-3.5-sonnet-20240620", usage.ByModel[0].ModelName)
+	assert.Equal(t, "gpt-4o", usage.ByModel[1].ModelName)
+	assert.Equal(t, "openai", usage.ByModel[1].ModelProvider)
+	assert.Equal(t, int64(1500), usage.ByModel[1].InputTokens)
+
+	require.Len(t, usage.ByTask, 2)
+	assert.Equal(t, build.ID, usage.ByTask[0].TaskID)
+	assert.Equal(t, "Build feature", usage.ByTask[0].TaskTitle)
+	assert.Equal(t, int64(2), usage.ByTask[0].Sessions)
+	assert.InDelta(t, 3.0, usage.ByTask[0].CostUSD, 1e-9)
+	assert.Equal(t, fix.ID, usage.ByTask[1].TaskID)
+}
+
+func TestSessionRepository_GetProjectUsage_NoSessions(t *testing.T) {
+	db := setupSessionTestDB(t)
+	repo := NewSessionRepository(db)
+
+	usage, err := repo.GetProjectUsage(context.Background(), uuid.New())
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), usage.Total.Sessions)
+	assert.Empty(t, usage.ByDay)
+	assert.Empty(t, usage.ByTask)
+}
+
+func TestSessionRepository_SumUsageSince(t *testing.T) {
+	db := setupSessionTestDB(t)
+	repo := NewSessionRepository(db)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	projectA, projectB, otherProject := uuid.New(), uuid.New(), uuid.New()
+	for i, project := range []struct{ id, owner uuid.UUID }{{projectA, userID}, {projectB, userID}, {otherProject, uuid.New()}} {
+		require.NoError(t, db.Exec("INSERT INTO projects (id, name, slug, user_id) VALUES (?, ?, ?, ?)",
+			project.id, "project", fmt.Sprintf("project-%d", i), project.owner).Error)
+	}
+
+	monthStart := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	records := []struct {
+		project                   uuid.UUID
+		created, recorded         time.Time
+		inputTokens, outputTokens int64
+		cost                      float64
+	}{
+		// Sessions started last month count with the usage they recorded this month
+		{projectA, monthStart.Add(-time.Hour), monthStart.Add(time.Hour), 1000, 100, 1.0},
+		{projectB, monthStart.Add(48 * time.Hour), monthStart.Add(48 * time.Hour), 500, 50, 0.5},
+		// Usage recorded last month and by other users is not counted
+		{projectA, monthStart.Add(-2 * time.Hour), monthStart.Add(-time.Hour), 9999, 0, 99},
+		{otherProject, monthStart.Add(time.Hour), monthStart.Add(time.Hour), 9999, 0, 99},
+	}
+	for _, record := range records {
+		session := model.Session{ID: uuid.New(), TaskID: uuid.New(), ProjectID: record.project, Status: model.SessionStatusCompleted, CreatedAt: record.created}
+		require.NoError(t, db.Create(&session).Error)
+		require.NoError(t, db.Create(&model.SessionUsageRecord{
+			ID:           uuid.New(),
+			SessionID:    session.ID,
+			ProjectID:    record.project,
+			InputTokens:  record.inputTokens,
+			OutputTokens: record.outputTokens,
+			CostUSD:      record.cost,
+			RecordedAt:   record.recorded,
+		}).Error)
+	}
+
+	projectTotals, err := repo.SumProjectUsageSince(ctx, projectA, monthStart)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), projectTotals.Sessions)
+	assert.Equal(t, int64(1100), projectTotals.InputTokens+projectTotals.OutputTokens)
+	assert.InDelta(t, 1.0, projectTotals.CostUSD, 1e-9)
+
+	userTotals, err := repo.SumUserUsageSince(ctx, userID, monthStart)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), userTotals.Sessions)
+	assert.Equal(t, int64(1500), userTotals.InputTokens)
+	assert.InDelta(t, 1.5, userTotals.CostUSD, 1e-9)
+}
+
+func TestSessionRepository_UpdateFinalOutput(t *testing.T) {
+	db := setupSessionTestDB(t)
+	repo := NewSessionRepository(db)
+	ctx := context.Background()
+
+	session := createTestSession(t, db, uuid.New(), uuid.New(), model.SessionStatusRunning)
+
+	err := repo.UpdateFinalOutput(ctx, session.ID, "All tests pass")
+	require.NoError(t, err)
+
+	found, err := repo.FindByID(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "All tests pass", found.FinalOutput)
+	assert.Equal(t, session.Output, found.Output)
+}
+
 func TestSessionRepository_SoftDelete(t *testing.T) {
 	db := setupSessionTestDB(t)
 	repo := NewSessionRepository(db)
@@ -18,6 +18,7 @@ type TaskRepository interface {
 	Update(ctx context.Context, task *model.Task) error
 	UpdateStatus(ctx context.Context, id uuid.UUID, newStatus model.TaskStatus) error
 	UpdatePosition(ctx context.Context, id uuid.UUID, newPosition int) error
+	AppendOutput(ctx context.Context, id uuid.UUID, sessionID uuid.UUID, chunk string) error
 	SoftDelete(ctx context.Context, id uuid.UUID) error
 }
 
@@ -103,6 +104,28 @@ func (r *taskRepository) UpdatePosition(ctx context.Context, id uuid.UUID, newPo
 	return nil
 }
 
+// AppendOutput appends streamed output of the task's current session.
+// Output from a newer session replaces what the previous session left behind.
+func (r *taskRepository) AppendOutput(ctx context.Context, id uuid.UUID, sessionID uuid.UUID, chunk string) error {
+	return appendTaskOutput(r.db.WithContext(ctx), id, sessionID, chunk)
+}
+
+// appendTaskOutput appends output of sessionID to the task with db, which may be a transaction
+func appendTaskOutput(db *gorm.DB, id uuid.UUID, sessionID uuid.UUID, chunk string) error {
+	updates := map[string]interface{}{
+		"opencode_output":    gorm.Expr("CASE WHEN current_session_id = ? THEN COALESCE(opencode_output, '') || ? ELSE ? END", sessionID, chunk, chunk),
+		"current_session_id": sessionID,
+	}
+
+	if err := db.Model(&model.Task{}).
+		Where("id = ?", id).
+		Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to append task output: %w", err)
+	}
+
+	return nil
+}
+
 func (r *taskRepository) SoftDelete(ctx context.Context, id uuid.UUID) error {
 	if err := r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.Task{}).Error; err != nil {
 		return fmt.Errorf("failed to soft delete task: %w", err)
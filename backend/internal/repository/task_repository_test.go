@@ -78,6 +78,9 @@ func setupTaskTestDB(t *testing.T) *gorm.DB {
 			opencode_output TEXT,
 			execution_duration_ms INTEGER,
 			file_references TEXT,
+			branch_name TEXT,
+			commit_sha TEXT,
+			ai_review_verdict TEXT,
 			created_by TEXT NOT NULL,
 			created_at DATETIME,
 			updated_at DATETIME,
@@ -546,6 +549,43 @@ func TestTaskRepository_UpdatePosition(t *testing.T) {
 	})
 }
 
+func TestTaskRepository_AppendOutput(t *testing.T) {
+	db := setupTaskTestDB(t)
+	repo := NewTaskRepository(db)
+	ctx := context.Background()
+
+	userID := createTestUserForTask(t, db)
+	projectID := createTestProject(t, db, userID)
+
+	testTask := &model.Task{
+		ProjectID:      projectID,
+		Title:          "Test Task",
+		Status:         model.TaskStatusInProgress,
+		OpenCodeOutput: "Output of an earlier run",
+		CreatedBy:      userID,
+	}
+	require.NoError(t, repo.Create(ctx, testTask))
+
+	firstSession := uuid.New()
+	require.NoError(t, repo.AppendOutput(ctx, testTask.ID, firstSession, "Line 1\n"))
+	require.NoError(t, repo.AppendOutput(ctx, testTask.ID, firstSession, "Line 2\n"))
+
+	task, err := repo.FindByID(ctx, testTask.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Line 1\nLine 2\n", task.OpenCodeOutput)
+	require.NotNil(t, task.CurrentSessionID)
+	assert.Equal(t, firstSession, *task.CurrentSessionID)
+
+	// A new session starts the task output over
+	secondSession := uuid.New()
+	require.NoError(t, repo.AppendOutput(ctx, testTask.ID, secondSession, "Retry\n"))
+
+	task, err = repo.FindByID(ctx, testTask.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Retry\n", task.OpenCodeOutput)
+	assert.Equal(t, secondSession, *task.CurrentSessionID)
+}
+
 func TestTaskRepository_SoftDelete(t *testing.T) {
 	db := setupTaskTestDB(t)
 	repo := NewTaskRepository(db)
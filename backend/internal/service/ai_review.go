@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/npinot/vibe/backend/internal/model"
+)
+
+// defaultAIReviewSystemPrompt instructs the reviewer agent; projects can override it in their config
+const defaultAIReviewSystemPrompt = `You are a meticulous code reviewer. Review the changes another agent made for the task below.
+Check that they fully implement the task, are correct, handle errors and edge cases, and include tests where appropriate.
+Do not modify any files: only read the workspace and report your findings.
+
+End your answer with a single JSON object, with nothing after it, of the form:
+{"verdict": "pass" or "fail", "summary": "<one paragraph>", "findings": [{"severity": "error", "warning" or "info", "file_path": "<path>", "line": <line number>, "message": "<what is wrong and how to fix it>"}]}
+Use "fail" only when at least one finding has severity "error".`
+
+const (
+	// maxReviewDiffSize caps the diff embedded in the review prompt; the sidecar rejects prompts above 50000 characters
+	maxReviewDiffSize = 30000
+
+	// maxVerdictScanSize bounds how much of the reviewer output is searched for the verdict object
+	maxVerdictScanSize = 64 * 1024
+)
+
+// startAIReview moves a task whose execution session completed to ai_review and starts a reviewer session.
+// Tasks that were moved by hand while the session ran, or projects with the review stage disabled, are left alone.
+func (s *sessionService) startAIReview(ctx context.Context, session *model.Session) error {
+	task, err := s.taskRepo.FindByID(ctx, session.TaskID)
+	if err != nil {
+		return fmt.Errorf("failed to get task: %w", err)
+	}
+	if task.Status != model.TaskStatusInProgress {
+		return nil
+	}
+
+	config, err := s.configService.GetActiveConfig(ctx, session.ProjectID)
+	if err != nil {
+		return fmt.Errorf("failed to get project config: %w", err)
+	}
+	if !config.AIReviewEnabled {
+		return nil
+	}
+
+	project, err := s.projectRepo.FindByID(ctx, session.ProjectID)
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+
+	task.Status = model.TaskStatusAIReview
+	if err := s.taskRepo.UpdateStatus(ctx, task.ID, task.Status); err != nil {
+		return fmt.Errorf("failed to update task status: %w", err)
+	}
+
+	// The reviewer can still inspect the workspace itself when no diff was recorded
+	diff, _ := s.GetSessionDiff(ctx, session.ID)
+
+	systemPrompt := defaultAIReviewSystemPrompt
+	if config.ReviewSystemPrompt != nil && *config.ReviewSystemPrompt != "" {
+		systemPrompt = *config.ReviewSystemPrompt
+	}
+
+	// Reviewer sessions spend from the same budget as the sessions they review
+	if s.budgetService != nil {
+		err = s.budgetService.CheckBudget(ctx, project.ID)
+	}
+	if err == nil {
+		_, err = s.launchSession(ctx, task, project, buildAIReviewPrompt(task, session, diff), sessionOptions{
+			kind:         model.SessionKindReview,
+			systemPrompt: &systemPrompt,
+			modelName:    config.ReviewModelName,
+			readOnly:     true,
+		})
+	}
+	if err != nil {
+		// Without a reviewer the decision falls back to a human
+		return s.recordAIReviewVerdict(ctx, task, &model.AIReviewVerdict{
+			Error:             fmt.Sprintf("AI review could not be started: %v", err),
+			ReviewedSessionID: session.ID,
+		})
+	}
+
+	return nil
+}
+
+// applyAIReviewVerdict stores the verdict of a finished reviewer session on its task and moves the task on
+func (s *sessionService) applyAIReviewVerdict(ctx context.Context, reviewSession *model.Session) error {
+	task, err := s.taskRepo.FindByID(ctx, reviewSession.TaskID)
+	if err != nil {
+		return fmt.Errorf("failed to get task: %w", err)
+	}
+	if task.Status != model.TaskStatusAIReview {
+		return nil
+	}
+
+	var verdict *model.AIReviewVerdict
+	if reviewSession.Status == model.SessionStatusCompleted {
+		// The final response carries the verdict; the transcript is the fallback when it was not reported
+		output := reviewSession.FinalOutput
+		if output == "" {
+			output = reviewSession.Output
+		}
+		verdict, err = parseAIReviewVerdict(output)
+		if err != nil {
+			verdict = &model.AIReviewVerdict{Error: fmt.Sprintf("reviewer did not return a usable verdict: %v", err)}
+		}
+	} else {
+		verdict = &model.AIReviewVerdict{Error: fmt.Sprintf("review session %s", reviewSession.Status)}
+	}
+	verdict.SessionID = reviewSession.ID
+
+	sessions, err := s.sessionRepo.FindByTaskID(ctx, task.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get task sessions: %w", err)
+	}
+	for _, session := range sessions {
+		if session.Kind != model.SessionKindReview {
+			verdict.ReviewedSessionID = session.ID
+			break
+		}
+	}
+
+	return s.recordAIReviewVerdict(ctx, task, verdict)
+}
+
+// recordAIReviewVerdict stores the verdict on the task. A pass moves the task to human_review and
+// a fail sends it back to in_progress; when there is no usable verdict a human decides.
+func (s *sessionService) recordAIReviewVerdict(ctx context.Context, task *model.Task, verdict *model.AIReviewVerdict) error {
+	verdict.ReviewedAt = time.Now()
+	if verdict.Findings == nil {
+		verdict.Findings = []model.AIReviewFinding{}
+	}
+
+	task.AIReviewVerdict = verdict
+	task.Status = model.TaskStatusHumanReview
+	if !verdict.Passed && verdict.Error == "" {
+		task.Status = model.TaskStatusInProgress
+	}
+
+	if err := s.taskRepo.Update(ctx, task); err != nil {
+		return fmt.Errorf("failed to record AI review verdict: %w", err)
+	}
+
+	return nil
+}
+
+// parseAIReviewVerdict extracts the last verdict object from the reviewer output.
+// The object may be surrounded by prose or a markdown code fence.
+func parseAIReviewVerdict(output string) (*model.AIReviewVerdict, error) {
+	if len(output) > maxVerdictScanSize {
+		output = output[len(output)-maxVerdictScanSize:]
+	}
+
+	// Walk candidate objects from the end; nested finding objects have no verdict and are skipped
+	for i := strings.LastIndex(output, "{"); i >= 0; i = strings.LastIndex(output[:i], "{") {
+		var raw struct {
+			Verdict  string                  `json:"verdict"`
+			Summary  string                  `json:"summary"`
+			Findings []model.AIReviewFinding `json:"findings"`
+		}
+		if err := json.NewDecoder(strings.NewReader(output[i:])).Decode(&raw); err != nil || raw.Verdict == "" {
+			continue
+		}
+
+		verdict := &model.AIReviewVerdict{
+			Summary:  strings.TrimSpace(raw.Summary),
+			Findings: []model.AIReviewFinding{},
+		}
+		switch strings.ToLower(strings.TrimSpace(raw.Verdict)) {
+		case "pass":
+			verdict.Passed = true
+		case "fail":
+		default:
+			return nil, fmt.Errorf("unknown verdict %q", raw.Verdict)
+		}
+
+		for _, finding := range raw.Findings {
+			finding.Message = strings.TrimSpace(finding.Message)
+			if finding.Message == "" {
+				continue
+			}
+			finding.Severity = strings.ToLower(strings.TrimSpace(finding.Severity))
+			switch finding.Severity {
+			case "error", "warning", "info":
+			default:
+				finding.Severity = "warning"
+			}
+			if finding.Line < 0 {
+				finding.Line = 0
+			}
+			verdict.Findings = append(verdict.Findings, finding)
+		}
+
+		return verdict, nil
+	}
+
+	return nil, errors.New("no verdict object found in reviewer output")
+}
+
+// buildAIReviewPrompt describes the task and the changes of the execution session to the reviewer
+func buildAIReviewPrompt(task *model.Task, session *model.Session, diff *WorkspaceDiff) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Review the changes made for the following task.\n\nTask: %s\n\nDescription:\n%s", task.Title, task.Description)
+
+	if session.BranchName != "" {
+		fmt.Fprintf(&b, "\n\nBranch: %s", session.BranchName)
+	}
+	if session.CommitSHA != "" {
+		fmt.Fprintf(&b, "\nCommit: %s", session.CommitSHA)
+	}
+
+	if diff == nil {
+		b.WriteString("\n\nNo diff was recorded for this session; inspect the workspace to find the changes.")
+		return b.String()
+	}
+
+	if diff.FilesChanged == 0 {
+		b.WriteString("\n\nThe session did not change any files.")
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "\n\nChanged files (%d files, +%d -%d):", diff.FilesChanged, diff.Additions, diff.Deletions)
+	for _, file := range diff.Files {
+		fmt.Fprintf(&b, "\n- %s (%s, +%d -%d)", file.Path, file.Status, file.Additions, file.Deletions)
+	}
+
+	var patches strings.Builder
+	omitted := 0
+	for _, file := range diff.Files {
+		if file.Patch == "" || patches.Len()+len(file.Patch) > maxReviewDiffSize {
+			omitted++
+			continue
+		}
+		patches.WriteString(file.Patch)
+	}
+	if patches.Len() > 0 {
+		fmt.Fprintf(&b, "\n\nDiff:\n%s", patches.String())
+	}
+	if omitted > 0 {
+		fmt.Fprintf(&b, "\n\nThe diff of %d file(s) is not included above; read them in the workspace.", omitted)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,311 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/npinot/vibe/backend/internal/model"
+)
+
+func TestParseAIReviewVerdict(t *testing.T) {
+	t.Run("verdict in code fence after prose", func(t *testing.T) {
+		output := "I reviewed the changes.\n\n```json\n" +
+			`{"verdict": "FAIL", "summary": " Missing checks ", "findings": [` +
+			`{"severity": "error", "file_path": "auth.go", "line": 12, "message": "Signature not verified"},` +
+			`{"severity": "nitpick", "message": "Rename variable"},` +
+			`{"severity": "info", "message": "  "}]}` +
+			"\n```\n"
+
+		verdict, err := parseAIReviewVerdict(output)
+
+		require.NoError(t, err)
+		assert.False(t, verdict.Passed)
+		assert.Equal(t, "Missing checks", verdict.Summary)
+		require.Len(t, verdict.Findings, 2)
+		assert.Equal(t, model.AIReviewFinding{Severity: "error", FilePath: "auth.go", Line: 12, Message: "Signature not verified"}, verdict.Findings[0])
+		assert.Equal(t, "warning", verdict.Findings[1].Severity)
+	})
+
+	t.Run("last verdict wins", func(t *testing.T) {
+		output := `Draft: {"verdict": "fail"} Final: {"verdict": "pass", "findings": []}`
+
+		verdict, err := parseAIReviewVerdict(output)
+
+		require.NoError(t, err)
+		assert.True(t, verdict.Passed)
+		assert.NotNil(t, verdict.Findings)
+	})
+
+	t.Run("no verdict", func(t *testing.T) {
+		_, err := parseAIReviewVerdict(`Looks good to me! {"note": "no verdict here"}`)
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown verdict", func(t *testing.T) {
+		_, err := parseAIReviewVerdict(`{"verdict": "maybe"}`)
+		assert.Error(t, err)
+	})
+}
+
+func TestBuildAIReviewPrompt(t *testing.T) {
+	task := &model.Task{Title: "Add login", Description: "Use OIDC"}
+	session := &model.Session{BranchName: "task/1234abcd-add-login", CommitSHA: "abc123"}
+
+	t.Run("includes file summary and patches", func(t *testing.T) {
+		diff := &WorkspaceDiff{
+			FilesChanged: 2,
+			Additions:    3,
+			Deletions:    1,
+			Files: []WorkspaceFileDiff{
+				{Path: "auth.go", Status: "added", Additions: 3, Patch: "diff --git a/auth.go b/auth.go\n+package auth\n"},
+				{Path: "logo.png", Status: "modified", Binary: true},
+			},
+		}
+
+		prompt := buildAIReviewPrompt(task, session, diff)
+
+		assert.Contains(t, prompt, "Task: Add login\n\nDescription:\nUse OIDC")
+		assert.Contains(t, prompt, "Branch: task/1234abcd-add-login\nCommit: abc123")
+		assert.Contains(t, prompt, "Changed files (2 files, +3 -1):\n- auth.go (added, +3 -0)\n- logo.png (modified, +0 -0)")
+		assert.Contains(t, prompt, "Diff:\ndiff --git a/auth.go b/auth.go\n+package auth\n")
+		assert.Contains(t, prompt, "The diff of 1 file(s) is not included above")
+	})
+
+	t.Run("caps embedded diff size", func(t *testing.T) {
+		diff := &WorkspaceDiff{
+			FilesChanged: 1,
+			Files:        []WorkspaceFileDiff{{Path: "big.txt", Status: "added", Patch: strings.Repeat("+x\n", maxReviewDiffSize)}},
+		}
+
+		prompt := buildAIReviewPrompt(task, session, diff)
+
+		assert.Less(t, len(prompt), maxReviewDiffSize)
+		assert.NotContains(t, prompt, "Diff:")
+	})
+
+	t.Run("without diff", func(t *testing.T) {
+		prompt := buildAIReviewPrompt(task, &model.Session{}, nil)
+
+		assert.Contains(t, prompt, "No diff was recorded for this session")
+		assert.NotContains(t, prompt, "Branch:")
+	})
+}
+
+func TestSessionService_StartAIReview(t *testing.T) {
+	ctx := context.Background()
+	taskID := uuid.New()
+	projectID := uuid.New()
+
+	setup := func(taskStatus model.TaskStatus, config *model.OpenCodeConfig) (*sessionService, *MockSessionRepository, *MockTaskRepository, *MockKubernetesService, *model.Session, *model.Task) {
+		sessionRepo := new(MockSessionRepository)
+		taskRepo := new(MockTaskRepository)
+		projectRepo := new(MockProjectRepository)
+		k8sService := new(MockKubernetesService)
+		configService := new(MockConfigService)
+
+		session := &model.Session{ID: uuid.New(), TaskID: taskID, ProjectID: projectID, Status: model.SessionStatusRunning, Kind: model.SessionKindExecution}
+		task := &model.Task{ID: taskID, ProjectID: projectID, Title: "Add login", Status: taskStatus}
+		project := &model.Project{ID: projectID, PodName: "pod", PodNamespace: "ns"}
+
+		sessionRepo.On("FindByID", ctx, session.ID).Return(session, nil)
+		sessionRepo.On("Update", ctx, session).Return(nil)
+		taskRepo.On("FindByID", ctx, taskID).Return(task, nil)
+		projectRepo.On("FindByID", ctx, projectID).Return(project, nil)
+		configService.On("GetActiveConfig", ctx, projectID).Return(config, nil)
+
+		service := &sessionService{
+			sessionRepo:   sessionRepo,
+			taskRepo:      taskRepo,
+			projectRepo:   projectRepo,
+			k8sService:    k8sService,
+			configService: configService,
+			httpClient:    &http.Client{},
+		}
+
+		return service, sessionRepo, taskRepo, k8sService, session, task
+	}
+
+	t.Run("review stage disabled", func(t *testing.T) {
+		service, _, taskRepo, k8sService, session, task := setup(model.TaskStatusInProgress, &model.OpenCodeConfig{AIReviewEnabled: false})
+
+		err := service.UpdateSessionStatus(ctx, session.ID, "completed", "")
+
+		require.NoError(t, err)
+		assert.Equal(t, model.TaskStatusInProgress, task.Status)
+		taskRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+		k8sService.AssertNotCalled(t, "GetPodIP", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("task moved by hand while running", func(t *testing.T) {
+		service, _, taskRepo, _, session, _ := setup(model.TaskStatusTodo, &model.OpenCodeConfig{AIReviewEnabled: true})
+
+		err := service.UpdateSessionStatus(ctx, session.ID, "completed", "")
+
+		require.NoError(t, err)
+		taskRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("reviewer cannot be started", func(t *testing.T) {
+		service, _, taskRepo, k8sService, session, task := setup(model.TaskStatusInProgress, &model.OpenCodeConfig{AIReviewEnabled: true})
+
+		taskRepo.On("UpdateStatus", ctx, taskID, model.TaskStatusAIReview).Return(nil)
+		k8sService.On("GetPodIP", ctx, "pod", "ns").Return("", errors.New("pod not running"))
+		taskRepo.On("Update", ctx, task).Return(nil)
+
+		err := service.UpdateSessionStatus(ctx, session.ID, "completed", "")
+
+		require.NoError(t, err)
+		assert.Equal(t, model.TaskStatusHumanReview, task.Status)
+		require.NotNil(t, task.AIReviewVerdict)
+		assert.Contains(t, task.AIReviewVerdict.Error, "AI review could not be started")
+		assert.Equal(t, session.ID, task.AIReviewVerdict.ReviewedSessionID)
+	})
+
+	t.Run("budget used up", func(t *testing.T) {
+		service, sessionRepo, taskRepo, k8sService, session, task := setup(model.TaskStatusInProgress, &model.OpenCodeConfig{AIReviewEnabled: true})
+		budgetRepo := new(MockBudgetRepository)
+		service.budgetService = NewBudgetService(budgetRepo, sessionRepo, service.projectRepo, NewAuthorizationService(service.projectRepo, nil))
+
+		budgetRepo.On("FindByScope", ctx, model.BudgetScopeProject, projectID).
+			Return(&model.Budget{Scope: model.BudgetScopeProject, ScopeID: projectID, MonthlyCostUSD: floatPtr(10), WarnPercent: 80}, nil)
+		budgetRepo.On("FindByScope", ctx, model.BudgetScopeUser, mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+		sessionRepo.On("SumProjectUsageSince", ctx, projectID, mock.Anything).Return(&model.UsageTotals{CostUSD: 10.5}, nil)
+		sessionRepo.On("SumUserUsageSince", ctx, mock.Anything, mock.Anything).Return(&model.UsageTotals{CostUSD: 10.5}, nil)
+		taskRepo.On("UpdateStatus", ctx, taskID, model.TaskStatusAIReview).Return(nil)
+		taskRepo.On("Update", ctx, task).Return(nil)
+
+		err := service.UpdateSessionStatus(ctx, session.ID, "completed", "")
+
+		require.NoError(t, err)
+		k8sService.AssertNotCalled(t, "GetPodIP", mock.Anything, mock.Anything, mock.Anything)
+		assert.Equal(t, model.TaskStatusHumanReview, task.Status)
+		require.NotNil(t, task.AIReviewVerdict)
+		assert.Contains(t, task.AIReviewVerdict.Error, "budget exceeded")
+	})
+
+	t.Run("repeated completion does not start another review", func(t *testing.T) {
+		service, _, taskRepo, _, session, _ := setup(model.TaskStatusInProgress, &model.OpenCodeConfig{AIReviewEnabled: true})
+		session.Status = model.SessionStatusCompleted
+
+		err := service.UpdateSessionStatus(ctx, session.ID, "completed", "")
+
+		require.NoError(t, err)
+		taskRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestSessionService_ApplyAIReviewVerdict(t *testing.T) {
+	ctx := context.Background()
+	taskID := uuid.New()
+	projectID := uuid.New()
+	executionSessionID := uuid.New()
+
+	setup := func(output string) (*sessionService, *MockTaskRepository, *model.Session, *model.Task) {
+		sessionRepo := new(MockSessionRepository)
+		taskRepo := new(MockTaskRepository)
+
+		reviewSession := &model.Session{
+			ID:          uuid.New(),
+			TaskID:      taskID,
+			ProjectID:   projectID,
+			Status:      model.SessionStatusRunning,
+			Kind:        model.SessionKindReview,
+			FinalOutput: output,
+		}
+		task := &model.Task{ID: taskID, ProjectID: projectID, Status: model.TaskStatusAIReview}
+
+		sessionRepo.On("FindByID", ctx, reviewSession.ID).Return(reviewSession, nil)
+		sessionRepo.On("Update", ctx, reviewSession).Return(nil)
+		sessionRepo.On("FindByTaskID", ctx, taskID).Return([]model.Session{
+			*reviewSession,
+			{ID: executionSessionID, Kind: model.SessionKindExecution},
+		}, nil)
+		taskRepo.On("FindByID", ctx, taskID).Return(task, nil)
+		taskRepo.On("Update", ctx, task).Return(nil)
+
+		service := &sessionService{
+			sessionRepo: sessionRepo,
+			taskRepo:    taskRepo,
+			httpClient:  &http.Client{},
+		}
+
+		return service, taskRepo, reviewSession, task
+	}
+
+	t.Run("pass moves task to human review", func(t *testing.T) {
+		service, _, reviewSession, task := setup(`{"verdict": "pass", "summary": "All good", "findings": []}`)
+
+		err := service.UpdateSessionStatus(ctx, reviewSession.ID, "completed", "")
+
+		require.NoError(t, err)
+		assert.Equal(t, model.TaskStatusHumanReview, task.Status)
+		assert.True(t, task.AIReviewVerdict.Passed)
+		assert.Equal(t, reviewSession.ID, task.AIReviewVerdict.SessionID)
+		assert.Equal(t, executionSessionID, task.AIReviewVerdict.ReviewedSessionID)
+	})
+
+	t.Run("fail sends task back with findings", func(t *testing.T) {
+		service, _, reviewSession, task := setup(`{"verdict": "fail", "findings": [{"severity": "error", "message": "Tests are failing"}]}`)
+
+		err := service.UpdateSessionStatus(ctx, reviewSession.ID, "completed", "")
+
+		require.NoError(t, err)
+		assert.Equal(t, model.TaskStatusInProgress, task.Status)
+		assert.False(t, task.AIReviewVerdict.Passed)
+		require.Len(t, task.AIReviewVerdict.Findings, 1)
+		assert.Equal(t, "Tests are failing", task.AIReviewVerdict.Findings[0].Message)
+	})
+
+	t.Run("verdict read from transcript without final output", func(t *testing.T) {
+		service, _, reviewSession, task := setup("")
+		reviewSession.Output = "Reviewing...\n" + `{"verdict": "pass", "summary": "Fine"}` + "\n"
+
+		err := service.UpdateSessionStatus(ctx, reviewSession.ID, "completed", "")
+
+		require.NoError(t, err)
+		assert.Equal(t, model.TaskStatusHumanReview, task.Status)
+		assert.True(t, task.AIReviewVerdict.Passed)
+		assert.Empty(t, task.AIReviewVerdict.Error)
+	})
+
+	t.Run("unusable output leaves the decision to a human", func(t *testing.T) {
+		service, _, reviewSession, task := setup("I could not finish the review.")
+
+		err := service.UpdateSessionStatus(ctx, reviewSession.ID, "completed", "")
+
+		require.NoError(t, err)
+		assert.Equal(t, model.TaskStatusHumanReview, task.Status)
+		assert.Contains(t, task.AIReviewVerdict.Error, "did not return a usable verdict")
+	})
+
+	t.Run("failed review session", func(t *testing.T) {
+		service, _, reviewSession, task := setup("")
+
+		err := service.UpdateSessionStatus(ctx, reviewSession.ID, "failed", "model unavailable")
+
+		require.NoError(t, err)
+		assert.Equal(t, model.TaskStatusHumanReview, task.Status)
+		assert.Equal(t, "review session failed", task.AIReviewVerdict.Error)
+	})
+
+	t.Run("review sessions are never committed", func(t *testing.T) {
+		service, _, reviewSession, _ := setup(`{"verdict": "pass"}`)
+		gitService := new(MockWorkspaceGitService)
+		service.gitService = gitService
+		reviewSession.BranchName = "task/1234abcd-add-login"
+
+		err := service.UpdateSessionStatus(ctx, reviewSession.ID, "completed", "")
+
+		require.NoError(t, err)
+		gitService.AssertNotCalled(t, "CommitAll", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
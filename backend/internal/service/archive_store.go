@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrArchiveNotFound = errors.New("archive not found")
+
+// ArchiveStore keeps the workspace tarballs of archived projects, keyed by slash-separated paths
+type ArchiveStore interface {
+	// Put stores the content of r under key, replacing any previous content, and returns its size
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+
+	// Get opens the content stored under key; the caller closes it
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete removes the content stored under key; deleting a missing key is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+type filesystemArchiveStore struct {
+	dir string
+}
+
+// NewFilesystemArchiveStore creates an archive store keeping archives as files under dir, a
+// stand-in for an object store that works with a volume shared by the API replicas
+func NewFilesystemArchiveStore(dir string) (ArchiveStore, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	return &filesystemArchiveStore{dir: dir}, nil
+}
+
+// Put writes to a temporary file renamed once complete, so a failed upload never replaces an archive
+func (s *filesystemArchiveStore) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return 0, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	size, err := io.Copy(tmp, contextReader{ctx: ctx, r: r})
+	if err != nil {
+		tmp.Close()
+		return 0, fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, fmt.Errorf("failed to store archive: %w", err)
+	}
+
+	return size, nil
+}
+
+func (s *filesystemArchiveStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrArchiveNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+
+	return f, nil
+}
+
+func (s *filesystemArchiveStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete archive: %w", err)
+	}
+
+	return nil
+}
+
+// path maps a key to a file under the store directory, rejecting keys escaping it
+func (s *filesystemArchiveStore) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(clean) || clean == "." || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid archive key %q", key)
+	}
+
+	return filepath.Join(s.dir, clean), nil
+}
+
+// contextReader stops a copy once the context is done
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilesystemArchiveStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := NewFilesystemArchiveStore(dir)
+	require.NoError(t, err)
+
+	t.Run("puts and gets an archive", func(t *testing.T) {
+		size, err := store.Put(ctx, "projects/1234/workspace.tar.gz", strings.NewReader("tarball"))
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), size)
+
+		r, err := store.Get(ctx, "projects/1234/workspace.tar.gz")
+		require.NoError(t, err)
+		defer r.Close()
+		content, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, "tarball", string(content))
+	})
+
+	t.Run("deletes an archive", func(t *testing.T) {
+		_, err := store.Put(ctx, "projects/5678/workspace.tar.gz", strings.NewReader("tarball"))
+		require.NoError(t, err)
+
+		require.NoError(t, store.Delete(ctx, "projects/5678/workspace.tar.gz"))
+		_, err = store.Get(ctx, "projects/5678/workspace.tar.gz")
+		assert.ErrorIs(t, err, ErrArchiveNotFound)
+
+		// Deleting twice is fine
+		assert.NoError(t, store.Delete(ctx, "projects/5678/workspace.tar.gz"))
+	})
+
+	t.Run("missing archive", func(t *testing.T) {
+		_, err := store.Get(ctx, "projects/missing.tar.gz")
+		assert.ErrorIs(t, err, ErrArchiveNotFound)
+	})
+
+	t.Run("failed upload keeps the previous archive", func(t *testing.T) {
+		_, err := store.Put(ctx, "projects/9999/workspace.tar.gz", strings.NewReader("first"))
+		require.NoError(t, err)
+
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err = store.Put(cancelled, "projects/9999/workspace.tar.gz", strings.NewReader("second"))
+		assert.Error(t, err)
+
+		content, err := os.ReadFile(filepath.Join(dir, "projects", "9999", "workspace.tar.gz"))
+		require.NoError(t, err)
+		assert.Equal(t, "first", string(content))
+		entries, _ := os.ReadDir(filepath.Join(dir, "projects", "9999"))
+		assert.Len(t, entries, 1, "temporary upload should be removed")
+	})
+
+	t.Run("rejects keys escaping the store", func(t *testing.T) {
+		for _, key := range []string{"", "../escape.tar.gz", "/etc/passwd", "projects/../../escape"} {
+			_, err := store.Put(ctx, key, strings.NewReader("evil"))
+			assert.Error(t, err, key)
+		}
+		_, err := os.Stat(filepath.Join(filepath.Dir(dir), "escape.tar.gz"))
+		assert.True(t, os.IsNotExist(err))
+	})
+}
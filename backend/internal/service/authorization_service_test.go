@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/npinot/vibe/backend/internal/model"
+)
+
+type MockProjectMemberRepository struct {
+	mock.Mock
+}
+
+func (m *MockProjectMemberRepository) FindMember(ctx context.Context, projectID, userID uuid.UUID) (*model.ProjectMember, error) {
+	args := m.Called(ctx, projectID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ProjectMember), args.Error(1)
+}
+
+func (m *MockProjectMemberRepository) FindMembers(ctx context.Context, projectID uuid.UUID) ([]model.ProjectMember, error) {
+	args := m.Called(ctx, projectID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.ProjectMember), args.Error(1)
+}
+
+func (m *MockProjectMemberRepository) UpdateMember(ctx context.Context, member *model.ProjectMember) error {
+	args := m.Called(ctx, member)
+	return args.Error(0)
+}
+
+func (m *MockProjectMemberRepository) DeleteMember(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockProjectMemberRepository) CreateInvitation(ctx context.Context, invitation *model.ProjectInvitation) error {
+	args := m.Called(ctx, invitation)
+	return args.Error(0)
+}
+
+func (m *MockProjectMemberRepository) UpdateInvitation(ctx context.Context, invitation *model.ProjectInvitation) error {
+	args := m.Called(ctx, invitation)
+	return args.Error(0)
+}
+
+func (m *MockProjectMemberRepository) FindInvitationByID(ctx context.Context, id uuid.UUID) (*model.ProjectInvitation, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ProjectInvitation), args.Error(1)
+}
+
+func (m *MockProjectMemberRepository) FindInvitation(ctx context.Context, projectID uuid.UUID, email string) (*model.ProjectInvitation, error) {
+	args := m.Called(ctx, projectID, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ProjectInvitation), args.Error(1)
+}
+
+func (m *MockProjectMemberRepository) FindInvitations(ctx context.Context, projectID uuid.UUID) ([]model.ProjectInvitation, error) {
+	args := m.Called(ctx, projectID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.ProjectInvitation), args.Error(1)
+}
+
+func (m *MockProjectMemberRepository) FindInvitationsByEmail(ctx context.Context, email string) ([]model.ProjectInvitation, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.ProjectInvitation), args.Error(1)
+}
+
+func (m *MockProjectMemberRepository) DeleteInvitation(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockProjectMemberRepository) AcceptInvitation(ctx context.Context, invitation *model.ProjectInvitation, member *model.ProjectMember) error {
+	args := m.Called(ctx, invitation, member)
+	return args.Error(0)
+}
+
+// expectMember makes userID a member of the project with the given role
+func expectMember(memberRepo *MockProjectMemberRepository, project *model.Project, userID uuid.UUID, role model.ProjectRole) {
+	memberRepo.On("FindMember", mock.Anything, project.ID, userID).
+		Return(&model.ProjectMember{ID: uuid.New(), ProjectID: project.ID, UserID: userID, Role: role}, nil)
+}
+
+func TestAuthorizationService_AuthorizeProject(t *testing.T) {
+	ownerID := uuid.New()
+	maintainerID := uuid.New()
+	viewerID := uuid.New()
+	strangerID := uuid.New()
+
+	tests := []struct {
+		name    string
+		userID  uuid.UUID
+		role    model.ProjectRole
+		wantErr error
+	}{
+		{name: "owner as owner", userID: ownerID, role: model.ProjectRoleOwner},
+		{name: "owner as viewer", userID: ownerID, role: model.ProjectRoleViewer},
+		{name: "maintainer as maintainer", userID: maintainerID, role: model.ProjectRoleMaintainer},
+		{name: "maintainer as viewer", userID: maintainerID, role: model.ProjectRoleViewer},
+		{name: "maintainer as owner", userID: maintainerID, role: model.ProjectRoleOwner, wantErr: ErrUnauthorized},
+		{name: "viewer as viewer", userID: viewerID, role: model.ProjectRoleViewer},
+		{name: "viewer as maintainer", userID: viewerID, role: model.ProjectRoleMaintainer, wantErr: ErrUnauthorized},
+		{name: "stranger as viewer", userID: strangerID, role: model.ProjectRoleViewer, wantErr: ErrUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			projectRepo := new(MockProjectRepository)
+			memberRepo := new(MockProjectMemberRepository)
+			project := &model.Project{ID: uuid.New(), UserID: ownerID}
+
+			projectRepo.On("FindByID", ctx, project.ID).Return(project, nil)
+			expectMember(memberRepo, project, maintainerID, model.ProjectRoleMaintainer)
+			expectMember(memberRepo, project, viewerID, model.ProjectRoleViewer)
+			memberRepo.On("FindMember", ctx, project.ID, strangerID).Return(nil, gorm.ErrRecordNotFound)
+
+			authz := NewAuthorizationService(projectRepo, memberRepo)
+			got, err := authz.AuthorizeProject(ctx, project.ID, tt.userID, tt.role)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, got)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, project, got)
+		})
+	}
+}
+
+func TestAuthorizationService_AuthorizeProjectErrors(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	t.Run("project not found", func(t *testing.T) {
+		projectRepo := new(MockProjectRepository)
+		projectID := uuid.New()
+		projectRepo.On("FindByID", ctx, projectID).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := NewAuthorizationService(projectRepo, nil).AuthorizeProject(ctx, projectID, userID, model.ProjectRoleViewer)
+
+		assert.ErrorIs(t, err, ErrProjectNotFound)
+	})
+
+	t.Run("membership lookup fails", func(t *testing.T) {
+		projectRepo := new(MockProjectRepository)
+		memberRepo := new(MockProjectMemberRepository)
+		project := &model.Project{ID: uuid.New(), UserID: uuid.New()}
+		projectRepo.On("FindByID", ctx, project.ID).Return(project, nil)
+		memberRepo.On("FindMember", ctx, project.ID, userID).Return(nil, errors.New("connection reset"))
+
+		_, err := NewAuthorizationService(projectRepo, memberRepo).AuthorizeProject(ctx, project.ID, userID, model.ProjectRoleViewer)
+
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrUnauthorized)
+		assert.Contains(t, err.Error(), "failed to retrieve project membership")
+	})
+
+	t.Run("without members only the owner has access", func(t *testing.T) {
+		projectRepo := new(MockProjectRepository)
+		project := &model.Project{ID: uuid.New(), UserID: uuid.New()}
+		projectRepo.On("FindByID", ctx, project.ID).Return(project, nil)
+
+		authz := NewAuthorizationService(projectRepo, nil)
+
+		_, err := authz.AuthorizeProject(ctx, project.ID, userID, model.ProjectRoleViewer)
+		assert.ErrorIs(t, err, ErrUnauthorized)
+
+		role, err := authz.ProjectRole(ctx, project, project.UserID)
+		require.NoError(t, err)
+		assert.Equal(t, model.ProjectRoleOwner, role)
+	})
+}
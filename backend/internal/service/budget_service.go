@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/npinot/vibe/backend/internal/model"
+	"github.com/npinot/vibe/backend/internal/repository"
+)
+
+var (
+	ErrBudgetExceeded = errors.New("budget exceeded")
+	ErrInvalidBudget  = errors.New("invalid budget")
+)
+
+// defaultBudgetWarnPercent is the share of a budget at which a warning is posted unless set otherwise
+const defaultBudgetWarnPercent = 80
+
+// BudgetLimits are the monthly limits of a budget; nil limits are not enforced
+type BudgetLimits struct {
+	MonthlyCostUSD *float64
+	MonthlyTokens  *int64
+	WarnPercent    int // zero picks the default
+}
+
+// BudgetStatus is a budget with what its project or user spent in the current month
+type BudgetStatus struct {
+	Budget *model.Budget      `json:"budget"` // nil without a budget
+	Period string             `json:"period"` // YYYY-MM
+	Spent  *model.UsageTotals `json:"spent"`
+}
+
+// Exceeded reports whether a limit of the budget is used up
+func (b *BudgetStatus) Exceeded() bool {
+	return b.usedShare() >= 1
+}
+
+// WarningReached reports whether the spending reached the warning threshold of the budget
+func (b *BudgetStatus) WarningReached() bool {
+	return b.Budget != nil && b.usedShare()*100 >= float64(b.Budget.WarnPercent)
+}
+
+// usedShare is the largest share of a limit the spending used
+func (b *BudgetStatus) usedShare() float64 {
+	if b.Budget == nil {
+		return 0
+	}
+
+	share := 0.0
+	if limit := b.Budget.MonthlyCostUSD; limit != nil {
+		share = max(share, usedShare(b.Spent.CostUSD, *limit))
+	}
+	if limit := b.Budget.MonthlyTokens; limit != nil {
+		share = max(share, usedShare(float64(b.Spent.InputTokens+b.Spent.OutputTokens), float64(*limit)))
+	}
+	return share
+}
+
+func usedShare(spent, limit float64) float64 {
+	if limit <= 0 {
+		return 1
+	}
+	return spent / limit
+}
+
+// Describe summarizes the budget and its spending, e.g. "the project's monthly budget of $50.00 ($42.10 spent)"
+func (b *BudgetStatus) Describe() string {
+	var limits, spent []string
+	if limit := b.Budget.MonthlyCostUSD; limit != nil {
+		limits = append(limits, fmt.Sprintf("$%.2f", *limit))
+		spent = append(spent, fmt.Sprintf("$%.2f spent", b.Spent.CostUSD))
+	}
+	if limit := b.Budget.MonthlyTokens; limit != nil {
+		limits = append(limits, fmt.Sprintf("%d tokens", *limit))
+		spent = append(spent, fmt.Sprintf("%d tokens used", b.Spent.InputTokens+b.Spent.OutputTokens))
+	}
+
+	return fmt.Sprintf("the %s's monthly budget of %s (%s)", b.Budget.Scope, strings.Join(limits, " and "), strings.Join(spent, ", "))
+}
+
+type BudgetService interface {
+	GetProjectBudget(ctx context.Context, projectID, userID uuid.UUID) (*BudgetStatus, error)
+	SetProjectBudget(ctx context.Context, projectID, userID uuid.UUID, limits BudgetLimits) (*BudgetStatus, error)
+	DeleteProjectBudget(ctx context.Context, projectID, userID uuid.UUID) error
+	GetUserBudget(ctx context.Context, userID uuid.UUID) (*BudgetStatus, error)
+	SetUserBudget(ctx context.Context, userID uuid.UUID, limits BudgetLimits) (*BudgetStatus, error)
+	DeleteUserBudget(ctx context.Context, userID uuid.UUID) error
+	CheckBudget(ctx context.Context, projectID uuid.UUID) error
+	EvaluateBudgets(ctx context.Context, projectID uuid.UUID) ([]BudgetStatus, error)
+	MarkWarned(ctx context.Context, status *BudgetStatus) (bool, error)
+}
+
+type budgetService struct {
+	budgetRepo  repository.BudgetRepository
+	sessionRepo repository.SessionRepository
+	projectRepo repository.ProjectRepository
+	authz       AuthorizationService
+	now         func() time.Time
+}
+
+func NewBudgetService(budgetRepo repository.BudgetRepository, sessionRepo repository.SessionRepository, projectRepo repository.ProjectRepository, authz AuthorizationService) BudgetService {
+	return &budgetService{
+		budgetRepo:  budgetRepo,
+		sessionRepo: sessionRepo,
+		projectRepo: projectRepo,
+		authz:       authz,
+		now:         time.Now,
+	}
+}
+
+// GetProjectBudget returns the project's budget and what the project spent this month
+func (s *budgetService) GetProjectBudget(ctx context.Context, projectID, userID uuid.UUID) (*BudgetStatus, error) {
+	if _, err := s.authz.AuthorizeProject(ctx, projectID, userID, model.ProjectRoleViewer); err != nil {
+		return nil, err
+	}
+
+	return s.budgetStatus(ctx, model.BudgetScopeProject, projectID)
+}
+
+// SetProjectBudget creates or replaces the project's budget
+func (s *budgetService) SetProjectBudget(ctx context.Context, projectID, userID uuid.UUID, limits BudgetLimits) (*BudgetStatus, error) {
+	if _, err := s.authz.AuthorizeProject(ctx, projectID, userID, model.ProjectRoleOwner); err != nil {
+		return nil, err
+	}
+
+	return s.setBudget(ctx, model.BudgetScopeProject, projectID, limits)
+}
+
+func (s *budgetService) DeleteProjectBudget(ctx context.Context, projectID, userID uuid.UUID) error {
+	if _, err := s.authz.AuthorizeProject(ctx, projectID, userID, model.ProjectRoleOwner); err != nil {
+		return err
+	}
+
+	return s.budgetRepo.Delete(ctx, model.BudgetScopeProject, projectID)
+}
+
+// GetUserBudget returns the user's budget and what the user's projects spent this month
+func (s *budgetService) GetUserBudget(ctx context.Context, userID uuid.UUID) (*BudgetStatus, error) {
+	return s.budgetStatus(ctx, model.BudgetScopeUser, userID)
+}
+
+// SetUserBudget creates or replaces the budget covering all projects of the user
+func (s *budgetService) SetUserBudget(ctx context.Context, userID uuid.UUID, limits BudgetLimits) (*BudgetStatus, error) {
+	return s.setBudget(ctx, model.BudgetScopeUser, userID, limits)
+}
+
+func (s *budgetService) DeleteUserBudget(ctx context.Context, userID uuid.UUID) error {
+	return s.budgetRepo.Delete(ctx, model.BudgetScopeUser, userID)
+}
+
+// CheckBudget returns ErrBudgetExceeded when the budget of the project or of its owner is used up
+func (s *budgetService) CheckBudget(ctx context.Context, projectID uuid.UUID) error {
+	statuses, err := s.EvaluateBudgets(ctx, projectID)
+	if err != nil {
+		return err
+	}
+
+	for i := range statuses {
+		if statuses[i].Exceeded() {
+			return fmt.Errorf("%w: %s is used up", ErrBudgetExceeded, statuses[i].Describe())
+		}
+	}
+
+	return nil
+}
+
+// EvaluateBudgets returns the budgets that apply to the project's sessions: the project's own and its owner's
+func (s *budgetService) EvaluateBudgets(ctx context.Context, projectID uuid.UUID) ([]BudgetStatus, error) {
+	project, err := s.projectRepo.FindByID(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	var statuses []BudgetStatus
+	for _, scope := range []struct {
+		scope model.BudgetScope
+		id    uuid.UUID
+	}{
+		{model.BudgetScopeProject, project.ID},
+		{model.BudgetScopeUser, project.UserID},
+	} {
+		status, err := s.budgetStatus(ctx, scope.scope, scope.id)
+		if err != nil {
+			return nil, err
+		}
+		if status.Budget != nil {
+			statuses = append(statuses, *status)
+		}
+	}
+
+	return statuses, nil
+}
+
+// MarkWarned records that the warning of the budget was posted for its period, reporting false when it already was
+func (s *budgetService) MarkWarned(ctx context.Context, status *BudgetStatus) (bool, error) {
+	return s.budgetRepo.MarkWarned(ctx, status.Budget.ID, status.Period)
+}
+
+func (s *budgetService) setBudget(ctx context.Context, scope model.BudgetScope, scopeID uuid.UUID, limits BudgetLimits) (*BudgetStatus, error) {
+	if err := validateBudgetLimits(&limits); err != nil {
+		return nil, err
+	}
+
+	budget := &model.Budget{
+		Scope:          scope,
+		ScopeID:        scopeID,
+		MonthlyCostUSD: limits.MonthlyCostUSD,
+		MonthlyTokens:  limits.MonthlyTokens,
+		WarnPercent:    limits.WarnPercent,
+	}
+	if err := s.budgetRepo.Upsert(ctx, budget); err != nil {
+		return nil, err
+	}
+
+	return s.budgetStatus(ctx, scope, scopeID)
+}
+
+// budgetStatus returns the budget of the scope, if any, with the spending of the current month
+func (s *budgetService) budgetStatus(ctx context.Context, scope model.BudgetScope, scopeID uuid.UUID) (*BudgetStatus, error) {
+	budget, err := s.budgetRepo.FindByScope(ctx, scope, scopeID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		budget, err = nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budget: %w", err)
+	}
+
+	now := s.now().UTC()
+	periodStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	var spent *model.UsageTotals
+	switch scope {
+	case model.BudgetScopeProject:
+		spent, err = s.sessionRepo.SumProjectUsageSince(ctx, scopeID, periodStart)
+	case model.BudgetScopeUser:
+		spent, err = s.sessionRepo.SumUserUsageSince(ctx, scopeID, periodStart)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &BudgetStatus{
+		Budget: budget,
+		Period: periodStart.Format("2006-01"),
+		Spent:  spent,
+	}, nil
+}
+
+func validateBudgetLimits(limits *BudgetLimits) error {
+	if limits.MonthlyCostUSD == nil && limits.MonthlyTokens == nil {
+		return fmt.Errorf("%w: set a monthly cost or token limit", ErrInvalidBudget)
+	}
+	if limits.MonthlyCostUSD != nil && *limits.MonthlyCostUSD < 0 {
+		return fmt.Errorf("%w: monthly cost must not be negative", ErrInvalidBudget)
+	}
+	if limits.MonthlyTokens != nil && *limits.MonthlyTokens < 0 {
+		return fmt.Errorf("%w: monthly tokens must not be negative", ErrInvalidBudget)
+	}
+
+	if limits.WarnPercent == 0 {
+		limits.WarnPercent = defaultBudgetWarnPercent
+	}
+	if limits.WarnPercent < 1 || limits.WarnPercent > 100 {
+		return fmt.Errorf("%w: warning threshold must be between 1 and 100 percent", ErrInvalidBudget)
+	}
+
+	return nil
+}
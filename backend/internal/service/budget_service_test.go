@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/npinot/vibe/backend/internal/model"
+)
+
+type MockBudgetRepository struct {
+	mock.Mock
+}
+
+func (m *MockBudgetRepository) FindByScope(ctx context.Context, scope model.BudgetScope, scopeID uuid.UUID) (*model.Budget, error) {
+	args := m.Called(ctx, scope, scopeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Budget), args.Error(1)
+}
+
+func (m *MockBudgetRepository) Upsert(ctx context.Context, budget *model.Budget) error {
+	args := m.Called(ctx, budget)
+	return args.Error(0)
+}
+
+func (m *MockBudgetRepository) Delete(ctx context.Context, scope model.BudgetScope, scopeID uuid.UUID) error {
+	args := m.Called(ctx, scope, scopeID)
+	return args.Error(0)
+}
+
+func (m *MockBudgetRepository) MarkWarned(ctx context.Context, id uuid.UUID, period string) (bool, error) {
+	args := m.Called(ctx, id, period)
+	return args.Bool(0), args.Error(1)
+}
+
+func floatPtr(v float64) *float64 { return &v }
+
+func int64Ptr(v int64) *int64 { return &v }
+
+func TestBudgetStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		budget   *model.Budget
+		spent    model.UsageTotals
+		exceeded bool
+		warning  bool
+		describe string
+	}{
+		{
+			name:  "no budget",
+			spent: model.UsageTotals{CostUSD: 1000},
+		},
+		{
+			name:     "below warning",
+			budget:   &model.Budget{Scope: model.BudgetScopeProject, MonthlyCostUSD: floatPtr(50), WarnPercent: 80},
+			spent:    model.UsageTotals{CostUSD: 10},
+			describe: "the project's monthly budget of $50.00 ($10.00 spent)",
+		},
+		{
+			name:     "warning reached",
+			budget:   &model.Budget{Scope: model.BudgetScopeProject, MonthlyCostUSD: floatPtr(50), WarnPercent: 80},
+			spent:    model.UsageTotals{CostUSD: 40},
+			warning:  true,
+			describe: "the project's monthly budget of $50.00 ($40.00 spent)",
+		},
+		{
+			name:     "token limit used up",
+			budget:   &model.Budget{Scope: model.BudgetScopeUser, MonthlyCostUSD: floatPtr(50), MonthlyTokens: int64Ptr(1000), WarnPercent: 80},
+			spent:    model.UsageTotals{InputTokens: 900, OutputTokens: 100, CostUSD: 1},
+			exceeded: true,
+			warning:  true,
+			describe: "the user's monthly budget of $50.00 and 1000 tokens ($1.00 spent, 1000 tokens used)",
+		},
+		{
+			name:     "zero budget blocks everything",
+			budget:   &model.Budget{Scope: model.BudgetScopeProject, MonthlyCostUSD: floatPtr(0), WarnPercent: 80},
+			exceeded: true,
+			warning:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := &BudgetStatus{Budget: tt.budget, Period: "2026-03", Spent: &tt.spent}
+
+			assert.Equal(t, tt.exceeded, status.Exceeded())
+			assert.Equal(t, tt.warning, status.WarningReached())
+			if tt.describe != "" {
+				assert.Equal(t, tt.describe, status.Describe())
+			}
+		})
+	}
+}
+
+func setupBudgetServiceTest() (*budgetService, *MockBudgetRepository, *MockSessionRepository, *MockProjectRepository) {
+	budgetRepo := new(MockBudgetRepository)
+	sessionRepo := new(MockSessionRepository)
+	projectRepo := new(MockProjectRepository)
+
+	service := NewBudgetService(budgetRepo, sessionRepo, projectRepo, NewAuthorizationService(projectRepo, nil)).(*budgetService)
+	service.now = func() time.Time { return time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC) }
+
+	return service, budgetRepo, sessionRepo, projectRepo
+}
+
+func TestBudgetService_CheckBudget(t *testing.T) {
+	ctx := context.Background()
+	monthStart := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	projectID := uuid.New()
+	ownerID := uuid.New()
+	project := &model.Project{ID: projectID, UserID: ownerID}
+
+	t.Run("no budgets", func(t *testing.T) {
+		service, budgetRepo, sessionRepo, projectRepo := setupBudgetServiceTest()
+		projectRepo.On("FindByID", ctx, projectID).Return(project, nil)
+		budgetRepo.On("FindByScope", ctx, mock.Anything, mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+		sessionRepo.On("SumProjectUsageSince", ctx, projectID, monthStart).Return(&model.UsageTotals{CostUSD: 500}, nil)
+		sessionRepo.On("SumUserUsageSince", ctx, ownerID, monthStart).Return(&model.UsageTotals{CostUSD: 500}, nil)
+
+		assert.NoError(t, service.CheckBudget(ctx, projectID))
+	})
+
+	t.Run("project budget used up", func(t *testing.T) {
+		service, budgetRepo, sessionRepo, projectRepo := setupBudgetServiceTest()
+		projectRepo.On("FindByID", ctx, projectID).Return(project, nil)
+		budgetRepo.On("FindByScope", ctx, model.BudgetScopeProject, projectID).
+			Return(&model.Budget{Scope: model.BudgetScopeProject, ScopeID: projectID, MonthlyCostUSD: floatPtr(50), WarnPercent: 80}, nil)
+		budgetRepo.On("FindByScope", ctx, model.BudgetScopeUser, ownerID).Return(nil, gorm.ErrRecordNotFound)
+		sessionRepo.On("SumProjectUsageSince", ctx, projectID, monthStart).Return(&model.UsageTotals{CostUSD: 50.5}, nil)
+		sessionRepo.On("SumUserUsageSince", ctx, ownerID, monthStart).Return(&model.UsageTotals{CostUSD: 50.5}, nil)
+
+		err := service.CheckBudget(ctx, projectID)
+
+		assert.ErrorIs(t, err, ErrBudgetExceeded)
+		assert.Contains(t, err.Error(), "the project's monthly budget of $50.00 ($50.50 spent) is used up")
+	})
+
+	t.Run("owner's budget used up by other projects", func(t *testing.T) {
+		service, budgetRepo, sessionRepo, projectRepo := setupBudgetServiceTest()
+		projectRepo.On("FindByID", ctx, projectID).Return(project, nil)
+		budgetRepo.On("FindByScope", ctx, model.BudgetScopeProject, projectID).Return(nil, gorm.ErrRecordNotFound)
+		budgetRepo.On("FindByScope", ctx, model.BudgetScopeUser, ownerID).
+			Return(&model.Budget{Scope: model.BudgetScopeUser, ScopeID: ownerID, MonthlyTokens: int64Ptr(1_000_000), WarnPercent: 80}, nil)
+		sessionRepo.On("SumProjectUsageSince", ctx, projectID, monthStart).Return(&model.UsageTotals{}, nil)
+		sessionRepo.On("SumUserUsageSince", ctx, ownerID, monthStart).Return(&model.UsageTotals{InputTokens: 900_000, OutputTokens: 100_000}, nil)
+
+		err := service.CheckBudget(ctx, projectID)
+
+		assert.ErrorIs(t, err, ErrBudgetExceeded)
+		assert.Contains(t, err.Error(), "the user's monthly budget")
+	})
+}
+
+func TestBudgetService_SetProjectBudget(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+	ownerID := uuid.New()
+
+	t.Run("saves budget with default warning threshold", func(t *testing.T) {
+		service, budgetRepo, sessionRepo, projectRepo := setupBudgetServiceTest()
+		projectRepo.On("FindByID", ctx, projectID).Return(&model.Project{ID: projectID, UserID: ownerID}, nil)
+		budgetRepo.On("Upsert", ctx, mock.MatchedBy(func(budget *model.Budget) bool {
+			return budget.Scope == model.BudgetScopeProject && budget.ScopeID == projectID &&
+				*budget.MonthlyCostUSD == 25 && budget.MonthlyTokens == nil && budget.WarnPercent == 80
+		})).Return(nil)
+		saved := &model.Budget{Scope: model.BudgetScopeProject, ScopeID: projectID, MonthlyCostUSD: floatPtr(25), WarnPercent: 80}
+		budgetRepo.On("FindByScope", ctx, model.BudgetScopeProject, projectID).Return(saved, nil)
+		sessionRepo.On("SumProjectUsageSince", ctx, projectID, mock.Anything).Return(&model.UsageTotals{CostUSD: 3}, nil)
+
+		status, err := service.SetProjectBudget(ctx, projectID, ownerID, BudgetLimits{MonthlyCostUSD: floatPtr(25)})
+
+		require.NoError(t, err)
+		assert.Equal(t, saved, status.Budget)
+		assert.Equal(t, "2026-03", status.Period)
+		assert.Equal(t, 3.0, status.Spent.CostUSD)
+	})
+
+	t.Run("other user's project", func(t *testing.T) {
+		service, _, _, projectRepo := setupBudgetServiceTest()
+		projectRepo.On("FindByID", ctx, projectID).Return(&model.Project{ID: projectID, UserID: ownerID}, nil)
+
+		_, err := service.SetProjectBudget(ctx, projectID, uuid.New(), BudgetLimits{MonthlyCostUSD: floatPtr(25)})
+
+		assert.ErrorIs(t, err, ErrUnauthorized)
+	})
+
+	invalid := []struct {
+		name   string
+		limits BudgetLimits
+	}{
+		{"no limit", BudgetLimits{WarnPercent: 50}},
+		{"negative cost", BudgetLimits{MonthlyCostUSD: floatPtr(-1)}},
+		{"negative tokens", BudgetLimits{MonthlyTokens: int64Ptr(-1)}},
+		{"warning above 100 percent", BudgetLimits{MonthlyCostUSD: floatPtr(10), WarnPercent: 120}},
+	}
+	for _, tc := range invalid {
+		t.Run(tc.name, func(t *testing.T) {
+			service, budgetRepo, _, projectRepo := setupBudgetServiceTest()
+			projectRepo.On("FindByID", ctx, projectID).Return(&model.Project{ID: projectID, UserID: ownerID}, nil)
+
+			_, err := service.SetProjectBudget(ctx, projectID, ownerID, tc.limits)
+
+			assert.ErrorIs(t, err, ErrInvalidBudget)
+			budgetRepo.AssertNotCalled(t, "Upsert", mock.Anything, mock.Anything)
+		})
+	}
+}
@@ -2,16 +2,14 @@ package service
 
 import (
 	"context"
-	"crypto/aes"
-	"crypto/cipher"
-	"crypto/rand"
 	"encoding/base64"
 	"errors"
 	"fmt"
-	"io"
 	"strings"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+
 	"github.com/npinot/vibe/backend/internal/model"
 	"github.com/npinot/vibe/backend/internal/repository"
 )
@@ -22,14 +20,18 @@ type ConfigServiceInterface interface {
 	CreateOrUpdateConfig(ctx context.Context, config *model.OpenCodeConfig, apiKey string) error
 	RollbackToVersion(ctx context.Context, projectID uuid.UUID, version int) error
 	GetConfigHistory(ctx context.Context, projectID uuid.UUID) ([]model.OpenCodeConfig, error)
+	CopyActiveConfig(ctx context.Context, sourceProjectID, targetProjectID uuid.UUID, withAPIKey bool) error
 }
 
 type ConfigService struct {
 	configRepo    repository.ConfigRepository
+	projectRepo   repository.ProjectRepository
 	encryptionKey []byte // 32-byte AES-256 key
 }
 
-func NewConfigService(configRepo repository.ConfigRepository, encryptionKey string) (*ConfigService, error) {
+// NewConfigService creates a config service. projectRepo may be nil, in which case
+// configs of archived projects are not protected from changes.
+func NewConfigService(configRepo repository.ConfigRepository, projectRepo repository.ProjectRepository, encryptionKey string) (*ConfigService, error) {
 	// Decode base64 encryption key
 	key, err := base64.StdEncoding.DecodeString(encryptionKey)
 	if err != nil || len(key) != 32 {
@@ -38,6 +40,7 @@ func NewConfigService(configRepo repository.ConfigRepository, encryptionKey stri
 
 	return &ConfigService{
 		configRepo:    configRepo,
+		projectRepo:   projectRepo,
 		encryptionKey: key,
 	}, nil
 }
@@ -57,6 +60,10 @@ func (s *ConfigService) GetActiveConfig(ctx context.Context, projectID uuid.UUID
 
 // CreateOrUpdateConfig creates a new configuration version
 func (s *ConfigService) CreateOrUpdateConfig(ctx context.Context, config *model.OpenCodeConfig, apiKey string) error {
+	if err := s.checkWritable(ctx, config.ProjectID); err != nil {
+		return err
+	}
+
 	// Validate configuration
 	if err := s.validateConfig(config); err != nil {
 		return fmt.Errorf("config validation failed: %w", err)
@@ -81,6 +88,10 @@ func (s *ConfigService) CreateOrUpdateConfig(ctx context.Context, config *model.
 
 // RollbackToVersion activates a previous configuration version
 func (s *ConfigService) RollbackToVersion(ctx context.Context, projectID uuid.UUID, version int) error {
+	if err := s.checkWritable(ctx, projectID); err != nil {
+		return err
+	}
+
 	// Get the old version
 	oldConfig, err := s.configRepo.GetConfigByVersion(ctx, projectID, version)
 	if err != nil {
@@ -109,6 +120,42 @@ func (s *ConfigService) GetConfigHistory(ctx context.Context, projectID uuid.UUI
 	return configs, nil
 }
 
+// CopyActiveConfig copies the active configuration of one project to another, re-encrypting the
+// API key when withAPIKey is set and leaving it out otherwise. It is a no-op when the source has
+// no configuration.
+func (s *ConfigService) CopyActiveConfig(ctx context.Context, sourceProjectID, targetProjectID uuid.UUID, withAPIKey bool) error {
+	source, err := s.configRepo.GetActiveConfig(ctx, sourceProjectID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get active config: %w", err)
+	}
+
+	config := *source
+	config.ID = uuid.Nil // Will be auto-generated
+	config.ProjectID = targetProjectID
+	config.APIKeyEncrypted = nil
+
+	if withAPIKey && len(source.APIKeyEncrypted) > 0 {
+		apiKey, err := s.decryptAPIKey(source.APIKeyEncrypted)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt API key: %w", err)
+		}
+		encrypted, err := s.encryptAPIKey(apiKey)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt API key: %w", err)
+		}
+		config.APIKeyEncrypted = encrypted
+	}
+
+	if err := s.configRepo.CreateConfig(ctx, &config); err != nil {
+		return fmt.Errorf("failed to create config: %w", err)
+	}
+
+	return nil
+}
+
 // GetDecryptedAPIKey retrieves and decrypts the API key for internal use
 func (s *ConfigService) GetDecryptedAPIKey(ctx context.Context, projectID uuid.UUID) (string, error) {
 	config, err := s.configRepo.GetActiveConfig(ctx, projectID)
@@ -123,6 +170,27 @@ func (s *ConfigService) GetDecryptedAPIKey(ctx context.Context, projectID uuid.U
 	return s.decryptAPIKey(config.APIKeyEncrypted)
 }
 
+// checkWritable fails for archived projects, whose configuration is read-only until they are restored
+func (s *ConfigService) checkWritable(ctx context.Context, projectID uuid.UUID) error {
+	if s.projectRepo == nil {
+		return nil
+	}
+
+	project, err := s.projectRepo.FindByID(ctx, projectID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrProjectNotFound
+		}
+		return fmt.Errorf("failed to retrieve project: %w", err)
+	}
+
+	if project.Status == model.ProjectStatusArchived {
+		return ErrProjectArchived
+	}
+
+	return nil
+}
+
 // validateConfig validates configuration fields
 func (s *ConfigService) validateConfig(config *model.OpenCodeConfig) error {
 	// Validate model provider (allow openai, anthropic, custom)
@@ -149,6 +217,13 @@ func (s *ConfigService) validateConfig(config *model.OpenCodeConfig) error {
 		}
 	}
 
+	// The reviewer shares the main model's API key, so it must come from the same provider
+	if config.ReviewModelName != nil && *config.ReviewModelName != "" && config.ModelProvider != "custom" {
+		if !IsValidModel(config.ModelProvider, *config.ReviewModelName) {
+			return fmt.Errorf("invalid review model %s for provider %s", *config.ReviewModelName, config.ModelProvider)
+		}
+	}
+
 	// Validate temperature range
 	if config.Temperature < 0 || config.Temperature > 2 {
 		return errors.New("temperature must be between 0 and 2")
@@ -198,47 +273,10 @@ func (s *ConfigService) validateConfig(config *model.OpenCodeConfig) error {
 
 // encryptAPIKey encrypts an API key using AES-256-GCM
 func (s *ConfigService) encryptAPIKey(plaintext string) ([]byte, error) {
-	block, err := aes.NewCipher(s.encryptionKey)
-	if err != nil {
-		return nil, err
-	}
-
-	aesGCM, err := cipher.NewGCM(block)
-	if err != nil {
-		return nil, err
-	}
-
-	nonce := make([]byte, aesGCM.NonceSize())
-	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
-		return nil, err
-	}
-
-	ciphertext := aesGCM.Seal(nonce, nonce, []byte(plaintext), nil)
-	return ciphertext, nil
+	return encryptAESGCM(s.encryptionKey, plaintext)
 }
 
 // decryptAPIKey decrypts an encrypted API key
 func (s *ConfigService) decryptAPIKey(ciphertext []byte) (string, error) {
-	block, err := aes.NewCipher(s.encryptionKey)
-	if err != nil {
-		return "", err
-	}
-
-	aesGCM, err := cipher.NewGCM(block)
-	if err != nil {
-		return "", err
-	}
-
-	nonceSize := aesGCM.NonceSize()
-	if len(ciphertext) < nonceSize {
-		return "", errors.New("ciphertext too short")
-	}
-
-	nonce, ciphertext := ciphertext[:nonceSize], ciphertext[nonceSize:]
-	plaintext, err := aesGCM.Open(nil, nonce, ciphertext, nil)
-	if err != nil {
-		return "", err
-	}
-
-	return string(plaintext), nil
+	return decryptAESGCM(s.encryptionKey, ciphertext)
 }
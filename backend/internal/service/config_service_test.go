@@ -85,7 +85,7 @@ func TestNewConfigService_ValidKey(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
 
-	service, err := NewConfigService(mockRepo, key)
+	service, err := NewConfigService(mockRepo, nil, key)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, service)
@@ -96,7 +96,7 @@ func TestNewConfigService_InvalidKey_NotBase64(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := "not-base64!!!!"
 
-	service, err := NewConfigService(mockRepo, key)
+	service, err := NewConfigService(mockRepo, nil, key)
 
 	assert.Error(t, err)
 	assert.Nil(t, service)
@@ -110,7 +110,7 @@ func TestNewConfigService_InvalidKey_WrongLength(t *testing.T) {
 	rand.Read(shortKey)
 	key := base64.StdEncoding.EncodeToString(shortKey)
 
-	service, err := NewConfigService(mockRepo, key)
+	service, err := NewConfigService(mockRepo, nil, key)
 
 	assert.Error(t, err)
 	assert.Nil(t, service)
@@ -121,7 +121,7 @@ func TestNewConfigService_InvalidKey_WrongLength(t *testing.T) {
 func TestGetActiveConfig_Success(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	ctx := context.Background()
 	projectID := uuid.New()
@@ -141,7 +141,7 @@ func TestGetActiveConfig_Success(t *testing.T) {
 func TestGetActiveConfig_NotFound(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	ctx := context.Background()
 	projectID := uuid.New()
@@ -161,7 +161,7 @@ func TestGetActiveConfig_NotFound(t *testing.T) {
 func TestCreateOrUpdateConfig_Success_NoAPIKey(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	ctx := context.Background()
 	config := createValidConfig()
@@ -178,7 +178,7 @@ func TestCreateOrUpdateConfig_Success_NoAPIKey(t *testing.T) {
 func TestCreateOrUpdateConfig_Success_WithAPIKey(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	ctx := context.Background()
 	config := createValidConfig()
@@ -199,7 +199,7 @@ func TestCreateOrUpdateConfig_Success_WithAPIKey(t *testing.T) {
 func TestCreateOrUpdateConfig_ValidationFails_InvalidProvider(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	ctx := context.Background()
 	config := createValidConfig()
@@ -215,7 +215,7 @@ func TestCreateOrUpdateConfig_ValidationFails_InvalidProvider(t *testing.T) {
 func TestCreateOrUpdateConfig_ValidationFails_InvalidTemperature(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	ctx := context.Background()
 	config := createValidConfig()
@@ -231,7 +231,7 @@ func TestCreateOrUpdateConfig_ValidationFails_InvalidTemperature(t *testing.T) {
 func TestCreateOrUpdateConfig_RepositoryError(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	ctx := context.Background()
 	config := createValidConfig()
@@ -245,12 +245,44 @@ func TestCreateOrUpdateConfig_RepositoryError(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestCreateOrUpdateConfig_ArchivedProject(t *testing.T) {
+	mockRepo := new(MockConfigRepository)
+	projectRepo := new(MockProjectRepository)
+	key := generateEncryptionKey()
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), projectRepo, key)
+
+	ctx := context.Background()
+	config := createValidConfig()
+	projectRepo.On("FindByID", ctx, config.ProjectID).Return(&model.Project{ID: config.ProjectID, Status: model.ProjectStatusArchived}, nil)
+
+	err := service.CreateOrUpdateConfig(ctx, config, "")
+
+	assert.ErrorIs(t, err, ErrProjectArchived)
+	mockRepo.AssertNotCalled(t, "CreateConfig")
+}
+
+func TestRollbackToVersion_ArchivedProject(t *testing.T) {
+	mockRepo := new(MockConfigRepository)
+	projectRepo := new(MockProjectRepository)
+	key := generateEncryptionKey()
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), projectRepo, key)
+
+	ctx := context.Background()
+	projectID := uuid.New()
+	projectRepo.On("FindByID", ctx, projectID).Return(&model.Project{ID: projectID, Status: model.ProjectStatusArchived}, nil)
+
+	err := service.RollbackToVersion(ctx, projectID, 1)
+
+	assert.ErrorIs(t, err, ErrProjectArchived)
+	mockRepo.AssertNotCalled(t, "GetConfigByVersion")
+}
+
 // Test RollbackToVersion
 
 func TestRollbackToVersion_Success(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	ctx := context.Background()
 	projectID := uuid.New()
@@ -272,7 +304,7 @@ func TestRollbackToVersion_Success(t *testing.T) {
 func TestRollbackToVersion_VersionNotFound(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	ctx := context.Background()
 	projectID := uuid.New()
@@ -293,7 +325,7 @@ func TestRollbackToVersion_VersionNotFound(t *testing.T) {
 func TestGetConfigHistory_Success(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	ctx := context.Background()
 	projectID := uuid.New()
@@ -324,7 +356,7 @@ func TestGetConfigHistory_Success(t *testing.T) {
 func TestGetConfigHistory_Empty(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	ctx := context.Background()
 	projectID := uuid.New()
@@ -343,7 +375,7 @@ func TestGetConfigHistory_Empty(t *testing.T) {
 func TestGetDecryptedAPIKey_Success(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	ctx := context.Background()
 	projectID := uuid.New()
@@ -366,7 +398,7 @@ func TestGetDecryptedAPIKey_Success(t *testing.T) {
 func TestGetDecryptedAPIKey_NoAPIKey(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	ctx := context.Background()
 	projectID := uuid.New()
@@ -384,12 +416,64 @@ func TestGetDecryptedAPIKey_NoAPIKey(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestCopyActiveConfig_ReencryptsAPIKey(t *testing.T) {
+	mockRepo := new(MockConfigRepository)
+	key := generateEncryptionKey()
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
+
+	ctx := context.Background()
+	sourceID := uuid.New()
+	targetID := uuid.New()
+
+	encryptedKey, _ := service.encryptAPIKey("sk-test-key-12345")
+	source := createValidConfig()
+	source.ID = uuid.New()
+	source.ProjectID = sourceID
+	source.Version = 3
+	source.APIKeyEncrypted = encryptedKey
+
+	var copied *model.OpenCodeConfig
+	mockRepo.On("GetActiveConfig", ctx, sourceID).Return(source, nil)
+	mockRepo.On("CreateConfig", ctx, mock.AnythingOfType("*model.OpenCodeConfig")).
+		Run(func(args mock.Arguments) { copied = args.Get(1).(*model.OpenCodeConfig) }).
+		Return(nil)
+
+	err := service.CopyActiveConfig(ctx, sourceID, targetID, true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, uuid.Nil, copied.ID)
+	assert.Equal(t, targetID, copied.ProjectID)
+	assert.Equal(t, source.ModelName, copied.ModelName)
+	assert.NotEqual(t, encryptedKey, copied.APIKeyEncrypted)
+	decrypted, err := service.decryptAPIKey(copied.APIKeyEncrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "sk-test-key-12345", decrypted)
+	assert.Equal(t, sourceID, source.ProjectID, "source config must not be modified")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCopyActiveConfig_NoSourceConfig(t *testing.T) {
+	mockRepo := new(MockConfigRepository)
+	key := generateEncryptionKey()
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
+
+	ctx := context.Background()
+	sourceID := uuid.New()
+
+	mockRepo.On("GetActiveConfig", ctx, sourceID).Return(nil, gorm.ErrRecordNotFound)
+
+	err := service.CopyActiveConfig(ctx, sourceID, uuid.New(), true)
+
+	assert.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "CreateConfig", mock.Anything, mock.Anything)
+}
+
 // Test validateConfig
 
 func TestValidateConfig_ValidOpenAI(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	config := createValidConfig()
 	config.ModelProvider = "openai"
@@ -403,7 +487,7 @@ func TestValidateConfig_ValidOpenAI(t *testing.T) {
 func TestValidateConfig_ValidAnthropic(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	config := createValidConfig()
 	config.ModelProvider = "anthropic"
@@ -417,7 +501,7 @@ func TestValidateConfig_ValidAnthropic(t *testing.T) {
 func TestValidateConfig_ValidCustom(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	endpoint := "https://api.custom.com/v1"
 	config := createValidConfig()
@@ -433,7 +517,7 @@ func TestValidateConfig_ValidCustom(t *testing.T) {
 func TestValidateConfig_InvalidProvider(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	config := createValidConfig()
 	config.ModelProvider = "invalid"
@@ -447,7 +531,7 @@ func TestValidateConfig_InvalidProvider(t *testing.T) {
 func TestValidateConfig_InvalidOpenAIModel(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	config := createValidConfig()
 	config.ModelProvider = "openai"
@@ -459,10 +543,31 @@ func TestValidateConfig_InvalidOpenAIModel(t *testing.T) {
 	assert.Contains(t, err.Error(), "invalid model")
 }
 
+func TestValidateConfig_ReviewModel(t *testing.T) {
+	mockRepo := new(MockConfigRepository)
+	key := generateEncryptionKey()
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
+
+	config := createValidConfig()
+	config.ModelProvider = "openai"
+	config.ModelName = "gpt-4o-mini"
+
+	reviewModel := "gpt-4o"
+	config.ReviewModelName = &reviewModel
+	assert.NoError(t, service.validateConfig(config))
+
+	otherProviderModel := "claude-3-opus-20240229"
+	config.ReviewModelName = &otherProviderModel
+	err := service.validateConfig(config)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid review model")
+}
+
 func TestValidateConfig_InvalidAnthropicModel(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	config := createValidConfig()
 	config.ModelProvider = "anthropic"
@@ -477,7 +582,7 @@ func TestValidateConfig_InvalidAnthropicModel(t *testing.T) {
 func TestValidateConfig_TemperatureTooLow(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	config := createValidConfig()
 	config.Temperature = -0.1
@@ -491,7 +596,7 @@ func TestValidateConfig_TemperatureTooLow(t *testing.T) {
 func TestValidateConfig_TemperatureTooHigh(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	config := createValidConfig()
 	config.Temperature = 2.1
@@ -505,7 +610,7 @@ func TestValidateConfig_TemperatureTooHigh(t *testing.T) {
 func TestValidateConfig_MaxTokensTooLow(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	config := createValidConfig()
 	config.MaxTokens = 0
@@ -519,7 +624,7 @@ func TestValidateConfig_MaxTokensTooLow(t *testing.T) {
 func TestValidateConfig_MaxTokensTooHigh(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	httpsEndpoint := "https://api.custom.com/v1"
 	config := createValidConfig()
@@ -537,7 +642,7 @@ func TestValidateConfig_MaxTokensTooHigh(t *testing.T) {
 func TestValidateConfig_MaxIterationsTooLow(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	config := createValidConfig()
 	config.MaxIterations = 0
@@ -551,7 +656,7 @@ func TestValidateConfig_MaxIterationsTooLow(t *testing.T) {
 func TestValidateConfig_MaxIterationsTooHigh(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	config := createValidConfig()
 	config.MaxIterations = 100
@@ -565,7 +670,7 @@ func TestValidateConfig_MaxIterationsTooHigh(t *testing.T) {
 func TestValidateConfig_TimeoutTooLow(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	config := createValidConfig()
 	config.TimeoutSeconds = 30
@@ -579,7 +684,7 @@ func TestValidateConfig_TimeoutTooLow(t *testing.T) {
 func TestValidateConfig_TimeoutTooHigh(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	config := createValidConfig()
 	config.TimeoutSeconds = 5000
@@ -593,7 +698,7 @@ func TestValidateConfig_TimeoutTooHigh(t *testing.T) {
 func TestValidateConfig_InvalidTool(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	config := createValidConfig()
 	config.EnabledTools = model.ToolsList{"file_ops", "invalid_tool"}
@@ -607,7 +712,7 @@ func TestValidateConfig_InvalidTool(t *testing.T) {
 func TestValidateConfig_CustomProvider_MissingEndpoint(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	config := createValidConfig()
 	config.ModelProvider = "custom"
@@ -622,7 +727,7 @@ func TestValidateConfig_CustomProvider_MissingEndpoint(t *testing.T) {
 func TestValidateConfig_CustomProvider_EmptyEndpoint(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	emptyEndpoint := ""
 	config := createValidConfig()
@@ -638,7 +743,7 @@ func TestValidateConfig_CustomProvider_EmptyEndpoint(t *testing.T) {
 func TestValidateConfig_CustomProvider_NotHTTPS(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	httpEndpoint := "http://api.custom.com/v1"
 	config := createValidConfig()
@@ -656,7 +761,7 @@ func TestValidateConfig_CustomProvider_NotHTTPS(t *testing.T) {
 func TestEncryptDecrypt_Success(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	originalText := "sk-test-api-key-12345"
 
@@ -672,7 +777,7 @@ func TestEncryptDecrypt_Success(t *testing.T) {
 func TestEncryptDecrypt_EmptyString(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	originalText := ""
 
@@ -688,7 +793,7 @@ func TestEncryptDecrypt_EmptyString(t *testing.T) {
 func TestDecrypt_InvalidCiphertext(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	invalidCiphertext := []byte("too-short")
 
@@ -702,7 +807,7 @@ func TestDecrypt_InvalidCiphertext(t *testing.T) {
 func TestDecrypt_CorruptedCiphertext(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	// Encrypt first
 	encrypted, _ := service.encryptAPIKey("test-key")
@@ -719,7 +824,7 @@ func TestDecrypt_CorruptedCiphertext(t *testing.T) {
 func TestEncryptDecrypt_LongString(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	// Generate a long API key
 	longKey := "sk-proj-" + string(make([]byte, 500))
@@ -740,7 +845,7 @@ func TestEncryptDecrypt_LongString(t *testing.T) {
 func TestValidateConfig_MaxTokens_ExceedsModelLimit_GPT4(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	config := createValidConfig()
 	config.ModelProvider = "openai"
@@ -757,7 +862,7 @@ func TestValidateConfig_MaxTokens_ExceedsModelLimit_GPT4(t *testing.T) {
 func TestValidateConfig_MaxTokens_WithinModelLimit_GPT4(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	config := createValidConfig()
 	config.ModelProvider = "openai"
@@ -772,7 +877,7 @@ func TestValidateConfig_MaxTokens_WithinModelLimit_GPT4(t *testing.T) {
 func TestValidateConfig_MaxTokens_ExceedsModelLimit_GPT4oMini(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	config := createValidConfig()
 	config.ModelProvider = "openai"
@@ -789,7 +894,7 @@ func TestValidateConfig_MaxTokens_ExceedsModelLimit_GPT4oMini(t *testing.T) {
 func TestValidateConfig_MaxTokens_WithinModelLimit_GPT4oMini(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	config := createValidConfig()
 	config.ModelProvider = "openai"
@@ -804,7 +909,7 @@ func TestValidateConfig_MaxTokens_WithinModelLimit_GPT4oMini(t *testing.T) {
 func TestValidateConfig_MaxTokens_ExceedsModelLimit_Claude3Opus(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	config := createValidConfig()
 	config.ModelProvider = "anthropic"
@@ -821,7 +926,7 @@ func TestValidateConfig_MaxTokens_ExceedsModelLimit_Claude3Opus(t *testing.T) {
 func TestValidateConfig_MaxTokens_WithinModelLimit_Claude3Opus(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	config := createValidConfig()
 	config.ModelProvider = "anthropic"
@@ -836,7 +941,7 @@ func TestValidateConfig_MaxTokens_WithinModelLimit_Claude3Opus(t *testing.T) {
 func TestValidateConfig_MaxTokens_CustomProvider_NoModelLimit(t *testing.T) {
 	mockRepo := new(MockConfigRepository)
 	key := generateEncryptionKey()
-	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), key)
+	service, _ := NewConfigService(repository.ConfigRepository(mockRepo), nil, key)
 
 	httpsEndpoint := "https://api.custom.com/v1"
 	config := createValidConfig()
@@ -17,7 +17,7 @@ var (
 	ErrInteractionNotFound   = errors.New("interaction not found")
 	ErrInvalidMessageType    = errors.New("invalid message type")
 	ErrInvalidMessageContent = errors.New("invalid message content")
-	ErrTaskNotOwnedByUser    = errors.New("task not owned by user")
+	ErrTaskNotOwnedByUser    = errors.New("task not accessible by user")
 	// ErrSessionNotFound is imported from session_service.go (shared sentinel error)
 )
 
@@ -47,33 +47,33 @@ type InteractionService interface {
 	// DeleteTaskHistory deletes all interactions for a task with authorization
 	DeleteTaskHistory(ctx context.Context, taskID, userID uuid.UUID) error
 
-	// ValidateTaskOwnership validates that a user owns the task
-	ValidateTaskOwnership(ctx context.Context, taskID, userID uuid.UUID) error
+	// ValidateTaskAccess validates that a user may view the task
+	ValidateTaskAccess(ctx context.Context, taskID, userID uuid.UUID) error
 }
 
 type interactionService struct {
 	interactionRepo repository.InteractionRepository
 	taskRepo        repository.TaskRepository
-	projectRepo     repository.ProjectRepository
+	authz           AuthorizationService
 	sessionRepo     repository.SessionRepository
 }
 
 func NewInteractionService(
 	interactionRepo repository.InteractionRepository,
 	taskRepo repository.TaskRepository,
-	projectRepo repository.ProjectRepository,
+	authz AuthorizationService,
 	sessionRepo repository.SessionRepository,
 ) InteractionService {
 	return &interactionService{
 		interactionRepo: interactionRepo,
 		taskRepo:        taskRepo,
-		projectRepo:     projectRepo,
+		authz:           authz,
 		sessionRepo:     sessionRepo,
 	}
 }
 
 func (s *interactionService) CreateUserMessage(ctx context.Context, taskID, userID uuid.UUID, content string, metadata model.JSONB) (*model.Interaction, error) {
-	if err := s.ValidateTaskOwnership(ctx, taskID, userID); err != nil {
+	if err := s.validateTaskRole(ctx, taskID, userID, model.ProjectRoleMaintainer); err != nil {
 		return nil, err
 	}
 
@@ -101,7 +101,7 @@ func (s *interactionService) CreateUserMessage(ctx context.Context, taskID, user
 // External user-facing API endpoints must NOT expose this directly.
 // Phase 7.3 will add internal authentication for agent response creation.
 func (s *interactionService) CreateAgentResponse(ctx context.Context, taskID, userID uuid.UUID, sessionID uuid.UUID, content string, metadata model.JSONB) (*model.Interaction, error) {
-	if err := s.ValidateTaskOwnership(ctx, taskID, userID); err != nil {
+	if err := s.ValidateTaskAccess(ctx, taskID, userID); err != nil {
 		return nil, err
 	}
 
@@ -130,7 +130,7 @@ func (s *interactionService) CreateAgentResponse(ctx context.Context, taskID, us
 }
 
 func (s *interactionService) CreateSystemNotification(ctx context.Context, taskID, userID uuid.UUID, sessionID *uuid.UUID, content string, metadata model.JSONB) (*model.Interaction, error) {
-	if err := s.ValidateTaskOwnership(ctx, taskID, userID); err != nil {
+	if err := s.ValidateTaskAccess(ctx, taskID, userID); err != nil {
 		return nil, err
 	}
 
@@ -162,8 +162,8 @@ func (s *interactionService) CreateSystemNotification(ctx context.Context, taskI
 
 // GetTaskHistory retrieves all interactions for a task with authorization
 func (s *interactionService) GetTaskHistory(ctx context.Context, taskID, userID uuid.UUID) ([]model.Interaction, error) {
-	// Validate task ownership
-	if err := s.ValidateTaskOwnership(ctx, taskID, userID); err != nil {
+	// Validate task access
+	if err := s.ValidateTaskAccess(ctx, taskID, userID); err != nil {
 		return nil, err
 	}
 
@@ -187,8 +187,8 @@ func (s *interactionService) GetSessionHistory(ctx context.Context, sessionID, u
 		return nil, fmt.Errorf("failed to retrieve session: %w", err)
 	}
 
-	// Validate task ownership via session's task
-	if err := s.ValidateTaskOwnership(ctx, session.TaskID, userID); err != nil {
+	// Validate task access via session's task
+	if err := s.ValidateTaskAccess(ctx, session.TaskID, userID); err != nil {
 		return nil, err
 	}
 
@@ -203,8 +203,8 @@ func (s *interactionService) GetSessionHistory(ctx context.Context, sessionID, u
 
 // DeleteTaskHistory deletes all interactions for a task with authorization
 func (s *interactionService) DeleteTaskHistory(ctx context.Context, taskID, userID uuid.UUID) error {
-	// Validate task ownership
-	if err := s.ValidateTaskOwnership(ctx, taskID, userID); err != nil {
+	// Validate task access
+	if err := s.validateTaskRole(ctx, taskID, userID, model.ProjectRoleMaintainer); err != nil {
 		return err
 	}
 
@@ -216,8 +216,13 @@ func (s *interactionService) DeleteTaskHistory(ctx context.Context, taskID, user
 	return nil
 }
 
-// ValidateTaskOwnership validates that a user owns the task
-func (s *interactionService) ValidateTaskOwnership(ctx context.Context, taskID, userID uuid.UUID) error {
+// ValidateTaskAccess validates that a user may view the task
+func (s *interactionService) ValidateTaskAccess(ctx context.Context, taskID, userID uuid.UUID) error {
+	return s.validateTaskRole(ctx, taskID, userID, model.ProjectRoleViewer)
+}
+
+// validateTaskRole validates that a user holds at least the given role on the project of the task
+func (s *interactionService) validateTaskRole(ctx context.Context, taskID, userID uuid.UUID, role model.ProjectRole) error {
 	// Retrieve task
 	task, err := s.taskRepo.FindByID(ctx, taskID)
 	if err != nil {
@@ -227,18 +232,11 @@ func (s *interactionService) ValidateTaskOwnership(ctx context.Context, taskID,
 		return fmt.Errorf("failed to retrieve task: %w", err)
 	}
 
-	// Retrieve project to check ownership
-	project, err := s.projectRepo.FindByID(ctx, task.ProjectID)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ErrProjectNotFound
+	if _, err := s.authz.AuthorizeProject(ctx, task.ProjectID, userID, role); err != nil {
+		if errors.Is(err, ErrUnauthorized) {
+			return ErrTaskNotOwnedByUser
 		}
-		return fmt.Errorf("failed to retrieve project: %w", err)
-	}
-
-	// Check ownership
-	if project.UserID != userID {
-		return ErrTaskNotOwnedByUser
+		return err
 	}
 
 	return nil
@@ -3,13 +3,19 @@ package service
 import (
 	"context"
 	"fmt"
+	"io"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -22,24 +28,134 @@ type KubernetesService interface {
 	// CreateProjectPod creates a new pod with 3 containers and a PVC for the project
 	CreateProjectPod(ctx context.Context, project *model.Project) error
 
-	// DeleteProjectPod deletes the pod and PVC associated with the project
+	// CreateEmptyProjectPod creates the PVC and pod of the project like CreateProjectPod without
+	// cloning the repository, leaving an empty workspace to import an archive into
+	CreateEmptyProjectPod(ctx context.Context, project *model.Project) error
+
+	// CreateForkedProjectPod creates the PVC of the project as a CSI clone of the workspace PVC of
+	// source, then the project pod without cloning the repository
+	CreateForkedProjectPod(ctx context.Context, project, source *model.Project) error
+
+	// DeleteProjectPod deletes the pod, PVC and workspace snapshots associated with the project
 	DeleteProjectPod(ctx context.Context, podName, namespace string) error
 
 	// GetPodStatus retrieves the current status of a pod
 	GetPodStatus(ctx context.Context, podName, namespace string) (string, error)
 
-	// WatchPodStatus watches for status changes of a pod
-	WatchPodStatus(ctx context.Context, podName, namespace string) (<-chan string, error)
+	// WatchPodStatus streams status changes of a pod and the Kubernetes events about it
+	WatchPodStatus(ctx context.Context, podName, namespace string) (<-chan PodStatusUpdate, error)
 
 	// GetPodIP retrieves the IP address of a pod
 	GetPodIP(ctx context.Context, podName, namespace string) (string, error)
+
+	// GetRepoCloneStatus reports the progress of the repo-clone init container
+	GetRepoCloneStatus(ctx context.Context, podName, namespace string) (*RepoCloneStatus, error)
+
+	// StopProjectPod deletes the project pod but keeps its PVC and credential secret
+	StopProjectPod(ctx context.Context, podName, namespace string) error
+
+	// StartProjectPod recreates the pod of a stopped project on its existing PVC
+	StartProjectPod(ctx context.Context, project *model.Project) error
+
+	// WaitForPodReady waits until all containers of the pod are ready and returns its IP
+	WaitForPodReady(ctx context.Context, podName, namespace string) (string, error)
+
+	// ResizeProjectResources applies the resources of the project to its workspace PVC and pod
+	ResizeProjectResources(ctx context.Context, project *model.Project) error
+
+	// StreamContainerLogs streams the logs of a container of the pod, each line prefixed with its RFC3339 timestamp
+	StreamContainerLogs(ctx context.Context, podName, namespace string, opts ContainerLogOptions) (io.ReadCloser, error)
+
+	// CheckWorkspaceSnapshots returns ErrSnapshotsUnavailable when the cluster cannot snapshot workspaces
+	CheckWorkspaceSnapshots(ctx context.Context) error
+
+	// CreateWorkspaceSnapshot takes a CSI snapshot of the project workspace PVC, before the run of taskID when set
+	CreateWorkspaceSnapshot(ctx context.Context, project *model.Project, taskID *uuid.UUID) (*WorkspaceSnapshot, error)
+
+	// ListWorkspaceSnapshots lists the snapshots of the project workspace, newest first
+	ListWorkspaceSnapshots(ctx context.Context, project *model.Project) ([]WorkspaceSnapshot, error)
+
+	// DeleteWorkspaceSnapshot deletes a snapshot of the project workspace
+	DeleteWorkspaceSnapshot(ctx context.Context, project *model.Project, name string) error
+
+	// RestoreWorkspaceSnapshot moves the project onto a fresh workspace PVC restored from the snapshot,
+	// calling save to persist the project before the pod and the previous PVC are deleted
+	RestoreWorkspaceSnapshot(ctx context.Context, project *model.Project, name string, save func(*model.Project) error) error
+}
+
+const (
+	repoCloneContainerName  = "repo-clone"
+	repoCredentialSecretKey = "credential"
+)
+
+// ContainerLogOptions selects the lines StreamContainerLogs returns
+type ContainerLogOptions struct {
+	Container string
+	Follow    bool      // keep streaming new lines until ctx is done
+	Previous  bool      // logs of the previous instance of a restarted container
+	TailLines int64     // only the last lines (0 = all)
+	Since     time.Time // only lines logged from then on (zero = all)
+}
+
+// RepoClonePhase describes where the repo-clone init container is at
+type RepoClonePhase string
+
+const (
+	RepoClonePending   RepoClonePhase = "pending"
+	RepoCloneRunning   RepoClonePhase = "cloning"
+	RepoCloneCompleted RepoClonePhase = "completed"
+	RepoCloneFailed    RepoClonePhase = "failed"
+)
+
+// RepoCloneStatus is the state of the repository clone for a project pod
+type RepoCloneStatus struct {
+	Phase    RepoClonePhase `json:"phase"`
+	Message  string         `json:"message,omitempty"`
+	Attempts int32          `json:"attempts"`
+}
+
+// Kinds of PodStatusUpdate
+const (
+	PodUpdateStatus  = "status"
+	PodUpdateEvent   = "event"
+	PodUpdateDeleted = "deleted"
+)
+
+// PodStatusUpdate is a change reported by WatchPodStatus: the status of the pod and its containers,
+// a Kubernetes event about the pod, or the deletion of the pod
+type PodStatusUpdate struct {
+	Type       string               `json:"type"`
+	Status     string               `json:"status,omitempty"`
+	Error      string               `json:"error,omitempty"`
+	Ready      bool                 `json:"ready"`
+	Containers []PodContainerStatus `json:"containers,omitempty"`
+	Event      *PodEvent            `json:"event,omitempty"`
+}
+
+// PodContainerStatus is the state of one container of a project pod
+type PodContainerStatus struct {
+	Name         string `json:"name"`
+	State        string `json:"state"`
+	Reason       string `json:"reason,omitempty"`
+	Ready        bool   `json:"ready"`
+	RestartCount int32  `json:"restart_count"`
+}
+
+// PodEvent is a Kubernetes event about a project pod
+type PodEvent struct {
+	Type      string    `json:"type"`
+	Reason    string    `json:"reason"`
+	Message   string    `json:"message"`
+	Count     int32     `json:"count"`
+	Timestamp time.Time `json:"timestamp"`
 }
 
 // kubernetesService implements the KubernetesService interface
 type kubernetesService struct {
-	clientset kubernetes.Interface
-	namespace string
-	config    *KubernetesConfig
+	clientset     kubernetes.Interface
+	dynamicClient dynamic.Interface // VolumeSnapshots; nil disables workspace snapshots
+	namespace     string
+	config        *KubernetesConfig
 }
 
 // KubernetesConfig holds configuration for Kubernetes operations
@@ -48,19 +164,25 @@ type KubernetesConfig struct {
 	OpenCodeImage     string
 	FileBrowserImage  string
 	SessionProxyImage string
+	GitCloneImage     string
 	WorkspaceSize     string
 	CPULimit          string
 	MemoryLimit       string
 	CPURequest        string
 	MemoryRequest     string
+	SnapshotClass     string // VolumeSnapshotClass of workspace snapshots; empty uses the cluster default
 }
 
 // NewKubernetesService creates a new Kubernetes service
 func NewKubernetesService(kubeconfig, namespace string, config *KubernetesConfig) (KubernetesService, error) {
-	clientset, err := initKubernetesClient(kubeconfig)
+	clientset, err := NewKubernetesClient(kubeconfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize kubernetes client: %w", err)
 	}
+	dynamicClient, err := newDynamicClient(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize kubernetes dynamic client: %w", err)
+	}
 
 	if config == nil {
 		config = &KubernetesConfig{
@@ -68,6 +190,7 @@ func NewKubernetesService(kubeconfig, namespace string, config *KubernetesConfig
 			OpenCodeImage:     "registry.legal-suite.com/opencode/opencode-server-sidecar:latest",
 			FileBrowserImage:  "registry.legal-suite.com/opencode/file-browser-sidecar:latest",
 			SessionProxyImage: "registry.legal-suite.com/opencode/session-proxy-sidecar:latest",
+			GitCloneImage:     defaultGitCloneImage,
 			WorkspaceSize:     "1Gi",
 			CPULimit:          "1000m",
 			MemoryLimit:       "1Gi",
@@ -81,20 +204,47 @@ func NewKubernetesService(kubeconfig, namespace string, config *KubernetesConfig
 	}
 
 	return &kubernetesService{
-		clientset: clientset,
-		namespace: namespace,
-		config:    config,
+		clientset:     clientset,
+		dynamicClient: dynamicClient,
+		namespace:     namespace,
+		config:        config,
 	}, nil
 }
 
-// initKubernetesClient initializes a Kubernetes client
+// NewKubernetesClient initializes a Kubernetes client
 // Tries in-cluster config first, falls back to kubeconfig
-func initKubernetesClient(kubeconfig string) (kubernetes.Interface, error) {
-	var config *rest.Config
-	var err error
+func NewKubernetesClient(kubeconfig string) (kubernetes.Interface, error) {
+	config, err := newRESTConfig(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
 
-	// Try in-cluster config first
-	config, err = rest.InClusterConfig()
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clientset: %w", err)
+	}
+
+	return clientset, nil
+}
+
+// newDynamicClient initializes a client for custom resources such as VolumeSnapshots
+func newDynamicClient(kubeconfig string) (dynamic.Interface, error) {
+	config, err := newRESTConfig(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	return client, nil
+}
+
+// newRESTConfig tries in-cluster config first, falls back to kubeconfig
+func newRESTConfig(kubeconfig string) (*rest.Config, error) {
+	config, err := rest.InClusterConfig()
 	if err != nil {
 		// Fall back to kubeconfig
 		if kubeconfig == "" {
@@ -106,33 +256,82 @@ func initKubernetesClient(kubeconfig string) (kubernetes.Interface, error) {
 		}
 	}
 
-	clientset, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create clientset: %w", err)
-	}
-
-	return clientset, nil
+	return config, nil
 }
 
 // CreateProjectPod creates a new pod with 3 containers and a PVC for the project
 func (k *kubernetesService) CreateProjectPod(ctx context.Context, project *model.Project) error {
+	return k.createProjectPod(ctx, project, true, nil)
+}
+
+// CreateEmptyProjectPod creates the PVC and pod of the project without the repo-clone init container.
+// Once the workspace holds the repository again, StartProjectPod skips the clone as usual.
+func (k *kubernetesService) CreateEmptyProjectPod(ctx context.Context, project *model.Project) error {
+	return k.createProjectPod(ctx, project, false, nil)
+}
+
+// CreateForkedProjectPod clones the workspace PVC of source, which already holds the repository. CSI clones
+// need the same namespace and storage class as the source and at least its size; the PVC stays pending on
+// storage without clone support.
+func (k *kubernetesService) CreateForkedProjectPod(ctx context.Context, project, source *model.Project) error {
+	if source.WorkspacePVCName == "" {
+		return fmt.Errorf("source project %s has no workspace PVC", source.ID)
+	}
+	if namespace := k.workspaceNamespace(source); namespace != k.config.Namespace {
+		return fmt.Errorf("workspace PVC of source project %s is in namespace %s, not %s", source.ID, namespace, k.config.Namespace)
+	}
+
+	return k.createProjectPod(ctx, project, false, &corev1.TypedLocalObjectReference{
+		Kind: "PersistentVolumeClaim",
+		Name: source.WorkspacePVCName,
+	})
+}
+
+// createProjectPod creates the PVC, credential secret and pod of the project. The PVC is populated from
+// dataSource when set; clone adds the repo-clone init container.
+func (k *kubernetesService) createProjectPod(ctx context.Context, project *model.Project, clone bool, dataSource *corev1.TypedLocalObjectReference) error {
 	// Generate unique names
 	podName := generatePodName(project.ID)
 	pvcName := generatePVCName(project.ID)
 
 	// Create PVC first
-	pvc := buildPVCSpec(pvcName, k.config.Namespace, k.config.WorkspaceSize, project.ID)
+	storageSize := project.StorageSize
+	if storageSize == "" {
+		storageSize = k.config.WorkspaceSize
+	}
+	pvc := buildPVCSpec(pvcName, k.config.Namespace, storageSize, project.ID)
+	if project.StorageClass != "" {
+		pvc.Spec.StorageClassName = &project.StorageClass
+	}
+	pvc.Spec.DataSource = dataSource
 	createdPVC, err := k.clientset.CoreV1().PersistentVolumeClaims(k.config.Namespace).Create(ctx, pvc, metav1.CreateOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to create PVC: %w", err)
 	}
 
+	// Store the repository credential so the clone init container can read it
+	var secretName string
+	if project.RepoURL != "" && project.RepoCredential != "" {
+		secretName = generateRepoSecretName(project.ID)
+		secret := buildRepoCredentialSecret(secretName, k.config.Namespace, project.RepoCredential, project.ID)
+		if _, err := k.clientset.CoreV1().Secrets(k.config.Namespace).Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+			_ = k.clientset.CoreV1().PersistentVolumeClaims(k.config.Namespace).Delete(ctx, createdPVC.Name, metav1.DeleteOptions{})
+			return fmt.Errorf("failed to create repository credential secret: %w", err)
+		}
+	}
+
 	// Create Pod
-	pod := buildProjectPodSpec(podName, k.config.Namespace, pvcName, project.ID, k.config)
+	pod := k.buildPod(project, podName, pvcName, secretName)
+	if !clone {
+		pod.Spec.InitContainers = nil
+	}
 	createdPod, err := k.clientset.CoreV1().Pods(k.config.Namespace).Create(ctx, pod, metav1.CreateOptions{})
 	if err != nil {
-		// Cleanup PVC if pod creation fails
+		// Cleanup PVC and credential secret if pod creation fails
 		_ = k.clientset.CoreV1().PersistentVolumeClaims(k.config.Namespace).Delete(ctx, createdPVC.Name, metav1.DeleteOptions{})
+		if secretName != "" {
+			_ = k.clientset.CoreV1().Secrets(k.config.Namespace).Delete(ctx, secretName, metav1.DeleteOptions{})
+		}
 		return fmt.Errorf("failed to create pod: %w", err)
 	}
 
@@ -147,13 +346,252 @@ func (k *kubernetesService) CreateProjectPod(ctx context.Context, project *model
 	return nil
 }
 
-// DeleteProjectPod deletes the pod and PVC associated with the project
+// buildPod creates the pod specification of a project, cloning its repository into the workspace
+// and exposing the repository credential when secretName is set
+func (k *kubernetesService) buildPod(project *model.Project, podName, pvcName, secretName string) *corev1.Pod {
+	pod := buildProjectPodSpec(podName, k.config.Namespace, pvcName, project.ID, k.config)
+	applyProjectResources(pod, project)
+	if project.RepoURL != "" {
+		pod.Spec.InitContainers = []corev1.Container{buildRepoCloneContainer(project, secretName, k.config)}
+		// The file-browser sidecar pushes to the project repository, never to a URL of the workspace's git config
+		for i := range pod.Spec.Containers {
+			if pod.Spec.Containers[i].Name == "file-browser" {
+				pod.Spec.Containers[i].Env = append(pod.Spec.Containers[i].Env, corev1.EnvVar{Name: "REPO_URL", Value: project.RepoURL})
+			}
+		}
+	}
+	if secretName != "" {
+		// The file-browser sidecar needs the credential to push commits
+		for i := range pod.Spec.Containers {
+			if pod.Spec.Containers[i].Name == "file-browser" {
+				pod.Spec.Containers[i].Env = append(pod.Spec.Containers[i].Env, buildRepoCredentialEnv(secretName))
+			}
+		}
+	}
+	return pod
+}
+
+// StopProjectPod deletes the project pod but keeps its PVC and credential secret, so
+// StartProjectPod can bring the workspace back
+func (k *kubernetesService) StopProjectPod(ctx context.Context, podName, namespace string) error {
+	err := k.clientset.CoreV1().Pods(namespace).Delete(ctx, podName, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete pod: %w", err)
+	}
+
+	return nil
+}
+
+// StartProjectPod recreates the pod of a stopped project on its existing PVC. The repo-clone
+// init container skips the clone because the workspace already holds the repository.
+func (k *kubernetesService) StartProjectPod(ctx context.Context, project *model.Project) error {
+	if project.WorkspacePVCName == "" {
+		return fmt.Errorf("project has no workspace PVC")
+	}
+
+	namespace := project.PodNamespace
+	if namespace == "" {
+		namespace = k.config.Namespace
+	}
+	podName := project.PodName
+	if podName == "" {
+		podName = generatePodName(project.ID)
+	}
+
+	// The credential secret outlives the pod; it is optional in the pod spec should it be gone
+	var secretName string
+	if project.RepoURL != "" && len(project.RepoCredentialEncrypted) > 0 {
+		secretName = generateRepoSecretName(project.ID)
+	}
+
+	pod := k.buildPod(project, podName, project.WorkspacePVCName, secretName)
+	pod.Namespace = namespace
+	createdPod, err := k.clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+	if errors.IsAlreadyExists(err) {
+		// An earlier start created the pod but did not wait for it to become ready
+		project.PodName = podName
+		project.PodNamespace = namespace
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create pod: %w", err)
+	}
+
+	project.PodName = createdPod.Name
+	project.PodNamespace = createdPod.Namespace
+	project.PodStatus = string(createdPod.Status.Phase)
+	now := time.Now()
+	project.PodCreatedAt = &now
+
+	return nil
+}
+
+// ResizeProjectResources applies the resources of the project to its workspace PVC and pod. The PVC
+// request is raised when the storage size grew, which needs a storage class allowing volume expansion.
+// Container resources cannot change in place, so a running pod is deleted and recreated; a stopped
+// project picks up its new resources when it starts again.
+func (k *kubernetesService) ResizeProjectResources(ctx context.Context, project *model.Project) error {
+	namespace := project.PodNamespace
+	if namespace == "" {
+		namespace = k.config.Namespace
+	}
+
+	if project.WorkspacePVCName != "" && project.StorageSize != "" {
+		if err := k.expandPVC(ctx, project.WorkspacePVCName, namespace, project.StorageSize); err != nil {
+			return err
+		}
+	}
+
+	if project.PodName == "" {
+		return nil
+	}
+
+	err := k.clientset.CoreV1().Pods(namespace).Delete(ctx, project.PodName, metav1.DeleteOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete pod: %w", err)
+	}
+
+	if err := k.waitForPodDeleted(ctx, project.PodName, namespace); err != nil {
+		return err
+	}
+
+	return k.StartProjectPod(ctx, project)
+}
+
+// expandPVC raises the storage request of the PVC to size; smaller sizes are left alone
+func (k *kubernetesService) expandPVC(ctx context.Context, pvcName, namespace, size string) error {
+	pvc, err := k.clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, pvcName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get PVC: %w", err)
+	}
+
+	requested := resource.MustParse(size)
+	current := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	if requested.Cmp(current) <= 0 {
+		return nil
+	}
+
+	if pvc.Spec.Resources.Requests == nil {
+		pvc.Spec.Resources.Requests = corev1.ResourceList{}
+	}
+	pvc.Spec.Resources.Requests[corev1.ResourceStorage] = requested
+	if _, err := k.clientset.CoreV1().PersistentVolumeClaims(namespace).Update(ctx, pvc, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to expand PVC: %w", err)
+	}
+
+	return nil
+}
+
+// waitForPodDeleted waits until the pod is gone, so a pod with the same name can be created. A pod
+// that is not being deleted replaced it already, e.g. recreated by the pod reconciler.
+func (k *kubernetesService) waitForPodDeleted(ctx context.Context, podName, namespace string) error {
+	ticker := time.NewTicker(podReadyPollInterval)
+	defer ticker.Stop()
+
+	for {
+		pod, err := k.clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get pod: %w", err)
+		}
+		if pod.DeletionTimestamp == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("pod was not deleted: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// StreamContainerLogs streams the logs of a container of the pod. Lines are prefixed with their
+// RFC3339 timestamp; the caller closes the stream.
+func (k *kubernetesService) StreamContainerLogs(ctx context.Context, podName, namespace string, opts ContainerLogOptions) (io.ReadCloser, error) {
+	logOptions := &corev1.PodLogOptions{
+		Container:  opts.Container,
+		Follow:     opts.Follow,
+		Previous:   opts.Previous,
+		Timestamps: true,
+	}
+	if opts.TailLines > 0 {
+		logOptions.TailLines = &opts.TailLines
+	}
+	if !opts.Since.IsZero() {
+		since := metav1.NewTime(opts.Since)
+		logOptions.SinceTime = &since
+	}
+
+	stream, err := k.clientset.CoreV1().Pods(namespace).GetLogs(podName, logOptions).Stream(ctx)
+	if err != nil {
+		// The API server answers 400 for containers that are not running yet or have no previous instance
+		if errors.IsBadRequest(err) || errors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: %v", ErrContainerLogsUnavailable, err)
+		}
+		return nil, fmt.Errorf("failed to stream container logs: %w", err)
+	}
+
+	return stream, nil
+}
+
+// podReadyPollInterval is how often WaitForPodReady checks the pod
+const podReadyPollInterval = 2 * time.Second
+
+// WaitForPodReady waits until all containers of the pod are ready and returns its IP.
+// It gives up when ctx ends or the pod fails.
+func (k *kubernetesService) WaitForPodReady(ctx context.Context, podName, namespace string) (string, error) {
+	ticker := time.NewTicker(podReadyPollInterval)
+	defer ticker.Stop()
+
+	for {
+		pod, err := k.clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return "", fmt.Errorf("failed to get pod: %w", err)
+		}
+		if err == nil {
+			if pod.Status.Phase == corev1.PodFailed {
+				return "", fmt.Errorf("pod failed: %s", pod.Status.Message)
+			}
+			if isPodReady(pod) && pod.Status.PodIP != "" {
+				return pod.Status.PodIP, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("pod did not become ready: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// isPodReady reports whether the pod's Ready condition is true
+func isPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// DeleteProjectPod deletes the pod, PVC and workspace snapshots associated with the project
 func (k *kubernetesService) DeleteProjectPod(ctx context.Context, podName, namespace string) error {
 	// Get pod to find associated PVC
 	pod, err := k.clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
 	if err != nil && !errors.IsNotFound(err) {
 		return fmt.Errorf("failed to get pod: %w", err)
 	}
+	if errors.IsNotFound(err) {
+		// A hibernated project has no pod; its PVC and credential secret are named after it
+		return k.deleteStoppedProjectResources(ctx, podName, namespace)
+	}
 
 	// Extract PVC name from pod volumes
 	var pvcName string
@@ -182,6 +620,60 @@ func (k *kubernetesService) DeleteProjectPod(ctx context.Context, podName, names
 		}
 	}
 
+	// Delete repository credential secret if the pod referenced one; a pod created without the
+	// repo-clone init container only references it from the file-browser sidecar
+	if pod != nil {
+		deleted := map[string]bool{}
+		for _, container := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+			for _, env := range container.Env {
+				if env.ValueFrom == nil || env.ValueFrom.SecretKeyRef == nil || env.Name != "REPO_CREDENTIAL" {
+					continue
+				}
+				secretName := env.ValueFrom.SecretKeyRef.Name
+				if deleted[secretName] {
+					continue
+				}
+				err = k.clientset.CoreV1().Secrets(namespace).Delete(ctx, secretName, metav1.DeleteOptions{})
+				if err != nil && !errors.IsNotFound(err) {
+					return fmt.Errorf("failed to delete repository credential secret: %w", err)
+				}
+				deleted[secretName] = true
+			}
+		}
+	}
+
+	k.deleteProjectSnapshots(ctx, strings.TrimPrefix(podName, "project-"), namespace)
+
+	return nil
+}
+
+// deleteStoppedProjectResources deletes the PVC, credential secret and snapshots left by StopProjectPod.
+// A workspace restored from a snapshot lives in a PVC named after the project with a random suffix.
+func (k *kubernetesService) deleteStoppedProjectResources(ctx context.Context, podName, namespace string) error {
+	shortID := strings.TrimPrefix(podName, "project-")
+	pvcName := "workspace-" + shortID
+
+	pvcs, err := k.clientset.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list PVCs: %w", err)
+	}
+	for _, pvc := range pvcs.Items {
+		if pvc.Name != pvcName && !strings.HasPrefix(pvc.Name, pvcName+"-") {
+			continue
+		}
+		err := k.clientset.CoreV1().PersistentVolumeClaims(namespace).Delete(ctx, pvc.Name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete PVC: %w", err)
+		}
+	}
+
+	err = k.clientset.CoreV1().Secrets(namespace).Delete(ctx, "repo-credentials-"+shortID, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete repository credential secret: %w", err)
+	}
+
+	k.deleteProjectSnapshots(ctx, shortID, namespace)
+
 	return nil
 }
 
@@ -198,57 +690,179 @@ func (k *kubernetesService) GetPodStatus(ctx context.Context, podName, namespace
 	return string(pod.Status.Phase), nil
 }
 
-// WatchPodStatus watches for status changes of a pod
-func (k *kubernetesService) WatchPodStatus(ctx context.Context, podName, namespace string) (<-chan string, error) {
-	// Create a watch for the specific pod
-	watcher, err := k.clientset.CoreV1().Pods(namespace).Watch(ctx, metav1.ListOptions{
-		FieldSelector: fmt.Sprintf("metadata.name=%s", podName),
-	})
+// WatchPodStatus streams status changes of a pod and the Kubernetes events about it until ctx is done.
+// Watches closed by the API server are reopened, and a pod recreated under the same name, such as a
+// woken or reconciled project pod, keeps being followed.
+func (k *kubernetesService) WatchPodStatus(ctx context.Context, podName, namespace string) (<-chan PodStatusUpdate, error) {
+	openPodWatch := func() (watch.Interface, error) {
+		return k.clientset.CoreV1().Pods(namespace).Watch(ctx, metav1.ListOptions{
+			FieldSelector: fields.OneTermEqualSelector("metadata.name", podName).String(),
+		})
+	}
+	openEventWatch := func() (watch.Interface, error) {
+		return k.clientset.CoreV1().Events(namespace).Watch(ctx, metav1.ListOptions{
+			FieldSelector: fields.Set{"involvedObject.kind": "Pod", "involvedObject.name": podName}.AsSelector().String(),
+		})
+	}
+
+	podWatcher, err := openPodWatch()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create pod watcher: %w", err)
 	}
+	eventWatcher, err := openEventWatch()
+	if err != nil {
+		podWatcher.Stop()
+		return nil, fmt.Errorf("failed to create pod event watcher: %w", err)
+	}
 
-	statusChan := make(chan string, 10)
-
-	// Start watching in a goroutine
+	updates := make(chan PodStatusUpdate, 10)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		followWatch(ctx, podWatcher, openPodWatch, podStatusUpdate, updates)
+	}()
+	go func() {
+		defer wg.Done()
+		followWatch(ctx, eventWatcher, openEventWatch, podEventUpdate, updates)
+	}()
 	go func() {
-		defer close(statusChan)
-		defer watcher.Stop()
+		wg.Wait()
+		close(updates)
+	}()
 
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case event, ok := <-watcher.ResultChan():
-				if !ok {
-					return
-				}
+	return updates, nil
+}
 
-				switch event.Type {
-				case watch.Added, watch.Modified:
-					if pod, ok := event.Object.(*corev1.Pod); ok {
-						status := string(pod.Status.Phase)
-						select {
-						case statusChan <- status:
-						case <-ctx.Done():
-							return
-						}
-					}
-				case watch.Deleted:
-					select {
-					case statusChan <- "Deleted":
-					case <-ctx.Done():
-						return
-					}
-					return
-				case watch.Error:
+// watchRetryInterval is how long WatchPodStatus waits before reopening a closed watch
+const watchRetryInterval = time.Second
+
+// followWatch sends the converted events of a watch until ctx is done, reopening the watch whenever it closes
+func followWatch(ctx context.Context, watcher watch.Interface, reopen func() (watch.Interface, error), convert func(watch.Event) (PodStatusUpdate, bool), updates chan<- PodStatusUpdate) {
+	defer func() { watcher.Stop() }()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				watcher.Stop()
+				select {
+				case <-ctx.Done():
 					return
+				case <-time.After(watchRetryInterval):
 				}
+				next, err := reopen()
+				if err != nil {
+					// An empty watch is closed already, so the watch is retried after another interval
+					next = watch.NewEmptyWatch()
+				}
+				watcher = next
+				continue
+			}
+
+			update, ok := convert(event)
+			if !ok {
+				continue
+			}
+			select {
+			case updates <- update:
+			case <-ctx.Done():
+				return
 			}
 		}
-	}()
+	}
+}
+
+// podStatusUpdate reports the phase, errors and container states of a watched pod
+func podStatusUpdate(event watch.Event) (PodStatusUpdate, bool) {
+	pod, ok := event.Object.(*corev1.Pod)
+	if !ok {
+		return PodStatusUpdate{}, false
+	}
+
+	switch event.Type {
+	case watch.Deleted:
+		return PodStatusUpdate{Type: PodUpdateDeleted, Status: "Deleted"}, true
+	case watch.Added, watch.Modified:
+	default:
+		return PodStatusUpdate{}, false
+	}
+
+	status, podError := podStatus(pod)
+	update := PodStatusUpdate{
+		Type:   PodUpdateStatus,
+		Status: status,
+		Error:  podError,
+		Ready:  isPodReady(pod),
+	}
+
+	// Report every sidecar, including those without a status yet
+	statuses := make(map[string]corev1.ContainerStatus, len(pod.Status.ContainerStatuses))
+	for _, cs := range pod.Status.ContainerStatuses {
+		statuses[cs.Name] = cs
+	}
+	for _, container := range pod.Spec.Containers {
+		cs, ok := statuses[container.Name]
+		if !ok {
+			update.Containers = append(update.Containers, PodContainerStatus{Name: container.Name, State: "waiting"})
+			continue
+		}
+		update.Containers = append(update.Containers, containerStatus(cs))
+	}
+
+	return update, true
+}
 
-	return statusChan, nil
+func containerStatus(cs corev1.ContainerStatus) PodContainerStatus {
+	status := PodContainerStatus{
+		Name:         cs.Name,
+		Ready:        cs.Ready,
+		RestartCount: cs.RestartCount,
+	}
+
+	switch {
+	case cs.State.Running != nil:
+		status.State = "running"
+	case cs.State.Terminated != nil:
+		status.State = "terminated"
+		status.Reason = cs.State.Terminated.Reason
+	default:
+		status.State = "waiting"
+		if cs.State.Waiting != nil {
+			status.Reason = cs.State.Waiting.Reason
+		}
+	}
+
+	return status
+}
+
+// podEventUpdate reports a Kubernetes event about the watched pod
+func podEventUpdate(event watch.Event) (PodStatusUpdate, bool) {
+	k8sEvent, ok := event.Object.(*corev1.Event)
+	if !ok || (event.Type != watch.Added && event.Type != watch.Modified) {
+		return PodStatusUpdate{}, false
+	}
+
+	timestamp := k8sEvent.LastTimestamp.Time
+	if timestamp.IsZero() {
+		timestamp = k8sEvent.EventTime.Time
+	}
+	if timestamp.IsZero() {
+		timestamp = k8sEvent.CreationTimestamp.Time
+	}
+
+	return PodStatusUpdate{
+		Type: PodUpdateEvent,
+		Event: &PodEvent{
+			Type:      k8sEvent.Type,
+			Reason:    k8sEvent.Reason,
+			Message:   k8sEvent.Message,
+			Count:     k8sEvent.Count,
+			Timestamp: timestamp,
+		},
+	}, true
 }
 
 // generatePodName generates a unique pod name for a project
@@ -265,6 +879,12 @@ func generatePVCName(projectID uuid.UUID) string {
 	return fmt.Sprintf("workspace-%s", shortID)
 }
 
+// generateRepoSecretName generates the credential secret name for a project
+func generateRepoSecretName(projectID uuid.UUID) string {
+	shortID := projectID.String()[:8]
+	return fmt.Sprintf("repo-credentials-%s", shortID)
+}
+
 // GetPodIP retrieves the IP address of a pod
 func (k *kubernetesService) GetPodIP(ctx context.Context, podName, namespace string) (string, error) {
 	pod, err := k.clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
@@ -281,3 +901,79 @@ func (k *kubernetesService) GetPodIP(ctx context.Context, podName, namespace str
 
 	return pod.Status.PodIP, nil
 }
+
+// GetRepoCloneStatus reports the progress of the repo-clone init container.
+// Pods created without a repository report the clone as completed.
+func (k *kubernetesService) GetRepoCloneStatus(ctx context.Context, podName, namespace string) (*RepoCloneStatus, error) {
+	pod, err := k.clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, fmt.Errorf("pod not found")
+		}
+		return nil, fmt.Errorf("failed to get pod: %w", err)
+	}
+
+	hasCloneContainer := false
+	for _, container := range pod.Spec.InitContainers {
+		if container.Name == repoCloneContainerName {
+			hasCloneContainer = true
+			break
+		}
+	}
+	if !hasCloneContainer {
+		return &RepoCloneStatus{Phase: RepoCloneCompleted}, nil
+	}
+
+	for _, cs := range pod.Status.InitContainerStatuses {
+		if cs.Name != repoCloneContainerName {
+			continue
+		}
+		return repoCloneStatusFromContainer(cs), nil
+	}
+
+	return &RepoCloneStatus{Phase: RepoClonePending}, nil
+}
+
+// repoCloneStatusFromContainer maps an init container status to a clone status.
+// With RestartPolicyAlways a failed init container is retried, so a failure is
+// reported as soon as one attempt terminated with a non-zero exit code.
+func repoCloneStatusFromContainer(cs corev1.ContainerStatus) *RepoCloneStatus {
+	status := &RepoCloneStatus{Attempts: cs.RestartCount + 1}
+
+	if t := cs.State.Terminated; t != nil {
+		if t.ExitCode == 0 {
+			status.Phase = RepoCloneCompleted
+			return status
+		}
+		status.Phase = RepoCloneFailed
+		status.Message = terminationMessage(t)
+		return status
+	}
+
+	if t := cs.LastTerminationState.Terminated; t != nil && t.ExitCode != 0 {
+		status.Phase = RepoCloneFailed
+		status.Message = terminationMessage(t)
+		return status
+	}
+
+	if cs.State.Running != nil {
+		status.Phase = RepoCloneRunning
+		return status
+	}
+
+	status.Phase = RepoClonePending
+	if w := cs.State.Waiting; w != nil && (w.Reason == "ErrImagePull" || w.Reason == "ImagePullBackOff") {
+		status.Phase = RepoCloneFailed
+		status.Message = w.Message
+	}
+	return status
+}
+
+// terminationMessage returns the most useful description of a terminated container
+func terminationMessage(t *corev1.ContainerStateTerminated) string {
+	msg := strings.TrimSpace(t.Message)
+	if msg == "" {
+		msg = t.Reason
+	}
+	return fmt.Sprintf("exit code %d: %s", t.ExitCode, msg)
+}
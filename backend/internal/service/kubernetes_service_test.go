@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"io"
 	"testing"
 	"time"
 
@@ -232,6 +233,322 @@ func TestCreateProjectPod(t *testing.T) {
 	}
 }
 
+func TestCreateProjectPod_WithRepository(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+
+	config := &KubernetesConfig{
+		Namespace:         "test-namespace",
+		OpenCodeImage:     "opencode:latest",
+		FileBrowserImage:  "file-browser:latest",
+		SessionProxyImage: "session-proxy:latest",
+		GitCloneImage:     "git:latest",
+		WorkspaceSize:     "1Gi",
+		CPULimit:          "1000m",
+		MemoryLimit:       "1Gi",
+		CPURequest:        "100m",
+		MemoryRequest:     "256Mi",
+	}
+
+	service := &kubernetesService{
+		clientset: clientset,
+		namespace: "test-namespace",
+		config:    config,
+	}
+
+	projectID := uuid.New()
+	project := &model.Project{
+		ID:             projectID,
+		UserID:         uuid.New(),
+		Name:           "test-project",
+		RepoURL:        "https://github.com/test/private.git",
+		RepoRef:        "develop",
+		RepoCloneDepth: 1,
+		RepoCredential: "ghp_secret",
+	}
+
+	ctx := context.Background()
+	if err := service.CreateProjectPod(ctx, project); err != nil {
+		t.Fatalf("CreateProjectPod failed: %v", err)
+	}
+
+	secretName := generateRepoSecretName(projectID)
+	secret, err := clientset.CoreV1().Secrets("test-namespace").Get(ctx, secretName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get credential secret: %v", err)
+	}
+	if secret.StringData[repoCredentialSecretKey] != "ghp_secret" {
+		t.Errorf("Expected credential to be stored in secret, got %q", secret.StringData[repoCredentialSecretKey])
+	}
+
+	pod, err := clientset.CoreV1().Pods("test-namespace").Get(ctx, project.PodName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get created pod: %v", err)
+	}
+
+	if len(pod.Spec.InitContainers) != 1 {
+		t.Fatalf("Expected 1 init container, got %d", len(pod.Spec.InitContainers))
+	}
+
+	clone := pod.Spec.InitContainers[0]
+	if clone.Name != repoCloneContainerName {
+		t.Errorf("Expected init container %s, got %s", repoCloneContainerName, clone.Name)
+	}
+	if clone.Image != "git:latest" {
+		t.Errorf("Expected image git:latest, got %s", clone.Image)
+	}
+	if clone.TerminationMessagePolicy != corev1.TerminationMessageFallbackToLogsOnError {
+		t.Errorf("Expected FallbackToLogsOnError termination policy, got %s", clone.TerminationMessagePolicy)
+	}
+
+	env := map[string]corev1.EnvVar{}
+	for _, e := range clone.Env {
+		env[e.Name] = e
+	}
+	if env["REPO_URL"].Value != project.RepoURL {
+		t.Errorf("Expected REPO_URL %s, got %s", project.RepoURL, env["REPO_URL"].Value)
+	}
+	if env["REPO_REF"].Value != "develop" {
+		t.Errorf("Expected REPO_REF develop, got %s", env["REPO_REF"].Value)
+	}
+	if env["REPO_DEPTH"].Value != "1" {
+		t.Errorf("Expected REPO_DEPTH 1, got %s", env["REPO_DEPTH"].Value)
+	}
+	credential := env["REPO_CREDENTIAL"]
+	if credential.ValueFrom == nil || credential.ValueFrom.SecretKeyRef == nil || credential.ValueFrom.SecretKeyRef.Name != secretName {
+		t.Errorf("Expected REPO_CREDENTIAL to reference secret %s", secretName)
+	}
+
+	// The file-browser sidecar receives the credential and the repository URL for git push
+	foundSidecarCredential := false
+	foundSidecarRepoURL := false
+	for _, c := range pod.Spec.Containers {
+		if c.Name != "file-browser" {
+			continue
+		}
+		for _, e := range c.Env {
+			if e.Name == "REPO_CREDENTIAL" && e.ValueFrom != nil && e.ValueFrom.SecretKeyRef != nil && e.ValueFrom.SecretKeyRef.Name == secretName {
+				foundSidecarCredential = true
+			}
+			if e.Name == "REPO_URL" && e.Value == project.RepoURL {
+				foundSidecarRepoURL = true
+			}
+		}
+	}
+	if !foundSidecarCredential {
+		t.Error("Expected file-browser container to reference the credential secret")
+	}
+	if !foundSidecarRepoURL {
+		t.Error("Expected file-browser container to receive the repository URL")
+	}
+
+	// Deleting the pod removes the credential secret as well
+	if err := service.DeleteProjectPod(ctx, project.PodName, "test-namespace"); err != nil {
+		t.Fatalf("DeleteProjectPod failed: %v", err)
+	}
+	if _, err := clientset.CoreV1().Secrets("test-namespace").Get(ctx, secretName, metav1.GetOptions{}); err == nil {
+		t.Error("Expected credential secret to be deleted")
+	}
+}
+
+func TestCreateEmptyProjectPod(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+
+	service := &kubernetesService{
+		clientset: clientset,
+		namespace: "test-namespace",
+		config: &KubernetesConfig{
+			Namespace:     "test-namespace",
+			GitCloneImage: "git:latest",
+			WorkspaceSize: "1Gi",
+			CPULimit:      "1000m",
+			MemoryLimit:   "1Gi",
+			CPURequest:    "100m",
+			MemoryRequest: "256Mi",
+		},
+	}
+
+	projectID := uuid.New()
+	project := &model.Project{
+		ID:             projectID,
+		RepoURL:        "https://github.com/test/private.git",
+		RepoCredential: "ghp_secret",
+	}
+
+	ctx := context.Background()
+	if err := service.CreateEmptyProjectPod(ctx, project); err != nil {
+		t.Fatalf("CreateEmptyProjectPod failed: %v", err)
+	}
+
+	pod, err := clientset.CoreV1().Pods("test-namespace").Get(ctx, project.PodName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get created pod: %v", err)
+	}
+	if len(pod.Spec.InitContainers) != 0 {
+		t.Errorf("Expected no init container, got %d", len(pod.Spec.InitContainers))
+	}
+	if project.WorkspacePVCName != generatePVCName(projectID) {
+		t.Errorf("Expected WorkspacePVCName %s, got %s", generatePVCName(projectID), project.WorkspacePVCName)
+	}
+
+	// The credential secret is only referenced by the file-browser sidecar, and still deleted with the pod
+	secretName := generateRepoSecretName(projectID)
+	if _, err := clientset.CoreV1().Secrets("test-namespace").Get(ctx, secretName, metav1.GetOptions{}); err != nil {
+		t.Fatalf("Failed to get credential secret: %v", err)
+	}
+	if err := service.DeleteProjectPod(ctx, project.PodName, "test-namespace"); err != nil {
+		t.Fatalf("DeleteProjectPod failed: %v", err)
+	}
+	if _, err := clientset.CoreV1().Secrets("test-namespace").Get(ctx, secretName, metav1.GetOptions{}); err == nil {
+		t.Error("Expected credential secret to be deleted")
+	}
+}
+
+func TestCreateForkedProjectPod(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+
+	service := &kubernetesService{
+		clientset: clientset,
+		namespace: "test-namespace",
+		config: &KubernetesConfig{
+			Namespace:     "test-namespace",
+			GitCloneImage: "git:latest",
+			WorkspaceSize: "1Gi",
+			CPULimit:      "1000m",
+			MemoryLimit:   "1Gi",
+			CPURequest:    "100m",
+			MemoryRequest: "256Mi",
+		},
+	}
+
+	source := &model.Project{
+		ID:               uuid.New(),
+		PodNamespace:     "test-namespace",
+		WorkspacePVCName: "workspace-source",
+	}
+	project := &model.Project{
+		ID:           uuid.New(),
+		RepoURL:      "https://github.com/test/repo.git",
+		StorageSize:  "5Gi",
+		StorageClass: "csi-rbd",
+	}
+
+	ctx := context.Background()
+	if err := service.CreateForkedProjectPod(ctx, project, source); err != nil {
+		t.Fatalf("CreateForkedProjectPod failed: %v", err)
+	}
+
+	pvc, err := clientset.CoreV1().PersistentVolumeClaims("test-namespace").Get(ctx, project.WorkspacePVCName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get created PVC: %v", err)
+	}
+	if pvc.Spec.DataSource == nil || pvc.Spec.DataSource.Kind != "PersistentVolumeClaim" || pvc.Spec.DataSource.Name != "workspace-source" {
+		t.Errorf("Expected PVC cloned from workspace-source, got %+v", pvc.Spec.DataSource)
+	}
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName != "csi-rbd" {
+		t.Errorf("Expected storage class csi-rbd, got %v", pvc.Spec.StorageClassName)
+	}
+
+	pod, err := clientset.CoreV1().Pods("test-namespace").Get(ctx, project.PodName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get created pod: %v", err)
+	}
+	if len(pod.Spec.InitContainers) != 0 {
+		t.Errorf("Expected no repo-clone init container, got %d", len(pod.Spec.InitContainers))
+	}
+
+	// Clones cannot cross namespaces
+	source.PodNamespace = "other-namespace"
+	if err := service.CreateForkedProjectPod(ctx, &model.Project{ID: uuid.New()}, source); err == nil {
+		t.Error("Expected an error for a source PVC in another namespace")
+	}
+}
+
+func TestGetRepoCloneStatus(t *testing.T) {
+	namespace := "test-namespace"
+	cloneSpec := corev1.PodSpec{
+		InitContainers: []corev1.Container{{Name: repoCloneContainerName}},
+	}
+
+	tests := []struct {
+		name          string
+		spec          corev1.PodSpec
+		statuses      []corev1.ContainerStatus
+		expectedPhase RepoClonePhase
+		expectedMsg   string
+	}{
+		{
+			name:          "pod without repository",
+			spec:          corev1.PodSpec{},
+			expectedPhase: RepoCloneCompleted,
+		},
+		{
+			name:          "init container not started",
+			spec:          cloneSpec,
+			expectedPhase: RepoClonePending,
+		},
+		{
+			name: "clone running",
+			spec: cloneSpec,
+			statuses: []corev1.ContainerStatus{{
+				Name:  repoCloneContainerName,
+				State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+			}},
+			expectedPhase: RepoCloneRunning,
+		},
+		{
+			name: "clone completed",
+			spec: cloneSpec,
+			statuses: []corev1.ContainerStatus{{
+				Name:  repoCloneContainerName,
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0}},
+			}},
+			expectedPhase: RepoCloneCompleted,
+		},
+		{
+			name: "clone failed and restarting",
+			spec: cloneSpec,
+			statuses: []corev1.ContainerStatus{{
+				Name:         repoCloneContainerName,
+				RestartCount: 2,
+				State:        corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+				LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					ExitCode: 1,
+					Message:  "git clone of https://github.com/test/private.git failed",
+				}},
+			}},
+			expectedPhase: RepoCloneFailed,
+			expectedMsg:   "exit code 1: git clone of https://github.com/test/private.git failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "project-12345678", Namespace: namespace},
+				Spec:       tt.spec,
+				Status:     corev1.PodStatus{InitContainerStatuses: tt.statuses},
+			}
+
+			service := &kubernetesService{
+				clientset: fake.NewSimpleClientset(pod),
+				namespace: namespace,
+				config:    &KubernetesConfig{Namespace: namespace},
+			}
+
+			status, err := service.GetRepoCloneStatus(context.Background(), "project-12345678", namespace)
+			if err != nil {
+				t.Fatalf("GetRepoCloneStatus failed: %v", err)
+			}
+			if status.Phase != tt.expectedPhase {
+				t.Errorf("Expected phase %s, got %s", tt.expectedPhase, status.Phase)
+			}
+			if status.Message != tt.expectedMsg {
+				t.Errorf("Expected message %q, got %q", tt.expectedMsg, status.Message)
+			}
+		})
+	}
+}
+
 func TestDeleteProjectPod(t *testing.T) {
 	// Create fake clientset with existing pod and PVC
 	projectID := uuid.New()
@@ -393,11 +710,431 @@ func TestWatchPodStatus(t *testing.T) {
 
 	// Wait for status update
 	select {
-	case status := <-statusChan:
-		if status != string(corev1.PodRunning) {
-			t.Errorf("Expected status 'Running', got %s", status)
+	case update := <-statusChan:
+		if update.Type != PodUpdateStatus || update.Status != string(corev1.PodRunning) {
+			t.Errorf("Expected status 'Running', got %+v", update)
 		}
 	case <-ctx.Done():
 		t.Fatal("Timeout waiting for status update")
 	}
 }
+
+func TestWatchPodStatus_ContainersAndEvents(t *testing.T) {
+	namespace := "test-namespace"
+	clientset := fake.NewSimpleClientset()
+	service := &kubernetesService{
+		clientset: clientset,
+		namespace: namespace,
+		config:    &KubernetesConfig{Namespace: namespace},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	updates, err := service.WatchPodStatus(ctx, "project-1234", namespace)
+	if err != nil {
+		t.Fatalf("WatchPodStatus failed: %v", err)
+	}
+
+	next := func() PodStatusUpdate {
+		t.Helper()
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				t.Fatal("Update channel closed")
+			}
+			return update
+		case <-ctx.Done():
+			t.Fatal("Timeout waiting for update")
+		}
+		return PodStatusUpdate{}
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "project-1234", Namespace: namespace},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{
+			{Name: "opencode-server"}, {Name: "file-browser"}, {Name: "session-proxy"},
+		}},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "opencode-server", Ready: true, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+				{
+					Name:         "file-browser",
+					RestartCount: 3,
+					State:        corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+				},
+			},
+		},
+	}
+	if _, err := clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Failed to create pod: %v", err)
+	}
+
+	update := next()
+	if update.Type != PodUpdateStatus || update.Status != "Running" || update.Ready {
+		t.Errorf("Unexpected status update: %+v", update)
+	}
+	if update.Error != "container file-browser is in CrashLoopBackOff" {
+		t.Errorf("Unexpected pod error: %q", update.Error)
+	}
+	want := []PodContainerStatus{
+		{Name: "opencode-server", State: "running", Ready: true},
+		{Name: "file-browser", State: "waiting", Reason: "CrashLoopBackOff", RestartCount: 3},
+		{Name: "session-proxy", State: "waiting"},
+	}
+	if len(update.Containers) != len(want) {
+		t.Fatalf("Expected %d containers, got %+v", len(want), update.Containers)
+	}
+	for i := range want {
+		if update.Containers[i] != want[i] {
+			t.Errorf("Container %d: expected %+v, got %+v", i, want[i], update.Containers[i])
+		}
+	}
+
+	event := &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "project-1234.backoff", Namespace: namespace},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "project-1234", Namespace: namespace},
+		Type:           corev1.EventTypeWarning,
+		Reason:         "BackOff",
+		Message:        "Back-off restarting failed container file-browser",
+		Count:          4,
+		LastTimestamp:  metav1.Now(),
+	}
+	if _, err := clientset.CoreV1().Events(namespace).Create(ctx, event, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+
+	update = next()
+	if update.Type != PodUpdateEvent || update.Event == nil {
+		t.Fatalf("Expected an event update, got %+v", update)
+	}
+	if update.Event.Type != "Warning" || update.Event.Reason != "BackOff" || update.Event.Count != 4 {
+		t.Errorf("Unexpected event: %+v", update.Event)
+	}
+
+	if err := clientset.CoreV1().Pods(namespace).Delete(ctx, "project-1234", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("Failed to delete pod: %v", err)
+	}
+	if update = next(); update.Type != PodUpdateDeleted {
+		t.Errorf("Expected a deleted update, got %+v", update)
+	}
+
+	// A recreated pod keeps being followed
+	pod.ResourceVersion = ""
+	pod.Status = corev1.PodStatus{Phase: corev1.PodPending}
+	if _, err := clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Failed to recreate pod: %v", err)
+	}
+	if update = next(); update.Type != PodUpdateStatus || update.Status != "Pending" {
+		t.Errorf("Expected the recreated pod to be pending, got %+v", update)
+	}
+
+	cancel()
+	for range updates {
+	}
+}
+
+func TestStopAndStartProjectPod(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	config := &KubernetesConfig{
+		Namespace:         "test-namespace",
+		OpenCodeImage:     "opencode:latest",
+		FileBrowserImage:  "file-browser:latest",
+		SessionProxyImage: "session-proxy:latest",
+		GitCloneImage:     "git:latest",
+		WorkspaceSize:     "1Gi",
+		CPULimit:          "1000m",
+		MemoryLimit:       "1Gi",
+		CPURequest:        "100m",
+		MemoryRequest:     "256Mi",
+	}
+	service := &kubernetesService{
+		clientset: clientset,
+		namespace: "test-namespace",
+		config:    config,
+	}
+
+	project := &model.Project{
+		ID:             uuid.New(),
+		UserID:         uuid.New(),
+		Name:           "test-project",
+		RepoURL:        "https://github.com/test/private.git",
+		RepoCredential: "ghp_secret",
+	}
+
+	ctx := context.Background()
+	if err := service.CreateProjectPod(ctx, project); err != nil {
+		t.Fatalf("CreateProjectPod failed: %v", err)
+	}
+	project.RepoCredential = ""
+	project.RepoCredentialEncrypted = []byte("encrypted")
+
+	if err := service.StopProjectPod(ctx, project.PodName, project.PodNamespace); err != nil {
+		t.Fatalf("StopProjectPod failed: %v", err)
+	}
+
+	if _, err := clientset.CoreV1().Pods("test-namespace").Get(ctx, project.PodName, metav1.GetOptions{}); err == nil {
+		t.Error("Expected pod to be deleted")
+	}
+	if _, err := clientset.CoreV1().PersistentVolumeClaims("test-namespace").Get(ctx, project.WorkspacePVCName, metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected PVC to be kept: %v", err)
+	}
+	if _, err := clientset.CoreV1().Secrets("test-namespace").Get(ctx, generateRepoSecretName(project.ID), metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected credential secret to be kept: %v", err)
+	}
+
+	// Stopping a stopped project is a no-op
+	if err := service.StopProjectPod(ctx, project.PodName, project.PodNamespace); err != nil {
+		t.Fatalf("StopProjectPod on a missing pod failed: %v", err)
+	}
+
+	if err := service.StartProjectPod(ctx, project); err != nil {
+		t.Fatalf("StartProjectPod failed: %v", err)
+	}
+
+	pod, err := clientset.CoreV1().Pods("test-namespace").Get(ctx, project.PodName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get recreated pod: %v", err)
+	}
+	if claim := pod.Spec.Volumes[0].PersistentVolumeClaim; claim == nil || claim.ClaimName != project.WorkspacePVCName {
+		t.Errorf("Expected pod to mount the existing PVC %s", project.WorkspacePVCName)
+	}
+	if len(pod.Spec.InitContainers) != 1 {
+		t.Fatalf("Expected the repo-clone init container, got %d init containers", len(pod.Spec.InitContainers))
+	}
+	hasCredential := false
+	for _, env := range pod.Spec.InitContainers[0].Env {
+		if env.Name == "REPO_CREDENTIAL" && env.ValueFrom.SecretKeyRef.Name == generateRepoSecretName(project.ID) {
+			hasCredential = true
+		}
+	}
+	if !hasCredential {
+		t.Error("Expected the init container to read the existing credential secret")
+	}
+
+	// Starting an already started project keeps its pod
+	if err := service.StartProjectPod(ctx, project); err != nil {
+		t.Fatalf("StartProjectPod on an existing pod failed: %v", err)
+	}
+}
+
+func TestWaitForPodReady(t *testing.T) {
+	namespace := "test-namespace"
+	readyPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "ready-pod", Namespace: namespace},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			PodIP:      "10.0.0.5",
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+	startingPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "starting-pod", Namespace: namespace},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			PodIP:      "10.0.0.6",
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionFalse}},
+		},
+	}
+	failedPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "failed-pod", Namespace: namespace},
+		Status:     corev1.PodStatus{Phase: corev1.PodFailed, Message: "evicted"},
+	}
+
+	service := &kubernetesService{
+		clientset: fake.NewSimpleClientset(readyPod, startingPod, failedPod),
+		namespace: namespace,
+		config:    &KubernetesConfig{Namespace: namespace},
+	}
+
+	ip, err := service.WaitForPodReady(context.Background(), "ready-pod", namespace)
+	if err != nil {
+		t.Fatalf("WaitForPodReady failed: %v", err)
+	}
+	if ip != "10.0.0.5" {
+		t.Errorf("Expected IP 10.0.0.5, got %s", ip)
+	}
+
+	if _, err := service.WaitForPodReady(context.Background(), "failed-pod", namespace); err == nil {
+		t.Error("Expected an error for a failed pod")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := service.WaitForPodReady(ctx, "starting-pod", namespace); err == nil {
+		t.Error("Expected an error once the context ends before the pod is ready")
+	}
+}
+
+func TestDeleteProjectPod_Hibernated(t *testing.T) {
+	projectID := uuid.New()
+	namespace := "test-namespace"
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: generatePVCName(projectID), Namespace: namespace},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: generateRepoSecretName(projectID), Namespace: namespace},
+	}
+	clientset := fake.NewSimpleClientset(pvc, secret)
+
+	service := &kubernetesService{
+		clientset: clientset,
+		namespace: namespace,
+		config:    &KubernetesConfig{Namespace: namespace},
+	}
+
+	ctx := context.Background()
+	if err := service.DeleteProjectPod(ctx, generatePodName(projectID), namespace); err != nil {
+		t.Fatalf("DeleteProjectPod failed: %v", err)
+	}
+
+	if _, err := clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, pvc.Name, metav1.GetOptions{}); err == nil {
+		t.Error("Expected PVC to be deleted")
+	}
+	if _, err := clientset.CoreV1().Secrets(namespace).Get(ctx, secret.Name, metav1.GetOptions{}); err == nil {
+		t.Error("Expected credential secret to be deleted")
+	}
+}
+
+func TestResizeProjectResources(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	service := &kubernetesService{
+		clientset: clientset,
+		namespace: "test-namespace",
+		config: &KubernetesConfig{
+			Namespace:         "test-namespace",
+			OpenCodeImage:     "opencode:latest",
+			FileBrowserImage:  "file-browser:latest",
+			SessionProxyImage: "session-proxy:latest",
+			WorkspaceSize:     "1Gi",
+			CPULimit:          "1000m",
+			MemoryLimit:       "1Gi",
+			CPURequest:        "100m",
+			MemoryRequest:     "256Mi",
+		},
+	}
+
+	project := &model.Project{ID: uuid.New(), UserID: uuid.New(), Name: "test-project"}
+	small, _ := LookupResourceProfile(ResourceProfileSmall)
+	applyResourceProfile(project, &small)
+	project.StorageClass = "fast-ssd"
+
+	ctx := context.Background()
+	if err := service.CreateProjectPod(ctx, project); err != nil {
+		t.Fatalf("CreateProjectPod failed: %v", err)
+	}
+
+	pvc, err := clientset.CoreV1().PersistentVolumeClaims("test-namespace").Get(ctx, project.WorkspacePVCName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get PVC: %v", err)
+	}
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName != "fast-ssd" {
+		t.Errorf("Expected storage class fast-ssd, got %v", pvc.Spec.StorageClassName)
+	}
+	assertContainerLimits(t, clientset, project, "500m", "512Mi")
+
+	large, _ := LookupResourceProfile(ResourceProfileLarge)
+	applyResourceProfile(project, &large)
+	project.StorageClass = "fast-ssd"
+	if err := service.ResizeProjectResources(ctx, project); err != nil {
+		t.Fatalf("ResizeProjectResources failed: %v", err)
+	}
+
+	pvc, err = clientset.CoreV1().PersistentVolumeClaims("test-namespace").Get(ctx, project.WorkspacePVCName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get PVC: %v", err)
+	}
+	if storage := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; storage.String() != "10Gi" {
+		t.Errorf("Expected PVC to be expanded to 10Gi, got %s", storage.String())
+	}
+	assertContainerLimits(t, clientset, project, "2", "4Gi")
+
+	// A stopped project only gets its PVC expanded; the pod starts with the new resources later
+	if err := service.StopProjectPod(ctx, project.PodName, project.PodNamespace); err != nil {
+		t.Fatalf("StopProjectPod failed: %v", err)
+	}
+	project.StorageSize = "20Gi"
+	if err := service.ResizeProjectResources(ctx, project); err != nil {
+		t.Fatalf("ResizeProjectResources on a stopped project failed: %v", err)
+	}
+	if _, err := clientset.CoreV1().Pods("test-namespace").Get(ctx, project.PodName, metav1.GetOptions{}); err == nil {
+		t.Error("Expected the stopped project to stay without a pod")
+	}
+	pvc, _ = clientset.CoreV1().PersistentVolumeClaims("test-namespace").Get(ctx, project.WorkspacePVCName, metav1.GetOptions{})
+	if storage := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; storage.String() != "20Gi" {
+		t.Errorf("Expected PVC to be expanded to 20Gi, got %s", storage.String())
+	}
+}
+
+// assertContainerLimits checks the limits of the containers running the project's resource profile
+func assertContainerLimits(t *testing.T, clientset *fake.Clientset, project *model.Project, cpu, memory string) {
+	t.Helper()
+
+	pod, err := clientset.CoreV1().Pods(project.PodNamespace).Get(context.Background(), project.PodName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get pod: %v", err)
+	}
+	for _, container := range pod.Spec.Containers {
+		limits := container.Resources.Limits
+		if container.Name == "file-browser" {
+			if limits.Cpu().String() != "100m" {
+				t.Errorf("Expected file-browser to keep its own limits, got CPU %s", limits.Cpu().String())
+			}
+			continue
+		}
+		if limits.Cpu().String() != cpu || limits.Memory().String() != memory {
+			t.Errorf("Expected %s limits %s/%s, got %s/%s", container.Name, cpu, memory, limits.Cpu().String(), limits.Memory().String())
+		}
+	}
+}
+
+func TestStreamContainerLogs(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "project-12345678", Namespace: "test-namespace"},
+	})
+	service := &kubernetesService{
+		clientset: clientset,
+		namespace: "test-namespace",
+		config:    &KubernetesConfig{Namespace: "test-namespace"},
+	}
+
+	logs, err := service.StreamContainerLogs(context.Background(), "project-12345678", "test-namespace", ContainerLogOptions{
+		Container: "opencode-server",
+		TailLines: 100,
+		Since:     time.Now().Add(-time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("StreamContainerLogs failed: %v", err)
+	}
+	defer logs.Close()
+
+	content, err := io.ReadAll(logs)
+	if err != nil {
+		t.Fatalf("Failed to read logs: %v", err)
+	}
+	if len(content) == 0 {
+		t.Error("Expected log content")
+	}
+
+	var logOptions *corev1.PodLogOptions
+	for _, action := range clientset.Actions() {
+		if action.GetSubresource() == "log" {
+			if generic, ok := action.(k8stesting.GenericAction); ok {
+				logOptions, _ = generic.GetValue().(*corev1.PodLogOptions)
+			}
+		}
+	}
+	if logOptions == nil {
+		t.Fatal("Expected a pod log request")
+	}
+	if logOptions.Container != "opencode-server" || !logOptions.Timestamps {
+		t.Errorf("Expected timestamped opencode-server logs, got %+v", logOptions)
+	}
+	if logOptions.TailLines == nil || *logOptions.TailLines != 100 {
+		t.Errorf("Expected the last 100 lines, got %v", logOptions.TailLines)
+	}
+	if logOptions.SinceTime == nil {
+		t.Error("Expected logs since an hour ago")
+	}
+}
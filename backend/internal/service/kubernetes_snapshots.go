@@ -0,0 +1,334 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/rand"
+
+	"github.com/npinot/vibe/backend/internal/model"
+)
+
+// volumeSnapshotGVR is the CSI VolumeSnapshot resource, served by the external-snapshotter CRDs
+var volumeSnapshotGVR = schema.GroupVersionResource{Group: "snapshot.storage.k8s.io", Version: "v1", Resource: "volumesnapshots"}
+
+// Triggers of a workspace snapshot
+const (
+	SnapshotTriggerManual = "manual"
+	SnapshotTriggerTask   = "task"
+)
+
+const (
+	snapshotTriggerLabel = "snapshot-trigger"
+	snapshotTaskIDKey    = "task-id"
+)
+
+// WorkspaceSnapshot is a CSI snapshot of a project workspace PVC
+type WorkspaceSnapshot struct {
+	Name        string     `json:"name"`
+	Trigger     string     `json:"trigger"`
+	TaskID      *uuid.UUID `json:"task_id,omitempty"`
+	ReadyToUse  bool       `json:"ready_to_use"`
+	RestoreSize string     `json:"restore_size,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// CreateWorkspaceSnapshot takes a CSI snapshot of the project workspace PVC. The snapshot is taken
+// asynchronously by the CSI driver; it can be restored once ReadyToUse.
+func (k *kubernetesService) CreateWorkspaceSnapshot(ctx context.Context, project *model.Project, taskID *uuid.UUID) (*WorkspaceSnapshot, error) {
+	if k.dynamicClient == nil {
+		return nil, ErrSnapshotsUnavailable
+	}
+	if project.WorkspacePVCName == "" {
+		return nil, fmt.Errorf("%w: project has no workspace PVC", ErrSnapshotsUnavailable)
+	}
+
+	trigger := SnapshotTriggerManual
+	if taskID != nil {
+		trigger = SnapshotTriggerTask
+	}
+
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(volumeSnapshotGVR.GroupVersion().WithKind("VolumeSnapshot"))
+	snapshot.SetName(generateSnapshotName(project.ID))
+	snapshot.SetNamespace(k.workspaceNamespace(project))
+	snapshot.SetLabels(map[string]string{
+		"app":                "opencode-project",
+		projectIDLabel:       project.ID.String(),
+		snapshotTriggerLabel: trigger,
+	})
+	if taskID != nil {
+		snapshot.SetAnnotations(map[string]string{snapshotTaskIDKey: taskID.String()})
+	}
+	spec := map[string]interface{}{
+		"source": map[string]interface{}{
+			"persistentVolumeClaimName": project.WorkspacePVCName,
+		},
+	}
+	if k.config.SnapshotClass != "" {
+		spec["volumeSnapshotClassName"] = k.config.SnapshotClass
+	}
+	snapshot.Object["spec"] = spec
+
+	created, err := k.dynamicClient.Resource(volumeSnapshotGVR).Namespace(snapshot.GetNamespace()).Create(ctx, snapshot, metav1.CreateOptions{})
+	if errors.IsNotFound(err) {
+		return nil, fmt.Errorf("%w: the VolumeSnapshot CRDs are not installed", ErrSnapshotsUnavailable)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create volume snapshot: %w", err)
+	}
+
+	return workspaceSnapshot(created), nil
+}
+
+// CheckWorkspaceSnapshots looks up the VolumeSnapshot resource in the cluster's API
+func (k *kubernetesService) CheckWorkspaceSnapshots(ctx context.Context) error {
+	if k.dynamicClient == nil {
+		return ErrSnapshotsUnavailable
+	}
+
+	resources, err := k.clientset.Discovery().ServerResourcesForGroupVersion(volumeSnapshotGVR.GroupVersion().String())
+	if errors.IsNotFound(err) {
+		return fmt.Errorf("%w: the VolumeSnapshot CRDs are not installed", ErrSnapshotsUnavailable)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to discover volume snapshots: %w", err)
+	}
+
+	for _, resource := range resources.APIResources {
+		if resource.Name == volumeSnapshotGVR.Resource {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: the VolumeSnapshot CRDs are not installed", ErrSnapshotsUnavailable)
+}
+
+// ListWorkspaceSnapshots lists the snapshots of the project workspace, newest first
+func (k *kubernetesService) ListWorkspaceSnapshots(ctx context.Context, project *model.Project) ([]WorkspaceSnapshot, error) {
+	if k.dynamicClient == nil {
+		return nil, ErrSnapshotsUnavailable
+	}
+
+	list, err := k.dynamicClient.Resource(volumeSnapshotGVR).Namespace(k.workspaceNamespace(project)).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", projectIDLabel, project.ID),
+	})
+	if errors.IsNotFound(err) {
+		return nil, fmt.Errorf("%w: the VolumeSnapshot CRDs are not installed", ErrSnapshotsUnavailable)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list volume snapshots: %w", err)
+	}
+
+	snapshots := make([]WorkspaceSnapshot, 0, len(list.Items))
+	for i := range list.Items {
+		snapshots = append(snapshots, *workspaceSnapshot(&list.Items[i]))
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		if snapshots[i].CreatedAt.Equal(snapshots[j].CreatedAt) {
+			return snapshots[i].Name > snapshots[j].Name
+		}
+		return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt)
+	})
+
+	return snapshots, nil
+}
+
+// DeleteWorkspaceSnapshot deletes a snapshot of the project workspace
+func (k *kubernetesService) DeleteWorkspaceSnapshot(ctx context.Context, project *model.Project, name string) error {
+	if _, err := k.getWorkspaceSnapshot(ctx, project, name); err != nil {
+		return err
+	}
+
+	err := k.dynamicClient.Resource(volumeSnapshotGVR).Namespace(k.workspaceNamespace(project)).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete volume snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// RestoreWorkspaceSnapshot restores the snapshot into a fresh PVC and moves the project onto it,
+// deleting the PVC it replaces. The volume of a pod cannot change, so a running pod is deleted and
+// recreated on the restored PVC; a hibernated project picks it up when it wakes. save persists the
+// project once it uses the restored PVC, before its pod and the previous PVC are deleted: the pod
+// reconciler recreates vanished pods from the stored project, which must not name the previous PVC.
+func (k *kubernetesService) RestoreWorkspaceSnapshot(ctx context.Context, project *model.Project, name string, save func(*model.Project) error) error {
+	snapshot, err := k.getWorkspaceSnapshot(ctx, project, name)
+	if err != nil {
+		return err
+	}
+	if !snapshot.ReadyToUse {
+		return ErrSnapshotNotReady
+	}
+
+	namespace := k.workspaceNamespace(project)
+	size := project.StorageSize
+	if size == "" {
+		size = k.config.WorkspaceSize
+	}
+	// A volume restored from a snapshot cannot be smaller than the snapshot
+	if restoreSize, err := resource.ParseQuantity(snapshot.RestoreSize); err == nil {
+		if requested := resource.MustParse(size); restoreSize.Cmp(requested) > 0 {
+			size = restoreSize.String()
+		}
+	}
+
+	pvcName := fmt.Sprintf("%s-%s", generatePVCName(project.ID), rand.String(5))
+	pvc := buildPVCSpec(pvcName, namespace, size, project.ID)
+	if project.StorageClass != "" {
+		pvc.Spec.StorageClassName = &project.StorageClass
+	}
+	apiGroup := volumeSnapshotGVR.Group
+	pvc.Spec.DataSource = &corev1.TypedLocalObjectReference{
+		APIGroup: &apiGroup,
+		Kind:     "VolumeSnapshot",
+		Name:     name,
+	}
+	if _, err := k.clientset.CoreV1().PersistentVolumeClaims(namespace).Create(ctx, pvc, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to create PVC: %w", err)
+	}
+
+	previousPVC := project.WorkspacePVCName
+	project.WorkspacePVCName = pvcName
+	if err := save(project); err != nil {
+		project.WorkspacePVCName = previousPVC
+		_ = k.clientset.CoreV1().PersistentVolumeClaims(namespace).Delete(ctx, pvcName, metav1.DeleteOptions{})
+		return err
+	}
+
+	running := false
+	if project.PodName != "" {
+		err := k.clientset.CoreV1().Pods(namespace).Delete(ctx, project.PodName, metav1.DeleteOptions{})
+		switch {
+		case errors.IsNotFound(err):
+			// A hibernated project has no pod
+		case err != nil:
+			// The pod still runs on the previous PVC, move the project back onto it
+			project.WorkspacePVCName = previousPVC
+			if saveErr := save(project); saveErr != nil {
+				log.Printf("[KubernetesService] Failed to move project %s back to PVC %s: %v", project.ID, previousPVC, saveErr)
+			} else {
+				_ = k.clientset.CoreV1().PersistentVolumeClaims(namespace).Delete(ctx, pvcName, metav1.DeleteOptions{})
+			}
+			return fmt.Errorf("failed to delete pod: %w", err)
+		default:
+			running = true
+		}
+	}
+
+	var waitErr error
+	if running {
+		// The previous PVC is only released once the pod is gone; the reconciler recreates the pod
+		// on the restored PVC should it not come back before the wait ends
+		waitErr = k.waitForPodDeleted(ctx, project.PodName, namespace)
+	}
+
+	if previousPVC != "" {
+		err := k.clientset.CoreV1().PersistentVolumeClaims(namespace).Delete(ctx, previousPVC, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			log.Printf("[KubernetesService] Failed to delete PVC %s replaced by snapshot %s: %v", previousPVC, name, err)
+		}
+	}
+
+	if waitErr != nil {
+		return waitErr
+	}
+	if running {
+		return k.StartProjectPod(ctx, project)
+	}
+	return nil
+}
+
+// getWorkspaceSnapshot returns the snapshot of the project workspace, or ErrSnapshotNotFound when
+// it does not exist or belongs to another project
+func (k *kubernetesService) getWorkspaceSnapshot(ctx context.Context, project *model.Project, name string) (*WorkspaceSnapshot, error) {
+	if k.dynamicClient == nil {
+		return nil, ErrSnapshotsUnavailable
+	}
+
+	snapshot, err := k.dynamicClient.Resource(volumeSnapshotGVR).Namespace(k.workspaceNamespace(project)).Get(ctx, name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, ErrSnapshotNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get volume snapshot: %w", err)
+	}
+	if snapshot.GetLabels()[projectIDLabel] != project.ID.String() {
+		return nil, ErrSnapshotNotFound
+	}
+
+	return workspaceSnapshot(snapshot), nil
+}
+
+// deleteProjectSnapshots deletes the workspace snapshots of the project with the short ID. Snapshots
+// are not worth keeping a project from being deleted, so failures are only logged.
+func (k *kubernetesService) deleteProjectSnapshots(ctx context.Context, shortID, namespace string) {
+	if k.dynamicClient == nil {
+		return
+	}
+
+	snapshots := k.dynamicClient.Resource(volumeSnapshotGVR).Namespace(namespace)
+	list, err := snapshots.List(ctx, metav1.ListOptions{LabelSelector: projectPodSelector})
+	if errors.IsNotFound(err) {
+		// The VolumeSnapshot CRDs are not installed
+		return
+	}
+	if err != nil {
+		log.Printf("[KubernetesService] Failed to list volume snapshots of project %s: %v", shortID, err)
+		return
+	}
+
+	prefix := fmt.Sprintf("snapshot-%s-", shortID)
+	for _, snapshot := range list.Items {
+		if !strings.HasPrefix(snapshot.GetName(), prefix) {
+			continue
+		}
+		err := snapshots.Delete(ctx, snapshot.GetName(), metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			log.Printf("[KubernetesService] Failed to delete volume snapshot %s: %v", snapshot.GetName(), err)
+		}
+	}
+}
+
+func (k *kubernetesService) workspaceNamespace(project *model.Project) string {
+	if project.PodNamespace != "" {
+		return project.PodNamespace
+	}
+	return k.config.Namespace
+}
+
+// workspaceSnapshot reads the workspace snapshot out of a VolumeSnapshot
+func workspaceSnapshot(obj *unstructured.Unstructured) *WorkspaceSnapshot {
+	snapshot := &WorkspaceSnapshot{
+		Name:      obj.GetName(),
+		Trigger:   obj.GetLabels()[snapshotTriggerLabel],
+		CreatedAt: obj.GetCreationTimestamp().Time,
+	}
+	if taskID, err := uuid.Parse(obj.GetAnnotations()[snapshotTaskIDKey]); err == nil {
+		snapshot.TaskID = &taskID
+	}
+
+	snapshot.ReadyToUse, _, _ = unstructured.NestedBool(obj.Object, "status", "readyToUse")
+	snapshot.RestoreSize, _, _ = unstructured.NestedString(obj.Object, "status", "restoreSize")
+	snapshot.Error, _, _ = unstructured.NestedString(obj.Object, "status", "error", "message")
+
+	return snapshot
+}
+
+// generateSnapshotName generates a unique snapshot name for a project workspace
+func generateSnapshotName(projectID uuid.UUID) string {
+	shortID := projectID.String()[:8]
+	return fmt.Sprintf("snapshot-%s-%s", shortID, rand.String(5))
+}
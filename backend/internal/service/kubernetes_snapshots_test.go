@@ -0,0 +1,327 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/npinot/vibe/backend/internal/model"
+)
+
+const snapshotTestNamespace = "test-namespace"
+
+func newSnapshotTestService(clientset *fake.Clientset, snapshots ...runtime.Object) (*kubernetesService, *dynamicfake.FakeDynamicClient) {
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{volumeSnapshotGVR: "VolumeSnapshotList"},
+		snapshots...,
+	)
+	return &kubernetesService{
+		clientset:     clientset,
+		dynamicClient: dynamicClient,
+		namespace:     snapshotTestNamespace,
+		config: &KubernetesConfig{
+			Namespace:         snapshotTestNamespace,
+			OpenCodeImage:     "opencode:latest",
+			FileBrowserImage:  "file-browser:latest",
+			SessionProxyImage: "session-proxy:latest",
+			WorkspaceSize:     "1Gi",
+			CPULimit:          "1000m",
+			MemoryLimit:       "1Gi",
+			CPURequest:        "100m",
+			MemoryRequest:     "256Mi",
+			SnapshotClass:     "csi-snapclass",
+		},
+	}, dynamicClient
+}
+
+// discardProject stands in for saving the project when restoring a snapshot
+func discardProject(*model.Project) error { return nil }
+
+// testVolumeSnapshot builds a VolumeSnapshot as the snapshot controller reports it
+func testVolumeSnapshot(name string, projectID uuid.UUID, trigger string, ready bool, created time.Time) *unstructured.Unstructured {
+	snapshot := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"source": map[string]interface{}{"persistentVolumeClaimName": generatePVCName(projectID)},
+		},
+		"status": map[string]interface{}{
+			"readyToUse":  ready,
+			"restoreSize": "2Gi",
+		},
+	}}
+	snapshot.SetGroupVersionKind(volumeSnapshotGVR.GroupVersion().WithKind("VolumeSnapshot"))
+	snapshot.SetName(name)
+	snapshot.SetNamespace(snapshotTestNamespace)
+	snapshot.SetLabels(map[string]string{"app": "opencode-project", projectIDLabel: projectID.String(), snapshotTriggerLabel: trigger})
+	snapshot.SetCreationTimestamp(metav1.NewTime(created))
+	return snapshot
+}
+
+func TestCreateWorkspaceSnapshot(t *testing.T) {
+	service, dynamicClient := newSnapshotTestService(fake.NewSimpleClientset())
+	project := &model.Project{ID: uuid.New(), WorkspacePVCName: "workspace-12345678", PodNamespace: snapshotTestNamespace}
+	taskID := uuid.New()
+
+	ctx := context.Background()
+	snapshot, err := service.CreateWorkspaceSnapshot(ctx, project, &taskID)
+	if err != nil {
+		t.Fatalf("CreateWorkspaceSnapshot failed: %v", err)
+	}
+	if !strings.HasPrefix(snapshot.Name, "snapshot-"+project.ID.String()[:8]+"-") {
+		t.Errorf("Unexpected snapshot name %s", snapshot.Name)
+	}
+	if snapshot.Trigger != SnapshotTriggerTask || snapshot.TaskID == nil || *snapshot.TaskID != taskID {
+		t.Errorf("Expected a snapshot of task %s, got trigger %s and task %v", taskID, snapshot.Trigger, snapshot.TaskID)
+	}
+
+	created, err := dynamicClient.Resource(volumeSnapshotGVR).Namespace(snapshotTestNamespace).Get(ctx, snapshot.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get volume snapshot: %v", err)
+	}
+	if created.GetLabels()[projectIDLabel] != project.ID.String() {
+		t.Errorf("Expected project-id label %s, got %v", project.ID, created.GetLabels())
+	}
+	if source, _, _ := unstructured.NestedString(created.Object, "spec", "source", "persistentVolumeClaimName"); source != project.WorkspacePVCName {
+		t.Errorf("Expected snapshot of PVC %s, got %q", project.WorkspacePVCName, source)
+	}
+	if class, _, _ := unstructured.NestedString(created.Object, "spec", "volumeSnapshotClassName"); class != "csi-snapclass" {
+		t.Errorf("Expected snapshot class csi-snapclass, got %q", class)
+	}
+
+	manual, err := service.CreateWorkspaceSnapshot(ctx, project, nil)
+	if err != nil {
+		t.Fatalf("CreateWorkspaceSnapshot failed: %v", err)
+	}
+	if manual.Trigger != SnapshotTriggerManual || manual.TaskID != nil {
+		t.Errorf("Expected a manual snapshot, got trigger %s and task %v", manual.Trigger, manual.TaskID)
+	}
+}
+
+func TestCreateWorkspaceSnapshot_Unavailable(t *testing.T) {
+	ctx := context.Background()
+	project := &model.Project{ID: uuid.New(), WorkspacePVCName: "workspace-12345678"}
+
+	service, _ := newSnapshotTestService(fake.NewSimpleClientset())
+	service.dynamicClient = nil
+	if _, err := service.CreateWorkspaceSnapshot(ctx, project, nil); !errors.Is(err, ErrSnapshotsUnavailable) {
+		t.Errorf("Expected ErrSnapshotsUnavailable without a dynamic client, got %v", err)
+	}
+
+	service, _ = newSnapshotTestService(fake.NewSimpleClientset())
+	if _, err := service.CreateWorkspaceSnapshot(ctx, &model.Project{ID: uuid.New()}, nil); !errors.Is(err, ErrSnapshotsUnavailable) {
+		t.Errorf("Expected ErrSnapshotsUnavailable without a workspace PVC, got %v", err)
+	}
+}
+
+func TestCheckWorkspaceSnapshots(t *testing.T) {
+	ctx := context.Background()
+
+	clientset := fake.NewSimpleClientset()
+	service, _ := newSnapshotTestService(clientset)
+	if err := service.CheckWorkspaceSnapshots(ctx); !errors.Is(err, ErrSnapshotsUnavailable) {
+		t.Errorf("Expected ErrSnapshotsUnavailable without the VolumeSnapshot CRDs, got %v", err)
+	}
+
+	clientset.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "snapshot.storage.k8s.io/v1",
+		APIResources: []metav1.APIResource{{Name: "volumesnapshots", Kind: "VolumeSnapshot", Namespaced: true}},
+	}}
+	if err := service.CheckWorkspaceSnapshots(ctx); err != nil {
+		t.Errorf("Expected snapshots to be available, got %v", err)
+	}
+
+	service.dynamicClient = nil
+	if err := service.CheckWorkspaceSnapshots(ctx); !errors.Is(err, ErrSnapshotsUnavailable) {
+		t.Errorf("Expected ErrSnapshotsUnavailable without a dynamic client, got %v", err)
+	}
+}
+
+func TestListWorkspaceSnapshots(t *testing.T) {
+	projectID := uuid.New()
+	now := time.Now().Truncate(time.Second)
+	service, _ := newSnapshotTestService(fake.NewSimpleClientset(),
+		testVolumeSnapshot("snapshot-old", projectID, SnapshotTriggerManual, true, now.Add(-time.Hour)),
+		testVolumeSnapshot("snapshot-new", projectID, SnapshotTriggerTask, false, now),
+		testVolumeSnapshot("snapshot-other", uuid.New(), SnapshotTriggerManual, true, now),
+	)
+
+	snapshots, err := service.ListWorkspaceSnapshots(context.Background(), &model.Project{ID: projectID})
+	if err != nil {
+		t.Fatalf("ListWorkspaceSnapshots failed: %v", err)
+	}
+
+	if len(snapshots) != 2 {
+		t.Fatalf("Expected 2 snapshots of the project, got %d", len(snapshots))
+	}
+	if snapshots[0].Name != "snapshot-new" || snapshots[1].Name != "snapshot-old" {
+		t.Errorf("Expected newest snapshot first, got %s then %s", snapshots[0].Name, snapshots[1].Name)
+	}
+	if snapshots[0].ReadyToUse || !snapshots[1].ReadyToUse {
+		t.Errorf("Unexpected readiness %v and %v", snapshots[0].ReadyToUse, snapshots[1].ReadyToUse)
+	}
+	if snapshots[1].RestoreSize != "2Gi" || !snapshots[1].CreatedAt.Equal(now.Add(-time.Hour)) {
+		t.Errorf("Unexpected snapshot %+v", snapshots[1])
+	}
+}
+
+func TestDeleteWorkspaceSnapshot(t *testing.T) {
+	projectID := uuid.New()
+	otherID := uuid.New()
+	service, dynamicClient := newSnapshotTestService(fake.NewSimpleClientset(),
+		testVolumeSnapshot("snapshot-mine", projectID, SnapshotTriggerManual, true, time.Now()),
+		testVolumeSnapshot("snapshot-other", otherID, SnapshotTriggerManual, true, time.Now()),
+	)
+	snapshots := dynamicClient.Resource(volumeSnapshotGVR).Namespace(snapshotTestNamespace)
+
+	ctx := context.Background()
+	project := &model.Project{ID: projectID}
+	if err := service.DeleteWorkspaceSnapshot(ctx, project, "snapshot-other"); !errors.Is(err, ErrSnapshotNotFound) {
+		t.Errorf("Expected ErrSnapshotNotFound for another project's snapshot, got %v", err)
+	}
+	if _, err := snapshots.Get(ctx, "snapshot-other", metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected the other project's snapshot to be kept: %v", err)
+	}
+
+	if err := service.DeleteWorkspaceSnapshot(ctx, project, "snapshot-mine"); err != nil {
+		t.Fatalf("DeleteWorkspaceSnapshot failed: %v", err)
+	}
+	if _, err := snapshots.Get(ctx, "snapshot-mine", metav1.GetOptions{}); err == nil {
+		t.Error("Expected snapshot to be deleted")
+	}
+}
+
+func TestRestoreWorkspaceSnapshot(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	project := &model.Project{ID: uuid.New(), UserID: uuid.New(), Name: "test-project", StorageSize: "1Gi", StorageClass: "fast-ssd"}
+	service, _ := newSnapshotTestService(clientset,
+		testVolumeSnapshot("snapshot-ready", project.ID, SnapshotTriggerTask, true, time.Now()),
+		testVolumeSnapshot("snapshot-pending", project.ID, SnapshotTriggerManual, false, time.Now()),
+	)
+
+	ctx := context.Background()
+	if err := service.CreateProjectPod(ctx, project); err != nil {
+		t.Fatalf("CreateProjectPod failed: %v", err)
+	}
+	previousPVC := project.WorkspacePVCName
+
+	if err := service.RestoreWorkspaceSnapshot(ctx, project, "snapshot-pending", discardProject); !errors.Is(err, ErrSnapshotNotReady) {
+		t.Errorf("Expected ErrSnapshotNotReady, got %v", err)
+	}
+	if err := service.RestoreWorkspaceSnapshot(ctx, project, "snapshot-missing", discardProject); !errors.Is(err, ErrSnapshotNotFound) {
+		t.Errorf("Expected ErrSnapshotNotFound, got %v", err)
+	}
+
+	if err := service.RestoreWorkspaceSnapshot(ctx, project, "snapshot-ready", discardProject); err != nil {
+		t.Fatalf("RestoreWorkspaceSnapshot failed: %v", err)
+	}
+	if !strings.HasPrefix(project.WorkspacePVCName, previousPVC+"-") {
+		t.Fatalf("Expected project to move to a fresh PVC, got %s", project.WorkspacePVCName)
+	}
+
+	pvc, err := clientset.CoreV1().PersistentVolumeClaims(snapshotTestNamespace).Get(ctx, project.WorkspacePVCName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get restored PVC: %v", err)
+	}
+	if pvc.Spec.DataSource == nil || pvc.Spec.DataSource.Kind != "VolumeSnapshot" || pvc.Spec.DataSource.Name != "snapshot-ready" {
+		t.Errorf("Expected PVC restored from snapshot-ready, got %+v", pvc.Spec.DataSource)
+	}
+	if storage := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; storage.String() != "2Gi" {
+		t.Errorf("Expected PVC to be as large as the snapshot, got %s", storage.String())
+	}
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName != "fast-ssd" {
+		t.Errorf("Expected storage class fast-ssd, got %v", pvc.Spec.StorageClassName)
+	}
+	if _, err := clientset.CoreV1().PersistentVolumeClaims(snapshotTestNamespace).Get(ctx, previousPVC, metav1.GetOptions{}); err == nil {
+		t.Error("Expected the replaced PVC to be deleted")
+	}
+
+	pod, err := clientset.CoreV1().Pods(snapshotTestNamespace).Get(ctx, project.PodName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected pod to be recreated: %v", err)
+	}
+	if claim := podWorkspaceClaim(pod); claim != project.WorkspacePVCName {
+		t.Errorf("Expected pod to mount %s, got %s", project.WorkspacePVCName, claim)
+	}
+}
+
+func TestRestoreWorkspaceSnapshot_Hibernated(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	project := &model.Project{ID: uuid.New(), UserID: uuid.New(), Name: "test-project"}
+	service, _ := newSnapshotTestService(clientset,
+		testVolumeSnapshot("snapshot-ready", project.ID, SnapshotTriggerManual, true, time.Now()),
+	)
+
+	ctx := context.Background()
+	if err := service.CreateProjectPod(ctx, project); err != nil {
+		t.Fatalf("CreateProjectPod failed: %v", err)
+	}
+	if err := service.StopProjectPod(ctx, project.PodName, project.PodNamespace); err != nil {
+		t.Fatalf("StopProjectPod failed: %v", err)
+	}
+
+	if err := service.RestoreWorkspaceSnapshot(ctx, project, "snapshot-ready", discardProject); err != nil {
+		t.Fatalf("RestoreWorkspaceSnapshot failed: %v", err)
+	}
+
+	if _, err := clientset.CoreV1().PersistentVolumeClaims(snapshotTestNamespace).Get(ctx, project.WorkspacePVCName, metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected restored PVC to exist: %v", err)
+	}
+	if _, err := clientset.CoreV1().Pods(snapshotTestNamespace).Get(ctx, project.PodName, metav1.GetOptions{}); err == nil {
+		t.Error("Expected hibernated project to stay without a pod")
+	}
+}
+
+func TestDeleteProjectPod_RestoredWorkspace(t *testing.T) {
+	projectID := uuid.New()
+	otherID := uuid.New()
+	pvcs := []runtime.Object{
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: generatePVCName(projectID) + "-x7k2p", Namespace: snapshotTestNamespace}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: generatePVCName(otherID), Namespace: snapshotTestNamespace}},
+	}
+	clientset := fake.NewSimpleClientset(pvcs...)
+	service, dynamicClient := newSnapshotTestService(clientset,
+		testVolumeSnapshot(generateSnapshotName(projectID), projectID, SnapshotTriggerManual, true, time.Now()),
+		testVolumeSnapshot(generateSnapshotName(otherID), otherID, SnapshotTriggerManual, true, time.Now()),
+	)
+
+	ctx := context.Background()
+	if err := service.DeleteProjectPod(ctx, generatePodName(projectID), snapshotTestNamespace); err != nil {
+		t.Fatalf("DeleteProjectPod failed: %v", err)
+	}
+
+	remaining, err := clientset.CoreV1().PersistentVolumeClaims(snapshotTestNamespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Failed to list PVCs: %v", err)
+	}
+	if len(remaining.Items) != 1 || remaining.Items[0].Name != generatePVCName(otherID) {
+		t.Errorf("Expected only the other project's PVC to remain, got %v", remaining.Items)
+	}
+
+	snapshots, err := dynamicClient.Resource(volumeSnapshotGVR).Namespace(snapshotTestNamespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Failed to list volume snapshots: %v", err)
+	}
+	if len(snapshots.Items) != 1 || snapshots.Items[0].GetLabels()[projectIDLabel] != otherID.String() {
+		t.Errorf("Expected only the other project's snapshot to remain, got %d", len(snapshots.Items))
+	}
+}
+
+func podWorkspaceClaim(pod *corev1.Pod) string {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim != nil {
+			return volume.PersistentVolumeClaim.ClaimName
+		}
+	}
+	return ""
+}
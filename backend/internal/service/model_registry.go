@@ -141,6 +141,15 @@ func GetModelMaxTokens(provider, name string) int {
 	return 0
 }
 
+// GetModelCost prices the tokens of a model call in USD; models without pricing cost nothing
+func GetModelCost(provider, name string, inputTokens, outputTokens int64) float64 {
+	model := GetModelInfo(provider, name)
+	if model == nil {
+		return 0
+	}
+	return (float64(inputTokens)*model.Pricing["input"] + float64(outputTokens)*model.Pricing["output"]) / 1_000_000
+}
+
 // GetProviderModels returns all models for a specific provider
 func GetProviderModels(provider string) []*ModelInfo {
 	models := []*ModelInfo{}
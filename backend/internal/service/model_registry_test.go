@@ -136,6 +136,19 @@ func TestGetModelMaxTokens_InvalidModel(t *testing.T) {
 	assert.Equal(t, 0, maxTokens)
 }
 
+// Test GetModelCost function
+
+func TestGetModelCost_OpenAI_GPT4o(t *testing.T) {
+	// 2.50 per 1M input tokens, 10.00 per 1M output tokens
+	cost := GetModelCost("openai", "gpt-4o", 200000, 50000)
+	assert.InDelta(t, 1.0, cost, 1e-9)
+}
+
+func TestGetModelCost_InvalidModel(t *testing.T) {
+	cost := GetModelCost("openai", "invalid-model", 200000, 50000)
+	assert.Equal(t, 0.0, cost)
+}
+
 // Test GetProviderModels function
 
 func TestGetProviderModels_OpenAI(t *testing.T) {
@@ -0,0 +1,351 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/npinot/vibe/backend/internal/model"
+	"github.com/npinot/vibe/backend/internal/repository"
+)
+
+const (
+	// projectPodSelector selects the pods, PVCs and secrets created for projects
+	projectPodSelector = "app=opencode-project"
+
+	// projectIDLabel carries the ID of the project owning a pod, PVC or secret
+	projectIDLabel = "project-id"
+
+	// vanishedPodGracePeriod delays recreating a deleted pod, so a project being hibernated or deleted
+	// has its new status stored before the reconciler looks at it
+	vanishedPodGracePeriod = 30 * time.Second
+)
+
+// PodReconciler keeps the pod status of projects in sync with their pods. It watches the project pods
+// and PVCs through informers, stores pod phases and container errors (CrashLoopBackOff, image pull
+// failures, OOMKilled), recreates the pods of ready projects that vanished and deletes the pods, PVCs
+// and credential secrets of projects that no longer exist.
+type PodReconciler struct {
+	clientset   kubernetes.Interface
+	namespace   string
+	projectRepo repository.ProjectRepository
+	k8sService  KubernetesService
+	interval    time.Duration
+	gracePeriod time.Duration
+
+	informers informers.SharedInformerFactory
+	pods      corelisters.PodLister
+	pvcs      corelisters.PersistentVolumeClaimLister
+	synced    []cache.InformerSynced
+	queue     workqueue.TypedRateLimitingInterface[string]
+}
+
+func NewPodReconciler(clientset kubernetes.Interface, namespace string, projectRepo repository.ProjectRepository, k8sService KubernetesService, interval time.Duration) *PodReconciler {
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, interval,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.LabelSelector = projectPodSelector
+		}),
+	)
+	podInformer := factory.Core().V1().Pods()
+	pvcInformer := factory.Core().V1().PersistentVolumeClaims()
+
+	r := &PodReconciler{
+		clientset:   clientset,
+		namespace:   namespace,
+		projectRepo: projectRepo,
+		k8sService:  k8sService,
+		interval:    interval,
+		gracePeriod: vanishedPodGracePeriod,
+		informers:   factory,
+		pods:        podInformer.Lister(),
+		pvcs:        pvcInformer.Lister(),
+		synced:      []cache.InformerSynced{podInformer.Informer().HasSynced, pvcInformer.Informer().HasSynced},
+		queue: workqueue.NewTypedRateLimitingQueueWithConfig(
+			workqueue.DefaultTypedControllerRateLimiter[string](),
+			workqueue.TypedRateLimitingQueueConfig[string]{Name: "project-pods"},
+		),
+	}
+
+	_, _ = podInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { r.enqueue(obj, 0) },
+		UpdateFunc: func(_, obj interface{}) { r.enqueue(obj, 0) },
+		DeleteFunc: func(obj interface{}) { r.enqueue(obj, r.gracePeriod) },
+	})
+	_, _ = pvcInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { r.enqueue(obj, 0) },
+		UpdateFunc: func(_, obj interface{}) { r.enqueue(obj, 0) },
+	})
+
+	return r
+}
+
+// Run watches the project pods until ctx is cancelled. The ready projects are checked at startup and
+// then on every interval, to recreate pods deleted while the backend was not watching.
+func (r *PodReconciler) Run(ctx context.Context) {
+	defer r.queue.ShutDown()
+
+	r.informers.Start(ctx.Done())
+	defer r.informers.Shutdown()
+	if !cache.WaitForCacheSync(ctx.Done(), r.synced...) {
+		log.Printf("[PodReconciler] Failed to sync pod informers")
+		return
+	}
+
+	workerDone := make(chan struct{})
+	go func() {
+		defer close(workerDone)
+		for r.processNextItem(ctx) {
+		}
+	}()
+	defer func() {
+		r.queue.ShutDown()
+		<-workerDone
+	}()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.enqueueRunningProjects(ctx); err != nil {
+			log.Printf("[PodReconciler] %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// enqueue queues the project owning a pod or PVC, after delay when set
+func (r *PodReconciler) enqueue(obj interface{}, delay time.Duration) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	object, err := meta.Accessor(obj)
+	if err != nil {
+		return
+	}
+
+	projectID := object.GetLabels()[projectIDLabel]
+	if _, err := uuid.Parse(projectID); err != nil {
+		return
+	}
+
+	if delay > 0 {
+		r.queue.AddAfter(projectID, delay)
+		return
+	}
+	r.queue.Add(projectID)
+}
+
+func (r *PodReconciler) enqueueRunningProjects(ctx context.Context) error {
+	projects, err := r.projectRepo.FindRunningProjects(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to find running projects: %w", err)
+	}
+
+	for _, project := range projects {
+		r.queue.Add(project.ID.String())
+	}
+	return nil
+}
+
+func (r *PodReconciler) processNextItem(ctx context.Context) bool {
+	key, shutdown := r.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer r.queue.Done(key)
+
+	projectID, err := uuid.Parse(key)
+	if err != nil {
+		r.queue.Forget(key)
+		return true
+	}
+
+	if err := r.reconcile(ctx, projectID); err != nil {
+		log.Printf("[PodReconciler] Failed to reconcile project %s: %v", projectID, err)
+		r.queue.AddRateLimited(key)
+		return true
+	}
+
+	r.queue.Forget(key)
+	return true
+}
+
+// reconcile brings the pod of a project and its stored status in line with each other
+func (r *PodReconciler) reconcile(ctx context.Context, projectID uuid.UUID) error {
+	project, err := r.projectRepo.FindByID(ctx, projectID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Soft-deleted projects are not found either
+		return r.collectGarbage(ctx, projectID)
+	}
+	if err != nil {
+		return err
+	}
+
+	if project.PodName == "" || project.PodNamespace != r.namespace {
+		return nil
+	}
+
+	pod, err := r.pods.Pods(r.namespace).Get(project.PodName)
+	if k8serrors.IsNotFound(err) {
+		return r.recreatePod(ctx, project)
+	}
+	if err != nil {
+		return err
+	}
+
+	status, podError := podStatus(pod)
+	if podError == "" && project.Status == model.ProjectStatusError {
+		// Keep the error that put the project in error, such as a failed repository clone
+		podError = project.PodError
+	}
+	if status == project.PodStatus && podError == project.PodError {
+		return nil
+	}
+
+	if podError != "" && podError != project.PodError {
+		log.Printf("[PodReconciler] Pod %s of project %s: %s", pod.Name, project.ID, podError)
+	}
+	return r.projectRepo.UpdatePodStatus(ctx, project.ID, status, podError)
+}
+
+// recreatePod starts the pod of a ready project again on its workspace PVC. Projects not expected
+// to have a pod, such as hibernated projects, only have their pod status cleared.
+func (r *PodReconciler) recreatePod(ctx context.Context, project *model.Project) error {
+	if project.Status != model.ProjectStatusReady {
+		if project.PodStatus == "" {
+			return nil
+		}
+		return r.projectRepo.UpdatePodStatus(ctx, project.ID, "", project.PodError)
+	}
+
+	log.Printf("[PodReconciler] Pod %s of project %s vanished, recreating it", project.PodName, project.ID)
+	if err := r.k8sService.StartProjectPod(ctx, project); err != nil {
+		return fmt.Errorf("failed to recreate pod: %w", err)
+	}
+
+	return r.projectRepo.UpdatePodStatus(ctx, project.ID, string(corev1.PodPending), "")
+}
+
+// collectGarbage deletes the pods, PVCs and credential secrets left behind by a deleted project
+func (r *PodReconciler) collectGarbage(ctx context.Context, projectID uuid.UUID) error {
+	selector := labels.SelectorFromSet(labels.Set{projectIDLabel: projectID.String()})
+
+	pods, err := r.pods.Pods(r.namespace).List(selector)
+	if err != nil {
+		return err
+	}
+	for _, pod := range pods {
+		log.Printf("[PodReconciler] Deleting pod %s of deleted project %s", pod.Name, projectID)
+		err := r.clientset.CoreV1().Pods(r.namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete pod: %w", err)
+		}
+	}
+
+	pvcs, err := r.pvcs.PersistentVolumeClaims(r.namespace).List(selector)
+	if err != nil {
+		return err
+	}
+	for _, pvc := range pvcs {
+		log.Printf("[PodReconciler] Deleting PVC %s of deleted project %s", pvc.Name, projectID)
+		err := r.clientset.CoreV1().PersistentVolumeClaims(r.namespace).Delete(ctx, pvc.Name, metav1.DeleteOptions{})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete PVC: %w", err)
+		}
+	}
+
+	// Secrets are not watched, they only ever exist next to a pod or PVC
+	if len(pods) == 0 && len(pvcs) == 0 {
+		return nil
+	}
+	secrets, err := r.clientset.CoreV1().Secrets(r.namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return fmt.Errorf("failed to list repository credential secrets: %w", err)
+	}
+	for _, secret := range secrets.Items {
+		err := r.clientset.CoreV1().Secrets(r.namespace).Delete(ctx, secret.Name, metav1.DeleteOptions{})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete repository credential secret: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// podStatus returns the phase of a pod and what keeps it from running, if anything. Failures of the
+// repo-clone init container are left out, they are reported through the clone status.
+func podStatus(pod *corev1.Pod) (status string, podError string) {
+	status = string(pod.Status.Phase)
+	if pod.DeletionTimestamp != nil {
+		status = "Terminating"
+	}
+
+	if pod.Status.Phase == corev1.PodFailed && pod.Status.Reason != "" {
+		return status, fmt.Sprintf("pod %s: %s", pod.Status.Reason, pod.Status.Message)
+	}
+
+	for _, cs := range pod.Status.InitContainerStatuses {
+		if cs.Name == repoCloneContainerName {
+			continue
+		}
+		if msg := containerError(cs); msg != "" {
+			return status, msg
+		}
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		if msg := containerError(cs); msg != "" {
+			return status, msg
+		}
+	}
+
+	return status, ""
+}
+
+// containerError describes a container that is crash looping, cannot pull its image or ran out of memory
+func containerError(cs corev1.ContainerStatus) string {
+	if t := cs.State.Terminated; t != nil && t.Reason == "OOMKilled" {
+		return fmt.Sprintf("container %s was OOMKilled", cs.Name)
+	}
+
+	w := cs.State.Waiting
+	if w == nil {
+		return ""
+	}
+
+	switch w.Reason {
+	case "CrashLoopBackOff":
+		t := cs.LastTerminationState.Terminated
+		if t == nil {
+			return fmt.Sprintf("container %s is in CrashLoopBackOff", cs.Name)
+		}
+		if t.Reason == "OOMKilled" {
+			return fmt.Sprintf("container %s was OOMKilled and is in CrashLoopBackOff", cs.Name)
+		}
+		return fmt.Sprintf("container %s is in CrashLoopBackOff (%s)", cs.Name, terminationMessage(t))
+	case "ImagePullBackOff", "ErrImagePull", "InvalidImageName", "CreateContainerConfigError":
+		return fmt.Sprintf("container %s: %s: %s", cs.Name, w.Reason, w.Message)
+	}
+
+	return ""
+}
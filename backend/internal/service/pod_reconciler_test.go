@@ -0,0 +1,330 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/npinot/vibe/backend/internal/model"
+)
+
+func setupPodReconcilerTest() (*PodReconciler, *kubernetesService, *fake.Clientset, *MockProjectRepository) {
+	clientset := fake.NewSimpleClientset()
+	k8sService := &kubernetesService{
+		clientset: clientset,
+		namespace: "vibe",
+		config: &KubernetesConfig{
+			Namespace:         "vibe",
+			OpenCodeImage:     "opencode:latest",
+			FileBrowserImage:  "file-browser:latest",
+			SessionProxyImage: "session-proxy:latest",
+			GitCloneImage:     "git:latest",
+			WorkspaceSize:     "1Gi",
+			CPULimit:          "1000m",
+			MemoryLimit:       "1Gi",
+			CPURequest:        "100m",
+			MemoryRequest:     "256Mi",
+		},
+	}
+	projectRepo := new(MockProjectRepository)
+
+	reconciler := NewPodReconciler(clientset, "vibe", projectRepo, k8sService, time.Minute)
+	return reconciler, k8sService, clientset, projectRepo
+}
+
+// createReconcilerProject creates the pod, PVC and credential secret of a ready project
+func createReconcilerProject(t *testing.T, k8sService *kubernetesService) *model.Project {
+	project := &model.Project{
+		ID:             uuid.New(),
+		UserID:         uuid.New(),
+		Status:         model.ProjectStatusReady,
+		RepoURL:        "https://github.com/test/private.git",
+		RepoCredential: "ghp_secret",
+	}
+	require.NoError(t, k8sService.CreateProjectPod(context.Background(), project))
+	project.RepoCredential = ""
+	project.RepoCredentialEncrypted = []byte("encrypted")
+	project.PodStatus = ""
+	return project
+}
+
+func setPodStatus(t *testing.T, clientset *fake.Clientset, podName string, status corev1.PodStatus) {
+	ctx := context.Background()
+	pod, err := clientset.CoreV1().Pods("vibe").Get(ctx, podName, metav1.GetOptions{})
+	require.NoError(t, err)
+	pod.Status = status
+	_, err = clientset.CoreV1().Pods("vibe").UpdateStatus(ctx, pod, metav1.UpdateOptions{})
+	require.NoError(t, err)
+}
+
+// syncInformers starts the informers of the reconciler and waits for them to list the cluster
+func syncInformers(t *testing.T, reconciler *PodReconciler) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	reconciler.informers.Start(ctx.Done())
+	for typ, synced := range reconciler.informers.WaitForCacheSync(ctx.Done()) {
+		require.True(t, synced, "informer for %v did not sync", typ)
+	}
+}
+
+func crashLoopStatus(lastTermination *corev1.ContainerStateTerminated) corev1.PodStatus {
+	return corev1.PodStatus{
+		Phase: corev1.PodRunning,
+		ContainerStatuses: []corev1.ContainerStatus{
+			{Name: "file-browser", Ready: true, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+			{
+				Name:                 "opencode-server",
+				State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+				LastTerminationState: corev1.ContainerState{Terminated: lastTermination},
+			},
+		},
+	}
+}
+
+func TestPodStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    corev1.PodStatus
+		wantPhase string
+		wantError string
+	}{
+		{
+			name: "running pod",
+			status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "opencode-server", Ready: true, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+				},
+			},
+			wantPhase: "Running",
+		},
+		{
+			name:      "crash looping container",
+			status:    crashLoopStatus(&corev1.ContainerStateTerminated{ExitCode: 1, Message: "panic: bad config"}),
+			wantPhase: "Running",
+			wantError: "container opencode-server is in CrashLoopBackOff (exit code 1: panic: bad config)",
+		},
+		{
+			name:      "container restarting after running out of memory",
+			status:    crashLoopStatus(&corev1.ContainerStateTerminated{ExitCode: 137, Reason: "OOMKilled"}),
+			wantPhase: "Running",
+			wantError: "container opencode-server was OOMKilled and is in CrashLoopBackOff",
+		},
+		{
+			name: "container killed for running out of memory",
+			status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "file-browser", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 137, Reason: "OOMKilled"}}},
+				},
+			},
+			wantPhase: "Running",
+			wantError: "container file-browser was OOMKilled",
+		},
+		{
+			name: "image that cannot be pulled",
+			status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "session-proxy", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+						Reason:  "ImagePullBackOff",
+						Message: `Back-off pulling image "session-proxy:missing"`,
+					}}},
+				},
+			},
+			wantPhase: "Pending",
+			wantError: `container session-proxy: ImagePullBackOff: Back-off pulling image "session-proxy:missing"`,
+		},
+		{
+			name: "failing repository clone is left to the clone status",
+			status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				InitContainerStatuses: []corev1.ContainerStatus{
+					{Name: repoCloneContainerName, State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}},
+				},
+			},
+			wantPhase: "Pending",
+		},
+		{
+			name:      "evicted pod",
+			status:    corev1.PodStatus{Phase: corev1.PodFailed, Reason: "Evicted", Message: "The node was low on resource: memory."},
+			wantPhase: "Failed",
+			wantError: "pod Evicted: The node was low on resource: memory.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			phase, podError := podStatus(&corev1.Pod{Status: tt.status})
+			assert.Equal(t, tt.wantPhase, phase)
+			assert.Equal(t, tt.wantError, podError)
+		})
+	}
+}
+
+func TestPodReconciler_Reconcile(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("stores the error of a crash looping pod", func(t *testing.T) {
+		reconciler, k8sService, clientset, projectRepo := setupPodReconcilerTest()
+		project := createReconcilerProject(t, k8sService)
+		setPodStatus(t, clientset, project.PodName, crashLoopStatus(&corev1.ContainerStateTerminated{ExitCode: 137, Reason: "OOMKilled"}))
+		syncInformers(t, reconciler)
+
+		projectRepo.On("FindByID", ctx, project.ID).Return(project, nil)
+		projectRepo.On("UpdatePodStatus", ctx, project.ID, "Running", "container opencode-server was OOMKilled and is in CrashLoopBackOff").Return(nil)
+
+		require.NoError(t, reconciler.reconcile(ctx, project.ID))
+		projectRepo.AssertExpectations(t)
+	})
+
+	t.Run("does not write an unchanged status", func(t *testing.T) {
+		reconciler, k8sService, clientset, projectRepo := setupPodReconcilerTest()
+		project := createReconcilerProject(t, k8sService)
+		setPodStatus(t, clientset, project.PodName, corev1.PodStatus{Phase: corev1.PodRunning})
+		syncInformers(t, reconciler)
+
+		project.PodStatus = "Running"
+		projectRepo.On("FindByID", ctx, project.ID).Return(project, nil)
+
+		require.NoError(t, reconciler.reconcile(ctx, project.ID))
+		projectRepo.AssertNotCalled(t, "UpdatePodStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("clears the error of a recovered pod but keeps the error of a failed project", func(t *testing.T) {
+		reconciler, k8sService, clientset, projectRepo := setupPodReconcilerTest()
+		project := createReconcilerProject(t, k8sService)
+		setPodStatus(t, clientset, project.PodName, corev1.PodStatus{Phase: corev1.PodRunning})
+		syncInformers(t, reconciler)
+
+		project.PodStatus = "Running"
+		project.PodError = "container opencode-server is in CrashLoopBackOff"
+		projectRepo.On("FindByID", ctx, project.ID).Return(project, nil).Once()
+		projectRepo.On("UpdatePodStatus", ctx, project.ID, "Running", "").Return(nil).Once()
+		require.NoError(t, reconciler.reconcile(ctx, project.ID))
+
+		failed := *project
+		failed.Status = model.ProjectStatusError
+		failed.PodError = "Repository clone failed: exit code 128: repository not found"
+		projectRepo.On("FindByID", ctx, project.ID).Return(&failed, nil).Once()
+		require.NoError(t, reconciler.reconcile(ctx, project.ID))
+
+		projectRepo.AssertExpectations(t)
+		projectRepo.AssertNumberOfCalls(t, "UpdatePodStatus", 1)
+	})
+
+	t.Run("recreates the vanished pod of a ready project", func(t *testing.T) {
+		reconciler, k8sService, clientset, projectRepo := setupPodReconcilerTest()
+		project := createReconcilerProject(t, k8sService)
+		require.NoError(t, clientset.CoreV1().Pods("vibe").Delete(ctx, project.PodName, metav1.DeleteOptions{}))
+		syncInformers(t, reconciler)
+
+		projectRepo.On("FindByID", ctx, project.ID).Return(project, nil)
+		projectRepo.On("UpdatePodStatus", ctx, project.ID, "Pending", "").Return(nil)
+
+		require.NoError(t, reconciler.reconcile(ctx, project.ID))
+
+		pod, err := clientset.CoreV1().Pods("vibe").Get(ctx, project.PodName, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, project.WorkspacePVCName, pod.Spec.Volumes[0].PersistentVolumeClaim.ClaimName)
+		projectRepo.AssertExpectations(t)
+	})
+
+	t.Run("leaves the pod of a hibernated project deleted", func(t *testing.T) {
+		reconciler, k8sService, clientset, projectRepo := setupPodReconcilerTest()
+		project := createReconcilerProject(t, k8sService)
+		require.NoError(t, clientset.CoreV1().Pods("vibe").Delete(ctx, project.PodName, metav1.DeleteOptions{}))
+		syncInformers(t, reconciler)
+
+		project.Status = model.ProjectStatusHibernated
+		project.PodStatus = "Running"
+		projectRepo.On("FindByID", ctx, project.ID).Return(project, nil)
+		projectRepo.On("UpdatePodStatus", ctx, project.ID, "", "").Return(nil)
+
+		require.NoError(t, reconciler.reconcile(ctx, project.ID))
+
+		_, err := clientset.CoreV1().Pods("vibe").Get(ctx, project.PodName, metav1.GetOptions{})
+		assert.Error(t, err)
+		projectRepo.AssertExpectations(t)
+	})
+
+	t.Run("deletes the resources of a deleted project", func(t *testing.T) {
+		reconciler, k8sService, clientset, projectRepo := setupPodReconcilerTest()
+		deleted := createReconcilerProject(t, k8sService)
+		kept := createReconcilerProject(t, k8sService)
+		syncInformers(t, reconciler)
+
+		projectRepo.On("FindByID", ctx, deleted.ID).Return(nil, gorm.ErrRecordNotFound)
+
+		require.NoError(t, reconciler.reconcile(ctx, deleted.ID))
+
+		_, err := clientset.CoreV1().Pods("vibe").Get(ctx, deleted.PodName, metav1.GetOptions{})
+		assert.Error(t, err, "expected pod to be deleted")
+		_, err = clientset.CoreV1().PersistentVolumeClaims("vibe").Get(ctx, deleted.WorkspacePVCName, metav1.GetOptions{})
+		assert.Error(t, err, "expected PVC to be deleted")
+		_, err = clientset.CoreV1().Secrets("vibe").Get(ctx, generateRepoSecretName(deleted.ID), metav1.GetOptions{})
+		assert.Error(t, err, "expected credential secret to be deleted")
+
+		_, err = clientset.CoreV1().Pods("vibe").Get(ctx, kept.PodName, metav1.GetOptions{})
+		assert.NoError(t, err)
+		_, err = clientset.CoreV1().PersistentVolumeClaims("vibe").Get(ctx, kept.WorkspacePVCName, metav1.GetOptions{})
+		assert.NoError(t, err)
+		_, err = clientset.CoreV1().Secrets("vibe").Get(ctx, generateRepoSecretName(kept.ID), metav1.GetOptions{})
+		assert.NoError(t, err)
+	})
+}
+
+func TestPodReconciler_Run(t *testing.T) {
+	reconciler, k8sService, clientset, projectRepo := setupPodReconcilerTest()
+	project := createReconcilerProject(t, k8sService)
+	project.PodStatus = "Pending"
+
+	projectRepo.On("FindRunningProjects", mock.Anything).Return([]model.Project{*project}, nil)
+	projectRepo.On("FindByID", mock.Anything, project.ID).Return(project, nil)
+	podErrors := make(chan string, 10)
+	projectRepo.On("UpdatePodStatus", mock.Anything, project.ID, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { podErrors <- args.String(3) }).Return(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		reconciler.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// Pod changes are picked up from the informer
+	setPodStatus(t, clientset, project.PodName, corev1.PodStatus{
+		Phase: corev1.PodPending,
+		ContainerStatuses: []corev1.ContainerStatus{
+			{Name: "opencode-server", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+				Reason:  "ErrImagePull",
+				Message: "manifest unknown",
+			}}},
+		},
+	})
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case podError := <-podErrors:
+			if podError == "container opencode-server: ErrImagePull: manifest unknown" {
+				return
+			}
+		case <-timeout:
+			t.Fatal("pod error was not stored")
+		}
+	}
+}
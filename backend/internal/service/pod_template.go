@@ -1,11 +1,15 @@
 package service
 
 import (
+	"strconv"
+
 	"github.com/google/uuid"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/npinot/vibe/backend/internal/model"
 )
 
 // buildProjectPodSpec creates a pod specification with 3 containers and shared PVC
@@ -185,6 +189,17 @@ func buildProjectPodSpec(podName, namespace, pvcName string, projectID uuid.UUID
 							Name:  "OPENCODE_URL",
 							Value: "http://localhost:3003",
 						},
+						{
+							Name: "OPENCODE_SHARED_SECRET",
+							ValueFrom: &corev1.EnvVarSource{
+								SecretKeyRef: &corev1.SecretKeySelector{
+									LocalObjectReference: corev1.LocalObjectReference{
+										Name: "app-secrets",
+									},
+									Key: "OPENCODE_SHARED_SECRET",
+								},
+							},
+						},
 					},
 				},
 			},
@@ -204,6 +219,33 @@ func buildProjectPodSpec(podName, namespace, pvcName string, projectID uuid.UUID
 	return pod
 }
 
+// applyProjectResources gives the OpenCode server and session proxy containers the resources of the
+// project's resource profile; projects without stored resources keep the configured ones
+func applyProjectResources(pod *corev1.Pod, project *model.Project) {
+	for i := range pod.Spec.Containers {
+		container := &pod.Spec.Containers[i]
+		if container.Name != "opencode-server" && container.Name != "session-proxy" {
+			continue
+		}
+
+		// The containers share the configured resource lists, so each gets its own copy
+		limits := container.Resources.Limits.DeepCopy()
+		requests := container.Resources.Requests.DeepCopy()
+		setResourceQuantity(limits, corev1.ResourceCPU, project.CPULimit)
+		setResourceQuantity(limits, corev1.ResourceMemory, project.MemoryLimit)
+		setResourceQuantity(requests, corev1.ResourceCPU, project.CPURequest)
+		setResourceQuantity(requests, corev1.ResourceMemory, project.MemoryRequest)
+		container.Resources.Limits = limits
+		container.Resources.Requests = requests
+	}
+}
+
+func setResourceQuantity(list corev1.ResourceList, name corev1.ResourceName, value string) {
+	if value != "" {
+		list[name] = resource.MustParse(value)
+	}
+}
+
 // buildPVCSpec creates a PersistentVolumeClaim specification
 func buildPVCSpec(pvcName, namespace, size string, projectID uuid.UUID) *corev1.PersistentVolumeClaim {
 	pvc := &corev1.PersistentVolumeClaim{
@@ -232,3 +274,129 @@ func buildPVCSpec(pvcName, namespace, size string, projectID uuid.UUID) *corev1.
 
 	return pvc
 }
+
+// defaultGitCloneImage is used for the repo-clone init container when no image is configured
+const defaultGitCloneImage = "alpine/git:2.45.2"
+
+// repoCloneScript clones REPO_URL into /workspace. It is a no-op when the
+// workspace already holds a repository (e.g. the pod was recreated on an
+// existing PVC). The clone goes through a temporary directory because the
+// volume root may contain lost+found. Failures are written to the
+// termination log so they surface in the init container status.
+const repoCloneScript = `set -eu
+fail() { echo "$1" | tee /dev/termination-log >&2; exit 1; }
+
+if [ -d /workspace/.git ]; then
+  echo "workspace already contains a repository, skipping clone"
+  exit 0
+fi
+
+set -- clone --progress --no-checkout
+if [ -n "${REPO_REF:-}" ]; then set -- "$@" --branch "$REPO_REF"; fi
+if [ "${REPO_DEPTH:-0}" -gt 0 ]; then set -- "$@" --depth "$REPO_DEPTH"; fi
+
+if [ -n "${REPO_CREDENTIAL:-}" ]; then
+  case "$REPO_URL" in
+    git@*|ssh://*)
+      printf '%s\n' "$REPO_CREDENTIAL" > /tmp/repo-key
+      chmod 600 /tmp/repo-key
+      export GIT_SSH_COMMAND="ssh -i /tmp/repo-key -o StrictHostKeyChecking=accept-new"
+      ;;
+    *)
+      auth=$(printf 'x-access-token:%s' "$REPO_CREDENTIAL" | base64 | tr -d '\n')
+      set -- -c "http.extraHeader=Authorization: Basic $auth" "$@"
+      ;;
+  esac
+fi
+
+rm -rf /workspace/.clone-tmp
+git "$@" "$REPO_URL" /workspace/.clone-tmp 2>&1 || fail "git clone of $REPO_URL failed"
+mv /workspace/.clone-tmp/.git /workspace/.git
+rm -rf /workspace/.clone-tmp /tmp/repo-key
+git -C /workspace reset --hard --quiet || fail "checkout of cloned repository failed"
+echo "repository cloned into /workspace"
+`
+
+// buildRepoCloneContainer creates the init container that clones the project repository
+func buildRepoCloneContainer(project *model.Project, credentialSecretName string, config *KubernetesConfig) corev1.Container {
+	image := config.GitCloneImage
+	if image == "" {
+		image = defaultGitCloneImage
+	}
+
+	env := []corev1.EnvVar{
+		{
+			Name:  "REPO_URL",
+			Value: project.RepoURL,
+		},
+		{
+			Name:  "REPO_REF",
+			Value: project.RepoRef,
+		},
+		{
+			Name:  "REPO_DEPTH",
+			Value: strconv.Itoa(project.RepoCloneDepth),
+		},
+	}
+
+	if credentialSecretName != "" {
+		env = append(env, buildRepoCredentialEnv(credentialSecretName))
+	}
+
+	return corev1.Container{
+		Name:                     repoCloneContainerName,
+		Image:                    image,
+		Command:                  []string{"/bin/sh", "-c", repoCloneScript},
+		Env:                      env,
+		VolumeMounts:             []corev1.VolumeMount{{Name: "workspace", MountPath: "/workspace"}},
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("50m"),
+				corev1.ResourceMemory: resource.MustParse("64Mi"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("500m"),
+				corev1.ResourceMemory: resource.MustParse("512Mi"),
+			},
+		},
+	}
+}
+
+// buildRepoCredentialEnv exposes the repository credential secret as REPO_CREDENTIAL
+func buildRepoCredentialEnv(credentialSecretName string) corev1.EnvVar {
+	optional := true
+	return corev1.EnvVar{
+		Name: "REPO_CREDENTIAL",
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: credentialSecretName,
+				},
+				Key:      repoCredentialSecretKey,
+				Optional: &optional,
+			},
+		},
+	}
+}
+
+// buildRepoCredentialSecret creates the Secret holding the repository credential
+func buildRepoCredentialSecret(secretName, namespace, credential string, projectID uuid.UUID) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secretName,
+			Namespace: namespace,
+			Labels: map[string]string{
+				"app":        "opencode-project",
+				"project-id": projectID.String(),
+			},
+			Annotations: map[string]string{
+				"project-id": projectID.String(),
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		StringData: map[string]string{
+			repoCredentialSecretKey: credential,
+		},
+	}
+}
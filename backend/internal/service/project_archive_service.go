@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/npinot/vibe/backend/internal/model"
+	"github.com/npinot/vibe/backend/internal/repository"
+)
+
+var (
+	// ErrProjectArchived is returned when modifying an archived project, which is read-only until restored
+	ErrProjectArchived    = errors.New("project is archived")
+	ErrProjectNotArchived = errors.New("project is not archived")
+
+	// ErrArchivesUnavailable is returned when no archive store or Kubernetes cluster is configured
+	ErrArchivesUnavailable = errors.New("project archives are not available")
+)
+
+// projectArchiveTimeout bounds moving a workspace between the project pod and the archive store
+const projectArchiveTimeout = 15 * time.Minute
+
+// archiveCompleteTrailer is set by the file-browser sidecar once the whole workspace is exported
+const archiveCompleteTrailer = "X-Archive-Complete"
+
+// ProjectArchiveService moves projects that are not worked on out of the cluster and back
+type ProjectArchiveService interface {
+	// ArchiveProject exports the project workspace to the archive store, then deletes the
+	// project pod and PVC and marks the project archived
+	ArchiveProject(ctx context.Context, projectID, userID uuid.UUID) (*model.Project, error)
+
+	// RestoreProject recreates the project PVC and pod, imports the workspace from the archive
+	// and marks the project ready
+	RestoreProject(ctx context.Context, projectID, userID uuid.UUID) (*model.Project, error)
+}
+
+type projectArchiveService struct {
+	projectRepo repository.ProjectRepository
+	authz       AuthorizationService
+	sessionRepo repository.SessionRepository
+	k8sService  KubernetesService
+	store       ArchiveStore
+	cipher      SecretCipher
+	httpClient  *http.Client
+	sidecarPort int
+	now         func() time.Time
+}
+
+// NewProjectArchiveService creates a project archive service. Archives are unavailable when
+// k8sService or store is nil; cipher decrypts repository credentials of restored projects.
+func NewProjectArchiveService(projectRepo repository.ProjectRepository, authz AuthorizationService, sessionRepo repository.SessionRepository, k8sService KubernetesService, store ArchiveStore, cipher SecretCipher) ProjectArchiveService {
+	return &projectArchiveService{
+		projectRepo: projectRepo,
+		authz:       authz,
+		sessionRepo: sessionRepo,
+		k8sService:  k8sService,
+		store:       store,
+		cipher:      cipher,
+		// Workspaces can be large; transfers are bounded by projectArchiveTimeout instead
+		httpClient:  &http.Client{},
+		sidecarPort: 3001,
+		now:         time.Now,
+	}
+}
+
+func (s *projectArchiveService) ArchiveProject(ctx context.Context, projectID, userID uuid.UUID) (*model.Project, error) {
+	project, err := s.ownedProject(ctx, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Moving the workspace outlives a client giving up, which would leave the project half archived
+	archiveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), projectArchiveTimeout)
+	defer cancel()
+
+	if project.Status == model.ProjectStatusArchived {
+		if project.PodName == "" {
+			return nil, ErrProjectArchived
+		}
+		// A previous archive stored the workspace but failed to delete the pod
+		return s.deleteArchivedPod(archiveCtx, project)
+	}
+	if project.PodName == "" || project.WorkspacePVCName == "" {
+		return nil, fmt.Errorf("%w: the workspace was never created", ErrProjectPodMissing)
+	}
+
+	// A running agent would keep writing to the workspace being exported
+	sessions, err := s.sessionRepo.FindActiveSessionsForProject(ctx, project.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check active sessions: %w", err)
+	}
+	if len(sessions) > 0 {
+		return nil, fmt.Errorf("%w: task %s is still running", ErrWorkspaceBusy, sessions[0].TaskID)
+	}
+
+	now := s.now()
+	key := fmt.Sprintf("projects/%s/workspace-%s.tar.gz", project.ID, now.UTC().Format("20060102T150405Z"))
+	size, err := s.exportWorkspace(archiveCtx, project, key)
+	if err != nil {
+		return nil, err
+	}
+
+	// The project is archived before its pod goes, so the pod reconciler does not recreate the pod
+	project.Status = model.ProjectStatusArchived
+	project.ArchiveKey = key
+	project.ArchiveSize = size
+	project.ArchivedAt = &now
+	if err := s.projectRepo.Update(archiveCtx, project); err != nil {
+		s.deleteArchive(archiveCtx, project.ID, key)
+		return nil, fmt.Errorf("failed to update project: %w", err)
+	}
+
+	log.Printf("[ProjectArchiveService] Archived workspace of project %s (%d bytes)", project.ID, size)
+	return s.deleteArchivedPod(archiveCtx, project)
+}
+
+// deleteArchivedPod deletes the pod, PVC and snapshots of an archived project
+func (s *projectArchiveService) deleteArchivedPod(ctx context.Context, project *model.Project) (*model.Project, error) {
+	if err := s.k8sService.DeleteProjectPod(ctx, project.PodName, project.PodNamespace); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPodDeletionFailed, err)
+	}
+
+	project.PodName = ""
+	project.PodStatus = ""
+	project.PodError = ""
+	project.WorkspacePVCName = ""
+	project.PodCreatedAt = nil
+	project.HibernatedAt = nil
+	if err := s.projectRepo.Update(ctx, project); err != nil {
+		return nil, fmt.Errorf("failed to update project: %w", err)
+	}
+
+	return project, nil
+}
+
+func (s *projectArchiveService) RestoreProject(ctx context.Context, projectID, userID uuid.UUID) (*model.Project, error) {
+	project, err := s.ownedProject(ctx, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if project.Status != model.ProjectStatusArchived {
+		return nil, fmt.Errorf("%w: status is %s", ErrProjectNotArchived, project.Status)
+	}
+	if project.PodName != "" {
+		return nil, fmt.Errorf("%w: the pod of the archived project is still being deleted", ErrWorkspaceBusy)
+	}
+
+	// The file-browser sidecar needs the repository credential to push
+	if project.RepoURL != "" && len(project.RepoCredentialEncrypted) > 0 && s.cipher != nil {
+		credential, err := s.cipher.Decrypt(project.RepoCredentialEncrypted)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt repository credential: %w", err)
+		}
+		project.RepoCredential = credential
+	}
+	defer func() { project.RepoCredential = "" }()
+
+	restoreCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), projectArchiveTimeout)
+	defer cancel()
+
+	archive, err := s.store.Get(restoreCtx, project.ArchiveKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive of project %s: %w", project.ID, err)
+	}
+	defer archive.Close()
+
+	if err := s.k8sService.CreateEmptyProjectPod(restoreCtx, project); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPodCreationFailed, err)
+	}
+
+	// The project stays archived until its workspace is back, so a failed restore can be retried
+	if err := s.importWorkspace(restoreCtx, project, archive); err != nil {
+		s.deleteRestoredPod(restoreCtx, project)
+		return nil, err
+	}
+
+	archiveKey := project.ArchiveKey
+	now := s.now()
+	project.Status = model.ProjectStatusReady
+	project.PodStatus = "Running"
+	project.LastActivityAt = &now
+	project.ArchiveKey = ""
+	project.ArchiveSize = 0
+	if err := s.projectRepo.Update(restoreCtx, project); err != nil {
+		s.deleteRestoredPod(restoreCtx, project)
+		return nil, fmt.Errorf("failed to update project: %w", err)
+	}
+	s.deleteArchive(restoreCtx, project.ID, archiveKey)
+
+	log.Printf("[ProjectArchiveService] Restored workspace of project %s", project.ID)
+	return project, nil
+}
+
+// exportWorkspace streams the workspace tarball from the file-browser sidecar into the archive store.
+// Looking up the pod IP wakes hibernated projects.
+func (s *projectArchiveService) exportWorkspace(ctx context.Context, project *model.Project, key string) (int64, error) {
+	podIP, err := s.k8sService.GetPodIP(ctx, project.PodName, project.PodNamespace)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get pod IP: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("http://%s:%d/workspace/archive", podIP, s.sidecarPort), nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to call file-browser sidecar: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return 0, fmt.Errorf("workspace export returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	size, err := s.store.Put(ctx, key, resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to store archive: %w", err)
+	}
+
+	// Trailers are only known once the body is read
+	if resp.Trailer.Get(archiveCompleteTrailer) != "true" {
+		s.deleteArchive(ctx, project.ID, key)
+		return 0, fmt.Errorf("workspace export of project %s was cut short", project.ID)
+	}
+
+	return size, nil
+}
+
+// importWorkspace waits for the new project pod and replaces its workspace with the archive
+func (s *projectArchiveService) importWorkspace(ctx context.Context, project *model.Project, archive io.Reader) error {
+	podIP, err := s.k8sService.WaitForPodReady(ctx, project.PodName, project.PodNamespace)
+	if err != nil {
+		return fmt.Errorf("project pod did not become ready: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "PUT", fmt.Sprintf("http://%s:%d/workspace/archive", podIP, s.sidecarPort), archive)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/gzip")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call file-browser sidecar: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("workspace import returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// deleteRestoredPod deletes the pod and PVC of a failed restore, which leaves the project archived
+func (s *projectArchiveService) deleteRestoredPod(ctx context.Context, project *model.Project) {
+	if err := s.k8sService.DeleteProjectPod(ctx, project.PodName, project.PodNamespace); err != nil {
+		log.Printf("[ProjectArchiveService] Failed to delete pod %s after a failed restore: %v", project.PodName, err)
+	}
+}
+
+func (s *projectArchiveService) deleteArchive(ctx context.Context, projectID uuid.UUID, key string) {
+	if err := s.store.Delete(ctx, key); err != nil {
+		log.Printf("[ProjectArchiveService] Failed to delete archive %s of project %s: %v", key, projectID, err)
+	}
+}
+
+func (s *projectArchiveService) ownedProject(ctx context.Context, projectID, userID uuid.UUID) (*model.Project, error) {
+	project, err := s.authz.AuthorizeProject(ctx, projectID, userID, model.ProjectRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.k8sService == nil || s.store == nil {
+		return nil, ErrArchivesUnavailable
+	}
+
+	return project, nil
+}
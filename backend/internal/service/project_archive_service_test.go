@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/npinot/vibe/backend/internal/model"
+)
+
+// setupProjectArchiveTest starts a fake file-browser sidecar and returns an archive service pointing at it
+func setupProjectArchiveTest(t *testing.T, handler http.HandlerFunc) (*projectArchiveService, *MockProjectRepository, *MockSessionRepository, *MockKubernetesService, string) {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	addr := server.Listener.Addr().(*net.TCPAddr)
+
+	store, err := NewFilesystemArchiveStore(t.TempDir())
+	require.NoError(t, err)
+
+	projectRepo := new(MockProjectRepository)
+	sessionRepo := new(MockSessionRepository)
+	k8sService := new(MockKubernetesService)
+	svc := NewProjectArchiveService(projectRepo, NewAuthorizationService(projectRepo, nil), sessionRepo, k8sService, store, nil).(*projectArchiveService)
+	svc.sidecarPort = addr.Port
+	svc.now = func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+	return svc, projectRepo, sessionRepo, k8sService, addr.IP.String()
+}
+
+// exportHandler serves the workspace tarball, flagging it complete unless the export is cut short
+func exportHandler(t *testing.T, content string, complete bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "/workspace/archive", r.URL.Path)
+		w.Header().Set("Trailer", archiveCompleteTrailer)
+		io.WriteString(w, content)
+		if complete {
+			w.Header().Set(archiveCompleteTrailer, "true")
+		}
+	}
+}
+
+func archiveTestProject(userID uuid.UUID) *model.Project {
+	return &model.Project{
+		ID:               uuid.New(),
+		UserID:           userID,
+		Status:           model.ProjectStatusReady,
+		PodName:          "project-1234",
+		PodNamespace:     "vibe",
+		PodStatus:        "Running",
+		WorkspacePVCName: "workspace-1234",
+	}
+}
+
+func TestProjectArchiveService_ArchiveProject(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	t.Run("exports the workspace and deletes the pod", func(t *testing.T) {
+		svc, projectRepo, sessionRepo, k8sService, podIP := setupProjectArchiveTest(t, exportHandler(t, "tarball", true))
+		project := archiveTestProject(userID)
+		projectRepo.On("FindByID", ctx, project.ID).Return(project, nil)
+		sessionRepo.On("FindActiveSessionsForProject", ctx, project.ID).Return([]model.Session{}, nil)
+		k8sService.On("GetPodIP", mock.Anything, "project-1234", "vibe").Return(podIP, nil)
+		k8sService.On("DeleteProjectPod", mock.Anything, "project-1234", "vibe").Return(nil)
+		projectRepo.On("Update", mock.Anything, project).Return(nil)
+
+		archived, err := svc.ArchiveProject(ctx, project.ID, userID)
+
+		require.NoError(t, err)
+		assert.Equal(t, model.ProjectStatusArchived, archived.Status)
+		assert.Equal(t, "projects/"+project.ID.String()+"/workspace-20260301T120000Z.tar.gz", archived.ArchiveKey)
+		assert.Equal(t, int64(7), archived.ArchiveSize)
+		assert.NotNil(t, archived.ArchivedAt)
+		assert.Empty(t, archived.PodName)
+		assert.Empty(t, archived.WorkspacePVCName)
+		projectRepo.AssertNumberOfCalls(t, "Update", 2)
+
+		r, err := svc.store.Get(ctx, archived.ArchiveKey)
+		require.NoError(t, err)
+		defer r.Close()
+		content, _ := io.ReadAll(r)
+		assert.Equal(t, "tarball", string(content))
+	})
+
+	t.Run("export cut short", func(t *testing.T) {
+		svc, projectRepo, sessionRepo, k8sService, podIP := setupProjectArchiveTest(t, exportHandler(t, "tarb", false))
+		project := archiveTestProject(userID)
+		projectRepo.On("FindByID", ctx, project.ID).Return(project, nil)
+		sessionRepo.On("FindActiveSessionsForProject", ctx, project.ID).Return([]model.Session{}, nil)
+		k8sService.On("GetPodIP", mock.Anything, "project-1234", "vibe").Return(podIP, nil)
+
+		_, err := svc.ArchiveProject(ctx, project.ID, userID)
+
+		assert.Error(t, err)
+		assert.Equal(t, model.ProjectStatusReady, project.Status)
+		k8sService.AssertNotCalled(t, "DeleteProjectPod", mock.Anything, mock.Anything, mock.Anything)
+		projectRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		_, err = svc.store.Get(ctx, "projects/"+project.ID.String()+"/workspace-20260301T120000Z.tar.gz")
+		assert.ErrorIs(t, err, ErrArchiveNotFound)
+	})
+
+	t.Run("retries deleting the pod of an archived project", func(t *testing.T) {
+		svc, projectRepo, _, k8sService, _ := setupProjectArchiveTest(t, exportHandler(t, "", true))
+		project := archiveTestProject(userID)
+		project.Status = model.ProjectStatusArchived
+		projectRepo.On("FindByID", ctx, project.ID).Return(project, nil)
+		k8sService.On("DeleteProjectPod", mock.Anything, "project-1234", "vibe").Return(nil)
+		projectRepo.On("Update", mock.Anything, project).Return(nil)
+
+		archived, err := svc.ArchiveProject(ctx, project.ID, userID)
+
+		require.NoError(t, err)
+		assert.Empty(t, archived.PodName)
+		k8sService.AssertNotCalled(t, "GetPodIP", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("already archived", func(t *testing.T) {
+		svc, projectRepo, _, _, _ := setupProjectArchiveTest(t, exportHandler(t, "", true))
+		project := &model.Project{ID: uuid.New(), UserID: userID, Status: model.ProjectStatusArchived}
+		projectRepo.On("FindByID", ctx, project.ID).Return(project, nil)
+
+		_, err := svc.ArchiveProject(ctx, project.ID, userID)
+
+		assert.ErrorIs(t, err, ErrProjectArchived)
+	})
+
+	t.Run("rejected while a task runs", func(t *testing.T) {
+		svc, projectRepo, sessionRepo, k8sService, _ := setupProjectArchiveTest(t, exportHandler(t, "", true))
+		project := archiveTestProject(userID)
+		projectRepo.On("FindByID", ctx, project.ID).Return(project, nil)
+		sessionRepo.On("FindActiveSessionsForProject", ctx, project.ID).Return([]model.Session{{ID: uuid.New(), TaskID: uuid.New()}}, nil)
+
+		_, err := svc.ArchiveProject(ctx, project.ID, userID)
+
+		assert.ErrorIs(t, err, ErrWorkspaceBusy)
+		k8sService.AssertNotCalled(t, "GetPodIP", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("project of another user", func(t *testing.T) {
+		svc, projectRepo, _, _, _ := setupProjectArchiveTest(t, exportHandler(t, "", true))
+		project := archiveTestProject(uuid.New())
+		projectRepo.On("FindByID", ctx, project.ID).Return(project, nil)
+
+		_, err := svc.ArchiveProject(ctx, project.ID, userID)
+
+		assert.ErrorIs(t, err, ErrUnauthorized)
+	})
+
+	t.Run("project not found", func(t *testing.T) {
+		svc, projectRepo, _, _, _ := setupProjectArchiveTest(t, exportHandler(t, "", true))
+		projectID := uuid.New()
+		projectRepo.On("FindByID", ctx, projectID).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := svc.ArchiveProject(ctx, projectID, userID)
+
+		assert.ErrorIs(t, err, ErrProjectNotFound)
+	})
+
+	t.Run("without archive store", func(t *testing.T) {
+		projectRepo := new(MockProjectRepository)
+		project := archiveTestProject(userID)
+		projectRepo.On("FindByID", ctx, project.ID).Return(project, nil)
+		svc := NewProjectArchiveService(projectRepo, NewAuthorizationService(projectRepo, nil), new(MockSessionRepository), new(MockKubernetesService), nil, nil)
+
+		_, err := svc.ArchiveProject(ctx, project.ID, userID)
+
+		assert.ErrorIs(t, err, ErrArchivesUnavailable)
+	})
+}
+
+func TestProjectArchiveService_RestoreProject(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	archivedProject := func(t *testing.T, svc *projectArchiveService) *model.Project {
+		project := &model.Project{
+			ID:         uuid.New(),
+			UserID:     userID,
+			Status:     model.ProjectStatusArchived,
+			ArchiveKey: "projects/1234/workspace.tar.gz",
+		}
+		_, err := svc.store.Put(ctx, project.ArchiveKey, strings.NewReader("tarball"))
+		require.NoError(t, err)
+		return project
+	}
+
+	t.Run("imports the archive into a new pod", func(t *testing.T) {
+		var imported string
+		svc, projectRepo, _, k8sService, podIP := setupProjectArchiveTest(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "PUT", r.Method)
+			assert.Equal(t, "/workspace/archive", r.URL.Path)
+			body, _ := io.ReadAll(r.Body)
+			imported = string(body)
+			w.WriteHeader(http.StatusNoContent)
+		})
+		project := archivedProject(t, svc)
+		projectRepo.On("FindByID", ctx, project.ID).Return(project, nil)
+		k8sService.On("CreateEmptyProjectPod", mock.Anything, project).Return(nil)
+		k8sService.On("WaitForPodReady", mock.Anything, "project-12345678", "opencode").Return(podIP, nil)
+		projectRepo.On("Update", mock.Anything, project).Return(nil)
+
+		restored, err := svc.RestoreProject(ctx, project.ID, userID)
+
+		require.NoError(t, err)
+		assert.Equal(t, "tarball", imported)
+		assert.Equal(t, model.ProjectStatusReady, restored.Status)
+		assert.Equal(t, "workspace-12345678", restored.WorkspacePVCName)
+		assert.Empty(t, restored.ArchiveKey)
+		assert.NotNil(t, restored.LastActivityAt)
+		_, err = svc.store.Get(ctx, "projects/1234/workspace.tar.gz")
+		assert.ErrorIs(t, err, ErrArchiveNotFound)
+	})
+
+	t.Run("failed import deletes the new pod", func(t *testing.T) {
+		svc, projectRepo, _, k8sService, podIP := setupProjectArchiveTest(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+		project := archivedProject(t, svc)
+		projectRepo.On("FindByID", ctx, project.ID).Return(project, nil)
+		k8sService.On("CreateEmptyProjectPod", mock.Anything, project).Return(nil)
+		k8sService.On("WaitForPodReady", mock.Anything, "project-12345678", "opencode").Return(podIP, nil)
+		k8sService.On("DeleteProjectPod", mock.Anything, "project-12345678", "opencode").Return(nil)
+
+		_, err := svc.RestoreProject(ctx, project.ID, userID)
+
+		assert.Error(t, err)
+		k8sService.AssertCalled(t, "DeleteProjectPod", mock.Anything, "project-12345678", "opencode")
+		projectRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		_, err = svc.store.Get(ctx, "projects/1234/workspace.tar.gz")
+		assert.NoError(t, err, "archive should be kept for another attempt")
+	})
+
+	t.Run("missing archive", func(t *testing.T) {
+		svc, projectRepo, _, k8sService, _ := setupProjectArchiveTest(t, exportHandler(t, "", true))
+		project := &model.Project{ID: uuid.New(), UserID: userID, Status: model.ProjectStatusArchived, ArchiveKey: "projects/missing.tar.gz"}
+		projectRepo.On("FindByID", ctx, project.ID).Return(project, nil)
+
+		_, err := svc.RestoreProject(ctx, project.ID, userID)
+
+		assert.True(t, errors.Is(err, ErrArchiveNotFound))
+		k8sService.AssertNotCalled(t, "CreateEmptyProjectPod", mock.Anything, mock.Anything)
+	})
+
+	t.Run("project not archived", func(t *testing.T) {
+		svc, projectRepo, _, _, _ := setupProjectArchiveTest(t, exportHandler(t, "", true))
+		project := archiveTestProject(userID)
+		projectRepo.On("FindByID", ctx, project.ID).Return(project, nil)
+
+		_, err := svc.RestoreProject(ctx, project.ID, userID)
+
+		assert.ErrorIs(t, err, ErrProjectNotArchived)
+	})
+}
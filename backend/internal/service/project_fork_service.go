@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+
+	"github.com/npinot/vibe/backend/internal/model"
+	"github.com/npinot/vibe/backend/internal/repository"
+)
+
+// forkNameSuffix is appended to the source project name when a fork is not named
+const forkNameSuffix = " fork"
+
+// ForkOptions are the choices made when forking a project. An empty name or description keeps
+// the one of the source project, with the name suffixed by "fork".
+type ForkOptions struct {
+	Name        string
+	Description string
+	CopyTasks   bool // Copy the tasks of the source project, reset to todo
+}
+
+// ProjectForkService creates projects from the workspace of an existing one
+type ProjectForkService interface {
+	// ForkProject creates a project whose workspace PVC is cloned from the source project, with a copy
+	// of its active configuration and optionally of its tasks. The fork records the source as its parent.
+	// Forks made by other members than the source owner get neither the repository credential nor the API key.
+	ForkProject(ctx context.Context, projectID, userID uuid.UUID, opts ForkOptions) (*model.Project, error)
+}
+
+type projectForkService struct {
+	projectRepo   repository.ProjectRepository
+	authz         AuthorizationService
+	taskRepo      repository.TaskRepository
+	configService ConfigServiceInterface
+	k8sService    KubernetesService
+	cipher        SecretCipher
+	profiles      ResourceProfileService
+}
+
+// NewProjectForkService creates a project fork service. Forks are unavailable when k8sService is nil.
+// cipher decrypts the repository credential handed to the pod of the fork. profiles checks the
+// resources of the source project against the caps of the user forking it; without it forks are
+// not capped.
+func NewProjectForkService(projectRepo repository.ProjectRepository, authz AuthorizationService, taskRepo repository.TaskRepository, configService ConfigServiceInterface, k8sService KubernetesService, cipher SecretCipher, profiles ResourceProfileService) ProjectForkService {
+	return &projectForkService{
+		projectRepo:   projectRepo,
+		authz:         authz,
+		taskRepo:      taskRepo,
+		configService: configService,
+		k8sService:    k8sService,
+		cipher:        cipher,
+		profiles:      profiles,
+	}
+}
+
+func (s *projectForkService) ForkProject(ctx context.Context, projectID, userID uuid.UUID, opts ForkOptions) (*model.Project, error) {
+	// Viewers may not fork; maintainers may, but without the secrets of the source owner
+	source, err := s.authz.AuthorizeProject(ctx, projectID, userID, model.ProjectRoleMaintainer)
+	if err != nil {
+		return nil, err
+	}
+	withSecrets := userID == source.UserID
+
+	name := opts.Name
+	if name == "" {
+		name = defaultForkName(source.Name)
+	}
+	if err := validateProjectName(name); err != nil {
+		return nil, err
+	}
+
+	// Archived workspaces only exist in the archive store; they have to be restored first
+	if source.Status == model.ProjectStatusArchived {
+		return nil, ErrProjectArchived
+	}
+	if source.WorkspacePVCName == "" {
+		return nil, fmt.Errorf("%w: the workspace was never created", ErrProjectPodMissing)
+	}
+	if s.k8sService == nil {
+		return nil, fmt.Errorf("%w: Kubernetes is not configured", ErrPodCreationFailed)
+	}
+
+	// The fork belongs to the user forking, whose caps may be lower than the ones of the source owner
+	if s.profiles != nil {
+		if _, err := s.profiles.ResolveProfile(ctx, userID, ResourceSelection{
+			Profile:      source.ResourceProfile,
+			CPU:          source.CPULimit,
+			Memory:       source.MemoryLimit,
+			StorageSize:  source.StorageSize,
+			StorageClass: source.StorageClass,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	// The file-browser sidecar of the fork needs the repository credential to fetch and push.
+	// Only the owner of the source gets it; other members have to set their own.
+	var credential string
+	var credentialEncrypted []byte
+	if withSecrets {
+		credentialEncrypted = source.RepoCredentialEncrypted
+	}
+	if source.RepoURL != "" && len(credentialEncrypted) > 0 && s.cipher != nil {
+		credential, err = s.cipher.Decrypt(credentialEncrypted)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt repository credential: %w", err)
+		}
+	}
+
+	description := opts.Description
+	if description == "" {
+		description = source.Description
+	}
+
+	// The workspace is cloned with the repository already checked out, so the repository
+	// settings are only kept for reference and later git operations
+	fork := &model.Project{
+		UserID:                  userID,
+		Name:                    name,
+		Slug:                    generateSlug(name),
+		Description:             description,
+		RepoURL:                 source.RepoURL,
+		RepoRef:                 source.RepoRef,
+		RepoCloneDepth:          source.RepoCloneDepth,
+		RepoCredentialEncrypted: credentialEncrypted,
+		ResourceProfile:         source.ResourceProfile,
+		CPURequest:              source.CPURequest,
+		CPULimit:                source.CPULimit,
+		MemoryRequest:           source.MemoryRequest,
+		MemoryLimit:             source.MemoryLimit,
+		StorageSize:             source.StorageSize,
+		StorageClass:            source.StorageClass,
+		ParentProjectID:         &source.ID,
+		Status:                  model.ProjectStatusInitializing,
+	}
+
+	if err := s.projectRepo.Create(ctx, fork); err != nil {
+		return nil, fmt.Errorf("failed to create project in database: %w", err)
+	}
+
+	if err := s.copyProjectData(ctx, source, fork, userID, opts.CopyTasks, withSecrets); err != nil {
+		s.discardFork(ctx, fork)
+		return nil, err
+	}
+
+	fork.RepoCredential = credential
+	err = s.k8sService.CreateForkedProjectPod(ctx, fork, source)
+	fork.RepoCredential = ""
+	if err != nil {
+		s.discardFork(ctx, fork)
+		return nil, fmt.Errorf("%w: %v", ErrPodCreationFailed, err)
+	}
+
+	fork.Status = model.ProjectStatusReady
+	if err := s.projectRepo.Update(ctx, fork); err != nil {
+		return nil, fmt.Errorf("pod created successfully but failed to update project metadata: %w", err)
+	}
+
+	log.Printf("[ProjectForkService] Forked project %s into %s", source.ID, fork.ID)
+	return fork, nil
+}
+
+// copyProjectData copies the active configuration and, if requested, the tasks of the source project.
+// The API key of the configuration is only copied with withSecrets.
+func (s *projectForkService) copyProjectData(ctx context.Context, source, fork *model.Project, userID uuid.UUID, copyTasks, withSecrets bool) error {
+	if err := s.configService.CopyActiveConfig(ctx, source.ID, fork.ID, withSecrets); err != nil {
+		return fmt.Errorf("failed to copy configuration: %w", err)
+	}
+
+	if !copyTasks {
+		return nil
+	}
+
+	tasks, err := s.taskRepo.FindByProjectID(ctx, source.ID)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve tasks: %w", err)
+	}
+
+	// Execution state (sessions, output, branches, reviews) belongs to the source project
+	for _, task := range tasks {
+		copied := &model.Task{
+			ProjectID:   fork.ID,
+			Title:       task.Title,
+			Description: task.Description,
+			Status:      model.TaskStatusTodo,
+			Position:    task.Position,
+			Priority:    task.Priority,
+			CreatedBy:   userID,
+		}
+		if err := s.taskRepo.Create(ctx, copied); err != nil {
+			return fmt.Errorf("failed to copy task: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// discardFork deletes a fork that could not be completed
+func (s *projectForkService) discardFork(ctx context.Context, fork *model.Project) {
+	if err := s.projectRepo.SoftDelete(ctx, fork.ID); err != nil {
+		log.Printf("[ProjectForkService] Failed to delete incomplete fork %s: %v", fork.ID, err)
+	}
+}
+
+// defaultForkName suffixes the source name, shortening it to stay within the project name limit
+func defaultForkName(sourceName string) string {
+	if maxLen := 100 - len(forkNameSuffix); len(sourceName) > maxLen {
+		sourceName = sourceName[:maxLen]
+	}
+	return sourceName + forkNameSuffix
+}
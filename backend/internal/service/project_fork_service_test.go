@@ -0,0 +1,410 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/npinot/vibe/backend/internal/model"
+)
+
+func setupProjectForkTest() (ProjectForkService, *MockProjectRepository, *MockTaskRepository, *MockConfigService, *MockKubernetesService) {
+	projectRepo := new(MockProjectRepository)
+	taskRepo := new(MockTaskRepository)
+	configService := new(MockConfigService)
+	k8sService := new(MockKubernetesService)
+	svc := NewProjectForkService(projectRepo, NewAuthorizationService(projectRepo, nil), taskRepo, configService, k8sService, nil, nil)
+	return svc, projectRepo, taskRepo, configService, k8sService
+}
+
+func forkTestProject(userID uuid.UUID) *model.Project {
+	return &model.Project{
+		ID:                      uuid.New(),
+		UserID:                  userID,
+		Name:                    "Source",
+		Description:             "Source project",
+		RepoURL:                 "https://github.com/test/repo.git",
+		RepoRef:                 "main",
+		RepoCredentialEncrypted: []byte("encrypted"),
+		PodName:                 "project-12345678",
+		PodNamespace:            "opencode",
+		WorkspacePVCName:        "workspace-12345678",
+		ResourceProfile:         ResourceProfileLarge,
+		StorageSize:             "20Gi",
+		StorageClass:            "csi-rbd",
+		Status:                  model.ProjectStatusReady,
+	}
+}
+
+// expectForkCreate assigns an ID to the fork when it is saved, like the database does
+func expectForkCreate(projectRepo *MockProjectRepository) {
+	projectRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.Project")).
+		Run(func(args mock.Arguments) { args.Get(1).(*model.Project).ID = uuid.New() }).
+		Return(nil)
+}
+
+func TestForkProject_Success(t *testing.T) {
+	svc, projectRepo, taskRepo, configService, k8sService := setupProjectForkTest()
+	ctx := context.Background()
+	userID := uuid.New()
+	source := forkTestProject(userID)
+
+	projectRepo.On("FindByID", ctx, source.ID).Return(source, nil)
+	expectForkCreate(projectRepo)
+	configService.On("CopyActiveConfig", ctx, source.ID, mock.AnythingOfType("uuid.UUID"), true).Return(nil)
+	k8sService.On("CreateForkedProjectPod", ctx, mock.AnythingOfType("*model.Project"), source).Return(nil)
+	projectRepo.On("Update", ctx, mock.AnythingOfType("*model.Project")).Return(nil)
+
+	fork, err := svc.ForkProject(ctx, source.ID, userID, ForkOptions{})
+
+	require.NoError(t, err)
+	assert.NotEqual(t, source.ID, fork.ID)
+	assert.Equal(t, "Source fork", fork.Name)
+	assert.Equal(t, "Source project", fork.Description)
+	assert.Equal(t, userID, fork.UserID)
+	require.NotNil(t, fork.ParentProjectID)
+	assert.Equal(t, source.ID, *fork.ParentProjectID)
+	assert.Equal(t, source.RepoURL, fork.RepoURL)
+	assert.Equal(t, source.RepoCredentialEncrypted, fork.RepoCredentialEncrypted)
+	assert.Equal(t, ResourceProfileLarge, fork.ResourceProfile)
+	assert.Equal(t, "20Gi", fork.StorageSize)
+	assert.Equal(t, "csi-rbd", fork.StorageClass)
+	assert.Equal(t, model.ProjectStatusReady, fork.Status)
+	taskRepo.AssertNotCalled(t, "FindByProjectID", mock.Anything, mock.Anything)
+	configService.AssertCalled(t, "CopyActiveConfig", ctx, source.ID, fork.ID, true)
+	projectRepo.AssertExpectations(t)
+	k8sService.AssertExpectations(t)
+}
+
+func TestForkProject_CopiesTasksAsTodo(t *testing.T) {
+	svc, projectRepo, taskRepo, configService, k8sService := setupProjectForkTest()
+	ctx := context.Background()
+	userID := uuid.New()
+	source := forkTestProject(userID)
+	sessionID := uuid.New()
+
+	tasks := []model.Task{
+		{ID: uuid.New(), ProjectID: source.ID, Title: "Done task", Status: model.TaskStatusDone, Position: 0, Priority: model.TaskPriorityHigh, OpenCodeOutput: "output", BranchName: "task/done", CreatedBy: uuid.New()},
+		{ID: uuid.New(), ProjectID: source.ID, Title: "Running task", Description: "In progress", Status: model.TaskStatusInProgress, Position: 1, Priority: model.TaskPriorityLow, CurrentSessionID: &sessionID, CreatedBy: uuid.New()},
+	}
+
+	var copied []*model.Task
+	projectRepo.On("FindByID", ctx, source.ID).Return(source, nil)
+	expectForkCreate(projectRepo)
+	configService.On("CopyActiveConfig", ctx, source.ID, mock.AnythingOfType("uuid.UUID"), true).Return(nil)
+	taskRepo.On("FindByProjectID", ctx, source.ID).Return(tasks, nil)
+	taskRepo.On("Create", ctx, mock.AnythingOfType("*model.Task")).
+		Run(func(args mock.Arguments) { copied = append(copied, args.Get(1).(*model.Task)) }).
+		Return(nil)
+	k8sService.On("CreateForkedProjectPod", ctx, mock.AnythingOfType("*model.Project"), source).Return(nil)
+	projectRepo.On("Update", ctx, mock.AnythingOfType("*model.Project")).Return(nil)
+
+	fork, err := svc.ForkProject(ctx, source.ID, userID, ForkOptions{Name: "Experiment", Description: "Trying things", CopyTasks: true})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Experiment", fork.Name)
+	assert.Equal(t, "Trying things", fork.Description)
+	require.Len(t, copied, 2)
+	for i, task := range copied {
+		assert.Equal(t, fork.ID, task.ProjectID)
+		assert.Equal(t, tasks[i].Title, task.Title)
+		assert.Equal(t, tasks[i].Description, task.Description)
+		assert.Equal(t, tasks[i].Priority, task.Priority)
+		assert.Equal(t, tasks[i].Position, task.Position)
+		assert.Equal(t, model.TaskStatusTodo, task.Status)
+		assert.Equal(t, userID, task.CreatedBy)
+		assert.Nil(t, task.CurrentSessionID)
+		assert.Empty(t, task.OpenCodeOutput)
+		assert.Empty(t, task.BranchName)
+	}
+	taskRepo.AssertExpectations(t)
+}
+
+func TestForkProject_DefaultNameIsTruncated(t *testing.T) {
+	name := defaultForkName(strings.Repeat("a", 100))
+
+	assert.Len(t, name, 100)
+	assert.NoError(t, validateProjectName(name))
+	assert.True(t, strings.HasSuffix(name, " fork"))
+}
+
+func TestForkProject_Rejected(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name    string
+		modify  func(*model.Project)
+		userID  uuid.UUID
+		opts    ForkOptions
+		wantErr error
+	}{
+		{name: "other user", userID: uuid.New(), wantErr: ErrUnauthorized},
+		{name: "invalid name", userID: userID, opts: ForkOptions{Name: "bad/name"}, wantErr: ErrInvalidProjectName},
+		{name: "archived", userID: userID, modify: func(p *model.Project) { p.Status = model.ProjectStatusArchived; p.WorkspacePVCName = "" }, wantErr: ErrProjectArchived},
+		{name: "no workspace", userID: userID, modify: func(p *model.Project) { p.WorkspacePVCName = "" }, wantErr: ErrProjectPodMissing},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, projectRepo, _, _, _ := setupProjectForkTest()
+			ctx := context.Background()
+			source := forkTestProject(userID)
+			if tt.modify != nil {
+				tt.modify(source)
+			}
+			projectRepo.On("FindByID", ctx, source.ID).Return(source, nil)
+
+			_, err := svc.ForkProject(ctx, source.ID, tt.userID, tt.opts)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			projectRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestForkProject_NotFound(t *testing.T) {
+	svc, projectRepo, _, _, _ := setupProjectForkTest()
+	ctx := context.Background()
+	projectID := uuid.New()
+
+	projectRepo.On("FindByID", ctx, projectID).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := svc.ForkProject(ctx, projectID, uuid.New(), ForkOptions{})
+
+	assert.ErrorIs(t, err, ErrProjectNotFound)
+}
+
+func TestForkProject_Members(t *testing.T) {
+	tests := []struct {
+		name    string
+		role    model.ProjectRole
+		wantErr error
+	}{
+		{name: "maintainer", role: model.ProjectRoleMaintainer},
+		{name: "viewer", role: model.ProjectRoleViewer, wantErr: ErrUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectRepo := new(MockProjectRepository)
+			memberRepo := new(MockProjectMemberRepository)
+			configService := new(MockConfigService)
+			k8sService := new(MockKubernetesService)
+			svc := NewProjectForkService(projectRepo, NewAuthorizationService(projectRepo, memberRepo), new(MockTaskRepository), configService, k8sService, nil, nil)
+			ctx := context.Background()
+			memberID := uuid.New()
+			source := forkTestProject(uuid.New())
+
+			projectRepo.On("FindByID", ctx, source.ID).Return(source, nil)
+			expectMember(memberRepo, source, memberID, tt.role)
+			expectForkCreate(projectRepo)
+			configService.On("CopyActiveConfig", ctx, source.ID, mock.AnythingOfType("uuid.UUID"), false).Return(nil)
+			k8sService.On("CreateForkedProjectPod", ctx, mock.AnythingOfType("*model.Project"), source).Return(nil)
+			projectRepo.On("Update", ctx, mock.AnythingOfType("*model.Project")).Return(nil)
+
+			fork, err := svc.ForkProject(ctx, source.ID, memberID, ForkOptions{})
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				projectRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			// The fork belongs to the member who forked it
+			assert.Equal(t, memberID, fork.UserID)
+		})
+	}
+}
+
+func TestForkProject_ResourceCapExceeded(t *testing.T) {
+	projectRepo := new(MockProjectRepository)
+	capRepo := new(MockResourceCapRepository)
+	svc := NewProjectForkService(projectRepo, NewAuthorizationService(projectRepo, nil), new(MockTaskRepository), new(MockConfigService), new(MockKubernetesService), nil, NewResourceProfileService(capRepo, testDefaultCaps))
+	ctx := context.Background()
+	userID := uuid.New()
+	source := forkTestProject(userID)
+
+	projectRepo.On("FindByID", ctx, source.ID).Return(source, nil)
+	capRepo.On("FindByUserID", ctx, userID).Return(&model.ResourceCap{UserID: userID, MaxCPU: "1", MaxMemory: "1Gi", MaxStorage: "1Gi"}, nil)
+
+	_, err := svc.ForkProject(ctx, source.ID, userID, ForkOptions{})
+
+	assert.ErrorIs(t, err, ErrResourceCapExceeded)
+	projectRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestForkProject_NoKubernetes(t *testing.T) {
+	projectRepo := new(MockProjectRepository)
+	svc := NewProjectForkService(projectRepo, NewAuthorizationService(projectRepo, nil), new(MockTaskRepository), new(MockConfigService), nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	source := forkTestProject(userID)
+
+	projectRepo.On("FindByID", ctx, source.ID).Return(source, nil)
+
+	_, err := svc.ForkProject(ctx, source.ID, userID, ForkOptions{})
+
+	assert.ErrorIs(t, err, ErrPodCreationFailed)
+	projectRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestForkProject_PodCreationFailedDiscardsFork(t *testing.T) {
+	svc, projectRepo, _, configService, k8sService := setupProjectForkTest()
+	ctx := context.Background()
+	userID := uuid.New()
+	source := forkTestProject(userID)
+
+	projectRepo.On("FindByID", ctx, source.ID).Return(source, nil)
+	expectForkCreate(projectRepo)
+	configService.On("CopyActiveConfig", ctx, source.ID, mock.AnythingOfType("uuid.UUID"), true).Return(nil)
+	k8sService.On("CreateForkedProjectPod", ctx, mock.AnythingOfType("*model.Project"), source).Return(errors.New("clone not supported"))
+	projectRepo.On("SoftDelete", ctx, mock.AnythingOfType("uuid.UUID")).Return(nil)
+
+	_, err := svc.ForkProject(ctx, source.ID, userID, ForkOptions{})
+
+	assert.ErrorIs(t, err, ErrPodCreationFailed)
+	assert.Contains(t, err.Error(), "clone not supported")
+	projectRepo.AssertCalled(t, "SoftDelete", ctx, mock.AnythingOfType("uuid.UUID"))
+	projectRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestForkProject_ConfigCopyFailedDiscardsFork(t *testing.T) {
+	svc, projectRepo, _, configService, k8sService := setupProjectForkTest()
+	ctx := context.Background()
+	userID := uuid.New()
+	source := forkTestProject(userID)
+
+	projectRepo.On("FindByID", ctx, source.ID).Return(source, nil)
+	expectForkCreate(projectRepo)
+	configService.On("CopyActiveConfig", ctx, source.ID, mock.AnythingOfType("uuid.UUID"), true).Return(errors.New("decryption failed"))
+	projectRepo.On("SoftDelete", ctx, mock.AnythingOfType("uuid.UUID")).Return(nil)
+
+	_, err := svc.ForkProject(ctx, source.ID, userID, ForkOptions{})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to copy configuration")
+	projectRepo.AssertCalled(t, "SoftDelete", ctx, mock.AnythingOfType("uuid.UUID"))
+	k8sService.AssertNotCalled(t, "CreateForkedProjectPod", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestForkProject_RepoCredential(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	cipher, err := NewSecretCipher("MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+	require.NoError(t, err)
+	encrypted, err := cipher.Encrypt("ghp_secret")
+	require.NoError(t, err)
+
+	clientset := fake.NewSimpleClientset()
+	k8sService := &kubernetesService{
+		clientset: clientset,
+		namespace: "opencode",
+		config: &KubernetesConfig{
+			Namespace:     "opencode",
+			WorkspaceSize: "1Gi",
+			CPULimit:      "1000m",
+			MemoryLimit:   "1Gi",
+			CPURequest:    "100m",
+			MemoryRequest: "256Mi",
+		},
+	}
+	projectRepo := new(MockProjectRepository)
+	configService := new(MockConfigService)
+	svc := NewProjectForkService(projectRepo, NewAuthorizationService(projectRepo, nil), new(MockTaskRepository), configService, k8sService, cipher, nil)
+
+	source := forkTestProject(userID)
+	source.RepoCredentialEncrypted = encrypted
+	projectRepo.On("FindByID", ctx, source.ID).Return(source, nil)
+	expectForkCreate(projectRepo)
+	configService.On("CopyActiveConfig", ctx, source.ID, mock.AnythingOfType("uuid.UUID"), true).Return(nil)
+	projectRepo.On("Update", ctx, mock.AnythingOfType("*model.Project")).Return(nil)
+
+	fork, err := svc.ForkProject(ctx, source.ID, userID, ForkOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, fork.RepoCredential, "the decrypted credential must not outlive the pod creation")
+
+	secretName := generateRepoSecretName(fork.ID)
+	secret, err := clientset.CoreV1().Secrets("opencode").Get(ctx, secretName, metav1.GetOptions{})
+	require.NoError(t, err, "the fork needs its own credential secret")
+	assert.Equal(t, "ghp_secret", secret.StringData[repoCredentialSecretKey])
+
+	pod, err := clientset.CoreV1().Pods("opencode").Get(ctx, fork.PodName, metav1.GetOptions{})
+	require.NoError(t, err)
+	var mounted bool
+	for _, container := range pod.Spec.Containers {
+		for _, env := range container.Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil && env.ValueFrom.SecretKeyRef.Name == secretName {
+				mounted = true
+			}
+		}
+	}
+	assert.True(t, mounted, "the pod of the fork must read the credential secret")
+}
+
+func TestForkProject_MaintainerForkHasNoSecrets(t *testing.T) {
+	ctx := context.Background()
+	cipher, err := NewSecretCipher("MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+	require.NoError(t, err)
+	encrypted, err := cipher.Encrypt("ghp_secret")
+	require.NoError(t, err)
+
+	clientset := fake.NewSimpleClientset()
+	k8sService := &kubernetesService{
+		clientset: clientset,
+		namespace: "opencode",
+		config: &KubernetesConfig{
+			Namespace:     "opencode",
+			WorkspaceSize: "1Gi",
+			CPULimit:      "1000m",
+			MemoryLimit:   "1Gi",
+			CPURequest:    "100m",
+			MemoryRequest: "256Mi",
+		},
+	}
+	projectRepo := new(MockProjectRepository)
+	memberRepo := new(MockProjectMemberRepository)
+	configRepo := new(MockConfigRepository)
+	configService, err := NewConfigService(configRepo, projectRepo, generateEncryptionKey())
+	require.NoError(t, err)
+	svc := NewProjectForkService(projectRepo, NewAuthorizationService(projectRepo, memberRepo), new(MockTaskRepository), configService, k8sService, cipher, nil)
+
+	maintainerID := uuid.New()
+	source := forkTestProject(uuid.New())
+	source.RepoCredentialEncrypted = encrypted
+	apiKeyEncrypted, err := configService.encryptAPIKey("sk-owner-key")
+	require.NoError(t, err)
+	sourceConfig := createValidConfig()
+	sourceConfig.ID = uuid.New()
+	sourceConfig.ProjectID = source.ID
+	sourceConfig.APIKeyEncrypted = apiKeyEncrypted
+
+	var copied *model.OpenCodeConfig
+	projectRepo.On("FindByID", ctx, source.ID).Return(source, nil)
+	expectMember(memberRepo, source, maintainerID, model.ProjectRoleMaintainer)
+	expectForkCreate(projectRepo)
+	configRepo.On("GetActiveConfig", ctx, source.ID).Return(sourceConfig, nil)
+	configRepo.On("CreateConfig", ctx, mock.AnythingOfType("*model.OpenCodeConfig")).
+		Run(func(args mock.Arguments) { copied = args.Get(1).(*model.OpenCodeConfig) }).
+		Return(nil)
+	projectRepo.On("Update", ctx, mock.AnythingOfType("*model.Project")).Return(nil)
+
+	fork, err := svc.ForkProject(ctx, source.ID, maintainerID, ForkOptions{})
+	require.NoError(t, err)
+
+	assert.Empty(t, fork.RepoCredentialEncrypted, "the fork must not keep the credential of the source owner")
+	require.NotNil(t, copied)
+	assert.Equal(t, sourceConfig.ModelName, copied.ModelName)
+	assert.Empty(t, copied.APIKeyEncrypted, "the fork must not keep the API key of the source owner")
+
+	_, err = clientset.CoreV1().Secrets("opencode").Get(ctx, generateRepoSecretName(fork.ID), metav1.GetOptions{})
+	assert.Error(t, err, "no credential secret may be created for the fork")
+}
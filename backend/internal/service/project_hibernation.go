@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/npinot/vibe/backend/internal/model"
+	"github.com/npinot/vibe/backend/internal/repository"
+)
+
+var ErrProjectWakeFailed = errors.New("failed to wake project")
+
+const (
+	// projectWakeTimeout bounds how long waking a project waits for its pod to become ready
+	projectWakeTimeout = 5 * time.Minute
+
+	// activityRecordInterval throttles how often the activity of a project pod is written to the database
+	activityRecordInterval = time.Minute
+)
+
+// ProjectHibernator deletes the pods of projects left idle, keeping their workspace PVC, and recreates
+// them when the project is used again. It wraps the KubernetesService: every GetPodIP call counts as
+// project activity and transparently wakes hibernated projects, so the file API, terminals and sessions
+// reach their sidecars without knowing about hibernation.
+type ProjectHibernator struct {
+	KubernetesService
+	projectRepo repository.ProjectRepository
+	sessionRepo repository.SessionRepository
+	idleTimeout time.Duration
+	wakeTimeout time.Duration
+	now         func() time.Time
+
+	mu       sync.Mutex
+	recorded map[string]time.Time       // last activity written per pod
+	holds    map[string]int             // open terminals per pod
+	wakes    map[uuid.UUID]*projectWake // wake-ups in progress per project
+}
+
+// projectWake is a wake-up shared by all requests reaching a project while its pod comes back
+type projectWake struct {
+	done  chan struct{}
+	podIP string
+	err   error
+}
+
+func NewProjectHibernator(k8sService KubernetesService, projectRepo repository.ProjectRepository, sessionRepo repository.SessionRepository, idleTimeout time.Duration) *ProjectHibernator {
+	return &ProjectHibernator{
+		KubernetesService: k8sService,
+		projectRepo:       projectRepo,
+		sessionRepo:       sessionRepo,
+		idleTimeout:       idleTimeout,
+		wakeTimeout:       projectWakeTimeout,
+		now:               time.Now,
+		recorded:          make(map[string]time.Time),
+		holds:             make(map[string]int),
+		wakes:             make(map[uuid.UUID]*projectWake),
+	}
+}
+
+// Run hibernates idle projects on every interval until ctx is cancelled
+func (h *ProjectHibernator) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := h.HibernateIdleProjects(ctx); err != nil {
+			log.Printf("[Hibernation] %v", err)
+		}
+	}
+}
+
+// GetPodIP returns the IP of the pod and records the use of the project. The pod of a hibernated
+// project is recreated first, waiting until it is ready.
+func (h *ProjectHibernator) GetPodIP(ctx context.Context, podName, namespace string) (string, error) {
+	podIP, err := h.KubernetesService.GetPodIP(ctx, podName, namespace)
+	if err == nil {
+		h.recordActivity(ctx, podName, namespace, false)
+		return podIP, nil
+	}
+
+	project, findErr := h.projectRepo.FindByPodName(ctx, podName, namespace)
+	if findErr != nil || project.Status != model.ProjectStatusHibernated {
+		return "", err
+	}
+
+	return h.wake(ctx, project)
+}
+
+// Hold keeps the project pod from hibernating until release is called, for connections such as
+// terminals that use the pod without going through GetPodIP again
+func (h *ProjectHibernator) Hold(podName, namespace string) (release func()) {
+	key := podKey(podName, namespace)
+
+	h.mu.Lock()
+	h.holds[key]++
+	h.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			h.mu.Lock()
+			h.holds[key]--
+			if h.holds[key] <= 0 {
+				delete(h.holds, key)
+			}
+			h.mu.Unlock()
+
+			// The project stays awake for a full idle timeout after the connection closed
+			h.recordActivity(context.Background(), podName, namespace, true)
+		})
+	}
+}
+
+// HoldPod holds the project pod awake through k8sService when it is a ProjectHibernator, for streams
+// that keep using the pod after looking it up; without hibernation release does nothing
+func HoldPod(k8sService KubernetesService, podName, namespace string) (release func()) {
+	if hibernator, ok := k8sService.(*ProjectHibernator); ok {
+		return hibernator.Hold(podName, namespace)
+	}
+	return func() {}
+}
+
+// HibernateIdleProjects deletes the pods of ready projects unused for the idle timeout. Projects with
+// active sessions or open terminals are kept running.
+func (h *ProjectHibernator) HibernateIdleProjects(ctx context.Context) error {
+	idleSince := h.now().Add(-h.idleTimeout)
+	projects, err := h.projectRepo.FindIdleProjects(ctx, idleSince)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for i := range projects {
+		if err := h.hibernateProject(ctx, &projects[i], idleSince); err != nil {
+			errs = append(errs, fmt.Errorf("failed to hibernate project %s: %w", projects[i].ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (h *ProjectHibernator) hibernateProject(ctx context.Context, project *model.Project, idleSince time.Time) error {
+	if h.inUse(project.PodName, project.PodNamespace, idleSince) {
+		return nil
+	}
+
+	sessions, err := h.sessionRepo.FindActiveSessionsForProject(ctx, project.ID)
+	if err != nil {
+		return err
+	}
+	if len(sessions) > 0 {
+		return nil
+	}
+
+	// Delete the pod first: should the update fail, the project is still idle and retried on the next run
+	if err := h.KubernetesService.StopProjectPod(ctx, project.PodName, project.PodNamespace); err != nil {
+		return err
+	}
+
+	now := h.now()
+	project.Status = model.ProjectStatusHibernated
+	project.PodStatus = ""
+	project.HibernatedAt = &now
+	if err := h.projectRepo.Update(ctx, project); err != nil {
+		return err
+	}
+
+	log.Printf("[Hibernation] Project %s hibernated after being idle for %s", project.ID, h.idleTimeout)
+	return nil
+}
+
+// wake recreates the pod of a hibernated project and waits until it is ready. Concurrent requests
+// share one wake-up, which carries on when the request that started it gives up.
+func (h *ProjectHibernator) wake(ctx context.Context, project *model.Project) (string, error) {
+	h.mu.Lock()
+	w, waking := h.wakes[project.ID]
+	if !waking {
+		w = &projectWake{done: make(chan struct{})}
+		h.wakes[project.ID] = w
+		// The wake-up outlives this request, so it works on its own copy of the project
+		target := *project
+		go h.runWake(&target, w)
+	}
+	h.mu.Unlock()
+
+	select {
+	case <-w.done:
+		return w.podIP, w.err
+	case <-ctx.Done():
+		return "", fmt.Errorf("%w: %v", ErrProjectWakeFailed, ctx.Err())
+	}
+}
+
+func (h *ProjectHibernator) runWake(project *model.Project, w *projectWake) {
+	ctx, cancel := context.WithTimeout(context.Background(), h.wakeTimeout)
+	defer cancel()
+
+	w.podIP, w.err = h.wakeProject(ctx, project)
+
+	h.mu.Lock()
+	delete(h.wakes, project.ID)
+	h.mu.Unlock()
+	close(w.done)
+}
+
+func (h *ProjectHibernator) wakeProject(ctx context.Context, project *model.Project) (string, error) {
+	log.Printf("[Hibernation] Waking project %s", project.ID)
+
+	if err := h.KubernetesService.StartProjectPod(ctx, project); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrProjectWakeFailed, err)
+	}
+
+	// The project stays hibernated until its pod is ready, so a failed wake-up is retried by the next request
+	podIP, err := h.KubernetesService.WaitForPodReady(ctx, project.PodName, project.PodNamespace)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrProjectWakeFailed, err)
+	}
+
+	now := h.now()
+	project.Status = model.ProjectStatusReady
+	project.PodStatus = "Running"
+	project.HibernatedAt = nil
+	project.LastActivityAt = &now
+	if err := h.projectRepo.Update(ctx, project); err != nil {
+		return "", fmt.Errorf("failed to update project: %w", err)
+	}
+
+	h.mu.Lock()
+	h.recorded[podKey(project.PodName, project.PodNamespace)] = now
+	h.mu.Unlock()
+
+	log.Printf("[Hibernation] Project %s is awake", project.ID)
+	return podIP, nil
+}
+
+// recordActivity stores the last use of the project pod, at most once per activityRecordInterval unless forced
+func (h *ProjectHibernator) recordActivity(ctx context.Context, podName, namespace string, force bool) {
+	key := podKey(podName, namespace)
+	now := h.now()
+
+	h.mu.Lock()
+	if last, ok := h.recorded[key]; ok && !force && now.Sub(last) < activityRecordInterval {
+		h.mu.Unlock()
+		return
+	}
+	h.recorded[key] = now
+	h.mu.Unlock()
+
+	if err := h.projectRepo.UpdateLastActivity(ctx, podName, namespace, now); err != nil {
+		log.Printf("[Hibernation] Failed to record activity of pod %s: %v", key, err)
+	}
+}
+
+// inUse reports whether the pod is held or was used since idleSince without the database knowing yet
+func (h *ProjectHibernator) inUse(podName, namespace string, idleSince time.Time) bool {
+	key := podKey(podName, namespace)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.holds[key] > 0 || h.recorded[key].After(idleSince)
+}
+
+func podKey(podName, namespace string) string {
+	return namespace + "/" + podName
+}
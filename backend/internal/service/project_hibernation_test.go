@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/npinot/vibe/backend/internal/model"
+)
+
+func setupHibernatorTest() (*ProjectHibernator, *MockKubernetesService, *MockProjectRepository, *MockSessionRepository) {
+	k8sService := new(MockKubernetesService)
+	projectRepo := new(MockProjectRepository)
+	sessionRepo := new(MockSessionRepository)
+
+	hibernator := NewProjectHibernator(k8sService, projectRepo, sessionRepo, 30*time.Minute)
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	hibernator.now = func() time.Time { return now }
+
+	return hibernator, k8sService, projectRepo, sessionRepo
+}
+
+func hibernationProject(status model.ProjectStatus) *model.Project {
+	return &model.Project{
+		ID:               uuid.New(),
+		UserID:           uuid.New(),
+		Status:           status,
+		PodName:          "project-1234",
+		PodNamespace:     "vibe",
+		WorkspacePVCName: "workspace-1234",
+	}
+}
+
+func TestProjectHibernator_GetPodIP(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("records activity of running pods at most once a minute", func(t *testing.T) {
+		hibernator, k8sService, projectRepo, _ := setupHibernatorTest()
+		k8sService.On("GetPodIP", ctx, "project-1234", "vibe").Return("10.0.0.5", nil)
+		projectRepo.On("UpdateLastActivity", ctx, "project-1234", "vibe", hibernator.now()).Return(nil).Once()
+
+		for i := 0; i < 3; i++ {
+			podIP, err := hibernator.GetPodIP(ctx, "project-1234", "vibe")
+			require.NoError(t, err)
+			assert.Equal(t, "10.0.0.5", podIP)
+		}
+
+		projectRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns the pod error of projects that are not hibernated", func(t *testing.T) {
+		hibernator, k8sService, projectRepo, _ := setupHibernatorTest()
+		k8sService.On("GetPodIP", ctx, "project-1234", "vibe").Return("", errors.New("pod not found"))
+		projectRepo.On("FindByPodName", ctx, "project-1234", "vibe").Return(hibernationProject(model.ProjectStatusError), nil)
+
+		_, err := hibernator.GetPodIP(ctx, "project-1234", "vibe")
+
+		assert.EqualError(t, err, "pod not found")
+		k8sService.AssertNotCalled(t, "StartProjectPod", mock.Anything, mock.Anything)
+	})
+
+	t.Run("wakes hibernated projects once for concurrent requests", func(t *testing.T) {
+		hibernator, k8sService, projectRepo, _ := setupHibernatorTest()
+		project := hibernationProject(model.ProjectStatusHibernated)
+		hibernatedAt := hibernator.now().Add(-time.Hour)
+		project.HibernatedAt = &hibernatedAt
+
+		var lookups atomic.Int32
+		k8sService.On("GetPodIP", ctx, "project-1234", "vibe").Return("", errors.New("pod not found"))
+		projectRepo.On("FindByPodName", ctx, "project-1234", "vibe").
+			Run(func(mock.Arguments) { lookups.Add(1) }).Return(project, nil)
+		release := make(chan time.Time)
+		k8sService.On("StartProjectPod", mock.Anything, project).Return(nil).Once()
+		k8sService.On("WaitForPodReady", mock.Anything, "project-1234", "vibe").
+			WaitUntil(release).Return("10.0.0.7", nil).Once()
+		projectRepo.On("Update", mock.Anything, mock.MatchedBy(func(p *model.Project) bool {
+			return p.ID == project.ID && p.Status == model.ProjectStatusReady && p.HibernatedAt == nil && p.LastActivityAt != nil
+		})).Return(nil).Once()
+
+		var wg sync.WaitGroup
+		results := make([]string, 3)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				podIP, err := hibernator.GetPodIP(ctx, "project-1234", "vibe")
+				assert.NoError(t, err)
+				results[i] = podIP
+			}(i)
+		}
+
+		// Let every request join the wake-up before the pod becomes ready
+		require.Eventually(t, func() bool {
+			return lookups.Load() == 3
+		}, time.Second, time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, []string{"10.0.0.7", "10.0.0.7", "10.0.0.7"}, results)
+		k8sService.AssertExpectations(t)
+		projectRepo.AssertExpectations(t)
+	})
+
+	t.Run("keeps the project hibernated when the pod does not become ready", func(t *testing.T) {
+		hibernator, k8sService, projectRepo, _ := setupHibernatorTest()
+		project := hibernationProject(model.ProjectStatusHibernated)
+		k8sService.On("GetPodIP", ctx, "project-1234", "vibe").Return("", errors.New("pod not found"))
+		projectRepo.On("FindByPodName", ctx, "project-1234", "vibe").Return(project, nil)
+		k8sService.On("StartProjectPod", mock.Anything, project).Return(nil)
+		k8sService.On("WaitForPodReady", mock.Anything, "project-1234", "vibe").Return("", errors.New("pod failed: evicted"))
+
+		_, err := hibernator.GetPodIP(ctx, "project-1234", "vibe")
+
+		assert.ErrorIs(t, err, ErrProjectWakeFailed)
+		assert.Equal(t, model.ProjectStatusHibernated, project.Status)
+		projectRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+}
+
+func TestProjectHibernator_HibernateIdleProjects(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("deletes the pod of idle projects", func(t *testing.T) {
+		hibernator, k8sService, projectRepo, sessionRepo := setupHibernatorTest()
+		project := hibernationProject(model.ProjectStatusReady)
+		projectRepo.On("FindIdleProjects", ctx, hibernator.now().Add(-30*time.Minute)).Return([]model.Project{*project}, nil)
+		sessionRepo.On("FindActiveSessionsForProject", ctx, project.ID).Return([]model.Session{}, nil)
+		k8sService.On("StopProjectPod", ctx, "project-1234", "vibe").Return(nil)
+		projectRepo.On("Update", ctx, mock.MatchedBy(func(p *model.Project) bool {
+			return p.ID == project.ID && p.Status == model.ProjectStatusHibernated &&
+				p.PodName == "project-1234" && p.HibernatedAt != nil
+		})).Return(nil)
+
+		require.NoError(t, hibernator.HibernateIdleProjects(ctx))
+
+		k8sService.AssertExpectations(t)
+		projectRepo.AssertExpectations(t)
+	})
+
+	t.Run("keeps projects with active sessions", func(t *testing.T) {
+		hibernator, k8sService, projectRepo, sessionRepo := setupHibernatorTest()
+		project := hibernationProject(model.ProjectStatusReady)
+		projectRepo.On("FindIdleProjects", ctx, mock.Anything).Return([]model.Project{*project}, nil)
+		sessionRepo.On("FindActiveSessionsForProject", ctx, project.ID).
+			Return([]model.Session{{ID: uuid.New(), Status: model.SessionStatusRunning}}, nil)
+
+		require.NoError(t, hibernator.HibernateIdleProjects(ctx))
+
+		k8sService.AssertNotCalled(t, "StopProjectPod", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("keeps projects with open terminals until they close", func(t *testing.T) {
+		hibernator, k8sService, projectRepo, sessionRepo := setupHibernatorTest()
+		project := hibernationProject(model.ProjectStatusReady)
+		projectRepo.On("FindIdleProjects", ctx, mock.Anything).Return([]model.Project{*project}, nil)
+		sessionRepo.On("FindActiveSessionsForProject", ctx, project.ID).Return([]model.Session{}, nil)
+		projectRepo.On("UpdateLastActivity", mock.Anything, "project-1234", "vibe", mock.Anything).Return(nil)
+
+		release := hibernator.Hold("project-1234", "vibe")
+		require.NoError(t, hibernator.HibernateIdleProjects(ctx))
+		k8sService.AssertNotCalled(t, "StopProjectPod", mock.Anything, mock.Anything, mock.Anything)
+
+		// Closing the terminal counts as activity, so the project gets a full idle timeout again
+		release()
+		release()
+		projectRepo.AssertNumberOfCalls(t, "UpdateLastActivity", 1)
+		require.NoError(t, hibernator.HibernateIdleProjects(ctx))
+		k8sService.AssertNotCalled(t, "StopProjectPod", mock.Anything, mock.Anything, mock.Anything)
+
+		hibernator.now = func() time.Time { return time.Date(2026, 3, 15, 13, 0, 0, 0, time.UTC) }
+		k8sService.On("StopProjectPod", ctx, "project-1234", "vibe").Return(nil)
+		projectRepo.On("Update", ctx, mock.Anything).Return(nil)
+		require.NoError(t, hibernator.HibernateIdleProjects(ctx))
+		k8sService.AssertCalled(t, "StopProjectPod", ctx, "project-1234", "vibe")
+	})
+
+	t.Run("reports projects that failed to hibernate", func(t *testing.T) {
+		hibernator, k8sService, projectRepo, sessionRepo := setupHibernatorTest()
+		project := hibernationProject(model.ProjectStatusReady)
+		projectRepo.On("FindIdleProjects", ctx, mock.Anything).Return([]model.Project{*project}, nil)
+		sessionRepo.On("FindActiveSessionsForProject", ctx, project.ID).Return([]model.Session{}, nil)
+		k8sService.On("StopProjectPod", ctx, "project-1234", "vibe").Return(errors.New("forbidden"))
+
+		err := hibernator.HibernateIdleProjects(ctx)
+
+		assert.ErrorContains(t, err, "forbidden")
+		projectRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+}
+
+func TestHoldPod(t *testing.T) {
+	hibernator, _, projectRepo, _ := setupHibernatorTest()
+	projectRepo.On("UpdateLastActivity", mock.Anything, "project-1234", "vibe", mock.Anything).Return(nil)
+
+	release := HoldPod(hibernator, "project-1234", "vibe")
+	assert.Equal(t, 1, hibernator.holds[podKey("project-1234", "vibe")])
+	release()
+	assert.NotContains(t, hibernator.holds, podKey("project-1234", "vibe"))
+
+	// Without hibernation there is nothing to hold
+	HoldPod(new(MockKubernetesService), "project-1234", "vibe")()
+}
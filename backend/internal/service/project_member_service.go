@@ -0,0 +1,327 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/npinot/vibe/backend/internal/model"
+	"github.com/npinot/vibe/backend/internal/repository"
+)
+
+var (
+	ErrMemberNotFound     = errors.New("project member not found")
+	ErrInvitationNotFound = errors.New("invitation not found")
+	ErrInvalidMemberRole  = errors.New("invalid member role")
+	ErrInvalidEmail       = errors.New("invalid email address")
+	ErrAlreadyMember      = errors.New("user already has access to the project")
+)
+
+// ProjectMemberService shares projects with other users. The owner invites users by email
+// with a role; invited users see their invitations once signed in and accept or decline them.
+type ProjectMemberService interface {
+	// ListMembers lists the members of a project, oldest first. The owner is not a member.
+	ListMembers(ctx context.Context, projectID, userID uuid.UUID) ([]model.ProjectMember, error)
+
+	// UpdateMemberRole changes the role of the member memberID, the user ID of the member
+	UpdateMemberRole(ctx context.Context, projectID, memberID, userID uuid.UUID, role model.ProjectRole) (*model.ProjectMember, error)
+
+	// RemoveMember removes the member memberID from a project. Members may remove themselves.
+	RemoveMember(ctx context.Context, projectID, memberID, userID uuid.UUID) error
+
+	// ListInvitations lists the pending invitations to a project, oldest first
+	ListInvitations(ctx context.Context, projectID, userID uuid.UUID) ([]model.ProjectInvitation, error)
+
+	// InviteMember invites an email address to join a project with a role. Inviting the same
+	// address again replaces the role of the pending invitation.
+	InviteMember(ctx context.Context, projectID, userID uuid.UUID, email string, role model.ProjectRole) (*model.ProjectInvitation, error)
+
+	// RevokeInvitation deletes a pending invitation to a project
+	RevokeInvitation(ctx context.Context, projectID, invitationID, userID uuid.UUID) error
+
+	// ListMyInvitations lists the pending invitations sent to the email address of the user
+	ListMyInvitations(ctx context.Context, userID uuid.UUID) ([]model.ProjectInvitation, error)
+
+	// AcceptInvitation makes the user a member of the project with the role of the invitation
+	AcceptInvitation(ctx context.Context, invitationID, userID uuid.UUID) (*model.ProjectMember, error)
+
+	// DeclineInvitation deletes an invitation sent to the user
+	DeclineInvitation(ctx context.Context, invitationID, userID uuid.UUID) error
+}
+
+type projectMemberService struct {
+	memberRepo  repository.ProjectMemberRepository
+	projectRepo repository.ProjectRepository
+	userRepo    repository.UserRepository
+	authz       AuthorizationService
+}
+
+func NewProjectMemberService(memberRepo repository.ProjectMemberRepository, projectRepo repository.ProjectRepository, userRepo repository.UserRepository, authz AuthorizationService) ProjectMemberService {
+	return &projectMemberService{
+		memberRepo:  memberRepo,
+		projectRepo: projectRepo,
+		userRepo:    userRepo,
+		authz:       authz,
+	}
+}
+
+func (s *projectMemberService) ListMembers(ctx context.Context, projectID, userID uuid.UUID) ([]model.ProjectMember, error) {
+	if _, err := s.authz.AuthorizeProject(ctx, projectID, userID, model.ProjectRoleViewer); err != nil {
+		return nil, err
+	}
+
+	return s.memberRepo.FindMembers(ctx, projectID)
+}
+
+func (s *projectMemberService) UpdateMemberRole(ctx context.Context, projectID, memberID, userID uuid.UUID, role model.ProjectRole) (*model.ProjectMember, error) {
+	if _, err := s.authz.AuthorizeProject(ctx, projectID, userID, model.ProjectRoleOwner); err != nil {
+		return nil, err
+	}
+
+	if err := validateMemberRole(role); err != nil {
+		return nil, err
+	}
+
+	member, err := s.findMember(ctx, projectID, memberID)
+	if err != nil {
+		return nil, err
+	}
+
+	member.Role = role
+	if err := s.memberRepo.UpdateMember(ctx, member); err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+func (s *projectMemberService) RemoveMember(ctx context.Context, projectID, memberID, userID uuid.UUID) error {
+	// Leaving a project only takes access to it
+	role := model.ProjectRoleOwner
+	if memberID == userID {
+		role = model.ProjectRoleViewer
+	}
+
+	if _, err := s.authz.AuthorizeProject(ctx, projectID, userID, role); err != nil {
+		return err
+	}
+
+	member, err := s.findMember(ctx, projectID, memberID)
+	if err != nil {
+		return err
+	}
+
+	return s.memberRepo.DeleteMember(ctx, member.ID)
+}
+
+func (s *projectMemberService) ListInvitations(ctx context.Context, projectID, userID uuid.UUID) ([]model.ProjectInvitation, error) {
+	if _, err := s.authz.AuthorizeProject(ctx, projectID, userID, model.ProjectRoleOwner); err != nil {
+		return nil, err
+	}
+
+	return s.memberRepo.FindInvitations(ctx, projectID)
+}
+
+func (s *projectMemberService) InviteMember(ctx context.Context, projectID, userID uuid.UUID, email string, role model.ProjectRole) (*model.ProjectInvitation, error) {
+	project, err := s.authz.AuthorizeProject(ctx, projectID, userID, model.ProjectRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	email, err = normalizeEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateMemberRole(role); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkNotMember(ctx, project, email); err != nil {
+		return nil, err
+	}
+
+	invitation, err := s.memberRepo.FindInvitation(ctx, projectID, email)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to retrieve invitation: %w", err)
+		}
+
+		invitation = &model.ProjectInvitation{
+			ProjectID: projectID,
+			Email:     email,
+			Role:      role,
+			InvitedBy: userID,
+		}
+		if err := s.memberRepo.CreateInvitation(ctx, invitation); err != nil {
+			return nil, err
+		}
+		return invitation, nil
+	}
+
+	invitation.Role = role
+	invitation.InvitedBy = userID
+	if err := s.memberRepo.UpdateInvitation(ctx, invitation); err != nil {
+		return nil, err
+	}
+
+	return invitation, nil
+}
+
+func (s *projectMemberService) RevokeInvitation(ctx context.Context, projectID, invitationID, userID uuid.UUID) error {
+	if _, err := s.authz.AuthorizeProject(ctx, projectID, userID, model.ProjectRoleOwner); err != nil {
+		return err
+	}
+
+	invitation, err := s.findInvitation(ctx, invitationID)
+	if err != nil {
+		return err
+	}
+	if invitation.ProjectID != projectID {
+		return ErrInvitationNotFound
+	}
+
+	return s.memberRepo.DeleteInvitation(ctx, invitation.ID)
+}
+
+func (s *projectMemberService) ListMyInvitations(ctx context.Context, userID uuid.UUID) ([]model.ProjectInvitation, error) {
+	user, err := s.userRepo.FindByID(ctx, userID.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve user: %w", err)
+	}
+
+	return s.memberRepo.FindInvitationsByEmail(ctx, strings.ToLower(user.Email))
+}
+
+func (s *projectMemberService) AcceptInvitation(ctx context.Context, invitationID, userID uuid.UUID) (*model.ProjectMember, error) {
+	invitation, err := s.userInvitation(ctx, invitationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := s.projectRepo.FindByID(ctx, invitation.ProjectID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, fmt.Errorf("failed to retrieve project: %w", err)
+	}
+
+	if _, err := s.authz.ProjectRole(ctx, project, userID); err == nil {
+		return nil, ErrAlreadyMember
+	} else if !errors.Is(err, ErrUnauthorized) {
+		return nil, err
+	}
+
+	member := &model.ProjectMember{
+		ProjectID: invitation.ProjectID,
+		UserID:    userID,
+		Role:      invitation.Role,
+		InvitedBy: invitation.InvitedBy,
+	}
+	if err := s.memberRepo.AcceptInvitation(ctx, invitation, member); err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+func (s *projectMemberService) DeclineInvitation(ctx context.Context, invitationID, userID uuid.UUID) error {
+	invitation, err := s.userInvitation(ctx, invitationID, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.memberRepo.DeleteInvitation(ctx, invitation.ID)
+}
+
+// userInvitation retrieves an invitation sent to the email address of the user.
+// Invitations sent to other addresses are reported as not found.
+func (s *projectMemberService) userInvitation(ctx context.Context, invitationID, userID uuid.UUID) (*model.ProjectInvitation, error) {
+	invitation, err := s.findInvitation(ctx, invitationID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve user: %w", err)
+	}
+
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		return nil, ErrInvitationNotFound
+	}
+
+	return invitation, nil
+}
+
+// checkNotMember rejects inviting the email address of the owner or of a member of the project
+func (s *projectMemberService) checkNotMember(ctx context.Context, project *model.Project, email string) error {
+	owner, err := s.userRepo.FindByID(ctx, project.UserID.String())
+	if err != nil {
+		return fmt.Errorf("failed to retrieve project owner: %w", err)
+	}
+	if strings.EqualFold(owner.Email, email) {
+		return ErrAlreadyMember
+	}
+
+	members, err := s.memberRepo.FindMembers(ctx, project.ID)
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		if member.User != nil && strings.EqualFold(member.User.Email, email) {
+			return ErrAlreadyMember
+		}
+	}
+
+	return nil
+}
+
+func (s *projectMemberService) findMember(ctx context.Context, projectID, memberID uuid.UUID) (*model.ProjectMember, error) {
+	member, err := s.memberRepo.FindMember(ctx, projectID, memberID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMemberNotFound
+		}
+		return nil, fmt.Errorf("failed to retrieve project member: %w", err)
+	}
+
+	return member, nil
+}
+
+func (s *projectMemberService) findInvitation(ctx context.Context, invitationID uuid.UUID) (*model.ProjectInvitation, error) {
+	invitation, err := s.memberRepo.FindInvitationByID(ctx, invitationID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvitationNotFound
+		}
+		return nil, fmt.Errorf("failed to retrieve invitation: %w", err)
+	}
+
+	return invitation, nil
+}
+
+// validateMemberRole accepts the roles members can hold; ownership cannot be shared
+func validateMemberRole(role model.ProjectRole) error {
+	switch role {
+	case model.ProjectRoleMaintainer, model.ProjectRoleViewer:
+		return nil
+	default:
+		return fmt.Errorf("%w: must be %s or %s", ErrInvalidMemberRole, model.ProjectRoleMaintainer, model.ProjectRoleViewer)
+	}
+}
+
+// normalizeEmail checks email is a bare address and lowercases it
+func normalizeEmail(email string) (string, error) {
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return "", fmt.Errorf("%w: %q", ErrInvalidEmail, email)
+	}
+
+	return strings.ToLower(email), nil
+}
@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/npinot/vibe/backend/internal/model"
+)
+
+type projectMemberTest struct {
+	svc         ProjectMemberService
+	memberRepo  *MockProjectMemberRepository
+	projectRepo *MockProjectRepository
+	userRepo    *MockUserRepository
+	project     *model.Project
+	owner       *model.User
+}
+
+func setupProjectMemberTest() *projectMemberTest {
+	memberRepo := new(MockProjectMemberRepository)
+	projectRepo := new(MockProjectRepository)
+	userRepo := new(MockUserRepository)
+	owner := &model.User{ID: uuid.New(), Email: "owner@example.com"}
+	project := &model.Project{ID: uuid.New(), UserID: owner.ID, Name: "Shared"}
+
+	projectRepo.On("FindByID", mock.Anything, project.ID).Return(project, nil)
+	userRepo.On("FindByID", mock.Anything, owner.ID.String()).Return(owner, nil)
+
+	svc := NewProjectMemberService(memberRepo, projectRepo, userRepo, NewAuthorizationService(projectRepo, memberRepo))
+	return &projectMemberTest{svc: svc, memberRepo: memberRepo, projectRepo: projectRepo, userRepo: userRepo, project: project, owner: owner}
+}
+
+func TestProjectMemberService_InviteMember(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("creates invitation with lowercase email", func(t *testing.T) {
+		tc := setupProjectMemberTest()
+		tc.memberRepo.On("FindMembers", ctx, tc.project.ID).Return([]model.ProjectMember{}, nil)
+		tc.memberRepo.On("FindInvitation", ctx, tc.project.ID, "dev@example.com").Return(nil, gorm.ErrRecordNotFound)
+		tc.memberRepo.On("CreateInvitation", ctx, mock.AnythingOfType("*model.ProjectInvitation")).Return(nil)
+
+		invitation, err := tc.svc.InviteMember(ctx, tc.project.ID, tc.owner.ID, "Dev@Example.com", model.ProjectRoleMaintainer)
+
+		require.NoError(t, err)
+		assert.Equal(t, "dev@example.com", invitation.Email)
+		assert.Equal(t, model.ProjectRoleMaintainer, invitation.Role)
+		assert.Equal(t, tc.owner.ID, invitation.InvitedBy)
+	})
+
+	t.Run("inviting again replaces the role", func(t *testing.T) {
+		tc := setupProjectMemberTest()
+		existing := &model.ProjectInvitation{ID: uuid.New(), ProjectID: tc.project.ID, Email: "dev@example.com", Role: model.ProjectRoleMaintainer}
+		tc.memberRepo.On("FindMembers", ctx, tc.project.ID).Return([]model.ProjectMember{}, nil)
+		tc.memberRepo.On("FindInvitation", ctx, tc.project.ID, "dev@example.com").Return(existing, nil)
+		tc.memberRepo.On("UpdateInvitation", ctx, existing).Return(nil)
+
+		invitation, err := tc.svc.InviteMember(ctx, tc.project.ID, tc.owner.ID, "dev@example.com", model.ProjectRoleViewer)
+
+		require.NoError(t, err)
+		assert.Equal(t, existing.ID, invitation.ID)
+		assert.Equal(t, model.ProjectRoleViewer, invitation.Role)
+		tc.memberRepo.AssertNotCalled(t, "CreateInvitation", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		memberID := uuid.New()
+
+		tests := []struct {
+			name     string
+			asMember bool // invite as the maintainer instead of the owner
+			email    string
+			role     model.ProjectRole
+			wantErr  error
+		}{
+			{name: "not the owner", asMember: true, email: "dev@example.com", role: model.ProjectRoleViewer, wantErr: ErrUnauthorized},
+			{name: "invalid email", email: "not an email", role: model.ProjectRoleViewer, wantErr: ErrInvalidEmail},
+			{name: "display name", email: "Dev <dev@example.com>", role: model.ProjectRoleViewer, wantErr: ErrInvalidEmail},
+			{name: "owner role", email: "dev@example.com", role: model.ProjectRoleOwner, wantErr: ErrInvalidMemberRole},
+			{name: "owner email", email: "OWNER@example.com", role: model.ProjectRoleViewer, wantErr: ErrAlreadyMember},
+			{name: "member email", email: "member@example.com", role: model.ProjectRoleViewer, wantErr: ErrAlreadyMember},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				tc := setupProjectMemberTest()
+				expectMember(tc.memberRepo, tc.project, memberID, model.ProjectRoleMaintainer)
+				tc.memberRepo.On("FindMembers", ctx, tc.project.ID).Return([]model.ProjectMember{
+					{UserID: memberID, Role: model.ProjectRoleMaintainer, User: &model.User{ID: memberID, Email: "member@example.com"}},
+				}, nil)
+
+				userID := tc.owner.ID
+				if tt.asMember {
+					userID = memberID
+				}
+
+				_, err := tc.svc.InviteMember(ctx, tc.project.ID, userID, tt.email, tt.role)
+
+				assert.ErrorIs(t, err, tt.wantErr)
+				tc.memberRepo.AssertNotCalled(t, "CreateInvitation", mock.Anything, mock.Anything)
+			})
+		}
+	})
+}
+
+func TestProjectMemberService_UpdateMemberRole(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("owner changes the role", func(t *testing.T) {
+		tc := setupProjectMemberTest()
+		memberID := uuid.New()
+		expectMember(tc.memberRepo, tc.project, memberID, model.ProjectRoleViewer)
+		tc.memberRepo.On("UpdateMember", ctx, mock.AnythingOfType("*model.ProjectMember")).Return(nil)
+
+		member, err := tc.svc.UpdateMemberRole(ctx, tc.project.ID, memberID, tc.owner.ID, model.ProjectRoleMaintainer)
+
+		require.NoError(t, err)
+		assert.Equal(t, model.ProjectRoleMaintainer, member.Role)
+	})
+
+	t.Run("member not found", func(t *testing.T) {
+		tc := setupProjectMemberTest()
+		memberID := uuid.New()
+		tc.memberRepo.On("FindMember", ctx, tc.project.ID, memberID).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := tc.svc.UpdateMemberRole(ctx, tc.project.ID, memberID, tc.owner.ID, model.ProjectRoleViewer)
+
+		assert.ErrorIs(t, err, ErrMemberNotFound)
+	})
+
+	t.Run("maintainers cannot change roles", func(t *testing.T) {
+		tc := setupProjectMemberTest()
+		maintainerID := uuid.New()
+		expectMember(tc.memberRepo, tc.project, maintainerID, model.ProjectRoleMaintainer)
+
+		_, err := tc.svc.UpdateMemberRole(ctx, tc.project.ID, maintainerID, maintainerID, model.ProjectRoleViewer)
+
+		assert.ErrorIs(t, err, ErrUnauthorized)
+		tc.memberRepo.AssertNotCalled(t, "UpdateMember", mock.Anything, mock.Anything)
+	})
+}
+
+func TestProjectMemberService_RemoveMember(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("owner removes a member", func(t *testing.T) {
+		tc := setupProjectMemberTest()
+		memberID := uuid.New()
+		expectMember(tc.memberRepo, tc.project, memberID, model.ProjectRoleMaintainer)
+		tc.memberRepo.On("DeleteMember", ctx, mock.AnythingOfType("uuid.UUID")).Return(nil)
+
+		require.NoError(t, tc.svc.RemoveMember(ctx, tc.project.ID, memberID, tc.owner.ID))
+		tc.memberRepo.AssertCalled(t, "DeleteMember", ctx, mock.AnythingOfType("uuid.UUID"))
+	})
+
+	t.Run("viewer leaves the project", func(t *testing.T) {
+		tc := setupProjectMemberTest()
+		viewerID := uuid.New()
+		expectMember(tc.memberRepo, tc.project, viewerID, model.ProjectRoleViewer)
+		tc.memberRepo.On("DeleteMember", ctx, mock.AnythingOfType("uuid.UUID")).Return(nil)
+
+		require.NoError(t, tc.svc.RemoveMember(ctx, tc.project.ID, viewerID, viewerID))
+	})
+
+	t.Run("viewer cannot remove other members", func(t *testing.T) {
+		tc := setupProjectMemberTest()
+		viewerID := uuid.New()
+		memberID := uuid.New()
+		expectMember(tc.memberRepo, tc.project, viewerID, model.ProjectRoleViewer)
+
+		err := tc.svc.RemoveMember(ctx, tc.project.ID, memberID, viewerID)
+
+		assert.ErrorIs(t, err, ErrUnauthorized)
+		tc.memberRepo.AssertNotCalled(t, "DeleteMember", mock.Anything, mock.Anything)
+	})
+}
+
+func TestProjectMemberService_RevokeInvitation(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("deletes the invitation", func(t *testing.T) {
+		tc := setupProjectMemberTest()
+		invitation := &model.ProjectInvitation{ID: uuid.New(), ProjectID: tc.project.ID, Email: "dev@example.com"}
+		tc.memberRepo.On("FindInvitationByID", ctx, invitation.ID).Return(invitation, nil)
+		tc.memberRepo.On("DeleteInvitation", ctx, invitation.ID).Return(nil)
+
+		require.NoError(t, tc.svc.RevokeInvitation(ctx, tc.project.ID, invitation.ID, tc.owner.ID))
+	})
+
+	t.Run("invitation to another project", func(t *testing.T) {
+		tc := setupProjectMemberTest()
+		invitation := &model.ProjectInvitation{ID: uuid.New(), ProjectID: uuid.New(), Email: "dev@example.com"}
+		tc.memberRepo.On("FindInvitationByID", ctx, invitation.ID).Return(invitation, nil)
+
+		err := tc.svc.RevokeInvitation(ctx, tc.project.ID, invitation.ID, tc.owner.ID)
+
+		assert.ErrorIs(t, err, ErrInvitationNotFound)
+		tc.memberRepo.AssertNotCalled(t, "DeleteInvitation", mock.Anything, mock.Anything)
+	})
+}
+
+func TestProjectMemberService_AcceptInvitation(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func() (*projectMemberTest, *model.User, *model.ProjectInvitation) {
+		tc := setupProjectMemberTest()
+		invitee := &model.User{ID: uuid.New(), Email: "Dev@Example.com"}
+		invitation := &model.ProjectInvitation{ID: uuid.New(), ProjectID: tc.project.ID, Email: "dev@example.com", Role: model.ProjectRoleViewer, InvitedBy: tc.owner.ID}
+		tc.userRepo.On("FindByID", mock.Anything, invitee.ID.String()).Return(invitee, nil)
+		tc.memberRepo.On("FindInvitationByID", ctx, invitation.ID).Return(invitation, nil)
+		return tc, invitee, invitation
+	}
+
+	t.Run("becomes a member with the invitation role", func(t *testing.T) {
+		tc, invitee, invitation := setup()
+		tc.memberRepo.On("FindMember", ctx, tc.project.ID, invitee.ID).Return(nil, gorm.ErrRecordNotFound)
+		tc.memberRepo.On("AcceptInvitation", ctx, invitation, mock.AnythingOfType("*model.ProjectMember")).Return(nil)
+
+		member, err := tc.svc.AcceptInvitation(ctx, invitation.ID, invitee.ID)
+
+		require.NoError(t, err)
+		assert.Equal(t, invitee.ID, member.UserID)
+		assert.Equal(t, tc.project.ID, member.ProjectID)
+		assert.Equal(t, model.ProjectRoleViewer, member.Role)
+		assert.Equal(t, tc.owner.ID, member.InvitedBy)
+	})
+
+	t.Run("invitation sent to someone else", func(t *testing.T) {
+		tc, _, invitation := setup()
+		other := &model.User{ID: uuid.New(), Email: "other@example.com"}
+		tc.userRepo.On("FindByID", mock.Anything, other.ID.String()).Return(other, nil)
+
+		_, err := tc.svc.AcceptInvitation(ctx, invitation.ID, other.ID)
+
+		assert.ErrorIs(t, err, ErrInvitationNotFound)
+		tc.memberRepo.AssertNotCalled(t, "AcceptInvitation", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("already a member", func(t *testing.T) {
+		tc, invitee, invitation := setup()
+		expectMember(tc.memberRepo, tc.project, invitee.ID, model.ProjectRoleMaintainer)
+
+		_, err := tc.svc.AcceptInvitation(ctx, invitation.ID, invitee.ID)
+
+		assert.ErrorIs(t, err, ErrAlreadyMember)
+	})
+
+	t.Run("decline deletes the invitation", func(t *testing.T) {
+		tc, invitee, invitation := setup()
+		tc.memberRepo.On("DeleteInvitation", ctx, invitation.ID).Return(nil)
+
+		require.NoError(t, tc.svc.DeclineInvitation(ctx, invitation.ID, invitee.ID))
+	})
+}
+
+func TestProjectMemberService_ListMyInvitations(t *testing.T) {
+	tc := setupProjectMemberTest()
+	ctx := context.Background()
+	user := &model.User{ID: uuid.New(), Email: "Dev@Example.com"}
+	invitations := []model.ProjectInvitation{{ID: uuid.New(), ProjectID: tc.project.ID, Email: "dev@example.com"}}
+	tc.userRepo.On("FindByID", ctx, user.ID.String()).Return(user, nil)
+	tc.memberRepo.On("FindInvitationsByEmail", ctx, "dev@example.com").Return(invitations, nil)
+
+	got, err := tc.svc.ListMyInvitations(ctx, user.ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, invitations, got)
+}
@@ -4,11 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"log"
 	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
-	"gorm.io/gorm"
+	"k8s.io/apimachinery/pkg/api/resource"
 
 	"github.com/npinot/vibe/backend/internal/model"
 	"github.com/npinot/vibe/backend/internal/repository"
@@ -21,41 +25,94 @@ var (
 	ErrInvalidRepoURL     = errors.New("invalid repository URL")
 	ErrPodCreationFailed  = errors.New("failed to create Kubernetes pod")
 	ErrPodDeletionFailed  = errors.New("failed to delete Kubernetes pod")
+	ErrInvalidRepoRef     = errors.New("invalid repository ref")
+	ErrInvalidCloneDepth  = errors.New("invalid clone depth")
+	ErrInvalidCredential  = errors.New("invalid repository credential")
+	ErrProjectPodMissing  = errors.New("project has no pod")
+	ErrInvalidLogOptions  = errors.New("invalid log options")
+
+	// ErrContainerLogsUnavailable is returned when the container has no logs, e.g. while it waits to start
+	ErrContainerLogsUnavailable = errors.New("container logs are not available")
 )
 
+// resourceResizeTimeout bounds rolling the pod and expanding the PVC after a resource profile change
+const resourceResizeTimeout = 5 * time.Minute
+
+// Log streaming limits; without a tail a log request returns the last defaultLogTailLines lines
+const (
+	defaultLogTailLines = 500
+	maxLogTailLines     = 5000
+)
+
+// logContainers are the project pod containers whose logs can be streamed
+var logContainers = []string{"opencode-server", "file-browser", "session-proxy", repoCloneContainerName}
+
+// maxCloneDepth bounds shallow clones; deeper histories should use a full clone
+const maxCloneDepth = 10000
+
+// RepoCloneOptions controls how RepoURL is cloned into the project workspace
+type RepoCloneOptions struct {
+	Ref        string // Branch or tag to check out (empty = remote default branch)
+	Depth      int    // Shallow clone depth (0 = full history)
+	Credential string // Access token (HTTPS) or private key (SSH) for private repositories
+}
+
 // ProjectService defines business logic operations for project management
 type ProjectService interface {
-	// CreateProject creates a new project with a Kubernetes pod
-	CreateProject(ctx context.Context, userID uuid.UUID, name, description, repoURL string) (*model.Project, error)
+	// CreateProject creates a new project with a Kubernetes pod, cloning repoURL into its workspace
+	CreateProject(ctx context.Context, userID uuid.UUID, name, description, repoURL string, repoOpts RepoCloneOptions, resources ResourceSelection) (*model.Project, error)
 
-	// GetProject retrieves a project by ID with authorization check
+	// GetProject retrieves a project by ID for any user with access to it
 	GetProject(ctx context.Context, id, userID uuid.UUID) (*model.Project, error)
 
-	// ListProjects retrieves all projects for a user
+	// ListProjects retrieves the projects a user owns, followed by the projects shared with the user
 	ListProjects(ctx context.Context, userID uuid.UUID) ([]model.Project, error)
 
-	// UpdateProject updates project fields with authorization check
+	// UpdateProject updates project fields; only the owner may
 	UpdateProject(ctx context.Context, id, userID uuid.UUID, updates map[string]interface{}) (*model.Project, error)
 
-	// DeleteProject deletes a project and its Kubernetes pod
+	// DeleteProject deletes a project and its Kubernetes pod; only the owner may
 	DeleteProject(ctx context.Context, id, userID uuid.UUID) error
+
+	// WakeProject recreates the pod of a hibernated project and waits until it is ready
+	WakeProject(ctx context.Context, id, userID uuid.UUID) (*model.Project, error)
+
+	// WatchProjectStatus streams the status of the project pod and the Kubernetes events about it until ctx is done
+	WatchProjectStatus(ctx context.Context, id, userID uuid.UUID) (<-chan PodStatusUpdate, error)
+
+	// UpdateProjectResources switches the project to another resource profile and rolls its pod; only the owner may
+	UpdateProjectResources(ctx context.Context, id, userID uuid.UUID, resources ResourceSelection) (*model.Project, error)
+
+	// StreamProjectLogs streams the logs of a container of the project pod
+	StreamProjectLogs(ctx context.Context, id, userID uuid.UUID, opts ContainerLogOptions) (io.ReadCloser, error)
 }
 
 type projectService struct {
 	projectRepo repository.ProjectRepository
+	authz       AuthorizationService
 	k8sService  KubernetesService
+	cipher      SecretCipher
+	profiles    ResourceProfileService
+	archives    ArchiveStore
 }
 
-// NewProjectService creates a new project service
-func NewProjectService(projectRepo repository.ProjectRepository, k8sService KubernetesService) ProjectService {
+// NewProjectService creates a new project service.
+// cipher encrypts repository credentials; without it private repositories are rejected.
+// profiles checks resource profiles against the user's caps; without it profiles are not capped.
+// archives holds the workspaces of archived projects, which are removed with the project.
+func NewProjectService(projectRepo repository.ProjectRepository, authz AuthorizationService, k8sService KubernetesService, cipher SecretCipher, profiles ResourceProfileService, archives ArchiveStore) ProjectService {
 	return &projectService{
 		projectRepo: projectRepo,
+		authz:       authz,
 		k8sService:  k8sService,
+		cipher:      cipher,
+		profiles:    profiles,
+		archives:    archives,
 	}
 }
 
 // CreateProject creates a new project with validation and Kubernetes pod spawning
-func (s *projectService) CreateProject(ctx context.Context, userID uuid.UUID, name, description, repoURL string) (*model.Project, error) {
+func (s *projectService) CreateProject(ctx context.Context, userID uuid.UUID, name, description, repoURL string, repoOpts RepoCloneOptions, resources ResourceSelection) (*model.Project, error) {
 	// Validate input
 	if err := validateProjectName(name); err != nil {
 		return nil, err
@@ -65,6 +122,14 @@ func (s *projectService) CreateProject(ctx context.Context, userID uuid.UUID, na
 		if err := validateRepoURL(repoURL); err != nil {
 			return nil, err
 		}
+		if err := validateRepoCloneOptions(repoOpts); err != nil {
+			return nil, err
+		}
+	}
+
+	profile, err := s.resolveResources(ctx, userID, resources)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create project entity
@@ -76,6 +141,24 @@ func (s *projectService) CreateProject(ctx context.Context, userID uuid.UUID, na
 		RepoURL:     repoURL,
 		Status:      model.ProjectStatusInitializing,
 	}
+	applyResourceProfile(project, profile)
+
+	if repoURL != "" {
+		project.RepoRef = strings.TrimSpace(repoOpts.Ref)
+		project.RepoCloneDepth = repoOpts.Depth
+
+		if repoOpts.Credential != "" {
+			if s.cipher == nil {
+				return nil, fmt.Errorf("%w: credential encryption is not configured", ErrInvalidCredential)
+			}
+			encrypted, err := s.cipher.Encrypt(repoOpts.Credential)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encrypt repository credential: %w", err)
+			}
+			project.RepoCredentialEncrypted = encrypted
+			project.RepoCredential = repoOpts.Credential
+		}
+	}
 
 	// Save to database first
 	if err := s.projectRepo.Create(ctx, project); err != nil {
@@ -85,6 +168,7 @@ func (s *projectService) CreateProject(ctx context.Context, userID uuid.UUID, na
 	// Spawn Kubernetes pod
 	if err := s.k8sService.CreateProjectPod(ctx, project); err != nil {
 		// Store error in project metadata but don't fail the creation
+		project.RepoCredential = ""
 		project.Status = model.ProjectStatusError
 		project.PodError = fmt.Sprintf("Pod creation failed: %s", err.Error())
 
@@ -96,8 +180,13 @@ func (s *projectService) CreateProject(ctx context.Context, userID uuid.UUID, na
 		return project, nil
 	}
 
-	// Update project with pod metadata
+	// Update project with pod metadata; projects with a repository stay in
+	// "cloning" until the init container has populated the workspace
+	project.RepoCredential = ""
 	project.Status = model.ProjectStatusReady
+	if project.RepoURL != "" {
+		project.Status = model.ProjectStatusCloning
+	}
 	if err := s.projectRepo.Update(ctx, project); err != nil {
 		// Pod was created but we failed to update DB - this is a partial failure
 		// The project exists in DB but status is still "initializing"
@@ -109,36 +198,69 @@ func (s *projectService) CreateProject(ctx context.Context, userID uuid.UUID, na
 
 // GetProject retrieves a project with authorization check
 func (s *projectService) GetProject(ctx context.Context, id, userID uuid.UUID) (*model.Project, error) {
-	project, err := s.projectRepo.FindByID(ctx, id)
+	return s.authorizedProject(ctx, id, userID, model.ProjectRoleViewer)
+}
+
+// authorizedProject retrieves a project on which the user holds at least the given role
+func (s *projectService) authorizedProject(ctx context.Context, id, userID uuid.UUID, role model.ProjectRole) (*model.Project, error) {
+	project, err := s.authz.AuthorizeProject(ctx, id, userID, role)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrProjectNotFound
-		}
-		return nil, fmt.Errorf("failed to retrieve project: %w", err)
+		return nil, err
 	}
 
-	// Authorization check
-	if project.UserID != userID {
-		return nil, ErrUnauthorized
+	if project.Status == model.ProjectStatusCloning {
+		s.refreshCloneStatus(ctx, project)
 	}
 
 	return project, nil
 }
 
-// ListProjects retrieves all projects for a user
+// refreshCloneStatus moves a cloning project to ready or error once the
+// repo-clone init container has finished. Lookup failures are ignored so that
+// reading a project never fails because the cluster is unreachable.
+func (s *projectService) refreshCloneStatus(ctx context.Context, project *model.Project) {
+	if project.PodName == "" || project.PodNamespace == "" {
+		return
+	}
+
+	cloneStatus, err := s.k8sService.GetRepoCloneStatus(ctx, project.PodName, project.PodNamespace)
+	if err != nil {
+		return
+	}
+
+	switch cloneStatus.Phase {
+	case RepoCloneCompleted:
+		project.Status = model.ProjectStatusReady
+		project.PodError = ""
+	case RepoCloneFailed:
+		project.Status = model.ProjectStatusError
+		project.PodError = fmt.Sprintf("Repository clone failed: %s", cloneStatus.Message)
+	default:
+		return
+	}
+
+	_ = s.projectRepo.Update(ctx, project)
+}
+
+// ListProjects retrieves the projects of a user, owned ones first
 func (s *projectService) ListProjects(ctx context.Context, userID uuid.UUID) ([]model.Project, error) {
 	projects, err := s.projectRepo.FindByUserID(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list projects: %w", err)
 	}
 
-	return projects, nil
+	shared, err := s.projectRepo.FindSharedWithUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shared projects: %w", err)
+	}
+
+	return append(projects, shared...), nil
 }
 
 // UpdateProject updates project fields with authorization check
 func (s *projectService) UpdateProject(ctx context.Context, id, userID uuid.UUID, updates map[string]interface{}) (*model.Project, error) {
 	// Retrieve and authorize
-	project, err := s.GetProject(ctx, id, userID)
+	project, err := s.authorizedProject(ctx, id, userID, model.ProjectRoleOwner)
 	if err != nil {
 		return nil, err
 	}
@@ -176,7 +298,7 @@ func (s *projectService) UpdateProject(ctx context.Context, id, userID uuid.UUID
 // DeleteProject deletes a project and its Kubernetes resources
 func (s *projectService) DeleteProject(ctx context.Context, id, userID uuid.UUID) error {
 	// Retrieve and authorize
-	project, err := s.GetProject(ctx, id, userID)
+	project, err := s.authorizedProject(ctx, id, userID, model.ProjectRoleOwner)
 	if err != nil {
 		return err
 	}
@@ -195,6 +317,197 @@ func (s *projectService) DeleteProject(ctx context.Context, id, userID uuid.UUID
 		return fmt.Errorf("failed to delete project from database: %w", err)
 	}
 
+	// The workspace of an archived project only lives in the archive store
+	if project.ArchiveKey != "" && s.archives != nil {
+		if err := s.archives.Delete(ctx, project.ArchiveKey); err != nil {
+			log.Printf("[ProjectService] Failed to delete archive %s of project %s: %v", project.ArchiveKey, project.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// WakeProject brings a hibernated project back. Looking up the pod IP through the
+// ProjectHibernator recreates the pod; projects that are not hibernated are returned as is.
+func (s *projectService) WakeProject(ctx context.Context, id, userID uuid.UUID) (*model.Project, error) {
+	project, err := s.GetProject(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if project.Status != model.ProjectStatusHibernated {
+		return project, nil
+	}
+
+	if _, err := s.k8sService.GetPodIP(ctx, project.PodName, project.PodNamespace); err != nil {
+		if errors.Is(err, ErrProjectWakeFailed) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", ErrProjectWakeFailed, err)
+	}
+
+	project, err = s.projectRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve project: %w", err)
+	}
+
+	return project, nil
+}
+
+// WatchProjectStatus streams the status of the project pod and the Kubernetes events about it until ctx is done.
+// Watching does not count as project activity, so it neither wakes nor keeps awake a hibernated project.
+func (s *projectService) WatchProjectStatus(ctx context.Context, id, userID uuid.UUID) (<-chan PodStatusUpdate, error) {
+	project, err := s.GetProject(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if project.PodName == "" || project.PodNamespace == "" || project.Status == model.ProjectStatusHibernated {
+		return nil, ErrProjectPodMissing
+	}
+
+	updates, err := s.k8sService.WatchPodStatus(ctx, project.PodName, project.PodNamespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch pod status: %w", err)
+	}
+
+	return updates, nil
+}
+
+// StreamProjectLogs streams the logs of a container of the project pod, opencode-server by default.
+// Like WatchProjectStatus it does not wake a hibernated project.
+func (s *projectService) StreamProjectLogs(ctx context.Context, id, userID uuid.UUID, opts ContainerLogOptions) (io.ReadCloser, error) {
+	project, err := s.GetProject(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Container == "" {
+		opts.Container = "opencode-server"
+	}
+	if !slices.Contains(logContainers, opts.Container) {
+		return nil, fmt.Errorf("%w: container must be one of %s", ErrInvalidLogOptions, strings.Join(logContainers, ", "))
+	}
+	if opts.TailLines == 0 {
+		opts.TailLines = defaultLogTailLines
+	}
+	if opts.TailLines < 0 || opts.TailLines > maxLogTailLines {
+		return nil, fmt.Errorf("%w: tail must be between 1 and %d lines", ErrInvalidLogOptions, maxLogTailLines)
+	}
+
+	if project.PodName == "" || project.PodNamespace == "" || project.Status == model.ProjectStatusHibernated {
+		return nil, ErrProjectPodMissing
+	}
+
+	logs, err := s.k8sService.StreamContainerLogs(ctx, project.PodName, project.PodNamespace, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// The pod stays awake until the caller closes the stream
+	return &heldReadCloser{ReadCloser: logs, release: HoldPod(s.k8sService, project.PodName, project.PodNamespace)}, nil
+}
+
+// heldReadCloser releases a hold on the project pod once the stream is closed
+type heldReadCloser struct {
+	io.ReadCloser
+	release func()
+}
+
+func (r *heldReadCloser) Close() error {
+	defer r.release()
+	return r.ReadCloser.Close()
+}
+
+// UpdateProjectResources switches the project to another resource profile. The pod is recreated with the
+// new resources and the workspace PVC expanded in the background, which ends the running sessions; failures
+// are reported through the pod error. Workspaces can grow but never shrink or move to another storage class:
+// named profiles keep a larger workspace, custom resources asking for either are rejected.
+func (s *projectService) UpdateProjectResources(ctx context.Context, id, userID uuid.UUID, resources ResourceSelection) (*model.Project, error) {
+	project, err := s.authorizedProject(ctx, id, userID, model.ProjectRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	// Caps follow the owner of the project
+	profile, err := s.resolveResources(ctx, project.UserID, resources)
+	if err != nil {
+		return nil, err
+	}
+
+	// Named profiles only set the size of new workspaces, so switching to a smaller one keeps the workspace
+	// as it is; custom resources ask for the storage explicitly and are checked below
+	if resources.Profile != ResourceProfileCustom {
+		keepWorkspaceStorage(project, profile)
+	}
+	if err := validateStorageChange(project, profile); err != nil {
+		return nil, err
+	}
+
+	applyResourceProfile(project, profile)
+	if err := s.projectRepo.Update(ctx, project); err != nil {
+		return nil, fmt.Errorf("failed to update project: %w", err)
+	}
+
+	if project.PodName != "" && project.PodNamespace != "" {
+		go s.resizeProjectResources(*project)
+	}
+
+	return project, nil
+}
+
+// resizeProjectResources applies the resources of the project to its pod and PVC
+func (s *projectService) resizeProjectResources(project model.Project) {
+	ctx, cancel := context.WithTimeout(context.Background(), resourceResizeTimeout)
+	defer cancel()
+
+	if err := s.k8sService.ResizeProjectResources(ctx, &project); err != nil {
+		log.Printf("[ProjectService] Failed to apply resource profile %s to project %s: %v", project.ResourceProfile, project.ID, err)
+		podError := fmt.Sprintf("Resource change failed: %s", err.Error())
+		if updateErr := s.projectRepo.UpdatePodStatus(ctx, project.ID, project.PodStatus, podError); updateErr != nil {
+			log.Printf("[ProjectService] Failed to record resource change error for project %s: %v", project.ID, updateErr)
+		}
+	}
+}
+
+// resolveResources resolves the resource selection of a project owned by userID
+func (s *projectService) resolveResources(ctx context.Context, userID uuid.UUID, resources ResourceSelection) (*ResourceProfile, error) {
+	if s.profiles == nil {
+		return resolveResourceSelection(resources)
+	}
+	return s.profiles.ResolveProfile(ctx, userID, resources)
+}
+
+// keepWorkspaceStorage keeps the storage class of the workspace PVC and its size where the profile's is smaller
+func keepWorkspaceStorage(project *model.Project, profile *ResourceProfile) {
+	profile.StorageClass = project.StorageClass
+
+	current, err := resource.ParseQuantity(project.StorageSize)
+	if err != nil {
+		return
+	}
+	if requested := resource.MustParse(profile.StorageSize); requested.Cmp(current) < 0 {
+		profile.StorageSize = project.StorageSize
+	}
+}
+
+// validateStorageChange rejects profiles that would shrink the workspace PVC or move it to another storage class
+func validateStorageChange(project *model.Project, profile *ResourceProfile) error {
+	if project.StorageClass != profile.StorageClass {
+		return fmt.Errorf("%w: the storage class of a workspace cannot be changed", ErrInvalidResourceProfile)
+	}
+
+	if project.StorageSize == "" {
+		return nil
+	}
+	current, err := resource.ParseQuantity(project.StorageSize)
+	if err != nil {
+		return nil
+	}
+	if requested := resource.MustParse(profile.StorageSize); requested.Cmp(current) < 0 {
+		return fmt.Errorf("%w: the workspace cannot shrink from %s to %s", ErrInvalidResourceProfile, project.StorageSize, profile.StorageSize)
+	}
+
 	return nil
 }
 
@@ -234,6 +547,26 @@ func validateRepoURL(url string) error {
 	return nil
 }
 
+// validateRepoCloneOptions validates the ref and depth used to clone the repository
+func validateRepoCloneOptions(opts RepoCloneOptions) error {
+	ref := strings.TrimSpace(opts.Ref)
+	if ref != "" {
+		validRef := regexp.MustCompile(`^[A-Za-z0-9._/\-]+$`)
+		if len(ref) > 255 || !validRef.MatchString(ref) ||
+			strings.HasPrefix(ref, "-") || strings.HasPrefix(ref, "/") ||
+			strings.HasSuffix(ref, "/") || strings.HasSuffix(ref, ".lock") ||
+			strings.Contains(ref, "..") || strings.Contains(ref, "//") {
+			return fmt.Errorf("%w: %q is not a valid branch or tag name", ErrInvalidRepoRef, ref)
+		}
+	}
+
+	if opts.Depth < 0 || opts.Depth > maxCloneDepth {
+		return fmt.Errorf("%w: must be between 0 and %d", ErrInvalidCloneDepth, maxCloneDepth)
+	}
+
+	return nil
+}
+
 // generateSlug generates a URL-friendly slug from project name
 func generateSlug(name string) string {
 	// Convert to lowercase
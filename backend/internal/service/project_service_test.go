@@ -3,11 +3,15 @@ package service
 import (
 	"context"
 	"errors"
+	"io"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 
 	"github.com/npinot/vibe/backend/internal/model"
@@ -40,6 +44,14 @@ func (m *MockProjectRepository) FindByUserID(ctx context.Context, userID uuid.UU
 	return args.Get(0).([]model.Project), args.Error(1)
 }
 
+func (m *MockProjectRepository) FindSharedWithUser(ctx context.Context, userID uuid.UUID) ([]model.Project, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Project), args.Error(1)
+}
+
 func (m *MockProjectRepository) Update(ctx context.Context, project *model.Project) error {
 	args := m.Called(ctx, project)
 	return args.Error(0)
@@ -55,6 +67,35 @@ func (m *MockProjectRepository) UpdatePodStatus(ctx context.Context, id uuid.UUI
 	return args.Error(0)
 }
 
+func (m *MockProjectRepository) FindByPodName(ctx context.Context, podName, namespace string) (*model.Project, error) {
+	args := m.Called(ctx, podName, namespace)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Project), args.Error(1)
+}
+
+func (m *MockProjectRepository) FindIdleProjects(ctx context.Context, idleSince time.Time) ([]model.Project, error) {
+	args := m.Called(ctx, idleSince)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Project), args.Error(1)
+}
+
+func (m *MockProjectRepository) UpdateLastActivity(ctx context.Context, podName, namespace string, at time.Time) error {
+	args := m.Called(ctx, podName, namespace, at)
+	return args.Error(0)
+}
+
+func (m *MockProjectRepository) FindRunningProjects(ctx context.Context) ([]model.Project, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Project), args.Error(1)
+}
+
 var _ repository.ProjectRepository = (*MockProjectRepository)(nil)
 
 // MockKubernetesService is a mock implementation of KubernetesService
@@ -74,6 +115,30 @@ func (m *MockKubernetesService) CreateProjectPod(ctx context.Context, project *m
 	return args.Error(0)
 }
 
+func (m *MockKubernetesService) CreateEmptyProjectPod(ctx context.Context, project *model.Project) error {
+	args := m.Called(ctx, project)
+	// Simulate pod creation by setting pod metadata
+	if args.Error(0) == nil {
+		project.PodName = "project-12345678"
+		project.PodNamespace = "opencode"
+		project.WorkspacePVCName = "workspace-12345678"
+		project.PodStatus = "Pending"
+	}
+	return args.Error(0)
+}
+
+func (m *MockKubernetesService) CreateForkedProjectPod(ctx context.Context, project, source *model.Project) error {
+	args := m.Called(ctx, project, source)
+	// Simulate pod creation by setting pod metadata
+	if args.Error(0) == nil {
+		project.PodName = "project-87654321"
+		project.PodNamespace = "opencode"
+		project.WorkspacePVCName = "workspace-87654321"
+		project.PodStatus = "Pending"
+	}
+	return args.Error(0)
+}
+
 func (m *MockKubernetesService) DeleteProjectPod(ctx context.Context, podName, namespace string) error {
 	args := m.Called(ctx, podName, namespace)
 	return args.Error(0)
@@ -84,12 +149,12 @@ func (m *MockKubernetesService) GetPodStatus(ctx context.Context, podName, names
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockKubernetesService) WatchPodStatus(ctx context.Context, podName, namespace string) (<-chan string, error) {
+func (m *MockKubernetesService) WatchPodStatus(ctx context.Context, podName, namespace string) (<-chan PodStatusUpdate, error) {
 	args := m.Called(ctx, podName, namespace)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(<-chan string), args.Error(1)
+	return args.Get(0).(<-chan PodStatusUpdate), args.Error(1)
 }
 
 func (m *MockKubernetesService) GetPodIP(ctx context.Context, podName, namespace string) (string, error) {
@@ -97,6 +162,73 @@ func (m *MockKubernetesService) GetPodIP(ctx context.Context, podName, namespace
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockKubernetesService) GetRepoCloneStatus(ctx context.Context, podName, namespace string) (*RepoCloneStatus, error) {
+	args := m.Called(ctx, podName, namespace)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*RepoCloneStatus), args.Error(1)
+}
+
+func (m *MockKubernetesService) StopProjectPod(ctx context.Context, podName, namespace string) error {
+	args := m.Called(ctx, podName, namespace)
+	return args.Error(0)
+}
+
+func (m *MockKubernetesService) StartProjectPod(ctx context.Context, project *model.Project) error {
+	args := m.Called(ctx, project)
+	return args.Error(0)
+}
+
+func (m *MockKubernetesService) WaitForPodReady(ctx context.Context, podName, namespace string) (string, error) {
+	args := m.Called(ctx, podName, namespace)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockKubernetesService) ResizeProjectResources(ctx context.Context, project *model.Project) error {
+	args := m.Called(ctx, project)
+	return args.Error(0)
+}
+
+func (m *MockKubernetesService) StreamContainerLogs(ctx context.Context, podName, namespace string, opts ContainerLogOptions) (io.ReadCloser, error) {
+	args := m.Called(ctx, podName, namespace, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func (m *MockKubernetesService) CheckWorkspaceSnapshots(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockKubernetesService) CreateWorkspaceSnapshot(ctx context.Context, project *model.Project, taskID *uuid.UUID) (*WorkspaceSnapshot, error) {
+	args := m.Called(ctx, project, taskID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*WorkspaceSnapshot), args.Error(1)
+}
+
+func (m *MockKubernetesService) ListWorkspaceSnapshots(ctx context.Context, project *model.Project) ([]WorkspaceSnapshot, error) {
+	args := m.Called(ctx, project)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]WorkspaceSnapshot), args.Error(1)
+}
+
+func (m *MockKubernetesService) DeleteWorkspaceSnapshot(ctx context.Context, project *model.Project, name string) error {
+	args := m.Called(ctx, project, name)
+	return args.Error(0)
+}
+
+func (m *MockKubernetesService) RestoreWorkspaceSnapshot(ctx context.Context, project *model.Project, name string, save func(*model.Project) error) error {
+	args := m.Called(ctx, project, name, save)
+	return args.Error(0)
+}
+
 var _ KubernetesService = (*MockKubernetesService)(nil)
 
 func TestProjectService_CreateProject(t *testing.T) {
@@ -111,9 +243,9 @@ func TestProjectService_CreateProject(t *testing.T) {
 		mockK8s.On("CreateProjectPod", ctx, mock.AnythingOfType("*model.Project")).Return(nil)
 		mockRepo.On("Update", ctx, mock.AnythingOfType("*model.Project")).Return(nil)
 
-		svc := NewProjectService(mockRepo, mockK8s)
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
 
-		project, err := svc.CreateProject(ctx, userID, "Test Project", "A test project", "https://github.com/test/repo")
+		project, err := svc.CreateProject(ctx, userID, "Test Project", "A test project", "https://github.com/test/repo", RepoCloneOptions{}, ResourceSelection{})
 
 		assert.NoError(t, err)
 		assert.NotNil(t, project)
@@ -122,7 +254,7 @@ func TestProjectService_CreateProject(t *testing.T) {
 		assert.Equal(t, "A test project", project.Description)
 		assert.Equal(t, "https://github.com/test/repo", project.RepoURL)
 		assert.Equal(t, userID, project.UserID)
-		assert.Equal(t, model.ProjectStatusReady, project.Status)
+		assert.Equal(t, model.ProjectStatusCloning, project.Status)
 
 		mockRepo.AssertExpectations(t)
 		mockK8s.AssertExpectations(t)
@@ -132,9 +264,9 @@ func TestProjectService_CreateProject(t *testing.T) {
 		mockRepo := new(MockProjectRepository)
 		mockK8s := new(MockKubernetesService)
 
-		svc := NewProjectService(mockRepo, mockK8s)
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
 
-		project, err := svc.CreateProject(ctx, userID, "", "Description", "")
+		project, err := svc.CreateProject(ctx, userID, "", "Description", "", RepoCloneOptions{}, ResourceSelection{})
 
 		assert.Error(t, err)
 		assert.Nil(t, project)
@@ -148,14 +280,14 @@ func TestProjectService_CreateProject(t *testing.T) {
 		mockRepo := new(MockProjectRepository)
 		mockK8s := new(MockKubernetesService)
 
-		svc := NewProjectService(mockRepo, mockK8s)
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
 
 		longName := ""
 		for i := 0; i < 101; i++ {
 			longName += "a"
 		}
 
-		project, err := svc.CreateProject(ctx, userID, longName, "Description", "")
+		project, err := svc.CreateProject(ctx, userID, longName, "Description", "", RepoCloneOptions{}, ResourceSelection{})
 
 		assert.Error(t, err)
 		assert.Nil(t, project)
@@ -168,9 +300,9 @@ func TestProjectService_CreateProject(t *testing.T) {
 		mockRepo := new(MockProjectRepository)
 		mockK8s := new(MockKubernetesService)
 
-		svc := NewProjectService(mockRepo, mockK8s)
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
 
-		project, err := svc.CreateProject(ctx, userID, "Test@Project#123", "Description", "")
+		project, err := svc.CreateProject(ctx, userID, "Test@Project#123", "Description", "", RepoCloneOptions{}, ResourceSelection{})
 
 		assert.Error(t, err)
 		assert.Nil(t, project)
@@ -183,9 +315,9 @@ func TestProjectService_CreateProject(t *testing.T) {
 		mockRepo := new(MockProjectRepository)
 		mockK8s := new(MockKubernetesService)
 
-		svc := NewProjectService(mockRepo, mockK8s)
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
 
-		project, err := svc.CreateProject(ctx, userID, "Test Project", "Description", "invalid-url")
+		project, err := svc.CreateProject(ctx, userID, "Test Project", "Description", "invalid-url", RepoCloneOptions{}, ResourceSelection{})
 
 		assert.Error(t, err)
 		assert.Nil(t, project)
@@ -201,9 +333,9 @@ func TestProjectService_CreateProject(t *testing.T) {
 		dbErr := errors.New("database error")
 		mockRepo.On("Create", ctx, mock.AnythingOfType("*model.Project")).Return(dbErr)
 
-		svc := NewProjectService(mockRepo, mockK8s)
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
 
-		project, err := svc.CreateProject(ctx, userID, "Test Project", "Description", "")
+		project, err := svc.CreateProject(ctx, userID, "Test Project", "Description", "", RepoCloneOptions{}, ResourceSelection{})
 
 		assert.Error(t, err)
 		assert.Nil(t, project)
@@ -222,9 +354,9 @@ func TestProjectService_CreateProject(t *testing.T) {
 		mockK8s.On("CreateProjectPod", ctx, mock.AnythingOfType("*model.Project")).Return(podErr)
 		mockRepo.On("Update", ctx, mock.AnythingOfType("*model.Project")).Return(nil)
 
-		svc := NewProjectService(mockRepo, mockK8s)
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
 
-		project, err := svc.CreateProject(ctx, userID, "Test Project", "Description", "")
+		project, err := svc.CreateProject(ctx, userID, "Test Project", "Description", "", RepoCloneOptions{}, ResourceSelection{})
 
 		assert.NoError(t, err) // Project creation succeeds even if pod fails
 		assert.NotNil(t, project)
@@ -235,6 +367,65 @@ func TestProjectService_CreateProject(t *testing.T) {
 		mockK8s.AssertExpectations(t)
 	})
 
+	t.Run("repository with credential - encrypted and passed to pod", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		mockK8s := new(MockKubernetesService)
+
+		cipher, err := NewSecretCipher("MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+		assert.NoError(t, err)
+
+		mockRepo.On("Create", ctx, mock.AnythingOfType("*model.Project")).Return(nil)
+		mockK8s.On("CreateProjectPod", ctx, mock.MatchedBy(func(p *model.Project) bool {
+			return p.RepoCredential == "ghp_secret" && p.RepoRef == "develop" && p.RepoCloneDepth == 1
+		})).Return(nil)
+		mockRepo.On("Update", ctx, mock.AnythingOfType("*model.Project")).Return(nil)
+
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, cipher, nil, nil)
+
+		project, err := svc.CreateProject(ctx, userID, "Test Project", "Description", "https://github.com/test/private",
+			RepoCloneOptions{Ref: "develop", Depth: 1, Credential: "ghp_secret"}, ResourceSelection{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.ProjectStatusCloning, project.Status)
+		assert.Empty(t, project.RepoCredential)
+		assert.NotEmpty(t, project.RepoCredentialEncrypted)
+
+		decrypted, err := cipher.Decrypt(project.RepoCredentialEncrypted)
+		assert.NoError(t, err)
+		assert.Equal(t, "ghp_secret", decrypted)
+
+		mockRepo.AssertExpectations(t)
+		mockK8s.AssertExpectations(t)
+	})
+
+	t.Run("repository with credential - no cipher configured", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		mockK8s := new(MockKubernetesService)
+
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
+
+		project, err := svc.CreateProject(ctx, userID, "Test Project", "Description", "https://github.com/test/private",
+			RepoCloneOptions{Credential: "ghp_secret"}, ResourceSelection{})
+
+		assert.ErrorIs(t, err, ErrInvalidCredential)
+		assert.Nil(t, project)
+		mockRepo.AssertNotCalled(t, "Create")
+	})
+
+	t.Run("invalid repo ref", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		mockK8s := new(MockKubernetesService)
+
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
+
+		project, err := svc.CreateProject(ctx, userID, "Test Project", "Description", "https://github.com/test/repo",
+			RepoCloneOptions{Ref: "--upload-pack=evil"}, ResourceSelection{})
+
+		assert.ErrorIs(t, err, ErrInvalidRepoRef)
+		assert.Nil(t, project)
+		mockRepo.AssertNotCalled(t, "Create")
+	})
+
 	t.Run("pod created but update fails", func(t *testing.T) {
 		mockRepo := new(MockProjectRepository)
 		mockK8s := new(MockKubernetesService)
@@ -244,9 +435,9 @@ func TestProjectService_CreateProject(t *testing.T) {
 		mockK8s.On("CreateProjectPod", ctx, mock.AnythingOfType("*model.Project")).Return(nil)
 		mockRepo.On("Update", ctx, mock.AnythingOfType("*model.Project")).Return(updateErr)
 
-		svc := NewProjectService(mockRepo, mockK8s)
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
 
-		project, err := svc.CreateProject(ctx, userID, "Test Project", "Description", "")
+		project, err := svc.CreateProject(ctx, userID, "Test Project", "Description", "", RepoCloneOptions{}, ResourceSelection{})
 
 		assert.Error(t, err)
 		assert.Nil(t, project)
@@ -255,6 +446,40 @@ func TestProjectService_CreateProject(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 		mockK8s.AssertExpectations(t)
 	})
+
+	t.Run("resource profile", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		mockK8s := new(MockKubernetesService)
+
+		mockRepo.On("Create", ctx, mock.MatchedBy(func(p *model.Project) bool {
+			return p.ResourceProfile == "large" && p.CPULimit == "2000m" && p.MemoryLimit == "4Gi" && p.StorageSize == "10Gi"
+		})).Return(nil)
+		mockK8s.On("CreateProjectPod", ctx, mock.AnythingOfType("*model.Project")).Return(nil)
+		mockRepo.On("Update", ctx, mock.AnythingOfType("*model.Project")).Return(nil)
+
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
+
+		project, err := svc.CreateProject(ctx, userID, "Test Project", "Description", "", RepoCloneOptions{}, ResourceSelection{Profile: "large"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "large", project.ResourceProfile)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("resource profile above the caps", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		mockK8s := new(MockKubernetesService)
+		capRepo := new(MockResourceCapRepository)
+		capRepo.On("FindByUserID", ctx, userID).Return(&model.ResourceCap{UserID: userID, MaxCPU: "1", MaxMemory: "1Gi", MaxStorage: "1Gi"}, nil)
+
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, NewResourceProfileService(capRepo, testDefaultCaps), nil)
+
+		project, err := svc.CreateProject(ctx, userID, "Test Project", "Description", "", RepoCloneOptions{}, ResourceSelection{Profile: "large"})
+
+		assert.ErrorIs(t, err, ErrResourceCapExceeded)
+		assert.Nil(t, project)
+		mockRepo.AssertNotCalled(t, "Create")
+	})
 }
 
 func TestProjectService_GetProject(t *testing.T) {
@@ -274,7 +499,7 @@ func TestProjectService_GetProject(t *testing.T) {
 
 		mockRepo.On("FindByID", ctx, projectID).Return(expectedProject, nil)
 
-		svc := NewProjectService(mockRepo, mockK8s)
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
 
 		project, err := svc.GetProject(ctx, projectID, userID)
 
@@ -286,13 +511,102 @@ func TestProjectService_GetProject(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("cloning project - clone completed", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		mockK8s := new(MockKubernetesService)
+
+		cloningProject := &model.Project{
+			ID:           projectID,
+			UserID:       userID,
+			Name:         "Test Project",
+			PodName:      "project-12345678",
+			PodNamespace: "opencode",
+			Status:       model.ProjectStatusCloning,
+		}
+
+		mockRepo.On("FindByID", ctx, projectID).Return(cloningProject, nil)
+		mockK8s.On("GetRepoCloneStatus", ctx, "project-12345678", "opencode").
+			Return(&RepoCloneStatus{Phase: RepoCloneCompleted, Attempts: 1}, nil)
+		mockRepo.On("Update", ctx, cloningProject).Return(nil)
+
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
+
+		project, err := svc.GetProject(ctx, projectID, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.ProjectStatusReady, project.Status)
+		assert.Empty(t, project.PodError)
+
+		mockRepo.AssertExpectations(t)
+		mockK8s.AssertExpectations(t)
+	})
+
+	t.Run("cloning project - clone failed", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		mockK8s := new(MockKubernetesService)
+
+		cloningProject := &model.Project{
+			ID:           projectID,
+			UserID:       userID,
+			Name:         "Test Project",
+			PodName:      "project-12345678",
+			PodNamespace: "opencode",
+			Status:       model.ProjectStatusCloning,
+		}
+
+		mockRepo.On("FindByID", ctx, projectID).Return(cloningProject, nil)
+		mockK8s.On("GetRepoCloneStatus", ctx, "project-12345678", "opencode").
+			Return(&RepoCloneStatus{Phase: RepoCloneFailed, Message: "exit code 1: authentication failed"}, nil)
+		mockRepo.On("Update", ctx, cloningProject).Return(nil)
+
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
+
+		project, err := svc.GetProject(ctx, projectID, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.ProjectStatusError, project.Status)
+		assert.Contains(t, project.PodError, "Repository clone failed")
+		assert.Contains(t, project.PodError, "authentication failed")
+
+		mockRepo.AssertExpectations(t)
+		mockK8s.AssertExpectations(t)
+	})
+
+	t.Run("cloning project - clone still running", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		mockK8s := new(MockKubernetesService)
+
+		cloningProject := &model.Project{
+			ID:           projectID,
+			UserID:       userID,
+			Name:         "Test Project",
+			PodName:      "project-12345678",
+			PodNamespace: "opencode",
+			Status:       model.ProjectStatusCloning,
+		}
+
+		mockRepo.On("FindByID", ctx, projectID).Return(cloningProject, nil)
+		mockK8s.On("GetRepoCloneStatus", ctx, "project-12345678", "opencode").
+			Return(&RepoCloneStatus{Phase: RepoCloneRunning, Attempts: 1}, nil)
+
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
+
+		project, err := svc.GetProject(ctx, projectID, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.ProjectStatusCloning, project.Status)
+
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		mockK8s.AssertExpectations(t)
+	})
+
 	t.Run("project not found", func(t *testing.T) {
 		mockRepo := new(MockProjectRepository)
 		mockK8s := new(MockKubernetesService)
 
 		mockRepo.On("FindByID", ctx, projectID).Return(nil, gorm.ErrRecordNotFound)
 
-		svc := NewProjectService(mockRepo, mockK8s)
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
 
 		project, err := svc.GetProject(ctx, projectID, userID)
 
@@ -316,7 +630,7 @@ func TestProjectService_GetProject(t *testing.T) {
 
 		mockRepo.On("FindByID", ctx, projectID).Return(expectedProject, nil)
 
-		svc := NewProjectService(mockRepo, mockK8s)
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
 
 		project, err := svc.GetProject(ctx, projectID, userID)
 
@@ -334,7 +648,7 @@ func TestProjectService_GetProject(t *testing.T) {
 		dbErr := errors.New("database error")
 		mockRepo.On("FindByID", ctx, projectID).Return(nil, dbErr)
 
-		svc := NewProjectService(mockRepo, mockK8s)
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
 
 		project, err := svc.GetProject(ctx, projectID, userID)
 
@@ -360,15 +674,20 @@ func TestProjectService_ListProjects(t *testing.T) {
 			{ID: uuid.New(), UserID: userID, Name: "Project 3"},
 		}
 
+		sharedProject := model.Project{ID: uuid.New(), UserID: uuid.New(), Name: "Shared"}
+
 		mockRepo.On("FindByUserID", ctx, userID).Return(expectedProjects, nil)
+		mockRepo.On("FindSharedWithUser", ctx, userID).Return([]model.Project{sharedProject}, nil)
 
-		svc := NewProjectService(mockRepo, mockK8s)
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
 
 		projects, err := svc.ListProjects(ctx, userID)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, projects)
-		assert.Len(t, projects, 3)
+		assert.Len(t, projects, 4)
+		// Owned projects come first
+		assert.Equal(t, sharedProject.ID, projects[3].ID)
 
 		mockRepo.AssertExpectations(t)
 	})
@@ -379,8 +698,9 @@ func TestProjectService_ListProjects(t *testing.T) {
 
 		emptyProjects := []model.Project{}
 		mockRepo.On("FindByUserID", ctx, userID).Return(emptyProjects, nil)
+		mockRepo.On("FindSharedWithUser", ctx, userID).Return(emptyProjects, nil)
 
-		svc := NewProjectService(mockRepo, mockK8s)
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
 
 		projects, err := svc.ListProjects(ctx, userID)
 
@@ -398,7 +718,7 @@ func TestProjectService_ListProjects(t *testing.T) {
 		dbErr := errors.New("database error")
 		mockRepo.On("FindByUserID", ctx, userID).Return(nil, dbErr)
 
-		svc := NewProjectService(mockRepo, mockK8s)
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
 
 		projects, err := svc.ListProjects(ctx, userID)
 
@@ -429,7 +749,7 @@ func TestProjectService_UpdateProject(t *testing.T) {
 		mockRepo.On("FindByID", ctx, projectID).Return(existingProject, nil)
 		mockRepo.On("Update", ctx, mock.AnythingOfType("*model.Project")).Return(nil)
 
-		svc := NewProjectService(mockRepo, mockK8s)
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
 
 		updates := map[string]interface{}{
 			"name": "New Name",
@@ -459,7 +779,7 @@ func TestProjectService_UpdateProject(t *testing.T) {
 		mockRepo.On("FindByID", ctx, projectID).Return(existingProject, nil)
 		mockRepo.On("Update", ctx, mock.AnythingOfType("*model.Project")).Return(nil)
 
-		svc := NewProjectService(mockRepo, mockK8s)
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
 
 		updates := map[string]interface{}{
 			"description": "New description",
@@ -488,7 +808,7 @@ func TestProjectService_UpdateProject(t *testing.T) {
 		mockRepo.On("FindByID", ctx, projectID).Return(existingProject, nil)
 		mockRepo.On("Update", ctx, mock.AnythingOfType("*model.Project")).Return(nil)
 
-		svc := NewProjectService(mockRepo, mockK8s)
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
 
 		updates := map[string]interface{}{
 			"repo_url": "https://github.com/new/repo",
@@ -509,7 +829,7 @@ func TestProjectService_UpdateProject(t *testing.T) {
 
 		mockRepo.On("FindByID", ctx, projectID).Return(nil, gorm.ErrRecordNotFound)
 
-		svc := NewProjectService(mockRepo, mockK8s)
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
 
 		updates := map[string]interface{}{"name": "New Name"}
 		project, err := svc.UpdateProject(ctx, projectID, userID, updates)
@@ -534,7 +854,7 @@ func TestProjectService_UpdateProject(t *testing.T) {
 
 		mockRepo.On("FindByID", ctx, projectID).Return(existingProject, nil)
 
-		svc := NewProjectService(mockRepo, mockK8s)
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
 
 		updates := map[string]interface{}{"name": "New Name"}
 		project, err := svc.UpdateProject(ctx, projectID, userID, updates)
@@ -559,7 +879,7 @@ func TestProjectService_UpdateProject(t *testing.T) {
 
 		mockRepo.On("FindByID", ctx, projectID).Return(existingProject, nil)
 
-		svc := NewProjectService(mockRepo, mockK8s)
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
 
 		updates := map[string]interface{}{"name": ""}
 		project, err := svc.UpdateProject(ctx, projectID, userID, updates)
@@ -584,7 +904,7 @@ func TestProjectService_UpdateProject(t *testing.T) {
 
 		mockRepo.On("FindByID", ctx, projectID).Return(existingProject, nil)
 
-		svc := NewProjectService(mockRepo, mockK8s)
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
 
 		updates := map[string]interface{}{"repo_url": "invalid-url"}
 		project, err := svc.UpdateProject(ctx, projectID, userID, updates)
@@ -619,7 +939,7 @@ func TestProjectService_DeleteProject(t *testing.T) {
 		mockK8s.On("DeleteProjectPod", ctx, "project-12345678", "opencode").Return(nil)
 		mockRepo.On("SoftDelete", ctx, projectID).Return(nil)
 
-		svc := NewProjectService(mockRepo, mockK8s)
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
 
 		err := svc.DeleteProject(ctx, projectID, userID)
 
@@ -643,7 +963,7 @@ func TestProjectService_DeleteProject(t *testing.T) {
 		mockRepo.On("FindByID", ctx, projectID).Return(existingProject, nil)
 		mockRepo.On("SoftDelete", ctx, projectID).Return(nil)
 
-		svc := NewProjectService(mockRepo, mockK8s)
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
 
 		err := svc.DeleteProject(ctx, projectID, userID)
 
@@ -653,13 +973,44 @@ func TestProjectService_DeleteProject(t *testing.T) {
 		mockK8s.AssertNotCalled(t, "DeleteProjectPod")
 	})
 
+	t.Run("archived project deletes its archive", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		mockK8s := new(MockKubernetesService)
+		store, err := NewFilesystemArchiveStore(t.TempDir())
+		require.NoError(t, err)
+
+		archiveKey := "projects/" + projectID.String() + "/workspace.tar.gz"
+		_, err = store.Put(ctx, archiveKey, strings.NewReader("workspace"))
+		require.NoError(t, err)
+
+		existingProject := &model.Project{
+			ID:         projectID,
+			UserID:     userID,
+			Name:       "Test Project",
+			Status:     model.ProjectStatusArchived,
+			ArchiveKey: archiveKey,
+		}
+
+		mockRepo.On("FindByID", ctx, projectID).Return(existingProject, nil)
+		mockRepo.On("SoftDelete", ctx, projectID).Return(nil)
+
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, store)
+
+		err = svc.DeleteProject(ctx, projectID, userID)
+
+		assert.NoError(t, err)
+		_, err = store.Get(ctx, archiveKey)
+		assert.ErrorIs(t, err, ErrArchiveNotFound)
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("project not found", func(t *testing.T) {
 		mockRepo := new(MockProjectRepository)
 		mockK8s := new(MockKubernetesService)
 
 		mockRepo.On("FindByID", ctx, projectID).Return(nil, gorm.ErrRecordNotFound)
 
-		svc := NewProjectService(mockRepo, mockK8s)
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
 
 		err := svc.DeleteProject(ctx, projectID, userID)
 
@@ -684,7 +1035,7 @@ func TestProjectService_DeleteProject(t *testing.T) {
 
 		mockRepo.On("FindByID", ctx, projectID).Return(existingProject, nil)
 
-		svc := NewProjectService(mockRepo, mockK8s)
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
 
 		err := svc.DeleteProject(ctx, projectID, userID)
 
@@ -712,7 +1063,7 @@ func TestProjectService_DeleteProject(t *testing.T) {
 		mockRepo.On("FindByID", ctx, projectID).Return(existingProject, nil)
 		mockK8s.On("DeleteProjectPod", ctx, "project-12345678", "opencode").Return(podErr)
 
-		svc := NewProjectService(mockRepo, mockK8s)
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
 
 		err := svc.DeleteProject(ctx, projectID, userID)
 
@@ -741,7 +1092,7 @@ func TestProjectService_DeleteProject(t *testing.T) {
 		mockK8s.On("DeleteProjectPod", ctx, "project-12345678", "opencode").Return(nil)
 		mockRepo.On("SoftDelete", ctx, projectID).Return(dbErr)
 
-		svc := NewProjectService(mockRepo, mockK8s)
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
 
 		err := svc.DeleteProject(ctx, projectID, userID)
 
@@ -808,6 +1159,37 @@ func TestValidateRepoURL(t *testing.T) {
 	}
 }
 
+func TestValidateRepoCloneOptions(t *testing.T) {
+	testCases := []struct {
+		name      string
+		input     RepoCloneOptions
+		shouldErr bool
+	}{
+		{"defaults", RepoCloneOptions{}, false},
+		{"branch", RepoCloneOptions{Ref: "main"}, false},
+		{"nested branch", RepoCloneOptions{Ref: "feature/login-form"}, false},
+		{"tag", RepoCloneOptions{Ref: "v1.2.3"}, false},
+		{"shallow", RepoCloneOptions{Depth: 1}, false},
+		{"option injection", RepoCloneOptions{Ref: "-b"}, true},
+		{"parent traversal", RepoCloneOptions{Ref: "a..b"}, true},
+		{"whitespace", RepoCloneOptions{Ref: "my branch"}, true},
+		{"lock suffix", RepoCloneOptions{Ref: "main.lock"}, true},
+		{"negative depth", RepoCloneOptions{Depth: -1}, true},
+		{"depth too large", RepoCloneOptions{Depth: maxCloneDepth + 1}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateRepoCloneOptions(tc.input)
+			if tc.shouldErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestGenerateSlug(t *testing.T) {
 	testCases := []struct {
 		name     string
@@ -831,3 +1213,375 @@ func TestGenerateSlug(t *testing.T) {
 		})
 	}
 }
+
+func TestProjectService_WakeProject(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	projectID := uuid.New()
+
+	t.Run("wakes hibernated project through its pod lookup", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		mockK8s := new(MockKubernetesService)
+
+		hibernated := &model.Project{ID: projectID, UserID: userID, Status: model.ProjectStatusHibernated, PodName: "project-12345678", PodNamespace: "opencode"}
+		awake := &model.Project{ID: projectID, UserID: userID, Status: model.ProjectStatusReady, PodName: "project-12345678", PodNamespace: "opencode"}
+		mockRepo.On("FindByID", ctx, projectID).Return(hibernated, nil).Once()
+		mockK8s.On("GetPodIP", ctx, "project-12345678", "opencode").Return("10.0.0.5", nil)
+		mockRepo.On("FindByID", ctx, projectID).Return(awake, nil).Once()
+
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
+
+		project, err := svc.WakeProject(ctx, projectID, userID)
+
+		require.NoError(t, err)
+		assert.Equal(t, model.ProjectStatusReady, project.Status)
+		mockK8s.AssertExpectations(t)
+	})
+
+	t.Run("returns running project as is", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		mockK8s := new(MockKubernetesService)
+
+		mockRepo.On("FindByID", ctx, projectID).Return(&model.Project{ID: projectID, UserID: userID, Status: model.ProjectStatusReady}, nil)
+
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
+
+		project, err := svc.WakeProject(ctx, projectID, userID)
+
+		require.NoError(t, err)
+		assert.Equal(t, model.ProjectStatusReady, project.Status)
+		mockK8s.AssertNotCalled(t, "GetPodIP", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("pod does not come back", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		mockK8s := new(MockKubernetesService)
+
+		mockRepo.On("FindByID", ctx, projectID).Return(&model.Project{ID: projectID, UserID: userID, Status: model.ProjectStatusHibernated, PodName: "project-12345678", PodNamespace: "opencode"}, nil)
+		mockK8s.On("GetPodIP", ctx, "project-12345678", "opencode").Return("", errors.New("pod not found"))
+
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
+
+		_, err := svc.WakeProject(ctx, projectID, userID)
+
+		assert.ErrorIs(t, err, ErrProjectWakeFailed)
+	})
+
+	t.Run("other user's project", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		mockK8s := new(MockKubernetesService)
+
+		mockRepo.On("FindByID", ctx, projectID).Return(&model.Project{ID: projectID, UserID: uuid.New(), Status: model.ProjectStatusHibernated}, nil)
+
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
+
+		_, err := svc.WakeProject(ctx, projectID, userID)
+
+		assert.ErrorIs(t, err, ErrUnauthorized)
+	})
+}
+
+func TestProjectService_WatchProjectStatus(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	projectID := uuid.New()
+
+	t.Run("watches the project pod", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		mockK8s := new(MockKubernetesService)
+
+		updates := make(chan PodStatusUpdate)
+		mockRepo.On("FindByID", ctx, projectID).
+			Return(&model.Project{ID: projectID, UserID: userID, Status: model.ProjectStatusReady, PodName: "project-12345678", PodNamespace: "opencode"}, nil)
+		mockK8s.On("WatchPodStatus", ctx, "project-12345678", "opencode").Return((<-chan PodStatusUpdate)(updates), nil)
+
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
+
+		result, err := svc.WatchProjectStatus(ctx, projectID, userID)
+
+		require.NoError(t, err)
+		assert.Equal(t, (<-chan PodStatusUpdate)(updates), result)
+	})
+
+	t.Run("hibernated project has no pod to watch", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		mockK8s := new(MockKubernetesService)
+
+		mockRepo.On("FindByID", ctx, projectID).
+			Return(&model.Project{ID: projectID, UserID: userID, Status: model.ProjectStatusHibernated, PodName: "project-12345678", PodNamespace: "opencode"}, nil)
+
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
+
+		_, err := svc.WatchProjectStatus(ctx, projectID, userID)
+
+		assert.ErrorIs(t, err, ErrProjectPodMissing)
+		mockK8s.AssertNotCalled(t, "WatchPodStatus", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("other user's project", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		mockK8s := new(MockKubernetesService)
+
+		mockRepo.On("FindByID", ctx, projectID).
+			Return(&model.Project{ID: projectID, UserID: uuid.New(), Status: model.ProjectStatusReady, PodName: "project-12345678", PodNamespace: "opencode"}, nil)
+
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
+
+		_, err := svc.WatchProjectStatus(ctx, projectID, userID)
+
+		assert.ErrorIs(t, err, ErrUnauthorized)
+	})
+}
+
+func TestProjectService_UpdateProjectResources(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	projectID := uuid.New()
+
+	mediumProject := func() *model.Project {
+		return &model.Project{
+			ID: projectID, UserID: userID, Status: model.ProjectStatusReady,
+			PodName: "project-12345678", PodNamespace: "opencode", WorkspacePVCName: "workspace-12345678",
+			ResourceProfile: "medium", CPURequest: "100m", CPULimit: "1000m", MemoryRequest: "256Mi", MemoryLimit: "1Gi", StorageSize: "1Gi",
+		}
+	}
+
+	t.Run("switches profile and rolls the pod", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		mockK8s := new(MockKubernetesService)
+
+		resized := make(chan *model.Project, 1)
+		mockRepo.On("FindByID", ctx, projectID).Return(mediumProject(), nil)
+		mockRepo.On("Update", ctx, mock.MatchedBy(func(p *model.Project) bool {
+			return p.ResourceProfile == "large" && p.StorageSize == "10Gi"
+		})).Return(nil)
+		mockK8s.On("ResizeProjectResources", mock.Anything, mock.AnythingOfType("*model.Project")).
+			Run(func(args mock.Arguments) { resized <- args.Get(1).(*model.Project) }).
+			Return(nil)
+
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
+
+		project, err := svc.UpdateProjectResources(ctx, projectID, userID, ResourceSelection{Profile: "large"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "2000m", project.CPULimit)
+		select {
+		case p := <-resized:
+			assert.Equal(t, "large", p.ResourceProfile)
+		case <-time.After(time.Second):
+			t.Fatal("pod was not rolled")
+		}
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("records a failed roll", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		mockK8s := new(MockKubernetesService)
+
+		recorded := make(chan string, 1)
+		project := mediumProject()
+		project.PodStatus = "Running"
+		mockRepo.On("FindByID", ctx, projectID).Return(project, nil)
+		mockRepo.On("Update", ctx, mock.AnythingOfType("*model.Project")).Return(nil)
+		mockK8s.On("ResizeProjectResources", mock.Anything, mock.AnythingOfType("*model.Project")).Return(errors.New("storage class does not allow expansion"))
+		mockRepo.On("UpdatePodStatus", mock.Anything, projectID, "Running", mock.AnythingOfType("string")).
+			Run(func(args mock.Arguments) { recorded <- args.String(3) }).
+			Return(nil)
+
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
+
+		_, err := svc.UpdateProjectResources(ctx, projectID, userID, ResourceSelection{Profile: "large"})
+
+		require.NoError(t, err)
+		select {
+		case podError := <-recorded:
+			assert.Contains(t, podError, "storage class does not allow expansion")
+		case <-time.After(time.Second):
+			t.Fatal("resize error was not recorded")
+		}
+	})
+
+	t.Run("hibernated project keeps its pod stopped", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		mockK8s := new(MockKubernetesService)
+
+		project := mediumProject()
+		project.PodName = ""
+		mockRepo.On("FindByID", ctx, projectID).Return(project, nil)
+		mockRepo.On("Update", ctx, mock.AnythingOfType("*model.Project")).Return(nil)
+
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
+
+		project, err := svc.UpdateProjectResources(ctx, projectID, userID, ResourceSelection{Profile: "small"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "small", project.ResourceProfile)
+		mockK8s.AssertNotCalled(t, "ResizeProjectResources", mock.Anything, mock.Anything)
+	})
+
+	t.Run("smaller profile keeps the workspace", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		mockK8s := new(MockKubernetesService)
+
+		project := mediumProject()
+		project.PodName = ""
+		project.StorageSize = "10Gi"
+		project.StorageClass = "fast-ssd"
+		mockRepo.On("FindByID", ctx, projectID).Return(project, nil)
+		mockRepo.On("Update", ctx, mock.AnythingOfType("*model.Project")).Return(nil)
+
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
+
+		project, err := svc.UpdateProjectResources(ctx, projectID, userID, ResourceSelection{Profile: "small"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "small", project.ResourceProfile)
+		assert.Equal(t, "500m", project.CPULimit)
+		assert.Equal(t, "512Mi", project.MemoryLimit)
+		assert.Equal(t, "10Gi", project.StorageSize)
+		assert.Equal(t, "fast-ssd", project.StorageClass)
+	})
+
+	t.Run("custom resources cannot shrink the workspace", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		mockK8s := new(MockKubernetesService)
+
+		project := mediumProject()
+		project.StorageSize = "10Gi"
+		mockRepo.On("FindByID", ctx, projectID).Return(project, nil)
+
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
+
+		_, err := svc.UpdateProjectResources(ctx, projectID, userID,
+			ResourceSelection{Profile: "custom", CPU: "1", Memory: "1Gi", StorageSize: "2Gi"})
+
+		assert.ErrorIs(t, err, ErrInvalidResourceProfile)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("storage class cannot change", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		mockK8s := new(MockKubernetesService)
+
+		mockRepo.On("FindByID", ctx, projectID).Return(mediumProject(), nil)
+
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
+
+		_, err := svc.UpdateProjectResources(ctx, projectID, userID,
+			ResourceSelection{Profile: "custom", CPU: "1", Memory: "1Gi", StorageSize: "2Gi", StorageClass: "fast-ssd"})
+
+		assert.ErrorIs(t, err, ErrInvalidResourceProfile)
+	})
+
+	t.Run("other user's project", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		mockK8s := new(MockKubernetesService)
+
+		project := mediumProject()
+		project.UserID = uuid.New()
+		mockRepo.On("FindByID", ctx, projectID).Return(project, nil)
+
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
+
+		_, err := svc.UpdateProjectResources(ctx, projectID, userID, ResourceSelection{Profile: "large"})
+
+		assert.ErrorIs(t, err, ErrUnauthorized)
+	})
+}
+
+func TestProjectService_StreamProjectLogs(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	projectID := uuid.New()
+	readyProject := func() *model.Project {
+		return &model.Project{ID: projectID, UserID: userID, Status: model.ProjectStatusReady, PodName: "project-12345678", PodNamespace: "opencode"}
+	}
+
+	t.Run("defaults to the last opencode-server lines", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		mockK8s := new(MockKubernetesService)
+
+		logs := io.NopCloser(strings.NewReader("line\n"))
+		mockRepo.On("FindByID", ctx, projectID).Return(readyProject(), nil)
+		mockK8s.On("StreamContainerLogs", ctx, "project-12345678", "opencode", ContainerLogOptions{Container: "opencode-server", Follow: true, TailLines: defaultLogTailLines}).
+			Return(logs, nil)
+
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
+
+		result, err := svc.StreamProjectLogs(ctx, projectID, userID, ContainerLogOptions{Follow: true})
+
+		require.NoError(t, err)
+		content, err := io.ReadAll(result)
+		require.NoError(t, err)
+		assert.Equal(t, "line\n", string(content))
+		assert.NoError(t, result.Close())
+		mockK8s.AssertExpectations(t)
+	})
+
+	t.Run("keeps the pod awake until the stream is closed", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		mockK8s := new(MockKubernetesService)
+		hibernator := NewProjectHibernator(mockK8s, mockRepo, new(MockSessionRepository), 30*time.Minute)
+
+		mockRepo.On("FindByID", ctx, projectID).Return(readyProject(), nil)
+		mockRepo.On("UpdateLastActivity", mock.Anything, "project-12345678", "opencode", mock.Anything).Return(nil)
+		mockK8s.On("StreamContainerLogs", ctx, "project-12345678", "opencode", mock.Anything).
+			Return(io.NopCloser(strings.NewReader("line\n")), nil)
+
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), hibernator, nil, nil, nil)
+
+		result, err := svc.StreamProjectLogs(ctx, projectID, userID, ContainerLogOptions{})
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, hibernator.holds[podKey("project-12345678", "opencode")])
+		require.NoError(t, result.Close())
+		assert.NotContains(t, hibernator.holds, podKey("project-12345678", "opencode"))
+		mockRepo.AssertCalled(t, "UpdateLastActivity", mock.Anything, "project-12345678", "opencode", mock.Anything)
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		mockK8s := new(MockKubernetesService)
+		mockRepo.On("FindByID", ctx, projectID).Return(readyProject(), nil)
+
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
+
+		_, err := svc.StreamProjectLogs(ctx, projectID, userID, ContainerLogOptions{Container: "istio-proxy"})
+		assert.ErrorIs(t, err, ErrInvalidLogOptions)
+
+		_, err = svc.StreamProjectLogs(ctx, projectID, userID, ContainerLogOptions{TailLines: maxLogTailLines + 1})
+		assert.ErrorIs(t, err, ErrInvalidLogOptions)
+
+		mockK8s.AssertNotCalled(t, "StreamContainerLogs", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("hibernated project has no logs", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		mockK8s := new(MockKubernetesService)
+
+		project := readyProject()
+		project.Status = model.ProjectStatusHibernated
+		mockRepo.On("FindByID", ctx, projectID).Return(project, nil)
+
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
+
+		_, err := svc.StreamProjectLogs(ctx, projectID, userID, ContainerLogOptions{})
+
+		assert.ErrorIs(t, err, ErrProjectPodMissing)
+	})
+
+	t.Run("other user's project", func(t *testing.T) {
+		mockRepo := new(MockProjectRepository)
+		mockK8s := new(MockKubernetesService)
+
+		project := readyProject()
+		project.UserID = uuid.New()
+		mockRepo.On("FindByID", ctx, projectID).Return(project, nil)
+
+		svc := NewProjectService(mockRepo, NewAuthorizationService(mockRepo, nil), mockK8s, nil, nil, nil)
+
+		_, err := svc.StreamProjectLogs(ctx, projectID, userID, ContainerLogOptions{})
+
+		assert.ErrorIs(t, err, ErrUnauthorized)
+	})
+}
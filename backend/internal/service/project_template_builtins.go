@@ -0,0 +1,183 @@
+package service
+
+import (
+	"github.com/google/uuid"
+
+	"github.com/npinot/vibe/backend/internal/model"
+)
+
+// builtinTemplates ship with the application; their IDs are fixed so clients can refer to them
+var builtinTemplates = []model.ProjectTemplate{
+	{
+		ID:          uuid.MustParse("7e3f8a10-0c1d-4b6e-9a52-000000000001"),
+		Name:        "Go service",
+		Description: "HTTP service in Go with a health endpoint, tests and a Dockerfile",
+		Builtin:     true,
+		Files: model.TemplateFiles{
+			"go.mod": "module example.com/service\n\ngo 1.22\n",
+			"main.go": `package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+)
+
+func main() {
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", healthz)
+
+	log.Printf("Listening on :%s", port)
+	log.Fatal(http.ListenAndServe(":"+port, mux))
+}
+
+func healthz(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("ok"))
+}
+`,
+			"main_test.go": `package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHealthz(t *testing.T) {
+	w := httptest.NewRecorder()
+	healthz(w, httptest.NewRequest("GET", "/healthz", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+}
+`,
+			"Dockerfile": `FROM golang:1.22-alpine AS build
+WORKDIR /src
+COPY . .
+RUN CGO_ENABLED=0 go build -o /service .
+
+FROM gcr.io/distroless/static
+COPY --from=build /service /service
+ENTRYPOINT ["/service"]
+`,
+			"README.md": "# Go service\n\nRun it with `go run .` and test it with `go test ./...`.\n",
+		},
+		Config: &model.TemplateConfig{
+			ModelProvider:   "anthropic",
+			ModelName:       "claude-3.5-sonnet-20240620",
+			Temperature:     0.2,
+			MaxTokens:       8192,
+			EnabledTools:    model.ToolsList{"file_ops", "code_exec", "terminal"},
+			SystemPrompt:    stringPtr("You are working on a Go HTTP service. Write idiomatic Go, keep the standard library where it suffices and run go vet and go test before finishing."),
+			MaxIterations:   20,
+			TimeoutSeconds:  900,
+			AIReviewEnabled: true,
+		},
+		Tasks: model.TemplateTasks{
+			{Title: "Describe the service in the README", Description: "Explain what the service does, how to configure it and how to run it.", Priority: model.TaskPriorityLow},
+			{Title: "Add the first API endpoint", Description: "Add a JSON endpoint with a handler test.", Priority: model.TaskPriorityHigh},
+			{Title: "Add structured logging", Description: "Replace the log package with log/slog and log every request.", Priority: model.TaskPriorityMedium},
+			{Title: "Shut down gracefully", Description: "Drain in-flight requests on SIGTERM before exiting.", Priority: model.TaskPriorityMedium},
+		},
+		ResourceProfile: ResourceProfileMedium,
+	},
+	{
+		ID:          uuid.MustParse("7e3f8a10-0c1d-4b6e-9a52-000000000002"),
+		Name:        "React app",
+		Description: "Single-page React app built with Vite",
+		Builtin:     true,
+		Files: model.TemplateFiles{
+			"package.json": `{
+  "name": "app",
+  "private": true,
+  "type": "module",
+  "scripts": {
+    "dev": "vite",
+    "build": "vite build",
+    "preview": "vite preview"
+  },
+  "dependencies": {
+    "react": "^18.3.1",
+    "react-dom": "^18.3.1"
+  },
+  "devDependencies": {
+    "@vitejs/plugin-react": "^4.3.1",
+    "vite": "^5.4.0"
+  }
+}
+`,
+			"vite.config.js": `import { defineConfig } from 'vite'
+import react from '@vitejs/plugin-react'
+
+export default defineConfig({
+  plugins: [react()],
+})
+`,
+			"index.html": `<!doctype html>
+<html lang="en">
+  <head>
+    <meta charset="UTF-8" />
+    <meta name="viewport" content="width=device-width, initial-scale=1.0" />
+    <title>App</title>
+  </head>
+  <body>
+    <div id="root"></div>
+    <script type="module" src="/src/main.jsx"></script>
+  </body>
+</html>
+`,
+			"src/main.jsx": `import React from 'react'
+import ReactDOM from 'react-dom/client'
+import App from './App.jsx'
+
+ReactDOM.createRoot(document.getElementById('root')).render(
+  <React.StrictMode>
+    <App />
+  </React.StrictMode>,
+)
+`,
+			"src/App.jsx": `export default function App() {
+  return <h1>Hello from React</h1>
+}
+`,
+			"README.md": "# React app\n\nInstall the dependencies with `npm install` and start the dev server with `npm run dev`.\n",
+		},
+		Config: &model.TemplateConfig{
+			ModelProvider:   "anthropic",
+			ModelName:       "claude-3.5-sonnet-20240620",
+			Temperature:     0.3,
+			MaxTokens:       8192,
+			EnabledTools:    model.ToolsList{"file_ops", "code_exec", "terminal"},
+			SystemPrompt:    stringPtr("You are working on a React single-page app built with Vite. Use function components and hooks, keep components small and make sure npm run build succeeds before finishing."),
+			MaxIterations:   20,
+			TimeoutSeconds:  900,
+			AIReviewEnabled: true,
+		},
+		Tasks: model.TemplateTasks{
+			{Title: "Set up linting", Description: "Add ESLint with the React hooks rules and an npm run lint script.", Priority: model.TaskPriorityMedium},
+			{Title: "Add client-side routing", Description: "Add React Router with a home page and a not found page.", Priority: model.TaskPriorityHigh},
+			{Title: "Add component tests", Description: "Set up Vitest and React Testing Library and test App.", Priority: model.TaskPriorityMedium},
+		},
+		ResourceProfile: ResourceProfileMedium,
+	},
+}
+
+// lookupBuiltinTemplate returns the builtin template with the given ID
+func lookupBuiltinTemplate(id uuid.UUID) (model.ProjectTemplate, bool) {
+	for _, template := range builtinTemplates {
+		if template.ID == id {
+			return template, true
+		}
+	}
+	return model.ProjectTemplate{}, false
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,398 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/npinot/vibe/backend/internal/model"
+	"github.com/npinot/vibe/backend/internal/repository"
+)
+
+var (
+	ErrTemplateNotFound = errors.New("project template not found")
+	ErrInvalidTemplate  = errors.New("invalid project template")
+)
+
+// Template limits; seed files are meant for scaffolding, larger workspaces belong in a seed repository
+const (
+	maxTemplateFiles     = 100
+	maxTemplateFilesSize = 1 << 20
+	maxTemplateTasks     = 100
+)
+
+// templateSeedTimeout bounds waiting for the pod of a new project and writing the seed files into its workspace
+const templateSeedTimeout = 10 * time.Minute
+
+// TemplateProjectOptions are the choices made when creating a project from a template. An empty
+// repository URL or resource profile keeps the one of the template.
+type TemplateProjectOptions struct {
+	Name        string
+	Description string
+	RepoURL     string
+	RepoOpts    RepoCloneOptions // The credential also applies to the seed repository of the template
+	Resources   ResourceSelection
+	APIKey      string // API key for the model provider of the template configuration
+}
+
+// ProjectTemplateService manages project templates and bootstraps projects from them
+type ProjectTemplateService interface {
+	// ListTemplates returns the builtin templates followed by the templates saved by the user
+	ListTemplates(ctx context.Context, userID uuid.UUID) ([]model.ProjectTemplate, error)
+
+	// GetTemplate returns a builtin template or one saved by the user
+	GetTemplate(ctx context.Context, id, userID uuid.UUID) (*model.ProjectTemplate, error)
+
+	// CreateTemplateFromProject saves the repository, configuration, tasks and resource profile of a
+	// project as a template, together with the given seed files
+	CreateTemplateFromProject(ctx context.Context, projectID, userID uuid.UUID, name, description string, files map[string]string) (*model.ProjectTemplate, error)
+
+	// DeleteTemplate deletes a template saved by the user
+	DeleteTemplate(ctx context.Context, id, userID uuid.UUID) error
+
+	// CreateProjectFromTemplate creates a project with the configuration, tasks and resource profile of a
+	// template; its seed files are written into the workspace once the project pod is ready
+	CreateProjectFromTemplate(ctx context.Context, templateID, userID uuid.UUID, opts TemplateProjectOptions) (*model.Project, error)
+}
+
+type projectTemplateService struct {
+	templateRepo   repository.ProjectTemplateRepository
+	projectRepo    repository.ProjectRepository
+	projectService ProjectService
+	configService  ConfigServiceInterface
+	taskService    TaskService
+	k8sService     KubernetesService
+	httpClient     *http.Client
+	sidecarPort    int
+}
+
+// NewProjectTemplateService creates a project template service.
+// k8sService may be nil, in which case seed files are not written into new workspaces.
+func NewProjectTemplateService(templateRepo repository.ProjectTemplateRepository, projectRepo repository.ProjectRepository, projectService ProjectService, configService ConfigServiceInterface, taskService TaskService, k8sService KubernetesService) ProjectTemplateService {
+	return &projectTemplateService{
+		templateRepo:   templateRepo,
+		projectRepo:    projectRepo,
+		projectService: projectService,
+		configService:  configService,
+		taskService:    taskService,
+		k8sService:     k8sService,
+		httpClient:     &http.Client{Timeout: 30 * time.Second},
+		sidecarPort:    3001,
+	}
+}
+
+func (s *projectTemplateService) ListTemplates(ctx context.Context, userID uuid.UUID) ([]model.ProjectTemplate, error) {
+	saved, err := s.templateRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	templates := make([]model.ProjectTemplate, 0, len(builtinTemplates)+len(saved))
+	templates = append(templates, builtinTemplates...)
+	return append(templates, saved...), nil
+}
+
+func (s *projectTemplateService) GetTemplate(ctx context.Context, id, userID uuid.UUID) (*model.ProjectTemplate, error) {
+	if template, ok := lookupBuiltinTemplate(id); ok {
+		return &template, nil
+	}
+
+	template, err := s.templateRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, fmt.Errorf("failed to retrieve project template: %w", err)
+	}
+
+	if template.UserID != userID {
+		return nil, ErrUnauthorized
+	}
+
+	return template, nil
+}
+
+// CreateTemplateFromProject copies what a new project needs from an existing one. Repository credentials
+// and the API key are left out, and the workspace content is only kept through the given seed files.
+func (s *projectTemplateService) CreateTemplateFromProject(ctx context.Context, projectID, userID uuid.UUID, name, description string, files map[string]string) (*model.ProjectTemplate, error) {
+	if err := validateTemplateName(name); err != nil {
+		return nil, err
+	}
+	if err := validateTemplateFiles(files); err != nil {
+		return nil, err
+	}
+
+	project, err := s.projectService.GetProject(ctx, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	template := &model.ProjectTemplate{
+		UserID:          userID,
+		Name:            strings.TrimSpace(name),
+		Description:     description,
+		RepoURL:         project.RepoURL,
+		RepoRef:         project.RepoRef,
+		RepoCloneDepth:  project.RepoCloneDepth,
+		Files:           files,
+		ResourceProfile: project.ResourceProfile,
+	}
+	if template.ResourceProfile == "" {
+		template.ResourceProfile = DefaultResourceProfile
+	}
+	if template.ResourceProfile == ResourceProfileCustom {
+		template.CPULimit = project.CPULimit
+		template.MemoryLimit = project.MemoryLimit
+		template.StorageSize = project.StorageSize
+		template.StorageClass = project.StorageClass
+	}
+
+	config, err := s.configService.GetActiveConfig(ctx, projectID)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		// Projects without a configuration make templates without one
+	case err != nil:
+		return nil, err
+	default:
+		template.Config = templateConfigFrom(config)
+	}
+
+	tasks, err := s.taskService.ListProjectTasks(ctx, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(tasks) > maxTemplateTasks {
+		return nil, fmt.Errorf("%w: a template holds at most %d tasks", ErrInvalidTemplate, maxTemplateTasks)
+	}
+	for _, task := range tasks {
+		template.Tasks = append(template.Tasks, model.TemplateTask{
+			Title:       task.Title,
+			Description: task.Description,
+			Priority:    task.Priority,
+		})
+	}
+
+	if err := s.templateRepo.Create(ctx, template); err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+func (s *projectTemplateService) DeleteTemplate(ctx context.Context, id, userID uuid.UUID) error {
+	if _, ok := lookupBuiltinTemplate(id); ok {
+		return fmt.Errorf("%w: builtin templates cannot be deleted", ErrUnauthorized)
+	}
+
+	if _, err := s.GetTemplate(ctx, id, userID); err != nil {
+		return err
+	}
+
+	return s.templateRepo.Delete(ctx, id)
+}
+
+// CreateProjectFromTemplate creates the project first and then its configuration and tasks. A project
+// whose configuration or tasks cannot be created is deleted again, so it is never left half bootstrapped.
+func (s *projectTemplateService) CreateProjectFromTemplate(ctx context.Context, templateID, userID uuid.UUID, opts TemplateProjectOptions) (*model.Project, error) {
+	template, err := s.GetTemplate(ctx, templateID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	repoURL, repoOpts := opts.RepoURL, opts.RepoOpts
+	if repoURL == "" {
+		repoURL = template.RepoURL
+		repoOpts.Ref = template.RepoRef
+		repoOpts.Depth = template.RepoCloneDepth
+	}
+
+	resources := opts.Resources
+	if resources.Profile == "" {
+		resources = ResourceSelection{
+			Profile:      template.ResourceProfile,
+			CPU:          template.CPULimit,
+			Memory:       template.MemoryLimit,
+			StorageSize:  template.StorageSize,
+			StorageClass: template.StorageClass,
+		}
+	}
+
+	project, err := s.projectService.CreateProject(ctx, userID, opts.Name, opts.Description, repoURL, repoOpts, resources)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.bootstrapProject(ctx, project, template, userID, opts.APIKey); err != nil {
+		if deleteErr := s.projectService.DeleteProject(ctx, project.ID, userID); deleteErr != nil {
+			log.Printf("[ProjectTemplateService] Failed to delete project %s after a failed bootstrap: %v", project.ID, deleteErr)
+		}
+		return nil, err
+	}
+
+	if len(template.Files) > 0 && project.Status != model.ProjectStatusError {
+		go s.seedWorkspace(*project, template.Files)
+	}
+
+	return project, nil
+}
+
+// bootstrapProject creates the configuration and starter tasks of a project created from a template
+func (s *projectTemplateService) bootstrapProject(ctx context.Context, project *model.Project, template *model.ProjectTemplate, userID uuid.UUID, apiKey string) error {
+	if template.Config != nil {
+		config := openCodeConfigFrom(template.Config, project.ID, userID)
+		if err := s.configService.CreateOrUpdateConfig(ctx, config, apiKey); err != nil {
+			return fmt.Errorf("failed to create project config from template: %w", err)
+		}
+	}
+
+	for _, task := range template.Tasks {
+		priority := task.Priority
+		if priority == "" {
+			priority = model.TaskPriorityMedium
+		}
+		if _, err := s.taskService.CreateTask(ctx, project.ID, userID, task.Title, task.Description, priority); err != nil {
+			return fmt.Errorf("failed to create task %q from template: %w", task.Title, err)
+		}
+	}
+
+	return nil
+}
+
+// seedWorkspace writes the seed files of a template through the file-browser sidecar once the
+// project pod is ready, after the repository clone. Failures are reported through the pod error.
+func (s *projectTemplateService) seedWorkspace(project model.Project, files model.TemplateFiles) {
+	if s.k8sService == nil || project.PodName == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), templateSeedTimeout)
+	defer cancel()
+
+	if err := s.writeSeedFiles(ctx, &project, files); err != nil {
+		log.Printf("[ProjectTemplateService] Failed to seed workspace of project %s: %v", project.ID, err)
+		podError := fmt.Sprintf("Template files could not be written: %s", err.Error())
+		if updateErr := s.projectRepo.UpdatePodStatus(ctx, project.ID, project.PodStatus, podError); updateErr != nil {
+			log.Printf("[ProjectTemplateService] Failed to record seed error for project %s: %v", project.ID, updateErr)
+		}
+	}
+}
+
+func (s *projectTemplateService) writeSeedFiles(ctx context.Context, project *model.Project, files model.TemplateFiles) error {
+	podIP, err := s.k8sService.WaitForPodReady(ctx, project.PodName, project.PodNamespace)
+	if err != nil {
+		return fmt.Errorf("project pod did not become ready: %w", err)
+	}
+
+	url := fmt.Sprintf("http://%s:%d/files/write", podIP, s.sidecarPort)
+	for filePath, content := range files {
+		body, err := json.Marshal(map[string]string{"path": filePath, "content": content})
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := s.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to call file-browser sidecar: %w", err)
+		}
+		respBody, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("writing %s returned status %d: %s", filePath, resp.StatusCode, string(respBody))
+		}
+	}
+
+	return nil
+}
+
+// templateConfigFrom copies an OpenCode configuration without its API key
+func templateConfigFrom(config *model.OpenCodeConfig) *model.TemplateConfig {
+	return &model.TemplateConfig{
+		ModelProvider:      config.ModelProvider,
+		ModelName:          config.ModelName,
+		ModelVersion:       config.ModelVersion,
+		APIEndpoint:        config.APIEndpoint,
+		Temperature:        config.Temperature,
+		MaxTokens:          config.MaxTokens,
+		EnabledTools:       config.EnabledTools,
+		ToolsConfig:        config.ToolsConfig,
+		SystemPrompt:       config.SystemPrompt,
+		MaxIterations:      config.MaxIterations,
+		TimeoutSeconds:     config.TimeoutSeconds,
+		AIReviewEnabled:    config.AIReviewEnabled,
+		ReviewModelName:    config.ReviewModelName,
+		ReviewSystemPrompt: config.ReviewSystemPrompt,
+	}
+}
+
+// openCodeConfigFrom builds the first configuration version of a project from a template configuration
+func openCodeConfigFrom(config *model.TemplateConfig, projectID, userID uuid.UUID) *model.OpenCodeConfig {
+	return &model.OpenCodeConfig{
+		ProjectID:          projectID,
+		ModelProvider:      config.ModelProvider,
+		ModelName:          config.ModelName,
+		ModelVersion:       config.ModelVersion,
+		APIEndpoint:        config.APIEndpoint,
+		Temperature:        config.Temperature,
+		MaxTokens:          config.MaxTokens,
+		EnabledTools:       config.EnabledTools,
+		ToolsConfig:        config.ToolsConfig,
+		SystemPrompt:       config.SystemPrompt,
+		MaxIterations:      config.MaxIterations,
+		TimeoutSeconds:     config.TimeoutSeconds,
+		AIReviewEnabled:    config.AIReviewEnabled,
+		ReviewModelName:    config.ReviewModelName,
+		ReviewSystemPrompt: config.ReviewSystemPrompt,
+		CreatedBy:          userID,
+	}
+}
+
+// validateTemplateName validates template name constraints
+func validateTemplateName(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("%w: name cannot be empty", ErrInvalidTemplate)
+	}
+	if len(name) > 100 {
+		return fmt.Errorf("%w: name cannot exceed 100 characters", ErrInvalidTemplate)
+	}
+	return nil
+}
+
+// validateTemplateFiles checks that seed files stay within the workspace and the template limits
+func validateTemplateFiles(files map[string]string) error {
+	if len(files) > maxTemplateFiles {
+		return fmt.Errorf("%w: a template holds at most %d files", ErrInvalidTemplate, maxTemplateFiles)
+	}
+
+	size := 0
+	for filePath, content := range files {
+		if filePath == "" || path.IsAbs(filePath) || path.Clean(filePath) != filePath ||
+			filePath == ".." || strings.HasPrefix(filePath, "../") {
+			return fmt.Errorf("%w: %q is not a relative file path", ErrInvalidTemplate, filePath)
+		}
+		size += len(content)
+	}
+	if size > maxTemplateFilesSize {
+		return fmt.Errorf("%w: template files cannot exceed %d bytes", ErrInvalidTemplate, maxTemplateFilesSize)
+	}
+
+	return nil
+}
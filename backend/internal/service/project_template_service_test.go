@@ -0,0 +1,415 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/npinot/vibe/backend/internal/model"
+	"github.com/npinot/vibe/backend/internal/repository"
+)
+
+type MockProjectTemplateRepository struct {
+	mock.Mock
+}
+
+func (m *MockProjectTemplateRepository) Create(ctx context.Context, template *model.ProjectTemplate) error {
+	args := m.Called(ctx, template)
+	return args.Error(0)
+}
+
+func (m *MockProjectTemplateRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.ProjectTemplate, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ProjectTemplate), args.Error(1)
+}
+
+func (m *MockProjectTemplateRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]model.ProjectTemplate, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.ProjectTemplate), args.Error(1)
+}
+
+func (m *MockProjectTemplateRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+var _ repository.ProjectTemplateRepository = (*MockProjectTemplateRepository)(nil)
+
+type projectTemplateTest struct {
+	svc           *projectTemplateService
+	templateRepo  *MockProjectTemplateRepository
+	projectRepo   *MockProjectRepository
+	taskRepo      *MockTaskRepository
+	configService *MockConfigService
+	k8sService    *MockKubernetesService
+}
+
+// setupProjectTemplateTest wires the template service to real project and task services backed by mocks
+func setupProjectTemplateTest() *projectTemplateTest {
+	tt := &projectTemplateTest{
+		templateRepo:  new(MockProjectTemplateRepository),
+		projectRepo:   new(MockProjectRepository),
+		taskRepo:      new(MockTaskRepository),
+		configService: new(MockConfigService),
+		k8sService:    new(MockKubernetesService),
+	}
+	projectService := NewProjectService(tt.projectRepo, NewAuthorizationService(tt.projectRepo, nil), tt.k8sService, nil, nil, nil)
+	taskService := NewTaskService(tt.taskRepo, tt.projectRepo, NewAuthorizationService(tt.projectRepo, nil), nil, nil, nil, nil)
+	tt.svc = NewProjectTemplateService(tt.templateRepo, tt.projectRepo, projectService, tt.configService, taskService, tt.k8sService).(*projectTemplateService)
+	return tt
+}
+
+func TestProjectTemplateService_ListAndGetTemplates(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	saved := model.ProjectTemplate{ID: uuid.New(), UserID: userID, Name: "My service", ResourceProfile: ResourceProfileSmall}
+
+	t.Run("lists builtin templates before saved ones", func(t *testing.T) {
+		tt := setupProjectTemplateTest()
+		tt.templateRepo.On("FindByUserID", ctx, userID).Return([]model.ProjectTemplate{saved}, nil)
+
+		templates, err := tt.svc.ListTemplates(ctx, userID)
+
+		require.NoError(t, err)
+		require.Len(t, templates, len(builtinTemplates)+1)
+		assert.True(t, templates[0].Builtin)
+		assert.Equal(t, "Go service", templates[0].Name)
+		assert.Equal(t, "My service", templates[len(templates)-1].Name)
+	})
+
+	t.Run("gets a builtin template without the database", func(t *testing.T) {
+		tt := setupProjectTemplateTest()
+
+		template, err := tt.svc.GetTemplate(ctx, builtinTemplates[1].ID, userID)
+
+		require.NoError(t, err)
+		assert.Equal(t, "React app", template.Name)
+		tt.templateRepo.AssertNotCalled(t, "FindByID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects templates of other users", func(t *testing.T) {
+		tt := setupProjectTemplateTest()
+		tt.templateRepo.On("FindByID", ctx, saved.ID).Return(&saved, nil)
+
+		_, err := tt.svc.GetTemplate(ctx, saved.ID, uuid.New())
+
+		assert.ErrorIs(t, err, ErrUnauthorized)
+	})
+
+	t.Run("unknown template", func(t *testing.T) {
+		tt := setupProjectTemplateTest()
+		missingID := uuid.New()
+		tt.templateRepo.On("FindByID", ctx, missingID).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := tt.svc.GetTemplate(ctx, missingID, userID)
+
+		assert.ErrorIs(t, err, ErrTemplateNotFound)
+	})
+}
+
+func TestProjectTemplateService_CreateTemplateFromProject(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	prompt := "Keep handlers thin."
+	project := &model.Project{
+		ID:                      uuid.New(),
+		UserID:                  userID,
+		Status:                  model.ProjectStatusReady,
+		RepoURL:                 "https://github.com/example/service.git",
+		RepoRef:                 "main",
+		RepoCloneDepth:          1,
+		RepoCredentialEncrypted: []byte("encrypted"),
+		ResourceProfile:         ResourceProfileCustom,
+		CPULimit:                "1500m",
+		MemoryLimit:             "2Gi",
+		StorageSize:             "5Gi",
+	}
+
+	t.Run("copies the repository, config, tasks and resources", func(t *testing.T) {
+		tt := setupProjectTemplateTest()
+		tt.projectRepo.On("FindByID", ctx, project.ID).Return(project, nil)
+		tt.configService.On("GetActiveConfig", ctx, project.ID).Return(&model.OpenCodeConfig{
+			ProjectID:       project.ID,
+			ModelProvider:   "anthropic",
+			ModelName:       "claude-3-haiku-20240307",
+			APIKeyEncrypted: []byte("secret"),
+			EnabledTools:    model.ToolsList{"file_ops"},
+			SystemPrompt:    &prompt,
+			MaxIterations:   5,
+		}, nil)
+		tt.taskRepo.On("FindByProjectID", ctx, project.ID).Return([]model.Task{
+			{Title: "Add metrics", Description: "Expose Prometheus metrics", Priority: model.TaskPriorityHigh, Status: model.TaskStatusDone},
+			{Title: "Add tracing", Priority: model.TaskPriorityLow},
+		}, nil)
+		tt.templateRepo.On("Create", ctx, mock.AnythingOfType("*model.ProjectTemplate")).Return(nil)
+
+		template, err := tt.svc.CreateTemplateFromProject(ctx, project.ID, userID, " Service ", "Our services", map[string]string{"Makefile": "test:\n\tgo test ./...\n"})
+
+		require.NoError(t, err)
+		assert.Equal(t, userID, template.UserID)
+		assert.Equal(t, "Service", template.Name)
+		assert.Equal(t, "https://github.com/example/service.git", template.RepoURL)
+		assert.Equal(t, "main", template.RepoRef)
+		assert.Equal(t, 1, template.RepoCloneDepth)
+		assert.Equal(t, "test:\n\tgo test ./...\n", template.Files["Makefile"])
+		require.NotNil(t, template.Config)
+		assert.Equal(t, "claude-3-haiku-20240307", template.Config.ModelName)
+		assert.Equal(t, prompt, *template.Config.SystemPrompt)
+		assert.Equal(t, model.TemplateTasks{
+			{Title: "Add metrics", Description: "Expose Prometheus metrics", Priority: model.TaskPriorityHigh},
+			{Title: "Add tracing", Priority: model.TaskPriorityLow},
+		}, template.Tasks)
+		assert.Equal(t, ResourceProfileCustom, template.ResourceProfile)
+		assert.Equal(t, "1500m", template.CPULimit)
+		assert.Equal(t, "2Gi", template.MemoryLimit)
+		assert.Equal(t, "5Gi", template.StorageSize)
+
+		// Neither the API key nor the repository credential end up in the template
+		stored, err := json.Marshal(template)
+		require.NoError(t, err)
+		assert.NotContains(t, string(stored), "secret")
+		assert.NotContains(t, string(stored), "encrypted")
+	})
+
+	t.Run("projects without a config", func(t *testing.T) {
+		tt := setupProjectTemplateTest()
+		tt.projectRepo.On("FindByID", ctx, project.ID).Return(project, nil)
+		tt.configService.On("GetActiveConfig", ctx, project.ID).Return(nil, gorm.ErrRecordNotFound)
+		tt.taskRepo.On("FindByProjectID", ctx, project.ID).Return([]model.Task{}, nil)
+		tt.templateRepo.On("Create", ctx, mock.AnythingOfType("*model.ProjectTemplate")).Return(nil)
+
+		template, err := tt.svc.CreateTemplateFromProject(ctx, project.ID, userID, "Service", "", nil)
+
+		require.NoError(t, err)
+		assert.Nil(t, template.Config)
+		assert.Empty(t, template.Tasks)
+	})
+
+	t.Run("rejects projects of other users", func(t *testing.T) {
+		tt := setupProjectTemplateTest()
+		tt.projectRepo.On("FindByID", ctx, project.ID).Return(project, nil)
+
+		_, err := tt.svc.CreateTemplateFromProject(ctx, project.ID, uuid.New(), "Service", "", nil)
+
+		assert.ErrorIs(t, err, ErrUnauthorized)
+		tt.templateRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	invalid := []struct {
+		name  string
+		tName string
+		files map[string]string
+	}{
+		{"empty name", "  ", nil},
+		{"absolute path", "Service", map[string]string{"/etc/passwd": ""}},
+		{"path escaping the workspace", "Service", map[string]string{"../secrets": ""}},
+		{"unclean path", "Service", map[string]string{"src/../main.go": ""}},
+	}
+	for _, tc := range invalid {
+		t.Run("rejects "+tc.name, func(t *testing.T) {
+			tt := setupProjectTemplateTest()
+
+			_, err := tt.svc.CreateTemplateFromProject(ctx, project.ID, userID, tc.tName, "", tc.files)
+
+			assert.ErrorIs(t, err, ErrInvalidTemplate)
+		})
+	}
+}
+
+func TestProjectTemplateService_CreateProjectFromTemplate(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	projectID := uuid.New()
+
+	// expectProjectCreation expects the project to be created and its pod spawned
+	expectProjectCreation := func(tt *projectTemplateTest) {
+		tt.projectRepo.On("Create", ctx, mock.AnythingOfType("*model.Project")).
+			Run(func(args mock.Arguments) { args.Get(1).(*model.Project).ID = projectID }).
+			Return(nil)
+		tt.k8sService.On("CreateProjectPod", ctx, mock.AnythingOfType("*model.Project")).Return(nil)
+		tt.projectRepo.On("Update", ctx, mock.AnythingOfType("*model.Project")).Return(nil)
+		tt.projectRepo.On("FindByID", ctx, projectID).Return(&model.Project{ID: projectID, UserID: userID, Status: model.ProjectStatusReady}, nil)
+	}
+
+	t.Run("bootstraps the project from a builtin template", func(t *testing.T) {
+		tt := setupProjectTemplateTest()
+		template := builtinTemplates[0]
+
+		var mu sync.Mutex
+		written := map[string]string{}
+		done := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/files/write", r.URL.Path)
+			var req struct{ Path, Content string }
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			w.WriteHeader(http.StatusOK)
+
+			mu.Lock()
+			defer mu.Unlock()
+			written[req.Path] = req.Content
+			if len(written) == len(template.Files) {
+				close(done)
+			}
+		}))
+		defer server.Close()
+		addr := server.Listener.Addr().(*net.TCPAddr)
+		tt.svc.sidecarPort = addr.Port
+
+		expectProjectCreation(tt)
+		tt.configService.On("CreateOrUpdateConfig", ctx, mock.AnythingOfType("*model.OpenCodeConfig"), "sk-ant-key").Return(nil)
+		tt.taskRepo.On("FindByProjectID", ctx, projectID).Return([]model.Task{}, nil)
+		tt.taskRepo.On("Create", ctx, mock.AnythingOfType("*model.Task")).Return(nil)
+		tt.k8sService.On("WaitForPodReady", mock.Anything, "project-12345678", "opencode").Return(addr.IP.String(), nil)
+
+		project, err := tt.svc.CreateProjectFromTemplate(ctx, template.ID, userID, TemplateProjectOptions{
+			Name:   "Billing",
+			APIKey: "sk-ant-key",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, projectID, project.ID)
+		assert.Equal(t, "Billing", project.Name)
+		assert.Equal(t, ResourceProfileMedium, project.ResourceProfile)
+
+		config := tt.configService.Calls[0].Arguments.Get(1).(*model.OpenCodeConfig)
+		assert.Equal(t, projectID, config.ProjectID)
+		assert.Equal(t, userID, config.CreatedBy)
+		assert.Equal(t, template.Config.ModelName, config.ModelName)
+		assert.Equal(t, template.Config.SystemPrompt, config.SystemPrompt)
+
+		var titles []string
+		for _, call := range tt.taskRepo.Calls {
+			if call.Method == "Create" {
+				task := call.Arguments.Get(1).(*model.Task)
+				assert.Equal(t, model.TaskStatusTodo, task.Status)
+				titles = append(titles, task.Title)
+			}
+		}
+		require.Len(t, titles, len(template.Tasks))
+		assert.Equal(t, template.Tasks[0].Title, titles[0])
+
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("seed files were not written")
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, template.Files["main.go"], written["main.go"])
+	})
+
+	t.Run("request repository and profile win over the template", func(t *testing.T) {
+		tt := setupProjectTemplateTest()
+		template := &model.ProjectTemplate{
+			ID:              uuid.New(),
+			UserID:          userID,
+			Name:            "Seeded",
+			RepoURL:         "https://github.com/example/seed.git",
+			RepoRef:         "v1",
+			ResourceProfile: ResourceProfileLarge,
+		}
+		tt.templateRepo.On("FindByID", ctx, template.ID).Return(template, nil)
+		expectProjectCreation(tt)
+
+		project, err := tt.svc.CreateProjectFromTemplate(ctx, template.ID, userID, TemplateProjectOptions{
+			Name:      "Fork",
+			RepoURL:   "https://github.com/example/other.git",
+			Resources: ResourceSelection{Profile: ResourceProfileSmall},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "https://github.com/example/other.git", project.RepoURL)
+		assert.Empty(t, project.RepoRef)
+		assert.Equal(t, ResourceProfileSmall, project.ResourceProfile)
+		tt.configService.AssertNotCalled(t, "CreateOrUpdateConfig", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("deletes the project when the config cannot be created", func(t *testing.T) {
+		tt := setupProjectTemplateTest()
+		expectProjectCreation(tt)
+		tt.configService.On("CreateOrUpdateConfig", ctx, mock.AnythingOfType("*model.OpenCodeConfig"), "").Return(errors.New("config validation failed"))
+		tt.k8sService.On("DeleteProjectPod", ctx, "project-12345678", "opencode").Return(nil)
+		tt.projectRepo.On("SoftDelete", ctx, projectID).Return(nil)
+
+		_, err := tt.svc.CreateProjectFromTemplate(ctx, builtinTemplates[0].ID, userID, TemplateProjectOptions{Name: "Billing"})
+
+		require.Error(t, err)
+		tt.projectRepo.AssertCalled(t, "SoftDelete", ctx, projectID)
+		tt.taskRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown template", func(t *testing.T) {
+		tt := setupProjectTemplateTest()
+		missingID := uuid.New()
+		tt.templateRepo.On("FindByID", ctx, missingID).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := tt.svc.CreateProjectFromTemplate(ctx, missingID, userID, TemplateProjectOptions{Name: "Billing"})
+
+		assert.ErrorIs(t, err, ErrTemplateNotFound)
+		tt.projectRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestProjectTemplateService_DeleteTemplate(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	t.Run("deletes a saved template", func(t *testing.T) {
+		tt := setupProjectTemplateTest()
+		template := &model.ProjectTemplate{ID: uuid.New(), UserID: userID, Name: "Service"}
+		tt.templateRepo.On("FindByID", ctx, template.ID).Return(template, nil)
+		tt.templateRepo.On("Delete", ctx, template.ID).Return(nil)
+
+		require.NoError(t, tt.svc.DeleteTemplate(ctx, template.ID, userID))
+		tt.templateRepo.AssertExpectations(t)
+	})
+
+	t.Run("builtin templates cannot be deleted", func(t *testing.T) {
+		tt := setupProjectTemplateTest()
+
+		err := tt.svc.DeleteTemplate(ctx, builtinTemplates[0].ID, userID)
+
+		assert.ErrorIs(t, err, ErrUnauthorized)
+		tt.templateRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+}
+
+func TestBuiltinTemplatesAreValid(t *testing.T) {
+	configService := &ConfigService{}
+	ids := map[uuid.UUID]bool{}
+
+	for _, template := range builtinTemplates {
+		t.Run(template.Name, func(t *testing.T) {
+			assert.False(t, ids[template.ID], "duplicate template ID")
+			ids[template.ID] = true
+
+			assert.NoError(t, validateTemplateName(template.Name))
+			assert.NoError(t, validateTemplateFiles(template.Files))
+			_, ok := LookupResourceProfile(template.ResourceProfile)
+			assert.True(t, ok)
+			assert.NoError(t, configService.validateConfig(openCodeConfigFrom(template.Config, uuid.New(), uuid.New())))
+			for _, task := range template.Tasks {
+				assert.NoError(t, validateTaskTitle(task.Title))
+				assert.NoError(t, validateTaskPriority(task.Priority))
+			}
+		})
+	}
+}
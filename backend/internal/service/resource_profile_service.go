@@ -0,0 +1,295 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/npinot/vibe/backend/internal/model"
+	"github.com/npinot/vibe/backend/internal/repository"
+)
+
+var (
+	ErrInvalidResourceProfile = errors.New("invalid resource profile")
+	ErrResourceCapExceeded    = errors.New("resource profile exceeds the allowed resources")
+	ErrInvalidResourceCap     = errors.New("invalid resource caps")
+)
+
+const (
+	ResourceProfileSmall  = "small"
+	ResourceProfileMedium = "medium"
+	ResourceProfileLarge  = "large"
+	ResourceProfileCustom = "custom"
+
+	// DefaultResourceProfile is used for projects created without choosing a profile
+	DefaultResourceProfile = ResourceProfileMedium
+)
+
+// Smallest custom resources; custom profiles also request these so they schedule like the small profile
+const (
+	minCustomCPU     = "100m"
+	minCustomMemory  = "256Mi"
+	minCustomStorage = "1Gi"
+)
+
+// ResourceProfile is a named set of resources for each project pod container and the workspace PVC,
+// as Kubernetes quantities. An empty storage class picks the cluster default.
+type ResourceProfile struct {
+	Name          string `json:"name"`
+	CPURequest    string `json:"cpu_request,omitempty"`
+	CPULimit      string `json:"cpu_limit,omitempty"`
+	MemoryRequest string `json:"memory_request,omitempty"`
+	MemoryLimit   string `json:"memory_limit,omitempty"`
+	StorageSize   string `json:"storage_size,omitempty"`
+	StorageClass  string `json:"storage_class,omitempty"`
+}
+
+// resourceProfiles are the named profiles; medium matches the resources of projects created before profiles
+var resourceProfiles = []ResourceProfile{
+	{Name: ResourceProfileSmall, CPURequest: "100m", CPULimit: "500m", MemoryRequest: "256Mi", MemoryLimit: "512Mi", StorageSize: "1Gi"},
+	{Name: ResourceProfileMedium, CPURequest: "100m", CPULimit: "1000m", MemoryRequest: "256Mi", MemoryLimit: "1Gi", StorageSize: "1Gi"},
+	{Name: ResourceProfileLarge, CPURequest: "500m", CPULimit: "2000m", MemoryRequest: "1Gi", MemoryLimit: "4Gi", StorageSize: "10Gi"},
+}
+
+// LookupResourceProfile returns the named profile small, medium or large
+func LookupResourceProfile(name string) (ResourceProfile, bool) {
+	for _, profile := range resourceProfiles {
+		if profile.Name == name {
+			return profile, true
+		}
+	}
+	return ResourceProfile{}, false
+}
+
+// ResourceSelection is the resource profile chosen for a project. CPU and memory limits, storage size
+// and storage class are only read for the custom profile; an empty profile picks the default.
+type ResourceSelection struct {
+	Profile      string
+	CPU          string
+	Memory       string
+	StorageSize  string
+	StorageClass string
+}
+
+// ResourceProfileOption is a resource profile with whether the user may choose it
+type ResourceProfileOption struct {
+	ResourceProfile
+	Allowed bool `json:"allowed"`
+}
+
+// ResourceProfileList lists the resource profiles for a user with the caps deciding which are allowed
+type ResourceProfileList struct {
+	Profiles []ResourceProfileOption `json:"profiles"`
+	Caps     *model.ResourceCap      `json:"caps"`
+}
+
+type ResourceProfileService interface {
+	// ListProfiles returns the resource profiles and whether the user may choose them
+	ListProfiles(ctx context.Context, userID uuid.UUID) (*ResourceProfileList, error)
+
+	// ResolveProfile returns the resources of the selection once checked against the user's caps
+	ResolveProfile(ctx context.Context, userID uuid.UUID, selection ResourceSelection) (*ResourceProfile, error)
+
+	// GetUserCaps returns the caps of the user, or the default caps when no admin set any
+	GetUserCaps(ctx context.Context, userID uuid.UUID) (*model.ResourceCap, error)
+
+	// SetUserCaps replaces the caps of a user
+	SetUserCaps(ctx context.Context, resourceCap *model.ResourceCap) (*model.ResourceCap, error)
+
+	// DeleteUserCaps puts the user back on the default caps
+	DeleteUserCaps(ctx context.Context, userID uuid.UUID) error
+}
+
+type resourceProfileService struct {
+	capRepo     repository.ResourceCapRepository
+	defaultCaps model.ResourceCap
+}
+
+func NewResourceProfileService(capRepo repository.ResourceCapRepository, defaultCaps model.ResourceCap) ResourceProfileService {
+	return &resourceProfileService{
+		capRepo:     capRepo,
+		defaultCaps: defaultCaps,
+	}
+}
+
+func (s *resourceProfileService) ListProfiles(ctx context.Context, userID uuid.UUID) (*ResourceProfileList, error) {
+	caps, err := s.GetUserCaps(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &ResourceProfileList{Caps: caps}
+	for _, profile := range resourceProfiles {
+		list.Profiles = append(list.Profiles, ResourceProfileOption{
+			ResourceProfile: profile,
+			Allowed:         checkResourceCaps(&profile, caps) == nil,
+		})
+	}
+	list.Profiles = append(list.Profiles, ResourceProfileOption{
+		ResourceProfile: ResourceProfile{Name: ResourceProfileCustom},
+		Allowed:         caps.AllowCustom,
+	})
+
+	return list, nil
+}
+
+func (s *resourceProfileService) ResolveProfile(ctx context.Context, userID uuid.UUID, selection ResourceSelection) (*ResourceProfile, error) {
+	profile, err := resolveResourceSelection(selection)
+	if err != nil {
+		return nil, err
+	}
+
+	caps, err := s.GetUserCaps(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResourceCaps(profile, caps); err != nil {
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+func (s *resourceProfileService) GetUserCaps(ctx context.Context, userID uuid.UUID) (*model.ResourceCap, error) {
+	resourceCap, err := s.capRepo.FindByUserID(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		defaults := s.defaultCaps
+		defaults.UserID = userID
+		return &defaults, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resource caps: %w", err)
+	}
+
+	return resourceCap, nil
+}
+
+func (s *resourceProfileService) SetUserCaps(ctx context.Context, resourceCap *model.ResourceCap) (*model.ResourceCap, error) {
+	for name, value := range map[string]string{"CPU": resourceCap.MaxCPU, "memory": resourceCap.MaxMemory, "storage": resourceCap.MaxStorage} {
+		if _, err := parsePositiveQuantity(value); err != nil {
+			return nil, fmt.Errorf("%w: maximum %s %v", ErrInvalidResourceCap, name, err)
+		}
+	}
+
+	if err := s.capRepo.Upsert(ctx, resourceCap); err != nil {
+		return nil, err
+	}
+
+	return resourceCap, nil
+}
+
+func (s *resourceProfileService) DeleteUserCaps(ctx context.Context, userID uuid.UUID) error {
+	return s.capRepo.Delete(ctx, userID)
+}
+
+// resolveResourceSelection returns the named profile of the selection, or the custom resources it sets
+func resolveResourceSelection(selection ResourceSelection) (*ResourceProfile, error) {
+	name := selection.Profile
+	if name == "" {
+		name = DefaultResourceProfile
+	}
+
+	if name != ResourceProfileCustom {
+		profile, ok := LookupResourceProfile(name)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown profile %q", ErrInvalidResourceProfile, name)
+		}
+		return &profile, nil
+	}
+
+	cpu, err := parseCustomQuantity("CPU", selection.CPU, minCustomCPU)
+	if err != nil {
+		return nil, err
+	}
+	memory, err := parseCustomQuantity("memory", selection.Memory, minCustomMemory)
+	if err != nil {
+		return nil, err
+	}
+	storage, err := parseCustomQuantity("storage size", selection.StorageSize, minCustomStorage)
+	if err != nil {
+		return nil, err
+	}
+	if selection.StorageClass != "" {
+		if errs := validation.IsDNS1123Subdomain(selection.StorageClass); len(errs) > 0 {
+			return nil, fmt.Errorf("%w: storage class %q is not a valid name", ErrInvalidResourceProfile, selection.StorageClass)
+		}
+	}
+
+	return &ResourceProfile{
+		Name:          ResourceProfileCustom,
+		CPURequest:    minCustomCPU,
+		CPULimit:      cpu.String(),
+		MemoryRequest: minCustomMemory,
+		MemoryLimit:   memory.String(),
+		StorageSize:   storage.String(),
+		StorageClass:  selection.StorageClass,
+	}, nil
+}
+
+func parseCustomQuantity(name, value, minimum string) (resource.Quantity, error) {
+	quantity, err := parsePositiveQuantity(value)
+	if err != nil {
+		return quantity, fmt.Errorf("%w: custom %s %v", ErrInvalidResourceProfile, name, err)
+	}
+	if quantity.Cmp(resource.MustParse(minimum)) < 0 {
+		return quantity, fmt.Errorf("%w: custom %s must be at least %s", ErrInvalidResourceProfile, name, minimum)
+	}
+	return quantity, nil
+}
+
+func parsePositiveQuantity(value string) (resource.Quantity, error) {
+	if value == "" {
+		return resource.Quantity{}, errors.New("is required")
+	}
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return quantity, fmt.Errorf("%q is not a valid quantity", value)
+	}
+	if quantity.Sign() <= 0 {
+		return quantity, errors.New("must be positive")
+	}
+	return quantity, nil
+}
+
+// checkResourceCaps reports whether the profile fits in the caps
+func checkResourceCaps(profile *ResourceProfile, caps *model.ResourceCap) error {
+	if profile.Name == ResourceProfileCustom && !caps.AllowCustom {
+		return fmt.Errorf("%w: custom resources are not allowed", ErrResourceCapExceeded)
+	}
+
+	limits := []struct {
+		name, value, max string
+	}{
+		{"CPU", profile.CPULimit, caps.MaxCPU},
+		{"memory", profile.MemoryLimit, caps.MaxMemory},
+		{"storage", profile.StorageSize, caps.MaxStorage},
+	}
+	for _, limit := range limits {
+		maximum, err := resource.ParseQuantity(limit.max)
+		if err != nil {
+			return fmt.Errorf("%w: maximum %s %q is not a valid quantity", ErrInvalidResourceCap, limit.name, limit.max)
+		}
+		value := resource.MustParse(limit.value)
+		if value.Cmp(maximum) > 0 {
+			return fmt.Errorf("%w: %s %s is above the maximum of %s", ErrResourceCapExceeded, limit.name, limit.value, limit.max)
+		}
+	}
+
+	return nil
+}
+
+// applyResourceProfile stores the resources of the profile on the project
+func applyResourceProfile(project *model.Project, profile *ResourceProfile) {
+	project.ResourceProfile = profile.Name
+	project.CPURequest = profile.CPURequest
+	project.CPULimit = profile.CPULimit
+	project.MemoryRequest = profile.MemoryRequest
+	project.MemoryLimit = profile.MemoryLimit
+	project.StorageSize = profile.StorageSize
+	project.StorageClass = profile.StorageClass
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/npinot/vibe/backend/internal/model"
+)
+
+type MockResourceCapRepository struct {
+	mock.Mock
+}
+
+func (m *MockResourceCapRepository) FindByUserID(ctx context.Context, userID uuid.UUID) (*model.ResourceCap, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ResourceCap), args.Error(1)
+}
+
+func (m *MockResourceCapRepository) Upsert(ctx context.Context, resourceCap *model.ResourceCap) error {
+	args := m.Called(ctx, resourceCap)
+	return args.Error(0)
+}
+
+func (m *MockResourceCapRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+var testDefaultCaps = model.ResourceCap{MaxCPU: "2000m", MaxMemory: "4Gi", MaxStorage: "10Gi", AllowCustom: true}
+
+func TestResolveResourceSelection(t *testing.T) {
+	tests := []struct {
+		name      string
+		selection ResourceSelection
+		want      *ResourceProfile
+		wantErr   bool
+	}{
+		{
+			name:      "default profile",
+			selection: ResourceSelection{},
+			want:      &ResourceProfile{Name: "medium", CPURequest: "100m", CPULimit: "1000m", MemoryRequest: "256Mi", MemoryLimit: "1Gi", StorageSize: "1Gi"},
+		},
+		{
+			name:      "named profile ignores custom resources",
+			selection: ResourceSelection{Profile: "large", CPU: "8"},
+			want:      &ResourceProfile{Name: "large", CPURequest: "500m", CPULimit: "2000m", MemoryRequest: "1Gi", MemoryLimit: "4Gi", StorageSize: "10Gi"},
+		},
+		{
+			name:      "custom profile",
+			selection: ResourceSelection{Profile: "custom", CPU: "1.5", Memory: "2048Mi", StorageSize: "5Gi", StorageClass: "fast-ssd"},
+			want:      &ResourceProfile{Name: "custom", CPURequest: "100m", CPULimit: "1500m", MemoryRequest: "256Mi", MemoryLimit: "2Gi", StorageSize: "5Gi", StorageClass: "fast-ssd"},
+		},
+		{
+			name:      "unknown profile",
+			selection: ResourceSelection{Profile: "huge"},
+			wantErr:   true,
+		},
+		{
+			name:      "custom profile without memory",
+			selection: ResourceSelection{Profile: "custom", CPU: "1", StorageSize: "1Gi"},
+			wantErr:   true,
+		},
+		{
+			name:      "custom profile with invalid quantity",
+			selection: ResourceSelection{Profile: "custom", CPU: "lots", Memory: "1Gi", StorageSize: "1Gi"},
+			wantErr:   true,
+		},
+		{
+			name:      "custom profile below the minimum",
+			selection: ResourceSelection{Profile: "custom", CPU: "50m", Memory: "1Gi", StorageSize: "1Gi"},
+			wantErr:   true,
+		},
+		{
+			name:      "custom profile with invalid storage class",
+			selection: ResourceSelection{Profile: "custom", CPU: "1", Memory: "1Gi", StorageSize: "1Gi", StorageClass: "Fast_SSD"},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile, err := resolveResourceSelection(tt.selection)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidResourceProfile)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, profile)
+		})
+	}
+}
+
+func TestResourceProfileService_ResolveProfile(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	t.Run("default caps allow the large profile", func(t *testing.T) {
+		capRepo := new(MockResourceCapRepository)
+		capRepo.On("FindByUserID", ctx, userID).Return(nil, gorm.ErrRecordNotFound)
+		svc := NewResourceProfileService(capRepo, testDefaultCaps)
+
+		profile, err := svc.ResolveProfile(ctx, userID, ResourceSelection{Profile: "large"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "large", profile.Name)
+	})
+
+	t.Run("user caps reject a larger profile", func(t *testing.T) {
+		capRepo := new(MockResourceCapRepository)
+		capRepo.On("FindByUserID", ctx, userID).Return(&model.ResourceCap{UserID: userID, MaxCPU: "1", MaxMemory: "1Gi", MaxStorage: "5Gi", AllowCustom: true}, nil)
+		svc := NewResourceProfileService(capRepo, testDefaultCaps)
+
+		_, err := svc.ResolveProfile(ctx, userID, ResourceSelection{Profile: "large"})
+		assert.ErrorIs(t, err, ErrResourceCapExceeded)
+
+		profile, err := svc.ResolveProfile(ctx, userID, ResourceSelection{Profile: "medium"})
+		require.NoError(t, err)
+		assert.Equal(t, "medium", profile.Name)
+	})
+
+	t.Run("custom resources above the caps", func(t *testing.T) {
+		capRepo := new(MockResourceCapRepository)
+		capRepo.On("FindByUserID", ctx, userID).Return(nil, gorm.ErrRecordNotFound)
+		svc := NewResourceProfileService(capRepo, testDefaultCaps)
+
+		_, err := svc.ResolveProfile(ctx, userID, ResourceSelection{Profile: "custom", CPU: "1", Memory: "1Gi", StorageSize: "20Gi"})
+
+		assert.ErrorIs(t, err, ErrResourceCapExceeded)
+	})
+
+	t.Run("custom resources not allowed", func(t *testing.T) {
+		capRepo := new(MockResourceCapRepository)
+		capRepo.On("FindByUserID", ctx, userID).Return(&model.ResourceCap{UserID: userID, MaxCPU: "4", MaxMemory: "8Gi", MaxStorage: "20Gi"}, nil)
+		svc := NewResourceProfileService(capRepo, testDefaultCaps)
+
+		_, err := svc.ResolveProfile(ctx, userID, ResourceSelection{Profile: "custom", CPU: "1", Memory: "1Gi", StorageSize: "1Gi"})
+
+		assert.ErrorIs(t, err, ErrResourceCapExceeded)
+	})
+}
+
+func TestResourceProfileService_ListProfiles(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	capRepo := new(MockResourceCapRepository)
+	capRepo.On("FindByUserID", ctx, userID).Return(&model.ResourceCap{UserID: userID, MaxCPU: "1000m", MaxMemory: "2Gi", MaxStorage: "5Gi"}, nil)
+	svc := NewResourceProfileService(capRepo, testDefaultCaps)
+
+	list, err := svc.ListProfiles(ctx, userID)
+
+	require.NoError(t, err)
+	assert.Equal(t, "1000m", list.Caps.MaxCPU)
+	allowed := map[string]bool{}
+	for _, profile := range list.Profiles {
+		allowed[profile.Name] = profile.Allowed
+	}
+	assert.Equal(t, map[string]bool{"small": true, "medium": true, "large": false, "custom": false}, allowed)
+}
+
+func TestResourceProfileService_UserCaps(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	t.Run("defaults without user caps", func(t *testing.T) {
+		capRepo := new(MockResourceCapRepository)
+		capRepo.On("FindByUserID", ctx, userID).Return(nil, gorm.ErrRecordNotFound)
+		svc := NewResourceProfileService(capRepo, testDefaultCaps)
+
+		caps, err := svc.GetUserCaps(ctx, userID)
+
+		require.NoError(t, err)
+		assert.Equal(t, userID, caps.UserID)
+		assert.Equal(t, "2000m", caps.MaxCPU)
+		assert.True(t, caps.AllowCustom)
+	})
+
+	t.Run("set caps", func(t *testing.T) {
+		capRepo := new(MockResourceCapRepository)
+		resourceCap := &model.ResourceCap{UserID: userID, MaxCPU: "4", MaxMemory: "8Gi", MaxStorage: "50Gi"}
+		capRepo.On("Upsert", ctx, resourceCap).Return(nil)
+		svc := NewResourceProfileService(capRepo, testDefaultCaps)
+
+		saved, err := svc.SetUserCaps(ctx, resourceCap)
+
+		require.NoError(t, err)
+		assert.Equal(t, resourceCap, saved)
+		capRepo.AssertExpectations(t)
+	})
+
+	t.Run("set invalid caps", func(t *testing.T) {
+		capRepo := new(MockResourceCapRepository)
+		svc := NewResourceProfileService(capRepo, testDefaultCaps)
+
+		_, err := svc.SetUserCaps(ctx, &model.ResourceCap{UserID: userID, MaxCPU: "4", MaxMemory: "-1Gi", MaxStorage: "50Gi"})
+
+		assert.ErrorIs(t, err, ErrInvalidResourceCap)
+		capRepo.AssertNotCalled(t, "Upsert")
+	})
+}
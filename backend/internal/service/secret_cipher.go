@@ -0,0 +1,88 @@
+package service
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+)
+
+// SecretCipher encrypts and decrypts secrets stored in the database
+// (model API keys, repository credentials) using AES-256-GCM
+type SecretCipher interface {
+	Encrypt(plaintext string) ([]byte, error)
+	Decrypt(ciphertext []byte) (string, error)
+}
+
+type aesGCMCipher struct {
+	key []byte // 32-byte AES-256 key
+}
+
+// NewSecretCipher creates a cipher from a base64-encoded 32-byte key
+func NewSecretCipher(encryptionKey string) (SecretCipher, error) {
+	key, err := base64.StdEncoding.DecodeString(encryptionKey)
+	if err != nil || len(key) != 32 {
+		return nil, errors.New("encryption key must be base64-encoded 32 bytes")
+	}
+
+	return &aesGCMCipher{key: key}, nil
+}
+
+// Encrypt encrypts plaintext, prepending the random nonce to the ciphertext
+func (c *aesGCMCipher) Encrypt(plaintext string) ([]byte, error) {
+	return encryptAESGCM(c.key, plaintext)
+}
+
+// Decrypt decrypts a ciphertext produced by Encrypt
+func (c *aesGCMCipher) Decrypt(ciphertext []byte) (string, error) {
+	return decryptAESGCM(c.key, ciphertext)
+}
+
+// encryptAESGCM encrypts plaintext with AES-256-GCM and prepends the nonce
+func encryptAESGCM(key []byte, plaintext string) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aesGCM, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aesGCM.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	ciphertext := aesGCM.Seal(nonce, nonce, []byte(plaintext), nil)
+	return ciphertext, nil
+}
+
+// decryptAESGCM decrypts a nonce-prefixed AES-256-GCM ciphertext
+func decryptAESGCM(key, ciphertext []byte) (string, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+
+	aesGCM, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+
+	nonceSize := aesGCM.NonceSize()
+	if len(ciphertext) < nonceSize {
+		return "", errors.New("ciphertext too short")
+	}
+
+	nonce, ciphertext := ciphertext[:nonceSize], ciphertext[nonceSize:]
+	plaintext, err := aesGCM.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/npinot/vibe/backend/internal/model"
+)
+
+// SessionWatchdog stops sessions that run past the timeout or iteration limit of their project config,
+// whether or not the sidecar enforces them itself
+type SessionWatchdog struct {
+	sessionService SessionService
+	interval       time.Duration
+}
+
+func NewSessionWatchdog(sessionService SessionService, interval time.Duration) *SessionWatchdog {
+	return &SessionWatchdog{
+		sessionService: sessionService,
+		interval:       interval,
+	}
+}
+
+// Run enforces the session limits on every interval until ctx is cancelled
+func (w *SessionWatchdog) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := w.sessionService.EnforceSessionLimits(ctx); err != nil {
+			log.Printf("[SessionWatchdog] %v", err)
+		}
+	}
+}
+
+// EnforceSessionLimits stops every active session that exceeded its timeout or iteration limit
+func (s *sessionService) EnforceSessionLimits(ctx context.Context) error {
+	sessions, err := s.sessionRepo.FindAllActiveSessions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get active sessions: %w", err)
+	}
+
+	now := time.Now()
+	var errs []error
+	for i := range sessions {
+		reason := sessionLimitExceeded(&sessions[i], now)
+		if reason == "" {
+			continue
+		}
+		if err := s.stopSessionOverLimit(ctx, &sessions[i], reason, sessionLimitMetadata(&sessions[i])); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop session %s: %w", sessions[i].ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// startSessionRun starts the timeout, iteration count and run clock over for a new run of the session
+func startSessionRun(session *model.Session, now time.Time) {
+	session.RunStartedAt = &now
+	session.Iterations = 0
+	session.DeadlineAt = nil
+	if session.TimeoutSeconds > 0 {
+		deadline := now.Add(time.Duration(session.TimeoutSeconds) * time.Second)
+		session.DeadlineAt = &deadline
+	}
+}
+
+// pauseSessionRun adds the time since the current run started to the session's duration.
+// Waiting for input or finishing pauses the clock until the next run starts it again.
+func pauseSessionRun(session *model.Session, now time.Time) {
+	if session.RunStartedAt == nil {
+		return
+	}
+	session.DurationMs += now.Sub(*session.RunStartedAt).Milliseconds()
+	session.RunStartedAt = nil
+}
+
+// sessionLimitExceeded explains which limit the session's current run exceeded, if any.
+// Sessions waiting for input are paused and do not run out of time.
+func sessionLimitExceeded(session *model.Session, now time.Time) string {
+	if session.Status == model.SessionStatusWaitingForInput {
+		return ""
+	}
+
+	if session.MaxIterations > 0 && session.Iterations > session.MaxIterations {
+		return fmt.Sprintf("Execution stopped: the agent exceeded the limit of %d iterations", session.MaxIterations)
+	}
+	if session.DeadlineAt != nil && now.After(*session.DeadlineAt) {
+		return fmt.Sprintf("Execution stopped: the session exceeded its timeout of %s", time.Duration(session.TimeoutSeconds)*time.Second)
+	}
+
+	return ""
+}
+
+// recordIteration counts a step of the agent loop and stops the session once it exceeds its
+// iteration limit. It reports whether the session was stopped.
+func (s *sessionService) recordIteration(ctx context.Context, session *model.Session) (bool, error) {
+	unlock := s.lockSession(session.ID)
+	iterations, err := s.sessionRepo.IncrementIterations(ctx, session.ID)
+	unlock()
+	if err != nil {
+		return false, err
+	}
+
+	if session.MaxIterations == 0 || iterations <= session.MaxIterations {
+		return false, nil
+	}
+
+	exceeded := *session
+	exceeded.Iterations = iterations
+	return true, s.stopSessionOverLimit(ctx, &exceeded, sessionLimitExceeded(&exceeded, time.Now()), sessionLimitMetadata(&exceeded))
+}
+
+// sessionLimitMetadata describes the limits of the session for the notification that it was stopped
+func sessionLimitMetadata(session *model.Session) model.JSONB {
+	return model.JSONB{
+		"reason":          "session_limit",
+		"timeout_seconds": session.TimeoutSeconds,
+		"max_iterations":  session.MaxIterations,
+		"iterations":      session.Iterations,
+	}
+}
+
+// enforceBudgets stops the session once the budget of its project or owner is used up, and warns
+// the owner in the task chat the first time a budget reaches its warning threshold in a month.
+// It reports whether the session was stopped.
+func (s *sessionService) enforceBudgets(ctx context.Context, session *model.Session) (bool, error) {
+	if s.budgetService == nil {
+		return false, nil
+	}
+
+	statuses, err := s.budgetService.EvaluateBudgets(ctx, session.ProjectID)
+	if err != nil {
+		return false, err
+	}
+
+	for i := range statuses {
+		status := &statuses[i]
+		if status.Exceeded() {
+			reason := fmt.Sprintf("Execution stopped: %s is used up", status.Describe())
+			return true, s.stopSessionOverLimit(ctx, session, reason, budgetMetadata("budget_exceeded", status))
+		}
+	}
+
+	var errs []error
+	for i := range statuses {
+		status := &statuses[i]
+		if !status.WarningReached() {
+			continue
+		}
+		if err := s.warnBudget(ctx, session, status); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return false, errors.Join(errs...)
+}
+
+// warnBudget posts the warning of a budget once per month
+func (s *sessionService) warnBudget(ctx context.Context, session *model.Session, status *BudgetStatus) error {
+	if s.interactionService == nil {
+		return nil
+	}
+
+	first, err := s.budgetService.MarkWarned(ctx, status)
+	if err != nil || !first {
+		return err
+	}
+
+	project, err := s.projectRepo.FindByID(ctx, session.ProjectID)
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+
+	message := fmt.Sprintf("Budget warning: %d%% of %s is used; sessions stop once it is used up", status.Budget.WarnPercent, status.Describe())
+	if _, err := s.interactionService.CreateSystemNotification(ctx, session.TaskID, project.UserID, &session.ID, message, budgetMetadata("budget_warning", status)); err != nil {
+		return fmt.Errorf("failed to record budget warning: %w", err)
+	}
+
+	return nil
+}
+
+// budgetMetadata describes the budget for budget notifications
+func budgetMetadata(reason string, status *BudgetStatus) model.JSONB {
+	metadata := model.JSONB{
+		"reason":       reason,
+		"scope":        string(status.Budget.Scope),
+		"scope_id":     status.Budget.ScopeID.String(),
+		"period":       status.Period,
+		"spent_usd":    status.Spent.CostUSD,
+		"spent_tokens": status.Spent.InputTokens + status.Spent.OutputTokens,
+		"warn_percent": status.Budget.WarnPercent,
+	}
+	if status.Budget.MonthlyCostUSD != nil {
+		metadata["monthly_cost_usd"] = *status.Budget.MonthlyCostUSD
+	}
+	if status.Budget.MonthlyTokens != nil {
+		metadata["monthly_tokens"] = *status.Budget.MonthlyTokens
+	}
+	return metadata
+}
+
+// stopSessionOverLimit cancels the session on the sidecar, then fails it with the reason
+func (s *sessionService) stopSessionOverLimit(ctx context.Context, session *model.Session, reason string, metadata model.JSONB) error {
+	// A sidecar that is gone, or already stopped the session itself, has nothing left to stop
+	if podIP, err := s.sessionPodIP(ctx, session); err == nil {
+		if err := s.callOpenCodeStop(ctx, podIP, session.ID); err != nil {
+			log.Printf("[SessionWatchdog] Failed to stop session %s on the sidecar: %v", session.ID, err)
+		}
+	}
+
+	return s.failSessionOverLimit(ctx, session, reason, metadata)
+}
+
+// failSessionOverLimit marks the session failed with the reason and tells the user why in the task chat.
+// Sessions that finished meanwhile are left alone.
+func (s *sessionService) failSessionOverLimit(ctx context.Context, session *model.Session, reason string, metadata model.JSONB) error {
+	failed, err := s.transitionSession(ctx, session.ID, string(model.SessionStatusFailed), reason, true)
+	if err != nil || !failed {
+		return err
+	}
+
+	log.Printf("[SessionWatchdog] Session %s stopped: %s", session.ID, reason)
+
+	if s.interactionService == nil {
+		return nil
+	}
+
+	project, err := s.projectRepo.FindByID(ctx, session.ProjectID)
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+
+	if _, err := s.interactionService.CreateSystemNotification(ctx, session.TaskID, project.UserID, &session.ID, reason, metadata); err != nil {
+		return fmt.Errorf("failed to record session limit notification: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,347 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/npinot/vibe/backend/internal/model"
+)
+
+func TestSessionLimitExceeded(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Second)
+	future := now.Add(time.Minute)
+
+	tests := []struct {
+		name    string
+		session model.Session
+		want    string
+	}{
+		{"no limits", model.Session{Status: model.SessionStatusRunning, Iterations: 100}, ""},
+		{"within limits", model.Session{Status: model.SessionStatusRunning, MaxIterations: 10, Iterations: 10, TimeoutSeconds: 300, DeadlineAt: &future}, ""},
+		{"too many iterations", model.Session{Status: model.SessionStatusRunning, MaxIterations: 10, Iterations: 11}, "limit of 10 iterations"},
+		{"past deadline", model.Session{Status: model.SessionStatusRunning, TimeoutSeconds: 300, DeadlineAt: &past}, "timeout of 5m0s"},
+		{"waiting for input", model.Session{Status: model.SessionStatusWaitingForInput, TimeoutSeconds: 300, DeadlineAt: &past}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason := sessionLimitExceeded(&tt.session, now)
+			if tt.want == "" {
+				assert.Empty(t, reason)
+				return
+			}
+			assert.Contains(t, reason, tt.want)
+		})
+	}
+}
+
+func TestStartSessionRun(t *testing.T) {
+	now := time.Now()
+	session := &model.Session{TimeoutSeconds: 60, Iterations: 7}
+
+	startSessionRun(session, now)
+
+	assert.Equal(t, 0, session.Iterations)
+	require.NotNil(t, session.DeadlineAt)
+	assert.Equal(t, now.Add(time.Minute), *session.DeadlineAt)
+
+	session.TimeoutSeconds = 0
+	startSessionRun(session, now)
+	assert.Nil(t, session.DeadlineAt)
+	require.NotNil(t, session.RunStartedAt)
+	assert.Equal(t, now, *session.RunStartedAt)
+}
+
+func TestPauseSessionRun(t *testing.T) {
+	start := time.Now()
+	session := &model.Session{}
+
+	startSessionRun(session, start)
+	pauseSessionRun(session, start.Add(2*time.Second))
+	assert.Equal(t, int64(2000), session.DurationMs)
+	assert.Nil(t, session.RunStartedAt)
+
+	// The time between runs is not counted, and pausing twice counts the run once
+	startSessionRun(session, start.Add(time.Hour))
+	pauseSessionRun(session, start.Add(time.Hour+3*time.Second))
+	pauseSessionRun(session, start.Add(2*time.Hour))
+	assert.Equal(t, int64(5000), session.DurationMs)
+}
+
+func TestSessionService_EnforceSessionLimits(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T, session *model.Session, handler http.HandlerFunc) (*sessionService, *MockSessionRepository, *mockInteractionRepo) {
+		sessionRepo := new(MockSessionRepository)
+		taskRepo := new(MockTaskRepository)
+		projectRepo := new(MockProjectRepository)
+		k8sService := new(MockKubernetesService)
+		interactionRepo := new(mockInteractionRepo)
+
+		server := httptest.NewServer(handler)
+		t.Cleanup(server.Close)
+		target, err := url.Parse(server.URL)
+		require.NoError(t, err)
+
+		ownerID := uuid.New()
+		project := &model.Project{ID: session.ProjectID, UserID: ownerID, PodName: "pod", PodNamespace: "ns"}
+		task := &model.Task{ID: session.TaskID, ProjectID: session.ProjectID, Status: model.TaskStatusInProgress}
+
+		sessionRepo.On("FindAllActiveSessions", ctx).Return([]model.Session{*session}, nil)
+		projectRepo.On("FindByID", mock.Anything, session.ProjectID).Return(project, nil)
+		k8sService.On("GetPodIP", mock.Anything, "pod", "ns").Return("10.0.0.1", nil)
+		taskRepo.On("FindByID", mock.Anything, session.TaskID).Return(task, nil)
+		taskRepo.On("UpdateStatus", mock.Anything, session.TaskID, model.TaskStatusTodo).Return(nil)
+
+		service := &sessionService{
+			sessionRepo:        sessionRepo,
+			taskRepo:           taskRepo,
+			projectRepo:        projectRepo,
+			k8sService:         k8sService,
+			interactionService: NewInteractionService(interactionRepo, taskRepo, NewAuthorizationService(projectRepo, nil), sessionRepo),
+			httpClient:         &http.Client{Transport: sidecarTransport{target: target}},
+		}
+
+		return service, sessionRepo, interactionRepo
+	}
+
+	newSession := func() *model.Session {
+		return &model.Session{
+			ID:             uuid.New(),
+			TaskID:         uuid.New(),
+			ProjectID:      uuid.New(),
+			Status:         model.SessionStatusRunning,
+			Kind:           model.SessionKindExecution,
+			TimeoutSeconds: 300,
+			MaxIterations:  10,
+		}
+	}
+
+	t.Run("stops session past its deadline", func(t *testing.T) {
+		session := newSession()
+		deadline := time.Now().Add(-time.Second)
+		session.DeadlineAt = &deadline
+
+		stopped := false
+		service, sessionRepo, interactionRepo := setup(t, session, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "DELETE", r.Method)
+			assert.Equal(t, "/session/"+session.ID.String(), r.URL.Path)
+			stopped = true
+			w.Write([]byte(`{"status": "cancelled"}`))
+		})
+		stored := *session
+		sessionRepo.On("FindByID", mock.Anything, session.ID).Return(&stored, nil)
+		sessionRepo.On("Update", mock.Anything, &stored).Return(nil)
+		interactionRepo.On("Create", mock.Anything, mock.MatchedBy(func(interaction *model.Interaction) bool {
+			return interaction.MessageType == MessageTypeSystem &&
+				*interaction.SessionID == session.ID &&
+				strings.Contains(interaction.Content, "timeout of 5m0s")
+		})).Return(nil).Once()
+
+		err := service.EnforceSessionLimits(ctx)
+
+		require.NoError(t, err)
+		assert.True(t, stopped)
+		assert.Equal(t, model.SessionStatusFailed, stored.Status)
+		assert.Contains(t, stored.Error, "timeout of 5m0s")
+		interactionRepo.AssertExpectations(t)
+	})
+
+	t.Run("leaves sessions within their limits", func(t *testing.T) {
+		session := newSession()
+		deadline := time.Now().Add(time.Minute)
+		session.DeadlineAt = &deadline
+		session.Iterations = 10
+
+		service, _, _ := setup(t, session, func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("unexpected sidecar request %s", r.URL.Path)
+		})
+
+		require.NoError(t, service.EnforceSessionLimits(ctx))
+	})
+
+	t.Run("session finished meanwhile", func(t *testing.T) {
+		session := newSession()
+		session.Iterations = 11
+
+		service, sessionRepo, interactionRepo := setup(t, session, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		})
+		completed := *session
+		completed.Status = model.SessionStatusCompleted
+		sessionRepo.On("FindByID", mock.Anything, session.ID).Return(&completed, nil)
+
+		require.NoError(t, service.EnforceSessionLimits(ctx))
+		sessionRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		interactionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestSessionService_RecordIteration(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("tool call past the limit stops the session", func(t *testing.T) {
+		service, sessionRepo, taskRepo, session := setupSessionStreamTest(model.SessionKindExecution)
+		session.MaxIterations = 2
+		projectRepo := new(MockProjectRepository)
+		k8sService := new(MockKubernetesService)
+		service.projectRepo = projectRepo
+		service.k8sService = k8sService
+
+		stream := "event: tool_call\nid: evt-3\ndata: {\"tool\": \"bash\"}\n\n"
+		sessionRepo.On("AppendOutput", ctx, session.ID, session.TaskID, "\n[tool] bash\n", "evt-3").Return(nil)
+		sessionRepo.On("IncrementIterations", ctx, session.ID).Return(3, nil)
+		// The pod is gone; the session is failed all the same
+		projectRepo.On("FindByID", ctx, session.ProjectID).Return(&model.Project{ID: session.ProjectID, PodName: "pod", PodNamespace: "ns"}, nil)
+		k8sService.On("GetPodIP", ctx, "pod", "ns").Return("", assert.AnError)
+		sessionRepo.On("FindByID", ctx, session.ID).Return(session, nil)
+		sessionRepo.On("Update", ctx, session).Return(nil)
+		taskRepo.On("FindByID", ctx, session.TaskID).Return(&model.Task{ID: session.TaskID, Status: model.TaskStatusInProgress}, nil)
+		taskRepo.On("UpdateStatus", ctx, session.TaskID, model.TaskStatusTodo).Return(nil)
+
+		lastEventID := ""
+		finished, err := service.consumeSidecarEvents(ctx, session, strings.NewReader(stream), &lastEventID)
+
+		require.NoError(t, err)
+		assert.True(t, finished)
+		assert.Equal(t, model.SessionStatusFailed, session.Status)
+		assert.Contains(t, session.Error, "limit of 2 iterations")
+		taskRepo.AssertExpectations(t)
+	})
+
+	t.Run("tool call within the limit", func(t *testing.T) {
+		service, sessionRepo, _, session := setupSessionStreamTest(model.SessionKindReview)
+		session.MaxIterations = 2
+
+		stream := "event: tool_call\nid: evt-2\ndata: {\"tool\": \"read\"}\n\n"
+		sessionRepo.On("AppendOutput", ctx, session.ID, uuid.Nil, "\n[tool] read\n", "evt-2").Return(nil)
+		sessionRepo.On("IncrementIterations", ctx, session.ID).Return(2, nil)
+
+		lastEventID := ""
+		finished, err := service.consumeSidecarEvents(ctx, session, strings.NewReader(stream), &lastEventID)
+
+		require.NoError(t, err)
+		assert.False(t, finished)
+		assert.Equal(t, model.SessionStatusRunning, session.Status)
+	})
+}
+
+func TestSessionService_FinishSessionOverLimit(t *testing.T) {
+	ctx := context.Background()
+
+	// The sidecar's own timeout fires first and reports the session failed
+	service, sessionRepo, taskRepo, session := setupSessionStreamTest(model.SessionKindExecution)
+	deadline := time.Now().Add(-time.Second)
+	session.TimeoutSeconds = 60
+	session.DeadlineAt = &deadline
+	projectRepo := new(MockProjectRepository)
+	interactionRepo := new(mockInteractionRepo)
+	service.projectRepo = projectRepo
+	service.interactionService = NewInteractionService(interactionRepo, taskRepo, NewAuthorizationService(projectRepo, nil), sessionRepo)
+	ownerID := uuid.New()
+
+	sessionRepo.On("FindByID", mock.Anything, session.ID).Return(session, nil)
+	sessionRepo.On("Update", ctx, session).Return(nil)
+	projectRepo.On("FindByID", mock.Anything, session.ProjectID).Return(&model.Project{ID: session.ProjectID, UserID: ownerID}, nil)
+	taskRepo.On("FindByID", mock.Anything, session.TaskID).Return(&model.Task{ID: session.TaskID, ProjectID: session.ProjectID, Status: model.TaskStatusInProgress}, nil)
+	taskRepo.On("UpdateStatus", ctx, session.TaskID, model.TaskStatusTodo).Return(nil)
+	interactionRepo.On("Create", ctx, mock.MatchedBy(func(interaction *model.Interaction) bool {
+		return interaction.MessageType == MessageTypeSystem && interaction.UserID == ownerID && strings.Contains(interaction.Content, "timeout of 1m0s")
+	})).Return(nil).Once()
+
+	err := service.finishSession(ctx, session.ID, model.SessionStatusFailed, "Session timeout after 60 seconds", "")
+
+	require.NoError(t, err)
+	assert.Equal(t, model.SessionStatusFailed, session.Status)
+	assert.Contains(t, session.Error, "timeout of 1m0s")
+	interactionRepo.AssertExpectations(t)
+}
+
+func TestSessionService_EnforceBudgets(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T, spentUSD float64) (*sessionService, *MockSessionRepository, *MockTaskRepository, *MockBudgetRepository, *mockInteractionRepo, *model.Session, *model.Budget) {
+		service, sessionRepo, taskRepo, session := setupSessionStreamTest(model.SessionKindExecution)
+		session.ModelProvider = "openai"
+		session.ModelName = "gpt-4o"
+		projectRepo := new(MockProjectRepository)
+		k8sService := new(MockKubernetesService)
+		budgetRepo := new(MockBudgetRepository)
+		interactionRepo := new(mockInteractionRepo)
+		service.projectRepo = projectRepo
+		service.k8sService = k8sService
+		service.budgetService = NewBudgetService(budgetRepo, sessionRepo, projectRepo, NewAuthorizationService(projectRepo, nil))
+		service.interactionService = NewInteractionService(interactionRepo, taskRepo, NewAuthorizationService(projectRepo, nil), sessionRepo)
+
+		ownerID := uuid.New()
+		budget := &model.Budget{ID: uuid.New(), Scope: model.BudgetScopeProject, ScopeID: session.ProjectID, MonthlyCostUSD: floatPtr(10), WarnPercent: 80}
+		projectRepo.On("FindByID", mock.Anything, session.ProjectID).Return(&model.Project{ID: session.ProjectID, UserID: ownerID, PodName: "pod", PodNamespace: "ns"}, nil)
+		k8sService.On("GetPodIP", mock.Anything, "pod", "ns").Return("", assert.AnError)
+		budgetRepo.On("FindByScope", ctx, model.BudgetScopeProject, session.ProjectID).Return(budget, nil)
+		budgetRepo.On("FindByScope", ctx, model.BudgetScopeUser, ownerID).Return(nil, gorm.ErrRecordNotFound)
+		sessionRepo.On("SumProjectUsageSince", ctx, session.ProjectID, mock.Anything).Return(&model.UsageTotals{CostUSD: spentUSD}, nil)
+		sessionRepo.On("SumUserUsageSince", ctx, ownerID, mock.Anything).Return(&model.UsageTotals{CostUSD: spentUSD}, nil)
+		sessionRepo.On("AppendOutput", ctx, session.ID, session.TaskID, "", mock.Anything).Return(nil)
+		sessionRepo.On("AddUsage", ctx, session.ID, mock.Anything).Return(nil)
+		sessionRepo.On("FindByID", ctx, session.ID).Return(session, nil)
+		taskRepo.On("FindByID", mock.Anything, session.TaskID).Return(&model.Task{ID: session.TaskID, ProjectID: session.ProjectID, Status: model.TaskStatusInProgress}, nil)
+
+		return service, sessionRepo, taskRepo, budgetRepo, interactionRepo, session, budget
+	}
+
+	usageEvent := func(id string) string {
+		return "event: usage\nid: " + id + "\ndata: {\"input_tokens\": 1000, \"output_tokens\": 100}\n\n"
+	}
+
+	t.Run("stops session once the budget is used up", func(t *testing.T) {
+		service, sessionRepo, taskRepo, _, interactionRepo, session, _ := setup(t, 10.2)
+		sessionRepo.On("Update", ctx, session).Return(nil)
+		taskRepo.On("UpdateStatus", ctx, session.TaskID, model.TaskStatusTodo).Return(nil)
+		interactionRepo.On("Create", ctx, mock.MatchedBy(func(interaction *model.Interaction) bool {
+			return interaction.MessageType == MessageTypeSystem &&
+				interaction.Content == "Execution stopped: the project's monthly budget of $10.00 ($10.20 spent) is used up" &&
+				interaction.Metadata["reason"] == "budget_exceeded"
+		})).Return(nil).Once()
+
+		lastEventID := ""
+		finished, err := service.consumeSidecarEvents(ctx, session, strings.NewReader(usageEvent("evt-1")+usageEvent("evt-2")), &lastEventID)
+
+		require.NoError(t, err)
+		assert.True(t, finished)
+		assert.Equal(t, "evt-1", lastEventID)
+		assert.Equal(t, model.SessionStatusFailed, session.Status)
+		assert.Contains(t, session.Error, "budget")
+		interactionRepo.AssertExpectations(t)
+	})
+
+	t.Run("warns once when the warning threshold is reached", func(t *testing.T) {
+		service, _, _, budgetRepo, interactionRepo, session, budget := setup(t, 8.5)
+		budgetRepo.On("MarkWarned", ctx, budget.ID, mock.Anything).Return(true, nil).Once()
+		budgetRepo.On("MarkWarned", ctx, budget.ID, mock.Anything).Return(false, nil)
+		interactionRepo.On("Create", ctx, mock.MatchedBy(func(interaction *model.Interaction) bool {
+			return interaction.MessageType == MessageTypeSystem &&
+				strings.HasPrefix(interaction.Content, "Budget warning: 80% of the project's monthly budget of $10.00") &&
+				interaction.Metadata["reason"] == "budget_warning"
+		})).Return(nil).Once()
+
+		lastEventID := ""
+		finished, err := service.consumeSidecarEvents(ctx, session, strings.NewReader(usageEvent("evt-1")+usageEvent("evt-2")), &lastEventID)
+
+		require.NoError(t, err)
+		assert.False(t, finished)
+		assert.Equal(t, model.SessionStatusRunning, session.Status)
+		interactionRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/npinot/vibe/backend/internal/model"
+)
+
+// ContinueSession sends a follow-up prompt to the remote OpenCode session of an execution session.
+// A running session answers it after its current prompt, a session waiting for input takes it as
+// the answer to its question, and a finished session runs again with the conversation so far.
+// The prompt is recorded as a user interaction on the session.
+func (s *sessionService) ContinueSession(ctx context.Context, sessionID, userID uuid.UUID, prompt string) (*model.Session, error) {
+	unlock := s.lockSession(sessionID)
+	defer unlock()
+
+	session, err := s.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	// Reviewer sessions are driven by the AI review stage, and sessions the sidecar never
+	// started have no conversation to continue
+	if session.Kind == model.SessionKindReview || session.RemoteSessionID == "" {
+		return nil, fmt.Errorf("%w: session %s has no OpenCode conversation to continue", ErrSessionNotContinuable, session.ID)
+	}
+
+	if s.budgetService != nil {
+		if err := s.budgetService.CheckBudget(ctx, session.ProjectID); err != nil {
+			return nil, err
+		}
+	}
+
+	podIP, err := s.sessionPodIP(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.callOpenCodePrompt(ctx, podIP, session, prompt); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOpenCodeAPICall, err)
+	}
+
+	// Mark the turn in the transcript so the streamed output of each prompt can be told apart
+	turn := fmt.Sprintf("\n\n> %s\n\n", prompt)
+	session.Output += turn
+	if err := s.taskRepo.AppendOutput(ctx, session.TaskID, session.ID, turn); err != nil {
+		return nil, fmt.Errorf("failed to append task output: %w", err)
+	}
+
+	// A queued prompt runs within the current run; otherwise the sidecar starts a new one,
+	// and with it the timeout and iteration count
+	switch {
+	case session.Status == model.SessionStatusWaitingForInput:
+		session.Status = model.SessionStatusRunning
+		startSessionRun(session, time.Now())
+	case isTerminalSessionStatus(session.Status):
+		// The new turn is finalized like a new run: snapshot, commit and hand-off happen again
+		session.Status = model.SessionStatusRunning
+		session.Error = ""
+		session.FinalOutput = ""
+		session.FinalSnapshot = ""
+		session.CommitSHA = ""
+		session.CompletedAt = nil
+		startSessionRun(session, time.Now())
+	}
+
+	if err := s.sessionRepo.Update(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to update session: %w", err)
+	}
+
+	if s.interactionRepo != nil {
+		interaction := &model.Interaction{
+			TaskID:      session.TaskID,
+			SessionID:   &session.ID,
+			UserID:      userID,
+			MessageType: MessageTypeUser,
+			Content:     prompt,
+		}
+		if err := s.interactionRepo.Create(ctx, interaction); err != nil {
+			return nil, fmt.Errorf("failed to record prompt: %w", err)
+		}
+	}
+
+	s.followSessionStream(session)
+
+	return session, nil
+}
+
+// recordAgentTurn records the agent's answer to a follow-up prompt as an interaction,
+// on behalf of the user who sent the latest prompt. Single-prompt sessions have no turns to record.
+func (s *sessionService) recordAgentTurn(ctx context.Context, session *model.Session, output string) error {
+	if s.interactionRepo == nil || output == "" {
+		return nil
+	}
+
+	interactions, err := s.interactionRepo.FindBySessionID(ctx, session.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get session interactions: %w", err)
+	}
+
+	var lastPrompt *model.Interaction
+	for i := len(interactions) - 1; i >= 0; i-- {
+		if interactions[i].MessageType == MessageTypeUser {
+			lastPrompt = &interactions[i]
+			break
+		}
+	}
+	if lastPrompt == nil {
+		return nil
+	}
+
+	response := &model.Interaction{
+		TaskID:      session.TaskID,
+		SessionID:   &session.ID,
+		UserID:      lastPrompt.UserID,
+		MessageType: MessageTypeAgent,
+		Content:     output,
+	}
+	if err := s.interactionRepo.Create(ctx, response); err != nil {
+		return fmt.Errorf("failed to record agent response: %w", err)
+	}
+
+	return nil
+}
+
+// waitForUserInput pauses a session on a question the agent asked and records the question as an
+// agent response on behalf of the project owner, so it shows up in the task chat. The answer
+// comes back through ContinueSession.
+func (s *sessionService) waitForUserInput(ctx context.Context, session *model.Session, question string) error {
+	paused, err := s.markWaitingForInput(ctx, session.ID)
+	if err != nil {
+		return err
+	}
+	if !paused || s.interactionService == nil || question == "" {
+		return nil
+	}
+
+	project, err := s.projectRepo.FindByID(ctx, session.ProjectID)
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+
+	if _, err := s.interactionService.CreateAgentResponse(ctx, session.TaskID, project.UserID, session.ID, question, model.JSONB{"question": true}); err != nil {
+		return fmt.Errorf("failed to record agent question: %w", err)
+	}
+
+	return nil
+}
+
+// markWaitingForInput moves an underway session to waiting_for_input.
+// Sessions that already finished, e.g. because they were stopped, are left alone.
+func (s *sessionService) markWaitingForInput(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+	unlock := s.lockSession(sessionID)
+	defer unlock()
+
+	session, err := s.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get session: %w", err)
+	}
+	if !isActiveSessionStatus(session.Status) {
+		return false, nil
+	}
+
+	session.Status = model.SessionStatusWaitingForInput
+	pauseSessionRun(session, time.Now())
+	if err := s.sessionRepo.Update(ctx, session); err != nil {
+		return false, fmt.Errorf("failed to update session: %w", err)
+	}
+
+	return true, nil
+}
+
+// callOpenCodePrompt sends a follow-up prompt to the session on the sidecar.
+// The remote session and model config let the sidecar resume a session it already dropped.
+func (s *sessionService) callOpenCodePrompt(ctx context.Context, podIP string, session *model.Session, prompt string) error {
+	url := fmt.Sprintf("%s/session/%s/prompt", SessionProxyURL(podIP), session.ID.String())
+
+	requestBody, err := s.sidecarSessionConfig(ctx, session, sessionOptions{kind: session.Kind, readOnly: session.Kind == model.SessionKindReview})
+	if err != nil {
+		return err
+	}
+	requestBody["prompt"] = prompt
+	requestBody["remote_session_id"] = session.RemoteSessionID
+
+	jsonBody, err := json.Marshal(requestBody)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if s.sharedSecret != "" {
+		req.Header.Set("Authorization", "Bearer "+s.sharedSecret)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call OpenCode API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("OpenCode API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/npinot/vibe/backend/internal/model"
+)
+
+func TestSessionService_ContinueSession(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	setup := func(t *testing.T, session *model.Session, handler http.HandlerFunc) (*sessionService, *MockSessionRepository, *MockTaskRepository, *mockInteractionRepo) {
+		sessionRepo := new(MockSessionRepository)
+		taskRepo := new(MockTaskRepository)
+		projectRepo := new(MockProjectRepository)
+		interactionRepo := new(mockInteractionRepo)
+		k8sService := new(MockKubernetesService)
+		configService := new(MockConfigService)
+
+		server := httptest.NewServer(handler)
+		t.Cleanup(server.Close)
+		target, err := url.Parse(server.URL)
+		require.NoError(t, err)
+
+		sessionRepo.On("FindByID", ctx, session.ID).Return(session, nil)
+		projectRepo.On("FindByID", ctx, session.ProjectID).Return(&model.Project{ID: session.ProjectID, PodName: "pod", PodNamespace: "ns"}, nil)
+		k8sService.On("GetPodIP", ctx, "pod", "ns").Return("10.0.0.1", nil)
+		configService.On("GetActiveConfig", ctx, session.ProjectID).Return(&model.OpenCodeConfig{
+			ModelProvider: "openai",
+			ModelName:     "gpt-4o-mini",
+			Temperature:   0.7,
+			MaxTokens:     4096,
+		}, nil)
+		configService.On("GetDecryptedAPIKey", ctx, session.ProjectID).Return("sk-test", nil)
+
+		service := &sessionService{
+			sessionRepo:     sessionRepo,
+			taskRepo:        taskRepo,
+			projectRepo:     projectRepo,
+			interactionRepo: interactionRepo,
+			k8sService:      k8sService,
+			configService:   configService,
+			httpClient:      &http.Client{Transport: sidecarTransport{target: target}},
+		}
+
+		return service, sessionRepo, taskRepo, interactionRepo
+	}
+
+	newSession := func(status model.SessionStatus) *model.Session {
+		completedAt := time.Now()
+		return &model.Session{
+			ID:              uuid.New(),
+			TaskID:          uuid.New(),
+			ProjectID:       uuid.New(),
+			Status:          status,
+			Kind:            model.SessionKindExecution,
+			RemoteSessionID: "ses_remote",
+			Output:          "First answer",
+			FinalOutput:     "First answer",
+			FinalSnapshot:   "snap-final",
+			CommitSHA:       "abc123",
+			CompletedAt:     &completedAt,
+		}
+	}
+
+	t.Run("resumes finished session", func(t *testing.T) {
+		session := newSession(model.SessionStatusCompleted)
+		session.DurationMs = 60000
+
+		var body map[string]interface{}
+		service, sessionRepo, taskRepo, interactionRepo := setup(t, session, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "POST", r.Method)
+			assert.Equal(t, "/session/"+session.ID.String()+"/prompt", r.URL.Path)
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(`{"status": "running"}`))
+		})
+
+		turn := "\n\n> Also handle empty input\n\n"
+		taskRepo.On("AppendOutput", ctx, session.TaskID, session.ID, turn).Return(nil)
+		sessionRepo.On("Update", ctx, session).Return(nil)
+		interactionRepo.On("Create", ctx, mock.MatchedBy(func(interaction *model.Interaction) bool {
+			return interaction.MessageType == MessageTypeUser &&
+				interaction.Content == "Also handle empty input" &&
+				interaction.UserID == userID &&
+				interaction.TaskID == session.TaskID &&
+				interaction.SessionID != nil && *interaction.SessionID == session.ID
+		})).Return(nil)
+
+		result, err := service.ContinueSession(ctx, session.ID, userID, "Also handle empty input")
+
+		require.NoError(t, err)
+		assert.Equal(t, model.SessionStatusRunning, result.Status)
+		assert.Nil(t, result.CompletedAt)
+		assert.Empty(t, result.FinalOutput)
+		assert.Empty(t, result.FinalSnapshot)
+		assert.Empty(t, result.CommitSHA)
+		assert.Equal(t, "First answer"+turn, result.Output)
+		require.NotNil(t, result.RunStartedAt)
+		assert.WithinDuration(t, time.Now(), *result.RunStartedAt, time.Minute)
+		assert.Equal(t, int64(60000), result.DurationMs)
+
+		assert.Equal(t, "Also handle empty input", body["prompt"])
+		assert.Equal(t, "ses_remote", body["remote_session_id"])
+		modelConfig, ok := body["model_config"].(map[string]interface{})
+		require.True(t, ok)
+		assert.Equal(t, "sk-test", modelConfig["api_key"])
+		sessionRepo.AssertExpectations(t)
+		taskRepo.AssertExpectations(t)
+		interactionRepo.AssertExpectations(t)
+	})
+
+	t.Run("queues prompt on running session", func(t *testing.T) {
+		session := newSession(model.SessionStatusRunning)
+		session.CompletedAt = nil
+		session.FinalSnapshot = ""
+
+		service, sessionRepo, taskRepo, interactionRepo := setup(t, session, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(`{"status": "queued"}`))
+		})
+
+		taskRepo.On("AppendOutput", ctx, session.TaskID, session.ID, mock.Anything).Return(nil)
+		sessionRepo.On("Update", ctx, session).Return(nil)
+		interactionRepo.On("Create", ctx, mock.Anything).Return(nil)
+
+		result, err := service.ContinueSession(ctx, session.ID, userID, "Use the new helper")
+
+		require.NoError(t, err)
+		assert.Equal(t, model.SessionStatusRunning, result.Status)
+		assert.Equal(t, "abc123", result.CommitSHA)
+	})
+
+	t.Run("answers question of waiting session", func(t *testing.T) {
+		session := newSession(model.SessionStatusWaitingForInput)
+		session.CompletedAt = nil
+
+		service, sessionRepo, taskRepo, interactionRepo := setup(t, session, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(`{"status": "running"}`))
+		})
+
+		taskRepo.On("AppendOutput", ctx, session.TaskID, session.ID, mock.Anything).Return(nil)
+		sessionRepo.On("Update", ctx, session).Return(nil)
+		interactionRepo.On("Create", ctx, mock.Anything).Return(nil)
+
+		result, err := service.ContinueSession(ctx, session.ID, userID, "PostgreSQL")
+
+		require.NoError(t, err)
+		assert.Equal(t, model.SessionStatusRunning, result.Status)
+		assert.Equal(t, "snap-final", result.FinalSnapshot)
+		assert.Equal(t, "abc123", result.CommitSHA)
+	})
+
+	t.Run("rejects sessions without conversation", func(t *testing.T) {
+		review := newSession(model.SessionStatusCompleted)
+		review.Kind = model.SessionKindReview
+		neverStarted := newSession(model.SessionStatusFailed)
+		neverStarted.RemoteSessionID = ""
+
+		for _, session := range []*model.Session{review, neverStarted} {
+			service, _, _, interactionRepo := setup(t, session, func(w http.ResponseWriter, r *http.Request) {
+				t.Errorf("unexpected sidecar request %s", r.URL.Path)
+			})
+
+			_, err := service.ContinueSession(ctx, session.ID, userID, "Try again")
+
+			assert.ErrorIs(t, err, ErrSessionNotContinuable)
+			interactionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("sidecar rejects prompt", func(t *testing.T) {
+		session := newSession(model.SessionStatusCompleted)
+
+		service, sessionRepo, _, interactionRepo := setup(t, session, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error": "OpenCode session not found"}`))
+		})
+
+		_, err := service.ContinueSession(ctx, session.ID, userID, "Try again")
+
+		assert.ErrorIs(t, err, ErrOpenCodeAPICall)
+		sessionRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		interactionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestSessionService_RecordAgentTurn(t *testing.T) {
+	ctx := context.Background()
+	session := &model.Session{ID: uuid.New(), TaskID: uuid.New()}
+
+	t.Run("answers the latest prompt", func(t *testing.T) {
+		interactionRepo := new(mockInteractionRepo)
+		service := &sessionService{interactionRepo: interactionRepo}
+		firstUser, secondUser := uuid.New(), uuid.New()
+
+		interactionRepo.On("FindBySessionID", ctx, session.ID).Return([]model.Interaction{
+			{MessageType: MessageTypeUser, UserID: firstUser},
+			{MessageType: MessageTypeAgent, UserID: firstUser},
+			{MessageType: MessageTypeUser, UserID: secondUser},
+		}, nil)
+		interactionRepo.On("Create", ctx, mock.MatchedBy(func(interaction *model.Interaction) bool {
+			return interaction.MessageType == MessageTypeAgent &&
+				interaction.Content == "Done" &&
+				interaction.UserID == secondUser &&
+				interaction.SessionID != nil && *interaction.SessionID == session.ID
+		})).Return(nil)
+
+		require.NoError(t, service.recordAgentTurn(ctx, session, "Done"))
+		interactionRepo.AssertExpectations(t)
+	})
+
+	t.Run("single prompt session", func(t *testing.T) {
+		interactionRepo := new(mockInteractionRepo)
+		service := &sessionService{interactionRepo: interactionRepo}
+
+		interactionRepo.On("FindBySessionID", ctx, session.ID).Return([]model.Interaction{}, nil)
+
+		require.NoError(t, service.recordAgentTurn(ctx, session, "Done"))
+		interactionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("lookup failure", func(t *testing.T) {
+		interactionRepo := new(mockInteractionRepo)
+		service := &sessionService{interactionRepo: interactionRepo}
+
+		interactionRepo.On("FindBySessionID", ctx, session.ID).Return(nil, errors.New("db down"))
+
+		assert.Error(t, service.recordAgentTurn(ctx, session, "Done"))
+	})
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/npinot/vibe/backend/internal/model"
+)
+
+// orphanedSessionGracePeriod is how long a session may go without a reachable sidecar, or without
+// being started on it, before it is marked failed
+const orphanedSessionGracePeriod = 10 * time.Minute
+
+// SessionReconciler settles the sessions the backend still considers active, at startup and then
+// periodically, so sessions whose sidecar went away do not stay running forever
+type SessionReconciler struct {
+	sessionService SessionService
+	interval       time.Duration
+}
+
+func NewSessionReconciler(sessionService SessionService, interval time.Duration) *SessionReconciler {
+	return &SessionReconciler{
+		sessionService: sessionService,
+		interval:       interval,
+	}
+}
+
+// Run reconciles immediately and then on every interval until ctx is cancelled
+func (r *SessionReconciler) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.sessionService.ReconcileSessions(ctx); err != nil {
+			log.Printf("[SessionReconciler] %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ReconcileSessions compares every active session with its sidecar: sessions still running there are
+// followed again from their last stored event, the others are finished with the sidecar's final status
+// or marked failed when the sidecar no longer knows them
+func (s *sessionService) ReconcileSessions(ctx context.Context) error {
+	sessions, err := s.sessionRepo.FindAllActiveSessions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get active sessions: %w", err)
+	}
+
+	var errs []error
+	for i := range sessions {
+		if err := s.reconcileSession(ctx, &sessions[i]); err != nil {
+			errs = append(errs, fmt.Errorf("failed to reconcile session %s: %w", sessions[i].ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *sessionService) reconcileSession(ctx context.Context, session *model.Session) error {
+	if _, following := s.followers.Load(session.ID); following {
+		return nil
+	}
+
+	if session.RemoteSessionID == "" {
+		// The session may still be starting; give the sidecar call time to return
+		if time.Since(session.CreatedAt) < orphanedSessionGracePeriod {
+			return nil
+		}
+		return s.finishSession(ctx, session.ID, model.SessionStatusFailed, "session was never started on the OpenCode sidecar", "")
+	}
+
+	podIP, err := s.sessionPodIP(ctx, session)
+	if err != nil {
+		return s.failUnreachableSession(ctx, session, err)
+	}
+
+	sidecarStatus, found, err := s.fetchSidecarSessionStatus(ctx, podIP, session.ID)
+	if err != nil {
+		return s.failUnreachableSession(ctx, session, err)
+	}
+
+	switch {
+	case !found:
+		return s.finishSession(ctx, session.ID, model.SessionStatusFailed, sessionGoneMessage, "")
+	case isTerminalSessionStatus(model.SessionStatus(sidecarStatus.Status)):
+		return s.finishSession(ctx, session.ID, model.SessionStatus(sidecarStatus.Status), sidecarStatus.Error, "")
+	}
+
+	s.followSessionStream(session)
+	return nil
+}
+
+// failUnreachableSession marks a session failed once its sidecar has been unreachable for the grace period.
+// A pod that is only restarting gets the chance to come back first.
+func (s *sessionService) failUnreachableSession(ctx context.Context, session *model.Session, cause error) error {
+	if time.Since(session.UpdatedAt) < orphanedSessionGracePeriod {
+		return nil
+	}
+
+	return s.finishSession(ctx, session.ID, model.SessionStatusFailed, fmt.Sprintf("OpenCode sidecar unreachable: %v", cause), "")
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/npinot/vibe/backend/internal/model"
+)
+
+func TestSessionService_ReconcileSessions(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T, session *model.Session, handler http.HandlerFunc) (*sessionService, *MockSessionRepository, *MockTaskRepository, *MockKubernetesService) {
+		sessionRepo := new(MockSessionRepository)
+		taskRepo := new(MockTaskRepository)
+		projectRepo := new(MockProjectRepository)
+		k8sService := new(MockKubernetesService)
+
+		server := httptest.NewServer(handler)
+		t.Cleanup(server.Close)
+		target, err := url.Parse(server.URL)
+		require.NoError(t, err)
+
+		sessionRepo.On("FindAllActiveSessions", ctx).Return([]model.Session{*session}, nil)
+		projectRepo.On("FindByID", ctx, session.ProjectID).Return(&model.Project{ID: session.ProjectID, PodName: "pod", PodNamespace: "ns"}, nil)
+
+		service := &sessionService{
+			sessionRepo:  sessionRepo,
+			taskRepo:     taskRepo,
+			projectRepo:  projectRepo,
+			k8sService:   k8sService,
+			httpClient:   &http.Client{Transport: sidecarTransport{target: target}},
+			streamClient: &http.Client{Transport: sidecarTransport{target: target}},
+		}
+
+		return service, sessionRepo, taskRepo, k8sService
+	}
+
+	newSession := func(remoteSessionID string, age time.Duration) *model.Session {
+		return &model.Session{
+			ID:              uuid.New(),
+			TaskID:          uuid.New(),
+			ProjectID:       uuid.New(),
+			Status:          model.SessionStatusRunning,
+			Kind:            model.SessionKindExecution,
+			RemoteSessionID: remoteSessionID,
+			LastEventID:     "evt-7",
+			CreatedAt:       time.Now().Add(-age),
+			UpdatedAt:       time.Now().Add(-age),
+		}
+	}
+
+	// expectFinish sets up the database side of finishing the session and returns the stored session
+	expectFinish := func(sessionRepo *MockSessionRepository, taskRepo *MockTaskRepository, session *model.Session) *model.Session {
+		stored := *session
+		sessionRepo.On("FindByID", mock.Anything, session.ID).Return(&stored, nil)
+		sessionRepo.On("Update", mock.Anything, &stored).Return(nil)
+		taskRepo.On("FindByID", mock.Anything, session.TaskID).Return(&model.Task{ID: session.TaskID, Status: model.TaskStatusInProgress}, nil)
+		taskRepo.On("UpdateStatus", mock.Anything, session.TaskID, model.TaskStatusTodo).Return(nil)
+		return &stored
+	}
+
+	noSidecarCalls := func(t *testing.T) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("unexpected sidecar request %s", r.URL.Path)
+		}
+	}
+
+	t.Run("remote session gone", func(t *testing.T) {
+		session := newSession("remote-1", time.Minute)
+		service, sessionRepo, taskRepo, k8sService := setup(t, session, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/session/"+session.ID.String(), r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		})
+		k8sService.On("GetPodIP", ctx, "pod", "ns").Return("10.0.0.1", nil)
+		stored := expectFinish(sessionRepo, taskRepo, session)
+
+		err := service.ReconcileSessions(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, model.SessionStatusFailed, stored.Status)
+		assert.Contains(t, stored.Output, sessionGoneMessage)
+		require.NotNil(t, stored.CompletedAt)
+		taskRepo.AssertExpectations(t)
+	})
+
+	t.Run("sidecar reports final status", func(t *testing.T) {
+		session := newSession("remote-1", time.Minute)
+		service, sessionRepo, taskRepo, k8sService := setup(t, session, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"status": "cancelled"}`))
+		})
+		k8sService.On("GetPodIP", ctx, "pod", "ns").Return("10.0.0.1", nil)
+		stored := expectFinish(sessionRepo, taskRepo, session)
+
+		err := service.ReconcileSessions(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, model.SessionStatusCancelled, stored.Status)
+		taskRepo.AssertExpectations(t)
+	})
+
+	t.Run("still running resumes stream from last event", func(t *testing.T) {
+		session := newSession("remote-1", time.Minute)
+		lastEventIDs := make(chan string, 1)
+		service, sessionRepo, taskRepo, k8sService := setup(t, session, func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasSuffix(r.URL.Path, "/events") {
+				lastEventIDs <- r.Header.Get("Last-Event-ID")
+				w.Write([]byte("event: complete\nid: evt-8\ndata: {}\n\n"))
+				return
+			}
+			w.Write([]byte(`{"status": "running"}`))
+		})
+		k8sService.On("GetPodIP", mock.Anything, "pod", "ns").Return("10.0.0.1", nil)
+		stored := *session
+		sessionRepo.On("FindByID", mock.Anything, session.ID).Return(&stored, nil)
+		sessionRepo.On("AppendOutput", mock.Anything, session.ID, session.TaskID, "", "evt-8").Return(nil)
+		sessionRepo.On("Update", mock.Anything, &stored).Return(nil)
+		taskRepo.On("FindByID", mock.Anything, session.TaskID).Return(&model.Task{ID: session.TaskID, Status: model.TaskStatusDone}, nil)
+
+		err := service.ReconcileSessions(ctx)
+
+		require.NoError(t, err)
+		select {
+		case lastEventID := <-lastEventIDs:
+			assert.Equal(t, "evt-7", lastEventID)
+		case <-time.After(5 * time.Second):
+			t.Fatal("session stream was not resumed")
+		}
+		assert.Eventually(t, func() bool {
+			_, following := service.followers.Load(session.ID)
+			return !following
+		}, 5*time.Second, 10*time.Millisecond)
+		sessionRepo.AssertCalled(t, "Update", mock.Anything, &stored)
+	})
+
+	t.Run("session already followed", func(t *testing.T) {
+		session := newSession("remote-1", time.Hour)
+		service, _, _, k8sService := setup(t, session, noSidecarCalls(t))
+		service.followers.Store(session.ID, struct{}{})
+
+		err := service.ReconcileSessions(ctx)
+
+		require.NoError(t, err)
+		k8sService.AssertNotCalled(t, "GetPodIP", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("session still starting", func(t *testing.T) {
+		session := newSession("", time.Minute)
+		service, sessionRepo, _, _ := setup(t, session, noSidecarCalls(t))
+
+		err := service.ReconcileSessions(ctx)
+
+		require.NoError(t, err)
+		sessionRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("session never started", func(t *testing.T) {
+		session := newSession("", time.Hour)
+		service, sessionRepo, taskRepo, _ := setup(t, session, noSidecarCalls(t))
+		stored := expectFinish(sessionRepo, taskRepo, session)
+
+		err := service.ReconcileSessions(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, model.SessionStatusFailed, stored.Status)
+		assert.Contains(t, stored.Output, "never started")
+	})
+
+	t.Run("pod unavailable within grace period", func(t *testing.T) {
+		session := newSession("remote-1", time.Minute)
+		service, sessionRepo, _, k8sService := setup(t, session, noSidecarCalls(t))
+		k8sService.On("GetPodIP", ctx, "pod", "ns").Return("", errors.New("pod not found"))
+
+		err := service.ReconcileSessions(ctx)
+
+		require.NoError(t, err)
+		sessionRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("pod unavailable after grace period", func(t *testing.T) {
+		session := newSession("remote-1", time.Hour)
+		service, sessionRepo, taskRepo, k8sService := setup(t, session, noSidecarCalls(t))
+		k8sService.On("GetPodIP", ctx, "pod", "ns").Return("", errors.New("pod not found"))
+		stored := expectFinish(sessionRepo, taskRepo, session)
+
+		err := service.ReconcileSessions(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, model.SessionStatusFailed, stored.Status)
+		assert.Contains(t, stored.Output, "OpenCode sidecar unreachable: failed to get pod IP: pod not found")
+	})
+}
+
+func TestSessionReconciler_Run(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sessionService := new(MockSessionService)
+	calls := make(chan struct{}, 10)
+	sessionService.On("ReconcileSessions", ctx).Return(errors.New("database unavailable")).Run(func(mock.Arguments) {
+		calls <- struct{}{}
+	})
+
+	done := make(chan struct{})
+	go func() {
+		NewSessionReconciler(sessionService, 10*time.Millisecond).Run(ctx)
+		close(done)
+	}()
+
+	// Runs at startup and keeps running after errors
+	for i := 0; i < 2; i++ {
+		select {
+		case <-calls:
+		case <-time.After(5 * time.Second):
+			t.Fatal("reconciliation did not run")
+		}
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("reconciler did not stop")
+	}
+}
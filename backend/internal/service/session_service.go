@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,52 +19,113 @@ import (
 )
 
 var (
-	ErrSessionNotFound      = errors.New("session not found")
-	ErrInvalidSessionStatus = errors.New("invalid session status")
-	ErrOpenCodeAPICall      = errors.New("opencode API call failed")
-	ErrSessionAlreadyActive = errors.New("session already active for this task")
+	ErrSessionNotFound        = errors.New("session not found")
+	ErrInvalidSessionStatus   = errors.New("invalid session status")
+	ErrOpenCodeAPICall        = errors.New("opencode API call failed")
+	ErrSessionAlreadyActive   = errors.New("session already active for this task")
+	ErrSessionDiffUnavailable = errors.New("no workspace snapshot recorded for session")
+	ErrSessionNotContinuable  = errors.New("session cannot be continued")
 )
 
+// sessionProxyPort is the port of the session-proxy sidecar. It fronts the OpenCode server and
+// buffers session events on the workspace, so none are lost while the backend is disconnected.
+const sessionProxyPort = 3002
+
+// SessionProxyURL returns the base URL of the session-proxy sidecar of the pod
+func SessionProxyURL(podIP string) string {
+	return fmt.Sprintf("http://%s:%d", podIP, sessionProxyPort)
+}
+
 type SessionService interface {
 	StartSession(ctx context.Context, taskID uuid.UUID, prompt string) (*model.Session, error)
 	StopSession(ctx context.Context, sessionID uuid.UUID) error
+	ContinueSession(ctx context.Context, sessionID, userID uuid.UUID, prompt string) (*model.Session, error)
 	GetSession(ctx context.Context, sessionID uuid.UUID) (*model.Session, error)
 	GetSessionsByTaskID(ctx context.Context, taskID uuid.UUID) ([]model.Session, error)
 	GetActiveProjectSessions(ctx context.Context, projectID uuid.UUID) ([]model.Session, error)
 	GetAllActiveSessions(ctx context.Context) ([]model.Session, error)
 	UpdateSessionOutput(ctx context.Context, sessionID uuid.UUID, output string) error
+	UpdateSessionFinalOutput(ctx context.Context, sessionID uuid.UUID, finalOutput string) error
 	UpdateSessionStatus(ctx context.Context, sessionID uuid.UUID, status string, errorMsg string) error
 	UpdateLastEventID(ctx context.Context, sessionID uuid.UUID, lastEventID string) error
+	GetSessionDiff(ctx context.Context, sessionID uuid.UUID) (*WorkspaceDiff, error)
+	ReconcileSessions(ctx context.Context) error
+	EnforceSessionLimits(ctx context.Context) error
+	PruneSessionSnapshots(ctx context.Context, retention time.Duration) error
 }
 
 type sessionService struct {
-	sessionRepo   repository.SessionRepository
-	taskRepo      repository.TaskRepository
-	projectRepo   repository.ProjectRepository
-	k8sService    KubernetesService
-	configService ConfigServiceInterface
-	sharedSecret  string
-	httpClient    *http.Client
+	sessionRepo        repository.SessionRepository
+	taskRepo           repository.TaskRepository
+	projectRepo        repository.ProjectRepository
+	interactionRepo    repository.InteractionRepository // records the turns of multi-turn sessions
+	interactionService InteractionService               // records the questions agents ask the user
+	budgetService      BudgetService                    // stops sessions once a spending budget is used up; nil disables budgets
+	k8sService         KubernetesService
+	configService      ConfigServiceInterface
+	gitService         WorkspaceGitService
+	sharedSecret       string
+	httpClient         *http.Client
+	streamClient       *http.Client // follows sidecar event streams; nil disables following
+	followers          sync.Map     // session ID -> struct{} for streams being followed
+	sessionLocksMu     sync.Mutex
+	sessionLocks       map[uuid.UUID]*sessionLock // locks of sessions being written; removed once released
+}
+
+// sessionLock serializes writes to one session; refs counts the holder and the waiters
+type sessionLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// sessionOptions customizes how a session is started on the sidecar
+type sessionOptions struct {
+	kind         model.SessionKind
+	systemPrompt *string // overrides the project's system prompt
+	modelName    *string // overrides the project's model
+	readOnly     bool    // disables the agent tools that change the workspace
 }
 
 func NewSessionService(
 	sessionRepo repository.SessionRepository,
 	taskRepo repository.TaskRepository,
 	projectRepo repository.ProjectRepository,
+	interactionRepo repository.InteractionRepository,
+	budgetRepo repository.BudgetRepository,
 	k8sService KubernetesService,
 	configService ConfigServiceInterface,
+	gitService WorkspaceGitService,
 	sharedSecret string,
 ) SessionService {
+	// Sessions act on behalf of the project owner
+	authz := NewAuthorizationService(projectRepo, nil)
+
+	var interactionService InteractionService
+	if interactionRepo != nil {
+		interactionService = NewInteractionService(interactionRepo, taskRepo, authz, sessionRepo)
+	}
+
+	var budgetService BudgetService
+	if budgetRepo != nil {
+		budgetService = NewBudgetService(budgetRepo, sessionRepo, projectRepo, authz)
+	}
+
 	return &sessionService{
-		sessionRepo:   sessionRepo,
-		taskRepo:      taskRepo,
-		projectRepo:   projectRepo,
-		k8sService:    k8sService,
-		configService: configService,
-		sharedSecret:  sharedSecret,
+		sessionRepo:        sessionRepo,
+		taskRepo:           taskRepo,
+		projectRepo:        projectRepo,
+		interactionRepo:    interactionRepo,
+		interactionService: interactionService,
+		budgetService:      budgetService,
+		k8sService:         k8sService,
+		configService:      configService,
+		gitService:         gitService,
+		sharedSecret:       sharedSecret,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		// No timeout: event streams stay open for the whole session
+		streamClient: &http.Client{},
 	}
 }
 
@@ -98,6 +160,17 @@ func (s *sessionService) StartSession(ctx context.Context, taskID uuid.UUID, pro
 		}
 	}
 
+	if s.budgetService != nil {
+		if err := s.budgetService.CheckBudget(ctx, project.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.launchSession(ctx, task, project, prompt, sessionOptions{kind: model.SessionKindExecution})
+}
+
+// launchSession records a new session for the task and starts it on the OpenCode sidecar
+func (s *sessionService) launchSession(ctx context.Context, task *model.Task, project *model.Project, prompt string, opts sessionOptions) (*model.Session, error) {
 	// Get pod IP from Kubernetes
 	podIP, err := s.k8sService.GetPodIP(ctx, project.PodName, project.PodNamespace)
 	if err != nil {
@@ -106,19 +179,30 @@ func (s *sessionService) StartSession(ctx context.Context, taskID uuid.UUID, pro
 
 	// Create session record in database
 	session := &model.Session{
-		TaskID:    taskID,
-		ProjectID: project.ID,
-		Status:    model.SessionStatusPending,
-		Prompt:    prompt,
+		TaskID:     task.ID,
+		ProjectID:  project.ID,
+		Status:     model.SessionStatusPending,
+		Kind:       opts.kind,
+		Prompt:     prompt,
+		BranchName: task.BranchName,
 	}
 
 	if err := s.sessionRepo.Create(ctx, session); err != nil {
 		return nil, fmt.Errorf("failed to create session: %w", err)
 	}
 
+	// Snapshot the workspace so reviewers can see what this session changed. It is taken once the
+	// session exists, so snapshot pruning keeps it. A missing snapshot only disables the diff view,
+	// so it never blocks the session.
+	if s.gitService != nil && opts.kind == model.SessionKindExecution {
+		if snapshot, err := s.gitService.Snapshot(ctx, project, session.ID); err == nil {
+			session.BaseSnapshot = snapshot
+		}
+	}
+
 	// Start OpenCode session on sidecar
 	startedAt := time.Now()
-	remoteSessionID, err := s.callOpenCodeStart(ctx, podIP, session.ID, prompt, project.ID)
+	remoteSessionID, err := s.callOpenCodeStart(ctx, podIP, session, prompt, opts)
 	if err != nil {
 		session.Status = model.SessionStatusFailed
 		session.Error = err.Error()
@@ -129,14 +213,20 @@ func (s *sessionService) StartSession(ctx context.Context, taskID uuid.UUID, pro
 	session.Status = model.SessionStatusRunning
 	session.StartedAt = &startedAt
 	session.RemoteSessionID = remoteSessionID
+	startSessionRun(session, startedAt)
 	if err := s.sessionRepo.Update(ctx, session); err != nil {
 		return nil, fmt.Errorf("failed to update session status: %w", err)
 	}
 
+	s.followSessionStream(session)
+
 	return session, nil
 }
 
 func (s *sessionService) StopSession(ctx context.Context, sessionID uuid.UUID) error {
+	unlock := s.lockSession(sessionID)
+	defer unlock()
+
 	// Get session
 	session, err := s.sessionRepo.FindByID(ctx, sessionID)
 	if err != nil {
@@ -147,7 +237,7 @@ func (s *sessionService) StopSession(ctx context.Context, sessionID uuid.UUID) e
 	}
 
 	// Only stop if session is active
-	if session.Status != model.SessionStatusPending && session.Status != model.SessionStatusRunning {
+	if !isActiveSessionStatus(session.Status) {
 		return fmt.Errorf("%w: cannot stop session with status %s", ErrInvalidSessionStatus, session.Status)
 	}
 
@@ -170,12 +260,10 @@ func (s *sessionService) StopSession(ctx context.Context, sessionID uuid.UUID) e
 
 	// Update session status to cancelled
 	session.Status = model.SessionStatusCancelled
+	s.captureFinalSnapshot(ctx, session)
 	completedAt := time.Now()
 	session.CompletedAt = &completedAt
-
-	if session.StartedAt != nil {
-		session.DurationMs = completedAt.Sub(*session.StartedAt).Milliseconds()
-	}
+	pauseSessionRun(session, completedAt)
 
 	if err := s.sessionRepo.Update(ctx, session); err != nil {
 		return fmt.Errorf("failed to update session: %w", err)
@@ -215,6 +303,9 @@ func (s *sessionService) GetActiveProjectSessions(ctx context.Context, projectID
 }
 
 func (s *sessionService) UpdateSessionOutput(ctx context.Context, sessionID uuid.UUID, output string) error {
+	unlock := s.lockSession(sessionID)
+	defer unlock()
+
 	if err := s.sessionRepo.UpdateOutput(ctx, sessionID, output); err != nil {
 		return fmt.Errorf("failed to update session output: %w", err)
 	}
@@ -222,42 +313,57 @@ func (s *sessionService) UpdateSessionOutput(ctx context.Context, sessionID uuid
 	return nil
 }
 
-// callOpenCodeStart starts a new OpenCode session on the sidecar
-func (s *sessionService) callOpenCodeStart(ctx context.Context, podIP string, sessionID uuid.UUID, prompt string, projectID uuid.UUID) (string, error) {
-	url := fmt.Sprintf("http://%s:3003/sessions", podIP)
+// UpdateSessionFinalOutput records the agent's final response, kept apart from the streamed transcript
+func (s *sessionService) UpdateSessionFinalOutput(ctx context.Context, sessionID uuid.UUID, finalOutput string) error {
+	unlock := s.lockSession(sessionID)
+	defer unlock()
 
-	config, err := s.configService.GetActiveConfig(ctx, projectID)
-	if err != nil {
-		return "", fmt.Errorf("failed to get project config: %w", err)
+	if err := s.sessionRepo.UpdateFinalOutput(ctx, sessionID, finalOutput); err != nil {
+		return fmt.Errorf("failed to update session final output: %w", err)
 	}
 
-	apiKey, err := s.configService.GetDecryptedAPIKey(ctx, projectID)
-	if err != nil {
-		return "", fmt.Errorf("failed to decrypt API key: %w", err)
-	}
+	return nil
+}
 
-	requestBody := map[string]interface{}{
-		"session_id": sessionID.String(),
-		"prompt":     prompt,
-		"model_config": map[string]interface{}{
-			"provider":      config.ModelProvider,
-			"model":         config.ModelName,
-			"api_key":       apiKey,
-			"temperature":   config.Temperature,
-			"max_tokens":    config.MaxTokens,
-			"enabled_tools": config.EnabledTools,
-		},
+// lockSession serializes writes to a session. The stream follower, the sidecar callbacks and
+// user actions all save the session, and a full save must not drop output appended meanwhile.
+// The lock is dropped once nobody holds or waits for it, so finished sessions do not keep one.
+func (s *sessionService) lockSession(sessionID uuid.UUID) func() {
+	s.sessionLocksMu.Lock()
+	if s.sessionLocks == nil {
+		s.sessionLocks = make(map[uuid.UUID]*sessionLock)
+	}
+	lock, ok := s.sessionLocks[sessionID]
+	if !ok {
+		lock = &sessionLock{}
+		s.sessionLocks[sessionID] = lock
+	}
+	lock.refs++
+	s.sessionLocksMu.Unlock()
+
+	lock.mu.Lock()
+	return func() {
+		lock.mu.Unlock()
+
+		s.sessionLocksMu.Lock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(s.sessionLocks, sessionID)
+		}
+		s.sessionLocksMu.Unlock()
 	}
+}
 
-	if config.ModelVersion != nil {
-		requestBody["model_config"].(map[string]interface{})["model_version"] = *config.ModelVersion
-	}
-	if config.APIEndpoint != nil {
-		requestBody["model_config"].(map[string]interface{})["api_endpoint"] = *config.APIEndpoint
-	}
-	if config.SystemPrompt != nil {
-		requestBody["system_prompt"] = *config.SystemPrompt
+// callOpenCodeStart starts a new OpenCode session on the sidecar
+func (s *sessionService) callOpenCodeStart(ctx context.Context, podIP string, session *model.Session, prompt string, opts sessionOptions) (string, error) {
+	url := SessionProxyURL(podIP) + "/session"
+
+	requestBody, err := s.sidecarSessionConfig(ctx, session, opts)
+	if err != nil {
+		return "", err
 	}
+	requestBody["session_id"] = session.ID.String()
+	requestBody["prompt"] = prompt
 
 	jsonBody, err := json.Marshal(requestBody)
 	if err != nil {
@@ -300,11 +406,74 @@ func (s *sessionService) callOpenCodeStart(ctx context.Context, podIP string, se
 	return response.RemoteSessionID, nil
 }
 
+// sidecarSessionConfig builds the model configuration, system prompt and limits the sidecar runs a session with.
+// A session without limits takes the project's; the caller saves them with the session.
+func (s *sessionService) sidecarSessionConfig(ctx context.Context, session *model.Session, opts sessionOptions) (map[string]interface{}, error) {
+	config, err := s.configService.GetActiveConfig(ctx, session.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project config: %w", err)
+	}
+
+	apiKey, err := s.configService.GetDecryptedAPIKey(ctx, session.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt API key: %w", err)
+	}
+
+	modelName := config.ModelName
+	if opts.modelName != nil && *opts.modelName != "" {
+		modelName = *opts.modelName
+	}
+
+	requestBody := map[string]interface{}{
+		"model_config": map[string]interface{}{
+			"provider":      config.ModelProvider,
+			"model":         modelName,
+			"api_key":       apiKey,
+			"temperature":   config.Temperature,
+			"max_tokens":    config.MaxTokens,
+			"enabled_tools": config.EnabledTools,
+		},
+	}
+
+	if config.ModelVersion != nil {
+		requestBody["model_config"].(map[string]interface{})["model_version"] = *config.ModelVersion
+	}
+	if config.APIEndpoint != nil {
+		requestBody["model_config"].(map[string]interface{})["api_endpoint"] = *config.APIEndpoint
+	}
+	if opts.systemPrompt != nil {
+		requestBody["system_prompt"] = *opts.systemPrompt
+	} else if config.SystemPrompt != nil {
+		requestBody["system_prompt"] = *config.SystemPrompt
+	}
+	if opts.readOnly {
+		requestBody["read_only"] = true
+	}
+
+	// Usage reported without a model is priced as this one; the caller saves it with the session
+	session.ModelProvider = config.ModelProvider
+	session.ModelName = modelName
+
+	// Continued sessions keep the limits they started with
+	if session.TimeoutSeconds == 0 && session.MaxIterations == 0 {
+		session.TimeoutSeconds = config.TimeoutSeconds
+		session.MaxIterations = config.MaxIterations
+	}
+	if session.TimeoutSeconds > 0 {
+		requestBody["timeout_seconds"] = session.TimeoutSeconds
+	}
+	if session.MaxIterations > 0 {
+		requestBody["max_iterations"] = session.MaxIterations
+	}
+
+	return requestBody, nil
+}
+
 // callOpenCodeStop stops an active OpenCode session on the sidecar
 func (s *sessionService) callOpenCodeStop(ctx context.Context, podIP string, sessionID uuid.UUID) error {
-	url := fmt.Sprintf("http://%s:3003/sessions/%s/stop", podIP, sessionID.String())
+	url := fmt.Sprintf("%s/session/%s", SessionProxyURL(podIP), sessionID.String())
 
-	req, err := http.NewRequestWithContext(ctx, "POST", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
@@ -332,26 +501,207 @@ func (s *sessionService) GetAllActiveSessions(ctx context.Context) ([]model.Sess
 }
 
 func (s *sessionService) UpdateSessionStatus(ctx context.Context, sessionID uuid.UUID, status string, errorMsg string) error {
+	return s.updateSessionStatus(ctx, sessionID, status, errorMsg, false)
+}
+
+// updateSessionStatus records a new session status. With keepFinal set, sessions that already
+// reached a final status are left untouched.
+func (s *sessionService) updateSessionStatus(ctx context.Context, sessionID uuid.UUID, status string, errorMsg string, keepFinal bool) error {
+	_, err := s.transitionSession(ctx, sessionID, status, errorMsg, keepFinal)
+	return err
+}
+
+// transitionSession is updateSessionStatus, also reporting whether the status was recorded
+func (s *sessionService) transitionSession(ctx context.Context, sessionID uuid.UUID, status string, errorMsg string, keepFinal bool) (bool, error) {
+	unlock := s.lockSession(sessionID)
+	defer unlock()
+
 	session, err := s.sessionRepo.FindByID(ctx, sessionID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ErrSessionNotFound
+			return false, ErrSessionNotFound
 		}
-		return fmt.Errorf("failed to get session: %w", err)
+		return false, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	// Stage hand-off only happens once, when the session first reaches a final status
+	wasTerminal := isTerminalSessionStatus(session.Status)
+	if wasTerminal && keepFinal {
+		return false, nil
 	}
 
 	session.Status = model.SessionStatus(status)
 	if errorMsg != "" {
 		session.Output += fmt.Sprintf("\nError: %s\n", errorMsg)
+		if session.Status == model.SessionStatusFailed {
+			session.Error = errorMsg
+		}
+	}
+
+	if isTerminalSessionStatus(session.Status) {
+		s.captureFinalSnapshot(ctx, session)
+
+		if !wasTerminal {
+			completedAt := time.Now()
+			session.CompletedAt = &completedAt
+			pauseSessionRun(session, completedAt)
+		}
+	}
+
+	if session.Status == model.SessionStatusCompleted && session.Kind != model.SessionKindReview {
+		if err := s.commitSessionChanges(ctx, session); err != nil {
+			// The session itself succeeded; keep the commit failure visible without failing the update
+			session.Error = fmt.Sprintf("automatic commit failed: %v", err)
+		}
 	}
 
 	if err := s.sessionRepo.Update(ctx, session); err != nil {
-		return fmt.Errorf("failed to update session: %w", err)
+		return false, fmt.Errorf("failed to update session: %w", err)
+	}
+
+	// Hand the task to the next stage once the session has been recorded as finished
+	switch {
+	case wasTerminal:
+	case session.Kind == model.SessionKindReview && isTerminalSessionStatus(session.Status):
+		if err := s.applyAIReviewVerdict(ctx, session); err != nil {
+			return true, fmt.Errorf("failed to apply AI review verdict: %w", err)
+		}
+	case session.Status == model.SessionStatusCompleted:
+		if err := s.startAIReview(ctx, session); err != nil {
+			return true, fmt.Errorf("failed to start AI review: %w", err)
+		}
+	case isTerminalSessionStatus(session.Status):
+		if err := s.returnTaskToTodo(ctx, session); err != nil {
+			return true, fmt.Errorf("failed to return task to todo: %w", err)
+		}
+	}
+
+	if !wasTerminal && session.Status == model.SessionStatusCompleted {
+		return true, s.recordAgentTurn(ctx, session, session.FinalOutput)
+	}
+
+	return true, nil
+}
+
+// returnTaskToTodo puts a task whose execution session ended without completing back in TODO,
+// as stopping it would, so that it can be run again
+func (s *sessionService) returnTaskToTodo(ctx context.Context, session *model.Session) error {
+	task, err := s.taskRepo.FindByID(ctx, session.TaskID)
+	if err != nil {
+		return fmt.Errorf("failed to get task: %w", err)
+	}
+	if task.Status != model.TaskStatusInProgress {
+		return nil
+	}
+
+	task.Status = model.TaskStatusTodo
+	return s.taskRepo.UpdateStatus(ctx, task.ID, task.Status)
+}
+
+// isActiveSessionStatus reports whether the session is underway, including paused on a question
+func isActiveSessionStatus(status model.SessionStatus) bool {
+	switch status {
+	case model.SessionStatusPending, model.SessionStatusRunning, model.SessionStatusWaitingForInput:
+		return true
+	}
+	return false
+}
+
+func isTerminalSessionStatus(status model.SessionStatus) bool {
+	switch status {
+	case model.SessionStatusCompleted, model.SessionStatusFailed, model.SessionStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// commitSessionChanges commits everything the agent changed on the session's task branch
+// and records the resulting SHA on both the session and the task
+func (s *sessionService) commitSessionChanges(ctx context.Context, session *model.Session) error {
+	if s.gitService == nil || session.BranchName == "" || session.CommitSHA != "" {
+		return nil
+	}
+
+	task, err := s.taskRepo.FindByID(ctx, session.TaskID)
+	if err != nil {
+		return fmt.Errorf("failed to get task: %w", err)
+	}
+
+	project, err := s.projectRepo.FindByID(ctx, session.ProjectID)
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+
+	sha, err := s.gitService.CommitAll(ctx, project, session.BranchName, taskCommitMessage(task, session))
+	if err != nil {
+		if errors.Is(err, ErrNoChangesToCommit) {
+			return nil
+		}
+		return err
+	}
+
+	session.CommitSHA = sha
+	task.CommitSHA = sha
+	if err := s.taskRepo.Update(ctx, task); err != nil {
+		return fmt.Errorf("failed to record commit on task: %w", err)
 	}
 
 	return nil
 }
 
+// captureFinalSnapshot records the workspace content once the session has finished.
+// Without it the session diff keeps following the live workspace.
+func (s *sessionService) captureFinalSnapshot(ctx context.Context, session *model.Session) {
+	if s.gitService == nil || session.BaseSnapshot == "" || session.FinalSnapshot != "" {
+		return
+	}
+
+	project, err := s.projectRepo.FindByID(ctx, session.ProjectID)
+	if err != nil {
+		return
+	}
+
+	if snapshot, err := s.gitService.Snapshot(ctx, project, session.ID); err == nil {
+		session.FinalSnapshot = snapshot
+	}
+}
+
+// GetSessionDiff returns the workspace changes made during a session.
+// Sessions that are still running are compared against the live workspace.
+func (s *sessionService) GetSessionDiff(ctx context.Context, sessionID uuid.UUID) (*WorkspaceDiff, error) {
+	session, err := s.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	if s.gitService == nil || session.BaseSnapshot == "" {
+		return nil, ErrSessionDiffUnavailable
+	}
+
+	project, err := s.projectRepo.FindByID(ctx, session.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	diff, err := s.gitService.DiffSnapshots(ctx, project, session.BaseSnapshot, session.FinalSnapshot)
+	if err != nil {
+		if errors.Is(err, ErrInvalidSnapshot) {
+			return nil, ErrSessionDiffUnavailable
+		}
+		return nil, fmt.Errorf("failed to diff session snapshots: %w", err)
+	}
+
+	return diff, nil
+}
+
+// taskCommitMessage derives the automatic commit message from the task title
+func taskCommitMessage(task *model.Task, session *model.Session) string {
+	return fmt.Sprintf("%s\n\nTask: %s\nSession: %s", task.Title, task.ID, session.ID)
+}
+
 func (s *sessionService) UpdateLastEventID(ctx context.Context, sessionID uuid.UUID, lastEventID string) error {
 	if err := s.sessionRepo.UpdateLastEventID(ctx, sessionID, lastEventID); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
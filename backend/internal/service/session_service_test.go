@@ -15,6 +15,7 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/npinot/vibe/backend/internal/model"
+	"github.com/npinot/vibe/backend/internal/repository"
 )
 
 // MockSessionRepository - local mock for session repository
@@ -60,11 +61,76 @@ func (m *MockSessionRepository) UpdateOutput(ctx context.Context, id uuid.UUID,
 	return args.Error(0)
 }
 
+func (m *MockSessionRepository) AppendOutput(ctx context.Context, id uuid.UUID, taskID uuid.UUID, chunk string, lastEventID string) error {
+	args := m.Called(ctx, id, taskID, chunk, lastEventID)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) UpdateFinalOutput(ctx context.Context, id uuid.UUID, finalOutput string) error {
+	args := m.Called(ctx, id, finalOutput)
+	return args.Error(0)
+}
+
 func (m *MockSessionRepository) SoftDelete(ctx context.Context, id uuid.UUID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
+func (m *MockSessionRepository) FindAllActiveSessions(ctx context.Context) ([]model.Session, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) FindWithSnapshots(ctx context.Context, projectID uuid.UUID, since time.Time) ([]model.Session, error) {
+	args := m.Called(ctx, projectID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) UpdateLastEventID(ctx context.Context, id uuid.UUID, lastEventID string) error {
+	args := m.Called(ctx, id, lastEventID)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) IncrementIterations(ctx context.Context, id uuid.UUID) (int, error) {
+	args := m.Called(ctx, id)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockSessionRepository) AddUsage(ctx context.Context, id uuid.UUID, usage repository.SessionUsage) error {
+	args := m.Called(ctx, id, usage)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) GetProjectUsage(ctx context.Context, projectID uuid.UUID) (*model.ProjectUsage, error) {
+	args := m.Called(ctx, projectID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ProjectUsage), args.Error(1)
+}
+
+func (m *MockSessionRepository) SumProjectUsageSince(ctx context.Context, projectID uuid.UUID, since time.Time) (*model.UsageTotals, error) {
+	args := m.Called(ctx, projectID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.UsageTotals), args.Error(1)
+}
+
+func (m *MockSessionRepository) SumUserUsageSince(ctx context.Context, userID uuid.UUID, since time.Time) (*model.UsageTotals, error) {
+	args := m.Called(ctx, userID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.UsageTotals), args.Error(1)
+}
+
 type MockConfigService struct {
 	mock.Mock
 }
@@ -97,6 +163,11 @@ func (m *MockConfigService) GetConfigHistory(ctx context.Context, projectID uuid
 	return args.Get(0).([]model.OpenCodeConfig), args.Error(1)
 }
 
+func (m *MockConfigService) CopyActiveConfig(ctx context.Context, sourceProjectID, targetProjectID uuid.UUID, withAPIKey bool) error {
+	args := m.Called(ctx, sourceProjectID, targetProjectID, withAPIKey)
+	return args.Error(0)
+}
+
 func setupSessionServiceTest() (*sessionService, *MockSessionRepository) {
 	sessionRepo := new(MockSessionRepository)
 	taskRepo := new(MockTaskRepository)
@@ -214,11 +285,12 @@ func TestSessionService_StopSession(t *testing.T) {
 	startedAt := time.Now().Add(-5 * time.Minute)
 
 	session := &model.Session{
-		ID:        sessionID,
-		TaskID:    uuid.New(),
-		ProjectID: projectID,
-		Status:    model.SessionStatusRunning,
-		StartedAt: &startedAt,
+		ID:           sessionID,
+		TaskID:       uuid.New(),
+		ProjectID:    projectID,
+		Status:       model.SessionStatusRunning,
+		StartedAt:    &startedAt,
+		RunStartedAt: &startedAt,
 	}
 
 	project := &model.Project{
@@ -228,8 +300,8 @@ func TestSessionService_StopSession(t *testing.T) {
 	}
 
 	mockAPIServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		assert.Equal(t, "POST", r.Method)
-		assert.Contains(t, r.URL.Path, "/sessions/"+sessionID.String()+"/stop")
+		assert.Equal(t, "DELETE", r.Method)
+		assert.Contains(t, r.URL.Path, "/session/"+sessionID.String())
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Write([]byte("OpenCode API error"))
 	}))
@@ -354,6 +426,69 @@ func TestSessionService_StartSession_AlreadyActive(t *testing.T) {
 	sessionRepo.AssertExpectations(t)
 }
 
+func TestSessionService_StartSession_BudgetExceeded(t *testing.T) {
+	service, sessionRepo := setupSessionServiceTest()
+	ctx := context.Background()
+	budgetRepo := new(MockBudgetRepository)
+	service.budgetService = NewBudgetService(budgetRepo, sessionRepo, service.projectRepo, NewAuthorizationService(service.projectRepo, nil))
+
+	taskID := uuid.New()
+	projectID := uuid.New()
+	ownerID := uuid.New()
+
+	task := &model.Task{ID: taskID, ProjectID: projectID}
+	project := &model.Project{ID: projectID, UserID: ownerID, PodName: "test-pod", PodNamespace: "opencode"}
+
+	service.taskRepo.(*MockTaskRepository).On("FindByID", ctx, taskID).Return(task, nil)
+	service.projectRepo.(*MockProjectRepository).On("FindByID", ctx, projectID).Return(project, nil)
+	sessionRepo.On("FindActiveSessionsForProject", ctx, projectID).Return([]model.Session{}, nil)
+	budgetRepo.On("FindByScope", ctx, model.BudgetScopeProject, projectID).Return(nil, gorm.ErrRecordNotFound)
+	budgetRepo.On("FindByScope", ctx, model.BudgetScopeUser, ownerID).
+		Return(&model.Budget{Scope: model.BudgetScopeUser, ScopeID: ownerID, MonthlyCostUSD: floatPtr(100), WarnPercent: 80}, nil)
+	sessionRepo.On("SumProjectUsageSince", ctx, projectID, mock.Anything).Return(&model.UsageTotals{CostUSD: 20}, nil)
+	sessionRepo.On("SumUserUsageSince", ctx, ownerID, mock.Anything).Return(&model.UsageTotals{CostUSD: 100.25}, nil)
+
+	_, err := service.StartSession(ctx, taskID, "Test prompt")
+	assert.ErrorIs(t, err, ErrBudgetExceeded)
+
+	// The session is refused before anything is created
+	sessionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	service.k8sService.(*MockKubernetesService).AssertNotCalled(t, "GetPodIP", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSessionService_lockSession(t *testing.T) {
+	service := &sessionService{}
+	sessionID := uuid.New()
+
+	unlock := service.lockSession(sessionID)
+
+	acquired := make(chan struct{})
+	go func() {
+		release := service.lockSession(sessionID)
+		close(acquired)
+		release()
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("Expected second writer to wait for the lock")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unlock()
+	select {
+	case <-acquired:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Second writer never got the lock")
+	}
+
+	assert.Eventually(t, func() bool {
+		service.sessionLocksMu.Lock()
+		defer service.sessionLocksMu.Unlock()
+		return len(service.sessionLocks) == 0
+	}, 5*time.Second, 10*time.Millisecond, "released locks should be dropped")
+}
+
 func TestSessionService_callOpenCodeStart_ErrorHandling(t *testing.T) {
 	errorServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -379,10 +514,29 @@ func TestSessionService_callOpenCodeStart_ErrorHandling(t *testing.T) {
 	}
 
 	projectID := uuid.New()
-	err := service.callOpenCodeStart(context.Background(), serverURL, uuid.New(), "test", projectID)
+	_, err := service.callOpenCodeStart(context.Background(), serverURL, &model.Session{ID: uuid.New(), ProjectID: projectID}, "test", sessionOptions{kind: model.SessionKindExecution})
 	assert.Error(t, err)
 }
 
+func TestSessionService_sidecarSessionConfig_ReadOnly(t *testing.T) {
+	mockConfigService := &MockConfigService{}
+	mockConfigService.On("GetActiveConfig", mock.Anything, mock.Anything).Return(&model.OpenCodeConfig{
+		ModelProvider: "openai",
+		ModelName:     "gpt-4",
+	}, nil)
+	mockConfigService.On("GetDecryptedAPIKey", mock.Anything, mock.Anything).Return("test-api-key", nil)
+
+	service := &sessionService{configService: mockConfigService}
+
+	body, err := service.sidecarSessionConfig(context.Background(), &model.Session{ID: uuid.New()}, sessionOptions{kind: model.SessionKindReview, readOnly: true})
+	require.NoError(t, err)
+	assert.Equal(t, true, body["read_only"])
+
+	body, err = service.sidecarSessionConfig(context.Background(), &model.Session{ID: uuid.New()}, sessionOptions{kind: model.SessionKindExecution})
+	require.NoError(t, err)
+	assert.NotContains(t, body, "read_only")
+}
+
 func TestSessionService_callOpenCodeStop_ErrorHandling(t *testing.T) {
 	errorServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -402,3 +556,217 @@ func TestSessionService_callOpenCodeStop_ErrorHandling(t *testing.T) {
 	err := service.callOpenCodeStop(context.Background(), serverURL, uuid.New())
 	assert.Error(t, err)
 }
+
+func TestSessionService_UpdateSessionStatus_AutoCommit(t *testing.T) {
+	ctx := context.Background()
+	taskID := uuid.New()
+	projectID := uuid.New()
+
+	setup := func() (*sessionService, *MockSessionRepository, *MockTaskRepository, *MockWorkspaceGitService, *model.Session, *model.Task, *model.Project) {
+		sessionRepo := new(MockSessionRepository)
+		taskRepo := new(MockTaskRepository)
+		projectRepo := new(MockProjectRepository)
+		gitService := new(MockWorkspaceGitService)
+
+		session := &model.Session{
+			ID:         uuid.New(),
+			TaskID:     taskID,
+			ProjectID:  projectID,
+			Status:     model.SessionStatusRunning,
+			BranchName: "task/1234abcd-fix-login-bug",
+		}
+		task := &model.Task{ID: taskID, ProjectID: projectID, Title: "Fix login bug"}
+		project := &model.Project{ID: projectID}
+
+		sessionRepo.On("FindByID", ctx, session.ID).Return(session, nil)
+		taskRepo.On("FindByID", ctx, taskID).Return(task, nil)
+		projectRepo.On("FindByID", ctx, projectID).Return(project, nil)
+
+		service := &sessionService{
+			sessionRepo: sessionRepo,
+			taskRepo:    taskRepo,
+			projectRepo: projectRepo,
+			gitService:  gitService,
+			httpClient:  &http.Client{},
+		}
+
+		return service, sessionRepo, taskRepo, gitService, session, task, project
+	}
+
+	t.Run("commits changes and records SHA", func(t *testing.T) {
+		service, sessionRepo, taskRepo, gitService, session, task, project := setup()
+
+		gitService.On("CommitAll", ctx, project, session.BranchName, mock.MatchedBy(func(msg string) bool {
+			return strings.HasPrefix(msg, "Fix login bug\n")
+		})).Return("abc123", nil)
+		taskRepo.On("Update", ctx, task).Return(nil)
+		sessionRepo.On("Update", ctx, session).Return(nil)
+
+		err := service.UpdateSessionStatus(ctx, session.ID, "completed", "")
+
+		require.NoError(t, err)
+		assert.Equal(t, model.SessionStatusCompleted, session.Status)
+		assert.Equal(t, "abc123", session.CommitSHA)
+		assert.Equal(t, "abc123", task.CommitSHA)
+		gitService.AssertExpectations(t)
+	})
+
+	t.Run("nothing to commit", func(t *testing.T) {
+		service, sessionRepo, taskRepo, gitService, session, _, _ := setup()
+
+		gitService.On("CommitAll", ctx, mock.Anything, mock.Anything, mock.Anything).Return("", ErrNoChangesToCommit)
+		sessionRepo.On("Update", ctx, session).Return(nil)
+
+		err := service.UpdateSessionStatus(ctx, session.ID, "completed", "")
+
+		require.NoError(t, err)
+		assert.Empty(t, session.CommitSHA)
+		assert.Empty(t, session.Error)
+		taskRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("commit failure recorded on session", func(t *testing.T) {
+		service, sessionRepo, _, gitService, session, _, _ := setup()
+
+		gitService.On("CommitAll", ctx, mock.Anything, mock.Anything, mock.Anything).Return("", ErrWorkspaceBranchChanged)
+		sessionRepo.On("Update", ctx, session).Return(nil)
+
+		err := service.UpdateSessionStatus(ctx, session.ID, "completed", "")
+
+		require.NoError(t, err)
+		assert.Equal(t, model.SessionStatusCompleted, session.Status)
+		assert.Contains(t, session.Error, "automatic commit failed")
+	})
+
+	t.Run("failed sessions are not committed", func(t *testing.T) {
+		service, sessionRepo, _, gitService, session, _, _ := setup()
+
+		sessionRepo.On("Update", ctx, session).Return(nil)
+
+		err := service.UpdateSessionStatus(ctx, session.ID, "failed", "boom")
+
+		require.NoError(t, err)
+		gitService.AssertNotCalled(t, "CommitAll", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestSessionService_GetSessionDiff(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+	project := &model.Project{ID: projectID}
+
+	setup := func(session *model.Session) (*sessionService, *MockWorkspaceGitService) {
+		sessionRepo := new(MockSessionRepository)
+		projectRepo := new(MockProjectRepository)
+		gitService := new(MockWorkspaceGitService)
+
+		sessionRepo.On("FindByID", ctx, session.ID).Return(session, nil)
+		projectRepo.On("FindByID", ctx, projectID).Return(project, nil)
+
+		return &sessionService{
+			sessionRepo: sessionRepo,
+			projectRepo: projectRepo,
+			gitService:  gitService,
+		}, gitService
+	}
+
+	t.Run("finished session", func(t *testing.T) {
+		session := &model.Session{ID: uuid.New(), ProjectID: projectID, BaseSnapshot: "base", FinalSnapshot: "final"}
+		service, gitService := setup(session)
+
+		expected := &WorkspaceDiff{FilesChanged: 2}
+		gitService.On("DiffSnapshots", ctx, project, "base", "final").Return(expected, nil)
+
+		diff, err := service.GetSessionDiff(ctx, session.ID)
+		require.NoError(t, err)
+		assert.Equal(t, expected, diff)
+	})
+
+	t.Run("running session compares against live workspace", func(t *testing.T) {
+		session := &model.Session{ID: uuid.New(), ProjectID: projectID, BaseSnapshot: "base"}
+		service, gitService := setup(session)
+
+		gitService.On("DiffSnapshots", ctx, project, "base", "").Return(&WorkspaceDiff{}, nil)
+
+		_, err := service.GetSessionDiff(ctx, session.ID)
+		require.NoError(t, err)
+		gitService.AssertExpectations(t)
+	})
+
+	t.Run("no base snapshot", func(t *testing.T) {
+		session := &model.Session{ID: uuid.New(), ProjectID: projectID}
+		service, _ := setup(session)
+
+		_, err := service.GetSessionDiff(ctx, session.ID)
+		assert.ErrorIs(t, err, ErrSessionDiffUnavailable)
+	})
+}
+
+func TestSessionService_UpdateSessionStatus_FinalSnapshot(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+	project := &model.Project{ID: projectID}
+
+	sessionRepo := new(MockSessionRepository)
+	taskRepo := new(MockTaskRepository)
+	projectRepo := new(MockProjectRepository)
+	gitService := new(MockWorkspaceGitService)
+
+	session := &model.Session{ID: uuid.New(), TaskID: uuid.New(), ProjectID: projectID, Status: model.SessionStatusRunning, BaseSnapshot: "base"}
+	sessionRepo.On("FindByID", ctx, session.ID).Return(session, nil)
+	sessionRepo.On("Update", ctx, session).Return(nil)
+	taskRepo.On("FindByID", ctx, session.TaskID).Return(&model.Task{ID: session.TaskID, Status: model.TaskStatusTodo}, nil)
+	projectRepo.On("FindByID", ctx, projectID).Return(project, nil)
+	gitService.On("Snapshot", ctx, project, session.ID).Return("final", nil).Once()
+
+	service := &sessionService{
+		sessionRepo: sessionRepo,
+		taskRepo:    taskRepo,
+		projectRepo: projectRepo,
+		gitService:  gitService,
+	}
+
+	err := service.UpdateSessionStatus(ctx, session.ID, "failed", "boom")
+	require.NoError(t, err)
+	assert.Equal(t, "final", session.FinalSnapshot)
+
+	// A later status update must not move the final snapshot
+	err = service.UpdateSessionStatus(ctx, session.ID, "failed", "")
+	require.NoError(t, err)
+	gitService.AssertNumberOfCalls(t, "Snapshot", 1)
+}
+
+func TestSessionService_UpdateSessionStatus_ReturnsTaskToTodo(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(taskStatus model.TaskStatus) (*sessionService, *MockTaskRepository, *model.Session) {
+		sessionRepo := new(MockSessionRepository)
+		taskRepo := new(MockTaskRepository)
+
+		session := &model.Session{ID: uuid.New(), TaskID: uuid.New(), Status: model.SessionStatusRunning, Kind: model.SessionKindExecution}
+		sessionRepo.On("FindByID", ctx, session.ID).Return(session, nil)
+		sessionRepo.On("Update", ctx, session).Return(nil)
+		taskRepo.On("FindByID", ctx, session.TaskID).Return(&model.Task{ID: session.TaskID, Status: taskStatus}, nil)
+
+		return &sessionService{sessionRepo: sessionRepo, taskRepo: taskRepo}, taskRepo, session
+	}
+
+	t.Run("failed execution session", func(t *testing.T) {
+		service, taskRepo, session := setup(model.TaskStatusInProgress)
+		taskRepo.On("UpdateStatus", ctx, session.TaskID, model.TaskStatusTodo).Return(nil)
+
+		err := service.UpdateSessionStatus(ctx, session.ID, "failed", "sidecar crashed")
+
+		require.NoError(t, err)
+		taskRepo.AssertExpectations(t)
+	})
+
+	t.Run("task moved on by hand", func(t *testing.T) {
+		service, taskRepo, session := setup(model.TaskStatusDone)
+
+		err := service.UpdateSessionStatus(ctx, session.ID, "cancelled", "")
+
+		require.NoError(t, err)
+		taskRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/npinot/vibe/backend/internal/model"
+)
+
+// SessionSnapshotPruner drops the workspace snapshots of sessions that were deleted or are older than the
+// retention period, so the snapshot repositories of workspaces stop growing
+type SessionSnapshotPruner struct {
+	sessionService SessionService
+	retention      time.Duration
+	interval       time.Duration
+}
+
+func NewSessionSnapshotPruner(sessionService SessionService, retention, interval time.Duration) *SessionSnapshotPruner {
+	return &SessionSnapshotPruner{
+		sessionService: sessionService,
+		retention:      retention,
+		interval:       interval,
+	}
+}
+
+// Run prunes snapshots on every interval until ctx is cancelled
+func (p *SessionSnapshotPruner) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := p.sessionService.PruneSessionSnapshots(ctx, p.retention); err != nil {
+			log.Printf("[SessionSnapshotPruner] %v", err)
+		}
+	}
+}
+
+// PruneSessionSnapshots keeps, in the workspace of every running project, the snapshots of the sessions
+// created within retention or still active, and drops the others. Workspaces without a pod are pruned
+// once they run again.
+func (s *sessionService) PruneSessionSnapshots(ctx context.Context, retention time.Duration) error {
+	if s.gitService == nil {
+		return nil
+	}
+
+	projects, err := s.projectRepo.FindRunningProjects(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get running projects: %w", err)
+	}
+
+	since := time.Now().Add(-retention)
+	var errs []error
+	for i := range projects {
+		if err := s.pruneProjectSnapshots(ctx, &projects[i], since); err != nil {
+			errs = append(errs, fmt.Errorf("failed to prune snapshots of project %s: %w", projects[i].ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *sessionService) pruneProjectSnapshots(ctx context.Context, project *model.Project, since time.Time) error {
+	sessions, err := s.sessionRepo.FindWithSnapshots(ctx, project.ID, since)
+	if err != nil {
+		return err
+	}
+
+	// Listing the stored snapshots also keeps the ones taken before the sidecar kept them by session
+	keep := make(map[uuid.UUID][]string, len(sessions))
+	for _, session := range sessions {
+		ids := []string{}
+		for _, id := range []string{session.BaseSnapshot, session.FinalSnapshot} {
+			if id != "" {
+				ids = append(ids, id)
+			}
+		}
+		keep[session.ID] = ids
+	}
+
+	return s.gitService.PruneSnapshots(ctx, project, keep)
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/npinot/vibe/backend/internal/model"
+)
+
+func TestSessionService_launchSession_BaseSnapshot(t *testing.T) {
+	service, sessionRepo := setupSessionServiceTest()
+	gitService := new(MockWorkspaceGitService)
+	service.gitService = gitService
+	ctx := context.Background()
+
+	project := &model.Project{ID: uuid.New(), PodName: "test-pod", PodNamespace: "opencode"}
+	task := &model.Task{ID: uuid.New(), ProjectID: project.ID}
+
+	service.k8sService.(*MockKubernetesService).On("GetPodIP", ctx, "test-pod", "opencode").Return("127.0.0.1", nil)
+	service.configService.(*MockConfigService).On("GetActiveConfig", ctx, project.ID).Return(nil, errors.New("no config"))
+	sessionRepo.On("Create", ctx, mock.AnythingOfType("*model.Session")).
+		Run(func(args mock.Arguments) { args.Get(1).(*model.Session).ID = uuid.New() }).
+		Return(nil)
+	// The snapshot is taken for the stored session, so pruning never drops it before the session is saved
+	gitService.On("Snapshot", ctx, project, mock.AnythingOfType("uuid.UUID")).
+		Run(func(args mock.Arguments) { assert.NotEqual(t, uuid.Nil, args.Get(2)) }).
+		Return("base", nil)
+	sessionRepo.On("Update", ctx, mock.AnythingOfType("*model.Session")).Return(nil)
+
+	_, err := service.launchSession(ctx, task, project, "Add a handler", sessionOptions{kind: model.SessionKindExecution})
+	require.ErrorIs(t, err, ErrOpenCodeAPICall)
+
+	saved := sessionRepo.Calls[len(sessionRepo.Calls)-1].Arguments.Get(1).(*model.Session)
+	assert.Equal(t, "base", saved.BaseSnapshot)
+	gitService.AssertCalled(t, "Snapshot", ctx, project, saved.ID)
+}
+
+func TestSessionService_PruneSessionSnapshots(t *testing.T) {
+	service, sessionRepo := setupSessionServiceTest()
+	gitService := new(MockWorkspaceGitService)
+	service.gitService = gitService
+	projectRepo := service.projectRepo.(*MockProjectRepository)
+	ctx := context.Background()
+
+	running := model.Project{ID: uuid.New()}
+	failing := model.Project{ID: uuid.New()}
+	completed := model.Session{ID: uuid.New(), BaseSnapshot: "base-1", FinalSnapshot: "final-1"}
+	starting := model.Session{ID: uuid.New()}
+
+	projectRepo.On("FindRunningProjects", ctx).Return([]model.Project{running, failing}, nil)
+	sessionRepo.On("FindWithSnapshots", ctx, running.ID, mock.AnythingOfType("time.Time")).
+		Run(func(args mock.Arguments) {
+			assert.WithinDuration(t, time.Now().Add(-24*time.Hour), args.Get(2).(time.Time), time.Minute)
+		}).
+		Return([]model.Session{completed, starting}, nil)
+	sessionRepo.On("FindWithSnapshots", ctx, failing.ID, mock.AnythingOfType("time.Time")).Return(nil, errors.New("database unavailable"))
+	gitService.On("PruneSnapshots", ctx, &running, map[uuid.UUID][]string{
+		completed.ID: {"base-1", "final-1"},
+		starting.ID:  {},
+	}).Return(nil)
+
+	err := service.PruneSessionSnapshots(ctx, 24*time.Hour)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), failing.ID.String())
+	gitService.AssertExpectations(t)
+	gitService.AssertNumberOfCalls(t, "PruneSnapshots", 1)
+}
@@ -0,0 +1,414 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/npinot/vibe/backend/internal/model"
+	"github.com/npinot/vibe/backend/internal/repository"
+)
+
+const (
+	// streamRetryDelay is the pause before a dropped sidecar stream is resumed
+	streamRetryDelay = 2 * time.Second
+
+	// maxStreamRetries bounds consecutive resume attempts that make no progress
+	maxStreamRetries = 5
+
+	// maxStreamEventSize bounds a single line of the sidecar event stream
+	maxStreamEventSize = 1024 * 1024
+)
+
+// sessionGoneMessage is recorded on sessions the sidecar has lost, e.g. because the project pod restarted
+const sessionGoneMessage = "session is no longer known to the OpenCode sidecar"
+
+var errStreamEnded = errors.New("sidecar stream ended before the session finished")
+
+// sidecarEvent is one server-sent event of a session's sidecar stream
+type sidecarEvent struct {
+	ID   string
+	Type string
+	Data string
+}
+
+// sidecarEventPayload holds the fields of the sidecar event payloads the backend persists
+type sidecarEventPayload struct {
+	Text     string `json:"text"`
+	Tool     string `json:"tool"`
+	Status   string `json:"status"`
+	Error    string `json:"error"`
+	Output   string `json:"output"`
+	Question string `json:"question"`
+	Fatal    bool   `json:"fatal"`
+
+	// usage events
+	Provider     string `json:"provider"`
+	Model        string `json:"model"`
+	InputTokens  int64  `json:"input_tokens"`
+	OutputTokens int64  `json:"output_tokens"`
+}
+
+// followSessionStream persists the sidecar event stream of a running session in the background,
+// so its output and final status are recorded whether or not anyone has the task open
+func (s *sessionService) followSessionStream(session *model.Session) {
+	if s.streamClient == nil {
+		return
+	}
+	if _, following := s.followers.LoadOrStore(session.ID, struct{}{}); following {
+		return
+	}
+
+	go func() {
+		defer s.followers.Delete(session.ID)
+
+		if err := s.consumeSessionStream(context.Background(), session); err != nil {
+			log.Printf("[SessionStream] Stopped following session %s: %v", session.ID, err)
+		}
+	}()
+}
+
+// consumeSessionStream reads the session's sidecar stream until the session reaches a final status,
+// resuming after the last stored event whenever the connection drops
+func (s *sessionService) consumeSessionStream(ctx context.Context, session *model.Session) error {
+	lastEventID := session.LastEventID
+	failures := 0
+
+	for {
+		resumedFrom := lastEventID
+		finished, err := s.followSessionStreamOnce(ctx, session, &lastEventID)
+		if finished {
+			return nil
+		}
+
+		if lastEventID != resumedFrom {
+			failures = 0
+		}
+		failures++
+		if failures > maxStreamRetries {
+			return fmt.Errorf("giving up after %d attempts: %w", failures, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(streamRetryDelay):
+		}
+	}
+}
+
+// followSessionStreamOnce reads the stream until it ends, then asks the sidecar how the session
+// is doing to tell a finished session from a dropped connection
+func (s *sessionService) followSessionStreamOnce(ctx context.Context, session *model.Session, lastEventID *string) (bool, error) {
+	current, err := s.sessionRepo.FindByID(ctx, session.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get session: %w", err)
+	}
+	if isTerminalSessionStatus(current.Status) {
+		return true, nil
+	}
+
+	podIP, err := s.sessionPodIP(ctx, session)
+	if err != nil {
+		return false, err
+	}
+
+	finished, streamErr := s.readSessionStream(ctx, session, podIP, lastEventID)
+	if finished {
+		return true, streamErr
+	}
+	if streamErr == nil {
+		streamErr = errStreamEnded
+	}
+
+	sidecarStatus, found, err := s.fetchSidecarSessionStatus(ctx, podIP, session.ID)
+	if err != nil {
+		return false, streamErr
+	}
+
+	switch {
+	case !found:
+		return true, s.finishSession(ctx, session.ID, model.SessionStatusFailed, sessionGoneMessage, "")
+	case isTerminalSessionStatus(model.SessionStatus(sidecarStatus.Status)):
+		return true, s.finishSession(ctx, session.ID, model.SessionStatus(sidecarStatus.Status), sidecarStatus.Error, "")
+	}
+
+	return false, streamErr
+}
+
+// readSessionStream persists the events of one connection to the sidecar stream.
+// It reports whether a final session event was received.
+func (s *sessionService) readSessionStream(ctx context.Context, session *model.Session, podIP string, lastEventID *string) (bool, error) {
+	url := fmt.Sprintf("%s/session/%s/events", SessionProxyURL(podIP), session.ID.String())
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "text/event-stream")
+	if *lastEventID != "" {
+		req.Header.Set("Last-Event-ID", *lastEventID)
+	}
+	if s.sharedSecret != "" {
+		req.Header.Set("Authorization", "Bearer "+s.sharedSecret)
+	}
+
+	resp, err := s.streamClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to sidecar stream: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("sidecar stream returned status %d", resp.StatusCode)
+	}
+
+	return s.consumeSidecarEvents(ctx, session, resp.Body, lastEventID)
+}
+
+// consumeSidecarEvents parses server-sent events from r and applies them in order
+func (s *sessionService) consumeSidecarEvents(ctx context.Context, session *model.Session, r io.Reader, lastEventID *string) (bool, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamEventSize)
+
+	var event sidecarEvent
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case line == "":
+			if event.Type == "" && len(data) == 0 {
+				continue
+			}
+			if event.Type == "" {
+				event.Type = "message"
+			}
+			event.Data = strings.Join(data, "\n")
+
+			finished, err := s.applySidecarEvent(ctx, session, event, lastEventID)
+			if finished || err != nil {
+				return finished, err
+			}
+			event, data = sidecarEvent{}, nil
+		case strings.HasPrefix(line, ":"):
+			// comment line
+		default:
+			field, value, _ := strings.Cut(line, ":")
+			value = strings.TrimPrefix(value, " ")
+			switch field {
+			case "event":
+				event.Type = value
+			case "id":
+				event.ID = value
+			case "data":
+				data = append(data, value)
+			}
+		}
+	}
+
+	return false, scanner.Err()
+}
+
+// applySidecarEvent appends the event to the session transcript and finishes the session on final events
+func (s *sessionService) applySidecarEvent(ctx context.Context, session *model.Session, event sidecarEvent, lastEventID *string) (bool, error) {
+	var payload sidecarEventPayload
+	if event.Data != "" {
+		if err := json.Unmarshal([]byte(event.Data), &payload); err != nil {
+			return false, fmt.Errorf("invalid %s event from sidecar: %w", event.Type, err)
+		}
+	}
+
+	var chunk string
+	switch event.Type {
+	case "output":
+		chunk = payload.Text
+	case "tool_call":
+		chunk = fmt.Sprintf("\n[tool] %s\n", payload.Tool)
+	case "error":
+		chunk = fmt.Sprintf("\nError: %s\n", payload.Error)
+	case "question":
+		chunk = fmt.Sprintf("\nQuestion: %s\n", payload.Question)
+	}
+
+	// Status events are not replayed by the sidecar (the initial one is sent to a single subscriber),
+	// so resuming after one would skip everything since the previous event
+	if event.ID != "" && event.Type != "status" {
+		if err := s.appendSessionOutput(ctx, session, chunk, event.ID); err != nil {
+			return false, err
+		}
+		*lastEventID = event.ID
+	}
+
+	switch event.Type {
+	case "tool_call":
+		stopped, err := s.recordIteration(ctx, session)
+		if err != nil {
+			log.Printf("[SessionStream] Failed to count iteration of session %s: %v", session.ID, err)
+		}
+		if stopped {
+			return true, nil
+		}
+	case "usage":
+		if err := s.recordUsage(ctx, session, payload); err != nil {
+			log.Printf("[SessionStream] Failed to record token usage of session %s: %v", session.ID, err)
+			break
+		}
+		stopped, err := s.enforceBudgets(ctx, session)
+		if err != nil {
+			log.Printf("[SessionStream] Failed to check budgets of session %s: %v", session.ID, err)
+		}
+		if stopped {
+			return true, nil
+		}
+	case "turn_complete":
+		// A queued follow-up prompt runs next; the session itself goes on
+		if err := s.recordAgentTurn(ctx, session, payload.Output); err != nil {
+			log.Printf("[SessionStream] Failed to record turn of session %s: %v", session.ID, err)
+		}
+	case "question":
+		if err := s.waitForUserInput(ctx, session, payload.Question); err != nil {
+			return false, err
+		}
+	case "complete":
+		return true, s.finishSession(ctx, session.ID, model.SessionStatusCompleted, "", payload.Output)
+	case "status":
+		status := model.SessionStatus(payload.Status)
+		if isTerminalSessionStatus(status) {
+			return true, s.finishSession(ctx, session.ID, status, payload.Error, "")
+		}
+	case "error":
+		if payload.Fatal {
+			return false, fmt.Errorf("sidecar stream failed: %s", payload.Error)
+		}
+	}
+
+	return false, nil
+}
+
+// appendSessionOutput stores a chunk of streamed output on the session and, for execution sessions, on the task
+func (s *sessionService) appendSessionOutput(ctx context.Context, session *model.Session, chunk string, eventID string) error {
+	unlock := s.lockSession(session.ID)
+	defer unlock()
+
+	// Review sessions keep their output to themselves; the task transcript shows the work on it
+	taskID := session.TaskID
+	if session.Kind == model.SessionKindReview {
+		taskID = uuid.Nil
+	}
+
+	if err := s.sessionRepo.AppendOutput(ctx, session.ID, taskID, chunk, eventID); err != nil {
+		return fmt.Errorf("failed to append output: %w", err)
+	}
+
+	return nil
+}
+
+// recordUsage adds the tokens of a model call to the session, priced with the model registry.
+// Usage reported without a model is priced as the model the session runs with.
+func (s *sessionService) recordUsage(ctx context.Context, session *model.Session, payload sidecarEventPayload) error {
+	usage := repository.SessionUsage{
+		ModelProvider: payload.Provider,
+		ModelName:     payload.Model,
+		InputTokens:   payload.InputTokens,
+		OutputTokens:  payload.OutputTokens,
+	}
+	if usage.ModelName == "" {
+		usage.ModelProvider = session.ModelProvider
+		usage.ModelName = session.ModelName
+	}
+	usage.CostUSD = GetModelCost(usage.ModelProvider, usage.ModelName, usage.InputTokens, usage.OutputTokens)
+
+	unlock := s.lockSession(session.ID)
+	defer unlock()
+
+	return s.sessionRepo.AddUsage(ctx, session.ID, usage)
+}
+
+// finishSession records the final status reported by the sidecar unless the session already finished,
+// e.g. because it was stopped or the sidecar reported completion directly
+func (s *sessionService) finishSession(ctx context.Context, sessionID uuid.UUID, status model.SessionStatus, errorMsg string, finalOutput string) error {
+	if finalOutput != "" {
+		if err := s.UpdateSessionFinalOutput(ctx, sessionID, finalOutput); err != nil {
+			return err
+		}
+	}
+
+	// The sidecar enforces the same limits and usually stops the session first; the user still learns why
+	if status == model.SessionStatusFailed {
+		if session, err := s.sessionRepo.FindByID(ctx, sessionID); err == nil {
+			if reason := sessionLimitExceeded(session, time.Now()); reason != "" {
+				return s.failSessionOverLimit(ctx, session, reason, sessionLimitMetadata(session))
+			}
+		}
+	}
+
+	return s.updateSessionStatus(ctx, sessionID, string(status), errorMsg, true)
+}
+
+// sidecarSessionStatus is the sidecar's view of a session
+type sidecarSessionStatus struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+}
+
+// fetchSidecarSessionStatus asks the sidecar for the session status; found is false
+// once the sidecar no longer knows the session
+func (s *sessionService) fetchSidecarSessionStatus(ctx context.Context, podIP string, sessionID uuid.UUID) (*sidecarSessionStatus, bool, error) {
+	url := fmt.Sprintf("%s/session/%s", SessionProxyURL(podIP), sessionID.String())
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if s.sharedSecret != "" {
+		req.Header.Set("Authorization", "Bearer "+s.sharedSecret)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to call OpenCode API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, false, fmt.Errorf("OpenCode API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var status sidecarSessionStatus
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, false, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &status, true, nil
+}
+
+// sessionPodIP resolves the IP of the pod running the session's sidecar
+func (s *sessionService) sessionPodIP(ctx context.Context, session *model.Session) (string, error) {
+	project, err := s.projectRepo.FindByID(ctx, session.ProjectID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get project: %w", err)
+	}
+
+	podIP, err := s.k8sService.GetPodIP(ctx, project.PodName, project.PodNamespace)
+	if err != nil {
+		return "", fmt.Errorf("failed to get pod IP: %w", err)
+	}
+
+	return podIP, nil
+}
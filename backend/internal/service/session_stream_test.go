@@ -0,0 +1,388 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/npinot/vibe/backend/internal/model"
+	"github.com/npinot/vibe/backend/internal/repository"
+)
+
+// sidecarTransport sends every request to the test server, whatever pod IP it was addressed to
+type sidecarTransport struct {
+	target *url.URL
+}
+
+func (t sidecarTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func setupSessionStreamTest(kind model.SessionKind) (*sessionService, *MockSessionRepository, *MockTaskRepository, *model.Session) {
+	sessionRepo := new(MockSessionRepository)
+	taskRepo := new(MockTaskRepository)
+	startedAt := time.Now().Add(-time.Minute)
+
+	session := &model.Session{
+		ID:           uuid.New(),
+		TaskID:       uuid.New(),
+		ProjectID:    uuid.New(),
+		Status:       model.SessionStatusRunning,
+		Kind:         kind,
+		StartedAt:    &startedAt,
+		RunStartedAt: &startedAt,
+	}
+
+	service := &sessionService{
+		sessionRepo: sessionRepo,
+		taskRepo:    taskRepo,
+		httpClient:  &http.Client{},
+	}
+
+	return service, sessionRepo, taskRepo, session
+}
+
+func TestSessionService_ConsumeSidecarEvents(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("persists output and completes session", func(t *testing.T) {
+		service, sessionRepo, taskRepo, session := setupSessionStreamTest(model.SessionKindExecution)
+		// Review stage disabled: completion ends with the session update
+		configService := new(MockConfigService)
+		configService.On("GetActiveConfig", ctx, session.ProjectID).Return(&model.OpenCodeConfig{}, nil)
+		service.configService = configService
+
+		stream := strings.Join([]string{
+			"event: status",
+			"id: ses-init-1",
+			`data: {"status": "running"}`,
+			"",
+			": keep-alive comment",
+			"",
+			"event: output",
+			"id: evt-1",
+			`data: {"type": "stdout", "text": "Reading main.go\n"}`,
+			"",
+			"event: tool_call",
+			"id: evt-2",
+			`data: {"tool": "edit", "args": {}}`,
+			"",
+			"event: heartbeat",
+			"id: evt-3",
+			"data: {}",
+			"",
+			"event: complete",
+			"id: evt-4",
+			`data: {"final_message": "Task completed", "output": "Added the handler"}`,
+		}, "\n") + "\n\n"
+
+		sessionRepo.On("AppendOutput", ctx, session.ID, session.TaskID, "Reading main.go\n", "evt-1").Return(nil).Once()
+		sessionRepo.On("AppendOutput", ctx, session.ID, session.TaskID, "\n[tool] edit\n", "evt-2").Return(nil).Once()
+		sessionRepo.On("IncrementIterations", ctx, session.ID).Return(1, nil).Once()
+		sessionRepo.On("AppendOutput", ctx, session.ID, session.TaskID, "", "evt-3").Return(nil).Once()
+		sessionRepo.On("AppendOutput", ctx, session.ID, session.TaskID, "", "evt-4").Return(nil).Once()
+		sessionRepo.On("UpdateFinalOutput", ctx, session.ID, "Added the handler").Return(nil)
+		sessionRepo.On("FindByID", ctx, session.ID).Return(session, nil)
+		sessionRepo.On("Update", ctx, session).Return(nil)
+		taskRepo.On("FindByID", ctx, session.TaskID).Return(&model.Task{ID: session.TaskID, Status: model.TaskStatusInProgress}, nil)
+
+		lastEventID := ""
+		finished, err := service.consumeSidecarEvents(ctx, session, strings.NewReader(stream), &lastEventID)
+
+		require.NoError(t, err)
+		assert.True(t, finished)
+		assert.Equal(t, "evt-4", lastEventID)
+		assert.Equal(t, model.SessionStatusCompleted, session.Status)
+		require.NotNil(t, session.CompletedAt)
+		assert.GreaterOrEqual(t, session.DurationMs, time.Minute.Milliseconds())
+		sessionRepo.AssertExpectations(t)
+		taskRepo.AssertExpectations(t)
+	})
+
+	t.Run("records answered follow-up turns", func(t *testing.T) {
+		service, sessionRepo, _, session := setupSessionStreamTest(model.SessionKindExecution)
+		interactionRepo := new(mockInteractionRepo)
+		service.interactionRepo = interactionRepo
+		userID := uuid.New()
+
+		stream := "event: turn_complete\nid: evt-1\ndata: {\"output\": \"Renamed the helper\"}\n\n"
+		sessionRepo.On("AppendOutput", ctx, session.ID, session.TaskID, "", "evt-1").Return(nil)
+		interactionRepo.On("FindBySessionID", ctx, session.ID).Return([]model.Interaction{{MessageType: MessageTypeUser, UserID: userID}}, nil)
+		interactionRepo.On("Create", ctx, mock.MatchedBy(func(interaction *model.Interaction) bool {
+			return interaction.MessageType == MessageTypeAgent && interaction.Content == "Renamed the helper" && interaction.UserID == userID
+		})).Return(nil)
+
+		lastEventID := ""
+		finished, err := service.consumeSidecarEvents(ctx, session, strings.NewReader(stream), &lastEventID)
+
+		require.NoError(t, err)
+		assert.False(t, finished)
+		assert.Equal(t, "evt-1", lastEventID)
+		interactionRepo.AssertExpectations(t)
+	})
+
+	t.Run("records token usage priced by the model registry", func(t *testing.T) {
+		service, sessionRepo, _, session := setupSessionStreamTest(model.SessionKindExecution)
+		session.ModelProvider = "openai"
+		session.ModelName = "gpt-4o"
+
+		stream := strings.Join([]string{
+			"event: usage",
+			"id: evt-1",
+			`data: {"provider": "anthropic", "model": "claude-3.5-sonnet-20240620", "input_tokens": 100000, "output_tokens": 10000}`,
+			"",
+			// Usage without a model is priced as the session's model
+			"event: usage",
+			"id: evt-2",
+			`data: {"input_tokens": 200000, "output_tokens": 50000}`,
+		}, "\n") + "\n\n"
+
+		sessionRepo.On("AppendOutput", ctx, session.ID, session.TaskID, "", mock.Anything).Return(nil)
+		sessionRepo.On("AddUsage", ctx, session.ID, mock.MatchedBy(func(usage repository.SessionUsage) bool {
+			return usage.ModelName == "claude-3.5-sonnet-20240620" && usage.InputTokens == 100000 && usage.OutputTokens == 10000 &&
+				usage.CostUSD > 0.449 && usage.CostUSD < 0.451
+		})).Return(nil).Once()
+		sessionRepo.On("AddUsage", ctx, session.ID, mock.MatchedBy(func(usage repository.SessionUsage) bool {
+			return usage.ModelProvider == "openai" && usage.ModelName == "gpt-4o" && usage.CostUSD > 0.999 && usage.CostUSD < 1.001
+		})).Return(nil).Once()
+
+		lastEventID := ""
+		finished, err := service.consumeSidecarEvents(ctx, session, strings.NewReader(stream), &lastEventID)
+
+		require.NoError(t, err)
+		assert.False(t, finished)
+		assert.Equal(t, "evt-2", lastEventID)
+		sessionRepo.AssertExpectations(t)
+	})
+
+	t.Run("question pauses session for user input", func(t *testing.T) {
+		service, sessionRepo, taskRepo, session := setupSessionStreamTest(model.SessionKindExecution)
+		projectRepo := new(MockProjectRepository)
+		interactionRepo := new(mockInteractionRepo)
+		service.projectRepo = projectRepo
+		service.interactionService = NewInteractionService(interactionRepo, taskRepo, NewAuthorizationService(projectRepo, nil), sessionRepo)
+		ownerID := uuid.New()
+
+		stream := "event: question\nid: evt-1\ndata: {\"question\": \"Which database should I use?\"}\n\n"
+		sessionRepo.On("AppendOutput", ctx, session.ID, session.TaskID, "\nQuestion: Which database should I use?\n", "evt-1").Return(nil)
+		sessionRepo.On("FindByID", ctx, session.ID).Return(session, nil)
+		sessionRepo.On("Update", ctx, mock.MatchedBy(func(s *model.Session) bool {
+			return s.Status == model.SessionStatusWaitingForInput
+		})).Return(nil)
+		taskRepo.On("FindByID", ctx, session.TaskID).Return(&model.Task{ID: session.TaskID, ProjectID: session.ProjectID}, nil)
+		projectRepo.On("FindByID", ctx, session.ProjectID).Return(&model.Project{ID: session.ProjectID, UserID: ownerID}, nil)
+		interactionRepo.On("Create", ctx, mock.MatchedBy(func(interaction *model.Interaction) bool {
+			return interaction.MessageType == MessageTypeAgent &&
+				interaction.Content == "Which database should I use?" &&
+				interaction.UserID == ownerID &&
+				interaction.Metadata["question"] == true &&
+				interaction.SessionID != nil && *interaction.SessionID == session.ID
+		})).Return(nil)
+
+		lastEventID := ""
+		finished, err := service.consumeSidecarEvents(ctx, session, strings.NewReader(stream), &lastEventID)
+
+		require.NoError(t, err)
+		assert.False(t, finished)
+		assert.Equal(t, model.SessionStatusWaitingForInput, session.Status)
+		assert.Nil(t, session.RunStartedAt)
+		assert.GreaterOrEqual(t, session.DurationMs, time.Minute.Milliseconds())
+		sessionRepo.AssertExpectations(t)
+		interactionRepo.AssertExpectations(t)
+	})
+
+	t.Run("question after session was stopped", func(t *testing.T) {
+		service, sessionRepo, taskRepo, session := setupSessionStreamTest(model.SessionKindExecution)
+		interactionRepo := new(mockInteractionRepo)
+		service.interactionService = NewInteractionService(interactionRepo, taskRepo, nil, sessionRepo)
+		session.Status = model.SessionStatusCancelled
+
+		stream := "event: question\nid: evt-1\ndata: {\"question\": \"Continue?\"}\n\n"
+		sessionRepo.On("AppendOutput", ctx, session.ID, session.TaskID, mock.Anything, "evt-1").Return(nil)
+		sessionRepo.On("FindByID", ctx, session.ID).Return(session, nil)
+
+		lastEventID := ""
+		_, err := service.consumeSidecarEvents(ctx, session, strings.NewReader(stream), &lastEventID)
+
+		require.NoError(t, err)
+		assert.Equal(t, model.SessionStatusCancelled, session.Status)
+		sessionRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		interactionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("review output stays on the session", func(t *testing.T) {
+		service, sessionRepo, taskRepo, session := setupSessionStreamTest(model.SessionKindReview)
+
+		stream := "event: output\nid: evt-1\ndata: {\"text\": \"Looks fine\"}\n\n"
+		sessionRepo.On("AppendOutput", ctx, session.ID, uuid.Nil, "Looks fine", "evt-1").Return(nil)
+
+		lastEventID := ""
+		finished, err := service.consumeSidecarEvents(ctx, session, strings.NewReader(stream), &lastEventID)
+
+		require.NoError(t, err)
+		assert.False(t, finished)
+		sessionRepo.AssertExpectations(t)
+		taskRepo.AssertNotCalled(t, "AppendOutput", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("failed status finishes session with error", func(t *testing.T) {
+		service, sessionRepo, taskRepo, session := setupSessionStreamTest(model.SessionKindExecution)
+
+		stream := "event: status\nid: ses-failed-1\ndata: {\"status\": \"failed\", \"error\": \"Session timeout after 3600 seconds\"}\n\n"
+		sessionRepo.On("FindByID", ctx, session.ID).Return(session, nil)
+		sessionRepo.On("Update", ctx, session).Return(nil)
+		taskRepo.On("FindByID", ctx, session.TaskID).Return(&model.Task{ID: session.TaskID, Status: model.TaskStatusInProgress}, nil)
+		taskRepo.On("UpdateStatus", ctx, session.TaskID, model.TaskStatusTodo).Return(nil)
+
+		lastEventID := "evt-9"
+		finished, err := service.consumeSidecarEvents(ctx, session, strings.NewReader(stream), &lastEventID)
+
+		require.NoError(t, err)
+		assert.True(t, finished)
+		assert.Equal(t, "evt-9", lastEventID)
+		assert.Equal(t, model.SessionStatusFailed, session.Status)
+		assert.Contains(t, session.Output, "Session timeout after 3600 seconds")
+		taskRepo.AssertExpectations(t)
+		sessionRepo.AssertNotCalled(t, "AppendOutput", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("session already finished", func(t *testing.T) {
+		service, sessionRepo, _, session := setupSessionStreamTest(model.SessionKindExecution)
+		session.Status = model.SessionStatusCancelled
+
+		stream := "event: status\nid: ses-failed-1\ndata: {\"status\": \"failed\"}\n\n"
+		sessionRepo.On("FindByID", ctx, session.ID).Return(session, nil)
+
+		lastEventID := ""
+		finished, err := service.consumeSidecarEvents(ctx, session, strings.NewReader(stream), &lastEventID)
+
+		require.NoError(t, err)
+		assert.True(t, finished)
+		assert.Equal(t, model.SessionStatusCancelled, session.Status)
+		sessionRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("fatal stream error", func(t *testing.T) {
+		service, sessionRepo, _, session := setupSessionStreamTest(model.SessionKindExecution)
+
+		stream := "event: error\nid: ses-error-1\ndata: {\"error\": \"subscription closed\", \"fatal\": true}\n\n"
+		sessionRepo.On("AppendOutput", ctx, session.ID, session.TaskID, "\nError: subscription closed\n", "ses-error-1").Return(nil)
+
+		lastEventID := ""
+		finished, err := service.consumeSidecarEvents(ctx, session, strings.NewReader(stream), &lastEventID)
+
+		assert.Error(t, err)
+		assert.False(t, finished)
+	})
+}
+
+func TestSessionService_FollowSessionStreamOnce(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T, handler http.HandlerFunc) (*sessionService, *MockSessionRepository, *model.Session) {
+		service, sessionRepo, _, session := setupSessionStreamTest(model.SessionKindReview)
+
+		server := httptest.NewServer(handler)
+		t.Cleanup(server.Close)
+		target, err := url.Parse(server.URL)
+		require.NoError(t, err)
+
+		projectRepo := new(MockProjectRepository)
+		k8sService := new(MockKubernetesService)
+		projectRepo.On("FindByID", ctx, session.ProjectID).Return(&model.Project{ID: session.ProjectID, PodName: "pod", PodNamespace: "ns"}, nil)
+		k8sService.On("GetPodIP", ctx, "pod", "ns").Return("10.0.0.1", nil)
+
+		service.projectRepo = projectRepo
+		service.k8sService = k8sService
+		service.sharedSecret = "secret"
+		service.httpClient = &http.Client{Transport: sidecarTransport{target: target}}
+		service.streamClient = &http.Client{Transport: sidecarTransport{target: target}}
+
+		return service, sessionRepo, session
+	}
+
+	t.Run("resumes after last event", func(t *testing.T) {
+		service, sessionRepo, session := setup(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+			if strings.HasSuffix(r.URL.Path, "/events") {
+				assert.Equal(t, "evt-1", r.Header.Get("Last-Event-ID"))
+				w.Header().Set("Content-Type", "text/event-stream")
+				w.Write([]byte("event: output\nid: evt-2\ndata: {\"text\": \"more\"}\n\n"))
+				return
+			}
+			w.Write([]byte(`{"status": "running"}`))
+		})
+		sessionRepo.On("FindByID", ctx, session.ID).Return(session, nil)
+		sessionRepo.On("AppendOutput", ctx, session.ID, uuid.Nil, "more", "evt-2").Return(nil)
+
+		lastEventID := "evt-1"
+		finished, err := service.followSessionStreamOnce(ctx, session, &lastEventID)
+
+		assert.ErrorIs(t, err, errStreamEnded)
+		assert.False(t, finished)
+		assert.Equal(t, "evt-2", lastEventID)
+	})
+
+	t.Run("sidecar lost the session", func(t *testing.T) {
+		service, sessionRepo, session := setup(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": "Session not found"}`))
+		})
+		sessionRepo.On("FindByID", ctx, session.ID).Return(session, nil)
+		sessionRepo.On("Update", ctx, session).Return(nil)
+		service.taskRepo.(*MockTaskRepository).On("FindByID", ctx, session.TaskID).Return(&model.Task{ID: session.TaskID, Status: model.TaskStatusDone}, nil)
+
+		lastEventID := ""
+		finished, err := service.followSessionStreamOnce(ctx, session, &lastEventID)
+
+		require.NoError(t, err)
+		assert.True(t, finished)
+		assert.Equal(t, model.SessionStatusFailed, session.Status)
+		assert.Contains(t, session.Output, "no longer known to the OpenCode sidecar")
+	})
+
+	t.Run("sidecar reports final status", func(t *testing.T) {
+		service, sessionRepo, session := setup(t, func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasSuffix(r.URL.Path, "/events") {
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+			w.Write([]byte(`{"status": "cancelled"}`))
+		})
+		sessionRepo.On("FindByID", ctx, session.ID).Return(session, nil)
+		sessionRepo.On("Update", ctx, session).Return(nil)
+		service.taskRepo.(*MockTaskRepository).On("FindByID", ctx, session.TaskID).Return(&model.Task{ID: session.TaskID, Status: model.TaskStatusDone}, nil)
+
+		lastEventID := ""
+		finished, err := service.followSessionStreamOnce(ctx, session, &lastEventID)
+
+		require.NoError(t, err)
+		assert.True(t, finished)
+		assert.Equal(t, model.SessionStatusCancelled, session.Status)
+	})
+
+	t.Run("session already finished in database", func(t *testing.T) {
+		service, sessionRepo, session := setup(t, func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("unexpected sidecar request %s", r.URL.Path)
+		})
+		session.Status = model.SessionStatusCompleted
+		sessionRepo.On("FindByID", ctx, session.ID).Return(session, nil)
+
+		lastEventID := ""
+		finished, err := service.followSessionStreamOnce(ctx, session, &lastEventID)
+
+		require.NoError(t, err)
+		assert.True(t, finished)
+	})
+}
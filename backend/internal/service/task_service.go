@@ -4,6 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -17,9 +21,20 @@ var (
 	ErrInvalidTaskTitle       = errors.New("invalid task title")
 	ErrInvalidTaskPriority    = errors.New("invalid task priority")
 	ErrInvalidStateTransition = errors.New("invalid state transition")
+	ErrWorkspaceBusy          = errors.New("workspace is busy with another task")
+	ErrInvalidReview          = errors.New("invalid review")
+	ErrReviewRequired         = errors.New("review required")
 )
 
-// validTransitions defines the state machine for task status transitions
+const (
+	taskBranchPrefix  = "task/"
+	maxBranchSlugSize = 40
+)
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// validTransitions defines the state machine for task status transitions.
+// Leaving human_review additionally requires a matching review decision (see reviewDecisionFor).
 var validTransitions = map[model.TaskStatus][]model.TaskStatus{
 	model.TaskStatusTodo:        {model.TaskStatusInProgress},
 	model.TaskStatusInProgress:  {model.TaskStatusAIReview, model.TaskStatusTodo},
@@ -28,6 +43,12 @@ var validTransitions = map[model.TaskStatus][]model.TaskStatus{
 	model.TaskStatusDone:        {model.TaskStatusTodo}, // Allow reopening
 }
 
+// reviewDecisionFor maps the transitions out of human_review to the review decision they require
+var reviewDecisionFor = map[model.TaskStatus]model.ReviewDecision{
+	model.TaskStatusDone:       model.ReviewDecisionApproved,
+	model.TaskStatusInProgress: model.ReviewDecisionChangesRequested,
+}
+
 // TaskService defines business logic operations for task management
 type TaskService interface {
 	// CreateTask creates a new task with validation and authorization
@@ -56,20 +77,45 @@ type TaskService interface {
 
 	// GetTaskSessions returns execution history for a task
 	GetTaskSessions(ctx context.Context, id, userID uuid.UUID) ([]model.Session, error)
+
+	// GetSessionDiff returns the workspace changes made by one of the task's sessions
+	GetSessionDiff(ctx context.Context, id, sessionID, userID uuid.UUID) (*WorkspaceDiff, error)
+
+	// ContinueSession sends a follow-up prompt to one of the task's execution sessions,
+	// continuing its OpenCode conversation, and moves the task back to in_progress
+	ContinueSession(ctx context.Context, id, sessionID, userID uuid.UUID, prompt string) (*model.Session, error)
+
+	// SubmitReview records a human review of a task in human_review and moves the task
+	// to done (approved) or back to in_progress (changes requested)
+	SubmitReview(ctx context.Context, id, userID uuid.UUID, decision model.ReviewDecision, summary string, comments []model.ReviewComment) (*model.TaskReview, error)
+
+	// ListReviews returns the review history of a task, newest first
+	ListReviews(ctx context.Context, id, userID uuid.UUID) ([]model.TaskReview, error)
 }
 
 type taskService struct {
 	taskRepo       repository.TaskRepository
 	projectRepo    repository.ProjectRepository
+	authz          AuthorizationService
 	sessionService SessionService
+	gitService     WorkspaceGitService
+	reviewRepo     repository.ReviewRepository
+	snapshots      WorkspaceSnapshotService
+	projectLocks   sync.Map // project ID -> *sync.Mutex serializing task runs in the project workspace
 }
 
-// NewTaskService creates a new task service
-func NewTaskService(taskRepo repository.TaskRepository, projectRepo repository.ProjectRepository, sessionService SessionService) TaskService {
+// NewTaskService creates a new task service.
+// gitService may be nil, in which case tasks run without a dedicated branch.
+// snapshots may be nil, in which case no workspace snapshot is taken before tasks run.
+func NewTaskService(taskRepo repository.TaskRepository, projectRepo repository.ProjectRepository, authz AuthorizationService, sessionService SessionService, gitService WorkspaceGitService, reviewRepo repository.ReviewRepository, snapshots WorkspaceSnapshotService) TaskService {
 	return &taskService{
 		taskRepo:       taskRepo,
 		projectRepo:    projectRepo,
+		authz:          authz,
 		sessionService: sessionService,
+		gitService:     gitService,
+		reviewRepo:     reviewRepo,
+		snapshots:      snapshots,
 	}
 }
 
@@ -84,17 +130,13 @@ func (s *taskService) CreateTask(ctx context.Context, projectID, userID uuid.UUI
 		return nil, err
 	}
 
-	// Check user owns project
-	project, err := s.projectRepo.FindByID(ctx, projectID)
+	project, err := s.authz.AuthorizeProject(ctx, projectID, userID, model.ProjectRoleMaintainer)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrProjectNotFound
-		}
-		return nil, fmt.Errorf("failed to retrieve project: %w", err)
+		return nil, err
 	}
 
-	if project.UserID != userID {
-		return nil, ErrUnauthorized
+	if project.Status == model.ProjectStatusArchived {
+		return nil, ErrProjectArchived
 	}
 
 	// Get current tasks to determine position (append to TODO column)
@@ -132,43 +174,46 @@ func (s *taskService) CreateTask(ctx context.Context, projectID, userID uuid.UUI
 
 // GetTask retrieves a task with authorization check
 func (s *taskService) GetTask(ctx context.Context, id, userID uuid.UUID) (*model.Task, error) {
-	task, err := s.taskRepo.FindByID(ctx, id)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrTaskNotFound
-		}
-		return nil, fmt.Errorf("failed to retrieve task: %w", err)
-	}
+	task, _, err := s.authorizedTask(ctx, id, userID, model.ProjectRoleViewer)
+	return task, err
+}
 
-	// Authorization check - verify user owns the project
-	project, err := s.projectRepo.FindByID(ctx, task.ProjectID)
+// writableTask retrieves a task to modify or run, which requires the maintainer role; tasks of archived projects are read-only
+func (s *taskService) writableTask(ctx context.Context, id, userID uuid.UUID) (*model.Task, error) {
+	task, project, err := s.authorizedTask(ctx, id, userID, model.ProjectRoleMaintainer)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrProjectNotFound
-		}
-		return nil, fmt.Errorf("failed to retrieve project for authorization: %w", err)
+		return nil, err
 	}
 
-	if project.UserID != userID {
-		return nil, ErrUnauthorized
+	if project.Status == model.ProjectStatusArchived {
+		return nil, ErrProjectArchived
 	}
 
 	return task, nil
 }
 
-// ListProjectTasks retrieves all tasks for a project with authorization
-func (s *taskService) ListProjectTasks(ctx context.Context, projectID, userID uuid.UUID) ([]model.Task, error) {
-	// Check user owns project
-	project, err := s.projectRepo.FindByID(ctx, projectID)
+// authorizedTask retrieves a task and its project, checking the user holds at least the given role on the project
+func (s *taskService) authorizedTask(ctx context.Context, id, userID uuid.UUID, role model.ProjectRole) (*model.Task, *model.Project, error) {
+	task, err := s.taskRepo.FindByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrProjectNotFound
+			return nil, nil, ErrTaskNotFound
 		}
-		return nil, fmt.Errorf("failed to retrieve project: %w", err)
+		return nil, nil, fmt.Errorf("failed to retrieve task: %w", err)
 	}
 
-	if project.UserID != userID {
-		return nil, ErrUnauthorized
+	project, err := s.authz.AuthorizeProject(ctx, task.ProjectID, userID, role)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return task, project, nil
+}
+
+// ListProjectTasks retrieves all tasks for a project with authorization
+func (s *taskService) ListProjectTasks(ctx context.Context, projectID, userID uuid.UUID) ([]model.Task, error) {
+	if _, err := s.authz.AuthorizeProject(ctx, projectID, userID, model.ProjectRoleViewer); err != nil {
+		return nil, err
 	}
 
 	// Fetch tasks
@@ -183,7 +228,7 @@ func (s *taskService) ListProjectTasks(ctx context.Context, projectID, userID uu
 // UpdateTask updates task fields with authorization check
 func (s *taskService) UpdateTask(ctx context.Context, id, userID uuid.UUID, updates map[string]interface{}) (*model.Task, error) {
 	// Retrieve and authorize
-	task, err := s.GetTask(ctx, id, userID)
+	task, err := s.writableTask(ctx, id, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -219,7 +264,7 @@ func (s *taskService) UpdateTask(ctx context.Context, id, userID uuid.UUID, upda
 // MoveTask moves a task to a new state and/or position with state machine validation
 func (s *taskService) MoveTask(ctx context.Context, id, userID uuid.UUID, newState model.TaskStatus, newPosition int) (*model.Task, error) {
 	// Retrieve and authorize
-	task, err := s.GetTask(ctx, id, userID)
+	task, err := s.writableTask(ctx, id, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -229,6 +274,12 @@ func (s *taskService) MoveTask(ctx context.Context, id, userID uuid.UUID, newSta
 		if !isValidTransition(task.Status, newState) {
 			return nil, fmt.Errorf("%w: cannot transition from %s to %s", ErrInvalidStateTransition, task.Status, newState)
 		}
+
+		if task.Status == model.TaskStatusHumanReview {
+			if err := s.requireReviewDecision(ctx, task, reviewDecisionFor[newState]); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	// Update status and position
@@ -246,7 +297,7 @@ func (s *taskService) MoveTask(ctx context.Context, id, userID uuid.UUID, newSta
 // DeleteTask soft deletes a task with authorization check
 func (s *taskService) DeleteTask(ctx context.Context, id, userID uuid.UUID) error {
 	// Retrieve and authorize
-	_, err := s.GetTask(ctx, id, userID)
+	_, err := s.writableTask(ctx, id, userID)
 	if err != nil {
 		return err
 	}
@@ -300,16 +351,42 @@ func isValidTransition(currentState, newState model.TaskStatus) bool {
 
 // ExecuteTask starts execution of a task via OpenCode session
 func (s *taskService) ExecuteTask(ctx context.Context, id, userID uuid.UUID) (*model.Session, error) {
-	task, err := s.GetTask(ctx, id, userID)
+	task, err := s.writableTask(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	review, err := s.currentReview(ctx, task)
+	if err != nil {
+		return nil, err
+	}
+	changesRequested := review != nil && review.Decision == model.ReviewDecisionChangesRequested
+
+	aiFailure, err := s.currentAIReviewFailure(ctx, task)
 	if err != nil {
 		return nil, err
 	}
 
-	if task.Status != model.TaskStatusTodo {
+	// A task sent back by a reviewer waits in IN_PROGRESS until it is run again
+	rework := changesRequested || aiFailure != nil
+	if task.Status != model.TaskStatusTodo && !(task.Status == model.TaskStatusInProgress && rework) {
 		return nil, fmt.Errorf("%w: can only execute tasks in TODO state, current state: %s", ErrInvalidStateTransition, task.Status)
 	}
 
-	prompt := fmt.Sprintf("Task: %s\n\nDescription:\n%s", task.Title, task.Description)
+	// Another task may not start between the busy check and this session start
+	unlock := s.lockProject(task.ProjectID)
+	defer unlock()
+
+	if err := s.prepareTaskBranch(ctx, task); err != nil {
+		return nil, err
+	}
+
+	s.snapshotWorkspace(ctx, task)
+
+	if !changesRequested {
+		review = nil
+	}
+	prompt := buildTaskPrompt(task, review, aiFailure)
 
 	session, err := s.sessionService.StartSession(ctx, task.ID, prompt)
 	if err != nil {
@@ -324,9 +401,118 @@ func (s *taskService) ExecuteTask(ctx context.Context, id, userID uuid.UUID) (*m
 	return session, nil
 }
 
+// snapshotWorkspace snapshots the project workspace so the task run can be undone. The run does not
+// depend on it, so failures are only logged.
+func (s *taskService) snapshotWorkspace(ctx context.Context, task *model.Task) {
+	if s.snapshots == nil {
+		return
+	}
+
+	snapshot, err := s.snapshots.SnapshotBeforeTask(ctx, task.ProjectID, task.ID)
+	if err != nil {
+		log.Printf("[TaskService] Failed to snapshot workspace before task %s: %v", task.ID, err)
+		return
+	}
+	if snapshot != nil {
+		log.Printf("[TaskService] Snapshot %s taken before task %s", snapshot.Name, task.ID)
+	}
+}
+
+// lockProject serializes the runs of the project's tasks until unlock is called, so checking that the
+// workspace is free, checking out the task branch and starting the session happen as one step
+func (s *taskService) lockProject(projectID uuid.UUID) (unlock func()) {
+	lock, _ := s.projectLocks.LoadOrStore(projectID, &sync.Mutex{})
+	mu := lock.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
+// prepareTaskBranch checks out the task's dedicated branch in the project workspace
+// so that concurrent tasks in the same project never share uncommitted changes.
+// Projects without a repository and workspaces without a git repository are left untouched.
+// Callers hold the project lock.
+func (s *taskService) prepareTaskBranch(ctx context.Context, task *model.Task) error {
+	if s.gitService == nil {
+		return nil
+	}
+
+	project, err := s.projectRepo.FindByID(ctx, task.ProjectID)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve project: %w", err)
+	}
+
+	// The workspace has a single working tree, so only one task may run at a time
+	activeSessions, err := s.sessionService.GetActiveProjectSessions(ctx, project.ID)
+	if err != nil {
+		return fmt.Errorf("failed to check active sessions: %w", err)
+	}
+	for _, session := range activeSessions {
+		if session.TaskID != task.ID {
+			return fmt.Errorf("%w: task %s is still running", ErrWorkspaceBusy, session.TaskID)
+		}
+	}
+
+	// Without a repository there is no base ref to start task branches from; branching from HEAD would
+	// pick up whatever a previous task left checked out, so tasks run on the workspace as is
+	startPoint := taskBranchStartPoint(project)
+	if startPoint == "" {
+		return nil
+	}
+
+	branch := task.BranchName
+	if branch == "" {
+		branch = taskBranchName(task)
+	}
+
+	if err := s.gitService.PrepareBranch(ctx, project, branch, startPoint); err != nil {
+		if errors.Is(err, ErrWorkspaceNotRepository) {
+			return nil
+		}
+		return fmt.Errorf("failed to prepare task branch: %w", err)
+	}
+
+	if task.BranchName != branch {
+		task.BranchName = branch
+		if err := s.taskRepo.Update(ctx, task); err != nil {
+			return fmt.Errorf("failed to record task branch: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// taskBranchName builds the dedicated branch name for a task: task/<short-id>-<slug>
+func taskBranchName(task *model.Task) string {
+	shortID := task.ID.String()[:8]
+
+	slug := nonSlugChars.ReplaceAllString(strings.ToLower(task.Title), "-")
+	if len(slug) > maxBranchSlugSize {
+		slug = slug[:maxBranchSlugSize]
+	}
+	slug = strings.Trim(slug, "-")
+
+	if slug == "" {
+		return taskBranchPrefix + shortID
+	}
+	return taskBranchPrefix + shortID + "-" + slug
+}
+
+// taskBranchStartPoint returns the ref new task branches are created from, or "" for projects
+// without a repository. Task branches start from the project's base ref rather than whatever
+// branch a previous task left checked out.
+func taskBranchStartPoint(project *model.Project) string {
+	if project.RepoRef != "" {
+		return project.RepoRef
+	}
+	if project.RepoURL != "" {
+		return "origin/HEAD"
+	}
+	return ""
+}
+
 // StopTask stops execution of a task
 func (s *taskService) StopTask(ctx context.Context, id, userID uuid.UUID) error {
-	task, err := s.GetTask(ctx, id, userID)
+	task, err := s.writableTask(ctx, id, userID)
 	if err != nil {
 		return err
 	}
@@ -342,7 +528,7 @@ func (s *taskService) StopTask(ctx context.Context, id, userID uuid.UUID) error
 
 	var activeSessionID *uuid.UUID
 	for _, session := range activeSessions {
-		if session.Status == model.SessionStatusRunning || session.Status == model.SessionStatusPending {
+		if isActiveSessionStatus(session.Status) {
 			activeSessionID = &session.ID
 			break
 		}
@@ -377,3 +563,330 @@ func (s *taskService) GetTaskSessions(ctx context.Context, id, userID uuid.UUID)
 
 	return sessions, nil
 }
+
+// GetSessionDiff returns the workspace changes made by one of the task's sessions
+func (s *taskService) GetSessionDiff(ctx context.Context, id, sessionID, userID uuid.UUID) (*WorkspaceDiff, error) {
+	task, err := s.GetTask(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.sessionService.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.TaskID != task.ID {
+		return nil, ErrSessionNotFound
+	}
+
+	return s.sessionService.GetSessionDiff(ctx, sessionID)
+}
+
+// ContinueSession sends a follow-up prompt to one of the task's execution sessions.
+// Tasks under review or done are left to the review workflow; send them back first.
+func (s *taskService) ContinueSession(ctx context.Context, id, sessionID, userID uuid.UUID, prompt string) (*model.Session, error) {
+	// Follow-up prompts are recorded as user messages and share their limits
+	if err := validateMessageContent(prompt, MessageTypeUser); err != nil {
+		return nil, err
+	}
+
+	task, err := s.writableTask(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if task.Status != model.TaskStatusTodo && task.Status != model.TaskStatusInProgress {
+		return nil, fmt.Errorf("%w: can only continue sessions of tasks in TODO or IN_PROGRESS state, current state: %s", ErrInvalidStateTransition, task.Status)
+	}
+
+	session, err := s.sessionService.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.TaskID != task.ID {
+		return nil, ErrSessionNotFound
+	}
+
+	// The follow-up works on the task branch like the run it continues
+	unlock := s.lockProject(task.ProjectID)
+	defer unlock()
+
+	if err := s.prepareTaskBranch(ctx, task); err != nil {
+		return nil, err
+	}
+
+	session, err = s.sessionService.ContinueSession(ctx, sessionID, userID, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to continue session: %w", err)
+	}
+
+	if task.Status != model.TaskStatusInProgress {
+		task.Status = model.TaskStatusInProgress
+		if err := s.taskRepo.UpdateStatus(ctx, task.ID, task.Status); err != nil {
+			return nil, fmt.Errorf("failed to update task status: %w", err)
+		}
+	}
+
+	return session, nil
+}
+
+// SubmitReview records a human review of a task in human_review and moves the task
+// to done (approved) or back to in_progress (changes requested)
+func (s *taskService) SubmitReview(ctx context.Context, id, userID uuid.UUID, decision model.ReviewDecision, summary string, comments []model.ReviewComment) (*model.TaskReview, error) {
+	task, err := s.writableTask(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if task.Status != model.TaskStatusHumanReview {
+		return nil, fmt.Errorf("%w: can only review tasks in HUMAN_REVIEW state, current state: %s", ErrInvalidStateTransition, task.Status)
+	}
+
+	sessionID, err := s.latestSessionID(ctx, task.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := s.reviewedDiff(ctx, sessionID, comments)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateReview(decision, summary, comments, diff); err != nil {
+		return nil, err
+	}
+
+	review := &model.TaskReview{
+		TaskID:     task.ID,
+		SessionID:  sessionID,
+		ReviewerID: userID,
+		Decision:   decision,
+		Summary:    strings.TrimSpace(summary),
+		Comments:   comments,
+	}
+	if err := s.reviewRepo.Create(ctx, review); err != nil {
+		return nil, fmt.Errorf("failed to create review: %w", err)
+	}
+
+	task.Status = model.TaskStatusDone
+	if decision == model.ReviewDecisionChangesRequested {
+		task.Status = model.TaskStatusInProgress
+	}
+	if err := s.taskRepo.UpdateStatus(ctx, task.ID, task.Status); err != nil {
+		return nil, fmt.Errorf("failed to update task status: %w", err)
+	}
+
+	review.Task = task
+	return review, nil
+}
+
+// ListReviews returns the review history of a task, newest first
+func (s *taskService) ListReviews(ctx context.Context, id, userID uuid.UUID) ([]model.TaskReview, error) {
+	task, err := s.GetTask(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	reviews, err := s.reviewRepo.FindByTaskID(ctx, task.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reviews: %w", err)
+	}
+
+	return reviews, nil
+}
+
+// requireReviewDecision fails unless the task's current review carries the given decision
+func (s *taskService) requireReviewDecision(ctx context.Context, task *model.Task, decision model.ReviewDecision) error {
+	review, err := s.currentReview(ctx, task)
+	if err != nil {
+		return err
+	}
+
+	if review == nil || review.Decision != decision {
+		return fmt.Errorf("%w: moving a task out of human_review requires a review with decision %q", ErrReviewRequired, decision)
+	}
+
+	return nil
+}
+
+// currentReview returns the task's latest review when it still applies, i.e. the
+// task has not been run again since. It returns nil when there is no such review.
+func (s *taskService) currentReview(ctx context.Context, task *model.Task) (*model.TaskReview, error) {
+	review, err := s.reviewRepo.FindLatestByTaskID(ctx, task.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to retrieve latest review: %w", err)
+	}
+
+	sessionID, err := s.latestSessionID(ctx, task.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if (review.SessionID == nil) != (sessionID == nil) {
+		return nil, nil
+	}
+	if review.SessionID != nil && *review.SessionID != *sessionID {
+		return nil, nil
+	}
+
+	return review, nil
+}
+
+// currentAIReviewFailure returns the task's failed AI review verdict when it covers the
+// latest execution session, i.e. the findings have not been addressed by a new run yet
+func (s *taskService) currentAIReviewFailure(ctx context.Context, task *model.Task) (*model.AIReviewVerdict, error) {
+	verdict := task.AIReviewVerdict
+	if verdict == nil || verdict.Passed || verdict.Error != "" {
+		return nil, nil
+	}
+
+	sessionID, err := s.latestSessionID(ctx, task.ID)
+	if err != nil {
+		return nil, err
+	}
+	if sessionID == nil || *sessionID != verdict.ReviewedSessionID {
+		return nil, nil
+	}
+
+	return verdict, nil
+}
+
+// latestSessionID returns the most recent execution session of a task, or nil if it never ran.
+// Reviewer sessions are skipped: reviews always refer to the work of an execution session.
+func (s *taskService) latestSessionID(ctx context.Context, taskID uuid.UUID) (*uuid.UUID, error) {
+	sessions, err := s.sessionService.GetSessionsByTaskID(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task sessions: %w", err)
+	}
+
+	for _, session := range sessions {
+		if session.Kind != model.SessionKindReview {
+			return &session.ID, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// reviewedDiff returns the diff of the reviewed session when a comment is anchored to a file.
+// It returns nil when no comment needs it or the session recorded no diff.
+func (s *taskService) reviewedDiff(ctx context.Context, sessionID *uuid.UUID, comments []model.ReviewComment) (*WorkspaceDiff, error) {
+	anchored := false
+	for _, comment := range comments {
+		if strings.TrimSpace(comment.FilePath) != "" {
+			anchored = true
+			break
+		}
+	}
+	if !anchored || sessionID == nil {
+		return nil, nil
+	}
+
+	diff, err := s.sessionService.GetSessionDiff(ctx, *sessionID)
+	if err != nil {
+		if errors.Is(err, ErrSessionDiffUnavailable) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get session diff: %w", err)
+	}
+
+	return diff, nil
+}
+
+// validateReview checks the review decision and that comment anchors point into the reviewed diff
+func validateReview(decision model.ReviewDecision, summary string, comments []model.ReviewComment, diff *WorkspaceDiff) error {
+	switch decision {
+	case model.ReviewDecisionApproved, model.ReviewDecisionChangesRequested:
+	default:
+		return fmt.Errorf("%w: decision must be 'approved' or 'changes_requested'", ErrInvalidReview)
+	}
+
+	for i := range comments {
+		comments[i].FilePath = strings.TrimSpace(comments[i].FilePath)
+		comments[i].Body = strings.TrimSpace(comments[i].Body)
+
+		if comments[i].Body == "" {
+			return fmt.Errorf("%w: comment %d has an empty body", ErrInvalidReview, i+1)
+		}
+		if comments[i].Line < 0 {
+			return fmt.Errorf("%w: comment %d has a negative line number", ErrInvalidReview, i+1)
+		}
+		if comments[i].Line > 0 && comments[i].FilePath == "" {
+			return fmt.Errorf("%w: comment %d has a line number but no file", ErrInvalidReview, i+1)
+		}
+		if comments[i].FilePath == "" {
+			continue
+		}
+
+		if diff == nil {
+			return fmt.Errorf("%w: comment %d is anchored to a file but the reviewed session has no diff", ErrInvalidReview, i+1)
+		}
+		file := diff.File(comments[i].FilePath)
+		if file == nil {
+			return fmt.Errorf("%w: comment %d is on %s, which the reviewed session did not change", ErrInvalidReview, i+1, comments[i].FilePath)
+		}
+		if comments[i].Line > 0 && !file.HasLine(comments[i].Line) {
+			return fmt.Errorf("%w: comment %d is on line %d of %s, outside the changes", ErrInvalidReview, i+1, comments[i].Line, comments[i].FilePath)
+		}
+	}
+
+	if decision == model.ReviewDecisionChangesRequested && strings.TrimSpace(summary) == "" && len(comments) == 0 {
+		return fmt.Errorf("%w: requesting changes needs a summary or at least one comment", ErrInvalidReview)
+	}
+
+	return nil
+}
+
+// buildTaskPrompt builds the execution prompt for a task. When a human reviewer requested
+// changes or the AI review failed, the feedback is appended so the agent can address it.
+func buildTaskPrompt(task *model.Task, review *model.TaskReview, aiFailure *model.AIReviewVerdict) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Task: %s\n\nDescription:\n%s", task.Title, task.Description)
+
+	if aiFailure != nil {
+		b.WriteString("\n\nAn automated review of your previous work on this task failed. Fix every issue it reported.")
+		if aiFailure.Summary != "" {
+			fmt.Fprintf(&b, "\n\nAI review summary:\n%s", aiFailure.Summary)
+		}
+		if len(aiFailure.Findings) > 0 {
+			b.WriteString("\n\nAI review findings:")
+			for _, finding := range aiFailure.Findings {
+				switch {
+				case finding.FilePath != "" && finding.Line > 0:
+					fmt.Fprintf(&b, "\n- [%s] %s:%d: %s", finding.Severity, finding.FilePath, finding.Line, finding.Message)
+				case finding.FilePath != "":
+					fmt.Fprintf(&b, "\n- [%s] %s: %s", finding.Severity, finding.FilePath, finding.Message)
+				default:
+					fmt.Fprintf(&b, "\n- [%s] %s", finding.Severity, finding.Message)
+				}
+			}
+		}
+	}
+
+	if review == nil {
+		return b.String()
+	}
+
+	b.WriteString("\n\nA reviewer requested changes to your previous work on this task. Address all of the feedback below.")
+	if review.Summary != "" {
+		fmt.Fprintf(&b, "\n\nReview summary:\n%s", review.Summary)
+	}
+	if len(review.Comments) > 0 {
+		b.WriteString("\n\nReview comments:")
+		for _, comment := range review.Comments {
+			switch {
+			case comment.FilePath != "" && comment.Line > 0:
+				fmt.Fprintf(&b, "\n- %s:%d: %s", comment.FilePath, comment.Line, comment.Body)
+			case comment.FilePath != "":
+				fmt.Fprintf(&b, "\n- %s: %s", comment.FilePath, comment.Body)
+			default:
+				fmt.Fprintf(&b, "\n- %s", comment.Body)
+			}
+		}
+	}
+
+	return b.String()
+}
@@ -3,7 +3,10 @@ package service
 import (
 	"context"
 	"errors"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -54,6 +57,11 @@ func (m *MockTaskRepository) UpdatePosition(ctx context.Context, id uuid.UUID, n
 	return args.Error(0)
 }
 
+func (m *MockTaskRepository) AppendOutput(ctx context.Context, id uuid.UUID, sessionID uuid.UUID, chunk string) error {
+	args := m.Called(ctx, id, sessionID, chunk)
+	return args.Error(0)
+}
+
 func (m *MockTaskRepository) SoftDelete(ctx context.Context, id uuid.UUID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
@@ -78,6 +86,14 @@ func (m *MockSessionService) StopSession(ctx context.Context, sessionID uuid.UUI
 	return args.Error(0)
 }
 
+func (m *MockSessionService) ContinueSession(ctx context.Context, sessionID, userID uuid.UUID, prompt string) (*model.Session, error) {
+	args := m.Called(ctx, sessionID, userID, prompt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Session), args.Error(1)
+}
+
 func (m *MockSessionService) GetSession(ctx context.Context, sessionID uuid.UUID) (*model.Session, error) {
 	args := m.Called(ctx, sessionID)
 	if args.Get(0) == nil {
@@ -107,6 +123,11 @@ func (m *MockSessionService) UpdateSessionOutput(ctx context.Context, sessionID
 	return args.Error(0)
 }
 
+func (m *MockSessionService) UpdateSessionFinalOutput(ctx context.Context, sessionID uuid.UUID, finalOutput string) error {
+	args := m.Called(ctx, sessionID, finalOutput)
+	return args.Error(0)
+}
+
 func (m *MockSessionService) GetActiveProjectSessions(ctx context.Context, projectID uuid.UUID) ([]model.Session, error) {
 	args := m.Called(ctx, projectID)
 	if args.Get(0) == nil {
@@ -123,8 +144,76 @@ func (m *MockSessionService) GetSessionsByTaskID(ctx context.Context, taskID uui
 	return args.Get(0).([]model.Session), args.Error(1)
 }
 
+func (m *MockSessionService) GetAllActiveSessions(ctx context.Context) ([]model.Session, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Session), args.Error(1)
+}
+
+func (m *MockSessionService) UpdateSessionStatus(ctx context.Context, sessionID uuid.UUID, status string, errorMsg string) error {
+	args := m.Called(ctx, sessionID, status, errorMsg)
+	return args.Error(0)
+}
+
+func (m *MockSessionService) UpdateLastEventID(ctx context.Context, sessionID uuid.UUID, lastEventID string) error {
+	args := m.Called(ctx, sessionID, lastEventID)
+	return args.Error(0)
+}
+
+func (m *MockSessionService) ReconcileSessions(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockSessionService) EnforceSessionLimits(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockSessionService) PruneSessionSnapshots(ctx context.Context, retention time.Duration) error {
+	args := m.Called(ctx, retention)
+	return args.Error(0)
+}
+
+func (m *MockSessionService) GetSessionDiff(ctx context.Context, sessionID uuid.UUID) (*WorkspaceDiff, error) {
+	args := m.Called(ctx, sessionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*WorkspaceDiff), args.Error(1)
+}
+
 var _ SessionService = (*MockSessionService)(nil)
 
+type MockReviewRepository struct {
+	mock.Mock
+}
+
+func (m *MockReviewRepository) Create(ctx context.Context, review *model.TaskReview) error {
+	args := m.Called(ctx, review)
+	return args.Error(0)
+}
+
+func (m *MockReviewRepository) FindByTaskID(ctx context.Context, taskID uuid.UUID) ([]model.TaskReview, error) {
+	args := m.Called(ctx, taskID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.TaskReview), args.Error(1)
+}
+
+func (m *MockReviewRepository) FindLatestByTaskID(ctx context.Context, taskID uuid.UUID) (*model.TaskReview, error) {
+	args := m.Called(ctx, taskID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TaskReview), args.Error(1)
+}
+
+var _ repository.ReviewRepository = (*MockReviewRepository)(nil)
+
 func TestTaskService_CreateTask(t *testing.T) {
 	ctx := context.Background()
 	userID := uuid.New()
@@ -145,7 +234,7 @@ func TestTaskService_CreateTask(t *testing.T) {
 		mockTaskRepo.On("Create", ctx, mock.AnythingOfType("*model.Task")).Return(nil)
 
 		mockSessionService := new(MockSessionService)
-		svc := NewTaskService(mockTaskRepo, mockProjectRepo, mockSessionService)
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, nil, nil, nil)
 		task, err := svc.CreateTask(ctx, projectID, userID, "Test Task", "Description", model.TaskPriorityMedium)
 
 		assert.NoError(t, err)
@@ -165,7 +254,7 @@ func TestTaskService_CreateTask(t *testing.T) {
 		mockProjectRepo := new(MockProjectRepository)
 
 		mockSessionService := new(MockSessionService)
-		svc := NewTaskService(mockTaskRepo, mockProjectRepo, mockSessionService)
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, nil, nil, nil)
 		task, err := svc.CreateTask(ctx, projectID, userID, "", "Description", model.TaskPriorityMedium)
 
 		assert.Error(t, err)
@@ -186,7 +275,7 @@ func TestTaskService_CreateTask(t *testing.T) {
 		}
 
 		mockSessionService := new(MockSessionService)
-		svc := NewTaskService(mockTaskRepo, mockProjectRepo, mockSessionService)
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, nil, nil, nil)
 		task, err := svc.CreateTask(ctx, projectID, userID, longTitle, "Description", model.TaskPriorityMedium)
 
 		assert.Error(t, err)
@@ -200,7 +289,7 @@ func TestTaskService_CreateTask(t *testing.T) {
 		mockProjectRepo := new(MockProjectRepository)
 
 		mockSessionService := new(MockSessionService)
-		svc := NewTaskService(mockTaskRepo, mockProjectRepo, mockSessionService)
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, nil, nil, nil)
 		task, err := svc.CreateTask(ctx, projectID, userID, "Test Task", "Description", "invalid")
 
 		assert.Error(t, err)
@@ -216,7 +305,7 @@ func TestTaskService_CreateTask(t *testing.T) {
 		mockProjectRepo.On("FindByID", ctx, projectID).Return(nil, gorm.ErrRecordNotFound)
 
 		mockSessionService := new(MockSessionService)
-		svc := NewTaskService(mockTaskRepo, mockProjectRepo, mockSessionService)
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, nil, nil, nil)
 		task, err := svc.CreateTask(ctx, projectID, userID, "Test Task", "Description", model.TaskPriorityMedium)
 
 		assert.Error(t, err)
@@ -240,7 +329,7 @@ func TestTaskService_CreateTask(t *testing.T) {
 		mockProjectRepo.On("FindByID", ctx, projectID).Return(project, nil)
 
 		mockSessionService := new(MockSessionService)
-		svc := NewTaskService(mockTaskRepo, mockProjectRepo, mockSessionService)
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, nil, nil, nil)
 		task, err := svc.CreateTask(ctx, projectID, userID, "Test Task", "Description", model.TaskPriorityMedium)
 
 		assert.Error(t, err)
@@ -271,7 +360,7 @@ func TestTaskService_CreateTask(t *testing.T) {
 		mockTaskRepo.On("Create", ctx, mock.AnythingOfType("*model.Task")).Return(nil)
 
 		mockSessionService := new(MockSessionService)
-		svc := NewTaskService(mockTaskRepo, mockProjectRepo, mockSessionService)
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, nil, nil, nil)
 		task, err := svc.CreateTask(ctx, projectID, userID, "New Task", "Description", model.TaskPriorityLow)
 
 		assert.NoError(t, err)
@@ -294,7 +383,7 @@ func TestTaskService_CreateTask(t *testing.T) {
 		mockTaskRepo.On("Create", ctx, mock.AnythingOfType("*model.Task")).Return(errors.New("db error"))
 
 		mockSessionService := new(MockSessionService)
-		svc := NewTaskService(mockTaskRepo, mockProjectRepo, mockSessionService)
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, nil, nil, nil)
 		task, err := svc.CreateTask(ctx, projectID, userID, "Test Task", "Description", model.TaskPriorityHigh)
 
 		assert.Error(t, err)
@@ -329,7 +418,7 @@ func TestTaskService_GetTask(t *testing.T) {
 		mockProjectRepo.On("FindByID", ctx, projectID).Return(project, nil)
 
 		mockSessionService := new(MockSessionService)
-		svc := NewTaskService(mockTaskRepo, mockProjectRepo, mockSessionService)
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, nil, nil, nil)
 		result, err := svc.GetTask(ctx, taskID, userID)
 
 		assert.NoError(t, err)
@@ -347,7 +436,7 @@ func TestTaskService_GetTask(t *testing.T) {
 		mockTaskRepo.On("FindByID", ctx, taskID).Return(nil, gorm.ErrRecordNotFound)
 
 		mockSessionService := new(MockSessionService)
-		svc := NewTaskService(mockTaskRepo, mockProjectRepo, mockSessionService)
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, nil, nil, nil)
 		result, err := svc.GetTask(ctx, taskID, userID)
 
 		assert.Error(t, err)
@@ -377,7 +466,7 @@ func TestTaskService_GetTask(t *testing.T) {
 		mockProjectRepo.On("FindByID", ctx, projectID).Return(project, nil)
 
 		mockSessionService := new(MockSessionService)
-		svc := NewTaskService(mockTaskRepo, mockProjectRepo, mockSessionService)
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, nil, nil, nil)
 		result, err := svc.GetTask(ctx, taskID, userID)
 
 		assert.Error(t, err)
@@ -409,7 +498,7 @@ func TestTaskService_ListProjectTasks(t *testing.T) {
 		mockTaskRepo.On("FindByProjectID", ctx, projectID).Return(tasks, nil)
 
 		mockSessionService := new(MockSessionService)
-		svc := NewTaskService(mockTaskRepo, mockProjectRepo, mockSessionService)
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, nil, nil, nil)
 		result, err := svc.ListProjectTasks(ctx, projectID, userID)
 
 		assert.NoError(t, err)
@@ -431,7 +520,7 @@ func TestTaskService_ListProjectTasks(t *testing.T) {
 		mockTaskRepo.On("FindByProjectID", ctx, projectID).Return([]model.Task{}, nil)
 
 		mockSessionService := new(MockSessionService)
-		svc := NewTaskService(mockTaskRepo, mockProjectRepo, mockSessionService)
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, nil, nil, nil)
 		result, err := svc.ListProjectTasks(ctx, projectID, userID)
 
 		assert.NoError(t, err)
@@ -445,7 +534,7 @@ func TestTaskService_ListProjectTasks(t *testing.T) {
 		mockProjectRepo.On("FindByID", ctx, projectID).Return(nil, gorm.ErrRecordNotFound)
 
 		mockSessionService := new(MockSessionService)
-		svc := NewTaskService(mockTaskRepo, mockProjectRepo, mockSessionService)
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, nil, nil, nil)
 		result, err := svc.ListProjectTasks(ctx, projectID, userID)
 
 		assert.Error(t, err)
@@ -466,7 +555,7 @@ func TestTaskService_ListProjectTasks(t *testing.T) {
 		mockProjectRepo.On("FindByID", ctx, projectID).Return(project, nil)
 
 		mockSessionService := new(MockSessionService)
-		svc := NewTaskService(mockTaskRepo, mockProjectRepo, mockSessionService)
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, nil, nil, nil)
 		result, err := svc.ListProjectTasks(ctx, projectID, userID)
 
 		assert.Error(t, err)
@@ -501,7 +590,7 @@ func TestTaskService_UpdateTask(t *testing.T) {
 		mockTaskRepo.On("Update", ctx, mock.AnythingOfType("*model.Task")).Return(nil)
 
 		mockSessionService := new(MockSessionService)
-		svc := NewTaskService(mockTaskRepo, mockProjectRepo, mockSessionService)
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, nil, nil, nil)
 		updates := map[string]interface{}{"title": "New Title"}
 		result, err := svc.UpdateTask(ctx, taskID, userID, updates)
 
@@ -530,7 +619,7 @@ func TestTaskService_UpdateTask(t *testing.T) {
 		mockTaskRepo.On("Update", ctx, mock.AnythingOfType("*model.Task")).Return(nil)
 
 		mockSessionService := new(MockSessionService)
-		svc := NewTaskService(mockTaskRepo, mockProjectRepo, mockSessionService)
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, nil, nil, nil)
 		updates := map[string]interface{}{"priority": "high"}
 		result, err := svc.UpdateTask(ctx, taskID, userID, updates)
 
@@ -558,7 +647,7 @@ func TestTaskService_UpdateTask(t *testing.T) {
 		mockProjectRepo.On("FindByID", ctx, projectID).Return(project, nil)
 
 		mockSessionService := new(MockSessionService)
-		svc := NewTaskService(mockTaskRepo, mockProjectRepo, mockSessionService)
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, nil, nil, nil)
 		updates := map[string]interface{}{"title": ""}
 		result, err := svc.UpdateTask(ctx, taskID, userID, updates)
 
@@ -587,7 +676,7 @@ func TestTaskService_UpdateTask(t *testing.T) {
 		mockProjectRepo.On("FindByID", ctx, projectID).Return(project, nil)
 
 		mockSessionService := new(MockSessionService)
-		svc := NewTaskService(mockTaskRepo, mockProjectRepo, mockSessionService)
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, nil, nil, nil)
 		updates := map[string]interface{}{"priority": "invalid"}
 		result, err := svc.UpdateTask(ctx, taskID, userID, updates)
 
@@ -624,7 +713,7 @@ func TestTaskService_MoveTask(t *testing.T) {
 		mockTaskRepo.On("Update", ctx, mock.AnythingOfType("*model.Task")).Return(nil)
 
 		mockSessionService := new(MockSessionService)
-		svc := NewTaskService(mockTaskRepo, mockProjectRepo, mockSessionService)
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, nil, nil, nil)
 		result, err := svc.MoveTask(ctx, taskID, userID, model.TaskStatusInProgress, 0)
 
 		assert.NoError(t, err)
@@ -653,7 +742,7 @@ func TestTaskService_MoveTask(t *testing.T) {
 		mockProjectRepo.On("FindByID", ctx, projectID).Return(project, nil)
 
 		mockSessionService := new(MockSessionService)
-		svc := NewTaskService(mockTaskRepo, mockProjectRepo, mockSessionService)
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, nil, nil, nil)
 		result, err := svc.MoveTask(ctx, taskID, userID, model.TaskStatusDone, 0)
 
 		assert.Error(t, err)
@@ -684,7 +773,7 @@ func TestTaskService_MoveTask(t *testing.T) {
 		mockTaskRepo.On("Update", ctx, mock.AnythingOfType("*model.Task")).Return(nil)
 
 		mockSessionService := new(MockSessionService)
-		svc := NewTaskService(mockTaskRepo, mockProjectRepo, mockSessionService)
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, nil, nil, nil)
 		result, err := svc.MoveTask(ctx, taskID, userID, model.TaskStatusTodo, 2)
 
 		assert.NoError(t, err)
@@ -719,7 +808,7 @@ func TestTaskService_DeleteTask(t *testing.T) {
 		mockTaskRepo.On("SoftDelete", ctx, taskID).Return(nil)
 
 		mockSessionService := new(MockSessionService)
-		svc := NewTaskService(mockTaskRepo, mockProjectRepo, mockSessionService)
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, nil, nil, nil)
 		err := svc.DeleteTask(ctx, taskID, userID)
 
 		assert.NoError(t, err)
@@ -734,7 +823,7 @@ func TestTaskService_DeleteTask(t *testing.T) {
 		mockTaskRepo.On("FindByID", ctx, taskID).Return(nil, gorm.ErrRecordNotFound)
 
 		mockSessionService := new(MockSessionService)
-		svc := NewTaskService(mockTaskRepo, mockProjectRepo, mockSessionService)
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, nil, nil, nil)
 		err := svc.DeleteTask(ctx, taskID, userID)
 
 		assert.Error(t, err)
@@ -761,7 +850,7 @@ func TestTaskService_DeleteTask(t *testing.T) {
 		mockProjectRepo.On("FindByID", ctx, projectID).Return(project, nil)
 
 		mockSessionService := new(MockSessionService)
-		svc := NewTaskService(mockTaskRepo, mockProjectRepo, mockSessionService)
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, nil, nil, nil)
 		err := svc.DeleteTask(ctx, taskID, userID)
 
 		assert.Error(t, err)
@@ -849,3 +938,835 @@ func TestIsValidTransition(t *testing.T) {
 		})
 	}
 }
+
+func TestTaskService_ExecuteTask_Branch(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	projectID := uuid.New()
+	taskID := uuid.MustParse("1234abcd-0000-0000-0000-000000000000")
+
+	newTask := func() *model.Task {
+		return &model.Task{
+			ID:        taskID,
+			ProjectID: projectID,
+			Title:     "Fix login bug",
+			Status:    model.TaskStatusTodo,
+		}
+	}
+	project := &model.Project{
+		ID:      projectID,
+		UserID:  userID,
+		RepoURL: "https://github.com/example/repo.git",
+	}
+
+	t.Run("creates and records task branch", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		mockProjectRepo := new(MockProjectRepository)
+		mockSessionService := new(MockSessionService)
+		mockGitService := new(MockWorkspaceGitService)
+		mockReviewRepo := new(MockReviewRepository)
+		mockReviewRepo.On("FindLatestByTaskID", ctx, taskID).Return(nil, gorm.ErrRecordNotFound)
+
+		task := newTask()
+		mockTaskRepo.On("FindByID", ctx, taskID).Return(task, nil)
+		mockProjectRepo.On("FindByID", ctx, projectID).Return(project, nil)
+		mockSessionService.On("GetActiveProjectSessions", ctx, projectID).Return([]model.Session{}, nil)
+		mockGitService.On("PrepareBranch", ctx, project, "task/1234abcd-fix-login-bug", "origin/HEAD").Return(nil)
+		mockTaskRepo.On("Update", ctx, mock.MatchedBy(func(t *model.Task) bool {
+			return t.BranchName == "task/1234abcd-fix-login-bug"
+		})).Return(nil)
+		mockSessionService.On("StartSession", ctx, taskID, mock.AnythingOfType("string")).Return(&model.Session{ID: uuid.New()}, nil)
+		mockTaskRepo.On("UpdateStatus", ctx, taskID, model.TaskStatusInProgress).Return(nil)
+
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, mockGitService, mockReviewRepo, nil)
+		session, err := svc.ExecuteTask(ctx, taskID, userID)
+
+		assert.NoError(t, err)
+		assert.NotNil(t, session)
+		assert.Equal(t, "task/1234abcd-fix-login-bug", task.BranchName)
+		mockGitService.AssertExpectations(t)
+		mockTaskRepo.AssertExpectations(t)
+	})
+
+	t.Run("reuses existing task branch", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		mockProjectRepo := new(MockProjectRepository)
+		mockSessionService := new(MockSessionService)
+		mockGitService := new(MockWorkspaceGitService)
+		mockReviewRepo := new(MockReviewRepository)
+		mockReviewRepo.On("FindLatestByTaskID", ctx, taskID).Return(nil, gorm.ErrRecordNotFound)
+
+		task := newTask()
+		task.BranchName = "task/1234abcd-old-title"
+		mockTaskRepo.On("FindByID", ctx, taskID).Return(task, nil)
+		mockProjectRepo.On("FindByID", ctx, projectID).Return(project, nil)
+		mockSessionService.On("GetActiveProjectSessions", ctx, projectID).Return([]model.Session{}, nil)
+		mockGitService.On("PrepareBranch", ctx, project, "task/1234abcd-old-title", "origin/HEAD").Return(nil)
+		mockSessionService.On("StartSession", ctx, taskID, mock.AnythingOfType("string")).Return(&model.Session{ID: uuid.New()}, nil)
+		mockTaskRepo.On("UpdateStatus", ctx, taskID, model.TaskStatusInProgress).Return(nil)
+
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, mockGitService, mockReviewRepo, nil)
+		_, err := svc.ExecuteTask(ctx, taskID, userID)
+
+		assert.NoError(t, err)
+		mockTaskRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("workspace without repository", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		mockProjectRepo := new(MockProjectRepository)
+		mockSessionService := new(MockSessionService)
+		mockGitService := new(MockWorkspaceGitService)
+		mockReviewRepo := new(MockReviewRepository)
+		mockReviewRepo.On("FindLatestByTaskID", ctx, taskID).Return(nil, gorm.ErrRecordNotFound)
+
+		task := newTask()
+		mockTaskRepo.On("FindByID", ctx, taskID).Return(task, nil)
+		mockProjectRepo.On("FindByID", ctx, projectID).Return(project, nil)
+		mockSessionService.On("GetActiveProjectSessions", ctx, projectID).Return([]model.Session{}, nil)
+		mockGitService.On("PrepareBranch", ctx, project, mock.Anything, mock.Anything).Return(ErrWorkspaceNotRepository)
+		mockSessionService.On("StartSession", ctx, taskID, mock.AnythingOfType("string")).Return(&model.Session{ID: uuid.New()}, nil)
+		mockTaskRepo.On("UpdateStatus", ctx, taskID, model.TaskStatusInProgress).Return(nil)
+
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, mockGitService, mockReviewRepo, nil)
+		_, err := svc.ExecuteTask(ctx, taskID, userID)
+
+		assert.NoError(t, err)
+		assert.Empty(t, task.BranchName)
+	})
+
+	t.Run("project without repository", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		mockProjectRepo := new(MockProjectRepository)
+		mockSessionService := new(MockSessionService)
+		mockGitService := new(MockWorkspaceGitService)
+		mockReviewRepo := new(MockReviewRepository)
+		mockReviewRepo.On("FindLatestByTaskID", ctx, taskID).Return(nil, gorm.ErrRecordNotFound)
+
+		task := newTask()
+		mockTaskRepo.On("FindByID", ctx, taskID).Return(task, nil)
+		mockProjectRepo.On("FindByID", ctx, projectID).Return(&model.Project{ID: projectID, UserID: userID}, nil)
+		mockSessionService.On("GetActiveProjectSessions", ctx, projectID).Return([]model.Session{}, nil)
+		mockSessionService.On("StartSession", ctx, taskID, mock.AnythingOfType("string")).Return(&model.Session{ID: uuid.New()}, nil)
+		mockTaskRepo.On("UpdateStatus", ctx, taskID, model.TaskStatusInProgress).Return(nil)
+
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, mockGitService, mockReviewRepo, nil)
+		_, err := svc.ExecuteTask(ctx, taskID, userID)
+
+		assert.NoError(t, err)
+		assert.Empty(t, task.BranchName)
+		mockGitService.AssertNotCalled(t, "PrepareBranch", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("concurrent runs of two tasks", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		mockProjectRepo := new(MockProjectRepository)
+		sessionService := &startingSessionService{MockSessionService: new(MockSessionService)}
+		mockGitService := new(MockWorkspaceGitService)
+		mockReviewRepo := new(MockReviewRepository)
+		mockReviewRepo.On("FindLatestByTaskID", ctx, mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+
+		other := &model.Task{ID: uuid.New(), ProjectID: projectID, Title: "Other", Status: model.TaskStatusTodo}
+		mockTaskRepo.On("FindByID", ctx, taskID).Return(newTask(), nil)
+		mockTaskRepo.On("FindByID", ctx, other.ID).Return(other, nil)
+		mockTaskRepo.On("Update", ctx, mock.Anything).Return(nil)
+		mockTaskRepo.On("UpdateStatus", ctx, mock.Anything, model.TaskStatusInProgress).Return(nil)
+		mockProjectRepo.On("FindByID", ctx, projectID).Return(project, nil)
+		// A slow checkout leaves room for the other run to pass the busy check
+		mockGitService.On("PrepareBranch", ctx, project, mock.Anything, "origin/HEAD").After(50 * time.Millisecond).Return(nil)
+
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), sessionService, mockGitService, mockReviewRepo, nil)
+
+		errs := make(chan error, 2)
+		for _, id := range []uuid.UUID{taskID, other.ID} {
+			go func(id uuid.UUID) {
+				_, err := svc.ExecuteTask(ctx, id, userID)
+				errs <- err
+			}(id)
+		}
+
+		var busy int
+		for i := 0; i < 2; i++ {
+			if err := <-errs; err != nil {
+				assert.ErrorIs(t, err, ErrWorkspaceBusy)
+				busy++
+			}
+		}
+		assert.Equal(t, 1, busy)
+		mockGitService.AssertNumberOfCalls(t, "PrepareBranch", 1)
+	})
+
+	t.Run("workspace busy with another task", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		mockProjectRepo := new(MockProjectRepository)
+		mockSessionService := new(MockSessionService)
+		mockGitService := new(MockWorkspaceGitService)
+		mockReviewRepo := new(MockReviewRepository)
+		mockReviewRepo.On("FindLatestByTaskID", ctx, taskID).Return(nil, gorm.ErrRecordNotFound)
+
+		mockTaskRepo.On("FindByID", ctx, taskID).Return(newTask(), nil)
+		mockProjectRepo.On("FindByID", ctx, projectID).Return(project, nil)
+		mockSessionService.On("GetActiveProjectSessions", ctx, projectID).Return([]model.Session{
+			{ID: uuid.New(), TaskID: uuid.New(), Status: model.SessionStatusRunning},
+		}, nil)
+
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, mockGitService, mockReviewRepo, nil)
+		_, err := svc.ExecuteTask(ctx, taskID, userID)
+
+		assert.ErrorIs(t, err, ErrWorkspaceBusy)
+		mockGitService.AssertNotCalled(t, "PrepareBranch", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockSessionService.AssertNotCalled(t, "StartSession", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// startingSessionService reports the sessions it started as active
+type startingSessionService struct {
+	*MockSessionService
+
+	mu     sync.Mutex
+	active []model.Session
+}
+
+func (s *startingSessionService) GetActiveProjectSessions(ctx context.Context, projectID uuid.UUID) ([]model.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]model.Session(nil), s.active...), nil
+}
+
+func (s *startingSessionService) StartSession(ctx context.Context, taskID uuid.UUID, prompt string) (*model.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session := model.Session{ID: uuid.New(), TaskID: taskID, Status: model.SessionStatusPending}
+	s.active = append(s.active, session)
+	return &session, nil
+}
+
+func TestTaskBranchName(t *testing.T) {
+	id := uuid.MustParse("1234abcd-0000-0000-0000-000000000000")
+
+	tests := []struct {
+		name  string
+		title string
+		want  string
+	}{
+		{"simple title", "Fix login bug", "task/1234abcd-fix-login-bug"},
+		{"punctuation collapsed", "Add OAuth2 (Google) & GitHub!", "task/1234abcd-add-oauth2-google-github"},
+		{"no usable characters", "!!!", "task/1234abcd"},
+		{"long title truncated", "Refactor the entire authentication subsystem to use sessions", "task/1234abcd-refactor-the-entire-authentication-subsy"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := taskBranchName(&model.Task{ID: id, Title: tc.title})
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestTaskBranchStartPoint(t *testing.T) {
+	assert.Equal(t, "develop", taskBranchStartPoint(&model.Project{RepoURL: "https://example.com/r.git", RepoRef: "develop"}))
+	assert.Equal(t, "origin/HEAD", taskBranchStartPoint(&model.Project{RepoURL: "https://example.com/r.git"}))
+	assert.Equal(t, "", taskBranchStartPoint(&model.Project{}))
+}
+
+func TestTaskService_GetSessionDiff(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	projectID := uuid.New()
+	taskID := uuid.New()
+
+	setup := func() (*MockTaskRepository, *MockProjectRepository, *MockSessionService) {
+		mockTaskRepo := new(MockTaskRepository)
+		mockProjectRepo := new(MockProjectRepository)
+		mockSessionService := new(MockSessionService)
+
+		mockTaskRepo.On("FindByID", ctx, taskID).Return(&model.Task{ID: taskID, ProjectID: projectID}, nil)
+		mockProjectRepo.On("FindByID", ctx, projectID).Return(&model.Project{ID: projectID, UserID: userID}, nil)
+
+		return mockTaskRepo, mockProjectRepo, mockSessionService
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockTaskRepo, mockProjectRepo, mockSessionService := setup()
+		sessionID := uuid.New()
+
+		expected := &WorkspaceDiff{FilesChanged: 1}
+		mockSessionService.On("GetSession", ctx, sessionID).Return(&model.Session{ID: sessionID, TaskID: taskID}, nil)
+		mockSessionService.On("GetSessionDiff", ctx, sessionID).Return(expected, nil)
+
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, nil, nil, nil)
+		diff, err := svc.GetSessionDiff(ctx, taskID, sessionID, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, diff)
+	})
+
+	t.Run("session belongs to another task", func(t *testing.T) {
+		mockTaskRepo, mockProjectRepo, mockSessionService := setup()
+		sessionID := uuid.New()
+
+		mockSessionService.On("GetSession", ctx, sessionID).Return(&model.Session{ID: sessionID, TaskID: uuid.New()}, nil)
+
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, nil, nil, nil)
+		_, err := svc.GetSessionDiff(ctx, taskID, sessionID, userID)
+
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+		mockSessionService.AssertNotCalled(t, "GetSessionDiff", mock.Anything, mock.Anything)
+	})
+}
+
+func TestTaskService_ContinueSession(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	projectID := uuid.New()
+	taskID := uuid.New()
+	sessionID := uuid.New()
+
+	setup := func(status model.TaskStatus) (*MockTaskRepository, *MockSessionService, TaskService) {
+		mockTaskRepo := new(MockTaskRepository)
+		mockProjectRepo := new(MockProjectRepository)
+		mockSessionService := new(MockSessionService)
+
+		mockTaskRepo.On("FindByID", ctx, taskID).Return(&model.Task{ID: taskID, ProjectID: projectID, Status: status}, nil)
+		mockProjectRepo.On("FindByID", ctx, projectID).Return(&model.Project{ID: projectID, UserID: userID}, nil)
+
+		return mockTaskRepo, mockSessionService, NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, nil, nil, nil)
+	}
+
+	t.Run("moves task back to in progress", func(t *testing.T) {
+		mockTaskRepo, mockSessionService, svc := setup(model.TaskStatusTodo)
+
+		continued := &model.Session{ID: sessionID, TaskID: taskID, Status: model.SessionStatusRunning}
+		mockSessionService.On("GetSession", ctx, sessionID).Return(&model.Session{ID: sessionID, TaskID: taskID, Status: model.SessionStatusFailed}, nil)
+		mockSessionService.On("ContinueSession", ctx, sessionID, userID, "Fix the failing test").Return(continued, nil)
+		mockTaskRepo.On("UpdateStatus", ctx, taskID, model.TaskStatusInProgress).Return(nil)
+
+		session, err := svc.ContinueSession(ctx, taskID, sessionID, userID, "Fix the failing test")
+
+		assert.NoError(t, err)
+		assert.Equal(t, continued, session)
+		mockTaskRepo.AssertExpectations(t)
+	})
+
+	t.Run("task already in progress", func(t *testing.T) {
+		mockTaskRepo, mockSessionService, svc := setup(model.TaskStatusInProgress)
+
+		mockSessionService.On("GetSession", ctx, sessionID).Return(&model.Session{ID: sessionID, TaskID: taskID}, nil)
+		mockSessionService.On("ContinueSession", ctx, sessionID, userID, "Keep going").Return(&model.Session{ID: sessionID}, nil)
+
+		_, err := svc.ContinueSession(ctx, taskID, sessionID, userID, "Keep going")
+
+		assert.NoError(t, err)
+		mockTaskRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("task under review", func(t *testing.T) {
+		_, mockSessionService, svc := setup(model.TaskStatusHumanReview)
+
+		_, err := svc.ContinueSession(ctx, taskID, sessionID, userID, "Keep going")
+
+		assert.ErrorIs(t, err, ErrInvalidStateTransition)
+		mockSessionService.AssertNotCalled(t, "ContinueSession", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("session belongs to another task", func(t *testing.T) {
+		_, mockSessionService, svc := setup(model.TaskStatusInProgress)
+
+		mockSessionService.On("GetSession", ctx, sessionID).Return(&model.Session{ID: sessionID, TaskID: uuid.New()}, nil)
+
+		_, err := svc.ContinueSession(ctx, taskID, sessionID, userID, "Keep going")
+
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+		mockSessionService.AssertNotCalled(t, "ContinueSession", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("empty prompt", func(t *testing.T) {
+		_, mockSessionService, svc := setup(model.TaskStatusInProgress)
+
+		_, err := svc.ContinueSession(ctx, taskID, sessionID, userID, "   ")
+
+		assert.ErrorIs(t, err, ErrInvalidMessageContent)
+		mockSessionService.AssertNotCalled(t, "GetSession", mock.Anything, mock.Anything)
+	})
+
+	t.Run("session cannot be continued", func(t *testing.T) {
+		mockTaskRepo, mockSessionService, svc := setup(model.TaskStatusTodo)
+
+		mockSessionService.On("GetSession", ctx, sessionID).Return(&model.Session{ID: sessionID, TaskID: taskID}, nil)
+		mockSessionService.On("ContinueSession", ctx, sessionID, userID, "Keep going").Return(nil, ErrSessionNotContinuable)
+
+		_, err := svc.ContinueSession(ctx, taskID, sessionID, userID, "Keep going")
+
+		assert.ErrorIs(t, err, ErrSessionNotContinuable)
+		mockTaskRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestTaskService_SubmitReview(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	projectID := uuid.New()
+	taskID := uuid.New()
+	sessionID := uuid.New()
+
+	setup := func(status model.TaskStatus) (*MockTaskRepository, *MockReviewRepository, TaskService) {
+		mockTaskRepo := new(MockTaskRepository)
+		mockProjectRepo := new(MockProjectRepository)
+		mockSessionService := new(MockSessionService)
+		mockReviewRepo := new(MockReviewRepository)
+
+		mockTaskRepo.On("FindByID", ctx, taskID).Return(&model.Task{ID: taskID, ProjectID: projectID, Status: status}, nil)
+		mockProjectRepo.On("FindByID", ctx, projectID).Return(&model.Project{ID: projectID, UserID: userID}, nil)
+		mockSessionService.On("GetSessionsByTaskID", ctx, taskID).Return([]model.Session{{ID: sessionID}}, nil)
+		mockSessionService.On("GetSessionDiff", ctx, sessionID).Return(&WorkspaceDiff{Files: []WorkspaceFileDiff{
+			{Path: "main.go", Status: "modified", Patch: "@@ -8,4 +8,5 @@ func main() {\n \tx := run()\n+\tcheck(x)\n"},
+			{Path: "logo.png", Status: "added", Binary: true},
+		}}, nil).Maybe()
+
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, nil, mockReviewRepo, nil)
+		return mockTaskRepo, mockReviewRepo, svc
+	}
+
+	t.Run("approve moves task to done", func(t *testing.T) {
+		mockTaskRepo, mockReviewRepo, svc := setup(model.TaskStatusHumanReview)
+		mockReviewRepo.On("Create", ctx, mock.MatchedBy(func(r *model.TaskReview) bool {
+			return r.Decision == model.ReviewDecisionApproved && r.ReviewerID == userID && *r.SessionID == sessionID
+		})).Return(nil)
+		mockTaskRepo.On("UpdateStatus", ctx, taskID, model.TaskStatusDone).Return(nil)
+
+		review, err := svc.SubmitReview(ctx, taskID, userID, model.ReviewDecisionApproved, "LGTM", nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.TaskStatusDone, review.Task.Status)
+		mockTaskRepo.AssertExpectations(t)
+	})
+
+	t.Run("request changes moves task back to in_progress", func(t *testing.T) {
+		mockTaskRepo, mockReviewRepo, svc := setup(model.TaskStatusHumanReview)
+		mockReviewRepo.On("Create", ctx, mock.AnythingOfType("*model.TaskReview")).Return(nil)
+		mockTaskRepo.On("UpdateStatus", ctx, taskID, model.TaskStatusInProgress).Return(nil)
+
+		comments := []model.ReviewComment{{FilePath: " main.go ", Line: 10, Body: " Handle the error "}}
+		review, err := svc.SubmitReview(ctx, taskID, userID, model.ReviewDecisionChangesRequested, "", comments)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.TaskStatusInProgress, review.Task.Status)
+		assert.Equal(t, "main.go", review.Comments[0].FilePath)
+		assert.Equal(t, "Handle the error", review.Comments[0].Body)
+	})
+
+	t.Run("file comments without a recorded diff", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		mockProjectRepo := new(MockProjectRepository)
+		mockSessionService := new(MockSessionService)
+		mockReviewRepo := new(MockReviewRepository)
+
+		mockTaskRepo.On("FindByID", ctx, taskID).Return(&model.Task{ID: taskID, ProjectID: projectID, Status: model.TaskStatusHumanReview}, nil)
+		mockProjectRepo.On("FindByID", ctx, projectID).Return(&model.Project{ID: projectID, UserID: userID}, nil)
+		mockSessionService.On("GetSessionsByTaskID", ctx, taskID).Return([]model.Session{{ID: sessionID}}, nil)
+		mockSessionService.On("GetSessionDiff", ctx, sessionID).Return(nil, ErrSessionDiffUnavailable)
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, nil, mockReviewRepo, nil)
+
+		comments := []model.ReviewComment{{FilePath: "main.go", Line: 10, Body: "Handle the error"}}
+		_, err := svc.SubmitReview(ctx, taskID, userID, model.ReviewDecisionChangesRequested, "", comments)
+
+		assert.ErrorIs(t, err, ErrInvalidReview)
+		mockReviewRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("task not in human review", func(t *testing.T) {
+		_, mockReviewRepo, svc := setup(model.TaskStatusAIReview)
+
+		_, err := svc.SubmitReview(ctx, taskID, userID, model.ReviewDecisionApproved, "", nil)
+
+		assert.ErrorIs(t, err, ErrInvalidStateTransition)
+		mockReviewRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid reviews", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			decision model.ReviewDecision
+			summary  string
+			comments []model.ReviewComment
+		}{
+			{"unknown decision", "maybe", "", nil},
+			{"changes requested without feedback", model.ReviewDecisionChangesRequested, "  ", nil},
+			{"empty comment", model.ReviewDecisionApproved, "", []model.ReviewComment{{FilePath: "a.go", Body: " "}}},
+			{"line without file", model.ReviewDecisionChangesRequested, "", []model.ReviewComment{{Line: 3, Body: "fix"}}},
+			{"negative line", model.ReviewDecisionChangesRequested, "", []model.ReviewComment{{FilePath: "a.go", Line: -1, Body: "fix"}}},
+			{"file outside the diff", model.ReviewDecisionChangesRequested, "", []model.ReviewComment{{FilePath: "a.go", Body: "fix"}}},
+			{"line outside the changes", model.ReviewDecisionChangesRequested, "", []model.ReviewComment{{FilePath: "main.go", Line: 13, Body: "fix"}}},
+			{"line in binary file", model.ReviewDecisionChangesRequested, "", []model.ReviewComment{{FilePath: "logo.png", Line: 1, Body: "fix"}}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, mockReviewRepo, svc := setup(model.TaskStatusHumanReview)
+
+				_, err := svc.SubmitReview(ctx, taskID, userID, tt.decision, tt.summary, tt.comments)
+
+				assert.ErrorIs(t, err, ErrInvalidReview)
+				mockReviewRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+			})
+		}
+	})
+}
+
+func TestTaskService_MoveTask_ReviewGate(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	projectID := uuid.New()
+	taskID := uuid.New()
+	sessionID := uuid.New()
+
+	tests := []struct {
+		name     string
+		review   *model.TaskReview
+		newState model.TaskStatus
+		wantErr  error
+	}{
+		{"done without review", nil, model.TaskStatusDone, ErrReviewRequired},
+		{"done with approval", &model.TaskReview{Decision: model.ReviewDecisionApproved, SessionID: &sessionID}, model.TaskStatusDone, nil},
+		{"done with changes requested", &model.TaskReview{Decision: model.ReviewDecisionChangesRequested, SessionID: &sessionID}, model.TaskStatusDone, ErrReviewRequired},
+		{"in_progress with changes requested", &model.TaskReview{Decision: model.ReviewDecisionChangesRequested, SessionID: &sessionID}, model.TaskStatusInProgress, nil},
+		{"done with approval of an earlier session", &model.TaskReview{Decision: model.ReviewDecisionApproved, SessionID: &[]uuid.UUID{uuid.New()}[0]}, model.TaskStatusDone, ErrReviewRequired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTaskRepo := new(MockTaskRepository)
+			mockProjectRepo := new(MockProjectRepository)
+			mockSessionService := new(MockSessionService)
+			mockReviewRepo := new(MockReviewRepository)
+
+			mockTaskRepo.On("FindByID", ctx, taskID).Return(&model.Task{ID: taskID, ProjectID: projectID, Status: model.TaskStatusHumanReview}, nil)
+			mockProjectRepo.On("FindByID", ctx, projectID).Return(&model.Project{ID: projectID, UserID: userID}, nil)
+			mockSessionService.On("GetSessionsByTaskID", ctx, taskID).Return([]model.Session{{ID: sessionID}}, nil)
+			if tt.review != nil {
+				mockReviewRepo.On("FindLatestByTaskID", ctx, taskID).Return(tt.review, nil)
+			} else {
+				mockReviewRepo.On("FindLatestByTaskID", ctx, taskID).Return(nil, gorm.ErrRecordNotFound)
+			}
+			mockTaskRepo.On("Update", ctx, mock.AnythingOfType("*model.Task")).Return(nil)
+
+			svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, nil, mockReviewRepo, nil)
+			result, err := svc.MoveTask(ctx, taskID, userID, tt.newState, 0)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				mockTaskRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.newState, result.Status)
+		})
+	}
+}
+
+func TestTaskService_ExecuteTask_ReviewFeedback(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	projectID := uuid.New()
+	taskID := uuid.New()
+	sessionID := uuid.New()
+
+	review := &model.TaskReview{
+		SessionID: &sessionID,
+		Decision:  model.ReviewDecisionChangesRequested,
+		Summary:   "Error handling is missing",
+		Comments: []model.ReviewComment{
+			{FilePath: "main.go", Line: 42, Body: "Check the returned error"},
+		},
+	}
+
+	t.Run("re-runs task sent back by reviewer with feedback", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		mockProjectRepo := new(MockProjectRepository)
+		mockSessionService := new(MockSessionService)
+		mockReviewRepo := new(MockReviewRepository)
+
+		mockTaskRepo.On("FindByID", ctx, taskID).Return(&model.Task{ID: taskID, ProjectID: projectID, Title: "Add API", Status: model.TaskStatusInProgress}, nil)
+		mockProjectRepo.On("FindByID", ctx, projectID).Return(&model.Project{ID: projectID, UserID: userID}, nil)
+		mockReviewRepo.On("FindLatestByTaskID", ctx, taskID).Return(review, nil)
+		mockSessionService.On("GetSessionsByTaskID", ctx, taskID).Return([]model.Session{{ID: sessionID}}, nil)
+		mockSessionService.On("StartSession", ctx, taskID, mock.MatchedBy(func(prompt string) bool {
+			return strings.Contains(prompt, "Error handling is missing") &&
+				strings.Contains(prompt, "- main.go:42: Check the returned error")
+		})).Return(&model.Session{ID: uuid.New()}, nil)
+		mockTaskRepo.On("UpdateStatus", ctx, taskID, model.TaskStatusInProgress).Return(nil)
+
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, nil, mockReviewRepo, nil)
+		_, err := svc.ExecuteTask(ctx, taskID, userID)
+
+		assert.NoError(t, err)
+		mockSessionService.AssertExpectations(t)
+	})
+
+	t.Run("feedback already addressed by a later session", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		mockProjectRepo := new(MockProjectRepository)
+		mockSessionService := new(MockSessionService)
+		mockReviewRepo := new(MockReviewRepository)
+
+		mockTaskRepo.On("FindByID", ctx, taskID).Return(&model.Task{ID: taskID, ProjectID: projectID, Status: model.TaskStatusInProgress}, nil)
+		mockProjectRepo.On("FindByID", ctx, projectID).Return(&model.Project{ID: projectID, UserID: userID}, nil)
+		mockReviewRepo.On("FindLatestByTaskID", ctx, taskID).Return(review, nil)
+		mockSessionService.On("GetSessionsByTaskID", ctx, taskID).Return([]model.Session{{ID: uuid.New()}, {ID: sessionID}}, nil)
+
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, nil, mockReviewRepo, nil)
+		_, err := svc.ExecuteTask(ctx, taskID, userID)
+
+		assert.ErrorIs(t, err, ErrInvalidStateTransition)
+		mockSessionService.AssertNotCalled(t, "StartSession", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("re-runs task rejected by AI reviewer with findings", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		mockProjectRepo := new(MockProjectRepository)
+		mockSessionService := new(MockSessionService)
+		mockReviewRepo := new(MockReviewRepository)
+
+		mockTaskRepo.On("FindByID", ctx, taskID).Return(&model.Task{
+			ID:        taskID,
+			ProjectID: projectID,
+			Title:     "Add API",
+			Status:    model.TaskStatusInProgress,
+			AIReviewVerdict: &model.AIReviewVerdict{
+				Passed:            false,
+				Findings:          []model.AIReviewFinding{{Severity: "error", FilePath: "api.go", Line: 3, Message: "Handler ignores errors"}},
+				ReviewedSessionID: sessionID,
+			},
+		}, nil)
+		mockProjectRepo.On("FindByID", ctx, projectID).Return(&model.Project{ID: projectID, UserID: userID}, nil)
+		mockReviewRepo.On("FindLatestByTaskID", ctx, taskID).Return(nil, gorm.ErrRecordNotFound)
+		mockSessionService.On("GetSessionsByTaskID", ctx, taskID).Return([]model.Session{
+			{ID: uuid.New(), Kind: model.SessionKindReview},
+			{ID: sessionID, Kind: model.SessionKindExecution},
+		}, nil)
+		mockSessionService.On("StartSession", ctx, taskID, mock.MatchedBy(func(prompt string) bool {
+			return strings.Contains(prompt, "- [error] api.go:3: Handler ignores errors")
+		})).Return(&model.Session{ID: uuid.New()}, nil)
+		mockTaskRepo.On("UpdateStatus", ctx, taskID, model.TaskStatusInProgress).Return(nil)
+
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, nil, mockReviewRepo, nil)
+		_, err := svc.ExecuteTask(ctx, taskID, userID)
+
+		assert.NoError(t, err)
+		mockSessionService.AssertExpectations(t)
+	})
+}
+
+func TestBuildTaskPrompt(t *testing.T) {
+	task := &model.Task{Title: "Add login", Description: "Use OIDC"}
+
+	assert.Equal(t, "Task: Add login\n\nDescription:\nUse OIDC", buildTaskPrompt(task, nil, nil))
+
+	prompt := buildTaskPrompt(task, &model.TaskReview{
+		Decision: model.ReviewDecisionChangesRequested,
+		Comments: []model.ReviewComment{
+			{FilePath: "auth.go", Line: 7, Body: "Validate the state"},
+			{FilePath: "README.md", Body: "Document the flow"},
+			{Body: "Add tests"},
+		},
+	}, nil)
+	assert.True(t, strings.HasPrefix(prompt, "Task: Add login\n\nDescription:\nUse OIDC\n\n"))
+	assert.NotContains(t, prompt, "Review summary")
+	assert.Contains(t, prompt, "Review comments:\n- auth.go:7: Validate the state\n- README.md: Document the flow\n- Add tests")
+}
+
+func TestBuildTaskPrompt_AIReviewFindings(t *testing.T) {
+	task := &model.Task{Title: "Add login", Description: "Use OIDC"}
+
+	prompt := buildTaskPrompt(task, nil, &model.AIReviewVerdict{
+		Summary: "Token validation is missing",
+		Findings: []model.AIReviewFinding{
+			{Severity: "error", FilePath: "auth.go", Line: 12, Message: "The ID token signature is never checked"},
+			{Severity: "warning", Message: "No tests were added"},
+		},
+	})
+
+	assert.Contains(t, prompt, "AI review summary:\nToken validation is missing")
+	assert.Contains(t, prompt, "AI review findings:\n- [error] auth.go:12: The ID token signature is never checked\n- [warning] No tests were added")
+	assert.NotContains(t, prompt, "Review comments")
+}
+
+func TestTaskService_ExecuteTask_Snapshot(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	projectID := uuid.New()
+	taskID := uuid.New()
+
+	setup := func(snapshotErr error) (*MockTaskRepository, *MockSessionService, *MockWorkspaceSnapshotService, TaskService) {
+		mockTaskRepo := new(MockTaskRepository)
+		mockProjectRepo := new(MockProjectRepository)
+		mockSessionService := new(MockSessionService)
+		mockReviewRepo := new(MockReviewRepository)
+		mockSnapshots := new(MockWorkspaceSnapshotService)
+
+		mockTaskRepo.On("FindByID", ctx, taskID).Return(&model.Task{ID: taskID, ProjectID: projectID, Title: "Refactor", Status: model.TaskStatusTodo}, nil)
+		mockProjectRepo.On("FindByID", ctx, projectID).Return(&model.Project{ID: projectID, UserID: userID}, nil)
+		mockReviewRepo.On("FindLatestByTaskID", ctx, taskID).Return(nil, gorm.ErrRecordNotFound)
+		if snapshotErr != nil {
+			mockSnapshots.On("SnapshotBeforeTask", ctx, projectID, taskID).Return(nil, snapshotErr)
+		} else {
+			mockSnapshots.On("SnapshotBeforeTask", ctx, projectID, taskID).Return(&WorkspaceSnapshot{Name: "snapshot-1234-abcde"}, nil)
+		}
+		mockSessionService.On("StartSession", ctx, taskID, mock.AnythingOfType("string")).Return(&model.Session{ID: uuid.New()}, nil)
+		mockTaskRepo.On("UpdateStatus", ctx, taskID, model.TaskStatusInProgress).Return(nil)
+
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, nil, mockReviewRepo, mockSnapshots)
+		return mockTaskRepo, mockSessionService, mockSnapshots, svc
+	}
+
+	t.Run("snapshots the workspace before the run", func(t *testing.T) {
+		_, mockSessionService, mockSnapshots, svc := setup(nil)
+
+		session, err := svc.ExecuteTask(ctx, taskID, userID)
+
+		assert.NoError(t, err)
+		assert.NotNil(t, session)
+		mockSnapshots.AssertExpectations(t)
+		mockSessionService.AssertExpectations(t)
+	})
+
+	t.Run("runs the task when the snapshot fails", func(t *testing.T) {
+		mockTaskRepo, _, mockSnapshots, svc := setup(ErrSnapshotsUnavailable)
+
+		session, err := svc.ExecuteTask(ctx, taskID, userID)
+
+		assert.NoError(t, err)
+		assert.NotNil(t, session)
+		mockSnapshots.AssertExpectations(t)
+		mockTaskRepo.AssertExpectations(t)
+	})
+}
+
+func TestTaskService_ArchivedProjectIsReadOnly(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	projectID := uuid.New()
+	taskID := uuid.New()
+
+	setup := func() (*MockTaskRepository, *MockSessionService, TaskService) {
+		mockTaskRepo := new(MockTaskRepository)
+		mockProjectRepo := new(MockProjectRepository)
+		mockSessionService := new(MockSessionService)
+
+		mockTaskRepo.On("FindByID", ctx, taskID).Return(&model.Task{ID: taskID, ProjectID: projectID, Title: "Refactor", Status: model.TaskStatusTodo}, nil)
+		mockProjectRepo.On("FindByID", ctx, projectID).Return(&model.Project{ID: projectID, UserID: userID, Status: model.ProjectStatusArchived}, nil)
+
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, nil), mockSessionService, nil, new(MockReviewRepository), nil)
+		return mockTaskRepo, mockSessionService, svc
+	}
+
+	t.Run("tasks can still be read", func(t *testing.T) {
+		_, _, svc := setup()
+
+		task, err := svc.GetTask(ctx, taskID, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, taskID, task.ID)
+	})
+
+	t.Run("no task can be created", func(t *testing.T) {
+		mockTaskRepo, _, svc := setup()
+
+		_, err := svc.CreateTask(ctx, projectID, userID, "New task", "", model.TaskPriorityMedium)
+
+		assert.ErrorIs(t, err, ErrProjectArchived)
+		mockTaskRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("tasks cannot be updated", func(t *testing.T) {
+		mockTaskRepo, _, svc := setup()
+
+		_, err := svc.UpdateTask(ctx, taskID, userID, map[string]interface{}{"title": "Renamed"})
+
+		assert.ErrorIs(t, err, ErrProjectArchived)
+		mockTaskRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("tasks cannot be deleted", func(t *testing.T) {
+		mockTaskRepo, _, svc := setup()
+
+		err := svc.DeleteTask(ctx, taskID, userID)
+
+		assert.ErrorIs(t, err, ErrProjectArchived)
+		mockTaskRepo.AssertNotCalled(t, "SoftDelete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("tasks cannot be executed", func(t *testing.T) {
+		_, mockSessionService, svc := setup()
+
+		_, err := svc.ExecuteTask(ctx, taskID, userID)
+
+		assert.ErrorIs(t, err, ErrProjectArchived)
+		mockSessionService.AssertNotCalled(t, "StartSession", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestTaskService_MemberRoles(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+	taskID := uuid.New()
+	viewerID := uuid.New()
+	maintainerID := uuid.New()
+
+	setup := func() (*MockTaskRepository, *MockSessionService, TaskService) {
+		mockTaskRepo := new(MockTaskRepository)
+		mockProjectRepo := new(MockProjectRepository)
+		mockMemberRepo := new(MockProjectMemberRepository)
+		mockSessionService := new(MockSessionService)
+
+		project := &model.Project{ID: projectID, UserID: uuid.New(), Status: model.ProjectStatusReady}
+		mockTaskRepo.On("FindByID", ctx, taskID).Return(&model.Task{ID: taskID, ProjectID: projectID, Title: "Refactor", Status: model.TaskStatusTodo}, nil)
+		mockProjectRepo.On("FindByID", ctx, projectID).Return(project, nil)
+		expectMember(mockMemberRepo, project, viewerID, model.ProjectRoleViewer)
+		expectMember(mockMemberRepo, project, maintainerID, model.ProjectRoleMaintainer)
+
+		svc := NewTaskService(mockTaskRepo, mockProjectRepo, NewAuthorizationService(mockProjectRepo, mockMemberRepo), mockSessionService, nil, new(MockReviewRepository), nil)
+		return mockTaskRepo, mockSessionService, svc
+	}
+
+	t.Run("viewers can read tasks", func(t *testing.T) {
+		mockTaskRepo, _, svc := setup()
+		mockTaskRepo.On("FindByProjectID", ctx, projectID).Return([]model.Task{{ID: taskID, ProjectID: projectID}}, nil)
+
+		task, err := svc.GetTask(ctx, taskID, viewerID)
+		assert.NoError(t, err)
+		assert.Equal(t, taskID, task.ID)
+
+		tasks, err := svc.ListProjectTasks(ctx, projectID, viewerID)
+		assert.NoError(t, err)
+		assert.Len(t, tasks, 1)
+	})
+
+	t.Run("viewers cannot create tasks", func(t *testing.T) {
+		mockTaskRepo, _, svc := setup()
+
+		_, err := svc.CreateTask(ctx, projectID, viewerID, "New task", "", model.TaskPriorityMedium)
+
+		assert.ErrorIs(t, err, ErrUnauthorized)
+		mockTaskRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("viewers cannot execute tasks", func(t *testing.T) {
+		_, mockSessionService, svc := setup()
+
+		_, err := svc.ExecuteTask(ctx, taskID, viewerID)
+
+		assert.ErrorIs(t, err, ErrUnauthorized)
+		mockSessionService.AssertNotCalled(t, "StartSession", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("maintainers can update tasks", func(t *testing.T) {
+		mockTaskRepo, _, svc := setup()
+		mockTaskRepo.On("Update", ctx, mock.AnythingOfType("*model.Task")).Return(nil)
+
+		task, err := svc.UpdateTask(ctx, taskID, maintainerID, map[string]interface{}{"title": "Renamed"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Renamed", task.Title)
+	})
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/npinot/vibe/backend/internal/model"
+	"github.com/npinot/vibe/backend/internal/repository"
+)
+
+// UsageService reports the token usage and cost of the sessions run for a project
+type UsageService interface {
+	GetProjectUsage(ctx context.Context, projectID, userID uuid.UUID) (*model.ProjectUsage, error)
+}
+
+type usageService struct {
+	sessionRepo repository.SessionRepository
+	authz       AuthorizationService
+}
+
+func NewUsageService(sessionRepo repository.SessionRepository, authz AuthorizationService) UsageService {
+	return &usageService{
+		sessionRepo: sessionRepo,
+		authz:       authz,
+	}
+}
+
+// GetProjectUsage sums the usage of the project's sessions in total, by day, by model and by task
+func (s *usageService) GetProjectUsage(ctx context.Context, projectID, userID uuid.UUID) (*model.ProjectUsage, error) {
+	if _, err := s.authz.AuthorizeProject(ctx, projectID, userID, model.ProjectRoleViewer); err != nil {
+		return nil, err
+	}
+
+	usage, err := s.sessionRepo.GetProjectUsage(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project usage: %w", err)
+	}
+
+	return usage, nil
+}
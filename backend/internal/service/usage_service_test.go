@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/npinot/vibe/backend/internal/model"
+)
+
+func TestUsageService_GetProjectUsage(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+	ownerID := uuid.New()
+
+	setup := func() (UsageService, *MockSessionRepository, *MockProjectRepository) {
+		sessionRepo := new(MockSessionRepository)
+		projectRepo := new(MockProjectRepository)
+		return NewUsageService(sessionRepo, NewAuthorizationService(projectRepo, nil)), sessionRepo, projectRepo
+	}
+
+	t.Run("returns usage of the owner's project", func(t *testing.T) {
+		service, sessionRepo, projectRepo := setup()
+		usage := &model.ProjectUsage{ProjectID: projectID, Total: model.UsageTotals{Sessions: 2, CostUSD: 1.5}}
+		projectRepo.On("FindByID", ctx, projectID).Return(&model.Project{ID: projectID, UserID: ownerID}, nil)
+		sessionRepo.On("GetProjectUsage", ctx, projectID).Return(usage, nil)
+
+		result, err := service.GetProjectUsage(ctx, projectID, ownerID)
+
+		require.NoError(t, err)
+		assert.Equal(t, usage, result)
+	})
+
+	t.Run("project not found", func(t *testing.T) {
+		service, _, projectRepo := setup()
+		projectRepo.On("FindByID", ctx, projectID).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := service.GetProjectUsage(ctx, projectID, ownerID)
+
+		assert.ErrorIs(t, err, ErrProjectNotFound)
+	})
+
+	t.Run("other user's project", func(t *testing.T) {
+		service, sessionRepo, projectRepo := setup()
+		projectRepo.On("FindByID", ctx, projectID).Return(&model.Project{ID: projectID, UserID: ownerID}, nil)
+
+		_, err := service.GetProjectUsage(ctx, projectID, uuid.New())
+
+		assert.ErrorIs(t, err, ErrUnauthorized)
+		sessionRepo.AssertNotCalled(t, "GetProjectUsage", ctx, projectID)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		service, sessionRepo, projectRepo := setup()
+		projectRepo.On("FindByID", ctx, projectID).Return(&model.Project{ID: projectID, UserID: ownerID}, nil)
+		sessionRepo.On("GetProjectUsage", ctx, projectID).Return(nil, errors.New("database error"))
+
+		_, err := service.GetProjectUsage(ctx, projectID, ownerID)
+
+		assert.Error(t, err)
+	})
+}
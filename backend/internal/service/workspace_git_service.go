@@ -0,0 +1,370 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/npinot/vibe/backend/internal/model"
+)
+
+var (
+	ErrWorkspaceNotRepository = errors.New("workspace is not a git repository")
+	ErrNoChangesToCommit      = errors.New("no changes to commit")
+	ErrWorkspaceBranchChanged = errors.New("workspace branch changed")
+	ErrInvalidSnapshot        = errors.New("invalid workspace snapshot")
+)
+
+const (
+	// Identity used for commits created automatically at the end of a session
+	autoCommitAuthorName  = "OpenCode Agent"
+	autoCommitAuthorEmail = "opencode-agent@noreply.local"
+)
+
+// WorkspaceGitService runs git operations in a project workspace through the file-browser sidecar
+type WorkspaceGitService interface {
+	// PrepareBranch checks out the branch, creating it from startPoint if it does not exist yet
+	PrepareBranch(ctx context.Context, project *model.Project, branch, startPoint string) error
+
+	// CommitAll commits every change in the workspace on the given branch and returns the commit SHA
+	CommitAll(ctx context.Context, project *model.Project, branch, message string) (string, error)
+
+	// Snapshot records the current workspace content for a session and returns the snapshot id.
+	// The sidecar keeps it until PruneSnapshots no longer lists it.
+	Snapshot(ctx context.Context, project *model.Project, sessionID uuid.UUID) (string, error)
+
+	// DiffSnapshots compares two snapshots; an empty "to" compares against the live workspace
+	DiffSnapshots(ctx context.Context, project *model.Project, from, to string) (*WorkspaceDiff, error)
+
+	// PruneSnapshots deletes every snapshot of the workspace but the ones listed in keep, by session
+	PruneSnapshots(ctx context.Context, project *model.Project, keep map[uuid.UUID][]string) error
+}
+
+// WorkspaceFileDiff is the change to a single file between two snapshots
+type WorkspaceFileDiff struct {
+	Path      string `json:"path"`
+	OldPath   string `json:"old_path,omitempty"`
+	Status    string `json:"status"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+	Binary    bool   `json:"binary"`
+	Patch     string `json:"patch,omitempty"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+// WorkspaceDiff is the set of file changes between two workspace snapshots
+type WorkspaceDiff struct {
+	From         string              `json:"from"`
+	To           string              `json:"to"`
+	FilesChanged int                 `json:"files_changed"`
+	Additions    int                 `json:"additions"`
+	Deletions    int                 `json:"deletions"`
+	Files        []WorkspaceFileDiff `json:"files"`
+}
+
+// hunkHeader matches the line ranges of a unified diff hunk: @@ -start[,count] +start[,count] @@
+var hunkHeader = regexp.MustCompile(`(?m)^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// HasLine reports whether a line falls inside one of the file's diff hunks, on either side.
+// Truncated patches do not list every hunk, so any line is accepted.
+func (f *WorkspaceFileDiff) HasLine(line int) bool {
+	if f.Truncated {
+		return true
+	}
+
+	for _, m := range hunkHeader.FindAllStringSubmatch(f.Patch, -1) {
+		if hunkContains(m[1], m[2], line) || hunkContains(m[3], m[4], line) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hunkContains reports whether a line falls inside one side of a hunk; the count defaults to 1
+func hunkContains(start, count string, line int) bool {
+	first, _ := strconv.Atoi(start)
+	n := 1
+	if count != "" {
+		n, _ = strconv.Atoi(count)
+	}
+	return line >= first && line < first+n
+}
+
+// File returns the change to the file at path, matching renamed files by their old path too
+func (d *WorkspaceDiff) File(path string) *WorkspaceFileDiff {
+	for i := range d.Files {
+		if d.Files[i].Path == path || (d.Files[i].OldPath != "" && d.Files[i].OldPath == path) {
+			return &d.Files[i]
+		}
+	}
+	return nil
+}
+
+type workspaceGitService struct {
+	k8sService  KubernetesService
+	httpClient  *http.Client
+	sidecarPort int
+}
+
+// NewWorkspaceGitService creates a new workspace git service
+func NewWorkspaceGitService(k8sService KubernetesService) WorkspaceGitService {
+	return &workspaceGitService{
+		k8sService: k8sService,
+		httpClient: &http.Client{
+			Timeout: 2 * time.Minute,
+		},
+		sidecarPort: 3001,
+	}
+}
+
+type workspaceGitStatus struct {
+	Branch string `json:"branch"`
+	Clean  bool   `json:"clean"`
+}
+
+// PrepareBranch checks out the branch, creating it from startPoint if it does not exist yet
+func (s *workspaceGitService) PrepareBranch(ctx context.Context, project *model.Project, branch, startPoint string) error {
+	baseURL, err := s.sidecarURL(ctx, project)
+	if err != nil {
+		return err
+	}
+
+	status, err := s.status(ctx, baseURL)
+	if err != nil {
+		return err
+	}
+	if status.Branch == branch {
+		return nil
+	}
+
+	statusCode, body, err := s.post(ctx, baseURL+"/git/branch", map[string]interface{}{
+		"name":        branch,
+		"start_point": startPoint,
+		"checkout":    true,
+	})
+	if err != nil {
+		return err
+	}
+	if statusCode == http.StatusCreated {
+		return nil
+	}
+	if statusCode != http.StatusConflict {
+		return fmt.Errorf("failed to create branch %s: sidecar returned status %d: %s", branch, statusCode, body)
+	}
+
+	// Branch already exists from a previous run of the task
+	statusCode, body, err = s.post(ctx, baseURL+"/git/checkout", map[string]interface{}{
+		"branch": branch,
+	})
+	if err != nil {
+		return err
+	}
+	if statusCode != http.StatusOK {
+		return fmt.Errorf("failed to checkout branch %s: sidecar returned status %d: %s", branch, statusCode, body)
+	}
+
+	return nil
+}
+
+// CommitAll commits every change in the workspace on the given branch and returns the commit SHA
+func (s *workspaceGitService) CommitAll(ctx context.Context, project *model.Project, branch, message string) (string, error) {
+	baseURL, err := s.sidecarURL(ctx, project)
+	if err != nil {
+		return "", err
+	}
+
+	status, err := s.status(ctx, baseURL)
+	if err != nil {
+		return "", err
+	}
+	if status.Branch != branch {
+		return "", fmt.Errorf("%w: expected %s, found %s", ErrWorkspaceBranchChanged, branch, status.Branch)
+	}
+	if status.Clean {
+		return "", ErrNoChangesToCommit
+	}
+
+	statusCode, body, err := s.post(ctx, baseURL+"/git/commit", map[string]interface{}{
+		"message":      message,
+		"author_name":  autoCommitAuthorName,
+		"author_email": autoCommitAuthorEmail,
+	})
+	if err != nil {
+		return "", err
+	}
+	if statusCode != http.StatusCreated {
+		return "", fmt.Errorf("failed to commit changes: sidecar returned status %d: %s", statusCode, body)
+	}
+
+	var result struct {
+		SHA string `json:"sha"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("failed to decode commit response: %w", err)
+	}
+
+	return result.SHA, nil
+}
+
+// Snapshot records the current workspace content for a session and returns the snapshot id
+func (s *workspaceGitService) Snapshot(ctx context.Context, project *model.Project, sessionID uuid.UUID) (string, error) {
+	baseURL, err := s.sidecarURL(ctx, project)
+	if err != nil {
+		return "", err
+	}
+
+	statusCode, body, err := s.post(ctx, baseURL+"/git/snapshots", map[string]interface{}{
+		"session": sessionID,
+	})
+	if err != nil {
+		return "", err
+	}
+	if statusCode != http.StatusCreated {
+		return "", fmt.Errorf("failed to snapshot workspace: sidecar returned status %d: %s", statusCode, body)
+	}
+
+	var result struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("failed to decode snapshot response: %w", err)
+	}
+
+	return result.ID, nil
+}
+
+// DiffSnapshots compares two snapshots; an empty "to" compares against the live workspace
+func (s *workspaceGitService) DiffSnapshots(ctx context.Context, project *model.Project, from, to string) (*WorkspaceDiff, error) {
+	baseURL, err := s.sidecarURL(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("from", from)
+	if to != "" {
+		query.Set("to", to)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"/git/snapshots/diff?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call file-browser sidecar: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusBadRequest {
+		return nil, ErrInvalidSnapshot
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("snapshot diff returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var diff WorkspaceDiff
+	if err := json.NewDecoder(resp.Body).Decode(&diff); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot diff: %w", err)
+	}
+
+	return &diff, nil
+}
+
+// PruneSnapshots deletes every snapshot of the workspace but the ones listed in keep, by session
+func (s *workspaceGitService) PruneSnapshots(ctx context.Context, project *model.Project, keep map[uuid.UUID][]string) error {
+	baseURL, err := s.sidecarURL(ctx, project)
+	if err != nil {
+		return err
+	}
+
+	statusCode, body, err := s.post(ctx, baseURL+"/git/snapshots/prune", map[string]interface{}{
+		"keep": keep,
+	})
+	if err != nil {
+		return err
+	}
+	if statusCode != http.StatusOK {
+		return fmt.Errorf("failed to prune snapshots: sidecar returned status %d: %s", statusCode, body)
+	}
+
+	return nil
+}
+
+// sidecarURL resolves the file-browser sidecar URL for a project
+func (s *workspaceGitService) sidecarURL(ctx context.Context, project *model.Project) (string, error) {
+	podIP, err := s.k8sService.GetPodIP(ctx, project.PodName, project.PodNamespace)
+	if err != nil {
+		return "", fmt.Errorf("failed to get pod IP: %w", err)
+	}
+
+	return fmt.Sprintf("http://%s:%d", podIP, s.sidecarPort), nil
+}
+
+func (s *workspaceGitService) status(ctx context.Context, baseURL string) (*workspaceGitStatus, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"/git/status", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call file-browser sidecar: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// The status endpoint only conflicts when the workspace has no repository
+	if resp.StatusCode == http.StatusConflict {
+		return nil, ErrWorkspaceNotRepository
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("git status returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var status workspaceGitStatus
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, fmt.Errorf("failed to decode git status: %w", err)
+	}
+
+	return &status, nil
+}
+
+func (s *workspaceGitService) post(ctx context.Context, url string, payload interface{}) (int, []byte, error) {
+	jsonBody, err := json.Marshal(payload)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to call file-browser sidecar: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	return resp.StatusCode, body, nil
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/npinot/vibe/backend/internal/model"
+)
+
+// MockWorkspaceGitService - local mock for the workspace git service
+type MockWorkspaceGitService struct {
+	mock.Mock
+}
+
+func (m *MockWorkspaceGitService) PrepareBranch(ctx context.Context, project *model.Project, branch, startPoint string) error {
+	args := m.Called(ctx, project, branch, startPoint)
+	return args.Error(0)
+}
+
+func (m *MockWorkspaceGitService) CommitAll(ctx context.Context, project *model.Project, branch, message string) (string, error) {
+	args := m.Called(ctx, project, branch, message)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockWorkspaceGitService) Snapshot(ctx context.Context, project *model.Project, sessionID uuid.UUID) (string, error) {
+	args := m.Called(ctx, project, sessionID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockWorkspaceGitService) DiffSnapshots(ctx context.Context, project *model.Project, from, to string) (*WorkspaceDiff, error) {
+	args := m.Called(ctx, project, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*WorkspaceDiff), args.Error(1)
+}
+
+func (m *MockWorkspaceGitService) PruneSnapshots(ctx context.Context, project *model.Project, keep map[uuid.UUID][]string) error {
+	args := m.Called(ctx, project, keep)
+	return args.Error(0)
+}
+
+var _ WorkspaceGitService = (*MockWorkspaceGitService)(nil)
+
+// setupWorkspaceGitTest starts a fake file-browser sidecar and returns a service pointing at it
+func setupWorkspaceGitTest(t *testing.T, handler http.HandlerFunc) (*workspaceGitService, *model.Project) {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	addr := server.Listener.Addr().(*net.TCPAddr)
+	project := &model.Project{PodName: "project-pod", PodNamespace: "test-ns"}
+
+	k8sService := new(MockKubernetesService)
+	k8sService.On("GetPodIP", mock.Anything, "project-pod", "test-ns").Return(addr.IP.String(), nil)
+
+	svc := NewWorkspaceGitService(k8sService).(*workspaceGitService)
+	svc.sidecarPort = addr.Port
+
+	return svc, project
+}
+
+func TestWorkspaceGitService_PrepareBranch(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("already on branch", func(t *testing.T) {
+		svc, project := setupWorkspaceGitTest(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/git/status", r.URL.Path)
+			json.NewEncoder(w).Encode(map[string]interface{}{"branch": "task/1234abcd-fix", "clean": true})
+		})
+
+		err := svc.PrepareBranch(ctx, project, "task/1234abcd-fix", "main")
+		assert.NoError(t, err)
+	})
+
+	t.Run("creates new branch from start point", func(t *testing.T) {
+		var branchRequest map[string]interface{}
+		svc, project := setupWorkspaceGitTest(t, func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/git/status":
+				json.NewEncoder(w).Encode(map[string]interface{}{"branch": "main", "clean": true})
+			case "/git/branch":
+				json.NewDecoder(r.Body).Decode(&branchRequest)
+				w.WriteHeader(http.StatusCreated)
+			default:
+				t.Errorf("unexpected request to %s", r.URL.Path)
+			}
+		})
+
+		err := svc.PrepareBranch(ctx, project, "task/1234abcd-fix", "origin/HEAD")
+		require.NoError(t, err)
+		assert.Equal(t, "task/1234abcd-fix", branchRequest["name"])
+		assert.Equal(t, "origin/HEAD", branchRequest["start_point"])
+		assert.Equal(t, true, branchRequest["checkout"])
+	})
+
+	t.Run("checks out existing branch", func(t *testing.T) {
+		checkedOut := false
+		svc, project := setupWorkspaceGitTest(t, func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/git/status":
+				json.NewEncoder(w).Encode(map[string]interface{}{"branch": "main", "clean": true})
+			case "/git/branch":
+				w.WriteHeader(http.StatusConflict)
+			case "/git/checkout":
+				checkedOut = true
+				w.WriteHeader(http.StatusOK)
+			}
+		})
+
+		err := svc.PrepareBranch(ctx, project, "task/1234abcd-fix", "")
+		require.NoError(t, err)
+		assert.True(t, checkedOut)
+	})
+
+	t.Run("checkout blocked by uncommitted changes", func(t *testing.T) {
+		svc, project := setupWorkspaceGitTest(t, func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/git/status":
+				json.NewEncoder(w).Encode(map[string]interface{}{"branch": "main", "clean": false})
+			default:
+				w.WriteHeader(http.StatusConflict)
+			}
+		})
+
+		err := svc.PrepareBranch(ctx, project, "task/1234abcd-fix", "")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to checkout branch")
+	})
+
+	t.Run("workspace without repository", func(t *testing.T) {
+		svc, project := setupWorkspaceGitTest(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusConflict)
+		})
+
+		err := svc.PrepareBranch(ctx, project, "task/1234abcd-fix", "")
+		assert.ErrorIs(t, err, ErrWorkspaceNotRepository)
+	})
+}
+
+func TestWorkspaceGitService_CommitAll(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		var commitRequest map[string]interface{}
+		svc, project := setupWorkspaceGitTest(t, func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/git/status":
+				json.NewEncoder(w).Encode(map[string]interface{}{"branch": "task/1234abcd-fix", "clean": false})
+			case "/git/commit":
+				json.NewDecoder(r.Body).Decode(&commitRequest)
+				w.WriteHeader(http.StatusCreated)
+				json.NewEncoder(w).Encode(map[string]interface{}{"sha": "0123456789abcdef0123456789abcdef01234567", "branch": "task/1234abcd-fix"})
+			}
+		})
+
+		sha, err := svc.CommitAll(ctx, project, "task/1234abcd-fix", "Fix login bug")
+		require.NoError(t, err)
+		assert.Equal(t, "0123456789abcdef0123456789abcdef01234567", sha)
+		assert.Equal(t, "Fix login bug", commitRequest["message"])
+		assert.Equal(t, autoCommitAuthorName, commitRequest["author_name"])
+		assert.Equal(t, autoCommitAuthorEmail, commitRequest["author_email"])
+	})
+
+	t.Run("nothing changed", func(t *testing.T) {
+		svc, project := setupWorkspaceGitTest(t, func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(map[string]interface{}{"branch": "task/1234abcd-fix", "clean": true})
+		})
+
+		_, err := svc.CommitAll(ctx, project, "task/1234abcd-fix", "Fix login bug")
+		assert.ErrorIs(t, err, ErrNoChangesToCommit)
+	})
+
+	t.Run("branch changed during session", func(t *testing.T) {
+		svc, project := setupWorkspaceGitTest(t, func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(map[string]interface{}{"branch": "main", "clean": false})
+		})
+
+		_, err := svc.CommitAll(ctx, project, "task/1234abcd-fix", "Fix login bug")
+		assert.ErrorIs(t, err, ErrWorkspaceBranchChanged)
+	})
+}
+
+func TestWorkspaceGitService_Snapshots(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("snapshot", func(t *testing.T) {
+		sessionID := uuid.New()
+		svc, project := setupWorkspaceGitTest(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "POST", r.Method)
+			assert.Equal(t, "/git/snapshots", r.URL.Path)
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			assert.Equal(t, sessionID.String(), body["session"])
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]string{"id": "4b825dc642cb6eb9a060e54bf8d69288fbee4904"})
+		})
+
+		id, err := svc.Snapshot(ctx, project, sessionID)
+		require.NoError(t, err)
+		assert.Equal(t, "4b825dc642cb6eb9a060e54bf8d69288fbee4904", id)
+	})
+
+	t.Run("prune", func(t *testing.T) {
+		sessionID := uuid.New()
+		svc, project := setupWorkspaceGitTest(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "POST", r.Method)
+			assert.Equal(t, "/git/snapshots/prune", r.URL.Path)
+			var body struct {
+				Keep map[string][]string `json:"keep"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			assert.Equal(t, map[string][]string{sessionID.String(): {"aaaa", "bbbb"}}, body.Keep)
+			json.NewEncoder(w).Encode(map[string]string{"message": "Snapshots pruned"})
+		})
+
+		err := svc.PruneSnapshots(ctx, project, map[uuid.UUID][]string{sessionID: {"aaaa", "bbbb"}})
+		require.NoError(t, err)
+	})
+
+	t.Run("diff against live workspace", func(t *testing.T) {
+		svc, project := setupWorkspaceGitTest(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/git/snapshots/diff", r.URL.Path)
+			assert.Equal(t, "aaaa", r.URL.Query().Get("from"))
+			assert.False(t, r.URL.Query().Has("to"))
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"from":          "aaaa",
+				"to":            "bbbb",
+				"files_changed": 1,
+				"additions":     3,
+				"deletions":     1,
+				"files": []map[string]interface{}{
+					{"path": "main.go", "status": "modified", "additions": 3, "deletions": 1, "patch": "@@"},
+				},
+			})
+		})
+
+		diff, err := svc.DiffSnapshots(ctx, project, "aaaa", "")
+		require.NoError(t, err)
+		assert.Equal(t, 1, diff.FilesChanged)
+		assert.Equal(t, 3, diff.Additions)
+		assert.Equal(t, "main.go", diff.Files[0].Path)
+	})
+
+	t.Run("unknown snapshot", func(t *testing.T) {
+		svc, project := setupWorkspaceGitTest(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		})
+
+		_, err := svc.DiffSnapshots(ctx, project, "aaaa", "bbbb")
+		assert.ErrorIs(t, err, ErrInvalidSnapshot)
+	})
+}
+
+func TestWorkspaceDiff_Lines(t *testing.T) {
+	diff := &WorkspaceDiff{Files: []WorkspaceFileDiff{
+		{Path: "main.go", Status: "modified", Patch: "@@ -3,2 +3,3 @@ import\n a\n+b\n c\n@@ -20 +21,0 @@\n-gone\n"},
+		{Path: "new.go", OldPath: "old.go", Status: "renamed", Truncated: true},
+	}}
+
+	file := diff.File("main.go")
+	require.NotNil(t, file)
+	assert.True(t, file.HasLine(3))
+	assert.True(t, file.HasLine(5))
+	assert.False(t, file.HasLine(6))
+	assert.True(t, file.HasLine(20), "deleted lines are anchored on the old side")
+	assert.False(t, file.HasLine(21))
+
+	renamed := diff.File("old.go")
+	require.NotNil(t, renamed)
+	assert.Equal(t, "new.go", renamed.Path)
+	assert.True(t, renamed.HasLine(500), "truncated patches accept any line")
+
+	assert.Nil(t, diff.File("other.go"))
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/npinot/vibe/backend/internal/model"
+	"github.com/npinot/vibe/backend/internal/repository"
+)
+
+var (
+	ErrSnapshotNotFound = errors.New("snapshot not found")
+	ErrSnapshotNotReady = errors.New("snapshot is not ready to be restored")
+
+	// ErrSnapshotsUnavailable is returned when the cluster cannot snapshot the workspace, e.g. without
+	// the VolumeSnapshot CRDs, or when the project has no workspace yet
+	ErrSnapshotsUnavailable = errors.New("workspace snapshots are not available")
+)
+
+// snapshotRestoreTimeout bounds restoring a workspace, which rolls the project pod
+const snapshotRestoreTimeout = 5 * time.Minute
+
+type WorkspaceSnapshotService interface {
+	// ListSnapshots lists the snapshots of the project workspace, newest first
+	ListSnapshots(ctx context.Context, projectID, userID uuid.UUID) ([]WorkspaceSnapshot, error)
+
+	// CreateSnapshot takes a snapshot of the project workspace
+	CreateSnapshot(ctx context.Context, projectID, userID uuid.UUID) (*WorkspaceSnapshot, error)
+
+	// RestoreSnapshot puts the project workspace back to the snapshot, restarting the project pod
+	RestoreSnapshot(ctx context.Context, projectID, userID uuid.UUID, name string) (*model.Project, error)
+
+	// DeleteSnapshot deletes a snapshot of the project workspace
+	DeleteSnapshot(ctx context.Context, projectID, userID uuid.UUID, name string) error
+
+	// SnapshotBeforeTask takes the automatic snapshot of the project workspace before a task runs,
+	// pruning the oldest automatic snapshots. It returns nil when automatic snapshots are disabled.
+	SnapshotBeforeTask(ctx context.Context, projectID, taskID uuid.UUID) (*WorkspaceSnapshot, error)
+}
+
+type workspaceSnapshotService struct {
+	projectRepo repository.ProjectRepository
+	authz       AuthorizationService
+	sessionRepo repository.SessionRepository
+	k8sService  KubernetesService
+	taskKept    int
+}
+
+// NewWorkspaceSnapshotService creates a workspace snapshot service keeping taskKept automatic snapshots
+// per project; zero disables automatic snapshots before task runs.
+func NewWorkspaceSnapshotService(projectRepo repository.ProjectRepository, authz AuthorizationService, sessionRepo repository.SessionRepository, k8sService KubernetesService, taskKept int) WorkspaceSnapshotService {
+	return &workspaceSnapshotService{
+		projectRepo: projectRepo,
+		authz:       authz,
+		sessionRepo: sessionRepo,
+		k8sService:  k8sService,
+		taskKept:    taskKept,
+	}
+}
+
+func (s *workspaceSnapshotService) ListSnapshots(ctx context.Context, projectID, userID uuid.UUID) ([]WorkspaceSnapshot, error) {
+	project, err := s.authorizedProject(ctx, projectID, userID, model.ProjectRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.k8sService.ListWorkspaceSnapshots(ctx, project)
+}
+
+func (s *workspaceSnapshotService) CreateSnapshot(ctx context.Context, projectID, userID uuid.UUID) (*WorkspaceSnapshot, error) {
+	project, err := s.authorizedProject(ctx, projectID, userID, model.ProjectRoleMaintainer)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := s.k8sService.CreateWorkspaceSnapshot(ctx, project, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[WorkspaceSnapshotService] Created snapshot %s of project %s", snapshot.Name, project.ID)
+	return snapshot, nil
+}
+
+func (s *workspaceSnapshotService) RestoreSnapshot(ctx context.Context, projectID, userID uuid.UUID, name string) (*model.Project, error) {
+	project, err := s.authorizedProject(ctx, projectID, userID, model.ProjectRoleMaintainer)
+	if err != nil {
+		return nil, err
+	}
+
+	// A running agent would keep writing to the workspace being replaced
+	sessions, err := s.sessionRepo.FindActiveSessionsForProject(ctx, project.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check active sessions: %w", err)
+	}
+	if len(sessions) > 0 {
+		return nil, fmt.Errorf("%w: task %s is still running", ErrWorkspaceBusy, sessions[0].TaskID)
+	}
+
+	// Rolling the pod outlives a client giving up, which would leave the project without a pod
+	restoreCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), snapshotRestoreTimeout)
+	defer cancel()
+
+	save := func(project *model.Project) error {
+		if err := s.projectRepo.Update(restoreCtx, project); err != nil {
+			return fmt.Errorf("failed to update project: %w", err)
+		}
+		return nil
+	}
+	if err := s.k8sService.RestoreWorkspaceSnapshot(restoreCtx, project, name, save); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[WorkspaceSnapshotService] Restored project %s from snapshot %s", project.ID, name)
+	return project, nil
+}
+
+func (s *workspaceSnapshotService) DeleteSnapshot(ctx context.Context, projectID, userID uuid.UUID, name string) error {
+	project, err := s.authorizedProject(ctx, projectID, userID, model.ProjectRoleMaintainer)
+	if err != nil {
+		return err
+	}
+
+	return s.k8sService.DeleteWorkspaceSnapshot(ctx, project, name)
+}
+
+func (s *workspaceSnapshotService) SnapshotBeforeTask(ctx context.Context, projectID, taskID uuid.UUID) (*WorkspaceSnapshot, error) {
+	if s.taskKept <= 0 {
+		return nil, nil
+	}
+	if s.k8sService == nil {
+		return nil, ErrSnapshotsUnavailable
+	}
+
+	project, err := s.projectRepo.FindByID(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve project: %w", err)
+	}
+
+	snapshot, err := s.k8sService.CreateWorkspaceSnapshot(ctx, project, &taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.pruneTaskSnapshots(ctx, project, snapshot.Name)
+	return snapshot, nil
+}
+
+// pruneTaskSnapshots deletes the oldest automatic snapshots of the project beyond the ones kept,
+// counting the snapshot just taken whether or not it is listed yet
+func (s *workspaceSnapshotService) pruneTaskSnapshots(ctx context.Context, project *model.Project, latest string) {
+	snapshots, err := s.k8sService.ListWorkspaceSnapshots(ctx, project)
+	if err != nil {
+		log.Printf("[WorkspaceSnapshotService] Failed to list snapshots of project %s: %v", project.ID, err)
+		return
+	}
+
+	kept := 1
+	for _, snapshot := range snapshots {
+		if snapshot.Trigger != SnapshotTriggerTask || snapshot.Name == latest {
+			continue
+		}
+		if kept < s.taskKept {
+			kept++
+			continue
+		}
+		if err := s.k8sService.DeleteWorkspaceSnapshot(ctx, project, snapshot.Name); err != nil {
+			log.Printf("[WorkspaceSnapshotService] Failed to delete snapshot %s of project %s: %v", snapshot.Name, project.ID, err)
+		}
+	}
+}
+
+func (s *workspaceSnapshotService) authorizedProject(ctx context.Context, projectID, userID uuid.UUID, role model.ProjectRole) (*model.Project, error) {
+	project, err := s.authz.AuthorizeProject(ctx, projectID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.k8sService == nil {
+		return nil, ErrSnapshotsUnavailable
+	}
+
+	return project, nil
+}
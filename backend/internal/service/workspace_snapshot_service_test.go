@@ -0,0 +1,329 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/npinot/vibe/backend/internal/model"
+)
+
+type MockWorkspaceSnapshotService struct {
+	mock.Mock
+}
+
+func (m *MockWorkspaceSnapshotService) ListSnapshots(ctx context.Context, projectID, userID uuid.UUID) ([]WorkspaceSnapshot, error) {
+	args := m.Called(ctx, projectID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]WorkspaceSnapshot), args.Error(1)
+}
+
+func (m *MockWorkspaceSnapshotService) CreateSnapshot(ctx context.Context, projectID, userID uuid.UUID) (*WorkspaceSnapshot, error) {
+	args := m.Called(ctx, projectID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*WorkspaceSnapshot), args.Error(1)
+}
+
+func (m *MockWorkspaceSnapshotService) RestoreSnapshot(ctx context.Context, projectID, userID uuid.UUID, name string) (*model.Project, error) {
+	args := m.Called(ctx, projectID, userID, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Project), args.Error(1)
+}
+
+func (m *MockWorkspaceSnapshotService) DeleteSnapshot(ctx context.Context, projectID, userID uuid.UUID, name string) error {
+	args := m.Called(ctx, projectID, userID, name)
+	return args.Error(0)
+}
+
+func (m *MockWorkspaceSnapshotService) SnapshotBeforeTask(ctx context.Context, projectID, taskID uuid.UUID) (*WorkspaceSnapshot, error) {
+	args := m.Called(ctx, projectID, taskID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*WorkspaceSnapshot), args.Error(1)
+}
+
+func setupSnapshotServiceTest(taskKept int) (WorkspaceSnapshotService, *MockProjectRepository, *MockSessionRepository, *MockKubernetesService) {
+	projectRepo := new(MockProjectRepository)
+	sessionRepo := new(MockSessionRepository)
+	k8sService := new(MockKubernetesService)
+	return NewWorkspaceSnapshotService(projectRepo, NewAuthorizationService(projectRepo, nil), sessionRepo, k8sService, taskKept), projectRepo, sessionRepo, k8sService
+}
+
+func snapshotProject(userID uuid.UUID) *model.Project {
+	return &model.Project{
+		ID:               uuid.New(),
+		UserID:           userID,
+		Status:           model.ProjectStatusReady,
+		PodName:          "project-1234",
+		PodNamespace:     "vibe",
+		WorkspacePVCName: "workspace-1234",
+	}
+}
+
+func TestWorkspaceSnapshotService_Ownership(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	t.Run("project not found", func(t *testing.T) {
+		service, projectRepo, _, _ := setupSnapshotServiceTest(5)
+		projectID := uuid.New()
+		projectRepo.On("FindByID", ctx, projectID).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := service.ListSnapshots(ctx, projectID, userID)
+
+		assert.ErrorIs(t, err, ErrProjectNotFound)
+	})
+
+	t.Run("project of another user", func(t *testing.T) {
+		service, projectRepo, _, k8sService := setupSnapshotServiceTest(5)
+		project := snapshotProject(uuid.New())
+		projectRepo.On("FindByID", ctx, project.ID).Return(project, nil)
+
+		_, err := service.CreateSnapshot(ctx, project.ID, userID)
+
+		assert.ErrorIs(t, err, ErrUnauthorized)
+		k8sService.AssertNotCalled(t, "CreateWorkspaceSnapshot")
+	})
+
+	t.Run("without kubernetes", func(t *testing.T) {
+		projectRepo := new(MockProjectRepository)
+		project := snapshotProject(userID)
+		projectRepo.On("FindByID", ctx, project.ID).Return(project, nil)
+		service := NewWorkspaceSnapshotService(projectRepo, NewAuthorizationService(projectRepo, nil), new(MockSessionRepository), nil, 5)
+
+		_, err := service.ListSnapshots(ctx, project.ID, userID)
+
+		assert.ErrorIs(t, err, ErrSnapshotsUnavailable)
+	})
+}
+
+func TestWorkspaceSnapshotService_CreateSnapshot(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	service, projectRepo, _, k8sService := setupSnapshotServiceTest(5)
+	project := snapshotProject(userID)
+	projectRepo.On("FindByID", ctx, project.ID).Return(project, nil)
+	snapshot := &WorkspaceSnapshot{Name: "snapshot-1234-abcde", Trigger: SnapshotTriggerManual}
+	k8sService.On("CreateWorkspaceSnapshot", ctx, project, (*uuid.UUID)(nil)).Return(snapshot, nil)
+
+	created, err := service.CreateSnapshot(ctx, project.ID, userID)
+
+	require.NoError(t, err)
+	assert.Equal(t, snapshot, created)
+}
+
+func TestWorkspaceSnapshotService_RestoreSnapshot(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	t.Run("restores and saves the new workspace PVC", func(t *testing.T) {
+		service, projectRepo, sessionRepo, k8sService := setupSnapshotServiceTest(5)
+		project := snapshotProject(userID)
+		projectRepo.On("FindByID", ctx, project.ID).Return(project, nil)
+		sessionRepo.On("FindActiveSessionsForProject", ctx, project.ID).Return([]model.Session{}, nil)
+		k8sService.On("RestoreWorkspaceSnapshot", mock.Anything, project, "snapshot-1234-abcde", mock.Anything).
+			Run(func(args mock.Arguments) {
+				p := args.Get(1).(*model.Project)
+				p.WorkspacePVCName = "workspace-1234-fghij"
+				require.NoError(t, args.Get(3).(func(*model.Project) error)(p))
+			}).Return(nil)
+		projectRepo.On("Update", mock.Anything, mock.MatchedBy(func(p *model.Project) bool {
+			return p.WorkspacePVCName == "workspace-1234-fghij"
+		})).Return(nil)
+
+		restored, err := service.RestoreSnapshot(ctx, project.ID, userID, "snapshot-1234-abcde")
+
+		require.NoError(t, err)
+		assert.Equal(t, "workspace-1234-fghij", restored.WorkspacePVCName)
+		projectRepo.AssertExpectations(t)
+	})
+
+	t.Run("saves the new workspace PVC when the pod fails to start", func(t *testing.T) {
+		service, projectRepo, sessionRepo, k8sService := setupSnapshotServiceTest(5)
+		project := snapshotProject(userID)
+		projectRepo.On("FindByID", ctx, project.ID).Return(project, nil)
+		sessionRepo.On("FindActiveSessionsForProject", ctx, project.ID).Return([]model.Session{}, nil)
+		k8sService.On("RestoreWorkspaceSnapshot", mock.Anything, project, "snapshot-1234-abcde", mock.Anything).
+			Run(func(args mock.Arguments) {
+				p := args.Get(1).(*model.Project)
+				p.WorkspacePVCName = "workspace-1234-fghij"
+				require.NoError(t, args.Get(3).(func(*model.Project) error)(p))
+			}).Return(errors.New("failed to create pod"))
+		projectRepo.On("Update", mock.Anything, project).Return(nil)
+
+		_, err := service.RestoreSnapshot(ctx, project.ID, userID, "snapshot-1234-abcde")
+
+		assert.Error(t, err)
+		projectRepo.AssertCalled(t, "Update", mock.Anything, project)
+	})
+
+	t.Run("snapshot not ready", func(t *testing.T) {
+		service, projectRepo, sessionRepo, k8sService := setupSnapshotServiceTest(5)
+		project := snapshotProject(userID)
+		projectRepo.On("FindByID", ctx, project.ID).Return(project, nil)
+		sessionRepo.On("FindActiveSessionsForProject", ctx, project.ID).Return([]model.Session{}, nil)
+		k8sService.On("RestoreWorkspaceSnapshot", mock.Anything, project, "snapshot-1234-abcde", mock.Anything).Return(ErrSnapshotNotReady)
+
+		_, err := service.RestoreSnapshot(ctx, project.ID, userID, "snapshot-1234-abcde")
+
+		assert.ErrorIs(t, err, ErrSnapshotNotReady)
+		projectRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejected while a task runs", func(t *testing.T) {
+		service, projectRepo, sessionRepo, k8sService := setupSnapshotServiceTest(5)
+		project := snapshotProject(userID)
+		projectRepo.On("FindByID", ctx, project.ID).Return(project, nil)
+		sessionRepo.On("FindActiveSessionsForProject", ctx, project.ID).Return([]model.Session{{ID: uuid.New(), TaskID: uuid.New()}}, nil)
+
+		_, err := service.RestoreSnapshot(ctx, project.ID, userID, "snapshot-1234-abcde")
+
+		assert.ErrorIs(t, err, ErrWorkspaceBusy)
+		k8sService.AssertNotCalled(t, "RestoreWorkspaceSnapshot", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestWorkspaceSnapshotService_SnapshotBeforeTask(t *testing.T) {
+	ctx := context.Background()
+	taskID := uuid.New()
+
+	t.Run("prunes the oldest task snapshots", func(t *testing.T) {
+		service, projectRepo, _, k8sService := setupSnapshotServiceTest(2)
+		project := snapshotProject(uuid.New())
+		projectRepo.On("FindByID", ctx, project.ID).Return(project, nil)
+		k8sService.On("CreateWorkspaceSnapshot", ctx, project, &taskID).
+			Return(&WorkspaceSnapshot{Name: "snapshot-new", Trigger: SnapshotTriggerTask, TaskID: &taskID}, nil)
+		now := time.Now()
+		k8sService.On("ListWorkspaceSnapshots", ctx, project).Return([]WorkspaceSnapshot{
+			{Name: "snapshot-new", Trigger: SnapshotTriggerTask, CreatedAt: now},
+			{Name: "snapshot-manual", Trigger: SnapshotTriggerManual, CreatedAt: now.Add(-time.Minute)},
+			{Name: "snapshot-task-2", Trigger: SnapshotTriggerTask, CreatedAt: now.Add(-2 * time.Minute)},
+			{Name: "snapshot-task-1", Trigger: SnapshotTriggerTask, CreatedAt: now.Add(-3 * time.Minute)},
+		}, nil)
+		k8sService.On("DeleteWorkspaceSnapshot", ctx, project, "snapshot-task-1").Return(nil)
+
+		snapshot, err := service.SnapshotBeforeTask(ctx, project.ID, taskID)
+
+		require.NoError(t, err)
+		assert.Equal(t, "snapshot-new", snapshot.Name)
+		k8sService.AssertNumberOfCalls(t, "DeleteWorkspaceSnapshot", 1)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		service, projectRepo, _, k8sService := setupSnapshotServiceTest(0)
+
+		snapshot, err := service.SnapshotBeforeTask(ctx, uuid.New(), taskID)
+
+		require.NoError(t, err)
+		assert.Nil(t, snapshot)
+		projectRepo.AssertNotCalled(t, "FindByID", mock.Anything, mock.Anything)
+		k8sService.AssertNotCalled(t, "CreateWorkspaceSnapshot", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// storedProjectRepository keeps a copy of the project as saved, so that a project mutated in place
+// by a service is only seen by the pod reconciler once it is saved
+type storedProjectRepository struct {
+	*MockProjectRepository
+	mu      sync.Mutex
+	project model.Project
+}
+
+func (r *storedProjectRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.Project, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id != r.project.ID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	project := r.project
+	return &project, nil
+}
+
+func (r *storedProjectRepository) Update(ctx context.Context, project *model.Project) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.project = *project
+	return nil
+}
+
+func (r *storedProjectRepository) UpdatePodStatus(ctx context.Context, id uuid.UUID, status, podError string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.project.PodStatus = status
+	r.project.PodError = podError
+	return nil
+}
+
+func TestWorkspaceSnapshotService_RestoreSnapshotWithReconciler(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	clientset := fake.NewSimpleClientset()
+	project := &model.Project{ID: uuid.New(), UserID: userID, Name: "test-project", Status: model.ProjectStatusReady}
+	k8sService, _ := newSnapshotTestService(clientset,
+		testVolumeSnapshot("snapshot-ready", project.ID, SnapshotTriggerManual, true, time.Now()),
+	)
+	require.NoError(t, k8sService.CreateProjectPod(ctx, project))
+	previousPVC := project.WorkspacePVCName
+
+	projectRepo := &storedProjectRepository{MockProjectRepository: new(MockProjectRepository), project: *project}
+	sessionRepo := new(MockSessionRepository)
+	sessionRepo.On("FindActiveSessionsForProject", mock.Anything, project.ID).Return([]model.Session{}, nil)
+	// The fake clientset holds a lock while reacting, so the reconciler talks to the same objects
+	// through a clientset of its own
+	reconcilerClientset := &fake.Clientset{}
+	reconcilerClientset.AddReactor("*", "*", k8stesting.ObjectReaction(clientset.Tracker()))
+	reconcilerClientset.AddWatchReactor("*", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		watcher, err := clientset.Tracker().Watch(action.GetResource(), action.GetNamespace())
+		return true, watcher, err
+	})
+	reconcilerK8sService := *k8sService
+	reconcilerK8sService.clientset = reconcilerClientset
+	reconciler := NewPodReconciler(reconcilerClientset, snapshotTestNamespace, projectRepo, &reconcilerK8sService, time.Minute)
+	syncInformers(t, reconciler)
+
+	// Deleting the previous PVC fires an update of the PVC, on which the reconciler finds the project
+	// without its pod; run it then, as the informer would
+	clientset.PrependReactor("delete", "persistentvolumeclaims", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.(k8stesting.DeleteAction).GetName() != previousPVC {
+			return false, nil, nil
+		}
+		require.Eventually(t, func() bool {
+			_, err := reconciler.pods.Pods(snapshotTestNamespace).Get(project.PodName)
+			return err != nil
+		}, 5*time.Second, 10*time.Millisecond)
+		require.NoError(t, reconciler.reconcile(ctx, project.ID))
+		return false, nil, nil
+	})
+
+	service := NewWorkspaceSnapshotService(projectRepo, NewAuthorizationService(projectRepo, nil), sessionRepo, k8sService, 0)
+	restored, err := service.RestoreSnapshot(ctx, project.ID, userID, "snapshot-ready")
+	require.NoError(t, err)
+	require.NotEqual(t, previousPVC, restored.WorkspacePVCName)
+
+	pod, err := clientset.CoreV1().Pods(snapshotTestNamespace).Get(ctx, project.PodName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, restored.WorkspacePVCName, podWorkspaceClaim(pod), "pod must run on the restored PVC")
+	stored, err := projectRepo.FindByID(ctx, project.ID)
+	require.NoError(t, err)
+	assert.Equal(t, restored.WorkspacePVCName, stored.WorkspacePVCName)
+}
@@ -0,0 +1,12 @@
+-- Rollback repository clone settings
+
+UPDATE projects SET status = 'initializing' WHERE status = 'cloning';
+
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS projects_status_check;
+ALTER TABLE projects ADD CONSTRAINT projects_status_check
+    CHECK (status IN ('initializing', 'ready', 'error', 'archived'));
+
+ALTER TABLE projects
+DROP COLUMN IF EXISTS repo_ref,
+DROP COLUMN IF EXISTS repo_clone_depth,
+DROP COLUMN IF EXISTS repo_credential_encrypted;
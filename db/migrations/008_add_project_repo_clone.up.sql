@@ -0,0 +1,16 @@
+-- Add repository clone settings to projects
+-- The project pod clones repo_url into /workspace from an init container
+
+ALTER TABLE projects
+ADD COLUMN IF NOT EXISTS repo_ref VARCHAR(255),
+ADD COLUMN IF NOT EXISTS repo_clone_depth INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS repo_credential_encrypted BYTEA;
+
+-- Allow the 'cloning' status while the init container is running
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS projects_status_check;
+ALTER TABLE projects ADD CONSTRAINT projects_status_check
+    CHECK (status IN ('initializing', 'cloning', 'ready', 'error', 'archived'));
+
+COMMENT ON COLUMN projects.repo_ref IS 'Branch or tag to check out after cloning (empty = remote default branch)';
+COMMENT ON COLUMN projects.repo_clone_depth IS 'Shallow clone depth (0 = full history)';
+COMMENT ON COLUMN projects.repo_credential_encrypted IS 'AES-256-GCM encrypted access token or SSH key for private repositories';
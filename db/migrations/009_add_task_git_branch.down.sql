@@ -0,0 +1,9 @@
+-- Rollback task git branch tracking
+
+ALTER TABLE sessions
+DROP COLUMN IF EXISTS branch_name,
+DROP COLUMN IF EXISTS commit_sha;
+
+ALTER TABLE tasks
+DROP COLUMN IF EXISTS branch_name,
+DROP COLUMN IF EXISTS commit_sha;
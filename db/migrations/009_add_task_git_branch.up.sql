@@ -0,0 +1,15 @@
+-- Record the git branch and auto-commit produced by task execution
+-- Each task runs on its own branch; the session commit is created when the session completes
+
+ALTER TABLE tasks
+ADD COLUMN IF NOT EXISTS branch_name VARCHAR(255),
+ADD COLUMN IF NOT EXISTS commit_sha VARCHAR(64);
+
+ALTER TABLE sessions
+ADD COLUMN IF NOT EXISTS branch_name VARCHAR(255),
+ADD COLUMN IF NOT EXISTS commit_sha VARCHAR(64);
+
+COMMENT ON COLUMN tasks.branch_name IS 'Dedicated git branch for the task (task/<short-id>-<slug>)';
+COMMENT ON COLUMN tasks.commit_sha IS 'SHA of the most recent automatic commit for the task';
+COMMENT ON COLUMN sessions.branch_name IS 'Git branch checked out while the session ran';
+COMMENT ON COLUMN sessions.commit_sha IS 'SHA of the commit created when the session completed (empty if nothing changed)';
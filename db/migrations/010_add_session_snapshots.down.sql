@@ -0,0 +1,5 @@
+-- Rollback session workspace snapshots
+
+ALTER TABLE sessions
+DROP COLUMN IF EXISTS base_snapshot,
+DROP COLUMN IF EXISTS final_snapshot;
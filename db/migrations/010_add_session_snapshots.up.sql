@@ -0,0 +1,9 @@
+-- Record workspace snapshots taken around each session
+-- The file-browser sidecar stores snapshots in a private git repository under /workspace/.vibe
+
+ALTER TABLE sessions
+ADD COLUMN IF NOT EXISTS base_snapshot VARCHAR(64),
+ADD COLUMN IF NOT EXISTS final_snapshot VARCHAR(64);
+
+COMMENT ON COLUMN sessions.base_snapshot IS 'Workspace snapshot id taken before the session started';
+COMMENT ON COLUMN sessions.final_snapshot IS 'Workspace snapshot id taken when the session finished (empty while running)';
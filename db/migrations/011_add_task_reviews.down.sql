@@ -0,0 +1,7 @@
+-- Rollback task reviews
+
+DROP INDEX IF EXISTS idx_review_comments_review_id;
+DROP INDEX IF EXISTS idx_task_reviews_task_created;
+
+DROP TABLE IF EXISTS review_comments;
+DROP TABLE IF EXISTS task_reviews;
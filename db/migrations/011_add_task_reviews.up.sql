@@ -0,0 +1,30 @@
+-- Human review decisions on task sessions, with comments anchored to the session diff
+CREATE TABLE IF NOT EXISTS task_reviews (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    session_id UUID REFERENCES sessions(id) ON DELETE SET NULL,
+    reviewer_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    decision VARCHAR(20) NOT NULL,
+    summary TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT check_review_decision CHECK (decision IN ('approved', 'changes_requested'))
+);
+
+CREATE TABLE IF NOT EXISTS review_comments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    review_id UUID NOT NULL REFERENCES task_reviews(id) ON DELETE CASCADE,
+    file_path TEXT,
+    line INTEGER NOT NULL DEFAULT 0,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT check_review_comment_line CHECK (line >= 0)
+);
+
+CREATE INDEX idx_task_reviews_task_created ON task_reviews(task_id, created_at DESC);
+CREATE INDEX idx_review_comments_review_id ON review_comments(review_id);
+
+COMMENT ON COLUMN task_reviews.session_id IS 'Session whose changes were reviewed (the latest session of the task at review time)';
+COMMENT ON COLUMN review_comments.line IS 'Line in the new version of file_path; 0 when the comment applies to the whole file';
@@ -0,0 +1,12 @@
+-- Rollback automated AI review stage
+
+ALTER TABLE opencode_configs
+DROP COLUMN IF EXISTS review_system_prompt,
+DROP COLUMN IF EXISTS review_model_name,
+DROP COLUMN IF EXISTS ai_review_enabled;
+
+ALTER TABLE tasks
+DROP COLUMN IF EXISTS ai_review_verdict;
+
+ALTER TABLE sessions
+DROP COLUMN IF EXISTS kind;
@@ -0,0 +1,18 @@
+-- Automated AI review stage: reviewer sessions, structured verdicts and reviewer settings
+
+ALTER TABLE sessions
+ADD COLUMN IF NOT EXISTS kind VARCHAR(20) NOT NULL DEFAULT 'execution';
+
+ALTER TABLE tasks
+ADD COLUMN IF NOT EXISTS ai_review_verdict JSONB;
+
+ALTER TABLE opencode_configs
+ADD COLUMN IF NOT EXISTS ai_review_enabled BOOLEAN NOT NULL DEFAULT true,
+ADD COLUMN IF NOT EXISTS review_model_name VARCHAR(100),
+ADD COLUMN IF NOT EXISTS review_system_prompt TEXT;
+
+COMMENT ON COLUMN sessions.kind IS 'execution (works on the task) or review (reviews the previous execution session)';
+COMMENT ON COLUMN tasks.ai_review_verdict IS 'Latest AI review verdict: passed flag, summary and findings';
+COMMENT ON COLUMN opencode_configs.ai_review_enabled IS 'Run a reviewer session after each completed execution session';
+COMMENT ON COLUMN opencode_configs.review_model_name IS 'Reviewer model (same provider as model_name); NULL uses model_name';
+COMMENT ON COLUMN opencode_configs.review_system_prompt IS 'Reviewer system prompt; NULL uses the built-in review prompt';
@@ -0,0 +1,4 @@
+-- Rollback session final output
+
+ALTER TABLE sessions
+DROP COLUMN IF EXISTS final_output;
@@ -0,0 +1,7 @@
+-- Keep the agent's final answer apart from the streamed session transcript
+
+ALTER TABLE sessions
+ADD COLUMN IF NOT EXISTS final_output TEXT;
+
+COMMENT ON COLUMN sessions.output IS 'Transcript persisted from the sidecar event stream';
+COMMENT ON COLUMN sessions.final_output IS 'Final agent response reported when the session completed';
@@ -0,0 +1,7 @@
+-- Rollback session limits
+
+ALTER TABLE sessions
+DROP COLUMN IF EXISTS deadline_at,
+DROP COLUMN IF EXISTS iterations,
+DROP COLUMN IF EXISTS max_iterations,
+DROP COLUMN IF EXISTS timeout_seconds;
@@ -0,0 +1,12 @@
+-- Record the execution limits each session runs with, so the backend can enforce them
+
+ALTER TABLE sessions
+ADD COLUMN IF NOT EXISTS timeout_seconds INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS max_iterations INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS iterations INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS deadline_at TIMESTAMP;
+
+COMMENT ON COLUMN sessions.timeout_seconds IS 'Timeout of the current run, from the project config (0 = none)';
+COMMENT ON COLUMN sessions.max_iterations IS 'Tool call limit of the current run, from the project config (0 = none)';
+COMMENT ON COLUMN sessions.iterations IS 'Tool calls made in the current run';
+COMMENT ON COLUMN sessions.deadline_at IS 'When the current run exceeds its timeout';
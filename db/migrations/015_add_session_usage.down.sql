@@ -0,0 +1,10 @@
+-- Rollback session usage
+
+DROP INDEX IF EXISTS idx_sessions_project_created;
+
+ALTER TABLE sessions
+DROP COLUMN IF EXISTS cost_usd,
+DROP COLUMN IF EXISTS output_tokens,
+DROP COLUMN IF EXISTS input_tokens,
+DROP COLUMN IF EXISTS model_name,
+DROP COLUMN IF EXISTS model_provider;
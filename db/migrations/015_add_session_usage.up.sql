@@ -0,0 +1,16 @@
+-- Record the model and token usage of each session, so their cost can be reported per task and project
+
+ALTER TABLE sessions
+ADD COLUMN IF NOT EXISTS model_provider VARCHAR(50),
+ADD COLUMN IF NOT EXISTS model_name VARCHAR(100),
+ADD COLUMN IF NOT EXISTS input_tokens BIGINT NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS output_tokens BIGINT NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS cost_usd NUMERIC(12,6) NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_sessions_project_created ON sessions(project_id, created_at);
+
+COMMENT ON COLUMN sessions.model_provider IS 'Provider of the model the session last ran with';
+COMMENT ON COLUMN sessions.model_name IS 'Model the session last ran with';
+COMMENT ON COLUMN sessions.input_tokens IS 'Input tokens used by all runs of the session';
+COMMENT ON COLUMN sessions.output_tokens IS 'Output tokens used by all runs of the session';
+COMMENT ON COLUMN sessions.cost_usd IS 'Cost of the tokens used, priced with the model registry at the time of use';
@@ -0,0 +1,3 @@
+-- Rollback budgets
+
+DROP TABLE IF EXISTS budgets;
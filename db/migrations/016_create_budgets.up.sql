@@ -0,0 +1,25 @@
+-- Monthly spending budgets of projects and users; sessions stop once a budget is used up
+
+CREATE TABLE IF NOT EXISTS budgets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    scope VARCHAR(20) NOT NULL,
+    scope_id UUID NOT NULL,
+    monthly_cost_usd NUMERIC(12,2),
+    monthly_tokens BIGINT,
+    warn_percent INTEGER NOT NULL DEFAULT 80,
+    warned_period VARCHAR(7),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT check_budget_scope CHECK (scope IN ('project', 'user')),
+    CONSTRAINT check_budget_cost CHECK (monthly_cost_usd IS NULL OR monthly_cost_usd >= 0),
+    CONSTRAINT check_budget_tokens CHECK (monthly_tokens IS NULL OR monthly_tokens >= 0),
+    CONSTRAINT check_budget_warn_percent CHECK (warn_percent BETWEEN 1 AND 100),
+    CONSTRAINT unique_budget_scope UNIQUE (scope, scope_id)
+);
+
+COMMENT ON COLUMN budgets.scope_id IS 'Project or user the budget applies to; a user budget covers all projects the user owns';
+COMMENT ON COLUMN budgets.monthly_cost_usd IS 'Spending limit per calendar month (UTC) in USD; NULL for no limit';
+COMMENT ON COLUMN budgets.monthly_tokens IS 'Input and output token limit per calendar month (UTC); NULL for no limit';
+COMMENT ON COLUMN budgets.warn_percent IS 'Share of the budget at which a warning is posted';
+COMMENT ON COLUMN budgets.warned_period IS 'Month (YYYY-MM) the warning was last posted for';
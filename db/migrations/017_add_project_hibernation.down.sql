@@ -0,0 +1,14 @@
+-- Rollback project hibernation
+-- Hibernated projects have no pod; mark them as errored so they are not mistaken for running ones
+
+UPDATE projects SET status = 'error', pod_error = 'Pod was hibernated' WHERE status = 'hibernated';
+
+DROP INDEX IF EXISTS idx_projects_pod_name;
+
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS projects_status_check;
+ALTER TABLE projects ADD CONSTRAINT projects_status_check
+    CHECK (status IN ('initializing', 'cloning', 'ready', 'error', 'archived'));
+
+ALTER TABLE projects
+DROP COLUMN IF EXISTS last_activity_at,
+DROP COLUMN IF EXISTS hibernated_at;
@@ -0,0 +1,15 @@
+-- Hibernate idle projects: their pod is deleted while the workspace PVC is kept
+
+ALTER TABLE projects
+ADD COLUMN IF NOT EXISTS last_activity_at TIMESTAMP,
+ADD COLUMN IF NOT EXISTS hibernated_at TIMESTAMP;
+
+-- Allow the 'hibernated' status for projects without a running pod
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS projects_status_check;
+ALTER TABLE projects ADD CONSTRAINT projects_status_check
+    CHECK (status IN ('initializing', 'cloning', 'ready', 'hibernated', 'error', 'archived'));
+
+CREATE INDEX IF NOT EXISTS idx_projects_pod_name ON projects(pod_name);
+
+COMMENT ON COLUMN projects.last_activity_at IS 'Last time the project pod was used (sessions, file API, terminals)';
+COMMENT ON COLUMN projects.hibernated_at IS 'When the project pod was deleted for being idle';
@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS resource_caps;
+
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS check_project_resource_profile;
+
+ALTER TABLE projects
+DROP COLUMN IF EXISTS storage_class,
+DROP COLUMN IF EXISTS storage_size,
+DROP COLUMN IF EXISTS memory_limit,
+DROP COLUMN IF EXISTS memory_request,
+DROP COLUMN IF EXISTS cpu_limit,
+DROP COLUMN IF EXISTS cpu_request,
+DROP COLUMN IF EXISTS resource_profile;
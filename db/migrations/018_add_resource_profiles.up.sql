@@ -0,0 +1,33 @@
+-- Per-project resource profiles and the caps limiting which profiles each user may choose
+
+-- Existing projects keep the resources every project got so far, which the 'medium' profile matches
+ALTER TABLE projects
+ADD COLUMN IF NOT EXISTS resource_profile VARCHAR(20) NOT NULL DEFAULT 'medium',
+ADD COLUMN IF NOT EXISTS cpu_request VARCHAR(20) NOT NULL DEFAULT '100m',
+ADD COLUMN IF NOT EXISTS cpu_limit VARCHAR(20) NOT NULL DEFAULT '1000m',
+ADD COLUMN IF NOT EXISTS memory_request VARCHAR(20) NOT NULL DEFAULT '256Mi',
+ADD COLUMN IF NOT EXISTS memory_limit VARCHAR(20) NOT NULL DEFAULT '1Gi',
+ADD COLUMN IF NOT EXISTS storage_size VARCHAR(20) NOT NULL DEFAULT '1Gi',
+ADD COLUMN IF NOT EXISTS storage_class VARCHAR(255) NOT NULL DEFAULT '';
+
+ALTER TABLE projects ADD CONSTRAINT check_project_resource_profile
+    CHECK (resource_profile IN ('small', 'medium', 'large', 'custom'));
+
+COMMENT ON COLUMN projects.resource_profile IS 'Resource profile the project runs with: small, medium, large or custom';
+COMMENT ON COLUMN projects.cpu_limit IS 'CPU limit of the opencode-server and session-proxy containers of the project pod, as a Kubernetes quantity';
+COMMENT ON COLUMN projects.memory_limit IS 'Memory limit of the opencode-server and session-proxy containers of the project pod, as a Kubernetes quantity';
+COMMENT ON COLUMN projects.storage_size IS 'Size of the workspace PVC; it can grow but not shrink';
+COMMENT ON COLUMN projects.storage_class IS 'Storage class of the workspace PVC; empty for the cluster default';
+
+CREATE TABLE IF NOT EXISTS resource_caps (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    max_cpu VARCHAR(20) NOT NULL,
+    max_memory VARCHAR(20) NOT NULL,
+    max_storage VARCHAR(20) NOT NULL,
+    allow_custom BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE resource_caps IS 'Admin-defined resource caps of a user; users without one get the configured default caps';
+COMMENT ON COLUMN resource_caps.allow_custom IS 'Whether the user may choose custom resources within the caps';
@@ -0,0 +1,4 @@
+ALTER TABLE projects
+DROP COLUMN IF EXISTS archived_at,
+DROP COLUMN IF EXISTS archive_size,
+DROP COLUMN IF EXISTS archive_key;
@@ -0,0 +1,10 @@
+-- Archive projects: their workspace is exported to the archive store and their pod and PVC deleted
+
+ALTER TABLE projects
+ADD COLUMN IF NOT EXISTS archive_key VARCHAR(255) NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS archive_size BIGINT NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP;
+
+COMMENT ON COLUMN projects.archive_key IS 'Key of the workspace tarball in the archive store; empty unless the project was archived';
+COMMENT ON COLUMN projects.archive_size IS 'Size in bytes of the workspace tarball';
+COMMENT ON COLUMN projects.archived_at IS 'When the project was last archived';
@@ -0,0 +1,3 @@
+-- Rollback project templates
+
+DROP TABLE IF EXISTS project_templates;
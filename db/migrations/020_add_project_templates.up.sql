@@ -0,0 +1,30 @@
+-- Project templates saved by users; the builtin templates ship with the application
+
+CREATE TABLE IF NOT EXISTS project_templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    repo_url TEXT NOT NULL DEFAULT '',
+    repo_ref VARCHAR(255) NOT NULL DEFAULT '',
+    repo_clone_depth INTEGER NOT NULL DEFAULT 0,
+    files JSONB,
+    config JSONB,
+    tasks JSONB,
+    resource_profile VARCHAR(20) NOT NULL DEFAULT 'medium',
+    cpu_limit VARCHAR(20) NOT NULL DEFAULT '',
+    memory_limit VARCHAR(20) NOT NULL DEFAULT '',
+    storage_size VARCHAR(20) NOT NULL DEFAULT '',
+    storage_class VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT check_template_resource_profile CHECK (resource_profile IN ('small', 'medium', 'large', 'custom'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_templates_user_id ON project_templates(user_id);
+
+COMMENT ON COLUMN project_templates.files IS 'Seed files written into the workspace of new projects, as a map of relative path to content';
+COMMENT ON COLUMN project_templates.config IS 'OpenCode configuration of new projects, without the API key';
+COMMENT ON COLUMN project_templates.tasks IS 'Starter tasks created in the todo column of new projects';
+COMMENT ON COLUMN project_templates.cpu_limit IS 'CPU limit of the custom resource profile; empty for named profiles';
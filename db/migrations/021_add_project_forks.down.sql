@@ -0,0 +1,5 @@
+-- Rollback project forks
+
+DROP INDEX IF EXISTS idx_projects_parent_project_id;
+
+ALTER TABLE projects DROP COLUMN IF EXISTS parent_project_id;
@@ -0,0 +1,8 @@
+-- Forks: projects created from the workspace, active configuration and tasks of another project
+
+ALTER TABLE projects
+ADD COLUMN IF NOT EXISTS parent_project_id UUID REFERENCES projects(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_projects_parent_project_id ON projects(parent_project_id);
+
+COMMENT ON COLUMN projects.parent_project_id IS 'Project this project was forked from; NULL for projects that are not forks';
@@ -0,0 +1,6 @@
+-- Rollback project sharing
+
+DROP TRIGGER IF EXISTS update_project_invitations_updated_at ON project_invitations;
+DROP TRIGGER IF EXISTS update_project_members_updated_at ON project_members;
+DROP TABLE IF EXISTS project_invitations;
+DROP TABLE IF EXISTS project_members;
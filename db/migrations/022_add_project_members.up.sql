@@ -0,0 +1,40 @@
+-- Project sharing: members with a role and pending invitations by email
+
+CREATE TABLE IF NOT EXISTS project_members (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL,
+    invited_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT unique_project_member UNIQUE(project_id, user_id),
+    CONSTRAINT check_project_member_role CHECK (role IN ('maintainer', 'viewer'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_members_user_id ON project_members(user_id);
+
+CREATE TABLE IF NOT EXISTS project_invitations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    invited_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT unique_project_invitation UNIQUE(project_id, email),
+    CONSTRAINT check_project_invitation_role CHECK (role IN ('maintainer', 'viewer'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_invitations_email ON project_invitations(email);
+
+CREATE TRIGGER update_project_members_updated_at BEFORE UPDATE ON project_members
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_project_invitations_updated_at BEFORE UPDATE ON project_invitations
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE project_members IS 'Users other than the owner with access to a project; the owner is projects.user_id';
+COMMENT ON COLUMN project_invitations.email IS 'Lowercase email address; the invitation is offered to the user signing in with it';
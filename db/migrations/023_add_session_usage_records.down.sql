@@ -0,0 +1,3 @@
+-- Rollback session usage records
+
+DROP TABLE IF EXISTS session_usage;
@@ -0,0 +1,26 @@
+-- Record the usage of each model call, so spending is attributed to the time it happened rather than
+-- the time its session started
+
+CREATE TABLE IF NOT EXISTS session_usage (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    session_id UUID NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL,
+    model_provider VARCHAR(50),
+    model_name VARCHAR(100),
+    input_tokens BIGINT NOT NULL DEFAULT 0,
+    output_tokens BIGINT NOT NULL DEFAULT 0,
+    cost_usd NUMERIC(12,6) NOT NULL DEFAULT 0,
+    recorded_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_session_usage_project_recorded ON session_usage(project_id, recorded_at);
+CREATE INDEX IF NOT EXISTS idx_session_usage_session_id ON session_usage(session_id);
+
+-- Usage recorded before this migration only has the session totals; attribute it to when the session started
+INSERT INTO session_usage (session_id, project_id, model_provider, model_name, input_tokens, output_tokens, cost_usd, recorded_at)
+SELECT id, project_id, model_provider, model_name, input_tokens, output_tokens, cost_usd, created_at
+FROM sessions
+WHERE input_tokens > 0 OR output_tokens > 0 OR cost_usd > 0;
+
+COMMENT ON TABLE session_usage IS 'Tokens and cost of each model call made by a session; sessions keep the running totals';
+COMMENT ON COLUMN session_usage.project_id IS 'Project of the session, kept so usage of deleted projects still counts toward user budgets';
@@ -0,0 +1,6 @@
+-- Rollback active session time
+
+COMMENT ON COLUMN sessions.duration_ms IS 'Execution duration in milliseconds';
+
+ALTER TABLE sessions
+DROP COLUMN IF EXISTS run_started_at;
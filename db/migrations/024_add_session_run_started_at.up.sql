@@ -0,0 +1,10 @@
+-- Active session time: the duration only counts runs, not the pauses between them
+
+ALTER TABLE sessions
+ADD COLUMN IF NOT EXISTS run_started_at TIMESTAMP;
+
+-- Sessions running now started their current run when they started
+UPDATE sessions SET run_started_at = started_at WHERE status IN ('pending', 'running');
+
+COMMENT ON COLUMN sessions.run_started_at IS 'Start of the current run; NULL while the session is paused or finished';
+COMMENT ON COLUMN sessions.duration_ms IS 'Time spent running in milliseconds, excluding pauses between runs';
@@ -39,6 +39,8 @@ export function ExecutionHistory({ projectId, taskId }: ExecutionHistoryProps) {
         return <span className={`${baseClasses} bg-green-100 text-green-800`}>Completed</span>
       case 'running':
         return <span className={`${baseClasses} bg-blue-100 text-blue-800`}>Running</span>
+      case 'waiting_for_input':
+        return <span className={`${baseClasses} bg-purple-100 text-purple-800`}>Waiting for input</span>
       case 'failed':
         return <span className={`${baseClasses} bg-red-100 text-red-800`}>Failed</span>
       case 'cancelled':
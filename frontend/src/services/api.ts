@@ -127,6 +127,19 @@ export async function getTaskSessions(projectId: string, taskId: string): Promis
   return response.data
 }
 
+export async function continueSession(
+  projectId: string,
+  taskId: string,
+  sessionId: string,
+  prompt: string
+): Promise<Session> {
+  const response = await api.post<Session>(
+    `/projects/${projectId}/tasks/${taskId}/sessions/${sessionId}/prompt`,
+    { prompt }
+  )
+  return response.data
+}
+
 export async function getFileTree(projectId: string, includeHidden = false): Promise<FileInfo> {
   const response = await api.get<FileInfo>(`/projects/${projectId}/files/tree`, {
     params: { include_hidden: includeHidden },
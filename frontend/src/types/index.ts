@@ -40,12 +40,32 @@ export interface Task {
   opencode_output?: string
   execution_duration_ms: number
   file_references?: Record<string, unknown>
+  branch_name?: string
+  commit_sha?: string
+  ai_review_verdict?: AIReviewVerdict
   created_by: string
   created_at: string
   updated_at: string
   deleted_at?: string
 }
 
+export interface AIReviewFinding {
+  severity: 'error' | 'warning' | 'info'
+  file_path?: string
+  line?: number
+  message: string
+}
+
+export interface AIReviewVerdict {
+  passed: boolean
+  summary?: string
+  findings: AIReviewFinding[]
+  error?: string
+  session_id: string
+  reviewed_session_id: string
+  reviewed_at: string
+}
+
 export interface OpenCodeConfig {
   id: string
   project_id: string
@@ -72,6 +92,11 @@ export interface OpenCodeConfig {
   max_iterations: number
   timeout_seconds: number
   
+  // AI review configuration
+  ai_review_enabled: boolean
+  review_model_name?: string
+  review_system_prompt?: string
+  
   // Metadata
   created_by: string
   created_at: string
@@ -91,6 +116,9 @@ export interface CreateConfigRequest {
   system_prompt?: string
   max_iterations: number
   timeout_seconds: number
+  ai_review_enabled?: boolean
+  review_model_name?: string
+  review_system_prompt?: string
 }
 
 export interface OpenCodeSession {
@@ -99,7 +127,12 @@ export interface OpenCodeSession {
   task_id?: string
   remote_session_id?: string
   status: string
+  kind?: 'execution' | 'review'
   prompt: string
+  branch_name?: string
+  commit_sha?: string
+  base_snapshot?: string
+  final_snapshot?: string
   final_output?: string
   exit_code?: number
   execution_start_at?: string
@@ -108,6 +141,29 @@ export interface OpenCodeSession {
   updated_at: string
 }
 
+export type ReviewDecision = 'approved' | 'changes_requested'
+
+export interface ReviewComment {
+  id: string
+  review_id: string
+  file_path?: string
+  line?: number
+  body: string
+  created_at: string
+}
+
+export interface TaskReview {
+  id: string
+  task_id: string
+  session_id?: string
+  reviewer_id: string
+  decision: ReviewDecision
+  summary?: string
+  comments: ReviewComment[]
+  created_at: string
+  updated_at: string
+}
+
 export type PodStatus = 'Pending' | 'Running' | 'Succeeded' | 'Failed' | 'Unknown'
 
 export type MessageType = 'user_message' | 'agent_response' | 'system_notification'
@@ -201,7 +257,7 @@ export interface TaskExecutionState {
   error: string | null
 }
 
-export type SessionStatus = 'pending' | 'running' | 'completed' | 'failed' | 'cancelled'
+export type SessionStatus = 'pending' | 'running' | 'waiting_for_input' | 'completed' | 'failed' | 'cancelled'
 
 export interface Session {
   id: string
@@ -45,6 +45,9 @@ func main() {
 	router := gin.Default()
 
 	fileHandler := handler.NewFileHandler(workspaceDir)
+	// Pushes only go to the project repository; git commands never see the credential in their environment
+	gitHandler := handler.NewGitHandler(workspaceDir, os.Getenv("REPO_URL"), os.Getenv("REPO_CREDENTIAL"))
+	archiveHandler := handler.NewArchiveHandler(workspaceDir)
 
 	// Initialize file watcher for real-time change notifications
 	fileWatcher, err := service.NewFileWatcher(workspaceDir)
@@ -88,6 +91,25 @@ func main() {
 		files.GET("/watch", watchHandler.FileChangesStream)
 	}
 
+	git := router.Group("/git")
+	{
+		git.GET("/status", gitHandler.GetStatus)
+		git.GET("/diff", gitHandler.GetDiff)
+		git.POST("/commit", gitHandler.Commit)
+		git.POST("/branch", gitHandler.CreateBranch)
+		git.POST("/checkout", gitHandler.Checkout)
+		git.POST("/push", gitHandler.Push)
+		git.POST("/snapshots", gitHandler.CreateSnapshot)
+		git.POST("/snapshots/prune", gitHandler.PruneSnapshots)
+		git.GET("/snapshots/diff", gitHandler.DiffSnapshots)
+	}
+
+	workspace := router.Group("/workspace")
+	{
+		workspace.GET("/archive", archiveHandler.ExportWorkspace)
+		workspace.PUT("/archive", archiveHandler.ImportWorkspace)
+	}
+
 	srv := &http.Server{
 		Addr:    ":" + port,
 		Handler: router,
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/npinot/vibe/sidecars/file-browser/internal/service"
+)
+
+type ArchiveHandler struct {
+	archiveService *service.ArchiveService
+}
+
+func NewArchiveHandler(workspaceDir string) *ArchiveHandler {
+	return &ArchiveHandler{
+		archiveService: service.NewArchiveService(workspaceDir),
+	}
+}
+
+// archiveCompleteTrailer is set once the whole workspace is streamed. The status is sent with the
+// first bytes, so clients tell a complete archive from a stream cut short by a failure through it.
+const archiveCompleteTrailer = "X-Archive-Complete"
+
+// ExportWorkspace streams the workspace as a gzipped tarball
+func (h *ArchiveHandler) ExportWorkspace(c *gin.Context) {
+	c.Header("Content-Type", "application/gzip")
+	c.Header("Trailer", archiveCompleteTrailer)
+	c.Status(http.StatusOK)
+
+	if err := h.archiveService.Export(c.Writer); err != nil {
+		slog.Error("Workspace export failed", "error", err)
+		return
+	}
+	c.Writer.Header().Set(archiveCompleteTrailer, "true")
+}
+
+// ImportWorkspace replaces the workspace with the gzipped tarball in the request body
+func (h *ArchiveHandler) ImportWorkspace(c *gin.Context) {
+	if err := h.archiveService.Import(c.Request.Body); err != nil {
+		if errors.Is(err, service.ErrInvalidArchive) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		slog.Error("Workspace import failed", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Workspace import failed"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
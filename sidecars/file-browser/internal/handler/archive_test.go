@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setupArchiveTestRouter(workspaceDir string) *gin.Engine {
+	handler := NewArchiveHandler(workspaceDir)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/workspace/archive", handler.ExportWorkspace)
+	router.PUT("/workspace/archive", handler.ImportWorkspace)
+
+	return router
+}
+
+func TestArchiveHandler_ExportImport(t *testing.T) {
+	source := t.TempDir()
+	os.WriteFile(filepath.Join(source, "README.md"), []byte("# Project\n"), 0644)
+
+	req := httptest.NewRequest("GET", "/workspace/archive", nil)
+	w := httptest.NewRecorder()
+	setupArchiveTestRouter(source).ServeHTTP(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if resp.Header.Get("Content-Type") != "application/gzip" {
+		t.Errorf("Expected gzip content type, got %q", resp.Header.Get("Content-Type"))
+	}
+	if resp.Trailer.Get(archiveCompleteTrailer) != "true" {
+		t.Errorf("Expected %s trailer", archiveCompleteTrailer)
+	}
+
+	target := t.TempDir()
+	req = httptest.NewRequest("PUT", "/workspace/archive", bytes.NewReader(w.Body.Bytes()))
+	w = httptest.NewRecorder()
+	setupArchiveTestRouter(target).ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204, got %d: %s", w.Code, w.Body.String())
+	}
+	content, err := os.ReadFile(filepath.Join(target, "README.md"))
+	if err != nil || string(content) != "# Project\n" {
+		t.Errorf("Expected restored README.md, got %q (%v)", content, err)
+	}
+}
+
+func TestArchiveHandler_ImportInvalidArchive(t *testing.T) {
+	req := httptest.NewRequest("PUT", "/workspace/archive", bytes.NewReader([]byte("garbage")))
+	w := httptest.NewRecorder()
+	setupArchiveTestRouter(t.TempDir()).ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
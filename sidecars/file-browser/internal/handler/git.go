@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/npinot/vibe/sidecars/file-browser/internal/service"
+)
+
+type GitHandler struct {
+	gitService *service.GitService
+}
+
+func NewGitHandler(workspaceDir, remoteURL, credential string) *GitHandler {
+	return &GitHandler{
+		gitService: service.NewGitService(workspaceDir, remoteURL, credential),
+	}
+}
+
+func (h *GitHandler) GetStatus(c *gin.Context) {
+	status, err := h.gitService.Status(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+func (h *GitHandler) GetDiff(c *gin.Context) {
+	path := c.Query("path")
+	staged := c.Query("staged") == "true"
+
+	diff, err := h.gitService.Diff(c.Request.Context(), path, staged)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"diff": diff, "path": path, "staged": staged})
+}
+
+func (h *GitHandler) Commit(c *gin.Context) {
+	var req struct {
+		Message     string   `json:"message" binding:"required"`
+		AuthorName  string   `json:"author_name" binding:"required"`
+		AuthorEmail string   `json:"author_email" binding:"required"`
+		Paths       []string `json:"paths"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	author := service.GitAuthor{Name: req.AuthorName, Email: req.AuthorEmail}
+	result, err := h.gitService.Commit(c.Request.Context(), req.Message, author, req.Paths)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+func (h *GitHandler) CreateBranch(c *gin.Context) {
+	var req struct {
+		Name       string `json:"name" binding:"required"`
+		StartPoint string `json:"start_point"`
+		Checkout   bool   `json:"checkout"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.gitService.CreateBranch(c.Request.Context(), req.Name, req.StartPoint, req.Checkout); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Branch created successfully", "branch": req.Name, "checked_out": req.Checkout})
+}
+
+func (h *GitHandler) Checkout(c *gin.Context) {
+	var req struct {
+		Branch string `json:"branch" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.gitService.Checkout(c.Request.Context(), req.Branch); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Checked out branch", "branch": req.Branch})
+}
+
+func (h *GitHandler) Push(c *gin.Context) {
+	var req struct {
+		Remote string `json:"remote"`
+		Branch string `json:"branch"`
+	}
+
+	// An empty body pushes the current branch to origin
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	branch, err := h.gitService.Push(c.Request.Context(), req.Remote, req.Branch)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	remote := req.Remote
+	if remote == "" {
+		remote = "origin"
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Pushed successfully", "remote": remote, "branch": branch})
+}
+
+func (h *GitHandler) CreateSnapshot(c *gin.Context) {
+	var req struct {
+		Session string `json:"session"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	id, err := h.gitService.Snapshot(c.Request.Context(), req.Session)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": id})
+}
+
+func (h *GitHandler) PruneSnapshots(c *gin.Context) {
+	var req struct {
+		Keep map[string][]string `json:"keep"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.gitService.PruneSnapshots(c.Request.Context(), req.Keep); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Snapshots pruned"})
+}
+
+func (h *GitHandler) DiffSnapshots(c *gin.Context) {
+	from := c.Query("from")
+	if from == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from parameter is required"})
+		return
+	}
+
+	// An empty "to" compares against the live workspace
+	diff, err := h.gitService.DiffSnapshots(c.Request.Context(), from, c.Query("to"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+func (h *GitHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrNotRepository):
+		c.JSON(http.StatusConflict, gin.H{"error": "Workspace is not a git repository"})
+	case errors.Is(err, service.ErrInvalidPath):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Invalid path: directory traversal detected"})
+	case errors.Is(err, service.ErrNothingToCommit):
+		c.JSON(http.StatusConflict, gin.H{"error": "Nothing to commit"})
+	case errors.Is(err, service.ErrMessageRequired),
+		errors.Is(err, service.ErrAuthorRequired),
+		errors.Is(err, service.ErrInvalidBranchName),
+		errors.Is(err, service.ErrInvalidSnapshot):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrBranchExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "Branch already exists"})
+	case errors.Is(err, service.ErrBranchNotFound), errors.Is(err, service.ErrRemoteNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrUncommittedChanges):
+		c.JSON(http.StatusConflict, gin.H{"error": "Uncommitted changes would be overwritten by checkout"})
+	case errors.Is(err, service.ErrPushRejected):
+		c.JSON(http.StatusConflict, gin.H{"error": "Push rejected by remote (fetch and merge first)"})
+	case errors.Is(err, service.ErrUnsafeRepoConfig):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		slog.Error("Git operation failed", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Git operation failed"})
+	}
+}
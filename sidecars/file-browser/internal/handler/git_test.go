@@ -0,0 +1,305 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setupGitTestRouter(t *testing.T) (*gin.Engine, string) {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+
+	tmpDir := t.TempDir()
+	cmd := exec.Command("git", "init", "--quiet", "--initial-branch=main", tmpDir)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v\n%s", err, out)
+	}
+
+	handler := NewGitHandler(tmpDir, "", "")
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/git/status", handler.GetStatus)
+	router.GET("/git/diff", handler.GetDiff)
+	router.POST("/git/commit", handler.Commit)
+	router.POST("/git/branch", handler.CreateBranch)
+	router.POST("/git/checkout", handler.Checkout)
+
+	return router, tmpDir
+}
+
+func TestGitHandler_NotRepository(t *testing.T) {
+	handler := NewGitHandler(t.TempDir(), "", "")
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/git/status", handler.GetStatus)
+
+	req := httptest.NewRequest("GET", "/git/status", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409, got %d", w.Code)
+	}
+}
+
+func TestGitHandler_Commit(t *testing.T) {
+	router, tmpDir := setupGitTestRouter(t)
+
+	t.Run("missing author", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]string{"message": "Initial commit"})
+		req := httptest.NewRequest("POST", "/git/commit", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("nothing to commit", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]string{
+			"message":      "Initial commit",
+			"author_name":  "Jane Doe",
+			"author_email": "jane@example.com",
+		})
+		req := httptest.NewRequest("POST", "/git/commit", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusConflict {
+			t.Errorf("Expected status 409, got %d", w.Code)
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main\n"), 0644)
+
+		body, _ := json.Marshal(map[string]string{
+			"message":      "Initial commit",
+			"author_name":  "Jane Doe",
+			"author_email": "jane@example.com",
+		})
+		req := httptest.NewRequest("POST", "/git/commit", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var result map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &result)
+		if result["branch"] != "main" {
+			t.Errorf("Expected branch main, got %v", result["branch"])
+		}
+		if sha, _ := result["sha"].(string); len(sha) != 40 {
+			t.Errorf("Expected 40 character sha, got %v", result["sha"])
+		}
+	})
+
+	t.Run("status is clean after commit", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/git/status", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		var status map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &status)
+		if status["clean"] != true {
+			t.Errorf("Expected clean status, got %v", status)
+		}
+	})
+}
+
+func TestGitHandler_Branches(t *testing.T) {
+	router, tmpDir := setupGitTestRouter(t)
+
+	os.WriteFile(filepath.Join(tmpDir, "README.md"), []byte("# Test\n"), 0644)
+	body, _ := json.Marshal(map[string]string{
+		"message":      "Initial commit",
+		"author_name":  "Jane Doe",
+		"author_email": "jane@example.com",
+	})
+	req := httptest.NewRequest("POST", "/git/commit", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Initial commit failed: %d %s", w.Code, w.Body.String())
+	}
+
+	t.Run("create branch", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]interface{}{"name": "feature", "checkout": true})
+		req := httptest.NewRequest("POST", "/git/branch", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusCreated {
+			t.Errorf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("duplicate branch", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]interface{}{"name": "feature"})
+		req := httptest.NewRequest("POST", "/git/branch", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusConflict {
+			t.Errorf("Expected status 409, got %d", w.Code)
+		}
+	})
+
+	t.Run("invalid branch name", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]interface{}{"name": "bad..name"})
+		req := httptest.NewRequest("POST", "/git/branch", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("checkout unknown branch", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]string{"branch": "missing"})
+		req := httptest.NewRequest("POST", "/git/checkout", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("checkout main", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]string{"branch": "main"})
+		req := httptest.NewRequest("POST", "/git/checkout", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+}
+
+func TestGitHandler_Snapshots(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+
+	tmpDir := t.TempDir()
+	handler := NewGitHandler(tmpDir, "", "")
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/git/snapshots", handler.CreateSnapshot)
+	router.GET("/git/snapshots/diff", handler.DiffSnapshots)
+	router.POST("/git/snapshots/prune", handler.PruneSnapshots)
+
+	os.WriteFile(filepath.Join(tmpDir, "file.txt"), []byte("before\n"), 0644)
+
+	req := httptest.NewRequest("POST", "/git/snapshots", strings.NewReader(`{"session": "session-1"}`))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var snapshot map[string]string
+	json.Unmarshal(w.Body.Bytes(), &snapshot)
+
+	os.WriteFile(filepath.Join(tmpDir, "file.txt"), []byte("after\n"), 0644)
+
+	t.Run("diff against live workspace", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/git/snapshots/diff?from="+snapshot["id"], nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var diff map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &diff)
+		if diff["files_changed"] != float64(1) || diff["additions"] != float64(1) || diff["deletions"] != float64(1) {
+			t.Errorf("Unexpected diff summary: %v", diff)
+		}
+	})
+
+	t.Run("missing from", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/git/snapshots/diff", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("invalid snapshot id", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/git/snapshots/diff?from=HEAD", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("prune", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/git/snapshots/prune", strings.NewReader(`{"keep": {"session-1": ["`+snapshot["id"]+`"]}}`))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		req = httptest.NewRequest("GET", "/git/snapshots/diff?from="+snapshot["id"], nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected the kept snapshot to diff, got %d: %s", w.Code, w.Body.String())
+		}
+
+		req = httptest.NewRequest("POST", "/git/snapshots/prune", strings.NewReader(`{"keep": {}}`))
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		req = httptest.NewRequest("GET", "/git/snapshots/diff?from="+snapshot["id"]+"&to="+snapshot["id"], nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected the pruned snapshot to be gone, got %d", w.Code)
+		}
+	})
+}
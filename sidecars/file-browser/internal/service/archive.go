@@ -0,0 +1,196 @@
+package service
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrInvalidArchive = errors.New("invalid workspace archive")
+
+// lostAndFound is created by ext4 at the root of fresh volumes; it is neither archived nor cleared
+const lostAndFound = "lost+found"
+
+// ArchiveService exports the workspace to a gzipped tarball and restores it from one
+type ArchiveService struct {
+	WorkspaceDir string
+}
+
+func NewArchiveService(workspaceDir string) *ArchiveService {
+	return &ArchiveService{
+		WorkspaceDir: workspaceDir,
+	}
+}
+
+// Export writes the whole workspace, including the git directory, as a gzipped tarball
+func (s *ArchiveService) Export(w io.Writer) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	err := filepath.WalkDir(s.WorkspaceDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(s.WorkspaceDir, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		if rel == lostAndFound {
+			return filepath.SkipDir
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		var link string
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		} else if !info.Mode().IsRegular() && !info.IsDir() {
+			// Sockets, pipes and devices cannot be restored meaningfully
+			return nil
+		}
+
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to archive workspace: %w", err)
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// Import replaces the content of the workspace with a gzipped tarball written by Export.
+// Entries escaping the workspace are rejected; symlinks are created once every file is
+// extracted so that no entry is written through one.
+func (s *ArchiveService) Import(r io.Reader) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	defer gz.Close()
+
+	if err := s.clearWorkspace(); err != nil {
+		return err
+	}
+
+	type symlink struct{ path, target string }
+	var symlinks []symlink
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+
+		path, err := s.archivePath(header.Name)
+		if err != nil {
+			return err
+		}
+		mode := os.FileMode(header.Mode).Perm()
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, mode|0700); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+			if err := writeArchiveFile(path, mode, tr); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			symlinks = append(symlinks, symlink{path: path, target: header.Linkname})
+		}
+	}
+
+	for _, link := range symlinks {
+		if err := os.MkdirAll(filepath.Dir(link.path), 0755); err != nil {
+			return err
+		}
+		if err := os.Symlink(link.target, link.path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// archivePath resolves a tarball entry inside the workspace
+func (s *ArchiveService) archivePath(name string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: entry %q escapes the workspace", ErrInvalidArchive, name)
+	}
+	return filepath.Join(s.WorkspaceDir, clean), nil
+}
+
+// clearWorkspace removes everything in the workspace but lost+found
+func (s *ArchiveService) clearWorkspace() error {
+	entries, err := os.ReadDir(s.WorkspaceDir)
+	if err != nil {
+		return fmt.Errorf("failed to read workspace: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.Name() == lostAndFound {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(s.WorkspaceDir, entry.Name())); err != nil {
+			return fmt.Errorf("failed to clear workspace: %w", err)
+		}
+	}
+	return nil
+}
+
+func writeArchiveFile(path string, mode os.FileMode, r io.Reader) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestArchiveService_ExportImport(t *testing.T) {
+	source := t.TempDir()
+	os.MkdirAll(filepath.Join(source, "src", "pkg"), 0755)
+	os.MkdirAll(filepath.Join(source, ".git"), 0755)
+	os.MkdirAll(filepath.Join(source, "lost+found"), 0700)
+	os.WriteFile(filepath.Join(source, "src", "pkg", "main.go"), []byte("package main\n"), 0644)
+	os.WriteFile(filepath.Join(source, ".git", "HEAD"), []byte("ref: refs/heads/main\n"), 0644)
+	os.WriteFile(filepath.Join(source, "run.sh"), []byte("#!/bin/sh\n"), 0755)
+	os.WriteFile(filepath.Join(source, "lost+found", "orphan"), []byte("x"), 0644)
+	os.Symlink("src/pkg/main.go", filepath.Join(source, "main.go"))
+
+	var archive bytes.Buffer
+	if err := NewArchiveService(source).Export(&archive); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	target := t.TempDir()
+	os.MkdirAll(filepath.Join(target, "lost+found"), 0700)
+	os.WriteFile(filepath.Join(target, "stale.txt"), []byte("stale"), 0644)
+
+	if err := NewArchiveService(target).Import(&archive); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(target, "src", "pkg", "main.go"))
+	if err != nil || string(content) != "package main\n" {
+		t.Errorf("Expected restored main.go, got %q (%v)", content, err)
+	}
+	if _, err := os.Stat(filepath.Join(target, ".git", "HEAD")); err != nil {
+		t.Errorf("Expected git directory to be restored: %v", err)
+	}
+	if info, err := os.Stat(filepath.Join(target, "run.sh")); err != nil || info.Mode().Perm()&0100 == 0 {
+		t.Errorf("Expected run.sh to stay executable")
+	}
+	if link, err := os.Readlink(filepath.Join(target, "main.go")); err != nil || link != "src/pkg/main.go" {
+		t.Errorf("Expected main.go symlink, got %q (%v)", link, err)
+	}
+	if _, err := os.Stat(filepath.Join(target, "stale.txt")); !os.IsNotExist(err) {
+		t.Error("Expected stale.txt to be cleared")
+	}
+	if _, err := os.Stat(filepath.Join(target, "lost+found")); err != nil {
+		t.Error("Expected lost+found to be kept")
+	}
+	if _, err := os.Stat(filepath.Join(target, "lost+found", "orphan")); !os.IsNotExist(err) {
+		t.Error("Expected lost+found not to be archived")
+	}
+}
+
+func TestArchiveService_ImportRejectsTraversal(t *testing.T) {
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+	tw.WriteHeader(&tar.Header{Name: "../escape.txt", Typeflag: tar.TypeReg, Mode: 0644, Size: 4})
+	tw.Write([]byte("evil"))
+	tw.Close()
+	gz.Close()
+
+	parent := t.TempDir()
+	workspace := filepath.Join(parent, "workspace")
+	os.MkdirAll(workspace, 0755)
+
+	err := NewArchiveService(workspace).Import(&archive)
+	if !errors.Is(err, ErrInvalidArchive) {
+		t.Errorf("Expected ErrInvalidArchive, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(parent, "escape.txt")); !os.IsNotExist(err) {
+		t.Error("Expected no file outside the workspace")
+	}
+}
+
+func TestArchiveService_ImportRejectsGarbage(t *testing.T) {
+	workspace := t.TempDir()
+	os.WriteFile(filepath.Join(workspace, "keep.txt"), []byte("keep"), 0644)
+
+	err := NewArchiveService(workspace).Import(bytes.NewReader([]byte("not a tarball")))
+	if !errors.Is(err, ErrInvalidArchive) {
+		t.Errorf("Expected ErrInvalidArchive, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(workspace, "keep.txt")); err != nil {
+		t.Error("Expected workspace to be left untouched")
+	}
+}
@@ -0,0 +1,549 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNotRepository      = errors.New("workspace is not a git repository")
+	ErrNothingToCommit    = errors.New("nothing to commit")
+	ErrMessageRequired    = errors.New("commit message is required")
+	ErrAuthorRequired     = errors.New("commit author name and email are required")
+	ErrInvalidBranchName  = errors.New("invalid branch name")
+	ErrBranchExists       = errors.New("branch already exists")
+	ErrBranchNotFound     = errors.New("branch not found")
+	ErrUncommittedChanges = errors.New("uncommitted changes would be overwritten")
+	ErrPushRejected       = errors.New("push rejected by remote")
+	ErrRemoteNotFound     = errors.New("remote not found")
+	ErrUnsafeRepoConfig   = errors.New("repository config is not safe to push with")
+)
+
+// gitTimeout bounds every git invocation; push and fetch talk to the network
+const gitTimeout = 2 * time.Minute
+
+var validBranchName = regexp.MustCompile(`^[A-Za-z0-9._/\-]+$`)
+
+// GitFileStatus is the porcelain status of a single path
+type GitFileStatus struct {
+	Path         string `json:"path"`
+	OriginalPath string `json:"original_path,omitempty"`
+	Index        string `json:"index"`
+	WorkTree     string `json:"work_tree"`
+}
+
+// GitStatus summarizes the repository state of the workspace
+type GitStatus struct {
+	Branch   string          `json:"branch"`
+	Upstream string          `json:"upstream,omitempty"`
+	Ahead    int             `json:"ahead"`
+	Behind   int             `json:"behind"`
+	Clean    bool            `json:"clean"`
+	Files    []GitFileStatus `json:"files"`
+}
+
+// GitCommitResult describes a commit created in the workspace
+type GitCommitResult struct {
+	SHA     string `json:"sha"`
+	Branch  string `json:"branch"`
+	Message string `json:"message"`
+}
+
+// GitAuthor identifies the person a commit is attributed to
+type GitAuthor struct {
+	Name  string
+	Email string
+}
+
+// GitService runs git commands against the workspace repository.
+// Commands are serialized because git takes an index lock per operation.
+type GitService struct {
+	WorkspaceDir string
+	// RemoteURL is the project repository; pushes go there rather than to a remote of .git/config (optional)
+	RemoteURL string
+	// Credential is an access token used for HTTPS pushes (optional)
+	Credential string
+
+	mu sync.Mutex
+}
+
+func NewGitService(workspaceDir, remoteURL, credential string) *GitService {
+	return &GitService{
+		WorkspaceDir: workspaceDir,
+		RemoteURL:    remoteURL,
+		Credential:   credential,
+	}
+}
+
+// credentialEnv is the environment variable the repository credential is handed to the sidecar in
+const credentialEnv = "REPO_CREDENTIAL"
+
+// gitSafeConfig keeps git from running programs named by the workspace repository, which the agent can write
+var gitSafeConfig = []string{"-c", "core.hooksPath=/dev/null", "-c", "core.fsmonitor=false"}
+
+// gitEnv is the sidecar environment without the repository credential
+func gitEnv() []string {
+	var env []string
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, credentialEnv+"=") {
+			env = append(env, kv)
+		}
+	}
+	return env
+}
+
+// configEnv passes git configuration through the environment, e.g. so secrets stay out of the process list
+func configEnv(pairs ...string) []string {
+	env := []string{"GIT_CONFIG_COUNT=" + strconv.Itoa(len(pairs)/2)}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		env = append(env,
+			fmt.Sprintf("GIT_CONFIG_KEY_%d=%s", i/2, pairs[i]),
+			fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s", i/2, pairs[i+1]),
+		)
+	}
+	return env
+}
+
+// run executes git in the workspace and returns stdout.
+// On failure the error carries git's stderr output.
+func (s *GitService) run(ctx context.Context, env []string, args ...string) (string, error) {
+	return s.runWithInput(ctx, env, "", args...)
+}
+
+// runWithInput executes git like run, feeding input to its stdin
+func (s *GitService) runWithInput(ctx context.Context, env []string, input string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, gitTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", append(append([]string{}, gitSafeConfig...), args...)...)
+	cmd.Dir = s.WorkspaceDir
+	// The ceiling stops git from picking up a repository above the workspace
+	cmd.Env = append(gitEnv(), "GIT_TERMINAL_PROMPT=0", "GIT_CEILING_DIRECTORIES="+filepath.Dir(s.WorkspaceDir))
+	cmd.Env = append(cmd.Env, env...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = strings.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = strings.TrimSpace(stdout.String())
+		}
+		return stdout.String(), fmt.Errorf("git %s: %w: %s", args[0], err, msg)
+	}
+
+	return stdout.String(), nil
+}
+
+// gitPathspec turns a workspace path into a pathspec, rejecting paths that leave the workspace
+func gitPathspec(path string) (string, error) {
+	for _, part := range strings.Split(filepath.ToSlash(path), "/") {
+		if part == ".." {
+			return "", ErrInvalidPath
+		}
+	}
+	return strings.TrimPrefix(path, "/"), nil
+}
+
+func (s *GitService) ensureRepository(ctx context.Context) error {
+	if _, err := s.run(ctx, nil, "rev-parse", "--git-dir"); err != nil {
+		return ErrNotRepository
+	}
+	return nil
+}
+
+func (s *GitService) currentBranch(ctx context.Context) (string, error) {
+	out, err := s.run(ctx, nil, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+func (s *GitService) branchExists(ctx context.Context, name string) bool {
+	_, err := s.run(ctx, nil, "rev-parse", "--verify", "--quiet", "refs/heads/"+name)
+	return err == nil
+}
+
+// validateBranchName applies the rules of git check-ref-format that the allowed characters leave open.
+// Unlike paths, refs may not contain ".." anywhere, and no component may start with a dot or end in ".lock".
+func validateBranchName(name string) error {
+	if name == "" || len(name) > 255 || !validBranchName.MatchString(name) ||
+		strings.HasPrefix(name, "-") || strings.Contains(name, "..") {
+		return fmt.Errorf("%w: %q", ErrInvalidBranchName, name)
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == "" || strings.HasPrefix(part, ".") || strings.HasSuffix(part, ".lock") {
+			return fmt.Errorf("%w: %q", ErrInvalidBranchName, name)
+		}
+	}
+	return nil
+}
+
+// Status returns the current branch, upstream tracking info and changed files
+func (s *GitService) Status(ctx context.Context) (*GitStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.ensureRepository(ctx); err != nil {
+		return nil, err
+	}
+
+	out, err := s.run(ctx, nil, "status", "--porcelain=v1", "--branch", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+
+	return parsePorcelainStatus(out), nil
+}
+
+// parsePorcelainStatus parses `git status --porcelain=v1 --branch -z` output
+func parsePorcelainStatus(out string) *GitStatus {
+	status := &GitStatus{Files: []GitFileStatus{}}
+
+	entries := strings.Split(out, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 3 {
+			continue
+		}
+
+		if strings.HasPrefix(entry, "## ") {
+			parseBranchHeader(strings.TrimPrefix(entry, "## "), status)
+			continue
+		}
+
+		file := GitFileStatus{
+			Index:    string(entry[0]),
+			WorkTree: string(entry[1]),
+			Path:     entry[3:],
+		}
+		// Renames and copies are followed by the original path
+		if (entry[0] == 'R' || entry[0] == 'C') && i+1 < len(entries) {
+			file.OriginalPath = entries[i+1]
+			i++
+		}
+		status.Files = append(status.Files, file)
+	}
+
+	status.Clean = len(status.Files) == 0
+	return status
+}
+
+// parseBranchHeader parses "main...origin/main [ahead 1, behind 2]"
+func parseBranchHeader(header string, status *GitStatus) {
+	if rest, ok := strings.CutPrefix(header, "No commits yet on "); ok {
+		status.Branch = rest
+		return
+	}
+
+	tracking := ""
+	if idx := strings.Index(header, " ["); idx >= 0 {
+		tracking = strings.Trim(header[idx+2:], "]")
+		header = header[:idx]
+	}
+
+	branch, upstream, _ := strings.Cut(header, "...")
+	status.Branch = branch
+	status.Upstream = upstream
+
+	for _, part := range strings.Split(tracking, ", ") {
+		if n, ok := strings.CutPrefix(part, "ahead "); ok {
+			status.Ahead, _ = strconv.Atoi(n)
+		}
+		if n, ok := strings.CutPrefix(part, "behind "); ok {
+			status.Behind, _ = strconv.Atoi(n)
+		}
+	}
+}
+
+// Diff returns the unified diff of the working tree (or of the index when
+// staged is true), optionally restricted to a single path. Untracked files
+// are not part of `git diff`; use Status to list them.
+func (s *GitService) Diff(ctx context.Context, path string, staged bool) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.ensureRepository(ctx); err != nil {
+		return "", err
+	}
+
+	args := []string{"diff", "--no-color", "--no-ext-diff"}
+	if staged {
+		args = append(args, "--cached")
+	}
+	if path != "" {
+		pathspec, err := gitPathspec(path)
+		if err != nil {
+			return "", err
+		}
+		args = append(args, "--", pathspec)
+	}
+
+	return s.run(ctx, nil, args...)
+}
+
+// Commit stages the given paths (everything when empty) and commits them
+func (s *GitService) Commit(ctx context.Context, message string, author GitAuthor, paths []string) (*GitCommitResult, error) {
+	if strings.TrimSpace(message) == "" {
+		return nil, ErrMessageRequired
+	}
+	if author.Name == "" || author.Email == "" {
+		return nil, ErrAuthorRequired
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.ensureRepository(ctx); err != nil {
+		return nil, err
+	}
+
+	addArgs := []string{"add", "--all", "--"}
+	if len(paths) == 0 {
+		addArgs = append(addArgs, ".")
+	}
+	for _, p := range paths {
+		pathspec, err := gitPathspec(p)
+		if err != nil {
+			return nil, err
+		}
+		addArgs = append(addArgs, pathspec)
+	}
+	if _, err := s.run(ctx, nil, addArgs...); err != nil {
+		return nil, err
+	}
+
+	// Nothing staged: `git diff --cached --quiet` exits 0
+	if _, err := s.run(ctx, nil, "diff", "--cached", "--quiet"); err == nil {
+		return nil, ErrNothingToCommit
+	}
+
+	env := []string{
+		"GIT_AUTHOR_NAME=" + author.Name,
+		"GIT_AUTHOR_EMAIL=" + author.Email,
+		"GIT_COMMITTER_NAME=" + author.Name,
+		"GIT_COMMITTER_EMAIL=" + author.Email,
+	}
+	if _, err := s.run(ctx, env, "commit", "--no-verify", "--quiet", "-m", message); err != nil {
+		return nil, err
+	}
+
+	sha, err := s.run(ctx, nil, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	branch, err := s.currentBranch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GitCommitResult{
+		SHA:     strings.TrimSpace(sha),
+		Branch:  branch,
+		Message: message,
+	}, nil
+}
+
+// CreateBranch creates a branch from startPoint (HEAD when empty) and
+// optionally checks it out
+func (s *GitService) CreateBranch(ctx context.Context, name, startPoint string, checkout bool) error {
+	if err := validateBranchName(name); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.ensureRepository(ctx); err != nil {
+		return err
+	}
+
+	if s.branchExists(ctx, name) {
+		return ErrBranchExists
+	}
+
+	args := []string{"branch", name}
+	if checkout {
+		args = []string{"checkout", "--quiet", "-b", name}
+	}
+	if startPoint != "" {
+		if strings.HasPrefix(startPoint, "-") {
+			return fmt.Errorf("%w: invalid start point %q", ErrInvalidBranchName, startPoint)
+		}
+		args = append(args, startPoint)
+	}
+
+	if _, err := s.run(ctx, nil, args...); err != nil {
+		if strings.Contains(err.Error(), "not a valid object name") || strings.Contains(err.Error(), "not a commit") {
+			return fmt.Errorf("%w: %s", ErrBranchNotFound, startPoint)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Checkout switches the workspace to an existing branch
+func (s *GitService) Checkout(ctx context.Context, name string) error {
+	if err := validateBranchName(name); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.ensureRepository(ctx); err != nil {
+		return err
+	}
+
+	if _, err := s.run(ctx, nil, "checkout", "--quiet", name, "--"); err != nil {
+		msg := err.Error()
+		switch {
+		case strings.Contains(msg, "would be overwritten"):
+			return ErrUncommittedChanges
+		case strings.Contains(msg, "did not match any") || strings.Contains(msg, "invalid reference"):
+			return fmt.Errorf("%w: %s", ErrBranchNotFound, name)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Push pushes a branch (the current one when empty) to a remote
+// ("origin" when empty) and sets it as upstream
+func (s *GitService) Push(ctx context.Context, remote, branch string) (string, error) {
+	if remote == "" {
+		remote = "origin"
+	}
+	if strings.HasPrefix(remote, "-") {
+		return "", ErrRemoteNotFound
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.ensureRepository(ctx); err != nil {
+		return "", err
+	}
+
+	if branch == "" {
+		current, err := s.currentBranch(ctx)
+		if err != nil {
+			return "", err
+		}
+		branch = current
+	}
+	if err := validateBranchName(branch); err != nil {
+		return "", err
+	}
+
+	// The credential only goes to the project repository: a URL read from .git/config could point anywhere
+	target := s.RemoteURL
+	if target == "" {
+		if s.Credential != "" {
+			return "", fmt.Errorf("%w: the project has no repository to push to", ErrRemoteNotFound)
+		}
+		if _, err := s.run(ctx, nil, "remote", "get-url", remote); err != nil {
+			return "", fmt.Errorf("%w: %s", ErrRemoteNotFound, remote)
+		}
+		target = remote
+	}
+	if err := s.checkPushConfig(ctx); err != nil {
+		return "", err
+	}
+
+	// Programs git would otherwise take from the repository config never see the credential
+	config := []string{"core.sshCommand", "ssh", "credential.helper", ""}
+	var env []string
+	if s.Credential != "" {
+		if strings.HasPrefix(target, "git@") || strings.HasPrefix(target, "ssh://") {
+			// SSH remotes use the credential as a private key
+			keyFile, err := os.CreateTemp("", "repo-key-*")
+			if err != nil {
+				return "", fmt.Errorf("failed to write SSH key: %w", err)
+			}
+			defer os.Remove(keyFile.Name())
+			if _, err := keyFile.WriteString(strings.TrimSpace(s.Credential) + "\n"); err != nil {
+				keyFile.Close()
+				return "", fmt.Errorf("failed to write SSH key: %w", err)
+			}
+			keyFile.Close()
+			env = append(env, "GIT_SSH_COMMAND=ssh -i "+keyFile.Name()+" -o StrictHostKeyChecking=accept-new")
+		} else {
+			// The header is passed through the environment so the token never shows up in the process list
+			auth := base64.StdEncoding.EncodeToString([]byte("x-access-token:" + s.Credential))
+			config = append(config, "http.extraHeader", "Authorization: Basic "+auth)
+		}
+	}
+	env = append(env, configEnv(config...)...)
+
+	args := []string{"push", "--porcelain"}
+	if target == remote {
+		args = append(args, "--set-upstream")
+	}
+	args = append(args, target, "refs/heads/"+branch+":refs/heads/"+branch)
+
+	// --porcelain reports per-ref results on stdout, including rejections
+	if out, err := s.run(ctx, env, args...); err != nil {
+		if strings.Contains(out, "[rejected]") || strings.Contains(out, "[remote rejected]") {
+			return "", fmt.Errorf("%w: %s", ErrPushRejected, branch)
+		}
+		return "", err
+	}
+
+	if target != remote {
+		s.trackPushedBranch(ctx, remote, branch)
+	}
+
+	return branch, nil
+}
+
+// pushConfigPattern matches repository settings that could redirect a push or hand its credential to a program
+const pushConfigPattern = `^(url\..*|http\..*|credential\..*|core\.(sshcommand|gitproxy|askpass))$`
+
+// checkPushConfig refuses to push when the repository config, which the agent can write, sets pushConfigPattern
+func (s *GitService) checkPushConfig(ctx context.Context) error {
+	out, err := s.run(ctx, nil, "config", "--local", "--includes", "--name-only", "--get-regexp", pushConfigPattern)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		// Nothing matched
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	keys := strings.Fields(out)
+	if len(keys) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: the repository config sets %s", ErrUnsafeRepoConfig, keys[0])
+}
+
+// trackPushedBranch points <remote>/<branch> at the pushed commit and makes it the branch's upstream,
+// as --set-upstream does for pushes to a named remote. Repositories without that remote are left alone.
+func (s *GitService) trackPushedBranch(ctx context.Context, remote, branch string) {
+	if _, err := s.run(ctx, nil, "remote", "get-url", remote); err != nil {
+		return
+	}
+	if _, err := s.run(ctx, nil, "update-ref", "refs/remotes/"+remote+"/"+branch, "refs/heads/"+branch); err != nil {
+		return
+	}
+	// The push itself succeeded; a missing upstream only affects ahead/behind counts
+	_, _ = s.run(ctx, nil, "branch", "--set-upstream-to="+remote+"/"+branch, branch)
+}
@@ -0,0 +1,433 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupGitWorkspace creates a bare "remote" repository and a workspace
+// cloned from it with one initial commit pushed to main
+func setupGitWorkspace(t *testing.T) (workspace string, remote string) {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+
+	root := t.TempDir()
+	remote = filepath.Join(root, "remote.git")
+	workspace = filepath.Join(root, "workspace")
+
+	runGit(t, root, "init", "--quiet", "--bare", "--initial-branch=main", remote)
+	runGit(t, root, "clone", "--quiet", remote, workspace)
+
+	os.WriteFile(filepath.Join(workspace, "README.md"), []byte("# Test\n"), 0644)
+	runGit(t, workspace, "add", "README.md")
+	runGit(t, workspace, "-c", "user.name=Setup", "-c", "user.email=setup@example.com", "commit", "--quiet", "-m", "Initial commit")
+	runGit(t, workspace, "branch", "-M", "main")
+	runGit(t, workspace, "push", "--quiet", "-u", "origin", "main")
+
+	return workspace, remote
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+var testAuthor = GitAuthor{Name: "Jane Doe", Email: "jane@example.com"}
+
+func TestGitService_NotRepository(t *testing.T) {
+	service := NewGitService(t.TempDir(), "", "")
+
+	_, err := service.Status(context.Background())
+	if !errors.Is(err, ErrNotRepository) {
+		t.Errorf("Expected ErrNotRepository, got %v", err)
+	}
+}
+
+func TestGitService_Status(t *testing.T) {
+	workspace, _ := setupGitWorkspace(t)
+	service := NewGitService(workspace, "", "")
+	ctx := context.Background()
+
+	status, err := service.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if status.Branch != "main" {
+		t.Errorf("Expected branch main, got %s", status.Branch)
+	}
+	if status.Upstream != "origin/main" {
+		t.Errorf("Expected upstream origin/main, got %s", status.Upstream)
+	}
+	if !status.Clean {
+		t.Errorf("Expected clean workspace, got %+v", status.Files)
+	}
+
+	os.WriteFile(filepath.Join(workspace, "README.md"), []byte("# Changed\n"), 0644)
+	os.WriteFile(filepath.Join(workspace, "new.txt"), []byte("new\n"), 0644)
+
+	status, err = service.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if status.Clean {
+		t.Error("Expected dirty workspace")
+	}
+
+	files := map[string]GitFileStatus{}
+	for _, f := range status.Files {
+		files[f.Path] = f
+	}
+	if files["README.md"].WorkTree != "M" {
+		t.Errorf("Expected README.md to be modified, got %+v", files["README.md"])
+	}
+	if files["new.txt"].Index != "?" {
+		t.Errorf("Expected new.txt to be untracked, got %+v", files["new.txt"])
+	}
+}
+
+func TestGitService_Diff(t *testing.T) {
+	workspace, _ := setupGitWorkspace(t)
+	service := NewGitService(workspace, "", "")
+	ctx := context.Background()
+
+	os.WriteFile(filepath.Join(workspace, "README.md"), []byte("# Changed\n"), 0644)
+
+	diff, err := service.Diff(ctx, "", false)
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if !strings.Contains(diff, "-# Test") || !strings.Contains(diff, "+# Changed") {
+		t.Errorf("Unexpected diff:\n%s", diff)
+	}
+
+	staged, err := service.Diff(ctx, "README.md", true)
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if staged != "" {
+		t.Errorf("Expected empty staged diff, got:\n%s", staged)
+	}
+
+	if _, err := service.Diff(ctx, "../outside", false); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("Expected ErrInvalidPath, got %v", err)
+	}
+	if _, err := service.Diff(ctx, "docs/../../outside", false); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("Expected ErrInvalidPath, got %v", err)
+	}
+	if _, err := service.Diff(ctx, "release..notes.md", false); err != nil {
+		t.Errorf("Expected dotted file name to be accepted, got %v", err)
+	}
+}
+
+func TestGitService_Commit(t *testing.T) {
+	workspace, _ := setupGitWorkspace(t)
+	service := NewGitService(workspace, "", "")
+	ctx := context.Background()
+
+	t.Run("nothing to commit", func(t *testing.T) {
+		_, err := service.Commit(ctx, "Empty", testAuthor, nil)
+		if !errors.Is(err, ErrNothingToCommit) {
+			t.Errorf("Expected ErrNothingToCommit, got %v", err)
+		}
+	})
+
+	t.Run("message required", func(t *testing.T) {
+		_, err := service.Commit(ctx, "  ", testAuthor, nil)
+		if !errors.Is(err, ErrMessageRequired) {
+			t.Errorf("Expected ErrMessageRequired, got %v", err)
+		}
+	})
+
+	t.Run("author required", func(t *testing.T) {
+		_, err := service.Commit(ctx, "Message", GitAuthor{}, nil)
+		if !errors.Is(err, ErrAuthorRequired) {
+			t.Errorf("Expected ErrAuthorRequired, got %v", err)
+		}
+	})
+
+	t.Run("commit selected paths with author", func(t *testing.T) {
+		os.WriteFile(filepath.Join(workspace, "a.txt"), []byte("a\n"), 0644)
+		os.WriteFile(filepath.Join(workspace, "b.txt"), []byte("b\n"), 0644)
+
+		result, err := service.Commit(ctx, "Add a", testAuthor, []string{"/a.txt"})
+		if err != nil {
+			t.Fatalf("Commit failed: %v", err)
+		}
+		if result.Branch != "main" || len(result.SHA) != 40 {
+			t.Errorf("Unexpected commit result: %+v", result)
+		}
+
+		author := runGit(t, workspace, "log", "-1", "--format=%an <%ae>")
+		if author != "Jane Doe <jane@example.com>" {
+			t.Errorf("Expected commit author Jane Doe <jane@example.com>, got %s", author)
+		}
+
+		files := runGit(t, workspace, "show", "--name-only", "--format=", "HEAD")
+		if files != "a.txt" {
+			t.Errorf("Expected only a.txt in commit, got %q", files)
+		}
+	})
+
+	t.Run("paths with dots in their name", func(t *testing.T) {
+		os.WriteFile(filepath.Join(workspace, "a..b.txt"), []byte("a..b\n"), 0644)
+
+		if _, err := service.Commit(ctx, "Add a..b", testAuthor, []string{"a..b.txt"}); err != nil {
+			t.Fatalf("Commit failed: %v", err)
+		}
+		if _, err := service.Commit(ctx, "Escape", testAuthor, []string{"../outside"}); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("Expected ErrInvalidPath, got %v", err)
+		}
+	})
+}
+
+func TestGitService_BranchAndCheckout(t *testing.T) {
+	workspace, _ := setupGitWorkspace(t)
+	service := NewGitService(workspace, "", "")
+	ctx := context.Background()
+
+	if err := service.CreateBranch(ctx, "feature/login", "", true); err != nil {
+		t.Fatalf("CreateBranch failed: %v", err)
+	}
+	if branch := runGit(t, workspace, "rev-parse", "--abbrev-ref", "HEAD"); branch != "feature/login" {
+		t.Errorf("Expected feature/login to be checked out, got %s", branch)
+	}
+
+	if err := service.CreateBranch(ctx, "feature/login", "", false); !errors.Is(err, ErrBranchExists) {
+		t.Errorf("Expected ErrBranchExists, got %v", err)
+	}
+
+	for _, name := range []string{"--force", "a..b", "feature//login", "feature/.hidden", "feature/login.lock", "feature/"} {
+		if err := service.CreateBranch(ctx, name, "", false); !errors.Is(err, ErrInvalidBranchName) {
+			t.Errorf("Expected ErrInvalidBranchName for %q, got %v", name, err)
+		}
+	}
+
+	if err := service.Checkout(ctx, "main"); err != nil {
+		t.Fatalf("Checkout failed: %v", err)
+	}
+	if branch := runGit(t, workspace, "rev-parse", "--abbrev-ref", "HEAD"); branch != "main" {
+		t.Errorf("Expected main to be checked out, got %s", branch)
+	}
+
+	if err := service.Checkout(ctx, "does-not-exist"); !errors.Is(err, ErrBranchNotFound) {
+		t.Errorf("Expected ErrBranchNotFound, got %v", err)
+	}
+}
+
+func TestGitService_Push(t *testing.T) {
+	workspace, remote := setupGitWorkspace(t)
+	service := NewGitService(workspace, "", "")
+	ctx := context.Background()
+
+	if err := service.CreateBranch(ctx, "task/1234-fix", "", true); err != nil {
+		t.Fatalf("CreateBranch failed: %v", err)
+	}
+	os.WriteFile(filepath.Join(workspace, "fix.txt"), []byte("fix\n"), 0644)
+	result, err := service.Commit(ctx, "Fix", testAuthor, nil)
+	if err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	branch, err := service.Push(ctx, "", "")
+	if err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+	if branch != "task/1234-fix" {
+		t.Errorf("Expected pushed branch task/1234-fix, got %s", branch)
+	}
+
+	remoteSHA := runGit(t, remote, "rev-parse", "refs/heads/task/1234-fix")
+	if remoteSHA != result.SHA {
+		t.Errorf("Expected remote branch at %s, got %s", result.SHA, remoteSHA)
+	}
+
+	status, err := service.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if status.Upstream != "origin/task/1234-fix" {
+		t.Errorf("Expected upstream to be set, got %q", status.Upstream)
+	}
+
+	if _, err := service.Push(ctx, "upstream", ""); !errors.Is(err, ErrRemoteNotFound) {
+		t.Errorf("Expected ErrRemoteNotFound, got %v", err)
+	}
+}
+
+func TestGitService_PushWithCredential(t *testing.T) {
+	workspace, _ := setupGitWorkspace(t)
+	ctx := context.Background()
+
+	authorization := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case authorization <- r.Header.Get("Authorization"):
+		default:
+		}
+		http.Error(w, "repository not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+	service := NewGitService(workspace, server.URL+"/repo.git", "s3cret-token")
+
+	_, err := service.Push(ctx, "origin", "main")
+	if err == nil {
+		t.Fatal("Expected push to an unknown repository to fail")
+	}
+	if !strings.HasPrefix(err.Error(), "git push:") {
+		t.Errorf("Expected the error to name the push command, got %v", err)
+	}
+	if strings.Contains(err.Error(), "s3cret-token") {
+		t.Errorf("Expected the error not to contain the credential, got %v", err)
+	}
+
+	want := "Basic " + base64.StdEncoding.EncodeToString([]byte("x-access-token:s3cret-token"))
+	select {
+	case got := <-authorization:
+		if got != want {
+			t.Errorf("Expected Authorization %q, got %q", want, got)
+		}
+	default:
+		t.Error("Expected git to contact the remote")
+	}
+}
+
+func TestGitService_PushToProjectRepository(t *testing.T) {
+	workspace, remote := setupGitWorkspace(t)
+	ctx := context.Background()
+
+	// A rewritten origin must not receive the push
+	other := filepath.Join(t.TempDir(), "other.git")
+	runGit(t, filepath.Dir(other), "init", "--quiet", "--bare", other)
+	runGit(t, workspace, "remote", "set-url", "origin", other)
+	service := NewGitService(workspace, remote, "")
+
+	if err := service.CreateBranch(ctx, "feature", "", true); err != nil {
+		t.Fatalf("CreateBranch failed: %v", err)
+	}
+	os.WriteFile(filepath.Join(workspace, "feature.txt"), []byte("feature\n"), 0644)
+	result, err := service.Commit(ctx, "Feature", testAuthor, nil)
+	if err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	if _, err := service.Push(ctx, "origin", ""); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+	if sha := runGit(t, remote, "rev-parse", "refs/heads/feature"); sha != result.SHA {
+		t.Errorf("Expected project repository at %s, got %s", result.SHA, sha)
+	}
+	if out := runGit(t, other, "for-each-ref"); out != "" {
+		t.Errorf("Expected origin to receive nothing, got %q", out)
+	}
+
+	status, err := service.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if status.Upstream != "origin/feature" {
+		t.Errorf("Expected upstream origin/feature, got %q", status.Upstream)
+	}
+
+	runGit(t, workspace, "config", "url."+other+".pushInsteadOf", remote)
+	if _, err := service.Push(ctx, "origin", ""); !errors.Is(err, ErrUnsafeRepoConfig) {
+		t.Errorf("Expected ErrUnsafeRepoConfig with a URL rewrite, got %v", err)
+	}
+
+	if _, err := NewGitService(workspace, "", "token").Push(ctx, "origin", ""); !errors.Is(err, ErrRemoteNotFound) {
+		t.Errorf("Expected a credential without project repository to be refused, got %v", err)
+	}
+}
+
+func TestGitService_RepositoryProgramsDoNotRun(t *testing.T) {
+	workspace, _ := setupGitWorkspace(t)
+	service := NewGitService(workspace, "", "")
+	ctx := context.Background()
+	t.Setenv("REPO_CREDENTIAL", "s3cret-token")
+
+	// Programs the agent could plant in the repository record the environment they see
+	leak := filepath.Join(t.TempDir(), "leak")
+	script := filepath.Join(t.TempDir(), "record.sh")
+	os.WriteFile(script, []byte("#!/bin/sh\nenv >> "+leak+"\n"), 0755)
+	os.WriteFile(filepath.Join(workspace, ".git", "hooks", "post-commit"), []byte("#!/bin/sh\nenv >> "+leak+"\n"), 0755)
+	runGit(t, workspace, "config", "core.fsmonitor", script)
+
+	os.WriteFile(filepath.Join(workspace, "change.txt"), []byte("change\n"), 0644)
+	if _, err := service.Status(ctx); err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if _, err := service.Commit(ctx, "Change", testAuthor, nil); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	if _, err := os.Stat(leak); !os.IsNotExist(err) {
+		t.Error("Expected repository hooks and fsmonitor not to run")
+	}
+	for _, kv := range gitEnv() {
+		if strings.HasPrefix(kv, "REPO_CREDENTIAL=") {
+			t.Error("Expected the credential to be left out of the git environment")
+		}
+	}
+}
+
+func TestGitService_PushRejected(t *testing.T) {
+	workspace, remote := setupGitWorkspace(t)
+	service := NewGitService(workspace, "", "")
+	ctx := context.Background()
+
+	// Someone else pushes to main first
+	other := filepath.Join(t.TempDir(), "other")
+	runGit(t, filepath.Dir(other), "clone", "--quiet", remote, other)
+	os.WriteFile(filepath.Join(other, "other.txt"), []byte("other\n"), 0644)
+	runGit(t, other, "add", "other.txt")
+	runGit(t, other, "-c", "user.name=Other", "-c", "user.email=other@example.com", "commit", "--quiet", "-m", "Other")
+	runGit(t, other, "push", "--quiet", "origin", "main")
+
+	os.WriteFile(filepath.Join(workspace, "mine.txt"), []byte("mine\n"), 0644)
+	if _, err := service.Commit(ctx, "Mine", testAuthor, nil); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	if _, err := service.Push(ctx, "origin", "main"); !errors.Is(err, ErrPushRejected) {
+		t.Errorf("Expected ErrPushRejected, got %v", err)
+	}
+}
+
+func TestParsePorcelainStatus(t *testing.T) {
+	out := "## main...origin/main [ahead 2, behind 1]\x00R  new.go\x00old.go\x00 M changed.go\x00?? untracked.txt\x00"
+
+	status := parsePorcelainStatus(out)
+
+	if status.Branch != "main" || status.Upstream != "origin/main" {
+		t.Errorf("Unexpected branch info: %+v", status)
+	}
+	if status.Ahead != 2 || status.Behind != 1 {
+		t.Errorf("Expected ahead 2 / behind 1, got %d / %d", status.Ahead, status.Behind)
+	}
+	if len(status.Files) != 3 {
+		t.Fatalf("Expected 3 files, got %d: %+v", len(status.Files), status.Files)
+	}
+	if status.Files[0].Path != "new.go" || status.Files[0].OriginalPath != "old.go" || status.Files[0].Index != "R" {
+		t.Errorf("Unexpected rename entry: %+v", status.Files[0])
+	}
+	if status.Files[1].Path != "changed.go" || status.Files[1].WorkTree != "M" {
+		t.Errorf("Unexpected modified entry: %+v", status.Files[1])
+	}
+}
@@ -0,0 +1,387 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidSnapshot = errors.New("invalid snapshot id")
+
+// vibeDirName holds sidecar state inside the workspace; it is never part of a snapshot
+const vibeDirName = ".vibe"
+
+// maxPatchSize caps the unified diff returned for a single file
+const maxPatchSize = 256 * 1024
+
+// snapshotRefPrefix holds one ref per kept snapshot, refs/snapshots/<session>/<id>,
+// so gc keeps the snapshots of live sessions and prunes the others
+const snapshotRefPrefix = "refs/snapshots/"
+
+var snapshotIDPattern = regexp.MustCompile(`^[0-9a-f]{40}([0-9a-f]{24})?$`)
+
+var snapshotSessionPattern = regexp.MustCompile(`^[0-9A-Za-z][0-9A-Za-z_-]*$`)
+
+// FileDiff is the change to a single file between two snapshots
+type FileDiff struct {
+	Path      string `json:"path"`
+	OldPath   string `json:"old_path,omitempty"`
+	Status    string `json:"status"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+	Binary    bool   `json:"binary"`
+	Patch     string `json:"patch,omitempty"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+// SnapshotDiff summarizes every change between two snapshots
+type SnapshotDiff struct {
+	From         string     `json:"from"`
+	To           string     `json:"to"`
+	FilesChanged int        `json:"files_changed"`
+	Additions    int        `json:"additions"`
+	Deletions    int        `json:"deletions"`
+	Files        []FileDiff `json:"files"`
+}
+
+// snapshotDir is a private repository that records workspace content.
+// It is independent of the project's own repository, so snapshots work for
+// workspaces without git and never touch the user's index, refs or history.
+func (s *GitService) snapshotDir() string {
+	return filepath.Join(s.WorkspaceDir, vibeDirName, "snapshots.git")
+}
+
+func (s *GitService) snapshotEnv() []string {
+	return []string{
+		"GIT_DIR=" + s.snapshotDir(),
+		"GIT_WORK_TREE=" + s.WorkspaceDir,
+		"GIT_INDEX_FILE=" + filepath.Join(s.snapshotDir(), "index"),
+	}
+}
+
+func (s *GitService) ensureSnapshotRepository(ctx context.Context) error {
+	if _, err := os.Stat(filepath.Join(s.snapshotDir(), "HEAD")); err == nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(s.snapshotDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	if _, err := s.run(ctx, []string{"GIT_DIR=" + s.snapshotDir()}, "init", "--quiet"); err != nil {
+		return err
+	}
+
+	exclude := filepath.Join(s.snapshotDir(), "info", "exclude")
+	if err := os.MkdirAll(filepath.Dir(exclude), 0755); err != nil {
+		return fmt.Errorf("failed to create snapshot exclude file: %w", err)
+	}
+	if err := os.WriteFile(exclude, []byte("/"+vibeDirName+"/\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write snapshot exclude file: %w", err)
+	}
+
+	return nil
+}
+
+// excludeVibeDir keeps sidecar state out of the project's own repository
+func (s *GitService) excludeVibeDir(ctx context.Context) error {
+	if err := s.ensureRepository(ctx); err != nil {
+		return nil
+	}
+
+	out, err := s.run(ctx, nil, "rev-parse", "--git-path", "info/exclude")
+	if err != nil {
+		return err
+	}
+	exclude := strings.TrimSpace(out)
+	if !filepath.IsAbs(exclude) {
+		exclude = filepath.Join(s.WorkspaceDir, exclude)
+	}
+
+	pattern := "/" + vibeDirName + "/"
+	existing, err := os.ReadFile(exclude)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read exclude file: %w", err)
+	}
+	for _, line := range strings.Split(string(existing), "\n") {
+		if strings.TrimSpace(line) == pattern {
+			return nil
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(exclude), 0755); err != nil {
+		return fmt.Errorf("failed to create exclude file: %w", err)
+	}
+	f, err := os.OpenFile(exclude, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open exclude file: %w", err)
+	}
+	defer f.Close()
+
+	if len(existing) > 0 && !strings.HasSuffix(string(existing), "\n") {
+		pattern = "\n" + pattern
+	}
+	if _, err := f.WriteString(pattern + "\n"); err != nil {
+		return fmt.Errorf("failed to update exclude file: %w", err)
+	}
+
+	return nil
+}
+
+// Snapshot records the current content of the workspace and returns its id.
+// Files ignored by the workspace .gitignore files are not recorded. The snapshot is kept
+// for session until PruneSnapshots drops it; without a session, the next prune removes it.
+func (s *GitService) Snapshot(ctx context.Context, session string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if session != "" && !snapshotSessionPattern.MatchString(session) {
+		return "", fmt.Errorf("%w: invalid session %q", ErrInvalidSnapshot, session)
+	}
+
+	id, err := s.snapshot(ctx)
+	if err != nil {
+		return "", err
+	}
+	if session != "" {
+		if _, err := s.run(ctx, s.snapshotEnv(), "update-ref", snapshotRefPrefix+session+"/"+id, id); err != nil {
+			return "", err
+		}
+	}
+
+	return id, nil
+}
+
+// PruneSnapshots keeps every snapshot taken for the sessions in keep, along with the snapshots listed
+// for them, and deletes all the others. Listed snapshots missing from the repository are skipped.
+func (s *GitService) PruneSnapshots(ctx context.Context, keep map[string][]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wanted := map[string]string{}
+	for session, ids := range keep {
+		if !snapshotSessionPattern.MatchString(session) {
+			return fmt.Errorf("%w: invalid session %q", ErrInvalidSnapshot, session)
+		}
+		for _, id := range ids {
+			if !snapshotIDPattern.MatchString(id) {
+				return fmt.Errorf("%w: %q", ErrInvalidSnapshot, id)
+			}
+			wanted[snapshotRefPrefix+session+"/"+id] = id
+		}
+	}
+
+	if err := s.ensureSnapshotRepository(ctx); err != nil {
+		return err
+	}
+	env := s.snapshotEnv()
+
+	out, err := s.run(ctx, env, "for-each-ref", "--format=%(refname)", snapshotRefPrefix)
+	if err != nil {
+		return err
+	}
+	var updates strings.Builder
+	for _, ref := range strings.Fields(out) {
+		delete(wanted, ref)
+		session, _, _ := strings.Cut(strings.TrimPrefix(ref, snapshotRefPrefix), "/")
+		if _, ok := keep[session]; ok {
+			continue
+		}
+		fmt.Fprintf(&updates, "delete %s\n", ref)
+	}
+	// Snapshots taken before they were kept by session only survive if still in the repository
+	for ref, id := range wanted {
+		if _, err := s.run(ctx, env, "cat-file", "-e", id+"^{tree}"); err != nil {
+			continue
+		}
+		fmt.Fprintf(&updates, "create %s %s\n", ref, id)
+	}
+
+	if updates.Len() > 0 {
+		if _, err := s.runWithInput(ctx, env, updates.String(), "update-ref", "--stdin"); err != nil {
+			return err
+		}
+	}
+
+	// Unkept snapshots are never read again, so they need no grace period
+	_, err = s.run(ctx, env, "gc", "--quiet", "--prune=now")
+	return err
+}
+
+func (s *GitService) snapshot(ctx context.Context) (string, error) {
+	if err := s.excludeVibeDir(ctx); err != nil {
+		return "", err
+	}
+	if err := s.ensureSnapshotRepository(ctx); err != nil {
+		return "", err
+	}
+
+	env := s.snapshotEnv()
+	if _, err := s.run(ctx, env, "add", "--all", "--ignore-errors", "--", "."); err != nil {
+		return "", err
+	}
+	out, err := s.run(ctx, env, "write-tree")
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(out), nil
+}
+
+// DiffSnapshots compares two snapshots. An empty "to" compares against the
+// current workspace content.
+func (s *GitService) DiffSnapshots(ctx context.Context, from, to string) (*SnapshotDiff, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !snapshotIDPattern.MatchString(from) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidSnapshot, from)
+	}
+	if to == "" {
+		current, err := s.snapshot(ctx)
+		if err != nil {
+			return nil, err
+		}
+		to = current
+	} else if !snapshotIDPattern.MatchString(to) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidSnapshot, to)
+	}
+
+	if err := s.ensureSnapshotRepository(ctx); err != nil {
+		return nil, err
+	}
+
+	env := s.snapshotEnv()
+	for _, id := range []string{from, to} {
+		if _, err := s.run(ctx, env, "cat-file", "-e", id+"^{tree}"); err != nil {
+			return nil, fmt.Errorf("%w: %s not found", ErrInvalidSnapshot, id)
+		}
+	}
+
+	patch, err := s.run(ctx, env, "diff", "--no-color", "--no-ext-diff", "--find-renames", from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &SnapshotDiff{From: from, To: to, Files: parseUnifiedDiff(patch)}
+	for _, f := range diff.Files {
+		diff.Additions += f.Additions
+		diff.Deletions += f.Deletions
+	}
+	diff.FilesChanged = len(diff.Files)
+
+	return diff, nil
+}
+
+// parseUnifiedDiff splits git diff output into per-file patches and counts changed lines
+func parseUnifiedDiff(patch string) []FileDiff {
+	files := []FileDiff{}
+
+	var current *FileDiff
+	var body strings.Builder
+	inHunk := false
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+		if body.Len() > maxPatchSize {
+			current.Truncated = true
+		} else {
+			current.Patch = body.String()
+		}
+		files = append(files, *current)
+		body.Reset()
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(patch))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if strings.HasPrefix(line, "diff --git ") {
+			flush()
+			current = &FileDiff{Status: "modified"}
+			current.OldPath, current.Path = parseDiffHeaderPaths(line)
+			inHunk = false
+		}
+		if current == nil {
+			continue
+		}
+
+		if body.Len() <= maxPatchSize {
+			body.WriteString(line)
+			body.WriteByte('\n')
+		}
+
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case inHunk && strings.HasPrefix(line, "+"):
+			current.Additions++
+		case inHunk && strings.HasPrefix(line, "-"):
+			current.Deletions++
+		case inHunk:
+			// context or "\ No newline at end of file"
+		case strings.HasPrefix(line, "new file mode"):
+			current.Status = "added"
+		case strings.HasPrefix(line, "deleted file mode"):
+			current.Status = "deleted"
+		case strings.HasPrefix(line, "rename from "):
+			current.Status = "renamed"
+			current.OldPath = unquoteDiffPath(strings.TrimPrefix(line, "rename from "))
+		case strings.HasPrefix(line, "rename to "):
+			current.Path = unquoteDiffPath(strings.TrimPrefix(line, "rename to "))
+		case strings.HasPrefix(line, "Binary files "):
+			current.Binary = true
+		}
+	}
+	flush()
+
+	for i := range files {
+		if files[i].Status != "renamed" {
+			files[i].OldPath = ""
+		}
+	}
+
+	return files
+}
+
+// parseDiffHeaderPaths extracts both paths from a "diff --git a/x b/y" line
+func parseDiffHeaderPaths(line string) (string, string) {
+	rest := strings.TrimPrefix(line, "diff --git ")
+
+	if strings.HasPrefix(rest, `"`) {
+		// Quoted paths contain special characters; split on the closing quote
+		if end := strings.Index(rest[1:], `" `); end >= 0 {
+			oldPath := rest[:end+2]
+			newPath := rest[end+3:]
+			return stripDiffPrefix(unquoteDiffPath(oldPath)), stripDiffPrefix(unquoteDiffPath(newPath))
+		}
+	}
+
+	// Unquoted: both halves are identical unless renamed, so split in the middle
+	if idx := strings.Index(rest, " b/"); idx >= 0 {
+		return stripDiffPrefix(rest[:idx]), stripDiffPrefix(rest[idx+1:])
+	}
+	return rest, rest
+}
+
+func stripDiffPrefix(path string) string {
+	if strings.HasPrefix(path, "a/") || strings.HasPrefix(path, "b/") {
+		return path[2:]
+	}
+	return path
+}
+
+func unquoteDiffPath(path string) string {
+	if unquoted, err := strconv.Unquote(path); err == nil {
+		return unquoted
+	}
+	return path
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGitService_SnapshotWithoutRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+
+	workspace := t.TempDir()
+	service := NewGitService(workspace, "", "")
+	ctx := context.Background()
+
+	os.WriteFile(filepath.Join(workspace, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644)
+	os.WriteFile(filepath.Join(workspace, "old.txt"), []byte("remove me\n"), 0644)
+	os.WriteFile(filepath.Join(workspace, ".gitignore"), []byte("build/\n"), 0644)
+
+	before, err := service.Snapshot(ctx, "")
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	os.WriteFile(filepath.Join(workspace, "main.go"), []byte("package main\n\nfunc main() {\n\tprintln(\"hi\")\n}\n"), 0644)
+	os.Remove(filepath.Join(workspace, "old.txt"))
+	os.WriteFile(filepath.Join(workspace, "new.txt"), []byte("one\ntwo\n"), 0644)
+	os.MkdirAll(filepath.Join(workspace, "build"), 0755)
+	os.WriteFile(filepath.Join(workspace, "build", "out.bin"), []byte("ignored"), 0644)
+
+	after, err := service.Snapshot(ctx, "")
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	if after == before {
+		t.Fatal("Expected snapshot id to change after edits")
+	}
+
+	diff, err := service.DiffSnapshots(ctx, before, after)
+	if err != nil {
+		t.Fatalf("DiffSnapshots failed: %v", err)
+	}
+
+	files := map[string]FileDiff{}
+	for _, f := range diff.Files {
+		files[f.Path] = f
+	}
+
+	if len(files) != 3 {
+		t.Fatalf("Expected 3 changed files, got %d: %+v", len(files), diff.Files)
+	}
+	if f := files["main.go"]; f.Status != "modified" || f.Additions != 3 || f.Deletions != 1 {
+		t.Errorf("Unexpected main.go diff: %+v", f)
+	}
+	if f := files["old.txt"]; f.Status != "deleted" || f.Deletions != 1 {
+		t.Errorf("Unexpected old.txt diff: %+v", f)
+	}
+	if f := files["new.txt"]; f.Status != "added" || f.Additions != 2 || !strings.Contains(f.Patch, "+two") {
+		t.Errorf("Unexpected new.txt diff: %+v", f)
+	}
+	if diff.FilesChanged != 3 || diff.Additions != 5 || diff.Deletions != 2 {
+		t.Errorf("Unexpected totals: %d files, +%d -%d", diff.FilesChanged, diff.Additions, diff.Deletions)
+	}
+
+	// An empty "to" compares against the live workspace
+	live, err := service.DiffSnapshots(ctx, after, "")
+	if err != nil {
+		t.Fatalf("DiffSnapshots failed: %v", err)
+	}
+	if live.FilesChanged != 0 {
+		t.Errorf("Expected no live changes, got %+v", live.Files)
+	}
+}
+
+func TestGitService_SnapshotKeepsRepositoryClean(t *testing.T) {
+	workspace, _ := setupGitWorkspace(t)
+	service := NewGitService(workspace, "", "")
+	ctx := context.Background()
+
+	if _, err := service.Snapshot(ctx, ""); err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	status, err := service.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if !status.Clean {
+		t.Errorf("Expected snapshot state to be excluded from the repository, got %+v", status.Files)
+	}
+
+	// Running it twice must not duplicate the exclude entry
+	if _, err := service.Snapshot(ctx, ""); err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	exclude, _ := os.ReadFile(filepath.Join(workspace, ".git", "info", "exclude"))
+	if strings.Count(string(exclude), "/.vibe/") != 1 {
+		t.Errorf("Expected a single /.vibe/ exclude entry, got:\n%s", exclude)
+	}
+}
+
+func TestGitService_PruneSnapshots(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+
+	workspace := t.TempDir()
+	service := NewGitService(workspace, "", "")
+	ctx := context.Background()
+
+	snapshot := func(session, content string) string {
+		t.Helper()
+		os.WriteFile(filepath.Join(workspace, "file.txt"), []byte(content), 0644)
+		id, err := service.Snapshot(ctx, session)
+		if err != nil {
+			t.Fatalf("Snapshot failed: %v", err)
+		}
+		return id
+	}
+	exists := func(id string) bool {
+		_, err := service.run(ctx, service.snapshotEnv(), "cat-file", "-e", id+"^{tree}")
+		return err == nil
+	}
+
+	kept := snapshot("session-1", "kept\n")
+	alsoKept := snapshot("session-1", "also kept\n")
+	dropped := snapshot("session-2", "dropped\n")
+	unreferenced := snapshot("", "unreferenced\n")
+	snapshot("", "current\n")
+
+	if err := service.PruneSnapshots(ctx, map[string][]string{"session-1": {kept}}); err != nil {
+		t.Fatalf("PruneSnapshots failed: %v", err)
+	}
+
+	if !exists(kept) || !exists(alsoKept) {
+		t.Error("Expected the snapshots of the kept session to survive")
+	}
+	if exists(dropped) {
+		t.Error("Expected the snapshot of a dropped session to be pruned")
+	}
+	if exists(unreferenced) {
+		t.Error("Expected the snapshot taken without a session to be pruned")
+	}
+	if _, err := service.DiffSnapshots(ctx, kept, ""); err != nil {
+		t.Errorf("Expected the kept snapshot to diff, got %v", err)
+	}
+
+	refs, err := service.run(ctx, service.snapshotEnv(), "for-each-ref", "--format=%(refname)", snapshotRefPrefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Fields(refs); len(got) != 2 {
+		t.Errorf("Expected only the refs of session-1, got:\n%s", refs)
+	}
+
+	t.Run("keeps listed snapshots taken before they were kept by session", func(t *testing.T) {
+		older := snapshot("", "older\n")
+		if err := service.PruneSnapshots(ctx, map[string][]string{"session-1": {kept}, "session-3": {older, strings.Repeat("b", 40)}}); err != nil {
+			t.Fatalf("PruneSnapshots failed: %v", err)
+		}
+		if !exists(older) {
+			t.Error("Expected the listed snapshot to survive")
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		if _, err := service.Snapshot(ctx, "../HEAD"); !errors.Is(err, ErrInvalidSnapshot) {
+			t.Errorf("Expected ErrInvalidSnapshot, got %v", err)
+		}
+		if err := service.PruneSnapshots(ctx, map[string][]string{"session-1": {"HEAD"}}); !errors.Is(err, ErrInvalidSnapshot) {
+			t.Errorf("Expected ErrInvalidSnapshot, got %v", err)
+		}
+	})
+}
+
+func TestGitService_DiffSnapshotsInvalid(t *testing.T) {
+	service := NewGitService(t.TempDir(), "", "")
+	ctx := context.Background()
+
+	if _, err := service.DiffSnapshots(ctx, "HEAD~1", ""); !errors.Is(err, ErrInvalidSnapshot) {
+		t.Errorf("Expected ErrInvalidSnapshot, got %v", err)
+	}
+	if _, err := service.DiffSnapshots(ctx, strings.Repeat("a", 40), "--output=/tmp/x"); !errors.Is(err, ErrInvalidSnapshot) {
+		t.Errorf("Expected ErrInvalidSnapshot, got %v", err)
+	}
+}
+
+func TestParseUnifiedDiff(t *testing.T) {
+	patch := `diff --git a/a.txt b/b.txt
+similarity index 80%
+rename from a.txt
+rename to b.txt
+index 1111111..2222222 100644
+--- a/a.txt
++++ b/b.txt
+@@ -1,2 +1,2 @@
+ same
+--- removed line that looks like a header
++++ added line that looks like a header
+diff --git a/logo.png b/logo.png
+new file mode 100644
+index 0000000..3333333
+Binary files /dev/null and b/logo.png differ
+`
+
+	files := parseUnifiedDiff(patch)
+
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 files, got %d", len(files))
+	}
+	if files[0].Status != "renamed" || files[0].OldPath != "a.txt" || files[0].Path != "b.txt" {
+		t.Errorf("Unexpected rename: %+v", files[0])
+	}
+	if files[0].Additions != 1 || files[0].Deletions != 1 {
+		t.Errorf("Expected +1 -1 for rename, got +%d -%d", files[0].Additions, files[0].Deletions)
+	}
+	if files[1].Status != "added" || !files[1].Binary || files[1].Path != "logo.png" {
+		t.Errorf("Unexpected binary entry: %+v", files[1])
+	}
+}
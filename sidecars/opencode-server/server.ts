@@ -7,6 +7,7 @@
  * - GET /ready - Readiness probe (200 OK / 503 Service Unavailable)
  * - POST /sessions - Create new session
  * - GET /sessions/:id/stream - SSE stream for session output
+ * - POST /sessions/:id/prompt - Send a follow-up prompt to a session
  * - DELETE /sessions/:id - Cancel session
  * - GET /sessions/:id/status - Get session status
  */
@@ -14,7 +15,7 @@
 import { file, write as bunWrite } from "bun";
 import { access, constants } from "fs/promises";
 import { createOpencodeClient } from "@opencode-ai/sdk";
-import type { Session as OpenCodeSession, Message, Part } from "@opencode-ai/sdk";
+import type { Session as OpenCodeSession, AssistantMessage, Message, Part } from "@opencode-ai/sdk";
 
 // Environment configuration
 const PORT = parseInt(process.env.PORT || "3003", 10);
@@ -45,7 +46,8 @@ interface SessionState {
   prompt: string;
   modelConfig: ModelConfig;
   systemPrompt?: string;
-  status: "pending" | "running" | "waiting_input" | "completed" | "failed" | "cancelled";
+  readOnly?: boolean; // reviewer sessions may read the workspace but not change it
+  status: "pending" | "running" | "waiting_for_input" | "completed" | "failed" | "cancelled";
   createdAt: string;
   lastActivity: string;
   progress: number;
@@ -56,6 +58,13 @@ interface SessionState {
   lastEventId?: string;
   eventBuffer: Array<{ eventId: string; eventType: string; data: object }>;
   sseSubscribers: Set<ReadableStreamDefaultController>;
+  pendingPrompts: string[];
+  pendingQuestion?: string;
+  timeoutSeconds: number;
+  maxIterations?: number;
+  iterations: number;
+  cleanupTimer?: ReturnType<typeof setTimeout>;
+  eventStreamActive?: boolean;
 }
 
 interface ModelConfig {
@@ -69,6 +78,9 @@ interface ModelConfig {
   api_endpoint?: string;
 }
 
+// OpenCode tools that change the workspace, disabled for read-only sessions
+const WORKSPACE_WRITE_TOOLS = { write: false, edit: false, patch: false, bash: false };
+
 const sessions = new Map<string, SessionState>();
 const SESSION_CLEANUP_GRACE_PERIOD = 300000;
 
@@ -92,6 +104,12 @@ function cleanupSession(sessionId: string) {
   sessions.delete(sessionId);
 }
 
+// Drops a finished session after the grace period, unless a follow-up prompt resumes it first
+function scheduleCleanup(session: SessionState) {
+  clearTimeout(session.cleanupTimer);
+  session.cleanupTimer = setTimeout(() => cleanupSession(session.sessionId), SESSION_CLEANUP_GRACE_PERIOD);
+}
+
 function broadcastEvent(session: SessionState, eventId: string, eventType: string, data: object) {
   const encoder = new TextEncoder();
   const message = `event: ${eventType}\nid: ${eventId}\ndata: ${JSON.stringify(data)}\n\n`;
@@ -103,10 +121,6 @@ function broadcastEvent(session: SessionState, eventId: string, eventType: strin
   }
   session.lastEventId = eventId;
   
-  if (session.eventBuffer.length % 10 === 0) {
-    persistLastEventId(session.sessionId, eventId);
-  }
-  
   for (const controller of session.sseSubscribers) {
     try {
       controller.enqueue(encoded);
@@ -120,12 +134,37 @@ function broadcastEvent(session: SessionState, eventId: string, eventType: strin
   }
 }
 
+// Tells stream subscribers (the backend records them) that a session failed or was cancelled
+function broadcastFinalStatus(session: SessionState) {
+  broadcastEvent(session, `${session.opencodeSessionId || session.sessionId}-${session.status}-${Date.now()}`, "status", {
+    status: session.status,
+    ...(session.error ? { error: session.error } : {}),
+    timestamp: new Date().toISOString()
+  });
+}
+
+// Stops a session that ran past one of its limits and reports it failed
+function failSessionOverLimit(session: SessionState, reason: string) {
+  session.controller?.abort();
+  session.status = "failed";
+  session.error = reason;
+  session.lastActivity = new Date().toISOString();
+  log("warn", "Session limit exceeded", { 
+    sessionId: session.sessionId,
+    reason
+  });
+  
+  broadcastFinalStatus(session);
+  cleanupSession(session.sessionId);
+}
+
 async function startOpenCodeEventStream(session: SessionState) {
   if (!session.opencodeSessionId) {
     log("error", "Cannot start event stream without remote session ID", { sessionId: session.sessionId });
     return;
   }
   
+  session.eventStreamActive = true;
   try {
     const client = await getOpencodeClient();
     const eventsResult = await client.event.subscribe();
@@ -168,6 +207,13 @@ async function startOpenCodeEventStream(session: SessionState) {
           timestamp: new Date().toISOString()
         });
         session.currentTool = event.properties.tool;
+        
+        // Each tool call is one step of the agent loop
+        session.iterations++;
+        if (session.maxIterations && session.iterations > session.maxIterations) {
+          failSessionOverLimit(session, `Iteration limit of ${session.maxIterations} reached`);
+          break;
+        }
       } else if (event.type === "tool_result") {
         broadcastEvent(session, opencodeEventId, "tool_result", {
           tool: event.properties.tool,
@@ -181,6 +227,13 @@ async function startOpenCodeEventStream(session: SessionState) {
           text: event.properties.text,
           timestamp: new Date().toISOString()
         });
+      } else if (event.type === "question") {
+        // The session waits for the user's answer once the current prompt returns
+        session.pendingQuestion = event.properties.question || event.properties.text;
+        broadcastEvent(session, opencodeEventId, "question", {
+          question: session.pendingQuestion,
+          timestamp: new Date().toISOString()
+        });
       } else if (event.type === "progress") {
         session.progress = event.properties.percent || 0;
         broadcastEvent(session, opencodeEventId, "status", {
@@ -190,14 +243,9 @@ async function startOpenCodeEventStream(session: SessionState) {
         });
       }
 
+      // The final event itself is broadcast by executeSessionAsync
       if (session.status === "completed" || session.status === "failed") {
         clearInterval(heartbeatInterval);
-        const completeEventId = event.id || `${session.opencodeSessionId}-complete-${Date.now()}`;
-        broadcastEvent(session, completeEventId, "complete", {
-          final_message: event.properties.message || "Task completed",
-          files_modified: event.properties.files || [],
-          timestamp: new Date().toISOString()
-        });
         break;
       }
     }
@@ -216,6 +264,8 @@ async function startOpenCodeEventStream(session: SessionState) {
       fatal: true,
       timestamp: new Date().toISOString()
     });
+  } finally {
+    session.eventStreamActive = false;
   }
 }
 
@@ -285,11 +335,28 @@ function validateSessionRequest(body: any): ValidationError | null {
     return { field: "prompt", reason: `must not exceed ${MAX_PROMPT_LENGTH} characters` };
   }
   
-  if (!body.model_config || typeof body.model_config !== "object") {
-    return { field: "model_config", reason: "must be an object" };
+  const modelConfigError = validateModelConfig(body.model_config);
+  if (modelConfigError) {
+    return modelConfigError;
+  }
+  
+  const limitsError = validateSessionLimits(body);
+  if (limitsError) {
+    return limitsError;
+  }
+  
+  const readOnlyError = validateReadOnly(body.read_only);
+  if (readOnlyError) {
+    return readOnlyError;
   }
   
-  const mc = body.model_config;
+  return validateSystemPrompt(body.system_prompt);
+}
+
+function validateModelConfig(mc: any): ValidationError | null {
+  if (!mc || typeof mc !== "object") {
+    return { field: "model_config", reason: "must be an object" };
+  }
   
   if (!mc.provider || typeof mc.provider !== "string") {
     return { field: "model_config.provider", reason: "must be a non-empty string" };
@@ -330,17 +397,79 @@ function validateSessionRequest(body: any): ValidationError | null {
     }
   }
   
-  if (body.system_prompt && typeof body.system_prompt !== "string") {
+  return null;
+}
+
+function validateReadOnly(readOnly: any): ValidationError | null {
+  if (readOnly !== undefined && typeof readOnly !== "boolean") {
+    return { field: "read_only", reason: "must be a boolean" };
+  }
+  return null;
+}
+
+function validateSystemPrompt(systemPrompt: any): ValidationError | null {
+  if (systemPrompt && typeof systemPrompt !== "string") {
     return { field: "system_prompt", reason: "must be a string" };
   }
   
-  if (body.system_prompt && body.system_prompt.length > MAX_PROMPT_LENGTH) {
+  if (systemPrompt && systemPrompt.length > MAX_PROMPT_LENGTH) {
     return { field: "system_prompt", reason: `must not exceed ${MAX_PROMPT_LENGTH} characters` };
   }
   
   return null;
 }
 
+// The backend passes the project's limits; without them the sidecar's own timeout applies
+function validateSessionLimits(body: any): ValidationError | null {
+  if (body.timeout_seconds !== undefined && (!Number.isInteger(body.timeout_seconds) || body.timeout_seconds <= 0)) {
+    return { field: "timeout_seconds", reason: "must be a positive integer" };
+  }
+  
+  if (body.max_iterations !== undefined && (!Number.isInteger(body.max_iterations) || body.max_iterations <= 0)) {
+    return { field: "max_iterations", reason: "must be a positive integer" };
+  }
+  
+  return null;
+}
+
+// A session never runs longer than the sidecar's own timeout
+function sessionTimeoutSeconds(body: any): number {
+  return Math.min(body.timeout_seconds ?? SESSION_TIMEOUT, SESSION_TIMEOUT);
+}
+
+// Follow-up prompts carry the remote session and model config so that a session
+// the sidecar already dropped (e.g. after a restart) can be resumed
+function validatePromptRequest(body: any): ValidationError | null {
+  if (!body.prompt || typeof body.prompt !== "string") {
+    return { field: "prompt", reason: "must be a non-empty string" };
+  }
+  
+  if (body.prompt.length > MAX_PROMPT_LENGTH) {
+    return { field: "prompt", reason: `must not exceed ${MAX_PROMPT_LENGTH} characters` };
+  }
+  
+  if (!body.remote_session_id || typeof body.remote_session_id !== "string") {
+    return { field: "remote_session_id", reason: "must be a non-empty string" };
+  }
+  
+  const modelConfigError = validateModelConfig(body.model_config);
+  if (modelConfigError) {
+    return modelConfigError;
+  }
+  
+  const limitsError = validateSessionLimits(body);
+  if (limitsError) {
+    return limitsError;
+  }
+  
+  const readOnlyError = validateReadOnly(body.read_only);
+  if (readOnlyError) {
+    return readOnlyError;
+  }
+  
+  return validateSystemPrompt(body.system_prompt);
+}
+
 // Health check endpoint
 function handleHealthz(): Response {
   return Response.json({ status: "ok" });
@@ -415,13 +544,18 @@ async function handleCreateSession(req: Request): Promise<Response> {
       prompt: body.prompt,
       modelConfig: body.model_config,
       systemPrompt: body.system_prompt,
+      readOnly: body.read_only === true,
       status: "pending",
       createdAt: now,
       lastActivity: now,
       progress: 0,
       controller: new AbortController(),
       eventBuffer: [],
-      sseSubscribers: new Set()
+      sseSubscribers: new Set(),
+      pendingPrompts: [],
+      timeoutSeconds: sessionTimeoutSeconds(body),
+      maxIterations: body.max_iterations,
+      iterations: 0
     };
 
     sessions.set(body.session_id, session);
@@ -513,7 +647,9 @@ async function handleCreateSession(req: Request): Promise<Response> {
   }
 }
 
-async function executeSessionAsync(session: SessionState): Promise<void> {
+// Runs the session prompt, then any follow-up prompts queued meanwhile. The system prompt is only
+// sent with the first prompt of a session; follow-ups continue the same OpenCode conversation.
+async function executeSessionAsync(session: SessionState, includeSystemPrompt = true): Promise<void> {
   log("info", "Starting OpenCode prompt execution", { 
     sessionId: session.sessionId,
     opencodeSessionId: session.opencodeSessionId
@@ -521,18 +657,9 @@ async function executeSessionAsync(session: SessionState): Promise<void> {
   
   const timeoutId = setTimeout(() => {
     if (session.status === "running" || session.status === "pending") {
-      session.controller?.abort();
-      session.status = "failed";
-      session.error = `Session timeout after ${SESSION_TIMEOUT} seconds`;
-      session.lastActivity = new Date().toISOString();
-      log("warn", "Session timeout", { 
-        sessionId: session.sessionId,
-        timeout: SESSION_TIMEOUT 
-      });
-      
-      cleanupSession(session.sessionId);
+      failSessionOverLimit(session, `Session timeout after ${session.timeoutSeconds} seconds`);
     }
-  }, SESSION_TIMEOUT * 1000);
+  }, session.timeoutSeconds * 1000);
   
   try {
     const client = await getOpencodeClient();
@@ -541,29 +668,67 @@ async function executeSessionAsync(session: SessionState): Promise<void> {
       throw new Error("Missing remote session ID");
     }
     
-    const sendResult = await client.session.prompt({
-      path: { id: session.opencodeSessionId },
-      body: {
-        model: {
-          providerID: session.modelConfig.provider,
-          modelID: session.modelConfig.model,
-          apiKey: session.modelConfig.api_key,
-          temperature: session.modelConfig.temperature,
-          maxTokens: session.modelConfig.max_tokens
-        },
-        parts: [
-          ...(session.systemPrompt ? [{ type: "text" as const, text: session.systemPrompt }] : []),
-          { type: "text" as const, text: session.prompt }
-        ]
+    let prompt = session.prompt;
+    let output = "";
+    for (;;) {
+      const sendResult = await client.session.prompt({
+        path: { id: session.opencodeSessionId },
+        body: {
+          model: {
+            providerID: session.modelConfig.provider,
+            modelID: session.modelConfig.model,
+            apiKey: session.modelConfig.api_key,
+            temperature: session.modelConfig.temperature,
+            maxTokens: session.modelConfig.max_tokens
+          },
+          ...(session.readOnly ? { tools: WORKSPACE_WRITE_TOOLS } : {}),
+          parts: [
+            ...(includeSystemPrompt && session.systemPrompt ? [{ type: "text" as const, text: session.systemPrompt }] : []),
+            { type: "text" as const, text: prompt }
+          ]
+        }
+      });
+      
+      if (session.controller?.signal.aborted) {
+        session.status = "cancelled";
+        log("info", "Session cancelled during execution", { sessionId: session.sessionId });
+        clearTimeout(timeoutId);
+        broadcastFinalStatus(session);
+        cleanupSession(session.sessionId);
+        return;
       }
-    });
-    
-    if (session.controller?.signal.aborted) {
-      session.status = "cancelled";
-      log("info", "Session cancelled during execution", { sessionId: session.sessionId });
-      clearTimeout(timeoutId);
-      cleanupSession(session.sessionId);
-      return;
+      
+      output = extractResponseText(sendResult.data?.parts);
+      broadcastUsage(session, sendResult.data?.info);
+      
+      const nextPrompt = session.pendingPrompts.shift();
+      if (nextPrompt === undefined) {
+        if (session.pendingQuestion) {
+          // Paused until the answer arrives as a follow-up prompt; no timeout applies meanwhile
+          session.status = "waiting_for_input";
+          session.lastActivity = new Date().toISOString();
+          clearTimeout(timeoutId);
+          log("info", "Session waiting for user input", { 
+            sessionId: session.sessionId,
+            opencodeSessionId: session.opencodeSessionId
+          });
+          return;
+        }
+        break;
+      }
+      // A prompt queued while the agent asked its question is taken as the answer
+      session.pendingQuestion = undefined;
+      
+      // Each answered turn is reported so the backend can record it before the next one runs
+      broadcastEvent(session, `${session.opencodeSessionId}-turn-${Date.now()}`, "turn_complete", {
+        output,
+        timestamp: new Date().toISOString()
+      });
+      
+      session.prompt = nextPrompt;
+      session.lastActivity = new Date().toISOString();
+      prompt = nextPrompt;
+      includeSystemPrompt = false;
     }
     
     session.status = "completed";
@@ -575,8 +740,17 @@ async function executeSessionAsync(session: SessionState): Promise<void> {
       sessionId: session.sessionId,
       opencodeSessionId: session.opencodeSessionId
     });
+
+    broadcastEvent(session, `${session.opencodeSessionId}-complete-${Date.now()}`, "complete", {
+      final_message: "Task completed",
+      output,
+      timestamp: new Date().toISOString()
+    });
+
+    // Lets the backend finalize the session (e.g. commit the task branch or read a review verdict)
+    await markSessionCompleted(session.sessionId, output);
     
-    setTimeout(() => cleanupSession(session.sessionId), SESSION_CLEANUP_GRACE_PERIOD);
+    scheduleCleanup(session);
     
   } catch (error) {
     clearTimeout(timeoutId);
@@ -584,6 +758,7 @@ async function executeSessionAsync(session: SessionState): Promise<void> {
     if (session.controller?.signal.aborted) {
       session.status = "cancelled";
       log("info", "Session cancelled during execution", { sessionId: session.sessionId });
+      broadcastFinalStatus(session);
       cleanupSession(session.sessionId);
     } else {
       session.status = "failed";
@@ -596,7 +771,8 @@ async function executeSessionAsync(session: SessionState): Promise<void> {
         error: session.error
       });
       
-      setTimeout(() => cleanupSession(session.sessionId), SESSION_CLEANUP_GRACE_PERIOD);
+      broadcastFinalStatus(session);
+      scheduleCleanup(session);
     }
   }
 }
@@ -626,20 +802,21 @@ function handleSessionStream(sessionId: string, req: Request): Response {
       
       session.sseSubscribers.add(controller);
       
-      if (lastEventId) {
-        const replayIndex = session.eventBuffer.findIndex(e => e.eventId === lastEventId);
-        if (replayIndex !== -1) {
-          const eventsToReplay = session.eventBuffer.slice(replayIndex + 1);
-          for (const event of eventsToReplay) {
-            controller.enqueue(encoder.encode(
-              `event: ${event.eventType}\nid: ${event.eventId}\ndata: ${JSON.stringify(event.data)}\n\n`
-            ));
-          }
-          log("info", "Replayed events from buffer", { 
-            sessionId, 
-            count: eventsToReplay.length 
-          });
-        }
+      // Replay what the client missed. Without a known Last-Event-ID the whole buffer is sent,
+      // which covers events broadcast before the client connected (e.g. right after a follow-up
+      // prompt) and events of a session the sidecar resumed after dropping it.
+      const replayIndex = lastEventId ? session.eventBuffer.findIndex(e => e.eventId === lastEventId) : -1;
+      const eventsToReplay = session.eventBuffer.slice(replayIndex + 1);
+      for (const event of eventsToReplay) {
+        controller.enqueue(encoder.encode(
+          `event: ${event.eventType}\nid: ${event.eventId}\ndata: ${JSON.stringify(event.data)}\n\n`
+        ));
+      }
+      if (eventsToReplay.length > 0) {
+        log("info", "Replayed events from buffer", { 
+          sessionId, 
+          count: eventsToReplay.length 
+        });
       }
 
       const initialEventId = `${session.opencodeSessionId || sessionId}-init-${Date.now()}`;
@@ -680,6 +857,168 @@ function handleSessionStream(sessionId: string, req: Request): Response {
   });
 }
 
+// Follow-up prompt endpoint. Running sessions queue the prompt after the current one;
+// sessions waiting for input take it as the answer to their question, and finished sessions
+// run it in the same OpenCode session, which keeps the conversation so far.
+async function handlePromptSession(sessionId: string, req: Request): Promise<Response> {
+  try {
+    const body = await req.json();
+    
+    const validationError = validatePromptRequest(body);
+    if (validationError) {
+      return Response.json(
+        { 
+          error: "Invalid request",
+          details: validationError,
+          timestamp: new Date().toISOString()
+        },
+        { status: 400 }
+      );
+    }
+
+    let session = sessions.get(sessionId);
+
+    if (session && (session.status === "running" || session.status === "pending")) {
+      session.pendingPrompts.push(body.prompt);
+      session.lastActivity = new Date().toISOString();
+      
+      log("info", "Follow-up prompt queued", { 
+        sessionId, 
+        queued: session.pendingPrompts.length 
+      });
+      
+      return Response.json(
+        {
+          session_id: sessionId,
+          remote_session_id: session.opencodeSessionId,
+          status: "queued"
+        },
+        { status: 202 }
+      );
+    }
+
+    const runningSessions = Array.from(sessions.values()).filter(
+      s => s.status === "running" || s.status === "pending"
+    );
+    if (runningSessions.length >= MAX_CONCURRENT_SESSIONS) {
+      return Response.json(
+        { 
+          error: `Maximum concurrent sessions limit reached (${MAX_CONCURRENT_SESSIONS})`,
+          timestamp: new Date().toISOString()
+        },
+        { status: 503 }
+      );
+    }
+
+    const client = await getOpencodeClient();
+
+    if (!session) {
+      // The session was cleaned up or the sidecar restarted; OpenCode still holds the conversation
+      const remoteSession = await client.session.get({
+        path: { id: body.remote_session_id }
+      });
+      if (!remoteSession.data) {
+        return Response.json(
+          { 
+            error: "OpenCode session not found",
+            timestamp: new Date().toISOString()
+          },
+          { status: 404 }
+        );
+      }
+
+      const now = new Date().toISOString();
+      session = {
+        sessionId,
+        prompt: body.prompt,
+        modelConfig: body.model_config,
+        systemPrompt: body.system_prompt,
+        readOnly: body.read_only === true,
+        status: "pending",
+        createdAt: now,
+        lastActivity: now,
+        progress: 0,
+        opencodeSessionId: body.remote_session_id,
+        eventBuffer: [],
+        sseSubscribers: new Set(),
+        pendingPrompts: [],
+        timeoutSeconds: sessionTimeoutSeconds(body),
+        maxIterations: body.max_iterations,
+        iterations: 0
+      };
+      sessions.set(sessionId, session);
+    } else {
+      clearTimeout(session.cleanupTimer);
+      session.cleanupTimer = undefined;
+      session.modelConfig = body.model_config;
+      session.timeoutSeconds = sessionTimeoutSeconds(body);
+      session.maxIterations = body.max_iterations;
+      // A session waiting for input goes on with the same run; events of a finished run
+      // must not be replayed as part of this one
+      if (session.status !== "waiting_for_input") {
+        session.eventBuffer = [];
+      }
+    }
+
+    session.prompt = body.prompt;
+    session.pendingQuestion = undefined;
+    session.status = "running";
+    session.error = undefined;
+    session.progress = 0;
+    session.currentTool = undefined;
+    // Limits apply to each run, like the timeout restarted by executeSessionAsync
+    session.iterations = 0;
+    session.controller = new AbortController();
+    session.lastActivity = new Date().toISOString();
+
+    log("info", "Session resumed with follow-up prompt", { 
+      sessionId,
+      opencodeSessionId: session.opencodeSessionId
+    });
+
+    const resumed = session;
+    executeSessionAsync(resumed, false).catch(err => {
+      log("error", "Session execution failed", { 
+        sessionId, 
+        error: err.message 
+      });
+      resumed.status = "failed";
+      resumed.error = err.message;
+    });
+
+    if (!resumed.eventStreamActive) {
+      startOpenCodeEventStream(resumed).catch(err => {
+        log("error", "Event stream failed to start", {
+          sessionId,
+          error: err.message
+        });
+      });
+    }
+
+    return Response.json(
+      {
+        session_id: sessionId,
+        remote_session_id: resumed.opencodeSessionId,
+        status: "running"
+      },
+      { status: 202 }
+    );
+  } catch (error) {
+    log("error", "Failed to send follow-up prompt", { 
+      sessionId,
+      error: error instanceof Error ? error.message : String(error) 
+    });
+    return Response.json(
+      { 
+        error: "Failed to send follow-up prompt",
+        details: error instanceof Error ? error.message : String(error),
+        timestamp: new Date().toISOString()
+      },
+      { status: 500 }
+    );
+  }
+}
+
 async function handleCancelSession(sessionId: string): Promise<Response> {
   const session = sessions.get(sessionId);
   
@@ -693,10 +1032,18 @@ async function handleCancelSession(sessionId: string): Promise<Response> {
     );
   }
 
+  // No prompt is in flight while the session waits for input, so nothing else reports the cancellation
+  const wasWaiting = session.status === "waiting_for_input";
+
   session.controller?.abort();
   session.status = "cancelled";
   session.lastActivity = new Date().toISOString();
 
+  if (wasWaiting) {
+    broadcastFinalStatus(session);
+    scheduleCleanup(session);
+  }
+
   if (session.opencodeSessionId) {
     try {
       const client = await getOpencodeClient();
@@ -745,7 +1092,8 @@ function handleSessionStatus(sessionId: string): Response {
     created_at: session.createdAt,
     last_activity: session.lastActivity,
     progress: session.progress,
-    current_tool: session.currentTool
+    current_tool: session.currentTool,
+    ...(session.error ? { error: session.error } : {})
   });
 }
 
@@ -779,6 +1127,11 @@ async function handleRequest(req: Request): Promise<Response> {
     return handleSessionStream(streamMatch[1], req);
   }
 
+  const promptMatch = path.match(/^\/sessions\/([^/]+)\/prompt$/);
+  if (method === "POST" && promptMatch) {
+    return handlePromptSession(promptMatch[1], req);
+  }
+
   const cancelMatch = path.match(/^\/sessions\/([^/]+)$/);
   if (method === "DELETE" && cancelMatch) {
     return handleCancelSession(cancelMatch[1]);
@@ -820,15 +1173,22 @@ function setupGracefulShutdown(server: any) {
   process.on("SIGINT", () => shutdown("SIGINT"));
 }
 
+// The backend only accepts session callbacks carrying the shared secret
+function backendHeaders(): Record<string, string> {
+  const headers: Record<string, string> = { "Content-Type": "application/json" };
+  if (OPENCODE_SHARED_SECRET) {
+    headers["Authorization"] = `Bearer ${OPENCODE_SHARED_SECRET}`;
+  }
+  return headers;
+}
+
 async function recoverActiveSessions() {
   log("info", "Starting session recovery from backend database");
   
   try {
     const response = await fetch(`${BACKEND_API_URL}/api/sessions/active`, {
       method: "GET",
-      headers: {
-        "Content-Type": "application/json"
-      }
+      headers: backendHeaders()
     });
 
     if (!response.ok) {
@@ -877,6 +1237,7 @@ async function recoverActiveSessions() {
               max_tokens: dbSession.model_config?.max_tokens || 4096,
               enabled_tools: dbSession.model_config?.enabled_tools || []
             },
+            readOnly: dbSession.kind === "review",
             status: mapOpencodeStatus(sessionStatus.data.status),
             createdAt: dbSession.created_at,
             lastActivity: new Date().toISOString(),
@@ -885,7 +1246,8 @@ async function recoverActiveSessions() {
             opencodeSessionId: dbSession.remote_session_id,
             lastEventId: dbSession.last_event_id,
             eventBuffer: [],
-            sseSubscribers: new Set()
+            sseSubscribers: new Set(),
+            pendingPrompts: []
           };
 
           sessions.set(dbSession.id, recoveredSession);
@@ -936,9 +1298,7 @@ async function markSessionFailed(sessionId: string, reason: string) {
   try {
     await fetch(`${BACKEND_API_URL}/api/sessions/${sessionId}/status`, {
       method: "PATCH",
-      headers: {
-        "Content-Type": "application/json"
-      },
+      headers: backendHeaders(),
       body: JSON.stringify({
         status: "failed",
         error: reason
@@ -952,21 +1312,40 @@ async function markSessionFailed(sessionId: string, reason: string) {
   }
 }
 
-async function persistLastEventId(sessionId: string, lastEventId: string) {
+// Reports the tokens a prompt used, so the backend can account for its cost
+function broadcastUsage(session: SessionState, info: AssistantMessage | undefined) {
+  if (!info?.tokens) return;
+
+  broadcastEvent(session, `${session.opencodeSessionId}-usage-${info.id || Date.now()}`, "usage", {
+    provider: info.providerID || session.modelConfig.provider,
+    model: info.modelID || session.modelConfig.model,
+    input_tokens: info.tokens.input + (info.tokens.cache?.read || 0) + (info.tokens.cache?.write || 0),
+    output_tokens: info.tokens.output + (info.tokens.reasoning || 0),
+    timestamp: new Date().toISOString()
+  });
+}
+
+function extractResponseText(parts: Part[] | undefined): string {
+  if (!parts) return "";
+  return parts
+    .filter((part): part is Extract<Part, { type: "text" }> => part.type === "text")
+    .map((part) => part.text)
+    .join("\n");
+}
+
+async function markSessionCompleted(sessionId: string, output: string) {
   try {
     await fetch(`${BACKEND_API_URL}/api/sessions/${sessionId}/status`, {
       method: "PATCH",
-      headers: {
-        "Content-Type": "application/json"
-      },
+      headers: backendHeaders(),
       body: JSON.stringify({
-        last_event_id: lastEventId
+        status: "completed",
+        ...(output ? { output } : {})
       })
     });
   } catch (error) {
-    log("debug", "Failed to persist last_event_id to backend", {
+    log("error", "Failed to update session status in backend", {
       sessionId,
-      lastEventId,
       error: error instanceof Error ? error.message : String(error)
     });
   }
@@ -1,45 +1,140 @@
 package main
 
 import (
-	"log"
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/npinot/vibe/sidecars/session-proxy/internal/handler"
+	"github.com/npinot/vibe/sidecars/session-proxy/internal/service"
 )
 
 func main() {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	}))
+	slog.SetDefault(logger)
+
 	opencodeURL := os.Getenv("OPENCODE_URL")
 	if opencodeURL == "" {
-		opencodeURL = "http://localhost:3000"
+		opencodeURL = "http://localhost:3003"
+	}
+
+	workspaceDir := os.Getenv("WORKSPACE_DIR")
+	if workspaceDir == "" {
+		workspaceDir = "/workspace"
+	}
+
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "3002"
+	}
+
+	sharedSecret := os.Getenv("OPENCODE_SHARED_SECRET")
+
+	// Empty picks bash, or sh when the image has no bash
+	terminalShell := os.Getenv("TERMINAL_SHELL")
+
+	// Shells run unprivileged so they cannot read the shared secret from the sidecar's environment
+	terminalUserName := os.Getenv("TERMINAL_USER")
+	if terminalUserName == "" {
+		terminalUserName = "terminal"
+	}
+	terminalUser, err := service.LookupTerminalUser(terminalUserName)
+	if err != nil {
+		slog.Error("Invalid TERMINAL_USER", "value", terminalUserName, "error", err)
+		os.Exit(1)
+	}
+
+	sessionRetention := service.DefaultSessionRetention
+	if value := os.Getenv("SESSION_RETENTION"); value != "" {
+		retention, err := time.ParseDuration(value)
+		if err != nil || retention <= 0 {
+			slog.Error("Invalid SESSION_RETENTION", "value", value)
+			os.Exit(1)
+		}
+		sessionRetention = retention
 	}
 
+	// Session state lives on the workspace volume so it survives container restarts
+	store, err := service.NewSessionStore(filepath.Join(workspaceDir, ".vibe", "sessions"))
+	if err != nil {
+		slog.Error("Failed to create session store", "error", err)
+		os.Exit(1)
+	}
+
+	proxy := service.NewSessionProxy(opencodeURL, sharedSecret, store)
+	if err := proxy.Restore(); err != nil {
+		slog.Error("Failed to restore sessions", "error", err)
+		os.Exit(1)
+	}
+	defer proxy.Close()
+	go proxy.PruneFinished(sessionRetention)
+
+	if os.Getenv("LOG_LEVEL") != "debug" {
+		gin.SetMode(gin.ReleaseMode)
+	}
 	router := gin.Default()
 
-	sessionHandler := handler.NewSessionHandler(opencodeURL)
+	sessionHandler := handler.NewSessionHandler(proxy)
+	terminalHandler := handler.NewTerminalHandler(workspaceDir, terminalShell, terminalUser)
 
 	router.GET("/healthz", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok"})
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	})
 
-	session := router.Group("/session")
+	session := router.Group("/session", handler.RequireSharedSecret(sharedSecret))
 	{
 		session.GET("", sessionHandler.ListSessions)
 		session.POST("", sessionHandler.CreateSession)
 		session.GET("/:id", sessionHandler.GetSession)
+		session.DELETE("/:id", sessionHandler.CancelSession)
 		session.GET("/:id/events", sessionHandler.StreamEvents)
-		session.GET("/:id/pty/connect", sessionHandler.ConnectPTY)
+		session.GET("/:id/pty/connect", terminalHandler.ConnectPTY)
 		session.POST("/:id/prompt", sessionHandler.SendPrompt)
 	}
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "3002"
+	// Cancelled on shutdown to end event streams and terminals, which stay open until the client leaves
+	baseCtx, stopRequests := context.WithCancel(context.Background())
+
+	srv := &http.Server{
+		Addr:    ":" + port,
+		Handler: router,
+		BaseContext: func(net.Listener) context.Context {
+			return baseCtx
+		},
 	}
 
-	log.Printf("Session Proxy Sidecar starting on port %s", port)
-	if err := router.Run(":" + port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	go func() {
+		slog.Info("Session Proxy Sidecar starting", "port", port, "opencode_url", opencodeURL)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			slog.Error("Failed to start server", "error", err)
+			os.Exit(1)
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	slog.Info("Shutting down Session Proxy Sidecar...")
+	stopRequests()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		slog.Error("Server forced to shutdown", "error", err)
+		os.Exit(1)
 	}
+
+	slog.Info("Session Proxy Sidecar stopped gracefully")
 }
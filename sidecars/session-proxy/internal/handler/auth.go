@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireSharedSecret rejects requests without the shared secret as bearer token,
+// like the OpenCode server does. An empty secret disables the check.
+func RequireSharedSecret(secret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if secret == "" {
+			c.Next()
+			return
+		}
+
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: missing Authorization header"})
+			return
+		}
+
+		scheme, token, _ := strings.Cut(authHeader, " ")
+		if scheme != "Bearer" || token != secret {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: invalid credentials"})
+			return
+		}
+
+		c.Next()
+	}
+}
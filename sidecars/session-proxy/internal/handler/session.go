@@ -1,39 +1,237 @@
 package handler
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
 	"github.com/gin-gonic/gin"
+
+	"github.com/npinot/vibe/sidecars/session-proxy/internal/service"
 )
 
+// keepAliveInterval is how often an idle event stream gets a comment line to keep it open
+const keepAliveInterval = 30 * time.Second
+
+// SessionHandler proxies the session API of the OpenCode server and serves session event streams
+// from the proxy's buffer
 type SessionHandler struct {
-	opencodeURL string
+	proxy *service.SessionProxy
 }
 
-func NewSessionHandler(opencodeURL string) *SessionHandler {
+// NewSessionHandler creates a session handler backed by the proxy
+func NewSessionHandler(proxy *service.SessionProxy) *SessionHandler {
 	return &SessionHandler{
-		opencodeURL: opencodeURL,
+		proxy: proxy,
 	}
 }
 
+// upstreamSession holds the fields of the OpenCode server's session responses the proxy records
+type upstreamSession struct {
+	SessionID       string `json:"session_id"`
+	RemoteSessionID string `json:"remote_session_id"`
+	Status          string `json:"status"`
+	Error           string `json:"error"`
+}
+
+// ListSessions returns the sessions the proxy has recorded, including finished ones
 func (h *SessionHandler) ListSessions(c *gin.Context) {
-	c.JSON(501, gin.H{"error": "Not implemented yet"})
+	c.JSON(http.StatusOK, gin.H{"sessions": h.proxy.List()})
 }
 
+// CreateSession starts a session on the OpenCode server and begins buffering its events
 func (h *SessionHandler) CreateSession(c *gin.Context) {
-	c.JSON(501, gin.H{"error": "Not implemented yet"})
+	status, body, ok := h.forward(c, http.MethodPost, "/sessions")
+	if !ok {
+		return
+	}
+
+	if isSuccess(status) {
+		var session upstreamSession
+		if err := json.Unmarshal(body, &session); err == nil && session.SessionID != "" {
+			h.track(service.SessionRecord{
+				ID:              session.SessionID,
+				RemoteSessionID: session.RemoteSessionID,
+				Status:          session.Status,
+			})
+		}
+	}
+
+	c.Data(status, "application/json", body)
 }
 
+// GetSession returns the session status from the OpenCode server. A finished session the server
+// has already dropped is answered from the proxy's record.
 func (h *SessionHandler) GetSession(c *gin.Context) {
-	c.JSON(501, gin.H{"error": "Not implemented yet"})
+	sessionID := c.Param("id")
+
+	status, body, ok := h.forward(c, http.MethodGet, "/sessions/"+sessionID+"/status")
+	if !ok {
+		return
+	}
+
+	switch {
+	case status == http.StatusOK:
+		var session upstreamSession
+		if err := json.Unmarshal(body, &session); err == nil && session.Status != "" {
+			h.track(service.SessionRecord{ID: sessionID, Status: session.Status, Error: session.Error})
+		}
+	case status == http.StatusNotFound:
+		if record, exists := h.proxy.Get(sessionID); exists && service.IsTerminalStatus(record.Status) {
+			c.JSON(http.StatusOK, record)
+			return
+		}
+	}
+
+	c.Data(status, "application/json", body)
 }
 
+// StreamEvents streams the session's events as server-sent events. Events after the Last-Event-ID
+// header are replayed from the buffer first, so reconnecting clients lose nothing.
 func (h *SessionHandler) StreamEvents(c *gin.Context) {
-	c.JSON(501, gin.H{"error": "Not implemented yet"})
-}
+	sessionID := c.Param("id")
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+
+	sub, err := h.proxy.Subscribe(sessionID, lastEventID)
+	if err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
 
-func (h *SessionHandler) ConnectPTY(c *gin.Context) {
-	c.JSON(501, gin.H{"error": "Not implemented yet"})
+	w := c.Writer
+	for _, event := range sub.Replay {
+		if _, err := io.WriteString(w, service.FormatEvent(event)); err != nil {
+			return
+		}
+	}
+	w.Flush()
+
+	// Nothing more comes for a finished session
+	if service.IsTerminalStatus(sub.Status) {
+		return
+	}
+
+	ticker := time.NewTicker(keepAliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, open := <-sub.Events:
+			if !open {
+				// Dropped for falling behind; the client resumes from its last event
+				return
+			}
+			if _, err := io.WriteString(w, service.FormatEvent(event)); err != nil {
+				return
+			}
+			w.Flush()
+		case <-ticker.C:
+			if _, err := io.WriteString(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+			w.Flush()
+		}
+	}
 }
 
+// SendPrompt sends a follow-up prompt to the session and resumes buffering its events
 func (h *SessionHandler) SendPrompt(c *gin.Context) {
-	c.JSON(501, gin.H{"error": "Not implemented yet"})
+	sessionID := c.Param("id")
+
+	status, body, ok := h.forward(c, http.MethodPost, "/sessions/"+sessionID+"/prompt")
+	if !ok {
+		return
+	}
+
+	if isSuccess(status) {
+		var session upstreamSession
+		_ = json.Unmarshal(body, &session)
+
+		// Queued or not, the session has a prompt to answer
+		h.track(service.SessionRecord{
+			ID:              sessionID,
+			RemoteSessionID: session.RemoteSessionID,
+			Status:          service.StatusRunning,
+		})
+	}
+
+	c.Data(status, "application/json", body)
+}
+
+// CancelSession cancels the session on the OpenCode server
+func (h *SessionHandler) CancelSession(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	status, body, ok := h.forward(c, http.MethodDelete, "/sessions/"+sessionID)
+	if !ok {
+		return
+	}
+
+	if isSuccess(status) {
+		h.track(service.SessionRecord{ID: sessionID, Status: service.StatusCancelled})
+	}
+
+	c.Data(status, "application/json", body)
+}
+
+// forward sends the request body to the OpenCode server and returns its response.
+// It answers the client itself when the server cannot be reached.
+func (h *SessionHandler) forward(c *gin.Context, method, path string) (int, []byte, bool) {
+	var reqBody io.Reader
+	if c.Request.Body != nil && method != http.MethodGet && method != http.MethodDelete {
+		data, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+			return 0, nil, false
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	resp, err := h.proxy.Forward(c.Request.Context(), method, path, reqBody)
+	if err != nil {
+		slog.Error("Failed to forward request", "method", method, "path", path, "error", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "OpenCode server unavailable"})
+		return 0, nil, false
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to read OpenCode server response"})
+		return 0, nil, false
+	}
+
+	return resp.StatusCode, body, true
+}
+
+// track records a session update, logging failures: the request itself already succeeded upstream
+func (h *SessionHandler) track(update service.SessionRecord) {
+	if err := h.proxy.Track(update); err != nil {
+		slog.Error("Failed to record session", "session_id", update.ID, "error", err)
+	}
+}
+
+func isSuccess(status int) bool {
+	return status >= 200 && status < 300
 }
@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/npinot/vibe/sidecars/session-proxy/internal/service"
+)
+
+// setupSessionHandler routes the handler like main does, in front of a fake OpenCode server
+func setupSessionHandler(t *testing.T, upstream http.HandlerFunc) (*gin.Engine, *service.SessionProxy) {
+	t.Helper()
+
+	server := httptest.NewServer(upstream)
+	t.Cleanup(server.Close)
+
+	store, err := service.NewSessionStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy := service.NewSessionProxy(server.URL, "secret", store)
+	t.Cleanup(proxy.Close)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := NewSessionHandler(proxy)
+
+	session := router.Group("/session", RequireSharedSecret("secret"))
+	{
+		session.GET("", h.ListSessions)
+		session.POST("", h.CreateSession)
+		session.GET("/:id", h.GetSession)
+		session.DELETE("/:id", h.CancelSession)
+		session.GET("/:id/events", h.StreamEvents)
+		session.POST("/:id/prompt", h.SendPrompt)
+	}
+
+	return router, proxy
+}
+
+func authorizedRequest(method, path, body string) *http.Request {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer secret")
+	return req
+}
+
+// completedSessionUpstream creates sessions and streams them straight to completion
+func completedSessionUpstream(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("Request to %s not authenticated", r.URL.Path)
+		}
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/sessions":
+			body, _ := io.ReadAll(r.Body)
+			if !strings.Contains(string(body), `"prompt":"Fix the bug"`) {
+				t.Errorf("Request body not forwarded: %s", body)
+			}
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"session_id": "session-1", "remote_session_id": "ses_abc", "status": "running"}`))
+		case r.URL.Path == "/sessions/session-1/stream":
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Write([]byte("event: output\nid: evt-1\ndata: {\"text\": \"Fixed\"}\n\nevent: complete\nid: evt-2\ndata: {\"output\": \"Fixed\"}\n\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": "Session not found"}`))
+		}
+	}
+}
+
+func waitForCompletion(t *testing.T, proxy *service.SessionProxy, sessionID string) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if record, exists := proxy.Get(sessionID); exists && record.Status == service.StatusCompleted {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Session %s did not complete", sessionID)
+}
+
+func TestSessionHandler_CreateSession(t *testing.T) {
+	router, proxy := setupSessionHandler(t, completedSessionUpstream(t))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, authorizedRequest(http.MethodPost, "/session", `{"session_id":"session-1","prompt":"Fix the bug"}`))
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "ses_abc") {
+		t.Errorf("Expected the upstream response, got %s", w.Body.String())
+	}
+
+	waitForCompletion(t, proxy, "session-1")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, authorizedRequest(http.MethodGet, "/session", ""))
+
+	var resp struct {
+		Sessions []service.SessionRecord `json:"sessions"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Sessions) != 1 || resp.Sessions[0].RemoteSessionID != "ses_abc" {
+		t.Errorf("Expected the created session listed, got %+v", resp.Sessions)
+	}
+}
+
+func TestSessionHandler_StreamEventsReplaysBuffer(t *testing.T) {
+	router, proxy := setupSessionHandler(t, completedSessionUpstream(t))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, authorizedRequest(http.MethodPost, "/session", `{"session_id":"session-1","prompt":"Fix the bug"}`))
+	waitForCompletion(t, proxy, "session-1")
+
+	req := authorizedRequest(http.MethodGet, "/session/session-1/events", "")
+	req.Header.Set("Last-Event-ID", "evt-1")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Expected event stream, got %s", ct)
+	}
+
+	var ids []string
+	scanner := bufio.NewScanner(w.Body)
+	for scanner.Scan() {
+		if id, found := strings.CutPrefix(scanner.Text(), "id: "); found {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) != 1 || ids[0] != "evt-2" {
+		t.Errorf("Expected only evt-2 replayed, got %v", ids)
+	}
+}
+
+func TestSessionHandler_StreamEventsUnknownSession(t *testing.T) {
+	router, _ := setupSessionHandler(t, completedSessionUpstream(t))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, authorizedRequest(http.MethodGet, "/session/unknown/events", ""))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
+
+func TestSessionHandler_GetSessionFallsBackToRecord(t *testing.T) {
+	router, proxy := setupSessionHandler(t, completedSessionUpstream(t))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, authorizedRequest(http.MethodPost, "/session", `{"session_id":"session-1","prompt":"Fix the bug"}`))
+	waitForCompletion(t, proxy, "session-1")
+
+	// The fake server does not know the session anymore, like after its cleanup
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, authorizedRequest(http.MethodGet, "/session/session-1", ""))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), `"status":"completed"`) {
+		t.Errorf("Expected the recorded status, got %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, authorizedRequest(http.MethodGet, "/session/unknown", ""))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown session, got %d", w.Code)
+	}
+}
+
+func TestSessionHandler_UpstreamUnavailable(t *testing.T) {
+	router, _ := setupSessionHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, authorizedRequest(http.MethodPost, "/session/session-1/prompt", `{"prompt":"More"}`))
+
+	if w.Code != http.StatusBadGateway {
+		t.Errorf("Expected status 502, got %d", w.Code)
+	}
+}
+
+func TestRequireSharedSecret(t *testing.T) {
+	router, _ := setupSessionHandler(t, completedSessionUpstream(t))
+
+	tests := []struct {
+		name   string
+		header string
+		status int
+	}{
+		{"missing header", "", http.StatusUnauthorized},
+		{"wrong secret", "Bearer nope", http.StatusUnauthorized},
+		{"wrong scheme", "Basic secret", http.StatusUnauthorized},
+		{"valid secret", "Bearer secret", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/session", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, w.Code)
+			}
+		})
+	}
+}
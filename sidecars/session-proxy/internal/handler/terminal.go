@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"github.com/npinot/vibe/sidecars/session-proxy/internal/service"
+)
+
+var terminalUpgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool {
+		// Only the backend reaches the sidecar, authenticated by the shared secret
+		return true
+	},
+	ReadBufferSize:  4096,
+	WriteBufferSize: 32 * 1024,
+}
+
+// TerminalMessage is a control message sent by the client as a text frame.
+// Binary frames carry raw terminal input.
+type TerminalMessage struct {
+	Type string `json:"type"`           // input, resize
+	Data string `json:"data,omitempty"` // input
+	Cols uint16 `json:"cols,omitempty"` // resize
+	Rows uint16 `json:"rows,omitempty"` // resize
+}
+
+// TerminalHandler serves interactive shells in the workspace over WebSocket
+type TerminalHandler struct {
+	workspaceDir string
+	shell        string
+	account      *service.TerminalUser
+}
+
+// NewTerminalHandler creates a terminal handler running shell in workspaceDir as account.
+// An empty shell picks bash, or sh when bash is not installed. A nil account runs shells
+// as the sidecar's own user.
+func NewTerminalHandler(workspaceDir, shell string, account *service.TerminalUser) *TerminalHandler {
+	return &TerminalHandler{
+		workspaceDir: workspaceDir,
+		shell:        shell,
+		account:      account,
+	}
+}
+
+// ConnectPTY runs a shell for the WebSocket connection. Output is sent as binary frames;
+// a final text frame {"type":"exit","code":N} reports the shell exiting. The shell is killed
+// when the connection closes. Optional cols and rows query parameters set the initial size.
+func (h *TerminalHandler) ConnectPTY(c *gin.Context) {
+	cols := parseTerminalSize(c.Query("cols"))
+	rows := parseTerminalSize(c.Query("rows"))
+
+	terminal, err := service.StartTerminal(h.workspaceDir, h.shell, h.account, cols, rows)
+	if err != nil {
+		slog.Error("Failed to start terminal", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start terminal"})
+		return
+	}
+	defer terminal.Close()
+
+	conn, err := terminalUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		slog.Error("Failed to upgrade connection to WebSocket", "error", err)
+		return
+	}
+	defer conn.Close()
+
+	terminalID := c.Param("id")
+	slog.Info("Terminal started", "terminal_id", terminalID)
+
+	// Client -> terminal; the shell is killed by the deferred Close once the client leaves
+	clientGone := make(chan struct{})
+	go func() {
+		defer close(clientGone)
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err := applyTerminalMessage(terminal, messageType, data); err != nil {
+				slog.Warn("Invalid terminal message", "terminal_id", terminalID, "error", err)
+			}
+		}
+	}()
+
+	// Terminal -> client, until the shell exits and its output is drained
+	outputDone := make(chan struct{})
+	go func() {
+		defer close(outputDone)
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := terminal.Read(buf)
+			if n > 0 {
+				if err := conn.WriteMessage(websocket.BinaryMessage, buf[:n]); err != nil {
+					return
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	select {
+	case <-c.Request.Context().Done():
+		slog.Info("Terminal closed on shutdown", "terminal_id", terminalID)
+	case <-clientGone:
+		slog.Info("Terminal client disconnected", "terminal_id", terminalID)
+	case <-outputDone:
+		code := terminal.ExitCode()
+		conn.WriteJSON(gin.H{"type": "exit", "code": code})
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "shell exited"))
+		slog.Info("Terminal shell exited", "terminal_id", terminalID, "code", code)
+	}
+}
+
+func applyTerminalMessage(terminal *service.Terminal, messageType int, data []byte) error {
+	if messageType == websocket.BinaryMessage {
+		_, err := terminal.Write(data)
+		return err
+	}
+
+	var msg TerminalMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return err
+	}
+
+	switch msg.Type {
+	case "input":
+		_, err := terminal.Write([]byte(msg.Data))
+		return err
+	case "resize":
+		return terminal.Resize(msg.Cols, msg.Rows)
+	}
+	return nil
+}
+
+func parseTerminalSize(value string) uint16 {
+	n, err := strconv.ParseUint(value, 10, 16)
+	if err != nil {
+		return 0
+	}
+	return uint16(n)
+}
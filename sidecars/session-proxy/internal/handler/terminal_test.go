@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+func dialTerminal(t *testing.T, query string) *websocket.Conn {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := NewTerminalHandler(t.TempDir(), "/bin/sh", nil)
+	router.GET("/session/:id/pty/connect", RequireSharedSecret("secret"), h.ConnectPTY)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/session/term-1/pty/connect" + query
+	conn, resp, err := websocket.DefaultDialer.Dial(url, http.Header{"Authorization": {"Bearer secret"}})
+	if err != nil {
+		t.Fatalf("Failed to connect: %v (response %v)", err, resp)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+// readTerminalUntil collects output frames until they contain want, returning any text frames seen
+func readTerminalUntil(t *testing.T, conn *websocket.Conn, want string) []string {
+	t.Helper()
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	var output strings.Builder
+	var texts []string
+	for !strings.Contains(output.String(), want) {
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("Expected output %q, got %q before: %v", want, output.String(), err)
+		}
+		if messageType == websocket.TextMessage {
+			texts = append(texts, string(data))
+			continue
+		}
+		output.Write(data)
+	}
+	return texts
+}
+
+func TestConnectPTY(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("terminals are only supported on linux")
+	}
+
+	t.Run("relays input and output", func(t *testing.T) {
+		conn := dialTerminal(t, "?cols=100&rows=30")
+
+		conn.WriteMessage(websocket.BinaryMessage, []byte("echo \"size=$(stty size)\"\n"))
+		readTerminalUntil(t, conn, "size=30 100")
+
+		conn.WriteJSON(TerminalMessage{Type: "resize", Cols: 120, Rows: 40})
+		conn.WriteJSON(TerminalMessage{Type: "input", Data: "echo \"size=$(stty size)\"\n"})
+		readTerminalUntil(t, conn, "size=40 120")
+	})
+
+	t.Run("reports shell exit", func(t *testing.T) {
+		conn := dialTerminal(t, "")
+
+		conn.WriteJSON(TerminalMessage{Type: "input", Data: "exit 5\n"})
+
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				t.Fatalf("Connection closed without exit message: %v", err)
+			}
+			if messageType != websocket.TextMessage {
+				continue
+			}
+
+			var msg struct {
+				Type string `json:"type"`
+				Code int    `json:"code"`
+			}
+			if err := json.Unmarshal(data, &msg); err != nil {
+				t.Fatal(err)
+			}
+			if msg.Type != "exit" || msg.Code != 5 {
+				t.Errorf("Expected exit with code 5, got %s", data)
+			}
+			return
+		}
+	})
+}
+
+func TestConnectPTYRequiresSecret(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := NewTerminalHandler(t.TempDir(), "/bin/sh", nil)
+	router.GET("/session/:id/pty/connect", RequireSharedSecret("secret"), h.ConnectPTY)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/session/term-1/pty/connect", nil))
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", w.Code)
+	}
+}
@@ -0,0 +1,566 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// MaxBufferedEvents bounds the events kept per session for replay
+	MaxBufferedEvents = 1000
+
+	// maxEventSize bounds a single line of an event stream or event log
+	maxEventSize = 1024 * 1024
+
+	// subscriberBuffer is how many live events a slow subscriber may fall behind before it is dropped;
+	// it reconnects with Last-Event-ID and catches up from the buffer
+	subscriberBuffer = 256
+
+	defaultRetryDelay = 2 * time.Second
+
+	// DefaultSessionRetention is how long finished sessions are kept for replay before they are pruned
+	DefaultSessionRetention = 7 * 24 * time.Hour
+
+	// pruneInterval is how often finished sessions are checked for pruning
+	pruneInterval = time.Hour
+)
+
+// sessionLostMessage is recorded when the OpenCode server no longer knows a session, e.g. after it restarted
+const sessionLostMessage = "session is no longer known to the OpenCode server"
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+)
+
+var errStreamEnded = errors.New("stream ended")
+
+// Subscription is a session stream: the buffered events after the subscriber's last event,
+// then live events until Close is called or the subscriber falls too far behind
+type Subscription struct {
+	Replay []Event
+	Events <-chan Event
+	// Status is the session status when the subscription started
+	Status string
+
+	close func()
+}
+
+// Close stops the delivery of live events
+func (s *Subscription) Close() {
+	s.close()
+}
+
+type proxiedSession struct {
+	record      SessionRecord
+	events      []Event
+	subscribers map[chan Event]struct{}
+	following   bool
+}
+
+// SessionProxy fronts the OpenCode server: it forwards session requests and follows the event
+// stream of every active session into a buffer persisted in the workspace, so that clients can
+// reconnect at any time and replay what they missed
+type SessionProxy struct {
+	upstreamURL  string
+	sharedSecret string
+	store        *SessionStore
+	client       *http.Client
+	streamClient *http.Client
+	retryDelay   time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	sessions map[string]*proxiedSession
+}
+
+// NewSessionProxy creates a proxy for the OpenCode server at upstreamURL.
+// sharedSecret authenticates the proxy's own requests to the server.
+func NewSessionProxy(upstreamURL, sharedSecret string, store *SessionStore) *SessionProxy {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &SessionProxy{
+		upstreamURL:  strings.TrimRight(upstreamURL, "/"),
+		sharedSecret: sharedSecret,
+		store:        store,
+		client:       &http.Client{Timeout: 30 * time.Second},
+		// No timeout: event streams stay open for the whole session
+		streamClient: &http.Client{},
+		retryDelay:   defaultRetryDelay,
+		ctx:          ctx,
+		cancel:       cancel,
+		sessions:     make(map[string]*proxiedSession),
+	}
+}
+
+// Restore loads the sessions persisted by a previous run and resumes following the active ones
+func (p *SessionProxy) Restore() error {
+	stored, err := p.store.Load(MaxBufferedEvents)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, s := range stored {
+		session := &proxiedSession{
+			record:      s.Record,
+			events:      s.Events,
+			subscribers: make(map[chan Event]struct{}),
+		}
+		if len(s.Events) > 0 {
+			session.record.LastEventID = s.Events[len(s.Events)-1].ID
+		}
+		p.sessions[s.Record.ID] = session
+
+		if !IsTerminalStatus(session.record.Status) {
+			p.startFollowing(session)
+		}
+	}
+
+	slog.Info("Restored sessions", "count", len(stored))
+	return nil
+}
+
+// Prune forgets the finished sessions last updated before cutoff and deletes their records and
+// event logs, which would otherwise fill the workspace volume. Sessions with subscribers are kept.
+func (p *SessionProxy) Prune(cutoff time.Time) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pruned := 0
+	for id, session := range p.sessions {
+		if !IsTerminalStatus(session.record.Status) || session.following || len(session.subscribers) > 0 {
+			continue
+		}
+		if session.record.UpdatedAt.After(cutoff) {
+			continue
+		}
+
+		if err := p.store.Delete(id); err != nil {
+			slog.Error("Failed to delete finished session", "session_id", id, "error", err)
+			continue
+		}
+		delete(p.sessions, id)
+		pruned++
+	}
+
+	return pruned
+}
+
+// PruneFinished prunes the sessions finished for longer than retention now and then every
+// pruneInterval, until the proxy is closed
+func (p *SessionProxy) PruneFinished(retention time.Duration) {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	for {
+		if pruned := p.Prune(time.Now().Add(-retention)); pruned > 0 {
+			slog.Info("Pruned finished sessions", "count", pruned)
+		}
+
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Close stops following session streams
+func (p *SessionProxy) Close() {
+	p.cancel()
+}
+
+// Forward sends a request to the OpenCode server
+func (p *SessionProxy) Forward(ctx context.Context, method, path string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, p.upstreamURL+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	p.authorize(req)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach OpenCode server: %w", err)
+	}
+	return resp, nil
+}
+
+// Track records what the OpenCode server reported about a session and follows its event stream
+// while it is active. Empty fields of update leave the recorded values unchanged.
+func (p *SessionProxy) Track(update SessionRecord) error {
+	if !sessionIDPattern.MatchString(update.ID) {
+		return ErrInvalidSessionID
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	session, exists := p.sessions[update.ID]
+	if !exists {
+		session = &proxiedSession{
+			record:      SessionRecord{ID: update.ID, CreatedAt: now},
+			subscribers: make(map[chan Event]struct{}),
+		}
+		p.sessions[update.ID] = session
+	}
+
+	if update.RemoteSessionID != "" {
+		session.record.RemoteSessionID = update.RemoteSessionID
+	}
+	if update.Status != "" {
+		session.record.Status = update.Status
+		session.record.Error = update.Error
+	}
+	session.record.UpdatedAt = now
+
+	if err := p.store.SaveRecord(&session.record); err != nil {
+		return err
+	}
+
+	if !IsTerminalStatus(session.record.Status) {
+		p.startFollowing(session)
+	}
+
+	return nil
+}
+
+// List returns the recorded sessions, oldest first
+func (p *SessionProxy) List() []SessionRecord {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	records := make([]SessionRecord, 0, len(p.sessions))
+	for _, session := range p.sessions {
+		records = append(records, session.record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].CreatedAt.Before(records[j].CreatedAt)
+	})
+
+	return records
+}
+
+// Get returns the recorded session
+func (p *SessionProxy) Get(sessionID string) (SessionRecord, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	session, exists := p.sessions[sessionID]
+	if !exists {
+		return SessionRecord{}, false
+	}
+	return session.record, true
+}
+
+// Subscribe starts a subscription to the session's events. Events after lastEventID are replayed;
+// without a known lastEventID the whole buffer is.
+func (p *SessionProxy) Subscribe(sessionID, lastEventID string) (*Subscription, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	session, exists := p.sessions[sessionID]
+	if !exists {
+		return nil, ErrSessionNotFound
+	}
+
+	start := 0
+	if lastEventID != "" {
+		for i := len(session.events) - 1; i >= 0; i-- {
+			if session.events[i].ID == lastEventID {
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	ch := make(chan Event, subscriberBuffer)
+	session.subscribers[ch] = struct{}{}
+
+	return &Subscription{
+		Replay: append([]Event(nil), session.events[start:]...),
+		Events: ch,
+		Status: session.record.Status,
+		close: func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			if _, subscribed := session.subscribers[ch]; subscribed {
+				delete(session.subscribers, ch)
+				close(ch)
+			}
+		},
+	}, nil
+}
+
+// startFollowing starts following the session's upstream stream unless it already is. Callers hold p.mu.
+func (p *SessionProxy) startFollowing(session *proxiedSession) {
+	if session.following {
+		return
+	}
+	session.following = true
+
+	go p.follow(session)
+}
+
+// follow reads the session's upstream event stream until the session finishes,
+// resuming after the last buffered event whenever the connection drops
+func (p *SessionProxy) follow(session *proxiedSession) {
+	p.mu.Lock()
+	sessionID := session.record.ID
+	p.mu.Unlock()
+
+	for {
+		err := p.readUpstreamStream(session)
+		if p.stopFollowing(session) {
+			slog.Info("Stopped following finished session", "session_id", sessionID)
+			return
+		}
+		if err != nil {
+			slog.Warn("Session stream interrupted", "session_id", sessionID, "error", err)
+		}
+
+		select {
+		case <-p.ctx.Done():
+			p.mu.Lock()
+			session.following = false
+			p.mu.Unlock()
+			return
+		case <-time.After(p.retryDelay):
+		}
+	}
+}
+
+// stopFollowing ends following a finished session. Checking and clearing the flag under one lock
+// lets a follow-up prompt that resumes the session start following it again.
+func (p *SessionProxy) stopFollowing(session *proxiedSession) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !IsTerminalStatus(session.record.Status) {
+		return false
+	}
+	session.following = false
+	return true
+}
+
+// readUpstreamStream buffers the events of one connection to the upstream stream
+func (p *SessionProxy) readUpstreamStream(session *proxiedSession) error {
+	p.mu.Lock()
+	sessionID := session.record.ID
+	lastEventID := session.record.LastEventID
+	p.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(p.ctx, http.MethodGet, fmt.Sprintf("%s/sessions/%s/stream", p.upstreamURL, sessionID), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	p.authorize(req)
+
+	resp, err := p.streamClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to OpenCode server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return p.markLost(session)
+	default:
+		return fmt.Errorf("OpenCode server stream returned status %d", resp.StatusCode)
+	}
+
+	if err := readEvents(resp.Body, func(event Event) bool {
+		return p.appendEvent(session, event)
+	}); err != nil {
+		return err
+	}
+	return errStreamEnded
+}
+
+// appendEvent buffers, persists and fans out an upstream event. It reports whether the session finished.
+func (p *SessionProxy) appendEvent(session *proxiedSession, event Event) bool {
+	// Heartbeats only keep a connection alive; the proxy sends its own to subscribers
+	if event.Type == "heartbeat" {
+		return false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if event.ID == "" {
+		event.ID = fmt.Sprintf("%s-%s-%d", session.record.ID, event.Type, time.Now().UnixNano())
+	}
+
+	// The server replays its whole buffer when it does not know the last event ID
+	for i := len(session.events) - 1; i >= 0; i-- {
+		if session.events[i].ID == event.ID {
+			return IsTerminalStatus(session.record.Status)
+		}
+	}
+
+	session.events = append(session.events, event)
+	session.record.LastEventID = event.ID
+	if err := p.store.AppendEvent(session.record.ID, event); err != nil {
+		slog.Error("Failed to persist event", "session_id", session.record.ID, "error", err)
+	}
+
+	if len(session.events) > 2*MaxBufferedEvents {
+		session.events = append([]Event(nil), session.events[len(session.events)-MaxBufferedEvents:]...)
+		if err := p.store.ReplaceEvents(session.record.ID, session.events); err != nil {
+			slog.Error("Failed to compact event log", "session_id", session.record.ID, "error", err)
+		}
+	}
+
+	if status, errorMsg, ok := statusFromEvent(event); ok && status != session.record.Status {
+		session.record.Status = status
+		session.record.Error = errorMsg
+		session.record.UpdatedAt = time.Now()
+		if err := p.store.SaveRecord(&session.record); err != nil {
+			slog.Error("Failed to persist session record", "session_id", session.record.ID, "error", err)
+		}
+	}
+
+	for ch := range session.subscribers {
+		select {
+		case ch <- event:
+		default:
+			delete(session.subscribers, ch)
+			close(ch)
+		}
+	}
+
+	return IsTerminalStatus(session.record.Status)
+}
+
+// markLost fails a session the OpenCode server no longer knows, unless it already finished,
+// and tells subscribers with a final status event
+func (p *SessionProxy) markLost(session *proxiedSession) error {
+	p.mu.Lock()
+	finished := IsTerminalStatus(session.record.Status)
+	sessionID := session.record.ID
+	p.mu.Unlock()
+
+	if finished {
+		return nil
+	}
+
+	data, err := json.Marshal(map[string]string{
+		"status":    StatusFailed,
+		"error":     sessionLostMessage,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode status event: %w", err)
+	}
+
+	p.appendEvent(session, Event{
+		ID:   fmt.Sprintf("%s-lost-%d", sessionID, time.Now().UnixNano()),
+		Type: "status",
+		Data: string(data),
+	})
+	return nil
+}
+
+func (p *SessionProxy) authorize(req *http.Request) {
+	if p.sharedSecret != "" {
+		req.Header.Set("Authorization", "Bearer "+p.sharedSecret)
+	}
+}
+
+// statusFromEvent returns the session status an event implies, if any
+func statusFromEvent(event Event) (string, string, bool) {
+	switch event.Type {
+	case "complete":
+		return StatusCompleted, "", true
+	case "question":
+		return StatusWaitingForInput, "", true
+	case "turn_complete":
+		return StatusRunning, "", true
+	case "status":
+		var payload struct {
+			Status string `json:"status"`
+			Error  string `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(event.Data), &payload); err != nil || payload.Status == "" {
+			return "", "", false
+		}
+		return payload.Status, payload.Error, true
+	}
+	return "", "", false
+}
+
+// readEvents parses server-sent events from r and hands them to apply until it reports the end
+func readEvents(r io.Reader, apply func(Event) bool) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventSize)
+
+	var event Event
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case line == "":
+			if event.Type == "" && len(data) == 0 {
+				continue
+			}
+			if event.Type == "" {
+				event.Type = "message"
+			}
+			event.Data = strings.Join(data, "\n")
+
+			if apply(event) {
+				return nil
+			}
+			event, data = Event{}, nil
+		case strings.HasPrefix(line, ":"):
+			// comment line
+		default:
+			field, value, _ := strings.Cut(line, ":")
+			value = strings.TrimPrefix(value, " ")
+			switch field {
+			case "event":
+				event.Type = value
+			case "id":
+				event.ID = value
+			case "data":
+				data = append(data, value)
+			}
+		}
+	}
+
+	return scanner.Err()
+}
+
+// FormatEvent encodes an event in server-sent event format
+func FormatEvent(event Event) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "event: %s\nid: %s\n", event.Type, event.ID)
+	for _, line := range strings.Split(event.Data, "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
@@ -0,0 +1,277 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeUpstream serves scripted session streams in place of the OpenCode server
+type fakeUpstream struct {
+	mu           sync.Mutex
+	streams      []string // body served for each successive stream connection
+	lastEventIDs []string
+	notFound     bool
+}
+
+func (f *fakeUpstream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.notFound {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	f.lastEventIDs = append(f.lastEventIDs, r.Header.Get("Last-Event-ID"))
+	body := ""
+	if len(f.streams) > 0 {
+		body, f.streams = f.streams[0], f.streams[1:]
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Write([]byte(body))
+}
+
+func newTestProxy(t *testing.T, upstream http.Handler) (*SessionProxy, *SessionStore, string) {
+	t.Helper()
+
+	server := httptest.NewServer(upstream)
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	store, err := NewSessionStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proxy := NewSessionProxy(server.URL, "secret", store)
+	proxy.retryDelay = 10 * time.Millisecond
+	t.Cleanup(proxy.Close)
+
+	return proxy, store, dir
+}
+
+// waitForStatus polls until the session reaches the status
+func waitForStatus(t *testing.T, proxy *SessionProxy, sessionID, status string) SessionRecord {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if record, exists := proxy.Get(sessionID); exists && record.Status == status {
+			return record
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	record, _ := proxy.Get(sessionID)
+	t.Fatalf("Session %s did not reach status %s, last status %q", sessionID, status, record.Status)
+	return record
+}
+
+func TestSessionProxy_BuffersAndResumesStream(t *testing.T) {
+	upstream := &fakeUpstream{streams: []string{
+		"event: output\nid: evt-1\ndata: {\"text\": \"a\"}\n\nevent: heartbeat\nid: hb-1\ndata: {}\n\n",
+		// The server replays its buffer: evt-1 must not be stored twice
+		"event: output\nid: evt-1\ndata: {\"text\": \"a\"}\n\nevent: complete\nid: evt-2\ndata: {\"output\": \"done\"}\n\n",
+	}}
+	proxy, store, _ := newTestProxy(t, upstream)
+
+	if err := proxy.Track(SessionRecord{ID: "session-1", RemoteSessionID: "ses_abc", Status: StatusRunning}); err != nil {
+		t.Fatal(err)
+	}
+
+	record := waitForStatus(t, proxy, "session-1", StatusCompleted)
+	if record.LastEventID != "evt-2" {
+		t.Errorf("Expected last event evt-2, got %s", record.LastEventID)
+	}
+
+	upstream.mu.Lock()
+	if len(upstream.lastEventIDs) != 2 || upstream.lastEventIDs[1] != "evt-1" {
+		t.Errorf("Expected the reconnect to resume after evt-1, got %v", upstream.lastEventIDs)
+	}
+	upstream.mu.Unlock()
+
+	sub, err := proxy.Subscribe("session-1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	if len(sub.Replay) != 2 || sub.Replay[0].ID != "evt-1" || sub.Replay[1].ID != "evt-2" {
+		t.Errorf("Expected evt-1 and evt-2 buffered, got %+v", sub.Replay)
+	}
+
+	sessions, err := store.Load(MaxBufferedEvents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || len(sessions[0].Events) != 2 || sessions[0].Record.Status != StatusCompleted {
+		t.Errorf("Expected the session persisted with both events, got %+v", sessions)
+	}
+}
+
+func TestSessionProxy_SubscribeReplaysAfterLastEvent(t *testing.T) {
+	upstream := &fakeUpstream{streams: []string{
+		"event: output\nid: evt-1\ndata: {}\n\nevent: output\nid: evt-2\ndata: {}\n\nevent: complete\nid: evt-3\ndata: {}\n\n",
+	}}
+	proxy, _, _ := newTestProxy(t, upstream)
+
+	if err := proxy.Track(SessionRecord{ID: "session-1", Status: StatusRunning}); err != nil {
+		t.Fatal(err)
+	}
+	waitForStatus(t, proxy, "session-1", StatusCompleted)
+
+	sub, err := proxy.Subscribe("session-1", "evt-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+
+	if len(sub.Replay) != 2 || sub.Replay[0].ID != "evt-2" {
+		t.Errorf("Expected replay from evt-2, got %+v", sub.Replay)
+	}
+	if sub.Status != StatusCompleted {
+		t.Errorf("Expected completed status, got %s", sub.Status)
+	}
+
+	if _, err := proxy.Subscribe("unknown", ""); err != ErrSessionNotFound {
+		t.Errorf("Expected ErrSessionNotFound, got %v", err)
+	}
+}
+
+func TestSessionProxy_LiveEvents(t *testing.T) {
+	release := make(chan struct{})
+	proxy, _, _ := newTestProxy(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		<-release
+		w.Write([]byte("event: question\nid: evt-1\ndata: {\"question\": \"Which database?\"}\n\n"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+
+	if err := proxy.Track(SessionRecord{ID: "session-1", Status: StatusRunning}); err != nil {
+		t.Fatal(err)
+	}
+
+	sub, err := proxy.Subscribe("session-1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	close(release)
+
+	select {
+	case event := <-sub.Events:
+		if event.Type != "question" || !strings.Contains(event.Data, "Which database?") {
+			t.Errorf("Unexpected event %+v", event)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("No live event received")
+	}
+
+	waitForStatus(t, proxy, "session-1", StatusWaitingForInput)
+}
+
+func TestSessionProxy_MarksLostSessionFailed(t *testing.T) {
+	proxy, _, _ := newTestProxy(t, &fakeUpstream{notFound: true})
+
+	if err := proxy.Track(SessionRecord{ID: "session-1", Status: StatusRunning}); err != nil {
+		t.Fatal(err)
+	}
+
+	record := waitForStatus(t, proxy, "session-1", StatusFailed)
+	if record.Error != sessionLostMessage {
+		t.Errorf("Expected lost session error, got %q", record.Error)
+	}
+
+	sub, err := proxy.Subscribe("session-1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	if len(sub.Replay) != 1 || sub.Replay[0].Type != "status" {
+		t.Errorf("Expected a final status event for subscribers, got %+v", sub.Replay)
+	}
+}
+
+func TestSessionProxy_RestoreResumesActiveSessions(t *testing.T) {
+	upstream := &fakeUpstream{streams: []string{
+		"event: complete\nid: evt-2\ndata: {}\n\n",
+	}}
+	server := httptest.NewServer(upstream)
+	defer server.Close()
+
+	store, err := NewSessionStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.SaveRecord(&SessionRecord{ID: "active", Status: StatusRunning})
+	store.AppendEvent("active", Event{ID: "evt-1", Type: "output", Data: "{}"})
+	store.SaveRecord(&SessionRecord{ID: "finished", Status: StatusCompleted})
+
+	proxy := NewSessionProxy(server.URL, "secret", store)
+	proxy.retryDelay = 10 * time.Millisecond
+	defer proxy.Close()
+
+	if err := proxy.Restore(); err != nil {
+		t.Fatal(err)
+	}
+	if len(proxy.List()) != 2 {
+		t.Fatalf("Expected 2 restored sessions, got %d", len(proxy.List()))
+	}
+
+	waitForStatus(t, proxy, "active", StatusCompleted)
+
+	upstream.mu.Lock()
+	defer upstream.mu.Unlock()
+	if len(upstream.lastEventIDs) != 1 || upstream.lastEventIDs[0] != "evt-1" {
+		t.Errorf("Expected only the active session followed from evt-1, got %v", upstream.lastEventIDs)
+	}
+}
+
+func TestSessionProxy_PrunesFinishedSessions(t *testing.T) {
+	store, err := NewSessionStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * DefaultSessionRetention)
+	store.SaveRecord(&SessionRecord{ID: "old-finished", Status: StatusCompleted, UpdatedAt: old})
+	store.AppendEvent("old-finished", Event{ID: "evt-1", Type: "output", Data: "{}"})
+	store.SaveRecord(&SessionRecord{ID: "recent-finished", Status: StatusFailed, UpdatedAt: time.Now()})
+	store.SaveRecord(&SessionRecord{ID: "old-subscribed", Status: StatusCancelled, UpdatedAt: old})
+
+	// Finished sessions are not followed, so the proxy never reaches the upstream
+	proxy := NewSessionProxy("http://127.0.0.1:0", "secret", store)
+	defer proxy.Close()
+	if err := proxy.Restore(); err != nil {
+		t.Fatal(err)
+	}
+
+	sub, err := proxy.Subscribe("old-subscribed", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+
+	if pruned := proxy.Prune(time.Now().Add(-DefaultSessionRetention)); pruned != 1 {
+		t.Errorf("Expected 1 pruned session, got %d", pruned)
+	}
+	if _, exists := proxy.Get("old-finished"); exists {
+		t.Error("Expected the old finished session to be forgotten")
+	}
+
+	stored, err := store.Load(MaxBufferedEvents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 2 {
+		t.Errorf("Expected the recent and subscribed sessions to stay stored, got %+v", stored)
+	}
+}
@@ -0,0 +1,68 @@
+//go:build linux
+
+package service
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"golang.org/x/sys/unix"
+)
+
+// openPTY opens a pseudo-terminal pair, returning the controlling side and the terminal side
+func openPTY() (*os.File, *os.File, error) {
+	ptmx, err := os.OpenFile("/dev/ptmx", os.O_RDWR|unix.O_NOCTTY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open pty: %w", err)
+	}
+
+	var ptyNumber int
+	err = controlFD(ptmx, func(fd int) error {
+		if err := unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0); err != nil {
+			return fmt.Errorf("failed to unlock pty: %w", err)
+		}
+		n, err := unix.IoctlGetInt(fd, unix.TIOCGPTN)
+		if err != nil {
+			return fmt.Errorf("failed to get pty number: %w", err)
+		}
+		ptyNumber = n
+		return nil
+	})
+	if err != nil {
+		ptmx.Close()
+		return nil, nil, err
+	}
+
+	tty, err := os.OpenFile("/dev/pts/"+strconv.Itoa(ptyNumber), os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		ptmx.Close()
+		return nil, nil, fmt.Errorf("failed to open tty: %w", err)
+	}
+
+	return ptmx, tty, nil
+}
+
+// setPTYSize sets the window size of the terminal
+func setPTYSize(ptmx *os.File, cols, rows uint16) error {
+	return controlFD(ptmx, func(fd int) error {
+		return unix.IoctlSetWinsize(fd, unix.TIOCSWINSZ, &unix.Winsize{Col: cols, Row: rows})
+	})
+}
+
+// controlFD runs fn on the file descriptor without switching the file to blocking mode,
+// so closing the file still interrupts a pending read
+func controlFD(f *os.File, fn func(fd int) error) error {
+	conn, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var fnErr error
+	if err := conn.Control(func(fd uintptr) {
+		fnErr = fn(int(fd))
+	}); err != nil {
+		return err
+	}
+	return fnErr
+}
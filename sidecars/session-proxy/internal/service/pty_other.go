@@ -0,0 +1,18 @@
+//go:build !linux
+
+package service
+
+import (
+	"errors"
+	"os"
+)
+
+var errPTYUnsupported = errors.New("terminals are only supported on linux")
+
+func openPTY() (*os.File, *os.File, error) {
+	return nil, nil, errPTYUnsupported
+}
+
+func setPTYSize(ptmx *os.File, cols, rows uint16) error {
+	return errPTYUnsupported
+}
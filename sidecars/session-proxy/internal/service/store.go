@@ -0,0 +1,257 @@
+package service
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrInvalidSessionID = errors.New("invalid session ID")
+)
+
+// Session statuses reported by the OpenCode server
+const (
+	StatusPending         = "pending"
+	StatusRunning         = "running"
+	StatusWaitingForInput = "waiting_for_input"
+	StatusCompleted       = "completed"
+	StatusFailed          = "failed"
+	StatusCancelled       = "cancelled"
+)
+
+// IsTerminalStatus reports whether a session with this status is finished
+func IsTerminalStatus(status string) bool {
+	switch status {
+	case StatusCompleted, StatusFailed, StatusCancelled:
+		return true
+	}
+	return false
+}
+
+// sessionIDPattern keeps session IDs usable as file names
+var sessionIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// SessionRecord is the proxy's view of a session, persisted across container restarts
+type SessionRecord struct {
+	ID              string    `json:"session_id"`
+	RemoteSessionID string    `json:"remote_session_id,omitempty"`
+	Status          string    `json:"status"`
+	Error           string    `json:"error,omitempty"`
+	LastEventID     string    `json:"last_event_id,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Event is one server-sent event of a session stream, kept verbatim
+type Event struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data string `json:"data"`
+}
+
+// StoredSession is a session loaded back from disk
+type StoredSession struct {
+	Record SessionRecord
+	Events []Event
+}
+
+// SessionStore persists session records and their event buffers in a directory,
+// one <id>.json record and one <id>.events.jsonl event log per session
+type SessionStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewSessionStore creates a store in dir, creating the directory if needed
+func NewSessionStore(dir string) (*SessionStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create session directory: %w", err)
+	}
+
+	return &SessionStore{dir: dir}, nil
+}
+
+// SaveRecord writes the session record, replacing the previous one atomically
+func (s *SessionStore) SaveRecord(record *SessionRecord) error {
+	if !sessionIDPattern.MatchString(record.ID) {
+		return ErrInvalidSessionID
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode session record: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return writeFileAtomic(s.recordPath(record.ID), data)
+}
+
+// AppendEvent adds an event to the session's event log
+func (s *SessionStore) AppendEvent(sessionID string, event Event) error {
+	if !sessionIDPattern.MatchString(sessionID) {
+		return ErrInvalidSessionID
+	}
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.eventsPath(sessionID), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open event log: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to append event: %w", err)
+	}
+
+	return nil
+}
+
+// ReplaceEvents rewrites the session's event log with events, e.g. after the buffer was trimmed
+func (s *SessionStore) ReplaceEvents(sessionID string, events []Event) error {
+	if !sessionIDPattern.MatchString(sessionID) {
+		return ErrInvalidSessionID
+	}
+
+	var b strings.Builder
+	for _, event := range events {
+		line, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to encode event: %w", err)
+		}
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return writeFileAtomic(s.eventsPath(sessionID), []byte(b.String()))
+}
+
+// Delete removes the session's record and event log
+func (s *SessionStore) Delete(sessionID string) error {
+	if !sessionIDPattern.MatchString(sessionID) {
+		return ErrInvalidSessionID
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The record goes last: a record without events still loads, events without a record do not
+	for _, path := range []string{s.eventsPath(sessionID), s.recordPath(sessionID)} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to delete %s: %w", filepath.Base(path), err)
+		}
+	}
+
+	return nil
+}
+
+// Load reads every stored session, keeping at most maxEvents of the latest events per session
+func (s *SessionStore) Load(maxEvents int) ([]StoredSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session directory: %w", err)
+	}
+
+	var sessions []StoredSession
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(s.dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read session record %s: %w", name, err)
+		}
+
+		var record SessionRecord
+		if err := json.Unmarshal(data, &record); err != nil || !sessionIDPattern.MatchString(record.ID) {
+			// A record torn by a crash is useless; skip it rather than refusing to start
+			continue
+		}
+
+		events, err := s.readEvents(record.ID, maxEvents)
+		if err != nil {
+			return nil, err
+		}
+
+		sessions = append(sessions, StoredSession{Record: record, Events: events})
+	}
+
+	return sessions, nil
+}
+
+func (s *SessionStore) readEvents(sessionID string, maxEvents int) ([]Event, error) {
+	f, err := os.Open(s.eventsPath(sessionID))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open event log: %w", err)
+	}
+	defer f.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventSize)
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			// Only the line being written when the container stopped can be partial
+			continue
+		}
+		events = append(events, event)
+		if len(events) > maxEvents {
+			events = events[1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read event log: %w", err)
+	}
+
+	return events, nil
+}
+
+func (s *SessionStore) recordPath(sessionID string) string {
+	return filepath.Join(s.dir, sessionID+".json")
+}
+
+func (s *SessionStore) eventsPath(sessionID string) string {
+	return filepath.Join(s.dir, sessionID+".events.jsonl")
+}
+
+// writeFileAtomic writes data to a temporary file and renames it over path,
+// so readers never see a partially written file
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to replace %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
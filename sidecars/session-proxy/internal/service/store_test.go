@@ -0,0 +1,157 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSessionStore_SaveAndLoad(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), ".vibe", "sessions")
+
+	store, err := NewSessionStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := &SessionRecord{
+		ID:              "session-1",
+		RemoteSessionID: "ses_abc",
+		Status:          StatusRunning,
+		CreatedAt:       time.Now(),
+	}
+	if err := store.SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+	for _, event := range []Event{
+		{ID: "evt-1", Type: "output", Data: `{"text":"a"}`},
+		{ID: "evt-2", Type: "output", Data: `{"text":"b"}`},
+		{ID: "evt-3", Type: "complete", Data: `{"output":"done"}`},
+	} {
+		if err := store.AppendEvent(record.ID, event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sessions, err := store.Load(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 {
+		t.Fatalf("Expected 1 session, got %d", len(sessions))
+	}
+
+	loaded := sessions[0]
+	if loaded.Record.RemoteSessionID != "ses_abc" || loaded.Record.Status != StatusRunning {
+		t.Errorf("Unexpected record: %+v", loaded.Record)
+	}
+	if len(loaded.Events) != 2 || loaded.Events[0].ID != "evt-2" || loaded.Events[1].ID != "evt-3" {
+		t.Errorf("Expected the 2 latest events, got %+v", loaded.Events)
+	}
+}
+
+func TestSessionStore_LoadSkipsPartialWrites(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := NewSessionStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.SaveRecord(&SessionRecord{ID: "session-1", Status: StatusRunning}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AppendEvent("session-1", Event{ID: "evt-1", Type: "output", Data: "{}"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A line cut short by a container restart, and a record torn the same way
+	f, err := os.OpenFile(filepath.Join(dir, "session-1.events.jsonl"), os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"id":"evt-2","ty`)
+	f.Close()
+	os.WriteFile(filepath.Join(dir, "session-2.json"), []byte(`{"session_id":`), 0o644)
+
+	sessions, err := store.Load(MaxBufferedEvents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 {
+		t.Fatalf("Expected 1 session, got %d", len(sessions))
+	}
+	if len(sessions[0].Events) != 1 || sessions[0].Events[0].ID != "evt-1" {
+		t.Errorf("Expected only the complete event, got %+v", sessions[0].Events)
+	}
+}
+
+func TestSessionStore_ReplaceEvents(t *testing.T) {
+	store, err := NewSessionStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.SaveRecord(&SessionRecord{ID: "session-1", Status: StatusRunning}); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"evt-1", "evt-2", "evt-3"} {
+		if err := store.AppendEvent("session-1", Event{ID: id, Type: "output"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := store.ReplaceEvents("session-1", []Event{{ID: "evt-3", Type: "output"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, err := store.Load(MaxBufferedEvents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions[0].Events) != 1 || sessions[0].Events[0].ID != "evt-3" {
+		t.Errorf("Expected the replaced events, got %+v", sessions[0].Events)
+	}
+}
+
+func TestSessionStore_Delete(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewSessionStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store.SaveRecord(&SessionRecord{ID: "session-1", Status: StatusCompleted})
+	store.AppendEvent("session-1", Event{ID: "evt-1", Type: "output"})
+	store.SaveRecord(&SessionRecord{ID: "session-2", Status: StatusRunning})
+
+	if err := store.Delete("session-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete("session-1"); err != nil {
+		t.Errorf("Expected deleting a deleted session to succeed, got %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "session-2.json" {
+		t.Errorf("Expected only the record of session-2 left, got %v", entries)
+	}
+}
+
+func TestSessionStore_RejectsInvalidSessionID(t *testing.T) {
+	store, err := NewSessionStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.SaveRecord(&SessionRecord{ID: "../escape"}); err != ErrInvalidSessionID {
+		t.Errorf("Expected ErrInvalidSessionID, got %v", err)
+	}
+	if err := store.AppendEvent("a/b", Event{ID: "evt-1"}); err != ErrInvalidSessionID {
+		t.Errorf("Expected ErrInvalidSessionID, got %v", err)
+	}
+}
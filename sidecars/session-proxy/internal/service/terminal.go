@@ -0,0 +1,195 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// defaultShells are tried in order when no shell is configured
+var defaultShells = []string{"/bin/bash", "/bin/sh"}
+
+// shellEnvAllowlist names the sidecar environment variables passed on to the shell.
+// Leaving the others out of its environment does not hide them: any process of the sidecar's
+// user can read them from /proc/<pid>/environ. Only running the shell as another user, see
+// TerminalUser, keeps the shared secret guarding the sidecar APIs out of its reach.
+var shellEnvAllowlist = []string{"HOME", "PATH", "LANG", "USER", "SHELL"}
+
+// TerminalUser is the unprivileged account shells run as
+type TerminalUser struct {
+	Name string
+	UID  uint32
+	GID  uint32
+	Home string
+}
+
+// LookupTerminalUser finds the account shells run as. Root is refused, since its shells
+// could read the environment of the sidecar.
+func LookupTerminalUser(name string) (*TerminalUser, error) {
+	account, err := user.Lookup(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up terminal user: %w", err)
+	}
+	uid, err := strconv.ParseUint(account.Uid, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid uid %q for terminal user %s", account.Uid, name)
+	}
+	gid, err := strconv.ParseUint(account.Gid, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gid %q for terminal user %s", account.Gid, name)
+	}
+	if uid == 0 {
+		return nil, fmt.Errorf("terminal user %s must not be root", name)
+	}
+
+	return &TerminalUser{
+		Name: account.Username,
+		UID:  uint32(uid),
+		GID:  uint32(gid),
+		Home: account.HomeDir,
+	}, nil
+}
+
+// Terminal is an interactive shell running on a pseudo-terminal
+type Terminal struct {
+	pty  *os.File
+	cmd  *exec.Cmd
+	done chan struct{}
+	err  error
+}
+
+// StartTerminal starts shell (or the first available default shell) in dir on a new terminal,
+// as account, or as the sidecar's own user when account is nil
+func StartTerminal(dir, shell string, account *TerminalUser, cols, rows uint16) (*Terminal, error) {
+	if shell == "" {
+		shell = findShell()
+	}
+
+	ptmx, tty, err := openPTY()
+	if err != nil {
+		return nil, err
+	}
+	defer tty.Close()
+
+	if cols > 0 && rows > 0 {
+		if err := setPTYSize(ptmx, cols, rows); err != nil {
+			ptmx.Close()
+			return nil, fmt.Errorf("failed to size terminal: %w", err)
+		}
+	}
+
+	cmd := exec.Command(shell)
+	cmd.Dir = dir
+	cmd.Env = shellEnv(account)
+	cmd.Stdin = tty
+	cmd.Stdout = tty
+	cmd.Stderr = tty
+	// A session of its own makes the terminal the shell's controlling terminal,
+	// and lets Close signal everything started from the shell
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true}
+	if account != nil {
+		// The shell owns its terminal, like after a login
+		if err := tty.Chown(int(account.UID), int(account.GID)); err != nil {
+			ptmx.Close()
+			return nil, fmt.Errorf("failed to hand terminal to %s: %w", account.Name, err)
+		}
+		cmd.SysProcAttr.Credential = &syscall.Credential{Uid: account.UID, Gid: account.GID}
+	}
+
+	if err := cmd.Start(); err != nil {
+		ptmx.Close()
+		return nil, fmt.Errorf("failed to start shell: %w", err)
+	}
+
+	t := &Terminal{
+		pty:  ptmx,
+		cmd:  cmd,
+		done: make(chan struct{}),
+	}
+	go func() {
+		t.err = cmd.Wait()
+		close(t.done)
+	}()
+
+	return t, nil
+}
+
+// Read reads terminal output
+func (t *Terminal) Read(p []byte) (int, error) {
+	return t.pty.Read(p)
+}
+
+// Write sends input to the terminal
+func (t *Terminal) Write(p []byte) (int, error) {
+	return t.pty.Write(p)
+}
+
+// Resize changes the terminal window size
+func (t *Terminal) Resize(cols, rows uint16) error {
+	if cols == 0 || rows == 0 {
+		return errors.New("terminal size must be positive")
+	}
+	return setPTYSize(t.pty, cols, rows)
+}
+
+// Done is closed once the shell exited
+func (t *Terminal) Done() <-chan struct{} {
+	return t.done
+}
+
+// ExitCode returns the shell's exit code once it exited
+func (t *Terminal) ExitCode() int {
+	<-t.done
+
+	var exitErr *exec.ExitError
+	if errors.As(t.err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	if t.err != nil {
+		return -1
+	}
+	return 0
+}
+
+// Close ends the shell and everything it started, then releases the terminal
+func (t *Terminal) Close() error {
+	select {
+	case <-t.done:
+	default:
+		// The shell leads its own process group, which also holds the commands it started
+		syscall.Kill(-t.cmd.Process.Pid, syscall.SIGKILL)
+		<-t.done
+	}
+	return t.pty.Close()
+}
+
+// shellEnv builds the shell's environment from the allowlisted sidecar variables,
+// with the home and name of account when the shell runs as another user
+func shellEnv(account *TerminalUser) []string {
+	env := make([]string, 0, len(shellEnvAllowlist)+1)
+	for _, name := range shellEnvAllowlist {
+		if account != nil && (name == "HOME" || name == "USER") {
+			continue
+		}
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+value)
+		}
+	}
+	if account != nil {
+		env = append(env, "HOME="+account.Home, "USER="+account.Name)
+	}
+	return append(env, "TERM=xterm-256color")
+}
+
+func findShell() string {
+	for _, shell := range defaultShells {
+		if _, err := os.Stat(shell); err == nil {
+			return shell
+		}
+	}
+	return defaultShells[len(defaultShells)-1]
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readUntil reads terminal output until it contains want
+func readUntil(t *testing.T, terminal *Terminal, want string) string {
+	t.Helper()
+
+	output := make(chan string, 1)
+	go func() {
+		var out bytes.Buffer
+		buf := make([]byte, 1024)
+		for {
+			n, err := terminal.Read(buf)
+			out.Write(buf[:n])
+			if strings.Contains(out.String(), want) || err != nil {
+				output <- out.String()
+				return
+			}
+		}
+	}()
+
+	select {
+	case out := <-output:
+		if !strings.Contains(out, want) {
+			t.Fatalf("Expected output to contain %q, got %q", want, out)
+		}
+		return out
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for %q", want)
+		return ""
+	}
+}
+
+func TestTerminal(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("terminals are only supported on linux")
+	}
+
+	t.Run("runs commands in the directory", func(t *testing.T) {
+		dir := t.TempDir()
+
+		terminal, err := StartTerminal(dir, "/bin/sh", nil, 80, 24)
+		if err != nil {
+			t.Fatalf("Failed to start terminal: %v", err)
+		}
+		defer terminal.Close()
+
+		if _, err := terminal.Write([]byte("echo \"cwd=$(pwd)\"\n")); err != nil {
+			t.Fatal(err)
+		}
+		readUntil(t, terminal, "cwd="+dir)
+	})
+
+	t.Run("does not leak the sidecar environment", func(t *testing.T) {
+		t.Setenv("OPENCODE_SHARED_SECRET", "s3cret")
+		t.Setenv("LANG", "C.UTF-8")
+
+		terminal, err := StartTerminal(t.TempDir(), "/bin/sh", nil, 80, 24)
+		if err != nil {
+			t.Fatalf("Failed to start terminal: %v", err)
+		}
+		defer terminal.Close()
+
+		terminal.Write([]byte("echo \"secret=[$OPENCODE_SHARED_SECRET] lang=[$LANG] term=[$TERM]\"\n"))
+		readUntil(t, terminal, "secret=[] lang=[C.UTF-8] term=[xterm-256color]")
+	})
+
+	t.Run("runs as the terminal user", func(t *testing.T) {
+		if os.Geteuid() != 0 {
+			t.Skip("switching users requires root")
+		}
+		t.Setenv("OPENCODE_SHARED_SECRET", "s3cret")
+		account := &TerminalUser{Name: "nobody", UID: 65534, GID: 65534, Home: "/nonexistent"}
+
+		terminal, err := StartTerminal("/", "/bin/sh", account, 80, 24)
+		if err != nil {
+			t.Fatalf("Failed to start terminal: %v", err)
+		}
+		defer terminal.Close()
+
+		// The shell must not read the secret from the environment of the sidecar process either
+		fmt.Fprintf(terminal, "echo \"uid=$(id -u) home=$HOME user=$USER environ=$(cat /proc/%d/environ >/dev/null 2>&1 && echo readable || echo denied)\"\n", os.Getpid())
+		readUntil(t, terminal, "uid=65534 home=/nonexistent user=nobody environ=denied")
+	})
+
+	t.Run("resizes", func(t *testing.T) {
+		terminal, err := StartTerminal(t.TempDir(), "/bin/sh", nil, 80, 24)
+		if err != nil {
+			t.Fatalf("Failed to start terminal: %v", err)
+		}
+		defer terminal.Close()
+
+		if err := terminal.Resize(132, 43); err != nil {
+			t.Fatalf("Failed to resize: %v", err)
+		}
+		if err := terminal.Resize(0, 43); err == nil {
+			t.Error("Expected zero width to be rejected")
+		}
+
+		terminal.Write([]byte("echo \"size=$(stty size)\"\n"))
+		readUntil(t, terminal, "size=43 132")
+	})
+
+	t.Run("reports exit code", func(t *testing.T) {
+		terminal, err := StartTerminal(t.TempDir(), "/bin/sh", nil, 0, 0)
+		if err != nil {
+			t.Fatalf("Failed to start terminal: %v", err)
+		}
+		defer terminal.Close()
+
+		terminal.Write([]byte("exit 3\n"))
+
+		select {
+		case <-terminal.Done():
+		case <-time.After(5 * time.Second):
+			t.Fatal("Shell did not exit")
+		}
+		if code := terminal.ExitCode(); code != 3 {
+			t.Errorf("Expected exit code 3, got %d", code)
+		}
+	})
+
+	t.Run("close kills the shell", func(t *testing.T) {
+		terminal, err := StartTerminal(t.TempDir(), "/bin/sh", nil, 0, 0)
+		if err != nil {
+			t.Fatalf("Failed to start terminal: %v", err)
+		}
+
+		closed := make(chan error, 1)
+		go func() { closed <- terminal.Close() }()
+
+		select {
+		case <-closed:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Close did not return")
+		}
+		select {
+		case <-terminal.Done():
+		default:
+			t.Error("Expected shell to have exited")
+		}
+	})
+}
+
+func TestLookupTerminalUser(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("terminals are only supported on linux")
+	}
+
+	if _, err := LookupTerminalUser("root"); err == nil {
+		t.Error("Expected root to be refused")
+	}
+	if _, err := LookupTerminalUser("no-such-terminal-user"); err == nil {
+		t.Error("Expected unknown user to be refused")
+	}
+
+	account, err := LookupTerminalUser("nobody")
+	if err != nil {
+		t.Skipf("no nobody account: %v", err)
+	}
+	if account.UID == 0 || account.Name != "nobody" {
+		t.Errorf("Unexpected account %+v", account)
+	}
+}